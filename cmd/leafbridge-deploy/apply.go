@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbassign"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbschedule"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
+)
+
+// ApplyCmd applies the deployments that an assignment manifest assigns to
+// the local machine.
+type ApplyCmd struct {
+	Manifest      string          `kong:"required,name='manifest',help='URL or path of the assignment manifest. UNC paths are supported.'"`
+	Tags          []string        `kong:"optional,name='tag',help='Additional tags to treat the machine as having. Can be repeated.'"`
+	Force         bool            `kong:"optional,name='force',help='Force processing of the commands that would normally be skipped.'"`
+	Reverify      bool            `kong:"optional,name='reverify',help='Hash staged package files again instead of trusting the verification results of an earlier run.'"`
+	Timeout       time.Duration   `kong:"optional,name='timeout',help='The maximum amount of time each deployment is allowed to run. Overrides the timeout of the deployments.'"`
+	MaxConcurrent int             `kong:"optional,name='max-concurrent',default='1',help='The maximum number of assignments to apply at the same time. Assignments that compete for the same locks or packages are never applied at the same time.'"`
+	Verbose       bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	Locale        string          `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line, in the Windows event log and in progress updates. The de and fr locales are supported.'"`
+	EventFile     string          `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	NoHostContext bool            `kong:"optional,name='no-host-context',help='Omit host and session information from event records.'"`
+	AzureLog      AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF          GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge apply command.
+//
+// The machine is identified by its name, the directory groups its computer
+// account belongs to and the tags assigned to it. Matching assignments are
+// applied in order of priority, and then in the order that they appear in
+// the manifest. Independent assignments can be applied concurrently, but
+// assignments that compete for the same locks or packages are applied one
+// at a time. A failed assignment does not prevent the others from being
+// applied. Disruptive flows are deferred until the machine is idle.
+func (cmd ApplyCmd) Run(ctx context.Context) error {
+	// Prepare an event recorder.
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+	handler, closeHandler, err := newEventHandler(events, cmd.Verbose, cmd.Locale, cmd.EventFile, cmd.AzureLog, cmd.GELF)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+	if cmd.MaxConcurrent > 1 {
+		handler = lbevent.NewSyncHandler(handler)
+	}
+	recorder := lbevent.Recorder{Handler: handler}
+	if !cmd.NoHostContext {
+		recorder.Origin.Host = hostinfo.Collect()
+	}
+
+	// Identify the machine.
+	target, err := assignmentTarget(cmd.Tags)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the manifest and determine which assignments apply.
+	manifest, err := lbassign.FetchManifest(ctx, http.DefaultClient, cmd.Manifest)
+	if err != nil {
+		return err
+	}
+	assignments := manifest.Match(target)
+
+	matched := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		matched = append(matched, assignment.Label())
+	}
+	recorder.Record(lbdeployevent.AssignmentsResolved{
+		Manifest: cmd.Manifest,
+		Machine:  target.Machine,
+		Groups:   target.Groups,
+		Tags:     target.Tags,
+		Matched:  matched,
+		Total:    len(manifest.Assignments),
+	})
+
+	// Retrieve the deployment of each assignment, so that the resources
+	// that they compete for are known before any of them are applied.
+	var (
+		jobs []lbschedule.Job
+		errs []error
+	)
+	for _, assignment := range assignments {
+		started := time.Now()
+		location, dep, err := cmd.fetch(ctx, assignment)
+		if err != nil {
+			recorder.Record(lbdeployevent.AssignmentApplied{
+				Assignment: assignment.Label(),
+				Location:   location,
+				Flow:       assignment.Flow,
+				Duration:   time.Since(started),
+				Err:        err,
+			})
+			errs = append(errs, fmt.Errorf("assignment \"%s\": %w", assignment.Label(), err))
+			continue
+		}
+		jobs = append(jobs, lbschedule.Job{
+			Name:      assignment.Label(),
+			Priority:  assignment.Priority,
+			Resources: lbschedule.DeploymentResources(dep),
+			Run: func(ctx context.Context) error {
+				return cmd.apply(ctx, recorder, assignment, location, dep)
+			},
+		})
+	}
+
+	// Apply the assignments.
+	scheduler := lbschedule.Scheduler{MaxConcurrent: cmd.MaxConcurrent}
+	for i, err := range scheduler.Run(ctx, jobs) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("assignment \"%s\": %w", jobs[i].Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fetch resolves the location of the deployment of an assignment and
+// retrieves it.
+func (cmd ApplyCmd) fetch(ctx context.Context, assignment lbassign.Assignment) (location string, dep lbdeploy.Deployment, err error) {
+	location, err = lbassign.Resolve(cmd.Manifest, assignment.Deployment)
+	if err != nil {
+		return "", dep, err
+	}
+	dep, err = lbassign.FetchDeployment(ctx, http.DefaultClient, location)
+	return location, dep, err
+}
+
+// apply invokes the flow of an assignment within its deployment.
+func (cmd ApplyCmd) apply(ctx context.Context, recorder lbevent.Recorder, assignment lbassign.Assignment, location string, dep lbdeploy.Deployment) (err error) {
+	event := lbdeployevent.AssignmentApplied{
+		Assignment: assignment.Label(),
+		Location:   location,
+		Deployment: dep.ID,
+		Flow:       assignment.Flow,
+	}
+	started := time.Now()
+	defer func() {
+		event.Duration = time.Since(started)
+		event.Err = err
+		recorder.Record(event)
+	}()
+
+	return leafbridge.Invoke(ctx, dep, assignment.Flow, leafbridge.Options{
+		Handler:  recorder.Handler,
+		Origin:   recorder.Origin,
+		Force:    cmd.Force,
+		Reverify: cmd.Reverify,
+		Timeout:  cmd.Timeout,
+		Agent:    true,
+	})
+}
+
+// assignmentTarget identifies the local machine for the purpose of
+// evaluating assignments.
+func assignmentTarget(extraTags []string) (lbassign.Target, error) {
+	machine, err := hostinfo.ComputerName()
+	if err != nil {
+		if machine, err = os.Hostname(); err != nil {
+			return lbassign.Target{}, fmt.Errorf("failed to determine the name of the computer: %w", err)
+		}
+	}
+	groups, err := hostinfo.ComputerGroups()
+	if err != nil {
+		return lbassign.Target{}, fmt.Errorf("failed to determine the groups of the computer: %w", err)
+	}
+	tags, err := hostinfo.Tags()
+	if err != nil {
+		return lbassign.Target{}, fmt.Errorf("failed to read the tags of the computer: %w", err)
+	}
+	return lbassign.Target{
+		Machine: machine,
+		Groups:  groups,
+		Tags:    append(tags, extraTags...),
+	}, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// ApproveCmd signs a deployment on behalf of one of its approvers, and
+// prints the signature so that it can be added to the approval metadata
+// of the deployment file.
+type ApproveCmd struct {
+	ConfigFile string `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Approver   string `kong:"required,name='approver',help='The identity of the approver. It must be listed as an approver in the deployment file.'"`
+	KeyFile    string `kong:"required,name='key-file',help='Path to a file holding the base64-encoded ed25519 private key or seed of the approver.'"`
+}
+
+// Run executes the LeafBridge approve command.
+func (cmd ApproveCmd) Run(ctx context.Context) error {
+	// Read the deployment file.
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(dep.Approval.Approvers, cmd.Approver) {
+		return fmt.Errorf("\"%s\" is not listed as an approver of the \"%s\" deployment", cmd.Approver, dep.ID)
+	}
+
+	// Read the approver's private key.
+	key, err := loadApprovalKey(cmd.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	// Sign the deployment.
+	digest, err := dep.ApprovalDigest()
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(lbdeploy.ApprovalSignature{
+		Approver:  cmd.Approver,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest)),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+
+	return nil
+}
+
+// loadApprovalKey reads a base64-encoded ed25519 private key or seed from
+// the file at path.
+func loadApprovalKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("the key file does not hold valid base64: %w", err)
+	}
+	switch len(key) {
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	default:
+		return nil, fmt.Errorf("the key is %d bytes long, but ed25519 private keys are %d bytes long", len(key), ed25519.PrivateKeySize)
+	}
+}
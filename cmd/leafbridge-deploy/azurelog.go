@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbevent/azurelog"
+)
+
+// AzureLogOptions hold options for sending events to an Azure Log Analytics
+// workspace.
+type AzureLogOptions struct {
+	Endpoint         string `kong:"optional,name='endpoint',env='LEAFBRIDGE_AZURE_LOG_ENDPOINT',help='The logs ingestion URL of an Azure Monitor data collection endpoint. Events are sent to Azure Log Analytics when this is provided.'"`
+	RuleID           string `kong:"optional,name='rule-id',env='LEAFBRIDGE_AZURE_LOG_RULE_ID',help='The immutable ID of the data collection rule.'"`
+	Stream           string `kong:"optional,name='stream',env='LEAFBRIDGE_AZURE_LOG_STREAM',default='Custom-LeafBridge_CL',help='The name of the stream within the data collection rule.'"`
+	TenantID         string `kong:"optional,name='tenant-id',env='LEAFBRIDGE_AZURE_TENANT_ID',help='The Microsoft Entra tenant ID of the application used to send events.'"`
+	ClientID         string `kong:"optional,name='client-id',env='LEAFBRIDGE_AZURE_CLIENT_ID',help='The client ID of the application used to send events.'"`
+	ClientSecretFile string `kong:"optional,name='client-secret-file',env='LEAFBRIDGE_AZURE_CLIENT_SECRET_FILE',help='A file containing the client secret of the application used to send events. If not provided, the secret is read from the LEAFBRIDGE_AZURE_CLIENT_SECRET environment variable.'"`
+	SpoolDir         string `kong:"optional,name='spool-dir',env='LEAFBRIDGE_AZURE_LOG_SPOOL_DIR',help='A directory that holds events that could not be sent. Defaults to a directory within ProgramData.'"`
+}
+
+// clientSecretEnv is the environment variable that holds the client secret
+// when a secret file is not provided. The secret is deliberately not
+// accepted as a command line argument, where it would be visible to other
+// processes.
+const clientSecretEnv = "LEAFBRIDGE_AZURE_CLIENT_SECRET"
+
+// Enabled returns true if events should be sent to Azure Log Analytics.
+func (opts AzureLogOptions) Enabled() bool {
+	return opts.Endpoint != ""
+}
+
+// ClientSecret returns the client secret of the application used to send
+// events. It is read from the secret file, if one was provided, or from the
+// environment.
+func (opts AzureLogOptions) ClientSecret() (string, error) {
+	if opts.ClientSecretFile != "" {
+		data, err := os.ReadFile(opts.ClientSecretFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the Azure client secret file: %w", err)
+		}
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return "", fmt.Errorf("the Azure client secret file \"%s\" is empty", opts.ClientSecretFile)
+		}
+		return secret, nil
+	}
+	if secret := os.Getenv(clientSecretEnv); secret != "" {
+		return secret, nil
+	}
+	return "", fmt.Errorf("an Azure client secret was not provided in a secret file or the %s environment variable", clientSecretEnv)
+}
+
+// NewHandler returns an Azure Log Analytics event handler for the options.
+func (opts AzureLogOptions) NewHandler() (*azurelog.Handler, error) {
+	secret, err := opts.ClientSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	spoolDir := opts.SpoolDir
+	if spoolDir == "" {
+		if programData := os.Getenv("ProgramData"); programData != "" {
+			spoolDir = filepath.Join(programData, "LeafBridge", "Spool", "AzureLog")
+		}
+	}
+
+	return azurelog.NewHandler(azurelog.Config{
+		Endpoint: opts.Endpoint,
+		RuleID:   opts.RuleID,
+		Stream:   opts.Stream,
+		Credentials: &azurelog.ClientCredentials{
+			TenantID:     opts.TenantID,
+			ClientID:     opts.ClientID,
+			ClientSecret: secret,
+		},
+		SpoolDir: spoolDir,
+	})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbbundle"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
+	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
+)
+
+// BundleCmd packages a deployment and all of its packages into an offline
+// bundle, or into a self-extracting executable that invokes a flow when it
+// is launched.
+type BundleCmd struct {
+	ConfigFile    string                `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Output        string                `kong:"required,name='output',short='o',help='Path of the bundle to create.'"`
+	Flow          lbdeploy.FlowID       `kong:"optional,name='flow',help='Produce a self-extracting executable that invokes this flow when it is launched.'"`
+	Verbose       bool                  `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	Locale        string                `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line, in the Windows event log and in progress updates. The de and fr locales are supported.'"`
+	EventFile     string                `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	NoHostContext bool                  `kong:"optional,name='no-host-context',help='Omit host and session information from event records.'"`
+	Architecture  lbdeploy.Architecture `kong:"optional,name='architecture',help='Bundle the packages for this architecture (x64, arm64 or x86) instead of the architecture of this system.'"`
+	AzureLog      AzureLogOptions       `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF          GELFOptions           `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge bundle command.
+//
+// Each package is downloaded and verified, then copied into the bundle.
+// The finished bundle is verified again before the command returns.
+func (cmd BundleCmd) Run(ctx context.Context) error {
+	// Read the deployment file.
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+	document, err := os.ReadFile(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	// Prepare an event recorder.
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+	handler, closeHandler, err := newEventHandler(events, cmd.Verbose, cmd.Locale, cmd.EventFile, cmd.AzureLog, cmd.GELF)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+	recorder := lbevent.Recorder{Handler: handler}
+	if !cmd.NoHostContext {
+		recorder.Origin.Host = hostinfo.Collect()
+	}
+
+	// Make sure the architecture is recognized and the flow exists before
+	// anything is downloaded.
+	if cmd.Architecture != "" {
+		if err := cmd.Architecture.Validate(); err != nil {
+			return err
+		}
+	}
+	if cmd.Flow != "" {
+		if _, found := dep.Flows[cmd.Flow]; !found {
+			return fmt.Errorf("the flow \"%s\" does not exist within the \"%s\" deployment", cmd.Flow, dep.ID)
+		}
+	}
+
+	// Create the output file. Refuse to overwrite an existing file.
+	output, err := os.OpenFile(cmd.Output, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create the bundle: %w", err)
+	}
+
+	engine := lbengine.NewDeploymentEngine(dep, lbengine.Options{
+		Events:       recorder,
+		Architecture: cmd.Architecture,
+	})
+	if cmd.Flow == "" {
+		err = engine.Bundle(ctx, document, output)
+	} else {
+		err = writeExecutable(ctx, engine, document, output, cmd.Flow)
+	}
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = verifyBundle(cmd.Output)
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(cmd.Output))
+	}
+
+	return nil
+}
+
+// writeExecutable writes a self-extracting executable to output, using the
+// running executable as its stub. The bundle is staged in a temporary file
+// next to the output.
+func writeExecutable(ctx context.Context, engine lbengine.DeploymentEngine, document []byte, output *os.File, flow lbdeploy.FlowID) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+	stub, err := os.Open(exe)
+	if err != nil {
+		return fmt.Errorf("failed to open the running executable: %w", err)
+	}
+	defer stub.Close()
+
+	staged, err := os.CreateTemp(filepath.Dir(output.Name()), "leafbridge-bundle-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary bundle: %w", err)
+	}
+	defer os.Remove(staged.Name())
+	defer staged.Close()
+
+	if err := engine.Bundle(ctx, document, staged); err != nil {
+		return err
+	}
+	if _, err := staged.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return lbbundle.WriteExecutable(output, stub, staged, lbbundle.Launch{Flow: flow})
+}
+
+// verifyBundle opens the bundle at path and verifies all of its contents.
+func verifyBundle(path string) error {
+	bundle, err := lbbundle.Open(path)
+	if err != nil {
+		return err
+	}
+	defer bundle.Close()
+
+	return bundle.Verify()
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/platform/windows/controlpipe"
+)
+
+// CancelCmd cancels a running deployment through its control pipe.
+type CancelCmd struct {
+	Pipe string `kong:"optional,name='pipe',help='Path to the control pipe of the deployment to cancel. Defaults to the standard LeafBridge control pipe.'"`
+}
+
+// Run executes the cancel command.
+func (cmd CancelCmd) Run(ctx context.Context) error {
+	if err := controlpipe.Send(cmd.Pipe, controlpipe.VerbCancel); err != nil {
+		return err
+	}
+	fmt.Println("The deployment is being cancelled.")
+	return nil
+}
@@ -2,31 +2,68 @@ package main
 
 import (
 	"context"
-	"log/slog"
-	"os"
+	"errors"
+	"time"
 
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbbundle"
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
-	"github.com/leafbridge/leafbridge/core/lbdeployevent"
 	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbevent/catalog"
+	"github.com/leafbridge/leafbridge/core/lbprogress"
+	"github.com/leafbridge/leafbridge/platform/windows/controlpipe"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
 	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
-	"github.com/leafbridge/leafbridge/platform/windows/windowsevent"
+	"github.com/leafbridge/leafbridge/platform/windows/progresspipe"
 )
 
 // DeployCmd deploys software according to a LeafBridge deployment
 // configuration.
 type DeployCmd struct {
-	ConfigFile string          `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
-	Flow       lbdeploy.FlowID `kong:"required,name='flow',help='The flow to invoke within the deployment.'"`
-	Force      bool            `kong:"optional,name='force',help='Force processing of the commands that would normally be skipped.'"`
-	Verbose    bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	ConfigFile      string          `kong:"optional,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Bundle          string          `kong:"optional,name='bundle',help='Path to an offline bundle holding the deployment and its packages. No network requests are made.'"`
+	Flow            lbdeploy.FlowID `kong:"required,name='flow',help='The flow to invoke within the deployment.'"`
+	Force           bool            `kong:"optional,name='force',help='Force processing of the commands that would normally be skipped.'"`
+	Reverify        bool            `kong:"optional,name='reverify',help='Hash staged package files again instead of trusting the verification results of an earlier run.'"`
+	Resume          bool            `kong:"optional,name='resume',help='Continue flows that were interrupted by a crash or restart from their last completed action.'"`
+	Timeout         time.Duration   `kong:"optional,name='timeout',help='The maximum amount of time the deployment is allowed to run. Overrides the timeout of the deployment.'"`
+	Verbose         bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	TraceConditions bool            `kong:"optional,name='trace-conditions',help='Record a debug event for each condition that is evaluated, describing the values that were observed. Shown on the command line with --verbose.'"`
+	Locale          string          `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line, in the Windows event log and in progress updates. The de and fr locales are supported.'"`
+	EventFile       string          `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	ProgressPipe    string          `kong:"optional,name='progress-pipe',help='Stream the progress of the deployment as JSON to clients of the named pipe with this path.'"`
+	ProgressUI      string          `kong:"optional,name='progress-ui',help='Path to a program that presents the progress of the deployment to the signed-in user. It is started in the user session with a --pipe argument that names the progress pipe.'"`
+	StatusFile      string          `kong:"optional,name='status-file',help='Write the progress of the deployment as JSON to this file, including its predicted time of completion when earlier runs have been recorded.'"`
+	ControlPipe     string          `kong:"optional,name='control-pipe',help='Accept control requests, such as cancel, from administrators through the named pipe with this path.'"`
+	NoHostContext   bool            `kong:"optional,name='no-host-context',help='Omit host and session information from event records.'"`
+	AzureLog        AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF            GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
 }
 
 // Run executes the LeafBridge deploy command.
 func (cmd DeployCmd) Run(ctx context.Context) error {
-	// Read the deployment file.
-	dep, err := loadDeployment(cmd.ConfigFile)
-	if err != nil {
-		return err
+	// Read the deployment file, or open the bundle that holds it.
+	var (
+		dep    lbdeploy.Deployment
+		bundle *lbbundle.Bundle
+	)
+	switch {
+	case cmd.ConfigFile != "" && cmd.Bundle != "":
+		return errors.New("a deployment file and a bundle cannot both be provided")
+	case cmd.Bundle != "":
+		var err error
+		bundle, err = lbbundle.Open(cmd.Bundle)
+		if err != nil {
+			return err
+		}
+		defer bundle.Close()
+		dep = bundle.Deployment()
+	default:
+		var err error
+		dep, err = leafbridge.LoadDeployment(cmd.ConfigFile)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Select an event recorder.
@@ -38,34 +75,87 @@ func (cmd DeployCmd) Run(ctx context.Context) error {
 	*/
 
 	// Prepare an event registry.
-	events := lbevent.NewRegistry(startingEventID)
-	events.Add(lbdeployevent.Registrations...)
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+
+	// Prepare an event handler.
+	handler, closeHandler, err := newEventHandler(events, cmd.Verbose, cmd.Locale, cmd.EventFile, cmd.AzureLog, cmd.GELF)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+	recorder := lbevent.Recorder{Handler: handler}
+
+	// Describe the host in each event, so that forwarded events are
+	// self-describing.
+	if !cmd.NoHostContext {
+		recorder.Origin.Host = hostinfo.Collect()
+	}
 
-	// Attempt to use a Windows event handler, but carry on regardless if it
-	// doens't work out. The most likely reason it won't work is if the
-	// running process isn't elevated.
-	var handler lbevent.Handler
-	{
-		min := slog.LevelInfo
-		if cmd.Verbose {
-			min = slog.LevelDebug
+	// If requested, stream progress to clients of a named pipe. A user
+	// interface gets a pipe of its own when a name hasn't been provided.
+	pipeName := cmd.ProgressPipe
+	if pipeName == "" && cmd.ProgressUI != "" {
+		recorder.Origin.RunID = lbevent.NewRunID()
+		pipeName = progresspipe.RunName(recorder.Origin.RunID)
+	}
+	var publishers lbprogress.Publishers
+	if pipeName != "" {
+		server, err := progresspipe.Listen(pipeName)
+		if err != nil {
+			return err
 		}
-		basicHandler := lbevent.NewBasicHandler(os.Stdout, min)
-		windowsHandler, err := windowsevent.NewHandler(events)
+		defer server.Close()
+		publishers = append(publishers, server)
+	}
+
+	// If requested, write the progress to a status file as well.
+	if cmd.StatusFile != "" {
+		publishers = append(publishers, lbprogress.NewStatusFile(cmd.StatusFile))
+	}
+
+	// Track the progress of the deployment for its publishers. Durations
+	// recorded by earlier runs improve the estimates, and let the tracker
+	// predict when the deployment will finish.
+	if len(publishers) > 0 {
+		messages, err := catalog.Lookup(cmd.Locale)
 		if err != nil {
-			handler = basicHandler
-		} else {
-			handler = lbevent.MultiHandler{basicHandler, windowsHandler}
+			return err
 		}
+		weights := lbprogress.EstimateWeights(dep, lbengine.ActionDurations(dep))
+		recorder.Handler = lbevent.MultiHandler{handler, catalog.NewHandler(messages, lbprogress.NewTracker(dep, weights, publishers))}
 	}
-	recorder := lbevent.Recorder{Handler: handler}
 
-	// Prepare a new deployment engine for the deployment.
-	engine := lbengine.NewDeploymentEngine(dep, lbengine.Options{
-		Events: recorder,
-		Force:  cmd.Force,
-	})
+	// If requested, start a user interface that presents the progress. The
+	// deployment carries on without it if it can't be started.
+	if cmd.ProgressUI != "" {
+		startProgressUI(recorder, dep.ID, cmd.ProgressUI, pipeName)
+	}
+
+	// If requested, let administrators cancel the deployment through a
+	// named pipe.
+	if cmd.ControlPipe != "" {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		server, err := controlpipe.Listen(cmd.ControlPipe, cancel)
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+	}
 
 	// Invoke the requested flow within the deployment.
-	return engine.Invoke(ctx, cmd.Flow)
+	return leafbridge.Invoke(ctx, dep, cmd.Flow, leafbridge.Options{
+		Handler:         recorder.Handler,
+		Origin:          recorder.Origin,
+		Force:           cmd.Force,
+		Reverify:        cmd.Reverify,
+		Resume:          cmd.Resume,
+		Timeout:         cmd.Timeout,
+		Bundle:          bundle,
+		TraceConditions: cmd.TraceConditions,
+	})
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdetect"
+)
+
+// DetectionCmd generates detection scripts and detection rules for the
+// applications in a LeafBridge deployment, so that they can be detected
+// by Intune Win32 apps and Configuration Manager applications.
+type DetectionCmd struct {
+	ConfigFile string   `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Apps       []string `kong:"optional,name='app',help='Applications to detect. All applications that can be detected on Windows are included by default.'"`
+	Format     string   `kong:"optional,name='format',enum='script,rules',default='script',help='Format of the detection artifact (script or rules).'"`
+	Output     string   `kong:"optional,name='output',short='o',help='Path of the file to write. Standard output is used by default.'"`
+}
+
+// Run executes the LeafBridge detection command.
+func (cmd DetectionCmd) Run(ctx context.Context) error {
+	// Read the deployment file.
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	// Determine which applications to detect.
+	var apps lbdeploy.AppList
+	for _, app := range cmd.Apps {
+		apps = append(apps, lbdeploy.AppID(app))
+	}
+	if len(apps) == 0 {
+		apps = lbdetect.Detectable(dep)
+		if len(apps) == 0 {
+			return fmt.Errorf("the \"%s\" deployment does not have any applications that can be detected on Windows", dep.ID)
+		}
+	}
+
+	// Generate the detection artifact.
+	var out []byte
+	switch cmd.Format {
+	case "rules":
+		rules, err := lbdetect.Rules(dep, apps)
+		if err != nil {
+			return err
+		}
+		out, err = json.MarshalIndent(rules, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = append(out, '\n')
+	default:
+		script, err := lbdetect.Script(dep, apps)
+		if err != nil {
+			return err
+		}
+		out = []byte(script)
+	}
+
+	if cmd.Output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(cmd.Output, out, 0644)
+}
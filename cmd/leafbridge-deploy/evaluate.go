@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
+	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
+)
+
+// exitCodeDrift is the exit code used when the evaluation of a deployment
+// finds that the local system has drifted from its desired state.
+const exitCodeDrift = 2
+
+// errDrift is returned by the evaluate command when drift is detected.
+var errDrift = errors.New("the system is not in compliance with the deployment")
+
+// EvaluateCmd evaluates the compliance of the local system with the desired
+// state of a LeafBridge deployment, without making any changes.
+//
+// When remediation is enabled, flows that are marked for remediation on
+// drift are invoked when drift is detected. When an interval is provided,
+// the command runs as an agent that evaluates the deployment repeatedly
+// until it is interrupted.
+type EvaluateCmd struct {
+	ConfigFile    string          `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Remediate     bool            `kong:"optional,name='remediate',help='Invoke the flows that remediate drift when drift is detected.'"`
+	Interval      time.Duration   `kong:"optional,name='interval',help='Evaluate the deployment repeatedly at this interval until interrupted.'"`
+	Verbose       bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	Locale        string          `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line, in the Windows event log and in progress updates. The de and fr locales are supported.'"`
+	EventFile     string          `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	NoHostContext bool            `kong:"optional,name='no-host-context',help='Omit host and session information from event records.'"`
+	AzureLog      AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF          GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge evaluate command.
+//
+// The outcome of each evaluation is recorded as a compliance report event.
+// If drift remains after a single evaluation, an error is returned.
+func (cmd EvaluateCmd) Run(ctx context.Context) error {
+	if cmd.Interval < 0 {
+		return errors.New("a negative interval was provided")
+	}
+
+	// Read the deployment file.
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	// Prepare an event recorder.
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+	handler, closeHandler, err := newEventHandler(events, cmd.Verbose, cmd.Locale, cmd.EventFile, cmd.AzureLog, cmd.GELF)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+	recorder := lbevent.Recorder{Handler: handler}
+	if !cmd.NoHostContext {
+		recorder.Origin.Host = hostinfo.Collect()
+	}
+
+	// Evaluate the deployment once.
+	if cmd.Interval == 0 {
+		report, err := cmd.evaluate(ctx, dep, recorder)
+		if err != nil {
+			return err
+		}
+		if drift := report.Drift(); len(drift) > 0 {
+			return fmt.Errorf("%w: drift was detected in %d of %d checks", errDrift, len(drift), len(report.Checks))
+		}
+		return nil
+	}
+
+	// Evaluate the deployment repeatedly until interrupted. Failures are
+	// recorded as events and do not stop the agent.
+	ticker := time.NewTicker(cmd.Interval)
+	defer ticker.Stop()
+	for {
+		cmd.evaluate(ctx, dep, recorder)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// evaluate performs a single evaluation of the deployment, remediating
+// drift if requested.
+func (cmd EvaluateCmd) evaluate(ctx context.Context, dep lbdeploy.Deployment, recorder lbevent.Recorder) (lbdeploy.ComplianceReport, error) {
+	if cmd.Remediate {
+		engine := lbengine.NewDeploymentEngine(dep, lbengine.Options{
+			Events: recorder,
+			Agent:  true,
+		})
+		return engine.Remediate(ctx)
+	}
+
+	if err := dep.Validate(); err != nil {
+		return lbdeploy.ComplianceReport{}, err
+	}
+	report := lbengine.NewComplianceEngine(dep).Evaluate()
+	recorder.Record(lbdeployevent.ComplianceReport{
+		Deployment: dep.ID,
+		Report:     report,
+	})
+	return report, nil
+}
@@ -1,5 +1,97 @@
 package main
 
-// startingEventID is the start of the event ID number sequence for events
-// sent to the Windows event log.
-const startingEventID = 100
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbevent/catalog"
+	"github.com/leafbridge/leafbridge/platform/windows/windowsevent"
+)
+
+// newEventHandler returns an event handler that writes events to standard
+// output, to the Windows event log and, if configured, to an event file, to
+// Azure Log Analytics and to a GELF input. The returned function must be called to release
+// the handler when it is no longer needed.
+//
+// If an event file is provided, event records are appended to it as JSON,
+// one record per line. The file can be read by the replay command.
+//
+// If a locale is provided, messages written to standard output and to the
+// Windows event log are localized. Messages sent to Azure Log Analytics are
+// left in English, so that they can be queried consistently. The same is
+// true of messages sent to a GELF input.
+func newEventHandler(events *lbevent.Registry, verbose bool, locale, eventFile string, azureLog AzureLogOptions, gelfOpts GELFOptions) (lbevent.Handler, func(), error) {
+	// Look up the message catalog for the locale.
+	messages, err := catalog.Lookup(locale)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Attempt to use a Windows event handler, but carry on regardless if it
+	// doens't work out. The most likely reason it won't work is if the
+	// running process isn't elevated.
+	var handler lbevent.Handler
+	{
+		min := slog.LevelInfo
+		if verbose {
+			min = slog.LevelDebug
+		}
+		basicHandler := lbevent.NewBasicHandler(os.Stdout, min)
+		windowsHandler, err := windowsevent.NewHandler(events)
+		if err != nil {
+			handler = catalog.NewHandler(messages, basicHandler)
+		} else {
+			handler = catalog.NewHandler(messages, lbevent.MultiHandler{basicHandler, windowsHandler})
+		}
+	}
+
+	// Collect any additional handlers, along with the functions that
+	// release them.
+	handlers := lbevent.MultiHandler{handler}
+	var closers []func() error
+	closeAll := func() {
+		for _, release := range closers {
+			release()
+		}
+	}
+
+	// If requested, append event records to a file, so that they can be
+	// replayed later.
+	if eventFile != "" {
+		file, err := os.OpenFile(eventFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open the event file: %w", err)
+		}
+		handlers = append(handlers, lbevent.NewJSONHandler(file))
+		closers = append(closers, file.Close)
+	}
+
+	// If requested, send events to Azure Log Analytics as well.
+	if azureLog.Enabled() {
+		azureHandler, err := azureLog.NewHandler()
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		handlers = append(handlers, azureHandler)
+		closers = append(closers, azureHandler.Close)
+	}
+
+	// If requested, send events to a GELF input as well.
+	if gelfOpts.Enabled() {
+		gelfHandler, err := gelfOpts.NewHandler()
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		handlers = append(handlers, gelfHandler)
+		closers = append(closers, gelfHandler.Close)
+	}
+
+	if len(handlers) == 1 {
+		return handler, func() {}, nil
+	}
+	return handlers, closeAll, nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/leafbridge/leafbridge/core/lbevent/gelf"
+)
+
+// GELFOptions hold options for sending events to Graylog or another log
+// server that accepts GELF messages.
+type GELFOptions struct {
+	Address   string `kong:"optional,name='address',env='LEAFBRIDGE_GELF_ADDRESS',help='The host and port of a GELF input, such as graylog.example.com:12201. Events are sent to it when this is provided.'"`
+	Network   string `kong:"optional,name='network',env='LEAFBRIDGE_GELF_NETWORK',enum='udp,tcp',default='udp',help='The network used to reach the GELF input (udp or tcp).'"`
+	ChunkSize int    `kong:"optional,name='chunk-size',env='LEAFBRIDGE_GELF_CHUNK_SIZE',help='The maximum size of each UDP datagram. Larger messages are split into chunks.'"`
+}
+
+// Enabled returns true if events should be sent to a GELF input.
+func (opts GELFOptions) Enabled() bool {
+	return opts.Address != ""
+}
+
+// NewHandler returns a GELF event handler for the options.
+func (opts GELFOptions) NewHandler() (*gelf.Handler, error) {
+	return gelf.NewHandler(gelf.Config{
+		Address:   opts.Address,
+		Network:   opts.Network,
+		ChunkSize: opts.ChunkSize,
+	})
+}
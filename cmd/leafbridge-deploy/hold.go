@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbpolicy"
+	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
+)
+
+// HoldCmd manages the app holds of the local machine. Commands that would
+// modify a held app are skipped by every deployment.
+type HoldCmd struct {
+	Add    HoldAddCmd    `kong:"cmd,help='Places a hold on an app, so that deployments do not modify it.'"`
+	Remove HoldRemoveCmd `kong:"cmd,help='Removes the hold on an app.'"`
+	List   HoldListCmd   `kong:"cmd,help='Lists the apps that are held on this machine.'"`
+}
+
+// HoldAddCmd places a hold on an app.
+type HoldAddCmd struct {
+	App         lbdeploy.AppID       `kong:"optional,name='app',help='The ID of the app to hold.'"`
+	ProductCode lbdeploy.ProductCode `kong:"optional,name='product-code',help='The product code of the app to hold.'"`
+	Reason      string               `kong:"optional,name='reason',help='Why the app is held. It is included in the events of skipped commands.'"`
+}
+
+// Run executes the LeafBridge hold add command.
+func (cmd HoldAddCmd) Run(ctx context.Context) error {
+	hold := lbpolicy.Hold{
+		App:         cmd.App,
+		ProductCode: cmd.ProductCode,
+		Reason:      cmd.Reason,
+		Created:     time.Now(),
+	}
+	if err := hold.Validate(); err != nil {
+		return err
+	}
+
+	path, holds, err := loadMachineHolds()
+	if err != nil {
+		return err
+	}
+	holds = append(holds.Remove(cmd.App, cmd.ProductCode), hold)
+	if err := lbpolicy.SaveHolds(path, holds); err != nil {
+		return err
+	}
+
+	fmt.Printf("The %s app is now held.\n", hold)
+	return nil
+}
+
+// HoldRemoveCmd removes the hold on an app.
+type HoldRemoveCmd struct {
+	App         lbdeploy.AppID       `kong:"optional,name='app',help='The ID of the app to release.'"`
+	ProductCode lbdeploy.ProductCode `kong:"optional,name='product-code',help='The product code of the app to release.'"`
+}
+
+// Run executes the LeafBridge hold remove command.
+func (cmd HoldRemoveCmd) Run(ctx context.Context) error {
+	target := lbpolicy.Hold{App: cmd.App, ProductCode: cmd.ProductCode}
+	if err := target.Validate(); err != nil {
+		return err
+	}
+
+	path, holds, err := loadMachineHolds()
+	if err != nil {
+		return err
+	}
+	remaining := holds.Remove(cmd.App, cmd.ProductCode)
+	if len(remaining) == len(holds) {
+		return fmt.Errorf("the %s app is not held", target)
+	}
+	if err := lbpolicy.SaveHolds(path, remaining); err != nil {
+		return err
+	}
+
+	fmt.Printf("The %s app is no longer held.\n", target)
+	return nil
+}
+
+// HoldListCmd lists the app holds of the local machine.
+type HoldListCmd struct{}
+
+// Run executes the LeafBridge hold list command.
+//
+// Holds placed by the execution policy of the machine are listed as well.
+func (cmd HoldListCmd) Run(ctx context.Context) error {
+	_, holds, err := loadMachineHolds()
+	if err != nil {
+		return err
+	}
+	policy, err := lbengine.MachinePolicy()
+	if err != nil {
+		return err
+	}
+
+	if len(holds) == 0 && len(policy.Holds) == 0 {
+		fmt.Println("No apps are held.")
+		return nil
+	}
+
+	show := func(hold lbpolicy.Hold, source string) {
+		fmt.Printf("%s [%s]\n", hold, source)
+		if hold.Reason != "" {
+			fmt.Printf("  Reason:  %s\n", hold.Reason)
+		}
+		if !hold.Created.IsZero() {
+			fmt.Printf("  Created: %s\n", hold.Created.Format(time.RFC3339))
+		}
+	}
+	for _, hold := range policy.Holds {
+		show(hold, "policy")
+	}
+	for _, hold := range holds {
+		show(hold, "command line")
+	}
+
+	return nil
+}
+
+// loadMachineHolds returns the path of the app holds file of the machine,
+// along with the holds that it contains.
+func loadMachineHolds() (string, lbpolicy.HoldList, error) {
+	path, err := lbengine.MachineHoldsPath()
+	if err != nil {
+		return "", nil, err
+	}
+	holds, err := lbpolicy.LoadHolds(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, holds, nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/intunewin"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
+	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
+)
+
+// Names of the files within a Win32 app package.
+const (
+	intuneWinSetupFile  = "leafbridge-deploy.exe"
+	intuneWinBundleFile = "bundle.zip"
+)
+
+// IntuneWinCmd packages leafbridge-deploy and an offline bundle of a
+// deployment into a Win32 app package that can be uploaded to Intune.
+type IntuneWinCmd struct {
+	ConfigFile    string          `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Output        string          `kong:"required,name='output',short='o',help='Path of the .intunewin file to create.'"`
+	InstallFlow   lbdeploy.FlowID `kong:"required,name='install-flow',help='The flow that installs the deployment.'"`
+	UninstallFlow lbdeploy.FlowID `kong:"optional,name='uninstall-flow',help='The flow that uninstalls the deployment.'"`
+	Verbose       bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	Locale        string          `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line, in the Windows event log and in progress updates. The de and fr locales are supported.'"`
+	EventFile     string          `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	NoHostContext bool            `kong:"optional,name='no-host-context',help='Omit host and session information from event records.'"`
+	AzureLog      AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF          GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge intunewin command.
+//
+// The running executable and an offline bundle of the deployment are
+// staged in a temporary directory, which is then packaged. The install
+// and uninstall command lines for the Win32 app are printed when the
+// package has been written.
+func (cmd IntuneWinCmd) Run(ctx context.Context) error {
+	// Read the deployment file.
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+	document, err := os.ReadFile(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the flows exist before anything is downloaded.
+	for _, flow := range []lbdeploy.FlowID{cmd.InstallFlow, cmd.UninstallFlow} {
+		if flow == "" {
+			continue
+		}
+		if _, found := dep.Flows[flow]; !found {
+			return fmt.Errorf("the flow \"%s\" does not exist within the \"%s\" deployment", flow, dep.ID)
+		}
+	}
+
+	// Prepare an event recorder.
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+	handler, closeHandler, err := newEventHandler(events, cmd.Verbose, cmd.Locale, cmd.EventFile, cmd.AzureLog, cmd.GELF)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+	recorder := lbevent.Recorder{Handler: handler}
+	if !cmd.NoHostContext {
+		recorder.Origin.Host = hostinfo.Collect()
+	}
+
+	// Stage the contents of the package.
+	dir, err := os.MkdirTemp("", "leafbridge-intunewin-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a staging directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := copyExecutable(filepath.Join(dir, intuneWinSetupFile)); err != nil {
+		return err
+	}
+
+	bundlePath := filepath.Join(dir, intuneWinBundleFile)
+	bundle, err := os.Create(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to create the bundle: %w", err)
+	}
+	engine := lbengine.NewDeploymentEngine(dep, lbengine.Options{
+		Events: recorder,
+	})
+	err = engine.Bundle(ctx, document, bundle)
+	if closeErr := bundle.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := verifyBundle(bundlePath); err != nil {
+		return err
+	}
+
+	// Create the output file. Refuse to overwrite an existing file.
+	output, err := os.OpenFile(cmd.Output, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create the Win32 app package: %w", err)
+	}
+	err = intunewin.Write(output, intuneWinSetupFile, os.DirFS(dir))
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(cmd.Output))
+	}
+
+	// Suggest command lines for the Win32 app.
+	fmt.Printf("Install command:   %s\n", intuneWinCommandLine(cmd.InstallFlow))
+	if cmd.UninstallFlow != "" {
+		fmt.Printf("Uninstall command: %s\n", intuneWinCommandLine(cmd.UninstallFlow))
+	}
+	fmt.Printf("Detection:         leafbridge-deploy detection --config-file %s\n", quoteCommandArg(cmd.ConfigFile))
+
+	return nil
+}
+
+// copyExecutable copies the running executable to path.
+func copyExecutable(path string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+	source, err := os.Open(exe)
+	if err != nil {
+		return fmt.Errorf("failed to open the running executable: %w", err)
+	}
+	defer source.Close()
+
+	destination, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(destination, source)
+	if closeErr := destination.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// intuneWinCommandLine returns a command line that invokes a flow from
+// the bundle within a Win32 app package.
+func intuneWinCommandLine(flow lbdeploy.FlowID) string {
+	return fmt.Sprintf("%s deploy --bundle %s --flow %s", intuneWinSetupFile, intuneWinBundleFile, quoteCommandArg(string(flow)))
+}
+
+// quoteCommandArg returns s quoted for use on a command line if it
+// contains spaces or quotation marks.
+func quoteCommandArg(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
+	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
+)
+
+// LogonCmd runs the flows that deployments have registered to run when
+// each user logs on. It is started by Windows at logon, in the context of
+// the user that logged on.
+type LogonCmd struct {
+	Verbose       bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	Locale        string          `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line and in the Windows event log. The de and fr locales are supported.'"`
+	EventFile     string          `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	NoHostContext bool            `kong:"optional,name='no-host-context',help='Omit host and session information from event records.'"`
+	AzureLog      AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF          GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge logon command.
+func (cmd LogonCmd) Run(ctx context.Context) error {
+	// Prepare an event registry.
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+
+	// Prepare an event handler.
+	handler, closeHandler, err := newEventHandler(events, cmd.Verbose, cmd.Locale, cmd.EventFile, cmd.AzureLog, cmd.GELF)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+	recorder := lbevent.Recorder{Handler: handler}
+
+	// Describe the host in each event, so that forwarded events are
+	// self-describing.
+	if !cmd.NoHostContext {
+		recorder.Origin.Host = hostinfo.Collect()
+	}
+
+	// Run each registered flow that the user hasn't run yet.
+	return lbengine.RunLogonFlows(ctx, lbengine.Options{Events: recorder})
+}
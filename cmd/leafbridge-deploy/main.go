@@ -4,11 +4,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/alecthomas/kong"
+	"github.com/leafbridge/leafbridge/core/lbbundle"
+	"github.com/leafbridge/leafbridge/core/lberror"
 )
 
 func main() {
@@ -16,9 +19,24 @@ func main() {
 	defer stop()
 
 	var cli struct {
-		Deploy  DeployCmd  `kong:"cmd,help='Deploys a particular software package.'"`
-		Show    ShowCmd    `kong:"cmd,help='Shows information about a deployment.'"`
-		Version VersionCmd `kong:"cmd,help='Display leafbridge-deploy version information.'"`
+		Deploy    DeployCmd    `kong:"cmd,help='Deploys a particular software package.'"`
+		Resume    ResumeCmd    `kong:"cmd,help='Resumes a deployment that was interrupted by a crash or restart.'"`
+		Cancel    CancelCmd    `kong:"cmd,help='Cancels a deployment that accepts control requests through a named pipe.'"`
+		Rollout   RolloutCmd   `kong:"cmd,help='Deploys a sequence of deployments described by a rollout file.'"`
+		Apply     ApplyCmd     `kong:"cmd,help='Applies the deployments assigned to this machine by an assignment manifest.'"`
+		Bundle    BundleCmd    `kong:"cmd,help='Packages a deployment and its packages into an offline bundle.'"`
+		IntuneWin IntuneWinCmd `kong:"cmd,name='intunewin',help='Packages leafbridge-deploy and a deployment bundle as an Intune Win32 app.'"`
+		Evaluate  EvaluateCmd  `kong:"cmd,help='Evaluates the compliance of the local system with a deployment without making changes.'"`
+		Plan      PlanCmd      `kong:"cmd,help='Shows which actions, commands, downloads and extractions a flow would perform, without making changes.'"`
+		Approve   ApproveCmd   `kong:"cmd,help='Signs a deployment on behalf of one of its approvers.'"`
+		Hold      HoldCmd      `kong:"cmd,help='Manages the apps that deployments are not permitted to modify on this machine.'"`
+		Logon     LogonCmd     `kong:"cmd,help='Runs the flows that deployments have registered to run when a user logs on.'"`
+		Show      ShowCmd      `kong:"cmd,help='Shows information about a deployment.'"`
+		Detection DetectionCmd `kong:"cmd,help='Generates a detection script or detection rules for Intune and Configuration Manager.'"`
+		Replay    ReplayCmd    `kong:"cmd,help='Replays stored event records as text, CSV or JSON, or sends them to an event log.'"`
+		Test      TestCmd      `kong:"cmd,help='Tests a deployment against simulated systems.'"`
+		Update    UpdateCmd    `kong:"cmd,help='Updates leafbridge-deploy to the latest published build.'"`
+		Version   VersionCmd   `kong:"cmd,help='Display leafbridge-deploy version information.'"`
 	}
 
 	parser := kong.Must(&cli,
@@ -26,9 +44,42 @@ func main() {
 		kong.BindTo(ctx, (*context.Context)(nil)),
 		kong.UsageOnError())
 
-	app, parseErr := parser.Parse(os.Args[1:])
+	app, parseErr := parser.Parse(launchArgs(os.Args[1:]))
 	parser.FatalIfErrorf(parseErr)
 
 	appErr := app.Run()
+	if errors.Is(appErr, errDrift) {
+		app.Errorf("%s", appErr)
+		os.Exit(exitCodeDrift)
+	}
+	if category := lberror.CategoryOf(appErr); category != lberror.Unknown {
+		app.Errorf("%s", appErr)
+		os.Exit(category.ExitCode())
+	}
 	app.FatalIfErrorf(appErr)
 }
+
+// launchArgs returns the command line arguments to parse. When a
+// self-extracting executable is launched without arguments, it deploys its
+// embedded bundle.
+func launchArgs(args []string) []string {
+	if len(args) > 0 {
+		return args
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return args
+	}
+	bundle, err := lbbundle.Open(exe)
+	if err != nil {
+		return args
+	}
+	launch := bundle.Launch()
+	bundle.Close()
+	if launch.Flow == "" {
+		return args
+	}
+
+	return []string{"deploy", "--bundle", exe, "--flow", string(launch.Flow)}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// PlanCmd describes what a flow within a LeafBridge deployment would do on
+// the local system, without changing it.
+type PlanCmd struct {
+	ConfigFile string          `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Flow       lbdeploy.FlowID `kong:"required,name='flow',help='The flow to plan within the deployment.'"`
+	Force      bool            `kong:"optional,name='force',help='Plan the commands that would normally be skipped, as the deploy command does when it is forced.'"`
+}
+
+// Run executes the LeafBridge plan command.
+func (cmd PlanCmd) Run(ctx context.Context) error {
+	// Read the deployment file.
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("---- %s (%s): Plan for the \"%s\" flow ----\n", dep.Name, cmd.ConfigFile, cmd.Flow)
+
+	// Walk the flow and print each step of the plan. If the flow would
+	// fail, the steps leading up to the failure are printed first.
+	steps, err := leafbridge.Plan(ctx, dep, cmd.Flow, leafbridge.Options{Force: cmd.Force})
+	for _, step := range steps {
+		fmt.Printf("  %s\n", step)
+	}
+	if err != nil {
+		return fmt.Errorf("the flow would fail: %w", err)
+	}
+
+	if len(steps) == 0 {
+		fmt.Printf("  The flow has no actions.\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/usersession"
+)
+
+// startProgressUI starts the progress user interface at path in the
+// session of the signed-in user, passing it the name of the progress pipe.
+// The outcome is recorded as an event.
+func startProgressUI(events lbevent.Recorder, dep lbdeploy.DeploymentID, path, pipe string) {
+	process, err := usersession.Launch(path, "--pipe", pipe)
+	events.Record(lbdeployevent.ProgressUI{
+		Deployment: dep,
+		Path:       path,
+		Pipe:       pipe,
+		Session:    process.Session,
+		ProcessID:  process.ID,
+		AsCaller:   process.AsCaller,
+		NoSession:  errors.Is(err, usersession.ErrNoSession),
+		Err:        err,
+	})
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/windowsevent"
+)
+
+// maxRecordSize is the maximum size of a single event record that can be
+// replayed.
+const maxRecordSize = 16 * 1024 * 1024
+
+// ReplayCmd reads event records that were stored as JSON lines, filters
+// them, and renders them as text, CSV or JSON. It can also send them to
+// the Windows event log, to Azure Log Analytics or to a GELF input, so that
+// events that could not be delivered at the time can be shipped after the
+// fact.
+type ReplayCmd struct {
+	Files           []string        `kong:"arg,required,name='file',help='Paths to event files written with --event-file. Use - to read from standard input.'"`
+	Types           []string        `kong:"optional,name='type',help='Only replay events with types that match these patterns, such as deployment.flow:*.'"`
+	Level           string          `kong:"optional,name='level',enum='debug,info,warn,error',default='debug',help='Only replay events at or above this level.'"`
+	Since           time.Time       `kong:"optional,name='since',help='Only replay events recorded at or after this time (RFC 3339).'"`
+	Until           time.Time       `kong:"optional,name='until',help='Only replay events recorded before this time (RFC 3339).'"`
+	RunID           lbevent.RunID   `kong:"optional,name='run-id',help='Only replay events from the invocation with this run ID.'"`
+	Format          string          `kong:"optional,name='format',enum='text,csv,json,none',default='text',help='Format of the replayed events (text, csv, json or none).'"`
+	Details         bool            `kong:"optional,name='details',help='Include event details in text output.'"`
+	Output          string          `kong:"optional,name='output',short='o',help='Path of the file to write. Standard output is used by default.'"`
+	WindowsEventLog bool            `kong:"optional,name='windows-event-log',help='Send the replayed events to the Windows event log.'"`
+	AzureLog        AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF            GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge replay command.
+func (cmd ReplayCmd) Run(ctx context.Context) error {
+	// Prepare an event registry.
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+
+	// Prepare the filter.
+	var min slog.Level
+	if err := min.UnmarshalText([]byte(cmd.Level)); err != nil {
+		return err
+	}
+	for _, pattern := range cmd.Types {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("the \"%s\" event type pattern is not valid: %w", pattern, err)
+		}
+	}
+	match := func(r lbevent.Record) bool {
+		switch {
+		case r.Level() < min:
+			return false
+		case !cmd.Since.IsZero() && r.Time().Before(cmd.Since):
+			return false
+		case !cmd.Until.IsZero() && !r.Time().Before(cmd.Until):
+			return false
+		case cmd.RunID != "" && r.Origin().RunID != cmd.RunID:
+			return false
+		case len(cmd.Types) == 0:
+			return true
+		}
+		for _, pattern := range cmd.Types {
+			if matched, _ := path.Match(pattern, string(r.Type())); matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Prepare the output.
+	out := io.Writer(os.Stdout)
+	if cmd.Output != "" {
+		file, err := os.Create(cmd.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	writer := bufio.NewWriter(out)
+	defer writer.Flush()
+
+	// Prepare the handlers that replayed events are sent to.
+	var handlers lbevent.MultiHandler
+	switch cmd.Format {
+	case "text":
+		handlers = append(handlers, replayTextHandler{w: writer, details: cmd.Details})
+	case "csv":
+		handler := newReplayCSVHandler(writer, events)
+		defer handler.Flush()
+		handlers = append(handlers, handler)
+	case "json":
+		handlers = append(handlers, lbevent.NewJSONHandler(writer))
+	}
+	if cmd.WindowsEventLog {
+		handler, err := windowsevent.NewHandler(events)
+		if err != nil {
+			return err
+		}
+		defer handler.Close()
+		handlers = append(handlers, handler)
+	}
+	if cmd.AzureLog.Enabled() {
+		handler, err := cmd.AzureLog.NewHandler()
+		if err != nil {
+			return err
+		}
+		defer handler.Close()
+		handlers = append(handlers, handler)
+	}
+	if cmd.GELF.Enabled() {
+		handler, err := cmd.GELF.NewHandler()
+		if err != nil {
+			return err
+		}
+		defer handler.Close()
+		handlers = append(handlers, handler)
+	}
+
+	// Replay the records in each file.
+	var replayed, skipped int
+	for _, name := range cmd.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, s, err := replayFile(ctx, name, events, match, handlers)
+		replayed += n
+		skipped += s
+		if err != nil {
+			return err
+		}
+	}
+
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Replayed %d events. Skipped %d records that could not be read.\n", replayed, skipped)
+	}
+
+	return nil
+}
+
+// replayFile reads the event records in the named file and sends those
+// that match to handler. It returns the number of records that were
+// replayed and the number of records that were skipped because they could
+// not be read.
+//
+// Records with event types that are not recognized are skipped, so that
+// files written by newer versions of leafbridge-deploy can be replayed.
+func replayFile(ctx context.Context, name string, events *lbevent.Registry, match func(lbevent.Record) bool, handler lbevent.Handler) (replayed, skipped int, err error) {
+	var in io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, maxRecordSize)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return replayed, skipped, err
+		}
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		record, err := events.UnmarshalRecord(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s:%d: %v\n", name, line, err)
+			skipped++
+			continue
+		}
+		if !match(record) {
+			continue
+		}
+		if err := handler.Handle(record); err != nil {
+			return replayed, skipped, err
+		}
+		replayed++
+	}
+	if err := scanner.Err(); err != nil {
+		return replayed, skipped, fmt.Errorf("failed to read \"%s\": %w", name, err)
+	}
+
+	return replayed, skipped, nil
+}
+
+// replayTextHandler writes replayed events as text, in the same format that
+// is used for events on the command line.
+type replayTextHandler struct {
+	w       io.Writer
+	details bool
+}
+
+// Name returns a name for the handler.
+func (h replayTextHandler) Name() string {
+	return "replay-text"
+}
+
+// Handle processes the given event record.
+func (h replayTextHandler) Handle(r lbevent.Record) error {
+	if _, err := fmt.Fprintf(h.w, "%s: %-6s %s\n", r.Time().Local().Format(time.DateTime), r.Level().String()+":", r.Message()); err != nil {
+		return err
+	}
+	if details := r.Details(); h.details && details != "" {
+		if _, err := fmt.Fprintf(h.w, "%s\n\n", details); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayCSVHandler writes replayed events as CSV records.
+type replayCSVHandler struct {
+	w      *csv.Writer
+	events *lbevent.Registry
+}
+
+// newReplayCSVHandler returns a CSV handler that writes to w. It writes a
+// header row immediately.
+func newReplayCSVHandler(w io.Writer, events *lbevent.Registry) replayCSVHandler {
+	h := replayCSVHandler{w: csv.NewWriter(w), events: events}
+	h.w.Write([]string{"time", "level", "type", "event-id", "run-id", "machine", "message", "details"})
+	return h
+}
+
+// Name returns a name for the handler.
+func (h replayCSVHandler) Name() string {
+	return "replay-csv"
+}
+
+// Handle processes the given event record.
+func (h replayCSVHandler) Handle(r lbevent.Record) error {
+	var id string
+	if eid, ok := h.events.EventID(r.Type()); ok {
+		id = strconv.Itoa(int(eid))
+	}
+	return h.w.Write([]string{
+		r.Time().UTC().Format(time.RFC3339Nano),
+		r.Level().String(),
+		string(r.Type()),
+		id,
+		string(r.Origin().RunID),
+		r.Origin().Machine,
+		r.Message(),
+		r.Details(),
+	})
+}
+
+// Flush writes any buffered CSV data to the underlying writer.
+func (h replayCSVHandler) Flush() {
+	h.w.Flush()
+}
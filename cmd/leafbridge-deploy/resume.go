@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbbundle"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
+)
+
+// ResumeCmd continues a deployment that was interrupted by a crash or a
+// restart of the system, starting from the last action that completed.
+type ResumeCmd struct {
+	ConfigFile string          `kong:"optional,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Bundle     string          `kong:"optional,name='bundle',help='Path to an offline bundle holding the deployment and its packages. No network requests are made.'"`
+	Timeout    time.Duration   `kong:"optional,name='timeout',help='The maximum amount of time the deployment is allowed to run. Overrides the timeout of the deployment.'"`
+	Verbose    bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	Locale     string          `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line, in the Windows event log and in progress updates. The de and fr locales are supported.'"`
+	EventFile  string          `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	AzureLog   AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF       GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge resume command.
+func (cmd ResumeCmd) Run(ctx context.Context) error {
+	// Read the deployment file, or the deployment within the bundle.
+	var dep lbdeploy.Deployment
+	switch {
+	case cmd.ConfigFile != "" && cmd.Bundle != "":
+		return errors.New("a deployment file and a bundle cannot both be provided")
+	case cmd.Bundle != "":
+		bundle, err := lbbundle.Open(cmd.Bundle)
+		if err != nil {
+			return err
+		}
+		dep = bundle.Deployment()
+		bundle.Close()
+	default:
+		var err error
+		dep, err = leafbridge.LoadDeployment(cmd.ConfigFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Find the flow that was interrupted.
+	flow, found := lbengine.InterruptedFlow(dep)
+	if !found {
+		fmt.Printf("The \"%s\" deployment does not have an interrupted flow to resume.\n", dep.ID)
+		return nil
+	}
+
+	// Invoke the flow again, continuing from its checkpoint.
+	return DeployCmd{
+		ConfigFile: cmd.ConfigFile,
+		Bundle:     cmd.Bundle,
+		Flow:       flow,
+		Resume:     true,
+		Timeout:    cmd.Timeout,
+		Verbose:    cmd.Verbose,
+		Locale:     cmd.Locale,
+		EventFile:  cmd.EventFile,
+		AzureLog:   cmd.AzureLog,
+		GELF:       cmd.GELF,
+	}.Run(ctx)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
+	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
+)
+
+// RolloutCmd deploys a sequence of deployments according to a LeafBridge
+// rollout file.
+type RolloutCmd struct {
+	RolloutFile   string          `kong:"required,name='rollout-file',help='Path to a rollout file listing the deployments to invoke.'"`
+	Force         bool            `kong:"optional,name='force',help='Force processing of the commands that would normally be skipped.'"`
+	Reverify      bool            `kong:"optional,name='reverify',help='Hash staged package files again instead of trusting the verification results of an earlier run.'"`
+	Timeout       time.Duration   `kong:"optional,name='timeout',help='The maximum amount of time each deployment is allowed to run. Overrides the timeout of the deployments.'"`
+	Verbose       bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	Locale        string          `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line, in the Windows event log and in progress updates. The de and fr locales are supported.'"`
+	EventFile     string          `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	NoHostContext bool            `kong:"optional,name='no-host-context',help='Omit host and session information from event records.'"`
+	AzureLog      AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF          GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge rollout command.
+//
+// All of the deployment files are loaded and validated before the first
+// step is invoked.
+func (cmd RolloutCmd) Run(ctx context.Context) error {
+	// Read the rollout file and its deployment files.
+	rollout, deployments, err := leafbridge.LoadRollout(cmd.RolloutFile)
+	if err != nil {
+		return err
+	}
+
+	// Prepare an event recorder.
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+	handler, closeHandler, err := newEventHandler(events, cmd.Verbose, cmd.Locale, cmd.EventFile, cmd.AzureLog, cmd.GELF)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+	recorder := lbevent.Recorder{Handler: handler}
+	if !cmd.NoHostContext {
+		recorder.Origin.Host = hostinfo.Collect()
+	}
+
+	// Invoke each step of the rollout. The outcome of each step is
+	// recorded as an event, followed by a summary of the rollout.
+	engine := lbengine.NewRolloutEngine(rollout, deployments, lbengine.Options{
+		Events:   recorder,
+		Force:    cmd.Force,
+		Reverify: cmd.Reverify,
+		Timeout:  cmd.Timeout,
+	})
+	_, err = engine.Invoke(ctx)
+
+	return err
+}
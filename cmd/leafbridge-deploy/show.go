@@ -8,13 +8,17 @@ import (
 	"os"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/gentlemanautomaton/winobj/winmutex"
-	"github.com/leafbridge/leafbridge/core/lbdeployevent"
-	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbeval"
 	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
 	"github.com/leafbridge/leafbridge/platform/windows/localfs"
 	"github.com/leafbridge/leafbridge/platform/windows/localregistry"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+	"github.com/leafbridge/leafbridge/platform/windows/winplatform"
 )
 
 // ShowCmd shows information that is relevant to a LeafBridge deployment.
@@ -24,6 +28,7 @@ type ShowCmd struct {
 	Apps       ShowAppsCmd       `kong:"cmd,help='Shows the installation status of applications for a deployment.'"`
 	Conditions ShowConditionsCmd `kong:"cmd,help='Shows the current conditions for a deployment.'"`
 	Resources  ShowResourcesCmd  `kong:"cmd,help='Shows the relevant resources for a deployment.'"`
+	State      ShowStateCmd      `kong:"cmd,help='Shows the staged packages, backups, state and temporary files that deployments have left on this machine.'"`
 }
 
 // ShowEventTypesCmd shows a list of event types that can be recorded.
@@ -31,8 +36,10 @@ type ShowEventTypesCmd struct{}
 
 // Run executes the LeafBridge show event-types command.
 func (cmd ShowEventTypesCmd) Run(ctx context.Context) error {
-	events := lbevent.NewRegistry(startingEventID)
-	events.Add(lbdeployevent.Registrations...)
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
 	for _, eventType := range events.Types() {
 		eventID, _ := events.EventID(eventType)
 		fmt.Printf("%00d: %s\n", eventID, eventType)
@@ -48,7 +55,7 @@ type ShowConfigCmd struct {
 // Run executes the LeafBridge show config command.
 func (cmd ShowConfigCmd) Run(ctx context.Context) error {
 	// Read the deployment file.
-	dep, err := loadDeployment(cmd.ConfigFile)
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
 	if err != nil {
 		return err
 	}
@@ -75,7 +82,7 @@ type ShowAppsCmd struct {
 // Run executes the LeafBridge show apps command.
 func (cmd ShowAppsCmd) Run(ctx context.Context) error {
 	// Read the deployment file.
-	dep, err := loadDeployment(cmd.ConfigFile)
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
 	if err != nil {
 		return err
 	}
@@ -173,12 +180,13 @@ func (cmd ShowAppsCmd) showAll() bool {
 // LeafBridge deployment.
 type ShowConditionsCmd struct {
 	ConfigFile string `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Explain    bool   `kong:"optional,name='explain',help='Explain what each condition checks, the values that were observed and the comparison that was performed.'"`
 }
 
 // Run executes the LeafBridge show conditions command.
 func (cmd ShowConditionsCmd) Run(ctx context.Context) error {
 	// Read the deployment file.
-	dep, err := loadDeployment(cmd.ConfigFile)
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
 	if err != nil {
 		return err
 	}
@@ -191,8 +199,15 @@ func (cmd ShowConditionsCmd) Run(ctx context.Context) error {
 
 	fmt.Printf("---- %s (%s): Conditions ----\n", dep.Name, cmd.ConfigFile)
 
-	// Prepare a condition engine.
+	// Prepare a condition engine. When explaining, keep track of what it
+	// observes.
+	var trace []lbeval.Observation
 	ce := lbengine.NewConditionEngine(dep)
+	if cmd.Explain {
+		ce = ce.WithTracer(func(o lbeval.Observation) {
+			trace = append(trace, o)
+		})
+	}
 
 	// Sort the condition IDs for a deterministic order.
 	ids := slices.Collect(maps.Keys(dep.Conditions))
@@ -200,12 +215,18 @@ func (cmd ShowConditionsCmd) Run(ctx context.Context) error {
 
 	// Print the status of each condition.
 	for _, id := range ids {
+		trace = trace[:0]
 		result, err := ce.Evaluate(id)
 		if err != nil {
 			fmt.Printf("    %s: %s\n", id, err)
 		} else {
 			fmt.Printf("    %s: %t\n", id, result)
 		}
+		if cmd.Explain {
+			for _, line := range lbeval.Explain(id, trace) {
+				fmt.Printf("        %s\n", line)
+			}
+		}
 	}
 
 	return nil
@@ -220,7 +241,7 @@ type ShowResourcesCmd struct {
 // Run executes the LeafBridge show resources command.
 func (cmd ShowResourcesCmd) Run(ctx context.Context) error {
 	// Read the deployment file.
-	dep, err := loadDeployment(cmd.ConfigFile)
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
 	if err != nil {
 		return err
 	}
@@ -249,7 +270,7 @@ func (cmd ShowResourcesCmd) Run(ctx context.Context) error {
 				fmt.Printf("      Description: %s\n", process.Description)
 
 				// Look for running processes that match the criteria.
-				total, err := lbengine.NumberOfRunningProcesses(process.Match)
+				total, err := winplatform.New().Processes().NumberOfRunningProcesses(process.Match)
 				if err != nil {
 					fmt.Printf("      Running:     (%v)\n", process.Description)
 					return
@@ -544,3 +565,48 @@ func (cmd ShowResourcesCmd) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// ShowStateCmd shows the artifacts that deployments have left on the local
+// system.
+type ShowStateCmd struct {
+	Deployment lbdeploy.DeploymentID `kong:"optional,name='deployment',help='Only show the artifacts of this deployment.'"`
+}
+
+// Run executes the LeafBridge show state command.
+func (cmd ShowStateCmd) Run(ctx context.Context) error {
+	artifacts, err := stagingfs.Artifacts(cmd.Deployment)
+	if err != nil {
+		return err
+	}
+
+	if len(artifacts) == 0 {
+		fmt.Println("No deployment artifacts were found.")
+		return nil
+	}
+
+	var current lbdeploy.DeploymentID
+	for i, artifact := range artifacts {
+		if i == 0 || artifact.Deployment != current {
+			current = artifact.Deployment
+			fmt.Printf("---- %s ----\n", current)
+		}
+		fmt.Printf("    %-10s  %s\n", artifact.Kind, artifact.Path)
+		if artifact.Run != "" {
+			fmt.Printf("      Run:      %s\n", artifact.Run)
+		}
+		fmt.Printf("      Size:     %d bytes in %d %s\n", artifact.Size, artifact.Files, pluralFiles(artifact.Files))
+		if !artifact.Modified.IsZero() {
+			fmt.Printf("      Modified: %s\n", artifact.Modified.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// pluralFiles returns "file" or "files" for the given count.
+func pluralFiles(count int) string {
+	if count == 1 {
+		return "file"
+	}
+	return "files"
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+)
+
+// TestCmd runs a deployment against simulated systems described by fixture
+// files, and reports whether the expected actions would run.
+type TestCmd struct {
+	ConfigFile string   `kong:"required,name='config-file',help='Path to a deployment file describing the deployment.'"`
+	Fixtures   []string `kong:"arg,required,name='fixture-file',help='Paths to fixture files that describe simulated systems and expected outcomes.'"`
+	Verbose    bool     `kong:"optional,name='verbose',short='v',help='Print each simulated action.'"`
+}
+
+// Run executes the LeafBridge test command.
+func (cmd TestCmd) Run(ctx context.Context) error {
+	// Read the deployment file.
+	dep, err := leafbridge.LoadDeployment(cmd.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	var total, failed int
+	for _, path := range cmd.Fixtures {
+		fixtures, err := loadFixtures(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		for i, fixture := range fixtures {
+			name := fixture.Name
+			if name == "" {
+				name = fmt.Sprintf("%s [%d]", path, i+1)
+			}
+
+			steps, err := leafbridge.Test(ctx, dep, fixture)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			total++
+			problems := fixture.Expect.Check(steps, err)
+			if len(problems) == 0 {
+				fmt.Printf("PASS %s\n", name)
+			} else {
+				failed++
+				fmt.Printf("FAIL %s\n", name)
+				for _, problem := range problems {
+					fmt.Printf("  %s\n", problem)
+				}
+			}
+
+			if cmd.Verbose || len(problems) > 0 {
+				for _, step := range steps {
+					fmt.Printf("    %s\n", step)
+				}
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures failed", failed, total)
+	}
+
+	return nil
+}
+
+// loadFixtures reads a fixture file, which holds either a single fixture or
+// an array of fixtures.
+func loadFixtures(path string) ([]lbtest.Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []lbtest.Fixture
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &fixtures)
+	} else {
+		var fixture lbtest.Fixture
+		err = json.Unmarshal(data, &fixture)
+		fixtures = append(fixtures, fixture)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fixtures, nil
+}
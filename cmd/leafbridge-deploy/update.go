@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbupdate"
+	"github.com/leafbridge/leafbridge/core/lbupdateevent"
+	"github.com/leafbridge/leafbridge/internal/buildinfo"
+	"github.com/leafbridge/leafbridge/platform/windows/authenticode"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
+	"github.com/leafbridge/leafbridge/platform/windows/winservice"
+)
+
+// defaultServiceStopTimeout is the amount of time that the update command
+// waits for a service to stop before giving up on restarting it.
+const defaultServiceStopTimeout = 2 * time.Minute
+
+// UpdateCmd updates leafbridge-deploy to the latest build published to a
+// release channel.
+type UpdateCmd struct {
+	Channel          string          `kong:"required,name='channel',help='URL of the manifest of the release channel.'"`
+	SignerThumbprint string          `kong:"optional,name='signer-thumbprint',help='SHA-1 thumbprint of the certificate that builds must be signed with. Defaults to the signer of the running executable.'"`
+	RestartService   string          `kong:"optional,name='restart-service',help='Name of a service to restart once the update has been installed.'"`
+	Force            bool            `kong:"optional,name='force',help='Reinstall the published build even if it is the same version as the running build.'"`
+	AllowDowngrade   bool            `kong:"optional,name='allow-downgrade',help='Install the published build even if it is older than the running build.'"`
+	Verbose          bool            `kong:"optional,name='verbose',short='v',help='Show debug messages on the command line.'"`
+	Locale           string          `kong:"optional,name='locale',env='LEAFBRIDGE_LOCALE',help='Localize event messages on the command line, in the Windows event log and in progress updates. The de and fr locales are supported.'"`
+	EventFile        string          `kong:"optional,name='event-file',env='LEAFBRIDGE_EVENT_FILE',help='Append event records as JSON lines to this file, so that they can be replayed later.'"`
+	NoHostContext    bool            `kong:"optional,name='no-host-context',help='Omit host and session information from event records.'"`
+	AzureLog         AzureLogOptions `kong:"embed,prefix='azure-log-',group='Azure Log Analytics'"`
+	GELF             GELFOptions     `kong:"embed,prefix='gelf-',group='Graylog'"`
+}
+
+// Run executes the LeafBridge update command.
+//
+// The published build is downloaded next to the running executable, its
+// hash and Authenticode signature are verified, and it is then swapped
+// into place. The previous executable is kept as a backup until the next
+// update.
+func (cmd UpdateCmd) Run(ctx context.Context) error {
+	// Prepare an event recorder.
+	events, err := leafbridge.NewEventRegistry()
+	if err != nil {
+		return err
+	}
+	handler, closeHandler, err := newEventHandler(events, cmd.Verbose, cmd.Locale, cmd.EventFile, cmd.AzureLog, cmd.GELF)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+	recorder := lbevent.Recorder{Handler: handler}
+	if !cmd.NoHostContext {
+		recorder.Origin.Host = hostinfo.Collect()
+	}
+
+	// Locate the running executable.
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+
+	// Clean up after a previous update. This fails harmlessly if the
+	// previous executable is still running.
+	lbupdate.RemoveBackup(exe)
+
+	// Determine the version of the running executable.
+	current := buildinfo.Version("0.0.0.0")
+	if info, ok := debug.ReadBuildInfo(); ok {
+		current = buildinfo.GetVersion(info)
+	}
+
+	// Check the release channel.
+	manifest, err := lbupdate.FetchManifest(ctx, http.DefaultClient, cmd.Channel)
+	recorder.Record(lbupdateevent.UpdateCheck{
+		Channel:   cmd.Channel,
+		Current:   current,
+		Available: manifest.Version,
+		Err:       err,
+	})
+	if err != nil {
+		return err
+	}
+	switch comparison := manifest.Version.Compare(current); {
+	case comparison < 0 && !cmd.AllowDowngrade:
+		return nil
+	case comparison == 0 && !cmd.Force:
+		return nil
+	}
+
+	build, err := manifest.Build(lbupdate.Platform())
+	if err != nil {
+		return err
+	}
+
+	// Determine which certificate the build must be signed with before
+	// downloading anything.
+	expected := normalizeThumbprint(cmd.SignerThumbprint)
+	if expected == "" {
+		signer, err := authenticode.Verify(exe)
+		if err != nil {
+			return fmt.Errorf("the expected signer of the build could not be determined from the running executable, and a signer thumbprint was not provided: %w", err)
+		}
+		expected = normalizeThumbprint(signer.Thumbprint)
+	}
+
+	// Download the build next to the running executable, so that it can be
+	// moved into place without crossing volumes.
+	download := exe + ".new"
+	if err := os.Remove(download); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove a previous download: %w", err)
+	}
+	started := time.Now()
+	size, err := lbupdate.Download(ctx, http.DefaultClient, build, download)
+	recorder.Record(lbupdateevent.UpdateDownload{
+		Version:  manifest.Version,
+		URL:      build.URL,
+		Path:     download,
+		Size:     size,
+		Duration: time.Since(started),
+		Err:      err,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Verify the signature of the build.
+	signer, err := authenticode.Verify(download)
+	if err == nil && normalizeThumbprint(signer.Thumbprint) != expected {
+		err = fmt.Errorf("the build is signed with a certificate with the thumbprint %s instead of %s", signer.Thumbprint, expected)
+	}
+	recorder.Record(lbupdateevent.UpdateVerification{
+		Version:    manifest.Version,
+		Path:       download,
+		Signer:     signer.Subject,
+		Thumbprint: signer.Thumbprint,
+		Err:        err,
+	})
+	if err != nil {
+		os.Remove(download)
+		return err
+	}
+
+	// Swap the build into place.
+	backup, err := lbupdate.Replace(exe, download)
+	recorder.Record(lbupdateevent.UpdateInstall{
+		Previous:   current,
+		Version:    manifest.Version,
+		Path:       exe,
+		BackupPath: backup,
+		Err:        err,
+	})
+	if err != nil {
+		os.Remove(download)
+		return err
+	}
+
+	// Restart the service that runs LeafBridge, if requested.
+	if cmd.RestartService != "" {
+		restartCtx, cancel := context.WithTimeout(ctx, defaultServiceStopTimeout)
+		defer cancel()
+		err := winservice.Restart(restartCtx, cmd.RestartService)
+		recorder.Record(lbupdateevent.ServiceRestart{
+			Service: cmd.RestartService,
+			Err:     err,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// normalizeThumbprint returns the thumbprint in lower case without
+// separators.
+func normalizeThumbprint(thumbprint string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", ":", "").Replace(thumbprint))
+}
@@ -0,0 +1,166 @@
+// Package appassoc edits default application association files.
+//
+// A default application association file is an XML document that lists
+// the application that Windows opens for each file extension and protocol.
+// It is exported and imported by DISM, and can also be applied at each
+// logon through group policy.
+//
+// Edits are idempotent by identifier: each file extension or protocol is
+// associated with at most one application. Attributes that are not known
+// to this package are preserved, but the document is otherwise rewritten
+// in a standard form.
+package appassoc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Association associates a file extension or protocol with an application.
+//
+// Identifier is a file extension with a leading dot, such as ".pdf", or a
+// protocol, such as "mailto". ProgID is the programmatic identifier that
+// the application registers for the file type or protocol.
+type Association struct {
+	Identifier      string     `xml:"Identifier,attr"`
+	ProgID          string     `xml:"ProgId,attr"`
+	ApplicationName string     `xml:"ApplicationName,attr"`
+	Extra           []xml.Attr `xml:",any,attr"`
+}
+
+// document is the root of a default application association file.
+type document struct {
+	XMLName      xml.Name      `xml:"DefaultAssociations"`
+	Associations []Association `xml:"Association"`
+}
+
+// Set ensures that the association is present in doc, replacing any
+// existing association for the same identifier. If doc is empty, a new
+// document is created. It returns the updated document and true if it was
+// changed.
+func Set(doc []byte, assoc Association) ([]byte, bool, error) {
+	if err := ValidateIdentifier(assoc.Identifier); err != nil {
+		return nil, false, err
+	}
+	if assoc.ProgID == "" {
+		return nil, false, fmt.Errorf("a ProgID was not provided for \"%s\"", assoc.Identifier)
+	}
+
+	d, err := parse(doc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	found, changed := false, false
+	for i := 0; i < len(d.Associations); i++ {
+		existing := &d.Associations[i]
+		if !strings.EqualFold(existing.Identifier, assoc.Identifier) {
+			continue
+		}
+		if found {
+			d.Associations = append(d.Associations[:i], d.Associations[i+1:]...)
+			i--
+			changed = true
+			continue
+		}
+		found = true
+		if existing.Identifier != assoc.Identifier || existing.ProgID != assoc.ProgID || existing.ApplicationName != assoc.ApplicationName {
+			existing.Identifier = assoc.Identifier
+			existing.ProgID = assoc.ProgID
+			existing.ApplicationName = assoc.ApplicationName
+			changed = true
+		}
+	}
+	if !found {
+		d.Associations = append(d.Associations, assoc)
+		changed = true
+	}
+
+	if !changed {
+		return doc, false, nil
+	}
+	out, err := d.marshal()
+	return out, true, err
+}
+
+// Remove removes the association for identifier from doc. It returns the
+// updated document and true if it was changed.
+func Remove(doc []byte, identifier string) ([]byte, bool, error) {
+	if err := ValidateIdentifier(identifier); err != nil {
+		return nil, false, err
+	}
+
+	d, err := parse(doc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	changed := false
+	for i := 0; i < len(d.Associations); i++ {
+		if strings.EqualFold(d.Associations[i].Identifier, identifier) {
+			d.Associations = append(d.Associations[:i], d.Associations[i+1:]...)
+			i--
+			changed = true
+		}
+	}
+
+	if !changed {
+		return doc, false, nil
+	}
+	out, err := d.marshal()
+	return out, true, err
+}
+
+// ValidateIdentifier returns an error if identifier is not a valid file
+// extension or protocol.
+func ValidateIdentifier(identifier string) error {
+	if identifier == "" {
+		return errors.New("an identifier was not provided")
+	}
+	name, extension := strings.CutPrefix(identifier, ".")
+	if name == "" {
+		return fmt.Errorf("\"%s\" is not a valid file extension", identifier)
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case extension && (c == '_' || c == '-' || c == '.'):
+		case !extension && i > 0 && (c == '+' || c == '-' || c == '.'):
+		default:
+			if extension {
+				return fmt.Errorf("\"%s\" is not a valid file extension", identifier)
+			}
+			return fmt.Errorf("\"%s\" is not a valid protocol", identifier)
+		}
+	}
+	return nil
+}
+
+// parse parses a default application association file. An empty file is
+// treated as a document without associations.
+func parse(doc []byte) (document, error) {
+	var d document
+	if len(bytes.TrimSpace(doc)) == 0 {
+		return d, nil
+	}
+	if err := xml.Unmarshal(doc, &d); err != nil {
+		return document{}, fmt.Errorf("the default application associations could not be parsed: %w", err)
+	}
+	return d, nil
+}
+
+// marshal returns the document as XML.
+func (d document) marshal() ([]byte, error) {
+	data, err := xml.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\r\n")
+	out.Write(bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n")))
+	out.WriteString("\r\n")
+	return out.Bytes(), nil
+}
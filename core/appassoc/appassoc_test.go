@@ -0,0 +1,97 @@
+package appassoc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/appassoc"
+)
+
+const testAssociations = `<?xml version="1.0" encoding="UTF-8"?>
+<DefaultAssociations>
+  <Association Identifier=".htm" ProgId="MSEdgeHTM" ApplicationName="Microsoft Edge" />
+  <Association Identifier=".pdf" ProgId="MSEdgePDF" ApplicationName="Microsoft Edge" ApplyOnUpgrade="true" />
+</DefaultAssociations>
+`
+
+func TestSet(t *testing.T) {
+	fixtures := []struct {
+		Name        string
+		Association appassoc.Association
+		Changed     bool
+		Contains    []string
+		Excludes    []string
+	}{
+		{
+			Name:        "replace",
+			Association: appassoc.Association{Identifier: ".PDF", ProgID: "Acrobat.Document.DC", ApplicationName: "Adobe Acrobat"},
+			Changed:     true,
+			Contains:    []string{`Identifier=".PDF" ProgId="Acrobat.Document.DC" ApplicationName="Adobe Acrobat" ApplyOnUpgrade="true"`, `ProgId="MSEdgeHTM"`},
+			Excludes:    []string{`ProgId="MSEdgePDF"`},
+		},
+		{
+			Name:        "add",
+			Association: appassoc.Association{Identifier: "mailto", ProgID: "Outlook.URL.mailto.15", ApplicationName: "Outlook"},
+			Changed:     true,
+			Contains:    []string{`Identifier="mailto"`, `ProgId="MSEdgePDF"`},
+		},
+		{
+			Name:        "present",
+			Association: appassoc.Association{Identifier: ".htm", ProgID: "MSEdgeHTM", ApplicationName: "Microsoft Edge"},
+		},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			out, changed, err := appassoc.Set([]byte(testAssociations), fixture.Association)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != fixture.Changed {
+				t.Errorf("changed: got %t, want %t", changed, fixture.Changed)
+			}
+			for _, s := range fixture.Contains {
+				if !strings.Contains(string(out), s) {
+					t.Errorf("the result does not contain %s:\n%s", s, out)
+				}
+			}
+			for _, s := range fixture.Excludes {
+				if strings.Contains(string(out), s) {
+					t.Errorf("the result contains %s:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	out, changed, err := appassoc.Remove([]byte(testAssociations), ".Htm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || strings.Contains(string(out), "MSEdgeHTM") {
+		t.Errorf("the association was not removed:\n%s", out)
+	}
+
+	if _, changed, _ := appassoc.Remove(out, ".htm"); changed {
+		t.Errorf("removing a missing association reported a change")
+	}
+}
+
+func TestValidateIdentifier(t *testing.T) {
+	for identifier, valid := range map[string]bool{
+		".pdf":         true,
+		".tar.gz":      true,
+		"mailto":       true,
+		"ms-settings":  true,
+		"":             false,
+		".":            false,
+		"pdf file":     false,
+		"-bad":         false,
+		".pdf/../evil": false,
+	} {
+		if err := appassoc.ValidateIdentifier(identifier); (err == nil) != valid {
+			t.Errorf("%q: got error %v, want valid %t", identifier, err, valid)
+		}
+	}
+}
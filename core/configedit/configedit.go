@@ -0,0 +1,111 @@
+// Package configedit sets values within INI, XML and JSON configuration
+// documents.
+//
+// Edits are made by splicing new content into the original document, so
+// that comments, ordering, whitespace and line endings that are unrelated
+// to the edit are preserved.
+package configedit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding identifies the text encoding of a configuration document.
+type Encoding int
+
+// Supported encodings.
+const (
+	UTF8 Encoding = iota
+	UTF8BOM
+	UTF16LE
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+)
+
+// Decode returns the UTF-8 text of a configuration document along with
+// the encoding it was stored in. Documents with a UTF-16 little endian
+// byte order mark are converted to UTF-8.
+func Decode(data []byte) ([]byte, Encoding, error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return data[len(bomUTF8):], UTF8BOM, nil
+	case bytes.HasPrefix(data, bomUTF16LE):
+		data = data[len(bomUTF16LE):]
+		if len(data)%2 != 0 {
+			return nil, UTF16LE, errors.New("the document has an odd number of bytes for UTF-16 encoded text")
+		}
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(data[i*2:])
+		}
+		return []byte(string(utf16.Decode(units))), UTF16LE, nil
+	default:
+		if !utf8.Valid(data) {
+			return nil, UTF8, errors.New("the document is not valid UTF-8 text")
+		}
+		return data, UTF8, nil
+	}
+}
+
+// Encode returns text stored in the encoding.
+func (enc Encoding) Encode(text []byte) []byte {
+	switch enc {
+	case UTF8BOM:
+		return append(bytes.Clone(bomUTF8), text...)
+	case UTF16LE:
+		units := utf16.Encode([]rune(string(text)))
+		out := make([]byte, len(bomUTF16LE)+len(units)*2)
+		copy(out, bomUTF16LE)
+		for i, unit := range units {
+			binary.LittleEndian.PutUint16(out[len(bomUTF16LE)+i*2:], unit)
+		}
+		return out
+	default:
+		return text
+	}
+}
+
+// newline returns the line ending used by doc. It returns "\n" if doc
+// does not contain a line ending.
+func newline(doc []byte) string {
+	if i := bytes.IndexByte(doc, '\n'); i > 0 && doc[i-1] == '\r' {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// lineIndent returns the leading whitespace of the line that contains
+// offset.
+func lineIndent(doc []byte, offset int) string {
+	start := bytes.LastIndexByte(doc[:offset], '\n') + 1
+	line := doc[start:offset]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// indentUnit returns the indentation used by the first indented line of
+// doc. It returns two spaces if no lines are indented.
+func indentUnit(doc []byte) string {
+	for line := range bytes.Lines(doc) {
+		content := bytes.TrimLeft(line, " \t")
+		if indent := line[:len(line)-len(content)]; len(indent) > 0 && len(bytes.TrimSpace(content)) > 0 {
+			return string(indent)
+		}
+	}
+	return "  "
+}
+
+// splice returns a copy of doc with doc[start:end] replaced by content.
+func splice(doc []byte, start, end int, content string) []byte {
+	out := make([]byte, 0, len(doc)-(end-start)+len(content))
+	out = append(out, doc[:start]...)
+	out = append(out, content...)
+	out = append(out, doc[end:]...)
+	return out
+}
@@ -0,0 +1,207 @@
+package configedit_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/configedit"
+)
+
+var iniFixtures = []struct {
+	Name    string
+	Doc     string
+	Section string
+	Key     string
+	Value   string
+	Result  string
+}{
+	{
+		Name:    "replace",
+		Doc:     "; Settings\r\n[Update]\r\nChannel = Beta ; old\r\nAuto=1\r\n",
+		Section: "update",
+		Key:     "channel",
+		Value:   "Stable",
+		Result:  "; Settings\r\n[Update]\r\nChannel = Stable\r\nAuto=1\r\n",
+	},
+	{
+		Name:    "add-key",
+		Doc:     "[Update]\nAuto=1\n\n[Proxy]\nHost=proxy\n",
+		Section: "Update",
+		Key:     "Channel",
+		Value:   "Stable",
+		Result:  "[Update]\nAuto=1\nChannel=Stable\n\n[Proxy]\nHost=proxy\n",
+	},
+	{
+		Name:    "add-section",
+		Doc:     "[Update]\nAuto = 1",
+		Section: "Proxy",
+		Key:     "Host",
+		Value:   "proxy",
+		Result:  "[Update]\nAuto = 1\n\n[Proxy]\nHost = proxy",
+	},
+	{
+		Name:   "global",
+		Doc:    "# comment\n[Update]\nAuto=1\n",
+		Key:    "Version",
+		Value:  "2",
+		Result: "Version=2\n# comment\n[Update]\nAuto=1\n",
+	},
+	{
+		Name:    "empty",
+		Section: "Update",
+		Key:     "Auto",
+		Value:   "0",
+		Result:  "[Update]\nAuto=0\n",
+	},
+}
+
+func TestSetINI(t *testing.T) {
+	for _, fixture := range iniFixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			result, err := configedit.SetINI([]byte(fixture.Doc), fixture.Section, fixture.Key, fixture.Value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(result) != fixture.Result {
+				t.Errorf("unexpected result:\n%q\nwant:\n%q", result, fixture.Result)
+			}
+		})
+	}
+}
+
+var xmlFixtures = []struct {
+	Name      string
+	Doc       string
+	Path      string
+	Attribute string
+	Value     string
+	Result    string
+	Err       bool
+}{
+	{
+		Name:   "attribute",
+		Doc:    "<configuration>\n  <appSettings>\n    <add key=\"Mode\" value='Test' />\n  </appSettings>\n</configuration>\n",
+		Path:   "/configuration/appSettings/add[@key='Mode']/@value",
+		Value:  "Prod & Live",
+		Result: "<configuration>\n  <appSettings>\n    <add key=\"Mode\" value='Prod &amp; Live' />\n  </appSettings>\n</configuration>\n",
+	},
+	{
+		Name:      "create",
+		Doc:       "<configuration>\n  <appSettings>\n    <add key=\"Mode\" value=\"Test\"/>\n  </appSettings>\n</configuration>\n",
+		Path:      "/configuration/appSettings/add[@key='Server']",
+		Attribute: "value",
+		Value:     "db01",
+		Result:    "<configuration>\n  <appSettings>\n    <add key=\"Mode\" value=\"Test\"/>\n    <add key=\"Server\" value=\"db01\"/>\n  </appSettings>\n</configuration>\n",
+	},
+	{
+		Name:   "text",
+		Doc:    "<settings><server>old</server><port/></settings>",
+		Path:   "/settings/port",
+		Value:  "8080",
+		Result: "<settings><server>old</server><port>8080</port></settings>",
+	},
+	{
+		Name:   "nested",
+		Doc:    "<settings>\n\t<server/>\n</settings>",
+		Path:   "/settings/server/logging/level",
+		Value:  "debug",
+		Result: "<settings>\n\t<server>\n\t\t<logging>\n\t\t\t<level>debug</level>\n\t\t</logging>\n\t</server>\n</settings>",
+	},
+	{
+		Name: "wrong-root",
+		Doc:  "<settings/>",
+		Path: "/configuration/server",
+		Err:  true,
+	},
+}
+
+func TestSetXML(t *testing.T) {
+	for _, fixture := range xmlFixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			result, err := configedit.SetXML([]byte(fixture.Doc), fixture.Path, fixture.Attribute, fixture.Value)
+			if fixture.Err {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(result) != fixture.Result {
+				t.Errorf("unexpected result:\n%q\nwant:\n%q", result, fixture.Result)
+			}
+		})
+	}
+}
+
+var jsonFixtures = []struct {
+	Name    string
+	Doc     string
+	Pointer string
+	Value   string
+	Result  string
+	Err     bool
+}{
+	{
+		Name:    "replace",
+		Doc:     "{\n    \"update\": {\n        \"channel\": \"beta\",\n        \"auto\": true\n    }\n}\n",
+		Pointer: "/update/channel",
+		Value:   `"stable"`,
+		Result:  "{\n    \"update\": {\n        \"channel\": \"stable\",\n        \"auto\": true\n    }\n}\n",
+	},
+	{
+		Name:    "add",
+		Doc:     "{\n  \"update\": {\n    \"auto\": true\n  }\n}\n",
+		Pointer: "/proxy/host",
+		Value:   `"proxy.example.com"`,
+		Result:  "{\n  \"update\": {\n    \"auto\": true\n  },\n  \"proxy\": {\n    \"host\": \"proxy.example.com\"\n  }\n}\n",
+	},
+	{
+		Name:    "compact",
+		Doc:     `{"servers":["a"],"empty":{}}`,
+		Pointer: "/servers/-",
+		Value:   `"b"`,
+		Result:  `{"servers":["a","b"],"empty":{}}`,
+	},
+	{
+		Name:    "empty-object",
+		Doc:     "{\n  \"options\": {}\n}",
+		Pointer: "/options/a~1b",
+		Value:   `1`,
+		Result:  "{\n  \"options\": {\n    \"a/b\": 1\n  }\n}",
+	},
+	{
+		Name:    "new",
+		Pointer: "/enabled",
+		Value:   `true`,
+		Result:  "{\n  \"enabled\": true\n}\n",
+	},
+	{
+		Name:    "scalar",
+		Doc:     `{"name":"x"}`,
+		Pointer: "/name/first",
+		Value:   `"y"`,
+		Err:     true,
+	},
+}
+
+func TestSetJSON(t *testing.T) {
+	for _, fixture := range jsonFixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			result, err := configedit.SetJSON([]byte(fixture.Doc), fixture.Pointer, json.RawMessage(fixture.Value))
+			if fixture.Err {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(result) != fixture.Result {
+				t.Errorf("unexpected result:\n%q\nwant:\n%q", result, fixture.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package configedit
+
+import (
+	"errors"
+	"strings"
+)
+
+// SetINI sets the value of a key within a section of an INI document. If
+// section is empty, the key is set among the global keys that precede the
+// first section.
+//
+// Section and key names are compared without regard to case. If the key
+// does not exist, it is added after the last entry of the section. If the
+// section does not exist, it is added to the end of the document.
+func SetINI(doc []byte, section, key, value string) ([]byte, error) {
+	if key == "" {
+		return nil, errors.New("an INI key was not provided")
+	}
+	if strings.ContainsAny(key, "=\r\n") || strings.HasPrefix(key, "[") {
+		return nil, errors.New("the INI key contains characters that are not permitted")
+	}
+	if strings.ContainsAny(section, "]\r\n") {
+		return nil, errors.New("the INI section contains characters that are not permitted")
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return nil, errors.New("INI values cannot span multiple lines")
+	}
+
+	nl := newline(doc)
+	text := strings.ReplaceAll(string(doc), "\r\n", "\n")
+	trailing := text == "" || strings.HasSuffix(text, "\n")
+	text = strings.TrimSuffix(text, "\n")
+
+	var lines []string
+	if text != "" {
+		lines = strings.Split(text, "\n")
+	}
+
+	var (
+		current   string
+		found     = section == ""
+		last      = -1  // The last entry or header of the section.
+		separator = "=" // The separator used by existing entries.
+	)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			if end := strings.IndexByte(trimmed, ']'); end > 0 {
+				current = strings.TrimSpace(trimmed[1:end])
+				if section != "" && strings.EqualFold(current, section) {
+					found, last = true, i
+				}
+				continue
+			}
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			continue
+		}
+		if strings.HasSuffix(line[:eq], " ") {
+			separator = " = "
+		}
+		if !strings.EqualFold(current, section) {
+			continue
+		}
+		last = i
+		if !strings.EqualFold(strings.TrimSpace(line[:eq]), key) {
+			continue
+		}
+
+		// Preserve the key and any whitespace around the separator.
+		start := eq + 1
+		for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+			start++
+		}
+		lines[i] = line[:start] + value
+		return joinLines(lines, nl, trailing), nil
+	}
+
+	entry := key + separator + value
+	switch {
+	case found:
+		lines = insertLine(lines, last+1, entry)
+	case len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "":
+		lines = append(lines, "", "["+section+"]", entry)
+	default:
+		lines = append(lines, "["+section+"]", entry)
+	}
+
+	return joinLines(lines, nl, trailing), nil
+}
+
+func insertLine(lines []string, at int, line string) []string {
+	lines = append(lines, "")
+	copy(lines[at+1:], lines[at:])
+	lines[at] = line
+	return lines
+}
+
+func joinLines(lines []string, nl string, trailing bool) []byte {
+	out := strings.Join(lines, nl)
+	if trailing {
+		out += nl
+	}
+	return []byte(out)
+}
@@ -0,0 +1,249 @@
+package configedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetJSON sets the value at a JSON pointer within a JSON document. The
+// pointer follows RFC 6901. The "-" token appends to an array.
+//
+// Objects that are missing along the pointer are created. If the value
+// exists it is replaced; otherwise it is added after the last member of
+// its object or array, following the indentation of the document.
+func SetJSON(doc []byte, pointer string, value json.RawMessage) ([]byte, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, value); err != nil {
+		return nil, fmt.Errorf("the JSON value is invalid: %w", err)
+	}
+
+	if len(bytes.TrimSpace(doc)) == 0 {
+		if len(tokens) == 0 {
+			return append(compact.Bytes(), '\n'), nil
+		}
+		doc = []byte("{\n}\n")
+	} else if !json.Valid(doc) {
+		return nil, errors.New("the document is not valid JSON")
+	}
+
+	s := jsonScanner(doc)
+	start := s.skip(0)
+	end := s.value(start)
+
+	for i, token := range tokens {
+		switch doc[start] {
+		case '{':
+			members := s.members(start)
+			var found bool
+			for _, m := range members {
+				if m.key == token {
+					start, end, found = m.start, m.end, true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+			name := jsonString(token)
+			content := jsonNest(tokens[i+1:], compact.Bytes())
+			return s.insert(start, end, members, name, content), nil
+		case '[':
+			elements := s.members(start)
+			if token == "-" {
+				if i+1 < len(tokens) {
+					content := jsonNest(tokens[i+1:], compact.Bytes())
+					return s.insert(start, end, elements, "", content), nil
+				}
+				return s.insert(start, end, elements, "", compact.Bytes()), nil
+			}
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("the JSON pointer token \"%s\" is not a valid array index", token)
+			}
+			if index >= len(elements) {
+				return nil, fmt.Errorf("the JSON array index %d is out of range", index)
+			}
+			start, end = elements[index].start, elements[index].end
+		default:
+			return nil, fmt.Errorf("the JSON value at \"%s\" is not an object or array", formatPointer(tokens[:i]))
+		}
+	}
+
+	return splice(doc, start, end, s.indent(compact.Bytes(), lineIndent(doc, start), indentUnit(s))), nil
+}
+
+// parsePointer parses a JSON pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("the JSON pointer \"%s\" does not begin with a slash", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// formatPointer returns a JSON pointer for the given reference tokens.
+func formatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// jsonNest wraps value in nested objects for each of the given tokens.
+func jsonNest(tokens []string, value []byte) []byte {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		value = []byte(fmt.Sprintf("{%s:%s}", jsonString(tokens[i]), value))
+	}
+	return value
+}
+
+// jsonString returns s as a JSON string without HTML escaping.
+func jsonString(s string) string {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// jsonMember describes the location of an object member or array element
+// within a document.
+type jsonMember struct {
+	key      string
+	keyStart int
+	start    int
+	end      int
+}
+
+// jsonScanner locates values within a JSON document that is known to be
+// valid.
+type jsonScanner []byte
+
+// skip returns the offset of the first non-whitespace byte at or after i.
+func (s jsonScanner) skip(i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\r' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// value returns the offset immediately after the value that starts at i.
+func (s jsonScanner) value(i int) int {
+	switch s[i] {
+	case '"':
+		for i++; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return i
+	case '{', '[':
+		members := s.members(i)
+		end := i + 1
+		if len(members) > 0 {
+			end = members[len(members)-1].end
+		}
+		return s.skip(end) + 1
+	default:
+		for i < len(s) && !strings.ContainsRune(",]} \t\r\n", rune(s[i])) {
+			i++
+		}
+		return i
+	}
+}
+
+// members returns the members of the object or array that starts at i.
+func (s jsonScanner) members(i int) (members []jsonMember) {
+	object := s[i] == '{'
+	i = s.skip(i + 1)
+	for i < len(s) && s[i] != '}' && s[i] != ']' {
+		var m jsonMember
+		if object {
+			m.keyStart = i
+			keyEnd := s.value(i)
+			json.Unmarshal(s[i:keyEnd], &m.key)
+			i = s.skip(s.skip(keyEnd) + 1) // Skip the colon.
+		} else {
+			m.keyStart = i
+		}
+		m.start = i
+		m.end = s.value(i)
+		members = append(members, m)
+		i = s.skip(m.end)
+		if i < len(s) && s[i] == ',' {
+			i = s.skip(i + 1)
+		}
+	}
+	return members
+}
+
+// multiline returns true if the document spans more than one line.
+func (s jsonScanner) multiline() bool {
+	return bytes.Contains(bytes.TrimSpace(s), []byte{'\n'})
+}
+
+// indent formats value for placement at a position with the given line
+// indentation. Values in single-line documents are left compact.
+func (s jsonScanner) indent(value []byte, prefix, unit string) string {
+	if !s.multiline() || (value[0] != '{' && value[0] != '[') {
+		return string(value)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, value, prefix, unit); err != nil {
+		return string(value)
+	}
+	return out.String()
+}
+
+// insert adds a member to the object or array spanning doc[start:end],
+// which has the given members. The name is empty for array elements.
+func (s jsonScanner) insert(start, end int, members []jsonMember, name string, value []byte) []byte {
+	entry := func(indent string) string {
+		v := s.indent(value, indent, indentUnit(s))
+		if name == "" {
+			return v
+		}
+		if s.multiline() {
+			return name + ": " + v
+		}
+		return name + ":" + v
+	}
+
+	if len(members) == 0 {
+		closing := s.skip(start + 1)
+		if !s.multiline() {
+			return splice([]byte(s), start+1, closing, entry(""))
+		}
+		outer := lineIndent(s, start)
+		inner := outer + indentUnit(s)
+		nl := newline(s)
+		return splice([]byte(s), start+1, closing, nl+inner+entry(inner)+nl+outer)
+	}
+
+	// Follow the whitespace that precedes the first member.
+	first := members[0]
+	gap := string(s[start+1 : first.keyStart])
+	inner := lineIndent(s, first.keyStart)
+	last := members[len(members)-1]
+	return splice([]byte(s), last.end, last.end, ","+gap+entry(inner))
+}
@@ -0,0 +1,452 @@
+package configedit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SetXML sets the text of an element, or the value of one of its
+// attributes, within an XML document. If attribute is empty, the text of
+// the element is set.
+//
+// The element is located by a path of element names separated by
+// slashes, starting with the root element, such as
+// /configuration/appSettings/add[@key='Mode']. This is a subset of XPath.
+// Each step may include predicates that match an attribute value
+// ([@name='value']) or a one-based position among matching siblings
+// ([2]). A final step of the form @name selects an attribute.
+//
+// At each step the first matching element is used. Missing elements are
+// created along with the attributes named in their predicates, unless
+// the step relies on a position. Names are compared exactly as they
+// appear in the document, including namespace prefixes.
+func SetXML(doc []byte, path, attribute, value string) ([]byte, error) {
+	steps, attr, err := parseXMLPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if attr != "" {
+		if attribute != "" {
+			return nil, errors.New("an attribute was provided by both the XML path and the edit")
+		}
+		attribute = attr
+	}
+
+	if len(bytes.TrimSpace(doc)) == 0 {
+		doc = []byte(xml.Header + buildXMLElement(steps[:1], "", "", xmlLayout{}) + "\n")
+	}
+
+	root, err := parseXMLTree(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !steps[0].matches(root) {
+		return nil, fmt.Errorf("the root element of the document does not match \"%s\"", steps[0].name)
+	}
+
+	node := root
+	for i, step := range steps[1:] {
+		child := step.find(node.children)
+		if child == nil {
+			for _, missing := range steps[i+1:] {
+				if missing.position > 1 {
+					return nil, fmt.Errorf("the \"%s\" element at position %d does not exist", missing.name, missing.position)
+				}
+			}
+			return insertXMLChild(doc, node, steps[i+1:], attribute, value)
+		}
+		node = child
+	}
+
+	if attribute != "" {
+		return setXMLAttribute(doc, node, attribute, value)
+	}
+	if len(node.children) > 0 {
+		return nil, fmt.Errorf("the text of the \"%s\" element cannot be set because it has child elements", node.name)
+	}
+	if node.selfClosing() {
+		return splice(doc, node.start, node.end, node.openTag(doc)+escapeXML(value)+"</"+node.name+">"), nil
+	}
+	return splice(doc, node.startEnd, node.endStart, escapeXML(value)), nil
+}
+
+// xmlStep is a step within an XML path.
+type xmlStep struct {
+	name     string
+	attrs    []xml.Attr // Each name holds the qualified name.
+	position int        // One-based. Zero if not specified.
+}
+
+// matches returns true if the element satisfies the step's name and
+// attribute predicates.
+func (step xmlStep) matches(node *xmlNode) bool {
+	if node.name != step.name {
+		return false
+	}
+	for _, want := range step.attrs {
+		value, found := node.attr(want.Name.Local)
+		if !found || value != want.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the first element that satisfies the step.
+func (step xmlStep) find(nodes []*xmlNode) *xmlNode {
+	position := 0
+	for _, node := range nodes {
+		if !step.matches(node) {
+			continue
+		}
+		position++
+		if step.position == 0 || step.position == position {
+			return node
+		}
+	}
+	return nil
+}
+
+// parseXMLPath parses a path into its steps. If the final step selects an
+// attribute, its name is returned separately.
+func parseXMLPath(path string) (steps []xmlStep, attribute string, err error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, "", fmt.Errorf("the XML path \"%s\" does not begin with a slash", path)
+	}
+
+	// Split the path on slashes that are not within predicates.
+	var (
+		parts []string
+		depth int
+		quote rune
+		last  = 1
+	)
+	for i, c := range path {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '/' && depth == 0 && i > 0:
+			parts = append(parts, path[last:i])
+			last = i + 1
+		}
+	}
+	parts = append(parts, path[last:])
+
+	for i, part := range parts {
+		if strings.HasPrefix(part, "@") && i == len(parts)-1 && i > 0 {
+			attribute = part[1:]
+			break
+		}
+		step, err := parseXMLStep(part)
+		if err != nil {
+			return nil, "", fmt.Errorf("the XML path \"%s\" is invalid: %w", path, err)
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, attribute, nil
+}
+
+// parseXMLStep parses a single step of an XML path.
+func parseXMLStep(part string) (step xmlStep, err error) {
+	name, predicates, _ := strings.Cut(part, "[")
+	if name == "" || strings.ContainsAny(name, " \t@]'\"") {
+		return step, fmt.Errorf("the element name \"%s\" is not valid", name)
+	}
+	step.name = name
+
+	for predicates != "" {
+		content, rest, found := strings.Cut(predicates, "]")
+		if !found {
+			return step, errors.New("a predicate is not closed")
+		}
+		// Attribute values may contain a closing bracket.
+		for strings.Count(content, "'")%2 != 0 || strings.Count(content, `"`)%2 != 0 {
+			more, remainder, found := strings.Cut(rest, "]")
+			if !found {
+				return step, errors.New("a predicate is not closed")
+			}
+			content, rest = content+"]"+more, remainder
+		}
+		content = strings.TrimSpace(content)
+
+		if attr, ok := strings.CutPrefix(content, "@"); ok {
+			name, value, found := strings.Cut(attr, "=")
+			value = strings.TrimSpace(value)
+			if !found || len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+				return step, fmt.Errorf("the predicate \"[%s]\" is not supported", content)
+			}
+			step.attrs = append(step.attrs, xml.Attr{
+				Name:  xml.Name{Local: strings.TrimSpace(name)},
+				Value: value[1 : len(value)-1],
+			})
+		} else {
+			position, err := strconv.Atoi(content)
+			if err != nil || position < 1 {
+				return step, fmt.Errorf("the predicate \"[%s]\" is not supported", content)
+			}
+			step.position = position
+		}
+
+		predicates = strings.TrimPrefix(rest, "[")
+		if rest != "" && !strings.HasPrefix(rest, "[") {
+			return step, fmt.Errorf("unexpected text \"%s\" after a predicate", rest)
+		}
+	}
+
+	return step, nil
+}
+
+// xmlNode records the location of an element within a document.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	start    int // Offset of the start tag.
+	startEnd int // Offset immediately after the start tag.
+	endStart int // Offset of the end tag.
+	end      int // Offset immediately after the end tag.
+	children []*xmlNode
+}
+
+// attr returns the value of the attribute with the given qualified name.
+func (node *xmlNode) attr(name string) (string, bool) {
+	for _, attr := range node.attrs {
+		if qualifiedName(attr.Name) == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// selfClosing returns true if the element was written as an empty-element
+// tag.
+func (node *xmlNode) selfClosing() bool {
+	return node.startEnd == node.end
+}
+
+// openTag returns the start tag of the element. Empty-element tags are
+// converted to start tags.
+func (node *xmlNode) openTag(doc []byte) string {
+	tag := string(doc[node.start:node.startEnd])
+	if node.selfClosing() {
+		tag = strings.TrimRight(strings.TrimSuffix(tag, "/>"), " \t\r\n") + ">"
+	}
+	return tag
+}
+
+// parseXMLTree returns the root element of the document.
+func parseXMLTree(doc []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(doc))
+
+	var (
+		root  *xmlNode
+		stack []*xmlNode
+	)
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the document is not valid XML: %w", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{
+				name:     qualifiedName(token.Name),
+				attrs:    token.Copy().Attr,
+				start:    offset,
+				startEnd: int(decoder.InputOffset()),
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root != nil {
+				return nil, errors.New("the document is not valid XML: it has more than one root element")
+			} else {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("the document is not valid XML: unexpected end element \"%s\"", qualifiedName(token.Name))
+			}
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if name := qualifiedName(token.Name); name != node.name {
+				return nil, fmt.Errorf("the document is not valid XML: element \"%s\" is closed by \"%s\"", node.name, name)
+			}
+			node.endStart, node.end = offset, int(decoder.InputOffset())
+			if node.end == offset {
+				// The end element was implied by an empty-element tag.
+				node.endStart, node.end = node.startEnd, node.startEnd
+			}
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("the document is not valid XML: element \"%s\" is not closed", stack[len(stack)-1].name)
+	}
+	if root == nil {
+		return nil, errors.New("the document does not have a root element")
+	}
+
+	return root, nil
+}
+
+// qualifiedName returns the name as it appears in the document.
+func qualifiedName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// xmlLayout describes the whitespace used to lay out new elements.
+type xmlLayout struct {
+	nl     string // Empty for single-line documents.
+	indent string
+	unit   string
+}
+
+// nested returns the layout for children of an element.
+func (layout xmlLayout) nested() xmlLayout {
+	if layout.nl == "" {
+		return layout
+	}
+	layout.indent += layout.unit
+	return layout
+}
+
+// buildXMLElement returns markup for the elements described by steps,
+// each nested within the previous. The attribute or text value is applied
+// to the innermost element.
+func buildXMLElement(steps []xmlStep, attribute, value string, layout xmlLayout) string {
+	step := steps[0]
+	var b strings.Builder
+	b.WriteString("<" + step.name)
+	last := len(steps) == 1
+	for _, attr := range step.attrs {
+		if last && attr.Name.Local == attribute {
+			continue
+		}
+		b.WriteString(" " + attr.Name.Local + `="` + escapeXML(attr.Value) + `"`)
+	}
+	switch {
+	case !last:
+		inner := layout.nested()
+		b.WriteString(">" + layout.nl + inner.indent)
+		b.WriteString(buildXMLElement(steps[1:], attribute, value, inner))
+		b.WriteString(layout.nl + layout.indent + "</" + step.name + ">")
+	case attribute != "":
+		b.WriteString(" " + attribute + `="` + escapeXML(value) + `"/>`)
+	case value == "":
+		b.WriteString("/>")
+	default:
+		b.WriteString(">" + escapeXML(value) + "</" + step.name + ">")
+	}
+	return b.String()
+}
+
+// insertXMLChild adds the elements described by steps as a child of
+// parent.
+func insertXMLChild(doc []byte, parent *xmlNode, steps []xmlStep, attribute, value string) ([]byte, error) {
+	layout := xmlLayout{indent: lineIndent(doc, parent.start)}
+	if bytes.Contains(bytes.TrimSpace(doc), []byte{'\n'}) {
+		layout.nl = newline(doc)
+		layout.unit = indentUnit(doc)
+	}
+
+	if n := len(parent.children); n > 0 {
+		last := parent.children[n-1]
+		inner := layout.nested()
+		inner.indent = lineIndent(doc, last.start)
+		return splice(doc, last.end, last.end, inner.nl+inner.indent+buildXMLElement(steps, attribute, value, inner)), nil
+	}
+
+	inner := layout.nested()
+	content := inner.nl + inner.indent + buildXMLElement(steps, attribute, value, inner) + layout.nl + layout.indent
+	if parent.selfClosing() {
+		return splice(doc, parent.start, parent.end, parent.openTag(doc)+content+"</"+parent.name+">"), nil
+	}
+	if len(bytes.TrimSpace(doc[parent.startEnd:parent.endStart])) > 0 {
+		return nil, fmt.Errorf("child elements cannot be added to the \"%s\" element because it contains text", parent.name)
+	}
+	return splice(doc, parent.startEnd, parent.endStart, content), nil
+}
+
+// setXMLAttribute sets the value of an attribute of the element. An
+// existing value is replaced in place; otherwise the attribute is added
+// to the end of the start tag.
+func setXMLAttribute(doc []byte, node *xmlNode, name, value string) ([]byte, error) {
+	tag := doc[node.start:node.startEnd]
+	escaped := escapeXML(value)
+
+	// Skip the element name.
+	i := 1 + len(node.name)
+	for i < len(tag) {
+		for i < len(tag) && isXMLSpace(tag[i]) {
+			i++
+		}
+		if i >= len(tag) || tag[i] == '/' || tag[i] == '>' {
+			break
+		}
+		nameStart := i
+		for i < len(tag) && tag[i] != '=' && !isXMLSpace(tag[i]) {
+			i++
+		}
+		attrName := string(tag[nameStart:i])
+		for i < len(tag) && (tag[i] == '=' || isXMLSpace(tag[i])) {
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		quote := tag[i]
+		valueEnd := bytes.IndexByte(tag[i+1:], quote)
+		if valueEnd < 0 {
+			break
+		}
+		valueEnd += i + 1
+		if attrName == name {
+			return splice(doc, node.start+i+1, node.start+valueEnd, escaped), nil
+		}
+		i = valueEnd + 1
+	}
+
+	// Add the attribute before the end of the start tag.
+	end := len(tag) - 1
+	if bytes.HasSuffix(tag, []byte("/>")) {
+		end--
+	}
+	for end > 0 && isXMLSpace(tag[end-1]) {
+		end--
+	}
+	return splice(doc, node.start+end, node.start+end, " "+name+`="`+escaped+`"`), nil
+}
+
+func isXMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// escapeXML returns s with characters escaped for use in XML text or a
+// quoted attribute value.
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,30 @@
+package datatype
+
+import (
+	"fmt"
+	"time"
+)
+
+// Duration is a span of time. It is encoded as text in the form accepted
+// by [time.ParseDuration], such as "90s" or "1h30m".
+type Duration time.Duration
+
+// String returns a string representation of the duration.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText marshals the duration as text.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText unmarshals the duration from text.
+func (d *Duration) UnmarshalText(b []byte) error {
+	value, err := time.ParseDuration(string(b))
+	if err != nil {
+		return fmt.Errorf("invalid duration \"%s\": %w", b, err)
+	}
+	*d = Duration(value)
+	return nil
+}
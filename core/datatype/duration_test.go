@@ -0,0 +1,46 @@
+package datatype_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
+type durationInOut struct {
+	In  string
+	Out datatype.Duration
+}
+
+var durationFixtures = []durationInOut{
+	{In: `"0s"`, Out: 0},
+	{In: `"90s"`, Out: datatype.Duration(90 * time.Second)},
+	{In: `"1h30m"`, Out: datatype.Duration(90 * time.Minute)},
+}
+
+func TestDuration(t *testing.T) {
+	for i, fixture := range durationFixtures {
+		t.Run(fmt.Sprintf("%d:%s", i, fixture.In), func(t *testing.T) {
+			var d datatype.Duration
+			if err := json.Unmarshal([]byte(fixture.In), &d); err != nil {
+				t.Fatal(err)
+			}
+			if d != fixture.Out {
+				t.Fatalf("unexpected duration: got %v, want %v", d, fixture.Out)
+			}
+			out, err := json.Marshal(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var again datatype.Duration
+			if err := json.Unmarshal(out, &again); err != nil {
+				t.Fatal(err)
+			}
+			if again != d {
+				t.Fatalf("duration not equal after round trip: %v → %v", d, again)
+			}
+		})
+	}
+}
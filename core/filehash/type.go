@@ -5,6 +5,7 @@ import "strings"
 // Recognized file hash types.
 const (
 	SHA3_256 Type = "sha3-256"
+	SHA256   Type = "sha256"
 )
 
 // Type identifies the type of cryptographic hash used for file verification.
@@ -17,6 +18,8 @@ type Type string
 func (t Type) Priority() int {
 	switch t {
 	case SHA3_256:
+		return 2
+	case SHA256:
 		return 1
 	}
 	return 0
@@ -0,0 +1,189 @@
+// Package hostsfile edits hosts files.
+//
+// Edits are idempotent by hostname: each hostname is mapped to at most one
+// address by the entries that are managed through this package. Comments,
+// blank lines and unrelated entries are preserved.
+package hostsfile
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Add ensures that hostname maps to address within doc. If hostname is
+// present on other lines, it is removed from them. It returns the updated
+// document and true if it was changed.
+func Add(doc []byte, address, hostname string) ([]byte, bool, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return nil, false, fmt.Errorf("the address \"%s\" is not valid: %w", address, err)
+	}
+	if err := ValidateHostname(hostname); err != nil {
+		return nil, false, err
+	}
+
+	f := parse(doc)
+	found, changed := false, false
+	for i := 0; i < len(f.lines); i++ {
+		line := &f.lines[i]
+		if !line.contains(hostname) {
+			continue
+		}
+		if !found && line.addr == addr {
+			found = true
+			continue
+		}
+		line.remove(hostname)
+		changed = true
+		if len(line.names) == 0 {
+			f.lines = append(f.lines[:i], f.lines[i+1:]...)
+			i--
+		}
+	}
+
+	if !found {
+		f.lines = append(f.lines, hostsLine{
+			text:  addr.String() + "\t" + hostname,
+			addr:  addr,
+			names: []string{hostname},
+		})
+		changed = true
+	}
+
+	return f.bytes(), changed, nil
+}
+
+// Remove removes hostname from every entry within doc. Entries that have
+// no remaining hostnames are removed. It returns the updated document and
+// true if it was changed.
+func Remove(doc []byte, hostname string) ([]byte, bool, error) {
+	if err := ValidateHostname(hostname); err != nil {
+		return nil, false, err
+	}
+
+	f := parse(doc)
+	changed := false
+	for i := 0; i < len(f.lines); i++ {
+		line := &f.lines[i]
+		if !line.contains(hostname) {
+			continue
+		}
+		line.remove(hostname)
+		changed = true
+		if len(line.names) == 0 {
+			f.lines = append(f.lines[:i], f.lines[i+1:]...)
+			i--
+		}
+	}
+
+	return f.bytes(), changed, nil
+}
+
+// ValidateHostname returns an error if hostname is not a valid host name.
+func ValidateHostname(hostname string) error {
+	if hostname == "" {
+		return fmt.Errorf("a hostname was not provided")
+	}
+	if len(hostname) > 253 {
+		return fmt.Errorf("the hostname \"%s\" is too long", hostname)
+	}
+	for label := range strings.SplitSeq(hostname, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("the hostname \"%s\" is not valid", hostname)
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return fmt.Errorf("the hostname \"%s\" is not valid", hostname)
+			}
+		}
+	}
+	return nil
+}
+
+// hostsFile is a parsed hosts file.
+type hostsFile struct {
+	lines    []hostsLine
+	newline  string
+	trailing bool
+}
+
+// hostsLine is a line of a hosts file. Lines without an entry have no
+// names.
+type hostsLine struct {
+	text    string
+	addr    netip.Addr
+	names   []string
+	comment string
+	edited  bool
+}
+
+func parse(doc []byte) hostsFile {
+	text := string(doc)
+	f := hostsFile{newline: "\n", trailing: text == "" || strings.HasSuffix(text, "\n")}
+	if strings.Contains(text, "\r\n") {
+		f.newline = "\r\n"
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return f
+	}
+	for raw := range strings.SplitSeq(text, "\n") {
+		line := hostsLine{text: raw}
+		content := raw
+		if i := strings.IndexByte(raw, '#'); i >= 0 {
+			content, line.comment = raw[:i], raw[i:]
+		}
+		fields := strings.Fields(content)
+		if len(fields) >= 2 {
+			if addr, err := netip.ParseAddr(fields[0]); err == nil {
+				line.addr = addr
+				line.names = fields[1:]
+			}
+		}
+		f.lines = append(f.lines, line)
+	}
+	return f
+}
+
+func (line *hostsLine) contains(hostname string) bool {
+	for _, name := range line.names {
+		if strings.EqualFold(name, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+func (line *hostsLine) remove(hostname string) {
+	names := line.names[:0]
+	for _, name := range line.names {
+		if !strings.EqualFold(name, hostname) {
+			names = append(names, name)
+		}
+	}
+	line.names = names
+	line.edited = true
+}
+
+func (f hostsFile) bytes() []byte {
+	var b strings.Builder
+	for i, line := range f.lines {
+		if i > 0 {
+			b.WriteString(f.newline)
+		}
+		if !line.edited {
+			b.WriteString(line.text)
+			continue
+		}
+		b.WriteString(line.addr.String() + "\t" + strings.Join(line.names, " "))
+		if line.comment != "" {
+			b.WriteString(" " + line.comment)
+		}
+	}
+	if f.trailing && len(f.lines) > 0 {
+		b.WriteString(f.newline)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,78 @@
+package hostsfile_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/hostsfile"
+)
+
+const testHosts = "# Copyright (c) 1993-2009 Microsoft Corp.\r\n#\r\n127.0.0.1\tlocalhost\r\n10.0.0.5\tlicense.example.com legacy # Licensing\r\n"
+
+var hostsFixtures = []struct {
+	Name     string
+	Remove   bool
+	Address  string
+	Hostname string
+	Result   string
+	Changed  bool
+}{
+	{
+		Name:     "add",
+		Address:  "10.0.0.9",
+		Hostname: "app.example.com",
+		Result:   testHosts + "10.0.0.9\tapp.example.com\r\n",
+		Changed:  true,
+	},
+	{
+		Name:     "present",
+		Address:  "10.0.0.5",
+		Hostname: "LEGACY",
+		Result:   testHosts,
+	},
+	{
+		Name:     "move",
+		Address:  "10.0.0.6",
+		Hostname: "legacy",
+		Result:   "# Copyright (c) 1993-2009 Microsoft Corp.\r\n#\r\n127.0.0.1\tlocalhost\r\n10.0.0.5\tlicense.example.com # Licensing\r\n10.0.0.6\tlegacy\r\n",
+		Changed:  true,
+	},
+	{
+		Name:     "remove",
+		Remove:   true,
+		Hostname: "localhost",
+		Result:   "# Copyright (c) 1993-2009 Microsoft Corp.\r\n#\r\n10.0.0.5\tlicense.example.com legacy # Licensing\r\n",
+		Changed:  true,
+	},
+	{
+		Name:     "remove-absent",
+		Remove:   true,
+		Hostname: "app.example.com",
+		Result:   testHosts,
+	},
+}
+
+func TestHostsFile(t *testing.T) {
+	for _, fixture := range hostsFixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			var (
+				result  []byte
+				changed bool
+				err     error
+			)
+			if fixture.Remove {
+				result, changed, err = hostsfile.Remove([]byte(testHosts), fixture.Hostname)
+			} else {
+				result, changed, err = hostsfile.Add([]byte(testHosts), fixture.Address, fixture.Hostname)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if changed != fixture.Changed {
+				t.Errorf("expected changed to be %t, got %t", fixture.Changed, changed)
+			}
+			if string(result) != fixture.Result {
+				t.Errorf("unexpected result:\n%q\nwant:\n%q", result, fixture.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// Package intunewin writes Win32 app packages in the .intunewin format that
+// is accepted by Microsoft Intune.
+//
+// A .intunewin file is a ZIP archive that holds two entries. The first is
+// an encrypted ZIP archive of the package contents. The second is an XML
+// document that describes the package, including the keys that are needed
+// to decrypt its contents. The contents are encrypted with AES-256 in CBC
+// mode and authenticated with HMAC-SHA256, in the same manner as the
+// Microsoft Win32 Content Prep Tool.
+package intunewin
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// Names of the entries within a .intunewin file.
+const (
+	MetadataEntry = "IntuneWinPackage/Metadata/Detection.xml"
+	ContentEntry  = "IntuneWinPackage/Contents/IntunePackage.intunewin"
+)
+
+const (
+	// toolVersion is the version of the Win32 Content Prep Tool whose
+	// output is matched by this package.
+	toolVersion = "1.8.6.0"
+
+	// profileIdentifier identifies the encryption profile.
+	profileIdentifier = "ProfileVersion1"
+
+	// chunkSize is the size of the chunks that are encrypted at a time.
+	// It must be a multiple of the AES block size.
+	chunkSize = 64 << 10 // 64 KiB
+)
+
+// ApplicationInfo is the XML document that describes a .intunewin package.
+type ApplicationInfo struct {
+	XMLName                xml.Name       `xml:"ApplicationInfo"`
+	ToolVersion            string         `xml:"ToolVersion,attr"`
+	Name                   string         `xml:"Name"`
+	UnencryptedContentSize int64          `xml:"UnencryptedContentSize"`
+	FileName               string         `xml:"FileName"`
+	SetupFile              string         `xml:"SetupFile"`
+	EncryptionInfo         EncryptionInfo `xml:"EncryptionInfo"`
+}
+
+// EncryptionInfo describes the encryption of a .intunewin package's
+// contents. Each of its keys and digests is encoded in base64.
+type EncryptionInfo struct {
+	EncryptionKey        string `xml:"EncryptionKey"`
+	MacKey               string `xml:"MacKey"`
+	InitializationVector string `xml:"InitializationVector"`
+	Mac                  string `xml:"Mac"`
+	ProfileIdentifier    string `xml:"ProfileIdentifier"`
+	FileDigest           string `xml:"FileDigest"`
+	FileDigestAlgorithm  string `xml:"FileDigestAlgorithm"`
+}
+
+// Write writes a .intunewin package to w. The package holds every file in
+// contents. The setup file is the path of the file within contents that
+// Intune should treat as the package's installer.
+//
+// The contents are staged in temporary files while they are compressed
+// and encrypted.
+func Write(w io.Writer, setupFile string, contents fs.FS) error {
+	if info, err := fs.Stat(contents, setupFile); err != nil {
+		return fmt.Errorf("the \"%s\" setup file could not be found: %w", setupFile, err)
+	} else if !info.Mode().IsRegular() {
+		return fmt.Errorf("the \"%s\" setup file is not a regular file", setupFile)
+	}
+
+	// Compress the contents into a temporary archive, computing its digest
+	// along the way.
+	archive, err := os.CreateTemp("", "intunewin-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary archive: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	digest := sha256.New()
+	if err := writeArchive(io.MultiWriter(archive, digest), contents); err != nil {
+		return fmt.Errorf("failed to prepare the package contents: %w", err)
+	}
+	size, err := archive.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// Generate the keys.
+	key, macKey, iv := make([]byte, 32), make([]byte, 32), make([]byte, aes.BlockSize)
+	for _, b := range [][]byte{key, macKey, iv} {
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+	}
+
+	// Encrypt the archive into a second temporary file. The file starts
+	// with the message authentication code, which is filled in once the
+	// encryption is complete.
+	encrypted, err := os.CreateTemp("", "intunewin-*.bin")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file: %w", err)
+	}
+	defer os.Remove(encrypted.Name())
+	defer encrypted.Close()
+
+	mac, err := encrypt(encrypted, archive, key, macKey, iv)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt the package contents: %w", err)
+	}
+	if _, err := encrypted.WriteAt(mac, 0); err != nil {
+		return err
+	}
+	if _, err := encrypted.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// Describe the package.
+	info := ApplicationInfo{
+		ToolVersion:            toolVersion,
+		Name:                   path.Base(setupFile),
+		UnencryptedContentSize: size,
+		FileName:               path.Base(ContentEntry),
+		SetupFile:              setupFile,
+		EncryptionInfo: EncryptionInfo{
+			EncryptionKey:        base64.StdEncoding.EncodeToString(key),
+			MacKey:               base64.StdEncoding.EncodeToString(macKey),
+			InitializationVector: base64.StdEncoding.EncodeToString(iv),
+			Mac:                  base64.StdEncoding.EncodeToString(mac),
+			ProfileIdentifier:    profileIdentifier,
+			FileDigest:           base64.StdEncoding.EncodeToString(digest.Sum(nil)),
+			FileDigestAlgorithm:  "SHA256",
+		},
+	}
+	metadata, err := xml.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write the package.
+	zw := zip.NewWriter(w)
+	content, err := zw.CreateHeader(&zip.FileHeader{Name: ContentEntry, Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(content, encrypted); err != nil {
+		return err
+	}
+	detection, err := zw.Create(MetadataEntry)
+	if err != nil {
+		return err
+	}
+	if _, err := detection.Write(append([]byte(xml.Header), metadata...)); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeArchive writes every file in contents to w as a ZIP archive.
+func writeArchive(w io.Writer, contents fs.FS) error {
+	zw := zip.NewWriter(w)
+	err := fs.WalkDir(contents, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return fmt.Errorf("%s: only regular files can be included", name)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		file, err := contents.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(fw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// encrypt writes the contents of src to dst in the encrypted form used by
+// .intunewin packages, leaving room for the message authentication code at
+// the start of dst. It returns the message authentication code.
+func encrypt(dst io.Writer, src io.Reader, key, macKey, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	mode := cipher.NewCBCEncrypter(block, iv)
+	mac := hmac.New(sha256.New, macKey)
+
+	if _, err := dst.Write(make([]byte, mac.Size())); err != nil {
+		return nil, err
+	}
+	if err := writeAuthenticated(dst, mac, iv); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, chunkSize+aes.BlockSize)
+	for {
+		n, err := io.ReadFull(src, buf[:chunkSize])
+		final := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !final {
+			return nil, err
+		}
+
+		// Apply PKCS #7 padding to the final chunk.
+		chunk := buf[:n]
+		if final {
+			padding := aes.BlockSize - n%aes.BlockSize
+			chunk = append(chunk, bytes.Repeat([]byte{byte(padding)}, padding)...)
+		}
+
+		mode.CryptBlocks(chunk, chunk)
+		if err := writeAuthenticated(dst, mac, chunk); err != nil {
+			return nil, err
+		}
+		if final {
+			return mac.Sum(nil), nil
+		}
+	}
+}
+
+// writeAuthenticated writes p to w and adds it to the message
+// authentication code.
+func writeAuthenticated(w io.Writer, mac hash.Hash, p []byte) error {
+	mac.Write(p)
+	_, err := w.Write(p)
+	return err
+}
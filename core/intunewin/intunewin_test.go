@@ -0,0 +1,119 @@
+package intunewin_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/leafbridge/leafbridge/core/intunewin"
+)
+
+func TestWrite(t *testing.T) {
+	contents := fstest.MapFS{
+		"leafbridge-deploy.exe": {Data: []byte("executable")},
+		"bundle.zip":            {Data: bytes.Repeat([]byte("bundle"), 20000)},
+	}
+
+	var out bytes.Buffer
+	if err := intunewin.Write(&out, "leafbridge-deploy.exe", contents); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the metadata.
+	var info intunewin.ApplicationInfo
+	if err := xml.Unmarshal(readEntry(t, pkg, intunewin.MetadataEntry), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.SetupFile != "leafbridge-deploy.exe" {
+		t.Errorf("unexpected setup file: %s", info.SetupFile)
+	}
+
+	// Verify the message authentication code.
+	encrypted := readEntry(t, pkg, intunewin.ContentEntry)
+	macKey := decode(t, info.EncryptionInfo.MacKey)
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(encrypted[sha256.Size:])
+	if !hmac.Equal(mac.Sum(nil), encrypted[:sha256.Size]) {
+		t.Fatal("the message authentication code does not match the content")
+	}
+	if !bytes.Equal(encrypted[:sha256.Size], decode(t, info.EncryptionInfo.Mac)) {
+		t.Fatal("the message authentication code does not match the metadata")
+	}
+
+	// Decrypt the contents.
+	block, err := aes.NewCipher(decode(t, info.EncryptionInfo.EncryptionKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := encrypted[sha256.Size : sha256.Size+aes.BlockSize]
+	if !bytes.Equal(iv, decode(t, info.EncryptionInfo.InitializationVector)) {
+		t.Fatal("the initialization vector does not match the metadata")
+	}
+	archive := encrypted[sha256.Size+aes.BlockSize:]
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(archive, archive)
+	archive = archive[:len(archive)-int(archive[len(archive)-1])]
+
+	if int64(len(archive)) != info.UnencryptedContentSize {
+		t.Errorf("unexpected content size: got %d, want %d", len(archive), info.UnencryptedContentSize)
+	}
+	digest := sha256.Sum256(archive)
+	if !bytes.Equal(digest[:], decode(t, info.EncryptionInfo.FileDigest)) {
+		t.Error("the file digest does not match the content")
+	}
+
+	// Verify the files within the contents.
+	files, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, file := range contents {
+		if got := readEntry(t, files, name); !bytes.Equal(got, file.Data) {
+			t.Errorf("%s: the content of the file does not match", name)
+		}
+	}
+}
+
+func TestWriteMissingSetupFile(t *testing.T) {
+	contents := fstest.MapFS{"bundle.zip": {Data: []byte("bundle")}}
+	err := intunewin.Write(io.Discard, "setup.exe", contents)
+	if err == nil || !strings.Contains(err.Error(), "setup.exe") {
+		t.Errorf("expected an error about the missing setup file, got %v", err)
+	}
+}
+
+func readEntry(t *testing.T, r *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := r.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,126 @@
+package lbassign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// MaxDeploymentSize is the maximum size of a deployment document that is
+// retrieved from an assignment.
+const MaxDeploymentSize = 16 << 20
+
+// IsURL returns true if the location is an http or https URL. Any other
+// location is treated as a file path, which might be a UNC path.
+func IsURL(location string) bool {
+	lower := strings.ToLower(location)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// Resolve returns the location of a deployment, resolved relative to the
+// location of the manifest that refers to it. Absolute URLs and paths are
+// returned as-is.
+func Resolve(manifest, deployment string) (string, error) {
+	if IsURL(deployment) {
+		return deployment, nil
+	}
+	if IsURL(manifest) {
+		base, err := url.Parse(manifest)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(filepath.ToSlash(deployment))
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	if filepath.IsAbs(deployment) {
+		return deployment, nil
+	}
+	return filepath.Join(filepath.Dir(manifest), filepath.FromSlash(deployment)), nil
+}
+
+// FetchManifest retrieves and validates the assignment manifest at the
+// given location.
+func FetchManifest(ctx context.Context, client *http.Client, location string) (Manifest, error) {
+	data, err := fetch(ctx, client, location, MaxManifestSize)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("the assignment manifest could not be retrieved: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("the assignment manifest could not be parsed: %w", err)
+	}
+	if err := manifest.Validate(); err != nil {
+		return Manifest{}, err
+	}
+
+	return manifest, nil
+}
+
+// FetchDeployment retrieves and validates the deployment document at the
+// given location.
+func FetchDeployment(ctx context.Context, client *http.Client, location string) (lbdeploy.Deployment, error) {
+	data, err := fetch(ctx, client, location, MaxDeploymentSize)
+	if err != nil {
+		return lbdeploy.Deployment{}, fmt.Errorf("the deployment \"%s\" could not be retrieved: %w", location, err)
+	}
+
+	var dep lbdeploy.Deployment
+	if err := json.Unmarshal(data, &dep); err != nil {
+		return lbdeploy.Deployment{}, fmt.Errorf("the deployment \"%s\" could not be parsed: %w", location, err)
+	}
+	if err := dep.Validate(); err != nil {
+		return lbdeploy.Deployment{}, fmt.Errorf("the deployment \"%s\" is invalid: %w", location, err)
+	}
+
+	return dep, nil
+}
+
+// fetch reads up to limit bytes from a URL or file path.
+func fetch(ctx context.Context, client *http.Client, location string, limit int64) ([]byte, error) {
+	var r io.ReadCloser
+	if IsURL(location) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s", resp.Status)
+		}
+		r = resp.Body
+	} else {
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		r = file
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("the document exceeds the maximum size of %d bytes", limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,102 @@
+package lbassign_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbassign"
+)
+
+var testManifest = lbassign.Manifest{
+	Assignments: []lbassign.Assignment{
+		{
+			Name:       "lab",
+			Deployment: "7zip/deploy.json",
+			Flow:       "install",
+			Include:    lbassign.Selector{Machines: []string{"LAB-*"}, Groups: []string{"Lab Computers"}},
+			Exclude:    lbassign.Selector{Tags: []string{"kiosk"}},
+		},
+		{
+			Name:       "everyone",
+			Deployment: "agent/deploy.json",
+			Flow:       "install",
+			Include:    lbassign.Selector{Machines: []string{"*"}},
+		},
+		{
+			Name:       "finance",
+			Deployment: "erp/deploy.json",
+			Flow:       "install",
+			Include:    lbassign.Selector{Tags: []string{"finance"}},
+		},
+	},
+}
+
+func TestManifestMatch(t *testing.T) {
+	fixtures := []struct {
+		Name   string
+		Target lbassign.Target
+		Want   []string
+	}{
+		{Name: "lab-machine", Target: lbassign.Target{Machine: "lab-07"}, Want: []string{"lab", "everyone"}},
+		{Name: "lab-group", Target: lbassign.Target{Machine: "PC-12", Groups: []string{"lab computers"}}, Want: []string{"lab", "everyone"}},
+		{Name: "lab-kiosk", Target: lbassign.Target{Machine: "LAB-01", Tags: []string{"Kiosk"}}, Want: []string{"everyone"}},
+		{Name: "finance", Target: lbassign.Target{Machine: "PC-44", Tags: []string{"finance"}}, Want: []string{"everyone", "finance"}},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			var got []string
+			for _, assignment := range testManifest.Match(fixture.Target) {
+				got = append(got, assignment.Label())
+			}
+			if len(got) != len(fixture.Want) {
+				t.Fatalf("got %v, want %v", got, fixture.Want)
+			}
+			for i := range got {
+				if got[i] != fixture.Want[i] {
+					t.Fatalf("got %v, want %v", got, fixture.Want)
+				}
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	share := filepath.Join("share", "assignments.json")
+	fixtures := []struct {
+		Manifest   string
+		Deployment string
+		Want       string
+	}{
+		{Manifest: "https://example.com/fleet/assignments.json", Deployment: "7zip/deploy.json", Want: "https://example.com/fleet/7zip/deploy.json"},
+		{Manifest: "https://example.com/fleet/assignments.json", Deployment: "https://cdn.example.com/deploy.json", Want: "https://cdn.example.com/deploy.json"},
+		{Manifest: share, Deployment: "7zip/deploy.json", Want: filepath.Join("share", "7zip", "deploy.json")},
+	}
+
+	for _, fixture := range fixtures {
+		got, err := lbassign.Resolve(fixture.Manifest, fixture.Deployment)
+		if err != nil {
+			t.Errorf("%s: %v", fixture.Deployment, err)
+		} else if got != fixture.Want {
+			t.Errorf("%s: got \"%s\", want \"%s\"", fixture.Deployment, got, fixture.Want)
+		}
+	}
+}
+
+func TestFetchManifest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"assignments": [{"deployment": "a/deploy.json", "flow": "install", "include": {"machines": ["*"]}}]}`))
+	}))
+	defer server.Close()
+
+	manifest, err := lbassign.FetchManifest(context.Background(), server.Client(), server.URL+"/assignments.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Assignments) != 1 {
+		t.Fatalf("got %d assignments, want 1", len(manifest.Assignments))
+	}
+}
@@ -0,0 +1,177 @@
+// Package lbassign determines which deployments apply to a machine, based
+// on an assignment manifest that is maintained for a fleet of machines.
+//
+// An assignment manifest is a JSON document that maps machine names,
+// directory groups and tags to deployment documents. It can be published at
+// a URL or kept on a file share:
+//
+//	{
+//	  "assignments": [
+//	    {
+//	      "name": "7-Zip for lab machines",
+//	      "deployment": "7zip/deploy.json",
+//	      "flow": "install",
+//	      "include": {"machines": ["LAB-*"], "groups": ["Lab Computers"]},
+//	      "exclude": {"tags": ["kiosk"]}
+//	    }
+//	  ]
+//	}
+//
+// Deployment locations are resolved relative to the manifest.
+package lbassign
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// MaxManifestSize is the maximum size of an assignment manifest.
+const MaxManifestSize = 4 << 20
+
+// Manifest is an assignment manifest, which maps machines to the
+// deployments that should be applied to them.
+type Manifest struct {
+	Assignments []Assignment `json:"assignments"`
+}
+
+// Validate returns a non-nil error if the manifest contains invalid
+// configuration.
+func (m Manifest) Validate() error {
+	for i, assignment := range m.Assignments {
+		if err := assignment.Validate(); err != nil {
+			return fmt.Errorf("assignment %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the assignments in the manifest that apply to the given
+// target, in the order that they appear in the manifest.
+func (m Manifest) Match(target Target) []Assignment {
+	var matched []Assignment
+	for _, assignment := range m.Assignments {
+		if assignment.Applies(target) {
+			matched = append(matched, assignment)
+		}
+	}
+	return matched
+}
+
+// Assignment maps a set of machines to a flow within a deployment.
+//
+// An assignment applies to a machine if the machine is selected by its
+// include selector and is not selected by its exclude selector.
+//
+// Assignments with a higher priority are applied before those with a lower
+// priority when they compete for the same resources.
+type Assignment struct {
+	Name       string          `json:"name,omitempty"`
+	Deployment string          `json:"deployment"`
+	Flow       lbdeploy.FlowID `json:"flow"`
+	Priority   int             `json:"priority,omitempty"`
+	Include    Selector        `json:"include"`
+	Exclude    Selector        `json:"exclude,omitzero"`
+}
+
+// Label returns the name of the assignment, or the location of its
+// deployment if it does not have a name.
+func (a Assignment) Label() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.Deployment
+}
+
+// Validate returns a non-nil error if the assignment contains invalid
+// configuration.
+func (a Assignment) Validate() error {
+	if a.Deployment == "" {
+		return errors.New("a deployment location was not provided")
+	}
+	if !strings.HasSuffix(a.Deployment, "deploy.json") {
+		return fmt.Errorf("the deployment location \"%s\" does not end in deploy.json", a.Deployment)
+	}
+	if a.Flow == "" {
+		return errors.New("a flow was not provided")
+	}
+	if a.Include.IsZero() {
+		return errors.New("the assignment does not include any machines")
+	}
+	if err := a.Include.Validate(); err != nil {
+		return fmt.Errorf("include: %w", err)
+	}
+	if err := a.Exclude.Validate(); err != nil {
+		return fmt.Errorf("exclude: %w", err)
+	}
+	return nil
+}
+
+// Applies returns true if the assignment applies to the given target.
+func (a Assignment) Applies(target Target) bool {
+	return a.Include.Matches(target) && !a.Exclude.Matches(target)
+}
+
+// Selector selects machines by name, directory group membership or tag.
+//
+// Machine names can include wildcards that are interpreted by path.Match,
+// such as "LAB-*". A machine is selected if any of the names, groups or
+// tags match. All comparisons are case-insensitive.
+type Selector struct {
+	Machines []string `json:"machines,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// IsZero returns true if the selector does not select anything.
+func (s Selector) IsZero() bool {
+	return len(s.Machines) == 0 && len(s.Groups) == 0 && len(s.Tags) == 0
+}
+
+// Validate returns a non-nil error if the selector contains an invalid
+// machine name pattern.
+func (s Selector) Validate() error {
+	for _, pattern := range s.Machines {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("the machine name pattern \"%s\" is invalid: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the selector selects the given target.
+func (s Selector) Matches(target Target) bool {
+	machine := strings.ToLower(target.Machine)
+	for _, pattern := range s.Machines {
+		if matched, _ := path.Match(strings.ToLower(pattern), machine); matched {
+			return true
+		}
+	}
+	if containsFold(s.Groups, target.Groups) || containsFold(s.Tags, target.Tags) {
+		return true
+	}
+	return false
+}
+
+// Target describes a machine that assignments are evaluated against.
+type Target struct {
+	Machine string
+	Groups  []string
+	Tags    []string
+}
+
+// containsFold returns true if any of the wanted strings are present in
+// the list, without regard to case.
+func containsFold(wanted, list []string) bool {
+	for _, w := range wanted {
+		for _, item := range list {
+			if strings.EqualFold(w, item) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+// Package lbbundle reads and writes offline deployment bundles.
+//
+// A bundle is a zip archive that holds a deployment file along with the
+// files of every package that it references. It allows a deployment to be
+// invoked on machines that cannot reach the sources of its packages.
+//
+// Every file in a bundle is recorded in a manifest with its size and hashes,
+// so that the contents of the bundle can be verified before they are used.
+// The manifest also records the hashes of packages that rely on a checksums
+// file, which are resolved when the bundle is created.
+//
+// A bundle can also be appended to a copy of the leafbridge-deploy
+// executable, producing a self-extracting executable that invokes a chosen
+// flow when it is launched.
+package lbbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Names of the files within a bundle.
+const (
+	ManifestName = "bundle.json"
+	DocumentName = "deploy.json"
+	PackageDir   = "packages"
+)
+
+// MaxManifestSize is the maximum size of a bundle manifest or deployment
+// file within a bundle.
+const MaxManifestSize = 16 << 20 // 16 MiB
+
+// Bundle is an open deployment bundle.
+type Bundle struct {
+	path       string
+	closer     io.Closer
+	files      map[string]*zip.File
+	launch     Launch
+	manifest   Manifest
+	deployment lbdeploy.Deployment
+}
+
+// Open opens the bundle at the given path. The path can refer to a bundle or
+// to a self-extracting executable that contains one. It verifies the
+// bundle's manifest and deployment file, but it does not verify the bundle's
+// packages.
+//
+// It is the caller's responsibility to close the bundle when finished with
+// it.
+func Open(path string) (*Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the bundle: %w", err)
+	}
+
+	b, err := openFile(path, file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func openFile(path string, file *os.File) (*Bundle, error) {
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the bundle: %w", err)
+	}
+
+	// If the file is a self-extracting executable, locate the bundle
+	// within it.
+	var (
+		r      io.ReaderAt = file
+		size               = fi.Size()
+		launch Launch
+	)
+	t, err := readTrailer(file, size)
+	switch {
+	case err == nil:
+		r = io.NewSectionReader(file, t.Offset, t.Size)
+		size = t.Size
+		launch = t.Launch
+	case !errors.Is(err, ErrNotExecutable):
+		return nil, fmt.Errorf("the bundle \"%s\" is not valid: %w", path, err)
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the bundle: %w", err)
+	}
+
+	b := &Bundle{
+		path:   path,
+		closer: file,
+		files:  make(map[string]*zip.File, len(archive.File)),
+		launch: launch,
+	}
+	for _, file := range archive.File {
+		b.files[file.Name] = file
+	}
+
+	if err := b.load(); err != nil {
+		return nil, fmt.Errorf("the bundle \"%s\" is not valid: %w", path, err)
+	}
+
+	return b, nil
+}
+
+// load reads and verifies the manifest and deployment file of the bundle.
+func (b *Bundle) load() error {
+	// Read the manifest.
+	data, err := b.readFile(ManifestName)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &b.manifest); err != nil {
+		return fmt.Errorf("failed to parse the bundle manifest: %w", err)
+	}
+	if err := b.manifest.Validate(); err != nil {
+		return err
+	}
+
+	// Read and verify the deployment file.
+	document, err := b.readFile(b.manifest.Document.Path)
+	if err != nil {
+		return err
+	}
+	attributes, err := computeAttributes(bytes.NewReader(document), b.manifest.Document.Attributes.Hashes.Types()...)
+	if err != nil {
+		return err
+	}
+	if !lbdeploy.MatchFileAttributes(b.manifest.Document.Attributes, attributes) {
+		return errors.New("the deployment file does not match the hashes recorded in the bundle manifest")
+	}
+	if err := json.Unmarshal(document, &b.deployment); err != nil {
+		return fmt.Errorf("failed to parse the deployment file: %w", err)
+	}
+	if b.deployment.ID != b.manifest.Deployment {
+		return fmt.Errorf("the bundle manifest was created for the \"%s\" deployment, but the deployment file is for \"%s\"", b.manifest.Deployment, b.deployment.ID)
+	}
+
+	return nil
+}
+
+// Path returns the path of the bundle.
+func (b *Bundle) Path() string {
+	return b.path
+}
+
+// Launch returns the launch configuration of a self-extracting executable.
+// It returns a zero value if the bundle is not embedded in an executable.
+func (b *Bundle) Launch() Launch {
+	return b.launch
+}
+
+// Manifest returns the manifest of the bundle.
+func (b *Bundle) Manifest() Manifest {
+	return b.manifest
+}
+
+// Deployment returns the deployment contained in the bundle.
+func (b *Bundle) Deployment() lbdeploy.Deployment {
+	return b.deployment
+}
+
+// Package returns the manifest entry for the given package. It returns false
+// if the package is not present in the bundle.
+func (b *Bundle) Package(id lbdeploy.PackageID) (Entry, bool) {
+	entry, found := b.manifest.Packages[id]
+	return entry, found
+}
+
+// OpenPackage opens the file for the given package. The contents of the file
+// are not verified. It is the caller's responsibility to verify them against
+// the entry's attributes, and to close the file when finished with it.
+func (b *Bundle) OpenPackage(id lbdeploy.PackageID) (io.ReadCloser, Entry, error) {
+	entry, found := b.manifest.Packages[id]
+	if !found {
+		return nil, Entry{}, fmt.Errorf("the \"%s\" package is not present in the bundle", id)
+	}
+	file, found := b.files[entry.Path]
+	if !found {
+		return nil, Entry{}, fmt.Errorf("the file for the \"%s\" package is missing from the bundle: %w", id, fs.ErrNotExist)
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, Entry{}, err
+	}
+	return r, entry, nil
+}
+
+// Verify reads every package file in the bundle and verifies that it
+// matches the attributes recorded in the manifest.
+func (b *Bundle) Verify() error {
+	for _, id := range b.manifest.PackageIDs() {
+		if err := b.verifyPackage(id); err != nil {
+			return fmt.Errorf("package \"%s\": %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (b *Bundle) verifyPackage(id lbdeploy.PackageID) error {
+	r, entry, err := b.OpenPackage(id)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	attributes, err := computeAttributes(r, entry.Attributes.Hashes.Types()...)
+	if err != nil {
+		return err
+	}
+	if !lbdeploy.MatchFileAttributes(entry.Attributes, attributes) {
+		return errors.New("the package file does not match the hashes recorded in the bundle manifest")
+	}
+	return nil
+}
+
+// Close releases any resources consumed by the bundle.
+func (b *Bundle) Close() error {
+	return b.closer.Close()
+}
+
+// readFile reads the file with the given name from the bundle.
+func (b *Bundle) readFile(name string) ([]byte, error) {
+	file, found := b.files[name]
+	if !found {
+		return nil, fmt.Errorf("the bundle does not contain %s: %w", name, fs.ErrNotExist)
+	}
+	if file.UncompressedSize64 > MaxManifestSize {
+		return nil, fmt.Errorf("%s exceeds the maximum size of %d bytes", name, MaxManifestSize)
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(io.LimitReader(r, MaxManifestSize))
+}
+
+// packagePath returns the path of a package file within a bundle.
+func packagePath(id lbdeploy.PackageID, pkg lbdeploy.Package) string {
+	content := lbdeploy.PackageContent{
+		ID:          id,
+		PrimaryHash: pkg.Attributes.Hashes.Primary(),
+	}
+	return PackageDir + "/" + content.String() + "." + pkg.FileExtension()
+}
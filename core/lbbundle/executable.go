@@ -0,0 +1,129 @@
+package lbbundle
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// ErrNotExecutable is returned when a file is not a self-extracting
+// executable.
+var ErrNotExecutable = errors.New("the file does not contain an embedded bundle")
+
+// executableMagic marks the end of a self-extracting executable.
+const executableMagic = "LeafBridge Bundle 1"
+
+// maxLaunchSize is the maximum size of the launch configuration of a
+// self-extracting executable.
+const maxLaunchSize = 64 << 10 // 64 KiB
+
+// trailerSize is the size of the fixed portion of the trailer that follows
+// the launch configuration: the bundle's offset and size, the size of the
+// launch configuration and the magic string.
+const trailerSize = 8 + 8 + 4 + len(executableMagic)
+
+// Launch describes what a self-extracting executable does when it is
+// launched.
+type Launch struct {
+	Flow lbdeploy.FlowID `json:"flow"`
+}
+
+// trailer describes the location of a bundle within a self-extracting
+// executable.
+type trailer struct {
+	Offset int64
+	Size   int64
+	Launch Launch
+}
+
+// WriteExecutable writes a self-extracting executable to w. It is made up
+// of the stub executable, followed by the bundle and a trailer that holds
+// the launch configuration and the location of the bundle.
+//
+// If the stub is itself a self-extracting executable, its bundle is not
+// copied.
+//
+// The stub's Authenticode signature, if it has one, does not cover the
+// bundle. The contents of the bundle are verified by their hashes instead.
+func WriteExecutable(w io.Writer, stub *os.File, bundle io.Reader, launch Launch) error {
+	if launch.Flow == "" {
+		return errors.New("a flow must be provided for self-extracting executables")
+	}
+
+	// Determine how much of the stub to copy.
+	fi, err := stub.Stat()
+	if err != nil {
+		return err
+	}
+	stubSize := fi.Size()
+	if t, err := readTrailer(stub, stubSize); err == nil {
+		stubSize = t.Offset
+	} else if !errors.Is(err, ErrNotExecutable) {
+		return fmt.Errorf("failed to examine the stub executable: %w", err)
+	}
+
+	// Copy the stub and the bundle.
+	if _, err := io.Copy(w, io.NewSectionReader(stub, 0, stubSize)); err != nil {
+		return fmt.Errorf("failed to copy the stub executable: %w", err)
+	}
+	bundleSize, err := io.Copy(w, bundle)
+	if err != nil {
+		return fmt.Errorf("failed to copy the bundle: %w", err)
+	}
+
+	// Write the trailer.
+	config, err := json.Marshal(launch)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 0, len(config)+trailerSize)
+	buf = append(buf, config...)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(stubSize))
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(bundleSize))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(config)))
+	buf = append(buf, executableMagic...)
+	_, err = w.Write(buf)
+
+	return err
+}
+
+// readTrailer reads the trailer from the end of a self-extracting
+// executable with the given size. It returns ErrNotExecutable if the file
+// does not have a trailer.
+func readTrailer(r io.ReaderAt, size int64) (trailer, error) {
+	if size < int64(trailerSize) {
+		return trailer{}, ErrNotExecutable
+	}
+
+	var fixed [trailerSize]byte
+	if _, err := r.ReadAt(fixed[:], size-int64(trailerSize)); err != nil {
+		return trailer{}, err
+	}
+	if string(fixed[20:]) != executableMagic {
+		return trailer{}, ErrNotExecutable
+	}
+
+	offset := int64(binary.LittleEndian.Uint64(fixed[0:8]))
+	bundleSize := int64(binary.LittleEndian.Uint64(fixed[8:16]))
+	configSize := int64(binary.LittleEndian.Uint32(fixed[16:20]))
+	if configSize > maxLaunchSize || offset < 0 || bundleSize < 0 || offset+bundleSize+configSize+int64(trailerSize) != size {
+		return trailer{}, errors.New("the self-extracting executable has an invalid trailer")
+	}
+
+	config := make([]byte, configSize)
+	if _, err := r.ReadAt(config, offset+bundleSize); err != nil {
+		return trailer{}, err
+	}
+
+	t := trailer{Offset: offset, Size: bundleSize}
+	if err := json.Unmarshal(config, &t.Launch); err != nil {
+		return trailer{}, fmt.Errorf("failed to parse the launch configuration of the self-extracting executable: %w", err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,77 @@
+package lbbundle
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// defaultHashType is the hash type that is recorded for every file in a
+// bundle, in addition to any hashes declared by the deployment.
+const defaultHashType = filehash.SHA256
+
+// attributeWriter computes the size and hashes of the data written to it.
+type attributeWriter struct {
+	size   int64
+	hashes map[filehash.Type]hash.Hash
+}
+
+// newAttributeWriter returns an attribute writer that computes the default
+// hash type and the given hash types.
+func newAttributeWriter(hashTypes ...filehash.Type) (*attributeWriter, error) {
+	w := attributeWriter{
+		hashes: make(map[filehash.Type]hash.Hash, len(hashTypes)+1),
+	}
+	for _, typ := range append(hashTypes, defaultHashType) {
+		if _, exists := w.hashes[typ]; exists {
+			continue
+		}
+		switch typ {
+		case filehash.SHA3_256:
+			w.hashes[typ] = sha3.New256()
+		case filehash.SHA256:
+			w.hashes[typ] = sha256.New()
+		default:
+			return nil, fmt.Errorf("unrecognized file hash type \"%s\"", typ)
+		}
+	}
+	return &w, nil
+}
+
+// Write absorbs more file data into the writer's state.
+func (w *attributeWriter) Write(p []byte) (n int, err error) {
+	w.size += int64(len(p))
+	for _, h := range w.hashes {
+		h.Write(p)
+	}
+	return len(p), nil
+}
+
+// Attributes returns the attributes of the data written so far.
+func (w *attributeWriter) Attributes() lbdeploy.FileAttributes {
+	attributes := lbdeploy.FileAttributes{
+		Size:   w.size,
+		Hashes: make(filehash.Map, len(w.hashes)),
+	}
+	for typ, h := range w.hashes {
+		attributes.Hashes[typ] = h.Sum(nil)
+	}
+	return attributes
+}
+
+// computeAttributes reads r until EOF and returns its attributes.
+func computeAttributes(r io.Reader, hashTypes ...filehash.Type) (lbdeploy.FileAttributes, error) {
+	w, err := newAttributeWriter(hashTypes...)
+	if err != nil {
+		return lbdeploy.FileAttributes{}, err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return lbdeploy.FileAttributes{}, err
+	}
+	return w.Attributes(), nil
+}
@@ -0,0 +1,176 @@
+package lbbundle_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/lbbundle"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+const document = `{"id": "example", "name": "Example"}`
+
+func TestBundle(t *testing.T) {
+	content := []byte("example installer")
+	sum := sha256.Sum256(content)
+	wrong := sha256.Sum256([]byte("something else"))
+
+	fixtures := []struct {
+		Name    string
+		Package lbdeploy.Package
+		OK      bool
+	}{
+		{Name: "match", Package: lbdeploy.Package{Type: "msi", Attributes: lbdeploy.FileAttributes{Size: int64(len(content)), Hashes: filehash.Map{filehash.SHA256: sum[:]}}}, OK: true},
+		{Name: "any-size", Package: lbdeploy.Package{Type: "exe", Attributes: lbdeploy.FileAttributes{Hashes: filehash.Map{filehash.SHA256: sum[:]}}}, OK: true},
+		{Name: "wrong-size", Package: lbdeploy.Package{Type: "exe", Attributes: lbdeploy.FileAttributes{Size: 4, Hashes: filehash.Map{filehash.SHA256: sum[:]}}}, OK: false},
+		{Name: "wrong-hash", Package: lbdeploy.Package{Type: "exe", Attributes: lbdeploy.FileAttributes{Hashes: filehash.Map{filehash.SHA256: wrong[:]}}}, OK: false},
+		{Name: "no-hash", Package: lbdeploy.Package{Type: "exe", Attributes: lbdeploy.FileAttributes{Size: int64(len(content))}}, OK: false},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := lbbundle.NewWriter(&buf)
+			if err := w.SetDocument([]byte(document)); err != nil {
+				t.Fatalf("failed to add the deployment file: %v", err)
+			}
+			err := w.AddPackage("app", fixture.Package, bytes.NewReader(content))
+			switch {
+			case fixture.OK && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case !fixture.OK && err == nil:
+				t.Fatal("expected an error but none was returned")
+			case !fixture.OK:
+				return
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("failed to close the bundle: %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), "example.zip")
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := lbbundle.Open(path)
+			if err != nil {
+				t.Fatalf("failed to open the bundle: %v", err)
+			}
+			defer b.Close()
+
+			if id := b.Deployment().ID; id != "example" {
+				t.Errorf("unexpected deployment ID: %s", id)
+			}
+			if err := b.Verify(); err != nil {
+				t.Errorf("the bundle did not pass verification: %v", err)
+			}
+			if _, found := b.Package("app"); !found {
+				t.Error("the package is missing from the bundle")
+			}
+		})
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	var buf bytes.Buffer
+	w := lbbundle.NewWriter(&buf)
+	if err := w.SetDocument([]byte(document)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copy the bundle, replacing its deployment file with another one.
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tampered bytes.Buffer
+	zw := zip.NewWriter(&tampered)
+	for _, file := range r.File {
+		if file.Name == lbbundle.DocumentName {
+			fw, err := zw.Create(file.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write([]byte(`{"id": "example", "name": "Tampered"}`))
+			continue
+		}
+		if err := zw.Copy(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "example.zip")
+	if err := os.WriteFile(path, tampered.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := lbbundle.Open(path); err == nil {
+		b.Close()
+		t.Fatal("a tampered bundle was opened without error")
+	}
+}
+
+func TestExecutable(t *testing.T) {
+	var bundle bytes.Buffer
+	w := lbbundle.NewWriter(&bundle)
+	if err := w.SetDocument([]byte(document)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	stub := []byte("MZ example stub")
+	stubPath := filepath.Join(dir, "stub.exe")
+	if err := os.WriteFile(stubPath, stub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Produce an executable from the stub, then another one from the
+	// first executable. Both should contain a single copy of the stub.
+	paths := []string{filepath.Join(dir, "first.exe"), filepath.Join(dir, "second.exe")}
+	flows := []lbdeploy.FlowID{"install", "uninstall"}
+	for i, path := range paths {
+		src, err := os.Open(stubPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var exe bytes.Buffer
+		err = lbbundle.WriteExecutable(&exe, src, bytes.NewReader(bundle.Bytes()), lbbundle.Launch{Flow: flows[i]})
+		src.Close()
+		if err != nil {
+			t.Fatalf("failed to write the executable: %v", err)
+		}
+		if err := os.WriteFile(path, exe.Bytes(), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(exe.Bytes(), stub) || bytes.Count(exe.Bytes(), stub) != 1 {
+			t.Errorf("%s: the executable does not start with a single copy of the stub", path)
+		}
+
+		b, err := lbbundle.Open(path)
+		if err != nil {
+			t.Fatalf("failed to open the executable as a bundle: %v", err)
+		}
+		if flow := b.Launch().Flow; flow != flows[i] {
+			t.Errorf("%s: unexpected launch flow: %s", path, flow)
+		}
+		if id := b.Deployment().ID; id != "example" {
+			t.Errorf("%s: unexpected deployment ID: %s", path, id)
+		}
+		b.Close()
+
+		stubPath = path
+	}
+}
@@ -0,0 +1,73 @@
+package lbbundle
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Manifest describes the contents of a bundle.
+//
+// Architecture is the architecture that the bundled packages were selected
+// for, when the deployment provides different sources for each
+// architecture.
+type Manifest struct {
+	Deployment   lbdeploy.DeploymentID        `json:"deployment"`
+	Created      time.Time                    `json:"created,omitzero"`
+	Architecture lbdeploy.Architecture        `json:"architecture,omitempty"`
+	Document     Entry                        `json:"document"`
+	Packages     map[lbdeploy.PackageID]Entry `json:"packages,omitempty"`
+}
+
+// PackageIDs returns the identifiers of the packages in the manifest in
+// sorted order.
+func (m Manifest) PackageIDs() []lbdeploy.PackageID {
+	return slices.Sorted(maps.Keys(m.Packages))
+}
+
+// Validate returns a non-nil error if the manifest is incomplete.
+func (m Manifest) Validate() error {
+	if m.Deployment == "" {
+		return errors.New("the bundle manifest does not identify a deployment")
+	}
+	if m.Architecture != "" {
+		if err := m.Architecture.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := m.Document.Validate(); err != nil {
+		return fmt.Errorf("document: %w", err)
+	}
+	for id, entry := range m.Packages {
+		if err := entry.Validate(); err != nil {
+			return fmt.Errorf("package \"%s\": %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Entry describes a file within a bundle.
+type Entry struct {
+	Path       string                  `json:"path"`
+	Attributes lbdeploy.FileAttributes `json:"attributes"`
+}
+
+// Validate returns a non-nil error if the entry is incomplete or refers to
+// a path outside of the bundle.
+func (entry Entry) Validate() error {
+	if entry.Path == "" {
+		return errors.New("the path is missing")
+	}
+	if !fs.ValidPath(entry.Path) || entry.Path == "." {
+		return fmt.Errorf("the path \"%s\" is not a valid path within a bundle", entry.Path)
+	}
+	if len(entry.Attributes.Hashes) == 0 {
+		return fmt.Errorf("the file \"%s\" does not have any hashes", entry.Path)
+	}
+	return entry.Attributes.Validate()
+}
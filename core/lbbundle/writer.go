@@ -0,0 +1,139 @@
+package lbbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Writer writes a bundle to an underlying writer.
+//
+// The deployment file must be added with SetDocument, followed by each of
+// its packages. The manifest is written when the writer is closed.
+type Writer struct {
+	zw       *zip.Writer
+	manifest Manifest
+}
+
+// NewWriter returns a bundle writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		zw: zip.NewWriter(w),
+		manifest: Manifest{
+			Created:  time.Now().UTC(),
+			Packages: make(map[lbdeploy.PackageID]Entry),
+		},
+	}
+}
+
+// SetDocument adds the given deployment file to the bundle. The document
+// must hold a valid deployment.
+func (w *Writer) SetDocument(document []byte) error {
+	if w.manifest.Document.Path != "" {
+		return errors.New("the bundle already contains a deployment file")
+	}
+
+	var dep lbdeploy.Deployment
+	if err := json.Unmarshal(document, &dep); err != nil {
+		return fmt.Errorf("failed to parse the deployment file: %w", err)
+	}
+	if err := dep.Validate(); err != nil {
+		return err
+	}
+
+	attributes, err := w.writeFile(DocumentName, zip.Deflate, bytes.NewReader(document))
+	if err != nil {
+		return err
+	}
+
+	w.manifest.Deployment = dep.ID
+	w.manifest.Document = Entry{Path: DocumentName, Attributes: attributes}
+
+	return nil
+}
+
+// SetArchitecture records the architecture that the bundle's packages
+// were selected for.
+func (w *Writer) SetArchitecture(arch lbdeploy.Architecture) {
+	w.manifest.Architecture = arch
+}
+
+// AddPackage copies the file for a package from r into the bundle. The
+// package's attributes must include at least one hash, which the file is
+// verified against. Packages that rely on a checksums file must have their
+// hashes resolved before they are added.
+//
+// Package files are stored without compression, because installers and
+// archives are usually compressed already.
+func (w *Writer) AddPackage(id lbdeploy.PackageID, pkg lbdeploy.Package, r io.Reader) error {
+	if w.manifest.Document.Path == "" {
+		return errors.New("the deployment file must be added to the bundle before its packages")
+	}
+	if _, exists := w.manifest.Packages[id]; exists {
+		return fmt.Errorf("the \"%s\" package has already been added to the bundle", id)
+	}
+	if len(pkg.Attributes.Hashes) == 0 {
+		return fmt.Errorf("the \"%s\" package does not have any hashes for verification", id)
+	}
+
+	path := packagePath(id, pkg)
+	attributes, err := w.writeFile(path, zip.Store, r, pkg.Attributes.Hashes.Types()...)
+	if err != nil {
+		return fmt.Errorf("failed to add the \"%s\" package to the bundle: %w", id, err)
+	}
+	if !lbdeploy.MatchFileAttributes(pkg.Attributes, attributes) {
+		return fmt.Errorf("the file for the \"%s\" package did not pass its file verification checks", id)
+	}
+
+	w.manifest.Packages[id] = Entry{Path: path, Attributes: attributes}
+
+	return nil
+}
+
+// Close writes the manifest and finishes writing the bundle. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if w.manifest.Document.Path == "" {
+		return errors.New("the bundle does not contain a deployment file")
+	}
+
+	data, err := json.MarshalIndent(w.manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	if _, err := w.writeFile(ManifestName, zip.Deflate, bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	return w.zw.Close()
+}
+
+// writeFile writes a file to the bundle and returns its attributes,
+// including the given hash types.
+func (w *Writer) writeFile(name string, method uint16, r io.Reader, hashTypes ...filehash.Type) (lbdeploy.FileAttributes, error) {
+	file, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: w.manifest.Created,
+	})
+	if err != nil {
+		return lbdeploy.FileAttributes{}, err
+	}
+
+	aw, err := newAttributeWriter(hashTypes...)
+	if err != nil {
+		return lbdeploy.FileAttributes{}, err
+	}
+	if _, err := io.Copy(io.MultiWriter(file, aw), r); err != nil {
+		return lbdeploy.FileAttributes{}, err
+	}
+
+	return aw.Attributes(), nil
+}
@@ -0,0 +1,142 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxLocalUserNameLength is the maximum length of a local user name on
+// Windows.
+const maxLocalUserNameLength = 20
+
+// LocalUser describes a local user account that is created on, or removed
+// from, the local system.
+//
+// Users are idempotent by name. When a user is created, Password supplies
+// its initial password. If the user already exists, its password is left
+// alone, but its full name, description and flags are updated to match.
+type LocalUser struct {
+	Name                 string `json:"name"`
+	FullName             string `json:"full-name,omitempty"`
+	Description          string `json:"description,omitempty"`
+	Password             Secret `json:"password,omitzero"`
+	Disabled             bool   `json:"disabled,omitempty"`
+	PasswordNeverExpires bool   `json:"password-never-expires,omitempty"`
+	Remove               bool   `json:"remove,omitempty"`
+}
+
+// Validate returns an error if the user is not valid.
+func (user LocalUser) Validate() error {
+	if err := ValidateLocalUserName(user.Name); err != nil {
+		return err
+	}
+	if user.Remove {
+		if user.FullName != "" || user.Description != "" || !user.Password.IsZero() || user.Disabled || user.PasswordNeverExpires {
+			return errors.New("only a name can be provided when removing a user")
+		}
+		return nil
+	}
+	if user.Password.IsZero() {
+		return fmt.Errorf("a password was not provided for \"%s\"", user.Name)
+	}
+	if err := user.Password.Validate(); err != nil {
+		return fmt.Errorf("password: %w", err)
+	}
+	return nil
+}
+
+// ValidateLocalUserName returns an error if name is not a valid local user
+// name.
+func ValidateLocalUserName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("a user name was not provided")
+	case len([]rune(name)) > maxLocalUserNameLength:
+		return fmt.Errorf("the user name \"%s\" is longer than %d characters", name, maxLocalUserNameLength)
+	case strings.ContainsAny(name, "\"/\\[]:;|=,+*?<>@"):
+		return fmt.Errorf("the user name \"%s\" contains an invalid character", name)
+	case strings.Trim(name, ". ") == "":
+		return fmt.Errorf("the user name \"%s\" is not valid", name)
+	}
+	return nil
+}
+
+// GroupMembership describes an account that is added to, or removed
+// from, a local group.
+//
+// Group is the name of a local group, such as "Administrators". Member is
+// the name of a user or group, which may be qualified by a domain, such as
+// "CONTOSO\Workstation Admins" or "NT SERVICE\MSSQLSERVER".
+type GroupMembership struct {
+	Group  string `json:"group"`
+	Member string `json:"member"`
+	Remove bool   `json:"remove,omitempty"`
+}
+
+// Validate returns an error if the membership is not valid.
+func (membership GroupMembership) Validate() error {
+	if membership.Group == "" {
+		return errors.New("a group was not provided")
+	}
+	if strings.ContainsAny(membership.Group, "\\/") {
+		return fmt.Errorf("\"%s\" is not the name of a local group", membership.Group)
+	}
+	if err := validateAccountName(membership.Member); err != nil {
+		return fmt.Errorf("member: %w", err)
+	}
+	return nil
+}
+
+// RightAssignment describes a user right that is granted to, or revoked
+// from, an account through the local security policy.
+//
+// Right is the constant name of a user right or privilege, such as
+// "SeServiceLogonRight" for the right to log on as a service. Account is
+// the name of a user or group, which may be qualified by a domain.
+type RightAssignment struct {
+	Right   string `json:"right"`
+	Account string `json:"account"`
+	Remove  bool   `json:"remove,omitempty"`
+}
+
+// Validate returns an error if the assignment is not valid.
+func (assignment RightAssignment) Validate() error {
+	if err := ValidateUserRight(assignment.Right); err != nil {
+		return err
+	}
+	if err := validateAccountName(assignment.Account); err != nil {
+		return fmt.Errorf("account: %w", err)
+	}
+	return nil
+}
+
+// ValidateUserRight returns an error if right is not the constant name of
+// a user right or privilege.
+func ValidateUserRight(right string) error {
+	if right == "" {
+		return errors.New("a user right was not provided")
+	}
+	if !strings.HasPrefix(right, "Se") || (!strings.HasSuffix(right, "Right") && !strings.HasSuffix(right, "Privilege")) {
+		return fmt.Errorf("\"%s\" is not the name of a user right or privilege, such as \"SeServiceLogonRight\"", right)
+	}
+	for _, c := range right {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return fmt.Errorf("\"%s\" is not the name of a user right or privilege", right)
+		}
+	}
+	return nil
+}
+
+// validateAccountName returns an error if name is not a valid account
+// name, optionally qualified by a domain.
+func validateAccountName(name string) error {
+	if name == "" {
+		return errors.New("an account name was not provided")
+	}
+	domain, account, qualified := strings.Cut(name, `\`)
+	if qualified && (domain == "" || account == "" || strings.Contains(account, `\`)) {
+		return fmt.Errorf("\"%s\" is not a valid account name", name)
+	}
+	return nil
+}
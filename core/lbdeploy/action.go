@@ -1,28 +1,213 @@
 package lbdeploy
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
 // ActionType identifies the type of action.
 type ActionType string
 
 // Recognized action types.
 const (
-	ActionStartFlow      ActionType = "start-flow"
-	ActionPreparePackage ActionType = "prepare-package"
-	ActionInvokeCommand  ActionType = "invoke-command"
-	ActionCopyFile       ActionType = "copy-file"
-	ActionDeleteFile     ActionType = "delete-file"
+	ActionStartFlow                  ActionType = "start-flow"
+	ActionPreparePackage             ActionType = "prepare-package"
+	ActionInvokeCommand              ActionType = "invoke-command"
+	ActionCopyFile                   ActionType = "copy-file"
+	ActionDeleteFile                 ActionType = "delete-file"
+	ActionBackupFile                 ActionType = "backup-file"
+	ActionRestoreFile                ActionType = "restore-file"
+	ActionPowerShellScript           ActionType = "powershell-script"
+	ActionCmdScript                  ActionType = "cmd-script"
+	ActionShellScript                ActionType = "shell-script"
+	ActionWaitForRegistry            ActionType = "wait-for-registry-value"
+	ActionEditINIFile                ActionType = "edit-ini-file"
+	ActionEditXMLFile                ActionType = "edit-xml-file"
+	ActionEditJSONFile               ActionType = "edit-json-file"
+	ActionEditHostsFile              ActionType = "edit-hosts-file"
+	ActionSuspendBitLocker           ActionType = "suspend-bitlocker"
+	ActionResumeBitLocker            ActionType = "resume-bitlocker"
+	ActionSetTimeZone                ActionType = "set-time-zone"
+	ActionRegisterLogonFlow          ActionType = "register-logon-flow"
+	ActionConfigureBrowserExtensions ActionType = "configure-browser-extensions"
+	ActionSetBrowserPolicies         ActionType = "set-browser-policies"
+	ActionSetDefaultAssociations     ActionType = "set-default-associations"
+	ActionSetStartLayout             ActionType = "set-start-layout"
+	ActionClearStartLayout           ActionType = "clear-start-layout"
+	ActionConfigureLocalUsers        ActionType = "configure-local-users"
+	ActionConfigureLocalGroups       ActionType = "configure-local-groups"
+	ActionConfigureUserRights        ActionType = "configure-user-rights"
+	ActionSetPowerPlan               ActionType = "set-power-plan"
+	ActionConfigurePowerSettings     ActionType = "configure-power-settings"
+	ActionActivateLicense            ActionType = "activate-license"
 )
 
 // Action describes an action to be taken as part of a flow.
+//
+// If conditions are provided, the action is only invoked when all of them
+// pass. Otherwise the action is skipped.
+//
+// OnError determines what happens when the action fails:
+//
+//   - stop: The flow stops and reports the failure. The "fail" option is
+//     accepted as an alias of stop.
+//   - continue: The failure is ignored and the flow proceeds.
+//   - goto-flow: The flow stops and the flow identified by OnErrorFlow is
+//     started in its place. If that flow succeeds the failure is handled.
+//
+// If OnError is not specified, the on-error behavior of the flow applies.
+//
+// If Retry is provided, an action that fails is attempted again according
+// to its retry policy before its on-error policy is applied.
+//
+// If a timeout is provided, it limits the amount of time that each attempt
+// of the action is allowed to run. When it is exceeded, the attempt is
+// stopped, the processes it started are terminated and the attempt fails.
+//
+// A wait-for-registry-value action waits until its condition is satisfied.
+// The condition must examine a registry key or value. If the condition is
+// not satisfied within the action's timeout, the action fails. If a timeout
+// is not provided, a default of five minutes applies. The timeout of a
+// wait-for-registry-value action does not stop it in any other way.
+//
+// The edit-ini-file, edit-xml-file and edit-json-file actions apply their
+// edits to the destination file in order. The file is created if it does
+// not exist. Formatting that is unrelated to the edits is preserved.
+//
+// The edit-hosts-file action adds and removes hosts entries in order. A
+// backup of the hosts file is made before it is changed.
+//
+// The suspend-bitlocker action suspends BitLocker protection of the system
+// drive for the number of reboots given by RebootCount, which must be
+// between 1 and 15. Protection resumes automatically after that many
+// reboots, or when a resume-bitlocker action runs.
+//
+// A copy-file action leaves an existing destination file alone, unless
+// Replace is true. If the destination file of a copy-file or delete-file
+// action is locked by another process, the processes using it are reported
+// and the action fails, unless OnLocked is replace-on-reboot, in which case
+// the change is scheduled for the next restart of the system.
+//
+// Copy-file, delete-file and restore-file actions refuse to modify files in
+// protected folders, such as the Windows system folder, unless OnProtected
+// is take-ownership. Deployments should only opt in when they must replace
+// files that ship with the operating system.
+//
+// The backup-file action backs up the destination file, and the
+// restore-file action restores the destination file from the most recent
+// backup made during the same run. Restoring a backup of a file that did
+// not exist removes the file. These actions are typically paired with an
+// on-error flow.
+//
+// The set-time-zone action sets the time zone of the local system to
+// TimeZone, which is a Windows time zone identifier, such as
+// "W. Europe Standard Time".
+//
+// The register-logon-flow action registers Flow to be run in the context of
+// each user the next time they log on. Each user runs the flow once for each
+// registration, so registering the flow again causes it to run again for
+// every user.
+//
+// The configure-browser-extensions action adds extensions to, or removes
+// them from, the force-install lists of the browsers they are intended
+// for, along with their managed preferences. The set-browser-policies
+// action applies machine policies to browsers. Both are applied through
+// the policy locations that each browser reads.
+//
+// The set-default-associations action sets the default applications for
+// file extensions and protocols. The associations are kept in a default
+// application association file that is imported by DISM, which applies
+// them to users that sign in for the first time. If Enforce is true, the
+// file is also applied at each logon of every user through group policy.
+// Windows protects the choices that existing users have made, so enforcing
+// the associations is the only way to change them.
+//
+// The set-start-layout action deploys the Start menu and taskbar layout in
+// SourceFile, which must be a LayoutModification.xml file. The layout is
+// placed in the default user profile, which applies it to users that sign
+// in for the first time. If Enforce is true, the layout is also applied to
+// every user through group policy, and users cannot change it. The
+// clear-start-layout action removes a layout deployed in either way.
+//
+// The configure-local-users action creates and removes local user
+// accounts. The configure-local-groups action adds accounts to, and
+// removes them from, local groups. The configure-user-rights action grants
+// and revokes user rights, such as the right to log on as a service.
+// Entries are applied in order.
+//
+// The set-power-plan action makes PowerPlan the active power plan. If
+// SourceFile is provided, it must be a power scheme exported by powercfg,
+// which is imported under the GUID given by PowerPlan before it is made
+// active. The configure-power-settings action applies PowerSettings, which
+// adjust the sleep and display timeouts of the active plan and enable or
+// disable hibernation.
+//
+// The activate-license action activates the volume license of Windows or
+// Office, as described by Activation. A product key is installed first if
+// one is provided. The action only succeeds if the license status of the
+// product is licensed afterwards.
 type Action struct {
-	Type            ActionType          `json:"action"`
-	Package         PackageID           `json:"package,omitempty"`
-	Command         CommandID           `json:"command,omitempty"`
-	Force           bool                `json:"force,omitempty"`
-	Flow            FlowID              `json:"flow,omitempty"`
-	SourceFile      FileResourceID      `json:"source-file,omitempty"`
-	SourceDir       DirectoryResourceID `json:"source-directory,omitempty"`
-	DestinationFile FileResourceID      `json:"destination-file,omitempty"`
-	DestinationDir  DirectoryResourceID `json:"destination-directory,omitempty"`
+	Type            ActionType            `json:"action"`
+	Conditions      ConditionList         `json:"conditions,omitzero"`
+	OnError         OnErrorBehavior       `json:"on-error,omitempty"`
+	OnErrorFlow     FlowID                `json:"on-error-flow,omitempty"`
+	Retry           ActionRetry           `json:"retry,omitzero"`
+	Package         PackageID             `json:"package,omitempty"`
+	Command         CommandID             `json:"command,omitempty"`
+	Force           bool                  `json:"force,omitempty"`
+	Flow            FlowID                `json:"flow,omitempty"`
+	SourceFile      FileResourceID        `json:"source-file,omitempty"`
+	SourceDir       DirectoryResourceID   `json:"source-directory,omitempty"`
+	DestinationFile FileResourceID        `json:"destination-file,omitempty"`
+	DestinationDir  DirectoryResourceID   `json:"destination-directory,omitempty"`
+	Script          Script                `json:"script,omitzero"`
+	Condition       ConditionID           `json:"condition,omitempty"`
+	Timeout         datatype.Duration     `json:"timeout,omitzero"`
+	Edits           []ConfigEdit          `json:"edits,omitzero"`
+	HostsEntries    []HostsEntry          `json:"hosts-entries,omitzero"`
+	RebootCount     int                   `json:"reboot-count,omitempty"`
+	TimeZone        string                `json:"time-zone,omitempty"`
+	Replace         bool                  `json:"replace,omitempty"`
+	OnLocked        FileLockedBehavior    `json:"on-locked,omitempty"`
+	OnProtected     ProtectedFileBehavior `json:"on-protected,omitempty"`
+	Extensions      []BrowserExtension    `json:"browser-extensions,omitzero"`
+	BrowserPolicies []BrowserPolicy       `json:"browser-policies,omitzero"`
+	Associations    []FileAssociation     `json:"associations,omitzero"`
+	Enforce         bool                  `json:"enforce,omitempty"`
+	LocalUsers      []LocalUser           `json:"local-users,omitzero"`
+	GroupMembers    []GroupMembership     `json:"group-members,omitzero"`
+	UserRights      []RightAssignment     `json:"user-rights,omitzero"`
+	PowerPlan       PowerPlan             `json:"power-plan,omitempty"`
+	PowerSettings   []PowerSetting        `json:"power-settings,omitzero"`
+	Activation      LicenseActivation     `json:"activation,omitzero"`
+}
+
+// MaxBitLockerRebootCount is the largest number of reboots that a
+// suspend-bitlocker action can suspend protection for.
+const MaxBitLockerRebootCount = 15
+
+// DefaultRegistryWaitTimeout is the amount of time that a
+// wait-for-registry-value action waits when a timeout is not specified.
+const DefaultRegistryWaitTimeout = datatype.Duration(5 * time.Minute)
+
+// ValidateErrorPolicy returns an error if the on-error policy of the action
+// is not valid.
+func (action Action) ValidateErrorPolicy() error {
+	switch action.OnError {
+	case OnErrorUnspecified, OnErrorStop, OnErrorContinue:
+		if action.OnErrorFlow != "" {
+			return fmt.Errorf("an on-error flow was provided without the \"%s\" on-error policy", OnErrorGotoFlow)
+		}
+	case OnErrorGotoFlow:
+		if action.OnErrorFlow == "" {
+			return fmt.Errorf("the \"%s\" on-error policy requires an on-error flow", OnErrorGotoFlow)
+		}
+	default:
+		return fmt.Errorf("the \"%s\" on-error policy is not recognized", action.OnError)
+	}
+	return nil
 }
 
 /*
@@ -3,6 +3,8 @@ package lbdeploy
 import (
 	"fmt"
 	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lberror"
 )
 
 // AppMap holds a set of applications mapped by their identifiers.
@@ -62,12 +64,22 @@ type ProductCode string
 //
 // Alternatively, a condition may be specified that determines whether the
 // application is installed.
+//
+// On Linux, the application is mapped to a distribution package that is
+// looked up and managed by the system's package manager. On macOS, it is
+// identified by its application bundle or installer package receipt.
+//
+// If a desired state is provided, the application's presence is checked
+// when the compliance of the deployment is evaluated.
 type Application struct {
 	Name         string          `json:"name"`
 	Architecture AppArchitecture `json:"architecture,omitempty"`
 	Scope        AppScope        `json:"scope,omitempty"`
 	ProductCode  ProductCode     `json:"product-code,omitempty"`
 	Detection    AppDetection    `json:"detection,omitempty"`
+	Package      DistroPackage   `json:"package,omitzero"`
+	Mac          MacApp          `json:"mac,omitzero"`
+	Desired      DesiredState    `json:"desired,omitempty"`
 }
 
 // AppDetection describes how to detect the presence of an installed
@@ -145,11 +157,11 @@ func (s AppSummary) IsZero() bool {
 func (s AppSummary) Err() error {
 	switch {
 	case len(s.StillNotInstalled) > 0 && len(s.StillNotUninstalled) > 0:
-		return fmt.Errorf("some applications were not installed (%s) and some applications were not uninstalled (%s)", s.StillNotInstalled, s.StillNotUninstalled)
+		return lberror.Wrap(lberror.Installer, fmt.Errorf("some applications were not installed (%s) and some applications were not uninstalled (%s)", s.StillNotInstalled, s.StillNotUninstalled))
 	case len(s.StillNotInstalled) > 0:
-		return fmt.Errorf("the following applications were not installed properly: %s", s.StillNotInstalled)
+		return lberror.Wrap(lberror.Installer, fmt.Errorf("the following applications were not installed properly: %s", s.StillNotInstalled))
 	case len(s.StillNotUninstalled) > 0:
-		return fmt.Errorf("the following applications were not uninstalled properly: %s", s.StillNotUninstalled)
+		return lberror.Wrap(lberror.Installer, fmt.Errorf("the following applications were not uninstalled properly: %s", s.StillNotUninstalled))
 	default:
 		return nil
 	}
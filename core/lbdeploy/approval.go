@@ -0,0 +1,126 @@
+package lbdeploy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Approval records who approved a deployment, and under which change
+// ticket.
+//
+// Each approver can sign the deployment with an ed25519 key. Signatures
+// cover the whole deployment document, including the rest of its approval
+// metadata, so they are invalidated by any change to it. Whether approvals are
+// required, and whose signatures are trusted, is decided by the execution
+// policy of each machine.
+type Approval struct {
+	Approvers  []string            `json:"approvers,omitempty"`
+	Ticket     string              `json:"ticket,omitempty"`
+	Expires    time.Time           `json:"expires,omitzero"`
+	Signatures []ApprovalSignature `json:"signatures,omitempty"`
+}
+
+// ApprovalSignature is a base64-encoded ed25519 signature of a deployment
+// by one of its approvers.
+type ApprovalSignature struct {
+	Approver  string `json:"approver"`
+	Signature string `json:"signature"`
+}
+
+// IsZero returns true if no approval metadata has been provided.
+func (approval Approval) IsZero() bool {
+	return len(approval.Approvers) == 0 && approval.Ticket == "" && approval.Expires.IsZero() && len(approval.Signatures) == 0
+}
+
+// Validate returns a non-nil error if the approval metadata is invalid.
+func (approval Approval) Validate() error {
+	for i, approver := range approval.Approvers {
+		if approver == "" {
+			return fmt.Errorf("approver %d is missing an identity", i+1)
+		}
+		if slices.Contains(approval.Approvers[:i], approver) {
+			return fmt.Errorf("the approver \"%s\" is listed more than once", approver)
+		}
+	}
+	for i, sig := range approval.Signatures {
+		if !slices.Contains(approval.Approvers, sig.Approver) {
+			return fmt.Errorf("signature %d: \"%s\" is not one of the approvers", i+1, sig.Approver)
+		}
+		if _, err := base64.StdEncoding.DecodeString(sig.Signature); err != nil {
+			return fmt.Errorf("signature %d: the signature is not valid base64: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Expired returns true if the approval has an expiry date that has passed
+// at the given time.
+func (approval Approval) Expired(now time.Time) bool {
+	return !approval.Expires.IsZero() && !now.Before(approval.Expires)
+}
+
+// ApprovalDigest returns the SHA-256 digest of the deployment document
+// from which the deployment was decoded. This is the digest that is signed
+// by its approvers.
+//
+// It returns an error if the deployment was not decoded from a document,
+// because a deployment built in memory has nothing that an approver could
+// have signed.
+func (dep Deployment) ApprovalDigest() ([]byte, error) {
+	if dep.source == nil {
+		return nil, errors.New("the deployment was not decoded from a deployment document, so its approval can't be verified")
+	}
+	return ApprovalDigest(dep.source)
+}
+
+// ApprovalDigest returns the SHA-256 digest of a deployment document that
+// is signed by its approvers.
+//
+// The digest covers a canonical form of the whole document with its
+// approval signatures removed. Every field of the document is included,
+// even those that LeafBridge does not recognize, so that nothing can be
+// added to the document after it has been signed. In the canonical form,
+// whitespace is removed, object members are sorted by name and numbers
+// are kept as they were written.
+func ApprovalDigest(doc []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+
+	var root map[string]any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("the deployment could not be decoded for approval: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("the deployment could not be decoded for approval: unexpected data after the deployment")
+	}
+
+	if approval, ok := root["approval"].(map[string]any); ok {
+		delete(approval, "signatures")
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("the deployment could not be encoded for approval: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	return digest[:], nil
+}
+
+// UnmarshalJSON decodes the deployment from data. A copy of data is kept,
+// so that the approval of the deployment can be verified against the
+// document that was actually decoded.
+func (dep *Deployment) UnmarshalJSON(data []byte) error {
+	type plain Deployment
+	if err := json.Unmarshal(data, (*plain)(dep)); err != nil {
+		return err
+	}
+	dep.source = bytes.Clone(data)
+	return nil
+}
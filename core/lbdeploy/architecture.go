@@ -0,0 +1,200 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Architecture identifies the processor architecture of an operating
+// system.
+type Architecture string
+
+// Recognized architectures.
+const (
+	ArchitectureX64   Architecture = "x64"
+	ArchitectureARM64 Architecture = "arm64"
+	ArchitectureX86   Architecture = "x86"
+)
+
+// Validate returns a non-nil error if the architecture is not recognized.
+func (arch Architecture) Validate() error {
+	switch arch {
+	case ArchitectureX64, ArchitectureARM64, ArchitectureX86:
+		return nil
+	case "":
+		return errors.New("the architecture is missing")
+	default:
+		return fmt.Errorf("the architecture \"%s\" is not recognized", arch)
+	}
+}
+
+// Compatible returns the architectures whose code can run on an operating
+// system with the given architecture, in order of preference. Native code
+// is preferred over code that runs under emulation.
+func (arch Architecture) Compatible() []Architecture {
+	switch arch {
+	case ArchitectureARM64:
+		return []Architecture{ArchitectureARM64, ArchitectureX64, ArchitectureX86}
+	case ArchitectureX64:
+		return []Architecture{ArchitectureX64, ArchitectureX86}
+	default:
+		return []Architecture{arch}
+	}
+}
+
+// PackageVariant describes where the file of a package can be retrieved
+// for a particular architecture, and how it can be verified.
+type PackageVariant struct {
+	Sources    []PackageSource `json:"sources,omitempty"`
+	Attributes FileAttributes  `json:"attributes,omitzero"`
+	Checksums  ChecksumsFile   `json:"checksums,omitzero"`
+}
+
+// Validate returns a non-nil error if the package variant contains invalid
+// configuration.
+func (v PackageVariant) Validate() error {
+	if len(v.Sources) == 0 {
+		return errors.New("the variant has no sources")
+	}
+	for i, source := range v.Sources {
+		if err := source.Validate(); err != nil {
+			return fmt.Errorf("source %d: %w", i, err)
+		}
+	}
+	if err := v.Attributes.Validate(); err != nil {
+		return fmt.Errorf("file attributes: %w", err)
+	}
+	if !v.Checksums.IsZero() {
+		if len(v.Attributes.Hashes) > 0 {
+			return errors.New("file hashes cannot be provided alongside a checksums file")
+		}
+		if err := v.Checksums.Validate(); err != nil {
+			return fmt.Errorf("checksums file: %w", err)
+		}
+	}
+	return nil
+}
+
+// ForArchitecture returns the package as it applies to an operating system
+// with the given architecture.
+//
+// If the package provides sources for architectures that are compatible
+// with arch, the sources, attributes and checksums file of the most
+// preferred one replace those of the package. Otherwise the package's own
+// sources are used, if it has any. An error is returned if the package
+// provides sources for other architectures only.
+func (pkg Package) ForArchitecture(arch Architecture) (Package, error) {
+	if len(pkg.Architectures) == 0 {
+		return pkg, nil
+	}
+
+	for _, candidate := range arch.Compatible() {
+		if variant, found := pkg.Architectures[candidate]; found {
+			return pkg.withVariant(variant), nil
+		}
+	}
+
+	if len(pkg.Sources) == 0 {
+		return Package{}, fmt.Errorf("the package does not provide sources for the %s architecture", arch)
+	}
+
+	pkg.Architectures = nil
+	return pkg, nil
+}
+
+// Variants returns the package as it applies to each of the architectures
+// that it provides sources for, in sorted order. The package's own
+// sources are included when it has any, or when it does not provide
+// sources for particular architectures.
+func (pkg Package) Variants() []Package {
+	var variants []Package
+	if len(pkg.Architectures) == 0 || len(pkg.Sources) > 0 {
+		base := pkg
+		base.Architectures = nil
+		variants = append(variants, base)
+	}
+	for _, arch := range slices.Sorted(maps.Keys(pkg.Architectures)) {
+		variants = append(variants, pkg.withVariant(pkg.Architectures[arch]))
+	}
+	return variants
+}
+
+// ArchitectureSpecific returns true if any of the packages in the map
+// provides sources for particular architectures.
+func (m PackageMap) ArchitectureSpecific() bool {
+	for _, pkg := range m {
+		if len(pkg.Architectures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// withVariant returns the package with the sources, attributes and
+// checksums file of the given variant.
+func (pkg Package) withVariant(variant PackageVariant) Package {
+	pkg.Sources = variant.Sources
+	pkg.Attributes = variant.Attributes
+	pkg.Checksums = variant.Checksums
+	pkg.Architectures = nil
+	return pkg
+}
+
+// ForArchitecture returns the deployment as it applies to an operating
+// system with the given architecture, with each of its packages replaced
+// by the result of [Package.ForArchitecture].
+//
+// If the deployment declares the architectures that it supports, an error
+// is returned for any other architecture.
+func (dep Deployment) ForArchitecture(arch Architecture) (Deployment, error) {
+	if len(dep.Architectures) > 0 && !slices.Contains(dep.Architectures, arch) {
+		return Deployment{}, fmt.Errorf("the \"%s\" deployment does not support the %s architecture (supported: %s)", dep.ID, arch, joinArchitectures(dep.Architectures))
+	}
+
+	packages := make(PackageMap, len(dep.Resources.Packages))
+	for id, pkg := range dep.Resources.Packages {
+		selected, err := pkg.ForArchitecture(arch)
+		if err != nil {
+			return Deployment{}, fmt.Errorf("package \"%s\": %w", id, err)
+		}
+		packages[id] = selected
+	}
+	dep.Resources.Packages = packages
+
+	return dep, nil
+}
+
+// validateArchitectures returns a non-nil error if the architectures that
+// the deployment supports are invalid, or if any of its packages is not
+// available for one of them.
+func (dep Deployment) validateArchitectures() error {
+	for i, arch := range dep.Architectures {
+		if err := arch.Validate(); err != nil {
+			return fmt.Errorf("architectures: %w", err)
+		}
+		if slices.Contains(dep.Architectures[:i], arch) {
+			return fmt.Errorf("architectures: the %s architecture is listed more than once", arch)
+		}
+		for _, id := range dep.Resources.Packages.IDs() {
+			if _, err := dep.Resources.Packages[id].ForArchitecture(arch); err != nil {
+				return fmt.Errorf("package \"%s\": %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// joinArchitectures returns the given architectures as a comma-separated
+// list.
+func joinArchitectures(archs []Architecture) string {
+	var out string
+	for i, arch := range archs {
+		if i > 0 {
+			out += ", "
+		}
+		out += string(arch)
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestPackageForArchitecture(t *testing.T) {
+	source := func(url string) []lbdeploy.PackageSource {
+		return []lbdeploy.PackageSource{{Type: lbdeploy.PackageSourceHTTP, URL: url}}
+	}
+	multi := lbdeploy.Package{
+		Type: "msi",
+		Architectures: map[lbdeploy.Architecture]lbdeploy.PackageVariant{
+			"x64":   {Sources: source("https://example.com/app-x64.msi")},
+			"arm64": {Sources: source("https://example.com/app-arm64.msi")},
+		},
+	}
+	fallback := lbdeploy.Package{
+		Type:    "msi",
+		Sources: source("https://example.com/app-x86.msi"),
+		Architectures: map[lbdeploy.Architecture]lbdeploy.PackageVariant{
+			"arm64": {Sources: source("https://example.com/app-arm64.msi")},
+		},
+	}
+
+	tests := []struct {
+		Name         string
+		Package      lbdeploy.Package
+		Architecture lbdeploy.Architecture
+		URL          string
+		Valid        bool
+	}{
+		{Name: "x64", Package: multi, Architecture: "x64", URL: "https://example.com/app-x64.msi", Valid: true},
+		{Name: "arm64", Package: multi, Architecture: "arm64", URL: "https://example.com/app-arm64.msi", Valid: true},
+		{Name: "x86-missing", Package: multi, Architecture: "x86"},
+		{Name: "fallback-native", Package: fallback, Architecture: "arm64", URL: "https://example.com/app-arm64.msi", Valid: true},
+		{Name: "fallback-default", Package: fallback, Architecture: "x64", URL: "https://example.com/app-x86.msi", Valid: true},
+		{Name: "single", Package: lbdeploy.Package{Type: "msi", Sources: source("https://example.com/app.msi")}, Architecture: "arm64", URL: "https://example.com/app.msi", Valid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			pkg, err := test.Package.ForArchitecture(test.Architecture)
+			if !test.Valid {
+				if err == nil {
+					t.Fatalf("expected an error for the %s architecture", test.Architecture)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pkg.Sources) != 1 || pkg.Sources[0].URL != test.URL {
+				t.Errorf("got sources %v, want %s", pkg.Sources, test.URL)
+			}
+			if len(pkg.Architectures) != 0 {
+				t.Errorf("the selected package still has %d architectures", len(pkg.Architectures))
+			}
+		})
+	}
+}
+
+func TestDeploymentArchitectures(t *testing.T) {
+	dep := lbdeploy.Deployment{
+		ID:            "example",
+		Architectures: []lbdeploy.Architecture{"x64", "arm64"},
+		Resources: lbdeploy.Resources{
+			Packages: lbdeploy.PackageMap{
+				"app": {
+					Type: "msi",
+					Architectures: map[lbdeploy.Architecture]lbdeploy.PackageVariant{
+						"x64": {Sources: []lbdeploy.PackageSource{{Type: lbdeploy.PackageSourceHTTP, URL: "https://example.com/app-x64.msi"}}},
+					},
+				},
+			},
+		},
+	}
+
+	// The x64 variant runs under emulation on arm64, so both architectures
+	// are covered.
+	if err := dep.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	// Architectures that the deployment does not support are refused.
+	if _, err := dep.ForArchitecture("x86"); err == nil {
+		t.Error("expected an error for an unsupported architecture")
+	}
+
+	// Required architectures must be covered by every package.
+	dep.Architectures = append(dep.Architectures, "x86")
+	if err := dep.Validate(); err == nil {
+		t.Error("expected a validation error for an architecture that a package does not cover")
+	}
+}
@@ -0,0 +1,45 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/appassoc"
+)
+
+// FileAssociation describes the default application for a file extension
+// or protocol.
+//
+// Identifier is a file extension with a leading dot, such as ".pdf", or a
+// protocol, such as "mailto". ProgID is the programmatic identifier that
+// the application registers for it, and Application is the name of the
+// application as Windows presents it.
+//
+// Associations are idempotent by identifier. When an association is
+// removed, the identifier reverts to the choice of each user.
+type FileAssociation struct {
+	Identifier  string `json:"identifier"`
+	ProgID      string `json:"prog-id,omitempty"`
+	Application string `json:"application,omitempty"`
+	Remove      bool   `json:"remove,omitempty"`
+}
+
+// Validate returns an error if the association is not valid.
+func (assoc FileAssociation) Validate() error {
+	if err := appassoc.ValidateIdentifier(assoc.Identifier); err != nil {
+		return err
+	}
+	if assoc.Remove {
+		if assoc.ProgID != "" || assoc.Application != "" {
+			return errors.New("a ProgID or application cannot be provided when removing an association")
+		}
+		return nil
+	}
+	if assoc.ProgID == "" {
+		return fmt.Errorf("a ProgID was not provided for \"%s\"", assoc.Identifier)
+	}
+	if assoc.Application == "" {
+		return fmt.Errorf("an application was not provided for \"%s\"", assoc.Identifier)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestDeploymentBackupActions(t *testing.T) {
+	resources := lbdeploy.Resources{
+		FileSystem: lbdeploy.FileSystemResources{
+			Files: lbdeploy.FileResourceMap{"app-config": {Path: "app.json"}},
+		},
+	}
+	flows := func(actions ...lbdeploy.Action) lbdeploy.FlowMap {
+		return lbdeploy.FlowMap{"install": {Backup: true, Actions: actions}}
+	}
+
+	testDeploymentFixtures(t, []deploymentFixture{
+		{
+			Name:      "backup-and-restore",
+			Resources: resources,
+			Flows: flows(
+				lbdeploy.Action{Type: lbdeploy.ActionBackupFile, DestinationFile: "app-config"},
+				lbdeploy.Action{Type: lbdeploy.ActionRestoreFile, DestinationFile: "app-config"},
+			),
+		},
+		{
+			Name:      "backup-missing-destination",
+			Resources: resources,
+			Flows:     flows(lbdeploy.Action{Type: lbdeploy.ActionBackupFile}),
+			Err:       "action 1: a destination file was not provided",
+		},
+		{
+			Name:      "restore-undefined-destination",
+			Resources: resources,
+			Flows:     flows(lbdeploy.Action{Type: lbdeploy.ActionRestoreFile, DestinationFile: "app-data"}),
+			Err:       "action 1: the destination file refers to a file resource ID that is not defined: app-data",
+		},
+	})
+}
@@ -4,12 +4,26 @@ package lbdeploy
 type OnErrorBehavior string
 
 // Behavior options when an error is encountered.
+//
+// The goto-flow option is only supported by actions.
 const (
 	OnErrorUnspecified OnErrorBehavior = ""
 	OnErrorStop        OnErrorBehavior = "stop"
 	OnErrorContinue    OnErrorBehavior = "continue"
+	OnErrorGotoFlow    OnErrorBehavior = "goto-flow"
 )
 
+// UnmarshalText attempts to unmarshal the given text into b. The "fail"
+// option is accepted as an alias of "stop".
+func (b *OnErrorBehavior) UnmarshalText(text []byte) error {
+	if string(text) == "fail" {
+		*b = OnErrorStop
+	} else {
+		*b = OnErrorBehavior(text)
+	}
+	return nil
+}
+
 // Behavior describes behavior modifications for a deployment or flow.
 type Behavior struct {
 	OnError OnErrorBehavior `json:"on-error,omitempty"`
@@ -0,0 +1,176 @@
+package lbdeploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Browser identifies a web browser whose extensions and policies can be
+// managed by a deployment.
+type Browser string
+
+// Supported browsers.
+const (
+	BrowserChrome  Browser = "chrome"
+	BrowserEdge    Browser = "edge"
+	BrowserFirefox Browser = "firefox"
+)
+
+// Validate returns an error if the browser is not supported.
+func (browser Browser) Validate() error {
+	switch browser {
+	case BrowserChrome, BrowserEdge, BrowserFirefox:
+		return nil
+	case "":
+		return errors.New("a browser was not provided")
+	default:
+		return fmt.Errorf("the \"%s\" browser is not supported", browser)
+	}
+}
+
+// IsChromium returns true if the browser is based on Chromium, and reads
+// its policies in the same format as Chrome.
+func (browser Browser) IsChromium() bool {
+	return browser == BrowserChrome || browser == BrowserEdge
+}
+
+// DefaultUpdateURL returns the update URL of the extension store of the
+// browser. It returns an empty string for browsers that do not have one.
+func (browser Browser) DefaultUpdateURL() string {
+	switch browser {
+	case BrowserChrome:
+		return "https://clients2.google.com/service/update2/crx"
+	case BrowserEdge:
+		return "https://edge.microsoft.com/extensionwebstorebase/v1/crx"
+	default:
+		return ""
+	}
+}
+
+// BrowserExtension describes an extension that is force-installed in, or
+// removed from, a browser.
+//
+// For Chrome and Edge, ID is the 32 character extension ID and URL is the
+// update URL of the extension, which defaults to the browser's own store.
+// For Firefox, ID is the add-on ID and URL is the location of the
+// extension's XPI file, which must be provided.
+//
+// Settings are managed preferences that the browser supplies to the
+// extension through its managed storage. Each setting is a JSON value.
+// When settings are provided, they replace any managed preferences that
+// were previously applied to the extension.
+//
+// Entries are idempotent by browser and ID. When an extension is removed,
+// it is taken off the force-install list of the browser and its managed
+// preferences are removed.
+type BrowserExtension struct {
+	Browser  Browser                    `json:"browser"`
+	ID       string                     `json:"id"`
+	URL      string                     `json:"url,omitempty"`
+	Settings map[string]json.RawMessage `json:"settings,omitzero"`
+	Remove   bool                       `json:"remove,omitempty"`
+}
+
+// Validate returns an error if the extension is not valid.
+func (ext BrowserExtension) Validate() error {
+	if err := ext.Browser.Validate(); err != nil {
+		return err
+	}
+	if ext.ID == "" {
+		return errors.New("an extension ID was not provided")
+	}
+	if ext.Browser.IsChromium() {
+		if !isChromiumExtensionID(ext.ID) {
+			return fmt.Errorf("\"%s\" is not a valid %s extension ID", ext.ID, ext.Browser)
+		}
+	} else if strings.ContainsAny(ext.ID, "\\\"") {
+		return fmt.Errorf("\"%s\" is not a valid %s add-on ID", ext.ID, ext.Browser)
+	}
+	if ext.Remove {
+		if ext.URL != "" {
+			return errors.New("a URL cannot be provided when removing an extension")
+		}
+		if len(ext.Settings) > 0 {
+			return errors.New("settings cannot be provided when removing an extension")
+		}
+		return nil
+	}
+	if ext.URL == "" {
+		if ext.Browser.DefaultUpdateURL() == "" {
+			return fmt.Errorf("a URL was not provided for the \"%s\" extension", ext.ID)
+		}
+	} else if u, err := url.Parse(ext.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("the URL of the \"%s\" extension is not a valid https URL", ext.ID)
+	}
+	for name, value := range ext.Settings {
+		if name == "" {
+			return fmt.Errorf("the \"%s\" extension has a setting without a name", ext.ID)
+		}
+		if !json.Valid(value) {
+			return fmt.Errorf("the \"%s\" setting of the \"%s\" extension is not valid JSON", name, ext.ID)
+		}
+	}
+	return nil
+}
+
+// EffectiveURL returns the URL of the extension, or the default update URL
+// of the browser if one was not provided.
+func (ext BrowserExtension) EffectiveURL() string {
+	if ext.URL != "" {
+		return ext.URL
+	}
+	return ext.Browser.DefaultUpdateURL()
+}
+
+// BrowserPolicy describes a policy that is applied to a browser.
+//
+// Name is the name of the policy as it is documented by the browser
+// vendor, such as "HomepageLocation". Value is the JSON value of the
+// policy. A null value removes the policy.
+type BrowserPolicy struct {
+	Browser Browser         `json:"browser"`
+	Name    string          `json:"name"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// Validate returns an error if the policy is not valid.
+func (policy BrowserPolicy) Validate() error {
+	if err := policy.Browser.Validate(); err != nil {
+		return err
+	}
+	if policy.Name == "" {
+		return errors.New("a policy name was not provided")
+	}
+	if strings.ContainsRune(policy.Name, '\\') {
+		return fmt.Errorf("the policy name \"%s\" is not valid", policy.Name)
+	}
+	if len(policy.Value) == 0 {
+		return fmt.Errorf("a value was not provided for the \"%s\" policy", policy.Name)
+	}
+	if !json.Valid(policy.Value) {
+		return fmt.Errorf("the value of the \"%s\" policy is not valid JSON", policy.Name)
+	}
+	return nil
+}
+
+// Removes returns true if the policy removes any existing value.
+func (policy BrowserPolicy) Removes() bool {
+	return strings.TrimSpace(string(policy.Value)) == "null"
+}
+
+// isChromiumExtensionID returns true if id is a valid extension ID for
+// Chrome or Edge, which consists of 32 letters from a to p.
+func isChromiumExtensionID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	for _, c := range id {
+		if c < 'a' || c > 'p' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package lbdeploy_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestBrowserExtensionValidate(t *testing.T) {
+	const chromeID = "cjpalhdlnbpafiamejdnhcphjbkeiagm"
+
+	fixtures := []struct {
+		Name      string
+		Extension lbdeploy.BrowserExtension
+		Valid     bool
+	}{
+		{Name: "chrome-store", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserChrome, ID: chromeID}, Valid: true},
+		{Name: "edge-custom-url", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserEdge, ID: chromeID, URL: "https://example.com/update.xml"}, Valid: true},
+		{Name: "chrome-bad-id", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserChrome, ID: "uBlock0@raymondhill.net"}},
+		{Name: "chrome-http-url", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserChrome, ID: chromeID, URL: "http://example.com/update.xml"}},
+		{Name: "firefox", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserFirefox, ID: "uBlock0@raymondhill.net", URL: "https://addons.mozilla.org/firefox/downloads/latest/ublock-origin/latest.xpi"}, Valid: true},
+		{Name: "firefox-no-url", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserFirefox, ID: "uBlock0@raymondhill.net"}},
+		{Name: "firefox-remove", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserFirefox, ID: "uBlock0@raymondhill.net", Remove: true}, Valid: true},
+		{Name: "remove-with-settings", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserChrome, ID: chromeID, Remove: true, Settings: map[string]json.RawMessage{"mode": json.RawMessage(`"strict"`)}}},
+		{Name: "bad-setting", Extension: lbdeploy.BrowserExtension{Browser: lbdeploy.BrowserChrome, ID: chromeID, Settings: map[string]json.RawMessage{"mode": json.RawMessage(`strict`)}}},
+		{Name: "unknown-browser", Extension: lbdeploy.BrowserExtension{Browser: "opera", ID: chromeID}},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			err := fixture.Extension.Validate()
+			if fixture.Valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if !fixture.Valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package lbdeploy
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CertificateMap holds a set of certificate resources mapped by their
+// identifiers.
+type CertificateMap map[CertificateID]CertificateResource
+
+// CertificateID is a unique identifier for a certificate resource.
+type CertificateID string
+
+// CertificateResource describes a certificate within a certificate store
+// of the local machine.
+//
+// A certificate is identified by its thumbprint, its subject, or both. The
+// subject matches either the common name or the full distinguished name
+// of the certificate, without regard to case.
+//
+// Certificates that are expired or not yet valid are ignored unless
+// IncludeExpired is true.
+type CertificateResource struct {
+	// Store is the name of the certificate store, such as "My", "Root",
+	// "CA" or "TrustedPublisher".
+	Store string `json:"store"`
+
+	// Thumbprint is the SHA-1 thumbprint of the certificate in
+	// hexadecimal.
+	Thumbprint string `json:"thumbprint,omitempty"`
+
+	// Subject is the common name or distinguished name of the
+	// certificate's subject.
+	Subject string `json:"subject,omitempty"`
+
+	// IncludeExpired causes certificates outside of their validity period
+	// to be considered.
+	IncludeExpired bool `json:"include-expired,omitempty"`
+}
+
+// Validate returns an error if the certificate resource is not valid.
+func (cert CertificateResource) Validate() error {
+	if cert.Store == "" {
+		return errors.New("a certificate store was not provided")
+	}
+	if strings.ContainsAny(cert.Store, `\/`) {
+		return fmt.Errorf("the certificate store name \"%s\" is not valid", cert.Store)
+	}
+	if cert.Thumbprint == "" && cert.Subject == "" {
+		return errors.New("a thumbprint or subject was not provided")
+	}
+	if cert.Thumbprint != "" {
+		if b, err := hex.DecodeString(cert.NormalizedThumbprint()); err != nil || len(b) != 20 {
+			return fmt.Errorf("the thumbprint \"%s\" is not a valid SHA-1 thumbprint", cert.Thumbprint)
+		}
+	}
+	return nil
+}
+
+// NormalizedThumbprint returns the thumbprint in lowercase hexadecimal
+// without separators. The invisible left-to-right mark that is often
+// copied along with thumbprints from the certificate manager is removed.
+func (cert CertificateResource) NormalizedThumbprint() string {
+	return strings.ToLower(strings.NewReplacer(" ", "", ":", "", "\u200e", "").Replace(cert.Thumbprint))
+}
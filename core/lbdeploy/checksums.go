@@ -0,0 +1,237 @@
+package lbdeploy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/pgpsig"
+)
+
+// ChecksumsFile describes a detached checksums file that holds the expected
+// hash of a package, such as a SHA256SUMS file published alongside a
+// vendor's release.
+//
+// When a package refers to a checksums file, its hashes are retrieved from
+// the file when the package is needed, so that the deployment doesn't need
+// to be edited for every release. The file is expected to hold one entry
+// per line in the form produced by sha256sum, or in the BSD form produced
+// by shasum --tag.
+//
+// If a signature is provided, the checksums file must be signed by the
+// given public key or it will be rejected.
+type ChecksumsFile struct {
+	Source    PackageSource      `json:"source"`
+	Entry     string             `json:"entry,omitempty"`
+	HashType  filehash.Type      `json:"hash-type,omitempty"`
+	Signature ChecksumsSignature `json:"signature,omitzero"`
+}
+
+// IsZero returns true if a checksums file has not been specified.
+func (c ChecksumsFile) IsZero() bool {
+	return c.Source.Type == "" && c.Source.URL == ""
+}
+
+// Validate returns a non-nil error if the checksums file is invalid.
+func (c ChecksumsFile) Validate() error {
+	if err := c.Source.Validate(); err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	if c.HashType != "" && c.HashType.Priority() == 0 {
+		return fmt.Errorf("the file hash type \"%s\" is not recognized", c.HashType)
+	}
+	if !c.Signature.IsZero() {
+		if err := c.Signature.Validate(); err != nil {
+			return fmt.Errorf("signature: %w", err)
+		}
+	}
+	return nil
+}
+
+// HashTypeOrDefault returns the type of hash held by the checksums file.
+// If a type was not specified, it returns SHA-256.
+func (c ChecksumsFile) HashTypeOrDefault() filehash.Type {
+	if c.HashType == "" {
+		return filehash.SHA256
+	}
+	return c.HashType
+}
+
+// EntryFor returns the name of the entry in the checksums file that holds
+// the hash for the package. If an entry name was not specified, the file
+// name of the package's first source URL is used.
+func (c ChecksumsFile) EntryFor(pkg Package) (string, error) {
+	if c.Entry != "" {
+		return c.Entry, nil
+	}
+	if len(pkg.Sources) == 0 {
+		return "", errors.New("an entry name was not provided and the package has no sources")
+	}
+	u, err := url.Parse(pkg.Sources[0].URL)
+	if err != nil {
+		return "", fmt.Errorf("an entry name was not provided and the package source URL is invalid: %w", err)
+	}
+	name := path.Base(u.Path)
+	if name == "" || name == "." || name == "/" {
+		return "", errors.New("an entry name was not provided and one could not be determined from the package source URL")
+	}
+	return name, nil
+}
+
+// Lookup parses the content of a checksums file and returns the hash value
+// for the given entry.
+func (c ChecksumsFile) Lookup(data []byte, entry string) (filehash.Value, error) {
+	var found filehash.Value
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var value, name string
+		if algorithm, rest, ok := strings.Cut(line, " ("); ok && !strings.Contains(algorithm, " ") {
+			// BSD form: SHA256 (name) = value
+			n, v, ok := strings.Cut(rest, ") = ")
+			if !ok {
+				continue
+			}
+			name, value = n, v
+		} else {
+			// GNU form: value  name, or value *name for binary mode.
+			v, n, ok := strings.Cut(line, " ")
+			if !ok {
+				continue
+			}
+			value, name = v, strings.TrimPrefix(strings.TrimLeft(n, " "), "*")
+		}
+
+		if name != entry && path.Base(name) != entry {
+			continue
+		}
+
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("the checksum for \"%s\" is not a valid hexadecimal value: %w", entry, err)
+		}
+		if found != nil && !bytes.Equal(found, b) {
+			return nil, fmt.Errorf("the checksums file contains conflicting entries for \"%s\"", entry)
+		}
+		found = b
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("the checksums file does not contain an entry for \"%s\"", entry)
+	}
+	return found, nil
+}
+
+// Checksums signature types.
+const (
+	SignatureEd25519 SignatureType = "ed25519"
+	SignatureOpenPGP SignatureType = "openpgp"
+)
+
+// SignatureType identifies the type of a detached signature.
+type SignatureType string
+
+// ChecksumsSignature describes a detached signature for a checksums file.
+//
+// The signature is retrieved from its source.
+//
+// For ed25519 signatures, the signature can be provided as raw bytes or as
+// base64-encoded text, and the public key is base64-encoded.
+//
+// For openpgp signatures, such as those produced by gpg --detach-sign, the
+// signature can be ASCII-armored or binary, and the public key is an
+// ASCII-armored public key block. Version 4 RSA and Ed25519 keys are
+// supported, and signatures made by their signing subkeys are accepted.
+type ChecksumsSignature struct {
+	Type      SignatureType `json:"type"`
+	Source    PackageSource `json:"source"`
+	PublicKey string        `json:"public-key"`
+}
+
+// IsZero returns true if a signature has not been specified.
+func (sig ChecksumsSignature) IsZero() bool {
+	return sig.Type == "" && sig.Source.URL == "" && sig.PublicKey == ""
+}
+
+// Validate returns a non-nil error if the signature configuration is
+// invalid.
+func (sig ChecksumsSignature) Validate() error {
+	switch sig.Type {
+	case "":
+		return errors.New("the signature type is missing")
+	case SignatureEd25519, SignatureOpenPGP:
+	default:
+		return fmt.Errorf("the signature type \"%s\" is not supported", sig.Type)
+	}
+	if err := sig.Source.Validate(); err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	if sig.Type == SignatureOpenPGP {
+		if _, err := pgpsig.ParsePublicKey([]byte(sig.PublicKey)); err != nil {
+			return fmt.Errorf("the OpenPGP public key is not valid: %w", err)
+		}
+		return nil
+	}
+	if _, err := sig.publicKey(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Verify returns a non-nil error if the signature is not a valid signature
+// of data.
+func (sig ChecksumsSignature) Verify(data, signature []byte) error {
+	if sig.Type == SignatureOpenPGP {
+		key, err := pgpsig.ParsePublicKey([]byte(sig.PublicKey))
+		if err != nil {
+			return fmt.Errorf("the OpenPGP public key is not valid: %w", err)
+		}
+		if err := key.VerifyDetached(data, signature); err != nil {
+			return fmt.Errorf("the OpenPGP signature is not valid for the checksums file: %w", err)
+		}
+		return nil
+	}
+
+	key, err := sig.publicKey()
+	if err != nil {
+		return err
+	}
+
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+		if err != nil {
+			return errors.New("the signature is neither a raw nor a base64-encoded ed25519 signature")
+		}
+		signature = decoded
+	}
+
+	if !ed25519.Verify(key, data, signature) {
+		return errors.New("the signature is not valid for the checksums file")
+	}
+	return nil
+}
+
+func (sig ChecksumsSignature) publicKey() (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(sig.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("the public key is not valid base64: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("the public key is %d bytes long, but ed25519 public keys are %d bytes long", len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
@@ -0,0 +1,123 @@
+package lbdeploy_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+const testChecksums = `# Release checksums
+3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855a  tool-1.2.0-amd64.zip
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 *tool-1.2.0-arm64.zip
+SHA256 (dist/tool-1.2.0.msi) = 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824
+`
+
+var checksumsFixtures = []struct {
+	Entry string
+	Value string
+	Err   bool
+}{
+	{Entry: "tool-1.2.0-amd64.zip", Value: "3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855a"},
+	{Entry: "tool-1.2.0-arm64.zip", Value: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	{Entry: "tool-1.2.0.msi", Value: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+	{Entry: "tool-1.1.0.msi", Err: true},
+}
+
+// testOpenPGPKey and testOpenPGPSig were produced by GnuPG. The signature
+// of testChecksums was made by a signing subkey.
+const testOpenPGPKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatIaoxYJKwYBBAHaRw8BAQdAnaxByo1DrX4vDn7XzJsTvJnp78ju5OsLxHAU
+X8OdMNW0GlN1YiBUZXN0IDxzdWJAZXhhbXBsZS5jb20+iJAEExYIADgWIQSO02Mo
+cmUfZBxfBh9xHyHp1eHnlwUCatIaowIbAQULCQgHAgYVCgkICwIEFgIDAQIeAQIX
+gAAKCRBxHyHp1eHnl3mfAP45hyYCCh7FVv+1aIwsKFUS822zNNXoelkOGNMbwmEf
+KQD/diUlrUzpoUpjz/sEFPLJIRIVCjpzh2taAtzeAfc0HQG4MwRq0hqjFgkrBgEE
+AdpHDwEBB0CkFWw+9ifjseZsb6W8kuylO+3Qech001m0DaPjfr922YjvBBgWCAAg
+FiEEjtNjKHJlH2QcXwYfcR8h6dXh55cFAmrSGqMCGwIAgQkQcR8h6dXh55d2IAQZ
+FggAHRYhBD8Ip93qrzwB/kRQrULXW87nm8RTBQJq0hqjAAoJEELXW87nm8RTHq0B
+AMuFyJgHhkyEJB2oJkO0pHxuXcNRuxPdDwzMJdCkoOLGAP9gcIYNuNAtPh82nJ6z
+muDEhtDl/MatIHL0JBD9YrOABdNpAQCN3PNg1FVtfNXsPQY2KT0Eb03eeerCJYQy
+Ms8SocFGxgD+N94Z/Wl23+opfG10sgZWVHuTrFO4tJCHP7bOCVUDRQ0=
+=o2ei
+-----END PGP PUBLIC KEY BLOCK-----`
+
+const testOpenPGPSig = `-----BEGIN PGP SIGNATURE-----
+
+iHUEABYIAB0WIQQ/CKfd6q88Af5EUK1C11vO55vEUwUCatIbcQAKCRBC11vO55vE
+U5d5AP0WxfTAqyPuaoY6C0lqFUs4B0yTlUkMFibHJDXp4ZdvQQEA+c37lBaKQL0E
+dvCo/AMwJ1MLNLAlQcQzUdDEC238KQA=
+=bNZW
+-----END PGP SIGNATURE-----`
+
+func TestChecksumsLookup(t *testing.T) {
+	var checksums lbdeploy.ChecksumsFile
+	for _, fixture := range checksumsFixtures {
+		t.Run(fixture.Entry, func(t *testing.T) {
+			value, err := checksums.Lookup([]byte(testChecksums), fixture.Entry)
+			if fixture.Err {
+				if err == nil {
+					t.Fatalf("expected an error, got \"%s\"", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value.String() != fixture.Value {
+				t.Errorf("got \"%s\", want \"%s\"", value, fixture.Value)
+			}
+		})
+	}
+}
+
+func TestChecksumsSignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := lbdeploy.ChecksumsSignature{
+		Type:      lbdeploy.SignatureEd25519,
+		Source:    lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, URL: "https://example.com/SHA256SUMS.sig"},
+		PublicKey: base64.StdEncoding.EncodeToString(public),
+	}
+	if err := sig.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	signature := ed25519.Sign(private, []byte(testChecksums))
+	if err := sig.Verify([]byte(testChecksums), signature); err != nil {
+		t.Errorf("raw signature: %v", err)
+	}
+	if err := sig.Verify([]byte(testChecksums), []byte(base64.StdEncoding.EncodeToString(signature)+"\n")); err != nil {
+		t.Errorf("base64 signature: %v", err)
+	}
+	if err := sig.Verify([]byte(testChecksums+"tampered"), signature); err == nil {
+		t.Error("a signature for tampered data was accepted")
+	}
+}
+
+func TestChecksumsSignatureOpenPGP(t *testing.T) {
+	sig := lbdeploy.ChecksumsSignature{
+		Type:      lbdeploy.SignatureOpenPGP,
+		Source:    lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, URL: "https://example.com/SHA256SUMS.asc"},
+		PublicKey: testOpenPGPKey,
+	}
+	if err := sig.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sig.Verify([]byte(testChecksums), []byte(testOpenPGPSig)); err != nil {
+		t.Errorf("armored signature: %v", err)
+	}
+	if err := sig.Verify([]byte(testChecksums+"tampered"), []byte(testOpenPGPSig)); err == nil {
+		t.Error("a signature for tampered data was accepted")
+	}
+
+	invalid := sig
+	invalid.PublicKey = testOpenPGPSig
+	if err := invalid.Validate(); err == nil {
+		t.Error("a signature was accepted as a public key")
+	}
+}
@@ -3,6 +3,7 @@ package lbdeploy
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gentlemanautomaton/structformat"
 )
@@ -17,6 +18,10 @@ const (
 	CommandTypeMSIUpdate               = "msi-update"
 	CommandTypeMSIUninstall            = "msi-uninstall"
 	CommandTypeMSIUninstallProductCode = "msi-uninstall-product-code"
+	CommandTypePackageInstall          = "package-install"
+	CommandTypePackageRemove           = "package-remove"
+	CommandTypePkgInstall              = "pkg-install"
+	CommandTypeBundleInstall           = "bundle-install"
 )
 
 // IsAppBased returns true if the command applies to an application's product
@@ -25,6 +30,19 @@ func (t CommandType) IsAppBased() bool {
 	return t == CommandTypeMSIUninstallProductCode
 }
 
+// IsPackageManager returns true if the command installs or removes the
+// distribution packages of its applications through the system's package
+// manager.
+func (t CommandType) IsPackageManager() bool {
+	return t == CommandTypePackageInstall || t == CommandTypePackageRemove
+}
+
+// IsMacInstaller returns true if the command installs a macOS installer
+// package or application bundle.
+func (t CommandType) IsMacInstaller() bool {
+	return t == CommandTypePkgInstall || t == CommandTypeBundleInstall
+}
+
 // IsMSI returns true if the command invokes msiexec.
 func (t CommandType) IsMSI() bool {
 	switch t {
@@ -48,8 +66,6 @@ type ExecutableID string
 
 // Command defines a command that can be invoked for a deployment or
 // package.
-//
-// TODO: Support variable expansion when building arguments.
 type Command struct {
 	// Installs is a list of applications that the command installs.
 	Installs AppList `json:"installs,omitzero"`
@@ -77,11 +93,94 @@ type Command struct {
 	// utility.
 	Executable ExecutableID `json:"executable,omitempty"`
 
+	// DiskImage identifies a disk image file (.dmg) that holds the
+	// executable for pkg-install and bundle-install commands on macOS. The
+	// image is mounted while the command runs, and the executable is
+	// interpreted as a path within it.
+	DiskImage FileResourceID `json:"disk-image,omitempty"`
+
+	// MSI holds options for msi-based commands. These options are
+	// translated into msiexec arguments that precede Args.
+	MSI MSIOptions `json:"msi,omitzero"`
+
 	// Args is the set of arguments to be passed to the command.
+	//
+	// Arguments can include placeholders that refer to file resources,
+	// directory resources and variables, in the form {file:id}, {dir:id}
+	// and {var:name}. The placeholders are replaced with local paths and
+	// values when the command is invoked, and the arguments are quoted as
+	// needed.
 	Args []string `json:"args,omitzero"`
 
+	// Environment holds environment variables that will be provided to the
+	// command, in addition to those inherited from LeafBridge and those
+	// defined by the deployment.
+	Environment EnvironmentMap `json:"environment,omitzero"`
+
 	// ExitCodes provide a map of known exit codes for the command.
 	ExitCodes ExitCodeMap `json:"exit-codes,omitzero"`
+
+	// OutputEncoding specifies the text encoding of the command's output.
+	// If an encoding is not specified, it will be detected automatically,
+	// falling back to the system's OEM code page.
+	OutputEncoding OutputEncoding `json:"output-encoding,omitempty"`
+
+	// Output determines how much of the command's output is captured.
+	Output OutputOptions `json:"output,omitzero"`
+
+	// WindowWatchdog monitors the command for interactive windows that
+	// it opens unexpectedly. It is only supported on Windows.
+	WindowWatchdog WindowWatchdog `json:"window-watchdog,omitzero"`
+
+	// RestartManager identifies the files that the command will replace,
+	// so that the applications using them can be reported and optionally
+	// shut down and restarted. It is only supported on Windows.
+	RestartManager RestartManager `json:"restart-manager,omitzero"`
+}
+
+// Validate returns a non-nil error if the command contains invalid
+// configuration.
+func (cmd Command) Validate() error {
+	if err := cmd.OutputEncoding.Validate(); err != nil {
+		return err
+	}
+	if err := cmd.Output.Validate(); err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	if err := cmd.Environment.Validate(); err != nil {
+		return fmt.Errorf("environment: %w", err)
+	}
+	if err := cmd.WindowWatchdog.Validate(); err != nil {
+		return fmt.Errorf("window watchdog: %w", err)
+	}
+	if err := cmd.RestartManager.Validate(); err != nil {
+		return fmt.Errorf("restart manager: %w", err)
+	}
+	if cmd.DiskImage != "" && !cmd.Type.IsMacInstaller() {
+		return fmt.Errorf("a disk image was provided for a \"%s\" command, which does not install macOS software", cmd.Type)
+	}
+	if !cmd.MSI.IsZero() {
+		if !cmd.Type.IsMSI() {
+			return fmt.Errorf("msi options were provided for a \"%s\" command, which does not invoke the Windows Installer", cmd.Type)
+		}
+		if len(cmd.MSI.Transforms) > 0 && cmd.Type != CommandTypeMSIInstall {
+			return fmt.Errorf("transforms can only be applied by %s commands", CommandTypeMSIInstall)
+		}
+		if err := cmd.MSI.Validate(); err != nil {
+			return fmt.Errorf("msi options: %w", err)
+		}
+	}
+	if cmd.Type.IsMSI() {
+		// The msiexec command line is generated from the structured
+		// fields of the command. Options passed as free-text arguments
+		// would conflict with it.
+		for _, arg := range cmd.Args {
+			if strings.HasPrefix(arg, "/") || strings.HasPrefix(arg, "-") {
+				return fmt.Errorf("the \"%s\" argument is an msiexec option, which must be expressed through the command type and its msi options", arg)
+			}
+		}
+	}
+	return nil
 }
 
 // ExitCodeMap defines a set of expected exit codes.
@@ -0,0 +1,91 @@
+package lbdeploy
+
+import "fmt"
+
+// DesiredState describes the state that an application or resource is
+// expected to be in when the compliance of a deployment is evaluated.
+type DesiredState string
+
+// Desired states.
+const (
+	DesiredPresent DesiredState = "present"
+	DesiredAbsent  DesiredState = "absent"
+)
+
+// Validate returns a non-nil error if the desired state is not recognized.
+func (state DesiredState) Validate() error {
+	switch state {
+	case "", DesiredPresent, DesiredAbsent:
+		return nil
+	default:
+		return fmt.Errorf("the desired state \"%s\" is not recognized", state)
+	}
+}
+
+// ComplianceKind identifies the kind of item examined by a compliance
+// check.
+type ComplianceKind string
+
+// Compliance check kinds.
+const (
+	ComplianceApp           ComplianceKind = "app"
+	ComplianceCondition     ComplianceKind = "condition"
+	ComplianceDirectory     ComplianceKind = "directory"
+	ComplianceFile          ComplianceKind = "file"
+	ComplianceRegistryKey   ComplianceKind = "registry-key"
+	ComplianceRegistryValue ComplianceKind = "registry-value"
+)
+
+// ComplianceCheck records the outcome of a compliance check for a single
+// application, condition or resource.
+//
+// If the check could not be evaluated, Err is non-nil and the item is
+// treated as out of compliance.
+type ComplianceCheck struct {
+	Kind      ComplianceKind
+	ID        string
+	Desired   string
+	Actual    string
+	Violation string
+	Compliant bool
+	Err       error
+}
+
+// String returns a string representation of the check.
+func (check ComplianceCheck) String() string {
+	switch {
+	case check.Err != nil:
+		return fmt.Sprintf("%s %s: %s", check.Kind, check.ID, check.Err)
+	case check.Violation != "" && !check.Compliant:
+		return fmt.Sprintf("%s %s: %s", check.Kind, check.ID, check.Violation)
+	default:
+		return fmt.Sprintf("%s %s: expected %s, found %s", check.Kind, check.ID, check.Desired, check.Actual)
+	}
+}
+
+// ComplianceReport holds the results of a compliance evaluation.
+type ComplianceReport struct {
+	Checks []ComplianceCheck
+}
+
+// Drift returns the checks that are out of compliance.
+func (report ComplianceReport) Drift() []ComplianceCheck {
+	var drift []ComplianceCheck
+	for _, check := range report.Checks {
+		if !check.Compliant {
+			drift = append(drift, check)
+		}
+	}
+	return drift
+}
+
+// Compliant returns true if all of the checks in the report are in
+// compliance.
+func (report ComplianceReport) Compliant() bool {
+	for _, check := range report.Checks {
+		if !check.Compliant {
+			return false
+		}
+	}
+	return true
+}
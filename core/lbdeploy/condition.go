@@ -36,6 +36,38 @@ type ConditionID string
 type ConditionType string
 
 // Supported condition types.
+//
+// Memory comparisons are made against the installed memory in mebibytes.
+// Processor comparisons are made against the number of logical
+// processors. TPM conditions are only true when a TPM 2.0 device is
+// present. Virtual machine conditions may name a hypervisor as their value,
+// in which case they are only true on virtual machines running on it.
+// BitLocker conditions are true when protection of the system drive is on,
+// and false when it is off or suspended.
+//
+// Language and locale conditions take a language tag as their value. A tag
+// without a region, such as "de", matches every region of the language.
+// Keyboard layout conditions are true when any of the keyboard layouts of
+// the current user matches their value, which may be a language tag or a
+// layout identifier, such as "00000407".
+//
+// Time zone conditions compare their value with the identifier of the
+// current time zone without regard to case. On Windows this is a Windows
+// time zone identifier, such as "W. Europe Standard Time". Elsewhere it is
+// an IANA time zone name, such as "Europe/Berlin".
+//
+// Local user conditions are true when a local user account named by their
+// subject exists. Local group conditions are true when the account named
+// by their value is a direct member of the local group named by their
+// subject. User right conditions are true when the account named by their
+// value has been granted the user right named by their subject, such as
+// "SeServiceLogonRight". Accounts are matched by their security
+// identifiers, so a qualified and an unqualified name of the same account
+// are equivalent.
+//
+// Power plan conditions are true when the plan named by their value is the
+// active power plan. The value is the name of a plan built into Windows,
+// such as "balanced", or the GUID of a power scheme.
 const (
 	ConditionTypeSubcondition            ConditionType = "condition"
 	ConditionTypeProcessIsRunning        ConditionType = "resource.process:running"
@@ -45,19 +77,58 @@ const (
 	ConditionTypeRegistryValueComparison ConditionType = "resource.registry.value:comparison"
 	ConditionTypeDirectoryExists         ConditionType = "resource.file-system.directory:exists"
 	ConditionTypeFileExists              ConditionType = "resource.file-system.file:exists"
+	ConditionTypeFileContent             ConditionType = "resource.file-system.file:content"
+	ConditionTypeCertificateExists       ConditionType = "resource.certificate:exists"
+	ConditionTypeMemoryComparison        ConditionType = "system.memory:comparison"
+	ConditionTypeProcessorComparison     ConditionType = "system.processors:comparison"
+	ConditionTypeTPMPresent              ConditionType = "system.tpm:present"
+	ConditionTypeSecureBootEnabled       ConditionType = "system.secure-boot:enabled"
+	ConditionTypeVirtualMachine          ConditionType = "system.virtual-machine:detected"
+	ConditionTypeBitLockerProtected      ConditionType = "system.bitlocker:protected"
+	ConditionTypeUILanguage              ConditionType = "system.ui-language:matches"
+	ConditionTypeSystemLocale            ConditionType = "system.locale:matches"
+	ConditionTypeKeyboardLayout          ConditionType = "system.keyboard-layout:present"
+	ConditionTypeTimeZone                ConditionType = "system.time-zone:matches"
+	ConditionTypeLocalUserExists         ConditionType = "system.local-user:exists"
+	ConditionTypeLocalGroupMember        ConditionType = "system.local-group:member"
+	ConditionTypeUserRightGranted        ConditionType = "system.user-right:granted"
+	ConditionTypePowerPlanActive         ConditionType = "system.power-plan:active"
 )
 
+// IsRegistry returns true if the condition type examines a registry key or
+// value.
+func (t ConditionType) IsRegistry() bool {
+	switch t {
+	case ConditionTypeRegistryKeyExists, ConditionTypeRegistryValueExists, ConditionTypeRegistryValueComparison:
+		return true
+	default:
+		return false
+	}
+}
+
 // Condition describes a condition that can be evaluated.
+//
+// If Compliance is true, the condition is expected to be true when the
+// compliance of the deployment is evaluated. Its violation, if provided,
+// describes what it means for the condition to be false.
+//
+// If the condition is used as a precondition and is false, its failure
+// message, if provided, is reported to operators instead of the condition's
+// ID. It should explain the requirement in plain terms, such as "This app
+// requires Windows 11 22H2 or later".
 type Condition struct {
-	Label      string             `json:"label,omitempty"`
-	Type       ConditionType      `json:"type,omitempty"`
-	Subject    string             `json:"subject,omitempty"`
-	Comparison lbvalue.Comparison `json:"comparison,omitzero"`
-	Value      lbvalue.Value      `json:"value,omitzero"`
-	Negated    bool               `json:"negated,omitempty"`
-	Any        []Condition        `json:"any,omitzero"`
-	All        []Condition        `json:"all,omitzero"`
-	Violation  string             `json:"violation,omitempty"`
+	Label          string             `json:"label,omitempty"`
+	Type           ConditionType      `json:"type,omitempty"`
+	Subject        string             `json:"subject,omitempty"`
+	Comparison     lbvalue.Comparison `json:"comparison,omitzero"`
+	Value          lbvalue.Value      `json:"value,omitzero"`
+	Content        FileContentMatch   `json:"content,omitzero"`
+	Negated        bool               `json:"negated,omitempty"`
+	Any            []Condition        `json:"any,omitzero"`
+	All            []Condition        `json:"all,omitzero"`
+	Violation      string             `json:"violation,omitempty"`
+	Compliance     bool               `json:"compliance,omitempty"`
+	FailureMessage string             `json:"failure-message,omitempty"`
 }
 
 // ConditionUse identifies common uses of a condition.
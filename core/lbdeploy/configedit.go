@@ -0,0 +1,136 @@
+package lbdeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConfigFormat identifies the format of a configuration file.
+type ConfigFormat string
+
+// Supported configuration file formats.
+const (
+	ConfigINI  ConfigFormat = "ini"
+	ConfigXML  ConfigFormat = "xml"
+	ConfigJSON ConfigFormat = "json"
+)
+
+// ConfigFormat returns the configuration file format edited by the action.
+// It returns an empty string for actions that do not edit configuration
+// files.
+func (t ActionType) ConfigFormat() ConfigFormat {
+	switch t {
+	case ActionEditINIFile:
+		return ConfigINI
+	case ActionEditXMLFile:
+		return ConfigXML
+	case ActionEditJSONFile:
+		return ConfigJSON
+	default:
+		return ""
+	}
+}
+
+// ConfigEdit describes a value to be set within a configuration file.
+//
+// INI edits identify a key within a section. An empty section refers to
+// the keys that precede the first section.
+//
+// XML edits identify an element by path, such as
+// /configuration/appSettings/add[@key='Mode'], and optionally one of its
+// attributes. If an attribute is not provided, the text of the element is
+// set.
+//
+// JSON edits identify a value by JSON pointer, such as /update/channel.
+//
+// The value may be any JSON value for JSON edits. INI and XML edits accept
+// strings, numbers and booleans. Placeholders are expanded when the value
+// is a string.
+type ConfigEdit struct {
+	Section   string          `json:"section,omitempty"`
+	Key       string          `json:"key,omitempty"`
+	Path      string          `json:"path,omitempty"`
+	Attribute string          `json:"attribute,omitempty"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// Validate returns an error if the edit is not valid for the given
+// format.
+func (edit ConfigEdit) Validate(format ConfigFormat) error {
+	switch format {
+	case ConfigINI:
+		if edit.Key == "" {
+			return errors.New("an INI key was not provided")
+		}
+		if edit.Path != "" || edit.Attribute != "" {
+			return errors.New("INI edits do not accept a path or attribute")
+		}
+	case ConfigXML:
+		if !strings.HasPrefix(edit.Path, "/") {
+			return errors.New("an absolute XML path was not provided")
+		}
+		if edit.Section != "" || edit.Key != "" {
+			return errors.New("XML edits do not accept a section or key")
+		}
+	case ConfigJSON:
+		if edit.Path != "" && !strings.HasPrefix(edit.Path, "/") {
+			return errors.New("the JSON pointer does not begin with a slash")
+		}
+		if edit.Section != "" || edit.Key != "" || edit.Attribute != "" {
+			return errors.New("JSON edits do not accept a section, key or attribute")
+		}
+	default:
+		return fmt.Errorf("the configuration format \"%s\" is not recognized", format)
+	}
+
+	if len(edit.Value) == 0 {
+		return errors.New("a value was not provided")
+	}
+	if !json.Valid(edit.Value) {
+		return errors.New("the value is not valid JSON")
+	}
+	if format != ConfigJSON {
+		if _, err := edit.Text(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Text returns the value of the edit as text. It returns an error if the
+// value is not a string, number or boolean.
+func (edit ConfigEdit) Text() (string, error) {
+	value := bytes.TrimSpace(edit.Value)
+	if len(value) == 0 {
+		return "", errors.New("a value was not provided")
+	}
+	switch value[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	case '{', '[':
+		return "", errors.New("the value must be a string, number or boolean")
+	default:
+		if string(value) == "null" {
+			return "", errors.New("the value must be a string, number or boolean")
+		}
+		return string(value), nil
+	}
+}
+
+// Template returns the value of the edit if it is a string. Placeholders
+// within it are expanded when the edit is applied.
+func (edit ConfigEdit) Template() (string, bool) {
+	var s string
+	if err := json.Unmarshal(edit.Value, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
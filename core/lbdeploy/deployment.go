@@ -4,29 +4,68 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbexpand"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
 )
 
 // DeploymentID is a unique identifier for a deployment.
 type DeploymentID string
 
 // Validate returns a non-nil error if the deployment ID is invalid.
+//
+// Deployment IDs are used to name the files and directories that hold the
+// state, staging data and backups of a deployment, so they must be usable
+// as a single file name on every platform.
 func (id DeploymentID) Validate() error {
-	if id == "" {
+	switch {
+	case id == "":
 		return errors.New("a deployment ID is missing")
+	case id == "." || id == "..":
+		return fmt.Errorf("the deployment ID \"%s\" is not valid", id)
+	case strings.ContainsFunc(string(id), func(r rune) bool {
+		return r < ' ' || strings.ContainsRune(`<>:"/\|?*`, r)
+	}):
+		return fmt.Errorf("the deployment ID \"%s\" contains an invalid character", id)
 	}
 	return nil
 }
 
 // Deployment defines a deployment package.
+//
+// If a timeout is provided, it limits the total amount of time that an
+// invocation of the deployment is allowed to run. When it is exceeded, the
+// invocation is cancelled and its processes are terminated.
+//
+// If NotBefore or NotAfter are provided, the deployment refuses to run
+// outside of that window. This keeps outdated copies of a deployment file
+// from being run long after they were meant to be.
+//
+// If Architectures are provided, the deployment refuses to run on
+// operating systems with other architectures, and each of its packages
+// must provide sources that are suitable for all of them.
 type Deployment struct {
-	ID         DeploymentID `json:"id,omitempty"`
-	Name       string       `json:"name,omitempty"`
-	Behavior   Behavior     `json:"behavior,omitzero"`
-	Apps       AppMap       `json:"apps,omitzero"`
-	Conditions ConditionMap `json:"conditions,omitzero"`
-	Commands   CommandMap   `json:"commands,omitzero"`
-	Resources  Resources    `json:"resources,omitzero"`
-	Flows      FlowMap      `json:"flows,omitzero"`
+	ID            DeploymentID      `json:"id,omitempty"`
+	Name          string            `json:"name,omitempty"`
+	Timeout       datatype.Duration `json:"timeout,omitzero"`
+	NotBefore     time.Time         `json:"not-before,omitzero"`
+	NotAfter      time.Time         `json:"not-after,omitzero"`
+	Behavior      Behavior          `json:"behavior,omitzero"`
+	Variables     VariableMap       `json:"variables,omitzero"`
+	Environment   EnvironmentMap    `json:"environment,omitzero"`
+	Apps          AppMap            `json:"apps,omitzero"`
+	Conditions    ConditionMap      `json:"conditions,omitzero"`
+	Commands      CommandMap        `json:"commands,omitzero"`
+	Resources     Resources         `json:"resources,omitzero"`
+	Staging       Staging           `json:"staging,omitzero"`
+	Flows         FlowMap           `json:"flows,omitzero"`
+	Approval      Approval          `json:"approval,omitzero"`
+	Architectures []Architecture    `json:"architectures,omitempty"`
+
+	// source holds the document that the deployment was decoded from.
+	source []byte
 }
 
 // Validate returns an error if the deployment contains invalid configuration.
@@ -35,15 +74,442 @@ func (dep Deployment) Validate() error {
 		return err
 	}
 
+	if dep.Timeout < 0 {
+		return errors.New("a negative timeout was provided")
+	}
+
+	if err := dep.Validity().Validate(); err != nil {
+		return err
+	}
+
+	if err := dep.Approval.Validate(); err != nil {
+		return fmt.Errorf("approval: %w", err)
+	}
+
 	for id := range dep.Conditions {
 		if err := dep.ValidateCondition(id); err != nil {
 			return err
 		}
 	}
 
+	for id, flow := range dep.Flows {
+		if err := flow.Frequency.Validate(); err != nil {
+			return fmt.Errorf("flow \"%s\": frequency: %w", id, err)
+		}
+		if err := flow.Remediation.Validate(); err != nil {
+			return fmt.Errorf("flow \"%s\": remediation: %w", id, err)
+		}
+		if err := flow.Idle.Validate(); err != nil {
+			return fmt.Errorf("flow \"%s\": idle: %w", id, err)
+		}
+		if flow.Idle != (FlowIdle{}) && !flow.Disruptive {
+			return fmt.Errorf("flow \"%s\": idle: an idle requirement was provided for a flow that is not disruptive", id)
+		}
+		if flow.Timeout < 0 {
+			return fmt.Errorf("flow \"%s\": a negative timeout was provided", id)
+		}
+		if err := flow.Validity().Validate(); err != nil {
+			return fmt.Errorf("flow \"%s\": %w", id, err)
+		}
+		if err := flow.Hooks.Validate(); err != nil {
+			return fmt.Errorf("flow \"%s\": hooks: %w", id, err)
+		}
+		if err := flow.OnFailure.Validate(len(flow.Actions)); err != nil {
+			return fmt.Errorf("flow \"%s\": on-failure: %w", id, err)
+		}
+		if rollback := flow.OnFailure.Flow; rollback != "" {
+			if _, found := dep.Flows[rollback]; !found {
+				return fmt.Errorf("flow \"%s\": on-failure: the rollback flow \"%s\" is not defined", id, rollback)
+			}
+		}
+		for i, action := range flow.Actions {
+			if err := action.ValidateErrorPolicy(); err != nil {
+				return fmt.Errorf("flow \"%s\": action %d: %w", id, i+1, err)
+			}
+			if file := action.Script.Output.File; file != "" {
+				if _, found := dep.Resources.FileSystem.Files[file]; !found {
+					return fmt.Errorf("flow \"%s\": action %d: the output file refers to a file resource ID that is not defined: %s", id, i+1, file)
+				}
+			}
+			if action.OnErrorFlow != "" {
+				if _, found := dep.Flows[action.OnErrorFlow]; !found {
+					return fmt.Errorf("flow \"%s\": action %d: the on-error flow \"%s\" is not defined", id, i+1, action.OnErrorFlow)
+				}
+			}
+			for _, condition := range action.Conditions {
+				if _, found := dep.Conditions[condition]; !found {
+					return fmt.Errorf("flow \"%s\": action %d: the condition \"%s\" is not defined", id, i+1, condition)
+				}
+			}
+			if action.Timeout < 0 {
+				return fmt.Errorf("flow \"%s\": action %d: a negative timeout was provided", id, i+1)
+			}
+			if err := action.Retry.Validate(); err != nil {
+				return fmt.Errorf("flow \"%s\": action %d: retry: %w", id, i+1, err)
+			}
+			if format := action.Type.ConfigFormat(); format != "" {
+				if action.DestinationFile == "" {
+					return fmt.Errorf("flow \"%s\": action %d: a destination file was not provided", id, i+1)
+				}
+				if _, found := dep.Resources.FileSystem.Files[action.DestinationFile]; !found {
+					return fmt.Errorf("flow \"%s\": action %d: the destination file refers to a file resource ID that is not defined: %s", id, i+1, action.DestinationFile)
+				}
+				if len(action.Edits) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: edits were not provided", id, i+1)
+				}
+				for e, edit := range action.Edits {
+					if err := edit.Validate(format); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: edit %d: %w", id, i+1, e+1, err)
+					}
+					if template, ok := edit.Template(); ok {
+						if err := dep.ValidateTemplate(template); err != nil {
+							return fmt.Errorf("flow \"%s\": action %d: edit %d: %w", id, i+1, e+1, err)
+						}
+					}
+				}
+			}
+			if action.Type == ActionEditHostsFile {
+				if len(action.HostsEntries) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: hosts entries were not provided", id, i+1)
+				}
+				for e, entry := range action.HostsEntries {
+					if err := entry.Validate(); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: hosts entry %d: %w", id, i+1, e+1, err)
+					}
+				}
+			}
+			if action.Type == ActionConfigureBrowserExtensions {
+				if len(action.Extensions) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: browser extensions were not provided", id, i+1)
+				}
+				for e, ext := range action.Extensions {
+					if err := ext.Validate(); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: browser extension %d: %w", id, i+1, e+1, err)
+					}
+				}
+			} else if len(action.Extensions) > 0 {
+				return fmt.Errorf("flow \"%s\": action %d: browser extensions were provided for an action that does not use them", id, i+1)
+			}
+			if action.Type == ActionSetBrowserPolicies {
+				if len(action.BrowserPolicies) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: browser policies were not provided", id, i+1)
+				}
+				for p, policy := range action.BrowserPolicies {
+					if err := policy.Validate(); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: browser policy %d: %w", id, i+1, p+1, err)
+					}
+				}
+			} else if len(action.BrowserPolicies) > 0 {
+				return fmt.Errorf("flow \"%s\": action %d: browser policies were provided for an action that does not use them", id, i+1)
+			}
+			if action.Type == ActionSetDefaultAssociations {
+				if len(action.Associations) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: associations were not provided", id, i+1)
+				}
+				for a, assoc := range action.Associations {
+					if err := assoc.Validate(); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: association %d: %w", id, i+1, a+1, err)
+					}
+				}
+			} else if len(action.Associations) > 0 {
+				return fmt.Errorf("flow \"%s\": action %d: associations were provided for an action that does not use them", id, i+1)
+			}
+			if action.Type == ActionConfigureLocalUsers {
+				if len(action.LocalUsers) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: local users were not provided", id, i+1)
+				}
+				for e, user := range action.LocalUsers {
+					if err := user.Validate(); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: local user %d: %w", id, i+1, e+1, err)
+					}
+				}
+			} else if len(action.LocalUsers) > 0 {
+				return fmt.Errorf("flow \"%s\": action %d: local users were provided for an action that does not use them", id, i+1)
+			}
+			if action.Type == ActionConfigureLocalGroups {
+				if len(action.GroupMembers) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: group members were not provided", id, i+1)
+				}
+				for e, member := range action.GroupMembers {
+					if err := member.Validate(); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: group member %d: %w", id, i+1, e+1, err)
+					}
+				}
+			} else if len(action.GroupMembers) > 0 {
+				return fmt.Errorf("flow \"%s\": action %d: group members were provided for an action that does not use them", id, i+1)
+			}
+			if action.Type == ActionConfigureUserRights {
+				if len(action.UserRights) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: user rights were not provided", id, i+1)
+				}
+				for e, right := range action.UserRights {
+					if err := right.Validate(); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: user right %d: %w", id, i+1, e+1, err)
+					}
+				}
+			} else if len(action.UserRights) > 0 {
+				return fmt.Errorf("flow \"%s\": action %d: user rights were provided for an action that does not use them", id, i+1)
+			}
+			if action.Type == ActionSetStartLayout {
+				if action.SourceFile == "" {
+					return fmt.Errorf("flow \"%s\": action %d: a source file was not provided", id, i+1)
+				}
+				if _, found := dep.Resources.FileSystem.Files[action.SourceFile]; !found {
+					return fmt.Errorf("flow \"%s\": action %d: the source file refers to a file resource ID that is not defined: %s", id, i+1, action.SourceFile)
+				}
+			}
+			if action.Type == ActionSetPowerPlan {
+				if err := action.PowerPlan.Validate(); err != nil {
+					return fmt.Errorf("flow \"%s\": action %d: %w", id, i+1, err)
+				}
+				if action.SourceFile != "" {
+					if action.PowerPlan.IsBuiltIn() {
+						return fmt.Errorf("flow \"%s\": action %d: a power plan that is imported must be identified by a GUID", id, i+1)
+					}
+					if _, found := dep.Resources.FileSystem.Files[action.SourceFile]; !found {
+						return fmt.Errorf("flow \"%s\": action %d: the source file refers to a file resource ID that is not defined: %s", id, i+1, action.SourceFile)
+					}
+				}
+			} else if action.PowerPlan != "" {
+				return fmt.Errorf("flow \"%s\": action %d: a power plan was provided for an action that does not use one", id, i+1)
+			}
+			if action.Type == ActionConfigurePowerSettings {
+				if len(action.PowerSettings) == 0 {
+					return fmt.Errorf("flow \"%s\": action %d: power settings were not provided", id, i+1)
+				}
+				for e, setting := range action.PowerSettings {
+					if err := setting.Validate(); err != nil {
+						return fmt.Errorf("flow \"%s\": action %d: power setting %d: %w", id, i+1, e+1, err)
+					}
+				}
+			} else if len(action.PowerSettings) > 0 {
+				return fmt.Errorf("flow \"%s\": action %d: power settings were provided for an action that does not use them", id, i+1)
+			}
+			if action.Type == ActionActivateLicense {
+				if err := action.Activation.Validate(); err != nil {
+					return fmt.Errorf("flow \"%s\": action %d: activation: %w", id, i+1, err)
+				}
+			} else if action.Activation != (LicenseActivation{}) {
+				return fmt.Errorf("flow \"%s\": action %d: an activation was provided for an action that does not use one", id, i+1)
+			}
+			if action.Enforce && action.Type != ActionSetDefaultAssociations && action.Type != ActionSetStartLayout {
+				return fmt.Errorf("flow \"%s\": action %d: enforce was specified for an action that does not use it", id, i+1)
+			}
+			if action.Type == ActionSuspendBitLocker {
+				if action.RebootCount < 1 || action.RebootCount > MaxBitLockerRebootCount {
+					return fmt.Errorf("flow \"%s\": action %d: the reboot count must be between 1 and %d", id, i+1, MaxBitLockerRebootCount)
+				}
+			} else if action.RebootCount != 0 {
+				return fmt.Errorf("flow \"%s\": action %d: a reboot count was provided for an action that does not use one", id, i+1)
+			}
+			if action.Type == ActionBackupFile || action.Type == ActionRestoreFile {
+				if action.DestinationFile == "" {
+					return fmt.Errorf("flow \"%s\": action %d: a destination file was not provided", id, i+1)
+				}
+				if _, found := dep.Resources.FileSystem.Files[action.DestinationFile]; !found {
+					return fmt.Errorf("flow \"%s\": action %d: the destination file refers to a file resource ID that is not defined: %s", id, i+1, action.DestinationFile)
+				}
+			}
+			if action.Replace && action.Type != ActionCopyFile {
+				return fmt.Errorf("flow \"%s\": action %d: replace was specified for an action that does not copy files", id, i+1)
+			}
+			if err := action.OnLocked.Validate(); err != nil {
+				return fmt.Errorf("flow \"%s\": action %d: %w", id, i+1, err)
+			}
+			if action.OnLocked != FileLockedUnspecified && action.Type != ActionCopyFile && action.Type != ActionDeleteFile {
+				return fmt.Errorf("flow \"%s\": action %d: an on-locked behavior was provided for an action that does not use one", id, i+1)
+			}
+			if err := action.OnProtected.Validate(); err != nil {
+				return fmt.Errorf("flow \"%s\": action %d: %w", id, i+1, err)
+			}
+			if action.OnProtected != ProtectedFileUnspecified {
+				switch action.Type {
+				case ActionCopyFile, ActionDeleteFile, ActionRestoreFile:
+				default:
+					return fmt.Errorf("flow \"%s\": action %d: an on-protected behavior was provided for an action that does not use one", id, i+1)
+				}
+			}
+			if action.Type == ActionSetTimeZone {
+				if action.TimeZone == "" {
+					return fmt.Errorf("flow \"%s\": action %d: a time zone was not provided", id, i+1)
+				}
+			} else if action.TimeZone != "" {
+				return fmt.Errorf("flow \"%s\": action %d: a time zone was provided for an action that does not use one", id, i+1)
+			}
+			if action.Type == ActionRegisterLogonFlow {
+				if action.Flow == "" {
+					return fmt.Errorf("flow \"%s\": action %d: a logon flow was not provided", id, i+1)
+				}
+				if _, found := dep.Flows[action.Flow]; !found {
+					return fmt.Errorf("flow \"%s\": action %d: the logon flow \"%s\" is not defined", id, i+1, action.Flow)
+				}
+			}
+			if action.Type == ActionWaitForRegistry {
+				condition, found := dep.Conditions[action.Condition]
+				if !found {
+					return fmt.Errorf("flow \"%s\": action %d: the condition \"%s\" is not defined", id, i+1, action.Condition)
+				}
+				if !condition.Type.IsRegistry() {
+					return fmt.Errorf("flow \"%s\": action %d: the \"%s\" condition does not examine the registry", id, i+1, action.Condition)
+				}
+			}
+		}
+	}
+
+	if err := dep.validateArchitectures(); err != nil {
+		return err
+	}
+
+	if err := dep.Environment.Validate(); err != nil {
+		return fmt.Errorf("environment: %w", err)
+	}
+	if err := dep.ValidateEnvironment(dep.Environment); err != nil {
+		return fmt.Errorf("environment: %w", err)
+	}
+
+	for id, process := range dep.Resources.Processes {
+		if _, err := process.Match.Compile(false); err != nil {
+			return fmt.Errorf("process resource \"%s\": %w", id, err)
+		}
+	}
+
+	for id, cert := range dep.Resources.Certificates {
+		if err := cert.Validate(); err != nil {
+			return fmt.Errorf("certificate resource \"%s\": %w", id, err)
+		}
+	}
+
+	for id, file := range dep.Resources.FileSystem.Files {
+		if err := file.Integrity.Validate(); err != nil {
+			return fmt.Errorf("file resource \"%s\": integrity: %w", id, err)
+		}
+		if err := file.Desired.Validate(); err != nil {
+			return fmt.Errorf("file resource \"%s\": %w", id, err)
+		}
+	}
+
+	for id, dir := range dep.Resources.FileSystem.Directories {
+		if err := dir.Desired.Validate(); err != nil {
+			return fmt.Errorf("directory resource \"%s\": %w", id, err)
+		}
+	}
+
+	for id, key := range dep.Resources.Registry.Keys {
+		if err := key.Desired.Validate(); err != nil {
+			return fmt.Errorf("registry key resource \"%s\": %w", id, err)
+		}
+	}
+
+	for id, value := range dep.Resources.Registry.Values {
+		if err := value.Desired.Validate(); err != nil {
+			return fmt.Errorf("registry value resource \"%s\": %w", id, err)
+		}
+		if value.DesiredData.Kind() != lbvalue.KindUnknown && value.Desired != DesiredPresent {
+			return fmt.Errorf("registry value resource \"%s\": desired data was provided for a value that is not expected to be present", id)
+		}
+	}
+
+	for id, app := range dep.Apps {
+		if err := app.Desired.Validate(); err != nil {
+			return fmt.Errorf("app \"%s\": %w", id, err)
+		}
+	}
+
+	for id, command := range dep.Commands {
+		if err := command.Validate(); err != nil {
+			return fmt.Errorf("command \"%s\": %w", id, err)
+		}
+		if err := dep.ValidateEnvironment(command.Environment); err != nil {
+			return fmt.Errorf("command \"%s\": environment: %w", id, err)
+		}
+		for i, arg := range command.Args {
+			if err := dep.ValidateTemplate(arg); err != nil {
+				return fmt.Errorf("command \"%s\": argument %d: %w", id, i+1, err)
+			}
+		}
+		for _, name := range command.MSI.Properties.Names() {
+			if err := dep.ValidateTemplate(command.MSI.Properties[name]); err != nil {
+				return fmt.Errorf("command \"%s\": the \"%s\" property: %w", id, name, err)
+			}
+		}
+		if file := command.Output.File; file != "" {
+			if _, found := dep.Resources.FileSystem.Files[file]; !found {
+				return fmt.Errorf("command \"%s\": the output file refers to a file resource ID that is not defined: %s", id, file)
+			}
+		}
+		if file := command.DiskImage; file != "" {
+			if _, found := dep.Resources.FileSystem.Files[file]; !found {
+				return fmt.Errorf("command \"%s\": the disk image refers to a file resource ID that is not defined: %s", id, file)
+			}
+		}
+		for _, file := range command.RestartManager.Files {
+			if _, found := dep.Resources.FileSystem.Files[file]; !found {
+				return fmt.Errorf("command \"%s\": restart manager: the file refers to a file resource ID that is not defined: %s", id, file)
+			}
+		}
+		for _, dir := range command.RestartManager.Directories {
+			if _, found := dep.Resources.FileSystem.Directories[dir]; !found {
+				return fmt.Errorf("command \"%s\": restart manager: the directory refers to a directory resource ID that is not defined: %s", id, dir)
+			}
+		}
+		for _, transform := range command.MSI.Transforms {
+			if transform.IsEmbedded() {
+				continue
+			}
+			if _, found := dep.Resources.FileSystem.Files[FileResourceID(transform)]; !found {
+				return fmt.Errorf("command \"%s\": the transform refers to a file resource ID that is not defined: %s", id, transform)
+			}
+		}
+	}
+
 	return nil
 }
 
+// ValidateEnvironment returns an error if any of the values in env refer
+// to file resources or variables that are not defined in the deployment.
+func (dep Deployment) ValidateEnvironment(env EnvironmentMap) error {
+	for _, name := range env.Names() {
+		if err := dep.ValidateTemplate(env[name]); err != nil {
+			return fmt.Errorf("the \"%s\" variable is not valid: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateTemplate returns an error if any of the placeholders in the
+// given template refer to file resources or variables that are not defined
+// in the deployment.
+//
+// Directory placeholders are not checked, because they might refer to
+// well-known directories that are only known to the platform.
+func (dep Deployment) ValidateTemplate(template string) error {
+	for p := range lbexpand.Placeholders(template) {
+		switch p.Kind {
+		case lbexpand.KindFile:
+			if _, found := dep.Resources.FileSystem.Files[FileResourceID(p.Name)]; !found {
+				return fmt.Errorf("the %s placeholder refers to a file resource ID that is not defined", p)
+			}
+		case lbexpand.KindVar:
+			if _, found := dep.Variables[p.Name]; !found {
+				return fmt.Errorf("the %s placeholder refers to a variable that is not defined", p)
+			}
+		}
+	}
+	return nil
+}
+
+// FailureMessages returns the failure messages of the given conditions.
+// Conditions without a failure message are omitted.
+func (dep Deployment) FailureMessages(conditions ConditionList) []string {
+	var messages []string
+	for _, id := range conditions {
+		if message := dep.Conditions[id].FailureMessage; message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
 // ValidateCondition returns an error if the given condition is not valid.
 func (dep Deployment) ValidateCondition(condition ConditionID) error {
 	definition, found := dep.Conditions[condition]
@@ -160,13 +626,96 @@ func (dep Deployment) validateCondition(condition Condition) error {
 			if _, found := dep.Resources.FileSystem.Directories[DirectoryResourceID(condition.Subject)]; !found {
 				return fmt.Errorf("the condition references a directory resource ID that is not defined: %s", condition.Subject)
 			}
-		case ConditionTypeFileExists:
+		case ConditionTypeFileExists, ConditionTypeFileContent:
 			if condition.Subject == "" {
 				return errors.New("the condition does not provide a file resource ID")
 			}
 			if _, found := dep.Resources.FileSystem.Files[FileResourceID(condition.Subject)]; !found {
 				return fmt.Errorf("the condition references a file resource ID that is not defined: %s", condition.Subject)
 			}
+			if condition.Type == ConditionTypeFileContent {
+				if err := condition.Content.Validate(); err != nil {
+					return fmt.Errorf("content: %w", err)
+				}
+			}
+		case ConditionTypeCertificateExists:
+			if condition.Subject == "" {
+				return errors.New("the condition does not provide a certificate resource ID")
+			}
+			if _, found := dep.Resources.Certificates[CertificateID(condition.Subject)]; !found {
+				return fmt.Errorf("the condition references a certificate resource ID that is not defined: %s", condition.Subject)
+			}
+		case ConditionTypeMemoryComparison, ConditionTypeProcessorComparison, ConditionTypeTPMPresent, ConditionTypeSecureBootEnabled, ConditionTypeVirtualMachine, ConditionTypeBitLockerProtected:
+			if condition.Subject != "" {
+				return errors.New("the condition does not accept a subject")
+			}
+			switch condition.Type {
+			case ConditionTypeMemoryComparison, ConditionTypeProcessorComparison:
+				if kind := condition.Value.Kind(); kind != lbvalue.KindInt64 {
+					return fmt.Errorf("the condition requires an integer value, but a value of kind \"%s\" was provided", kind)
+				}
+			case ConditionTypeVirtualMachine:
+				switch kind := condition.Value.Kind(); kind {
+				case lbvalue.KindUnknown:
+				case lbvalue.KindString:
+					if err := Hypervisor(condition.Value.String()).Validate(); err != nil {
+						return err
+					}
+				default:
+					return fmt.Errorf("the condition requires a hypervisor name as its value, but a value of kind \"%s\" was provided", kind)
+				}
+			}
+		case ConditionTypeUILanguage, ConditionTypeSystemLocale, ConditionTypeKeyboardLayout:
+			if condition.Subject != "" {
+				return errors.New("the condition does not accept a subject")
+			}
+			if kind := condition.Value.Kind(); kind != lbvalue.KindString {
+				return fmt.Errorf("the condition requires a string value, but a value of kind \"%s\" was provided", kind)
+			}
+			if err := ValidateLocaleName(condition.Value.String()); err != nil {
+				return err
+			}
+		case ConditionTypeLocalUserExists:
+			if err := ValidateLocalUserName(condition.Subject); err != nil {
+				return err
+			}
+			if kind := condition.Value.Kind(); kind != lbvalue.KindUnknown {
+				return fmt.Errorf("the condition does not accept a value, but a value of kind \"%s\" was provided", kind)
+			}
+		case ConditionTypeLocalGroupMember, ConditionTypeUserRightGranted:
+			if condition.Type == ConditionTypeUserRightGranted {
+				if err := ValidateUserRight(condition.Subject); err != nil {
+					return err
+				}
+			} else if condition.Subject == "" {
+				return errors.New("a group was not provided as the subject of the condition")
+			}
+			if kind := condition.Value.Kind(); kind != lbvalue.KindString {
+				return fmt.Errorf("the condition requires an account name as its value, but a value of kind \"%s\" was provided", kind)
+			}
+			if err := validateAccountName(condition.Value.String()); err != nil {
+				return err
+			}
+		case ConditionTypePowerPlanActive:
+			if condition.Subject != "" {
+				return errors.New("the condition does not accept a subject")
+			}
+			if kind := condition.Value.Kind(); kind != lbvalue.KindString {
+				return fmt.Errorf("the condition requires a power plan as its value, but a value of kind \"%s\" was provided", kind)
+			}
+			if err := PowerPlan(condition.Value.String()).Validate(); err != nil {
+				return err
+			}
+		case ConditionTypeTimeZone:
+			if condition.Subject != "" {
+				return errors.New("the condition does not accept a subject")
+			}
+			if kind := condition.Value.Kind(); kind != lbvalue.KindString {
+				return fmt.Errorf("the condition requires a string value, but a value of kind \"%s\" was provided", kind)
+			}
+			if condition.Value.String() == "" {
+				return errors.New("a time zone was not provided")
+			}
 		default:
 			return fmt.Errorf("the condition type is not recognized: %s", condition.Type)
 		}
@@ -0,0 +1,173 @@
+package lbdeploy_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// deploymentFixture describes a deployment with a single "install" flow,
+// along with the error that validating it should produce.
+type deploymentFixture struct {
+	Name       string
+	Conditions lbdeploy.ConditionMap
+	Resources  lbdeploy.Resources
+	Flows      lbdeploy.FlowMap
+	Err        string
+}
+
+// testDeployment returns a deployment for the fixture.
+func (fixture deploymentFixture) testDeployment() lbdeploy.Deployment {
+	return lbdeploy.Deployment{
+		ID:         "test",
+		Conditions: fixture.Conditions,
+		Resources:  fixture.Resources,
+		Flows:      fixture.Flows,
+	}
+}
+
+// testDeploymentFixtures validates the deployment of each fixture and
+// checks the errors that are returned.
+func testDeploymentFixtures(t *testing.T, fixtures []deploymentFixture) {
+	t.Helper()
+	for _, fixture := range fixtures {
+		err := fixture.testDeployment().Validate()
+		switch {
+		case fixture.Err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", fixture.Name, err)
+		case fixture.Err != "" && err == nil:
+			t.Errorf("%s: expected an error containing %q", fixture.Name, fixture.Err)
+		case fixture.Err != "" && !strings.Contains(err.Error(), fixture.Err):
+			t.Errorf("%s: got error %q, want an error containing %q", fixture.Name, err, fixture.Err)
+		}
+	}
+}
+
+var testConditions = lbdeploy.ConditionMap{
+	"tpm": {Type: lbdeploy.ConditionTypeTPMPresent},
+}
+
+func TestDeploymentActionConditions(t *testing.T) {
+	testDeploymentFixtures(t, []deploymentFixture{
+		{
+			Name:  "none",
+			Flows: lbdeploy.FlowMap{"install": {Actions: []lbdeploy.Action{{Type: lbdeploy.ActionStartFlow, Flow: "install"}}}},
+		},
+		{
+			Name:       "defined",
+			Conditions: testConditions,
+			Flows: lbdeploy.FlowMap{"install": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionStartFlow, Flow: "install", Conditions: lbdeploy.ConditionList{"tpm"}},
+			}}},
+		},
+		{
+			Name:       "undefined",
+			Conditions: testConditions,
+			Flows: lbdeploy.FlowMap{"install": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionStartFlow, Flow: "install"},
+				{Type: lbdeploy.ActionStartFlow, Flow: "install", Conditions: lbdeploy.ConditionList{"tpm", "vm"}},
+			}}},
+			Err: `action 2: the condition "vm" is not defined`,
+		},
+	})
+}
+
+func TestDeploymentErrorPolicies(t *testing.T) {
+	flows := func(action lbdeploy.Action) lbdeploy.FlowMap {
+		return lbdeploy.FlowMap{
+			"install": {Actions: []lbdeploy.Action{action}},
+			"cleanup": {Actions: []lbdeploy.Action{{Type: lbdeploy.ActionStartFlow, Flow: "install"}}},
+		}
+	}
+	action := func(onError lbdeploy.OnErrorBehavior, onErrorFlow lbdeploy.FlowID) lbdeploy.Action {
+		return lbdeploy.Action{Type: lbdeploy.ActionStartFlow, Flow: "cleanup", OnError: onError, OnErrorFlow: onErrorFlow}
+	}
+
+	testDeploymentFixtures(t, []deploymentFixture{
+		{Name: "unspecified", Flows: flows(action("", ""))},
+		{Name: "stop", Flows: flows(action(lbdeploy.OnErrorStop, ""))},
+		{Name: "continue", Flows: flows(action(lbdeploy.OnErrorContinue, ""))},
+		{Name: "goto-flow", Flows: flows(action(lbdeploy.OnErrorGotoFlow, "cleanup"))},
+		{Name: "goto-flow-missing", Flows: flows(action(lbdeploy.OnErrorGotoFlow, "")), Err: `the "goto-flow" on-error policy requires an on-error flow`},
+		{Name: "goto-flow-undefined", Flows: flows(action(lbdeploy.OnErrorGotoFlow, "repair")), Err: `the on-error flow "repair" is not defined`},
+		{Name: "flow-without-goto", Flows: flows(action(lbdeploy.OnErrorContinue, "cleanup")), Err: `an on-error flow was provided without the "goto-flow" on-error policy`},
+		{Name: "unrecognized", Flows: flows(action("retry", "")), Err: `the "retry" on-error policy is not recognized`},
+	})
+}
+
+func TestOnErrorFailAlias(t *testing.T) {
+	fixtures := []struct {
+		JSON string
+		Want lbdeploy.OnErrorBehavior
+	}{
+		{JSON: `{}`, Want: lbdeploy.OnErrorUnspecified},
+		{JSON: `{"on-error": "stop"}`, Want: lbdeploy.OnErrorStop},
+		{JSON: `{"on-error": "fail"}`, Want: lbdeploy.OnErrorStop},
+		{JSON: `{"on-error": "continue"}`, Want: lbdeploy.OnErrorContinue},
+		{JSON: `{"on-error": "retry"}`, Want: "retry"},
+	}
+	for _, fixture := range fixtures {
+		var action lbdeploy.Action
+		if err := json.Unmarshal([]byte(fixture.JSON), &action); err != nil {
+			t.Fatalf("%s: %v", fixture.JSON, err)
+		}
+		if action.OnError != fixture.Want {
+			t.Errorf("%s: got \"%s\", want \"%s\"", fixture.JSON, action.OnError, fixture.Want)
+		}
+	}
+}
+
+func TestDeploymentHooks(t *testing.T) {
+	flows := func(hooks lbdeploy.ActionHooks) lbdeploy.FlowMap {
+		return lbdeploy.FlowMap{
+			"install": {Hooks: hooks, Actions: []lbdeploy.Action{{Type: lbdeploy.ActionStartFlow, Flow: "notify"}}},
+			"notify":  {},
+		}
+	}
+	hook := func(onError lbdeploy.OnErrorBehavior) lbdeploy.Action {
+		return lbdeploy.Action{Type: lbdeploy.ActionStartFlow, Flow: "notify", OnError: onError}
+	}
+
+	testDeploymentFixtures(t, []deploymentFixture{
+		{Name: "none", Flows: flows(lbdeploy.ActionHooks{})},
+		{Name: "before", Flows: flows(lbdeploy.ActionHooks{Before: []lbdeploy.Action{hook("")}})},
+		{Name: "after", Flows: flows(lbdeploy.ActionHooks{After: []lbdeploy.Action{hook(lbdeploy.OnErrorContinue)}})},
+		{Name: "both", Flows: flows(lbdeploy.ActionHooks{Before: []lbdeploy.Action{hook(lbdeploy.OnErrorStop)}, After: []lbdeploy.Action{hook("")}})},
+		{Name: "goto-flow", Flows: flows(lbdeploy.ActionHooks{Before: []lbdeploy.Action{{Type: lbdeploy.ActionStartFlow, Flow: "notify", OnError: lbdeploy.OnErrorGotoFlow, OnErrorFlow: "notify"}}}), Err: `hooks: before hook 1: the "goto-flow" on-error policy is not supported by hooks`},
+		{Name: "invalid", Flows: flows(lbdeploy.ActionHooks{Before: []lbdeploy.Action{hook("retry")}}), Err: `hooks: before hook 1: the "retry" on-error policy is not recognized`},
+	})
+
+	if !(lbdeploy.ActionHooks{}).IsZero() {
+		t.Error("hooks without actions should be zero")
+	}
+	if (lbdeploy.ActionHooks{After: []lbdeploy.Action{hook("")}}).IsZero() {
+		t.Error("hooks with actions should not be zero")
+	}
+}
+
+func TestDeploymentIDValidate(t *testing.T) {
+	fixtures := []struct {
+		ID    lbdeploy.DeploymentID
+		Valid bool
+	}{
+		{ID: "contoso-app", Valid: true},
+		{ID: "Contoso App 2.0", Valid: true},
+		{ID: ""},
+		{ID: "."},
+		{ID: ".."},
+		{ID: "../../Windows/System32/config"},
+		{ID: `..\..\evil`},
+		{ID: "app:stream"},
+		{ID: "app\x00"},
+	}
+	for _, fixture := range fixtures {
+		err := fixture.ID.Validate()
+		if fixture.Valid && err != nil {
+			t.Errorf("%q: unexpected error: %v", fixture.ID, err)
+		} else if !fixture.Valid && err == nil {
+			t.Errorf("%q: expected an error", fixture.ID)
+		}
+	}
+}
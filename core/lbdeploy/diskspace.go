@@ -0,0 +1,24 @@
+package lbdeploy
+
+import "math"
+
+// DiskSpaceSafetyFactor is applied to the declared size of a download or
+// extraction when verifying that enough disk space is available. It leaves
+// room for file system overhead and for anything else that writes to the
+// volume while the operation is in progress.
+const DiskSpaceSafetyFactor = 1.2
+
+// RequiredDiskSpace returns the amount of free disk space that is required
+// to write size bytes, with DiskSpaceSafetyFactor applied. It returns zero
+// if size is not positive, and saturates at the largest int64 value
+// instead of overflowing.
+func RequiredDiskSpace(size int64) int64 {
+	if size <= 0 {
+		return 0
+	}
+	required := float64(size) * DiskSpaceSafetyFactor
+	if required >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(required)
+}
@@ -0,0 +1,30 @@
+package lbdeploy_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestRequiredDiskSpace(t *testing.T) {
+	fixtures := []struct {
+		Size     int64
+		Required int64
+	}{
+		{Size: -1, Required: 0},
+		{Size: 0, Required: 0},
+		{Size: 10, Required: 12},
+		{Size: 1000000, Required: 1200000},
+		{Size: 5 << 30, Required: 6 << 30},
+		{Size: math.MaxInt64 / 2, Required: int64(float64(math.MaxInt64/2) * lbdeploy.DiskSpaceSafetyFactor)},
+		{Size: math.MaxInt64 - 1, Required: math.MaxInt64},
+		{Size: math.MaxInt64, Required: math.MaxInt64},
+	}
+
+	for _, fixture := range fixtures {
+		if got := lbdeploy.RequiredDiskSpace(fixture.Size); got != fixture.Required {
+			t.Errorf("%d bytes: got %d required, want %d", fixture.Size, got, fixture.Required)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package lbdeploy
+
+// PackageManager identifies a Linux package manager.
+type PackageManager string
+
+// Recognized package managers.
+const (
+	PackageManagerApt    PackageManager = "apt"
+	PackageManagerDnf    PackageManager = "dnf"
+	PackageManagerZypper PackageManager = "zypper"
+)
+
+// DistroPackage identifies the distribution package that provides an
+// application on Linux.
+//
+// Name is used with every package manager unless a name is provided for a
+// specific package manager.
+type DistroPackage struct {
+	Name   string `json:"name,omitempty"`
+	Apt    string `json:"apt,omitempty"`
+	Dnf    string `json:"dnf,omitempty"`
+	Zypper string `json:"zypper,omitempty"`
+}
+
+// IsZero returns true if the package is not defined.
+func (pkg DistroPackage) IsZero() bool {
+	return pkg == DistroPackage{}
+}
+
+// NameFor returns the name of the package for the given package manager.
+// It returns an empty string if the package is not available through it.
+func (pkg DistroPackage) NameFor(manager PackageManager) string {
+	var name string
+	switch manager {
+	case PackageManagerApt:
+		name = pkg.Apt
+	case PackageManagerDnf:
+		name = pkg.Dnf
+	case PackageManagerZypper:
+		name = pkg.Zypper
+	}
+	if name == "" {
+		name = pkg.Name
+	}
+	return name
+}
@@ -0,0 +1,51 @@
+package lbdeploy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OutputEncoding identifies the text encoding of output produced by a
+// command or script.
+//
+// In addition to the named encodings, a specific Windows code page can be
+// identified with a "cp" prefix, such as "cp850" or "cp1252".
+type OutputEncoding string
+
+// Output encodings.
+const (
+	OutputEncodingAuto    OutputEncoding = ""
+	OutputEncodingUTF8    OutputEncoding = "utf-8"
+	OutputEncodingUTF16LE OutputEncoding = "utf-16le"
+	OutputEncodingUTF16BE OutputEncoding = "utf-16be"
+	OutputEncodingOEM     OutputEncoding = "oem"
+	OutputEncodingANSI    OutputEncoding = "ansi"
+)
+
+// CodePage returns the code page number for encodings that identify a
+// specific code page, such as "cp850". If the encoding does not identify a
+// specific code page, ok will be false.
+func (enc OutputEncoding) CodePage() (cp uint32, ok bool) {
+	digits, found := strings.CutPrefix(string(enc), "cp")
+	if !found {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(digits, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(value), true
+}
+
+// Validate returns a non-nil error if the encoding is not recognized.
+func (enc OutputEncoding) Validate() error {
+	switch enc {
+	case OutputEncodingAuto, OutputEncodingUTF8, OutputEncodingUTF16LE, OutputEncodingUTF16BE, OutputEncodingOEM, OutputEncodingANSI:
+		return nil
+	}
+	if _, ok := enc.CodePage(); ok {
+		return nil
+	}
+	return fmt.Errorf("the output encoding \"%s\" is not recognized", enc)
+}
@@ -0,0 +1,45 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// VariableMap holds a set of deployment variables, mapped by their names.
+//
+// Variables can be referenced by placeholders in the form {var:name}.
+type VariableMap map[string]string
+
+// EnvironmentMap holds a set of environment variable values, mapped by
+// their names.
+//
+// Values can include placeholders that refer to file resources, directory
+// resources and variables, in the form {file:id}, {dir:id} and {var:name}.
+// The placeholders are replaced with their values when a process is
+// started.
+type EnvironmentMap map[string]string
+
+// Names returns the environment variable names in the map in sorted order.
+func (m EnvironmentMap) Names() []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// Validate returns a non-nil error if any of the environment variable names
+// are invalid.
+func (m EnvironmentMap) Validate() error {
+	for name, value := range m {
+		if name == "" {
+			return errors.New("an environment variable name is missing")
+		}
+		if strings.ContainsAny(name, "=\x00") {
+			return fmt.Errorf("the environment variable name \"%s\" contains an invalid character", name)
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("the value of the \"%s\" environment variable contains a null character", name)
+		}
+	}
+	return nil
+}
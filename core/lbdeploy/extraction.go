@@ -0,0 +1,179 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// ExtractionFilter limits the entries that are extracted from an archive
+// package, so that only the part of a large archive that is needed has to
+// be written to disk.
+//
+// Patterns are matched against the slash-separated path of each entry
+// within the archive, without regard to case. A single asterisk matches
+// any sequence of characters within a path element, a double asterisk
+// matches any sequence of characters across path elements, and a question
+// mark matches a single character. For example, "lang/de-DE/**" matches
+// everything within the lang/de-DE directory.
+//
+// An entry is extracted if it matches at least one of the include
+// patterns, or if there are no include patterns, and it does not match any
+// of the exclude patterns. The parent directories of extracted files are
+// always created.
+type ExtractionFilter struct {
+	Include []string `json:"include,omitzero"`
+	Exclude []string `json:"exclude,omitzero"`
+}
+
+// IsZero returns true if the filter does not exclude anything.
+func (f ExtractionFilter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Validate returns a non-nil error if any of the filter's patterns are
+// invalid.
+func (f ExtractionFilter) Validate() error {
+	_, err := f.Compile()
+	return err
+}
+
+// Compile returns a function that reports whether the entry with the given
+// path should be extracted.
+func (f ExtractionFilter) Compile() (func(name string) bool, error) {
+	include, err := compileGlobs(f.Include)
+	if err != nil {
+		return nil, fmt.Errorf("include: %w", err)
+	}
+	exclude, err := compileGlobs(f.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("exclude: %w", err)
+	}
+
+	return func(name string) bool {
+		name = strings.ToLower(strings.TrimSuffix(name, "/"))
+		if len(include) > 0 && !matchAny(include, name) {
+			return false
+		}
+		return !matchAny(exclude, name)
+	}, nil
+}
+
+// DefaultExtractionMargin is the fraction by which the files extracted from
+// an archive may exceed the expectations of its extraction quota, when the
+// quota does not specify a margin.
+const DefaultExtractionMargin = 0.1
+
+// ExtractionQuota declares how much an archive package is expected to
+// expand when it is extracted, so that an archive that expands far beyond
+// its expectations, such as a zip bomb, is stopped before it fills the
+// disk.
+//
+// TotalBytes and Files are the expected total size and number of the files
+// that are extracted, after the extraction filter has been applied. A zero
+// value means that the size or number of files is not limited.
+//
+// The extraction is stopped if the files exceed either expectation by more
+// than Margin, which is a fraction of the expectation. If a margin is not
+// specified, DefaultExtractionMargin is used.
+type ExtractionQuota struct {
+	TotalBytes int64   `json:"total-bytes,omitempty"`
+	Files      int     `json:"files,omitempty"`
+	Margin     float64 `json:"margin,omitempty"`
+}
+
+// IsZero returns true if the quota does not limit anything.
+func (q ExtractionQuota) IsZero() bool {
+	return q.TotalBytes == 0 && q.Files == 0 && q.Margin == 0
+}
+
+// Validate returns a non-nil error if the quota is invalid.
+func (q ExtractionQuota) Validate() error {
+	switch {
+	case q.IsZero():
+		return nil
+	case q.TotalBytes < 0:
+		return errors.New("the expected total size must not be negative")
+	case q.Files < 0:
+		return errors.New("the expected number of files must not be negative")
+	case q.Margin < 0 || math.IsNaN(q.Margin) || math.IsInf(q.Margin, 0):
+		return errors.New("the margin must be a non-negative fraction")
+	case q.TotalBytes == 0 && q.Files == 0:
+		return errors.New("a margin was provided without an expected total size or number of files")
+	}
+	return nil
+}
+
+// EffectiveMargin returns the margin of the quota, or the default margin if
+// one is not specified.
+func (q ExtractionQuota) EffectiveMargin() float64 {
+	if q.Margin > 0 {
+		return q.Margin
+	}
+	return DefaultExtractionMargin
+}
+
+// MaxBytes returns the largest total size that the extracted files may
+// have. It returns zero if the size is not limited.
+//
+// If the expectation and its margin exceed the range of an int64, the
+// result is saturated at math.MaxInt64.
+func (q ExtractionQuota) MaxBytes() int64 {
+	if q.TotalBytes <= 0 {
+		return 0
+	}
+	return addMargin(q.TotalBytes, q.EffectiveMargin(), math.MaxInt64)
+}
+
+// MaxFiles returns the largest number of files that may be extracted. It
+// returns zero if the number of files is not limited.
+//
+// If the expectation and its margin exceed the range of an int, the result
+// is saturated at math.MaxInt.
+func (q ExtractionQuota) MaxFiles() int {
+	if q.Files <= 0 {
+		return 0
+	}
+	return int(addMargin(int64(q.Files), q.EffectiveMargin(), math.MaxInt))
+}
+
+// addMargin returns n plus a fraction of n, rounded up. The result is
+// saturated at limit, which must be at least n.
+func addMargin(n int64, margin float64, limit int64) int64 {
+	// Floating point values of this size can't represent every integer,
+	// so compare against the remaining headroom before converting back.
+	extra := math.Ceil(float64(n) * margin)
+	if extra >= float64(limit-n) {
+		return limit
+	}
+	return n + int64(extra)
+}
+
+// compileGlobs compiles a set of glob patterns into regular expressions
+// that match lowercase paths.
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	expressions := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("an empty pattern was provided")
+		}
+		re, err := regexp.Compile(globExpression(strings.ToLower(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("the glob pattern \"%s\" is invalid: %w", pattern, err)
+		}
+		expressions = append(expressions, re)
+	}
+	return expressions, nil
+}
+
+// matchAny returns true if any of the expressions match s.
+func matchAny(expressions []*regexp.Regexp, s string) bool {
+	for _, re := range expressions {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
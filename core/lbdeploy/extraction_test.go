@@ -0,0 +1,78 @@
+package lbdeploy_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestExtractionFilter(t *testing.T) {
+	filter := lbdeploy.ExtractionFilter{
+		Include: []string{"setup.exe", "lang/en-US/**", "*.msi"},
+		Exclude: []string{"**.pdb"},
+	}
+	extract, err := filter.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures := []struct {
+		Name    string
+		Extract bool
+	}{
+		{Name: "Setup.exe", Extract: true},
+		{Name: "product.msi", Extract: true},
+		{Name: "x64/product.msi", Extract: false},
+		{Name: "lang/en-us/strings.dll", Extract: true},
+		{Name: "lang/en-US/debug/strings.pdb", Extract: false},
+		{Name: "lang/de-DE/strings.dll", Extract: false},
+		{Name: "lang/de-DE/", Extract: false},
+	}
+
+	for _, fixture := range fixtures {
+		if got := extract(fixture.Name); got != fixture.Extract {
+			t.Errorf("%s: got %t, want %t", fixture.Name, got, fixture.Extract)
+		}
+	}
+}
+
+func TestExtractionQuota(t *testing.T) {
+	fixtures := []struct {
+		Name     string
+		Quota    lbdeploy.ExtractionQuota
+		Valid    bool
+		MaxBytes int64
+		MaxFiles int
+	}{
+		{Name: "unlimited", Valid: true},
+		{Name: "default-margin", Quota: lbdeploy.ExtractionQuota{TotalBytes: 1000, Files: 25}, Valid: true, MaxBytes: 1100, MaxFiles: 28},
+		{Name: "custom-margin", Quota: lbdeploy.ExtractionQuota{TotalBytes: 1000, Margin: 0.5}, Valid: true, MaxBytes: 1500},
+		{Name: "files-only", Quota: lbdeploy.ExtractionQuota{Files: 10}, Valid: true, MaxFiles: 11},
+		{Name: "saturated-size", Quota: lbdeploy.ExtractionQuota{TotalBytes: math.MaxInt64 - 1}, Valid: true, MaxBytes: math.MaxInt64},
+		{Name: "saturated-margin", Quota: lbdeploy.ExtractionQuota{TotalBytes: 1000, Files: 25, Margin: math.MaxFloat64}, Valid: true, MaxBytes: math.MaxInt64, MaxFiles: math.MaxInt},
+		{Name: "negative-size", Quota: lbdeploy.ExtractionQuota{TotalBytes: -1}},
+		{Name: "negative-margin", Quota: lbdeploy.ExtractionQuota{Files: 10, Margin: -0.1}},
+		{Name: "margin-only", Quota: lbdeploy.ExtractionQuota{Margin: 0.2}},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			err := fixture.Quota.Validate()
+			if fixture.Valid && err != nil {
+				t.Fatalf("Validate returned an unexpected error: %v", err)
+			} else if !fixture.Valid {
+				if err == nil {
+					t.Fatal("Validate did not return an error")
+				}
+				return
+			}
+			if got := fixture.Quota.MaxBytes(); got != fixture.MaxBytes {
+				t.Errorf("MaxBytes returned %d, expected %d", got, fixture.MaxBytes)
+			}
+			if got := fixture.Quota.MaxFiles(); got != fixture.MaxFiles {
+				t.Errorf("MaxFiles returned %d, expected %d", got, fixture.MaxFiles)
+			}
+		})
+	}
+}
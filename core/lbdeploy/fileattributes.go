@@ -1,6 +1,7 @@
 package lbdeploy
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"slices"
@@ -60,3 +61,29 @@ func EqualFileAttributes(a, b FileAttributes) bool {
 
 	return true
 }
+
+// MatchFileAttributes returns true if the actual attributes of a file
+// satisfy the expected attributes. The file sizes are only compared if the
+// expected size is known, which is indicated by a non-zero value. Every
+// expected hash must be present in the actual attributes, with the same
+// value.
+func MatchFileAttributes(expected, actual FileAttributes) bool {
+	// Make sure there is something to compare.
+	if expected.Size == 0 && len(expected.Hashes) == 0 {
+		return false
+	}
+
+	// Compare file size.
+	if expected.Size != 0 && expected.Size != actual.Size {
+		return false
+	}
+
+	// Compare hashes.
+	for hashType, value := range expected.Hashes {
+		if !bytes.Equal(value, actual.Hashes[hashType]) {
+			return false
+		}
+	}
+
+	return true
+}
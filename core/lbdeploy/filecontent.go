@@ -0,0 +1,77 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+)
+
+// MaxFileContentSize is the maximum size of a file whose content can be
+// matched against a string or regular expression. Files that are larger
+// than this can still be matched by their hashes.
+const MaxFileContentSize = 16 * 1024 * 1024
+
+// FileContentMatch describes criteria for the content of a file.
+//
+// A match either compares the text of the file against a value, or
+// compares the file's cryptographic hashes against a set of expected
+// hashes. It cannot do both.
+type FileContentMatch struct {
+	// Type is the type of comparison to make against Value. Regular
+	// expressions are evaluated in multi-line mode, so that ^ and $ match
+	// at the start and end of each line.
+	Type MatchType `json:"type,omitempty"`
+
+	// Value is the string or regular expression to match.
+	Value string `json:"value,omitempty"`
+
+	// IgnoreCase causes Value to be compared without regard to case.
+	IgnoreCase bool `json:"ignore-case,omitempty"`
+
+	// Hashes holds expected file hashes. If more than one hash is
+	// provided, all of them must match.
+	Hashes filehash.Map `json:"hashes,omitzero"`
+}
+
+// IsZero returns true if the match does not describe any criteria.
+func (m FileContentMatch) IsZero() bool {
+	return m.Type == "" && m.Value == "" && !m.IgnoreCase && len(m.Hashes) == 0
+}
+
+// Validate returns a non-nil error if the match criteria are missing or
+// invalid.
+func (m FileContentMatch) Validate() error {
+	if len(m.Hashes) > 0 {
+		if m.Type != "" || m.Value != "" {
+			return errors.New("file content can be matched by value or by hash, but not both")
+		}
+		for _, entry := range m.Hashes.ToList() {
+			if entry.Type.Priority() == 0 {
+				return fmt.Errorf("the file hash type \"%s\" is not recognized", entry.Type)
+			}
+			if len(entry.Value) == 0 {
+				return fmt.Errorf("the file hash value for \"%s\" is missing", entry.Type)
+			}
+		}
+		return nil
+	}
+	_, err := m.Compile()
+	return err
+}
+
+// Compile returns a function that reports whether text satisfies the
+// match's value criteria. It returns an error if the match relies on
+// hashes or has an invalid type or value.
+func (m FileContentMatch) Compile() (func(text string) bool, error) {
+	switch m.Type {
+	case MatchEquals, MatchContains, MatchStartsWith, MatchEndsWith:
+		return compileMatchValue(m.Type, m.Value, m.IgnoreCase)
+	case MatchRegularExpression:
+		return compileMatchValue(m.Type, "(?m)"+m.Value, m.IgnoreCase)
+	case "":
+		return nil, errors.New("a file content match type was not provided")
+	default:
+		return nil, fmt.Errorf("the file content match type \"%s\" is not recognized", m.Type)
+	}
+}
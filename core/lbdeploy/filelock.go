@@ -0,0 +1,27 @@
+package lbdeploy
+
+import "fmt"
+
+// FileLockedBehavior identifies a response to take when the target of a
+// copy-file or delete-file action is locked by another process.
+type FileLockedBehavior string
+
+// Behavior options when the target of a file action is locked.
+//
+// When the replace-on-reboot option is used, the file is replaced or
+// deleted the next time the system restarts, and the action succeeds.
+const (
+	FileLockedUnspecified     FileLockedBehavior = ""
+	FileLockedFail            FileLockedBehavior = "fail"
+	FileLockedReplaceOnReboot FileLockedBehavior = "replace-on-reboot"
+)
+
+// Validate returns a non-nil error if the behavior is not recognized.
+func (b FileLockedBehavior) Validate() error {
+	switch b {
+	case FileLockedUnspecified, FileLockedFail, FileLockedReplaceOnReboot:
+		return nil
+	default:
+		return fmt.Errorf("the \"%s\" on-locked behavior is not recognized", b)
+	}
+}
@@ -0,0 +1,39 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestDeploymentFileLocked(t *testing.T) {
+	flows := func(action lbdeploy.Action) lbdeploy.FlowMap {
+		return lbdeploy.FlowMap{"install": {Actions: []lbdeploy.Action{action}}}
+	}
+
+	testDeploymentFixtures(t, []deploymentFixture{
+		{
+			Name:  "delete-fail",
+			Flows: flows(lbdeploy.Action{Type: lbdeploy.ActionDeleteFile, OnLocked: lbdeploy.FileLockedFail}),
+		},
+		{
+			Name:  "delete-replace-on-reboot",
+			Flows: flows(lbdeploy.Action{Type: lbdeploy.ActionDeleteFile, OnLocked: lbdeploy.FileLockedReplaceOnReboot}),
+		},
+		{
+			Name:  "unrecognized",
+			Flows: flows(lbdeploy.Action{Type: lbdeploy.ActionDeleteFile, OnLocked: "wait"}),
+			Err:   `action 1: the "wait" on-locked behavior is not recognized`,
+		},
+		{
+			Name:  "unused",
+			Flows: flows(lbdeploy.Action{Type: lbdeploy.ActionStartFlow, Flow: "install", OnLocked: lbdeploy.FileLockedFail}),
+			Err:   "an on-locked behavior was provided for an action that does not use one",
+		},
+		{
+			Name:  "replace-without-copy",
+			Flows: flows(lbdeploy.Action{Type: lbdeploy.ActionDeleteFile, Replace: true}),
+			Err:   "replace was specified for an action that does not copy files",
+		},
+	})
+}
@@ -24,9 +24,19 @@ type DirectoryResourceID string
 type DirectoryType string
 
 // FileResource describes a directory resource.
+//
+// If a desired state is provided, the directory's presence is checked when
+// the compliance of the deployment is evaluated.
 type DirectoryResource struct {
 	Location DirectoryResourceID // A well-known directory, or another directory ID.
 	Path     string              // Relative to location
+	Desired  DesiredState        `json:"desired,omitempty"`
+
+	// AllowReparsePoint permits the directory to be a junction, symbolic
+	// link or other reparse point. Without it, file actions refuse to
+	// modify files beneath the directory if it is a reparse point, so
+	// that a redirected directory can't be used to change files elsewhere.
+	AllowReparsePoint bool `json:"allow-reparse-point,omitempty"`
 }
 
 // DirRef is a resolved reference to a directory on the local file system.
@@ -62,6 +72,16 @@ type FileResourceID string
 type FileResource struct {
 	Location DirectoryResourceID // A well-known directory, or another directory ID.
 	Path     string              // Relative to location
+
+	// Integrity holds requirements that the file must satisfy before it is
+	// run as a command or script.
+	Integrity FileIntegrity `json:"integrity,omitzero"`
+
+	// Desired is the state that the file is expected to be in when the
+	// compliance of the deployment is evaluated. If the file is expected
+	// to be present and its integrity requirements include hashes, its
+	// content is checked as well.
+	Desired DesiredState `json:"desired,omitempty"`
 }
 
 // FileRef is a resolved reference to a file on the local file system.
@@ -96,10 +116,15 @@ func (ref FileRef) Path() (string, error) {
 }
 
 // KnownFolder is a folder with a known location.
+//
+// Protected folders are never modified by LeafBridge. Privileged folders
+// can only be modified by administrators, so changes made within them are
+// recorded as security audit events.
 type KnownFolder struct {
-	ID        DirectoryResourceID
-	Path      string
-	Protected bool
+	ID         DirectoryResourceID
+	Path       string
+	Protected  bool
+	Privileged bool
 
 	// TODO: Create our own representation of a GUID that is suitable for
 	// cross-platform use, then include it here.
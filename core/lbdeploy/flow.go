@@ -1,5 +1,11 @@
 package lbdeploy
 
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
 // FlowMap holds a set of deployment flows mapped by their identifiers.
 type FlowMap map[FlowID]Flow
 
@@ -8,17 +14,65 @@ type FlowID string
 
 // Flow is a flow of actions within a deployment.
 //
+// If the flow has a frequency, it is skipped when it has already run as
+// often as the frequency allows.
+//
+// If the flow is remediated on drift, it is invoked automatically when an
+// evaluation of the deployment's compliance detects drift.
+//
+// If the flow is disruptive, agents defer it until the machine is idle.
+//
+// If NotBefore or NotAfter are provided, the flow refuses to run outside of
+// that window.
+//
+// If the flow requests a restore point, a system restore point is created
+// before its actions run, so that a bad change can be rolled back. Failure
+// to create the restore point is reported, but does not stop the flow.
+// Restore points are only supported on Windows.
+//
+// If the flow requests backups, each file that its actions copy over,
+// delete or edit is backed up before it is first changed, so that a
+// restore-file action can return it to its prior state.
+//
+// If the flow has a timeout, it limits the total amount of time that the
+// actions of the flow are allowed to run. When it is exceeded, the running
+// action is stopped, its processes are terminated and the flow fails.
+//
+// If the flow has an on-failure section, the work of its completed actions
+// is rolled back when it fails.
+//
+// If the flow has a failure message, it is reported to operators when the
+// flow fails, including when its preconditions are not met.
+//
 // TODO: Consider renaming "Preconditions" to "Requirements".
 type Flow struct {
-	Constraints   ConditionList `json:"constraints,omitzero"`
-	Preconditions ConditionList `json:"preconditions,omitzero"`
-	Locks         []LockID      `json:"locks,omitzero"`
-	Behavior      Behavior      `json:"behavior,omitzero"`
-	Actions       []Action      `json:"actions,omitzero"`
+	Constraints    ConditionList     `json:"constraints,omitzero"`
+	Preconditions  ConditionList     `json:"preconditions,omitzero"`
+	Frequency      FlowFrequency     `json:"frequency,omitzero"`
+	Remediation    FlowRemediation   `json:"remediation,omitzero"`
+	Disruptive     bool              `json:"disruptive,omitempty"`
+	NotBefore      time.Time         `json:"not-before,omitzero"`
+	NotAfter       time.Time         `json:"not-after,omitzero"`
+	Idle           FlowIdle          `json:"idle,omitzero"`
+	RestorePoint   bool              `json:"restore-point,omitempty"`
+	Backup         bool              `json:"backup,omitempty"`
+	Timeout        datatype.Duration `json:"timeout,omitzero"`
+	Locks          []LockID          `json:"locks,omitzero"`
+	Behavior       Behavior          `json:"behavior,omitzero"`
+	Hooks          ActionHooks       `json:"hooks,omitzero"`
+	Actions        []Action          `json:"actions,omitzero"`
+	OnFailure      FlowRollback      `json:"on-failure,omitzero"`
+	FailureMessage string            `json:"failure-message,omitempty"`
 }
 
 // FlowStats hold statistics about a flow that has been invoked.
+//
+// ActionsIgnored counts actions that failed, but whose failure was ignored
+// or handled by their on-error policy. ActionsFailed counts failures that
+// were reported by the flow.
 type FlowStats struct {
 	ActionsCompleted int
 	ActionsFailed    int
+	ActionsIgnored   int
+	ActionsSkipped   int
 }
@@ -0,0 +1,80 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
+// FrequencyScope determines whether the frequency limit of a flow applies
+// to the machine as a whole or to each user that invokes it.
+type FrequencyScope string
+
+// Frequency scopes.
+const (
+	FrequencyPerMachine FrequencyScope = "machine"
+	FrequencyPerUser    FrequencyScope = "user"
+)
+
+// Validate returns a non-nil error if the scope is not recognized.
+func (scope FrequencyScope) Validate() error {
+	switch scope {
+	case "", FrequencyPerMachine, FrequencyPerUser:
+		return nil
+	default:
+		return fmt.Errorf("the frequency scope \"%s\" is not recognized", scope)
+	}
+}
+
+// FlowFrequency limits how often a flow is invoked. Only invocations of the
+// flow that complete successfully count toward the limit, so that a flow
+// that fails will be attempted again the next time it is invoked.
+//
+// If Once is true, the flow is skipped once it has completed successfully.
+// If Interval is non-zero, the flow is skipped until the interval has
+// elapsed since it last completed successfully, such as "168h" for at most
+// once every 7 days.
+//
+// The limit applies to the machine unless the scope is "user", in which
+// case it applies separately to each user that invokes the flow.
+//
+// The history of each flow is kept in a persistent state store. Forced
+// invocations ignore the limit, but are still recorded.
+type FlowFrequency struct {
+	Scope    FrequencyScope    `json:"scope,omitempty"`
+	Once     bool              `json:"once,omitempty"`
+	Interval datatype.Duration `json:"interval,omitzero"`
+}
+
+// IsZero returns true if the frequency does not impose a limit.
+func (f FlowFrequency) IsZero() bool {
+	return !f.Once && f.Interval == 0
+}
+
+// EffectiveScope returns the scope of the frequency limit, which defaults
+// to the machine.
+func (f FlowFrequency) EffectiveScope() FrequencyScope {
+	if f.Scope == "" {
+		return FrequencyPerMachine
+	}
+	return f.Scope
+}
+
+// Validate returns a non-nil error if the frequency contains invalid
+// configuration.
+func (f FlowFrequency) Validate() error {
+	if err := f.Scope.Validate(); err != nil {
+		return err
+	}
+	if f.Interval < 0 {
+		return errors.New("a negative interval was provided")
+	}
+	if f.Once && f.Interval != 0 {
+		return errors.New("a flow cannot be limited to a single run and to an interval at the same time")
+	}
+	if f.Scope != "" && f.IsZero() {
+		return errors.New("a frequency scope was provided without a limit")
+	}
+	return nil
+}
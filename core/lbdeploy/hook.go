@@ -0,0 +1,51 @@
+package lbdeploy
+
+import "fmt"
+
+// HookStage identifies when a hook is invoked relative to the action that
+// it is attached to.
+type HookStage string
+
+// Hook stages.
+const (
+	HookBefore HookStage = "before"
+	HookAfter  HookStage = "after"
+)
+
+// ActionHooks describe actions that are invoked before and after each
+// action within a flow.
+//
+// Hooks are invoked for actions that are about to run, so they are not
+// invoked for actions that are skipped because of their conditions. If a
+// hook that runs before an action fails, the action is not invoked and is
+// treated as having failed. Hooks that run after an action are invoked
+// whether or not the action succeeded.
+//
+// Processes started by a hook receive information about the action through
+// environment variables with a LEAFBRIDGE_ prefix.
+type ActionHooks struct {
+	Before []Action `json:"before,omitzero"`
+	After  []Action `json:"after,omitzero"`
+}
+
+// IsZero returns true if no hooks are defined.
+func (hooks ActionHooks) IsZero() bool {
+	return len(hooks.Before) == 0 && len(hooks.After) == 0
+}
+
+// Validate returns an error if any of the hooks are invalid.
+func (hooks ActionHooks) Validate() error {
+	for stage, actions := range map[HookStage][]Action{HookBefore: hooks.Before, HookAfter: hooks.After} {
+		for i, action := range actions {
+			if err := action.ValidateErrorPolicy(); err != nil {
+				return fmt.Errorf("%s hook %d: %w", stage, i+1, err)
+			}
+			switch action.OnError {
+			case OnErrorUnspecified, OnErrorStop, OnErrorContinue:
+			default:
+				return fmt.Errorf("%s hook %d: the \"%s\" on-error policy is not supported by hooks", stage, i+1, action.OnError)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/leafbridge/leafbridge/core/hostsfile"
+)
+
+// HostsEntry describes a hostname that is added to or removed from the
+// hosts file of the local system.
+//
+// Entries are idempotent by hostname. When an entry is added, the
+// hostname is removed from any other addresses it is mapped to. When an
+// entry is removed, the hostname is removed from every address.
+type HostsEntry struct {
+	Hostname string `json:"hostname"`
+	Address  string `json:"address,omitempty"`
+	Remove   bool   `json:"remove,omitempty"`
+}
+
+// Validate returns an error if the entry is not valid.
+func (entry HostsEntry) Validate() error {
+	if err := hostsfile.ValidateHostname(entry.Hostname); err != nil {
+		return err
+	}
+	if entry.Remove {
+		if entry.Address != "" {
+			return errors.New("an address cannot be provided when removing a hostname")
+		}
+		return nil
+	}
+	if entry.Address == "" {
+		return fmt.Errorf("an address was not provided for \"%s\"", entry.Hostname)
+	}
+	if _, err := netip.ParseAddr(entry.Address); err != nil {
+		return fmt.Errorf("the address \"%s\" is not valid", entry.Address)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Hypervisor identifies a virtualization platform that a virtual machine
+// runs on.
+type Hypervisor string
+
+// Recognized hypervisors.
+const (
+	HypervisorHyperV     Hypervisor = "hyper-v"
+	HypervisorVMware     Hypervisor = "vmware"
+	HypervisorVirtualBox Hypervisor = "virtualbox"
+	HypervisorKVM        Hypervisor = "kvm"
+	HypervisorXen        Hypervisor = "xen"
+	HypervisorParallels  Hypervisor = "parallels"
+	HypervisorOther      Hypervisor = "other"
+)
+
+// Validate returns a non-nil error if the hypervisor is not recognized.
+func (h Hypervisor) Validate() error {
+	switch h {
+	case HypervisorHyperV, HypervisorVMware, HypervisorVirtualBox, HypervisorKVM, HypervisorXen, HypervisorParallels, HypervisorOther:
+		return nil
+	case "":
+		return errors.New("a hypervisor was not provided")
+	default:
+		return fmt.Errorf("the hypervisor \"%s\" is not recognized", h)
+	}
+}
+
+// hypervisorSignatures map the system manufacturer or product names
+// reported by the firmware of virtual machines to their hypervisors.
+var hypervisorSignatures = []struct {
+	Text       string
+	Hypervisor Hypervisor
+}{
+	{"vmware", HypervisorVMware},
+	{"virtualbox", HypervisorVirtualBox},
+	{"innotek", HypervisorVirtualBox},
+	{"parallels", HypervisorParallels},
+	{"xen", HypervisorXen},
+	{"qemu", HypervisorKVM},
+	{"kvm", HypervisorKVM},
+	{"rhev", HypervisorKVM},
+	{"openstack", HypervisorKVM},
+	{"nutanix", HypervisorKVM},
+	{"amazon ec2", HypervisorKVM},
+	{"google compute engine", HypervisorKVM},
+}
+
+// IdentifyHypervisor examines the system manufacturer and product names
+// reported by the firmware and returns the hypervisor they belong to. It
+// returns an empty string if they describe a physical machine.
+func IdentifyHypervisor(manufacturer, product string) Hypervisor {
+	manufacturer = strings.ToLower(manufacturer)
+	product = strings.ToLower(product)
+
+	// Hyper-V virtual machines, including those in Azure, report
+	// Microsoft as their manufacturer. Surface devices do as well, so the
+	// product name is checked too.
+	if strings.HasPrefix(manufacturer, "microsoft") && product == "virtual machine" {
+		return HypervisorHyperV
+	}
+
+	for _, signature := range hypervisorSignatures {
+		if strings.Contains(manufacturer, signature.Text) || strings.Contains(product, signature.Text) {
+			return signature.Hypervisor
+		}
+	}
+
+	return ""
+}
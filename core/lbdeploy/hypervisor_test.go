@@ -0,0 +1,34 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+type hypervisorFixture struct {
+	Manufacturer string
+	Product      string
+	Hypervisor   lbdeploy.Hypervisor
+}
+
+var hypervisorFixtures = []hypervisorFixture{
+	{Manufacturer: "Microsoft Corporation", Product: "Virtual Machine", Hypervisor: lbdeploy.HypervisorHyperV},
+	{Manufacturer: "Microsoft Corporation", Product: "Surface Laptop 5", Hypervisor: ""},
+	{Manufacturer: "VMware, Inc.", Product: "VMware7,1", Hypervisor: lbdeploy.HypervisorVMware},
+	{Manufacturer: "innotek GmbH", Product: "VirtualBox", Hypervisor: lbdeploy.HypervisorVirtualBox},
+	{Manufacturer: "QEMU", Product: "Standard PC (Q35 + ICH9, 2009)", Hypervisor: lbdeploy.HypervisorKVM},
+	{Manufacturer: "Xen", Product: "HVM domU", Hypervisor: lbdeploy.HypervisorXen},
+	{Manufacturer: "Parallels International GmbH.", Product: "Parallels ARM Virtual Machine", Hypervisor: lbdeploy.HypervisorParallels},
+	{Manufacturer: "Dell Inc.", Product: "OptiPlex 7090", Hypervisor: ""},
+}
+
+func TestIdentifyHypervisor(t *testing.T) {
+	for _, fixture := range hypervisorFixtures {
+		t.Run(fixture.Manufacturer+"/"+fixture.Product, func(t *testing.T) {
+			if got := lbdeploy.IdentifyHypervisor(fixture.Manufacturer, fixture.Product); got != fixture.Hypervisor {
+				t.Errorf("got \"%s\", want \"%s\"", got, fixture.Hypervisor)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package lbdeploy
+
+import (
+	"errors"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
+// DefaultIdleTime is the amount of time without user input that is
+// required before a disruptive flow is run by an agent, if the flow does
+// not specify one.
+const DefaultIdleTime = 15 * time.Minute
+
+// FlowIdle describes when a disruptive flow is allowed to run.
+//
+// A disruptive flow is deferred by an agent until the machine has been
+// idle for Time, and no full-screen or presentation apps are being used.
+// If Deadline is non-zero, the flow stops being deferred once that much
+// time has passed since it was first deferred, much like the deadline of
+// a Windows update.
+//
+// Interactive invocations of a flow are never deferred.
+type FlowIdle struct {
+	Time     datatype.Duration `json:"time,omitzero"`
+	Deadline datatype.Duration `json:"deadline,omitzero"`
+}
+
+// EffectiveTime returns the amount of time without user input that is
+// required before the flow is run.
+func (idle FlowIdle) EffectiveTime() time.Duration {
+	if idle.Time <= 0 {
+		return DefaultIdleTime
+	}
+	return time.Duration(idle.Time)
+}
+
+// Validate returns a non-nil error if the idle requirement contains
+// invalid configuration.
+func (idle FlowIdle) Validate() error {
+	if idle.Time < 0 {
+		return errors.New("a negative idle time was provided")
+	}
+	if idle.Deadline < 0 {
+		return errors.New("a negative deadline was provided")
+	}
+	return nil
+}
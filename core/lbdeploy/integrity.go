@@ -0,0 +1,99 @@
+package lbdeploy
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+)
+
+// FileIntegrity describes integrity requirements that a file must satisfy
+// before LeafBridge will run it as a command or script, or apply it as a
+// Windows Installer transform. The file is locked while it is verified and
+// remains locked until it has been used.
+type FileIntegrity struct {
+	// Hashes is a set of cryptographic hashes that the file must match. If
+	// more than one hash is provided, the file must match all of them.
+	Hashes filehash.Map `json:"hashes,omitzero"`
+
+	// Signature describes Authenticode signature requirements for the file.
+	Signature SignatureRequirement `json:"signature,omitzero"`
+}
+
+// IsZero returns true if the integrity requirements are empty.
+func (integrity FileIntegrity) IsZero() bool {
+	return len(integrity.Hashes) == 0 && integrity.Signature.IsZero()
+}
+
+// Validate returns a non-nil error if the integrity requirements are
+// invalid.
+func (integrity FileIntegrity) Validate() error {
+	for _, entry := range integrity.Hashes.ToList() {
+		if entry.Type.Priority() == 0 {
+			return fmt.Errorf("the file hash type \"%s\" is not recognized", entry.Type)
+		}
+		if len(entry.Value) == 0 {
+			return fmt.Errorf("the file hash value for \"%s\" is missing", entry.Type)
+		}
+	}
+	return integrity.Signature.Validate()
+}
+
+// SignatureRequirement describes Authenticode signature requirements for a
+// file.
+//
+// If any publishers or thumbprints are listed, the file's signing
+// certificate must match at least one of them.
+type SignatureRequirement struct {
+	// Required indicates that the file must have a valid Authenticode
+	// signature that chains to a trusted root.
+	Required bool `json:"required,omitempty"`
+
+	// Publishers is a list of trusted publishers, identified by the subject
+	// name of their signing certificate.
+	Publishers []string `json:"publishers,omitzero"`
+
+	// Thumbprints is a list of trusted signing certificates, identified by
+	// their SHA-1 thumbprints in hexadecimal format.
+	Thumbprints []string `json:"thumbprints,omitzero"`
+}
+
+// IsZero returns true if the signature requirements are empty.
+func (req SignatureRequirement) IsZero() bool {
+	return !req.Required && len(req.Publishers) == 0 && len(req.Thumbprints) == 0
+}
+
+// Validate returns a non-nil error if the signature requirements are
+// invalid.
+func (req SignatureRequirement) Validate() error {
+	if !req.Required && (len(req.Publishers) > 0 || len(req.Thumbprints) > 0) {
+		return errors.New("trusted publishers or thumbprints were provided but a signature is not required")
+	}
+	for _, thumbprint := range req.Thumbprints {
+		if b, err := hex.DecodeString(thumbprint); err != nil || len(b) != 20 {
+			return fmt.Errorf("the thumbprint \"%s\" is not a valid SHA-1 thumbprint", thumbprint)
+		}
+	}
+	return nil
+}
+
+// Trusts returns true if a signer with the given subject name and
+// thumbprint satisfies the requirements.
+func (req SignatureRequirement) Trusts(subject, thumbprint string) bool {
+	if len(req.Publishers) == 0 && len(req.Thumbprints) == 0 {
+		return true
+	}
+	for _, publisher := range req.Publishers {
+		if strings.EqualFold(publisher, subject) {
+			return true
+		}
+	}
+	for _, trusted := range req.Thumbprints {
+		if strings.EqualFold(trusted, thumbprint) {
+			return true
+		}
+	}
+	return false
+}
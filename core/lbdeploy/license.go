@@ -0,0 +1,126 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultKMSPort is the port that a key management service listens on when
+// a port is not specified.
+const DefaultKMSPort = 1688
+
+// LicenseProduct identifies a product whose volume license is activated.
+type LicenseProduct string
+
+// Recognized license products. Office activation covers Office 2013 and
+// later, which is licensed by the Software Protection Platform of Windows.
+const (
+	LicenseProductWindows LicenseProduct = "windows"
+	LicenseProductOffice  LicenseProduct = "office"
+)
+
+// Validate returns a non-nil error if the product is not recognized.
+func (product LicenseProduct) Validate() error {
+	switch product {
+	case LicenseProductWindows, LicenseProductOffice:
+		return nil
+	case "":
+		return errors.New("a license product was not provided")
+	default:
+		return fmt.Errorf("the license product \"%s\" is not recognized", product)
+	}
+}
+
+// LicenseActivation describes the activation of a volume license.
+//
+// If ProductKey is provided, it is installed unless a key ending in the
+// same characters is already installed. If KMSHost is provided, the key
+// management service at that address is used for activation. It may
+// include a port, such as "kms.contoso.com:1688". Otherwise the service is
+// located through DNS, or the product is activated through Active
+// Directory-based activation.
+//
+// Products that are already activated are left alone.
+type LicenseActivation struct {
+	Product    LicenseProduct `json:"product"`
+	ProductKey Secret         `json:"product-key,omitzero"`
+	KMSHost    string         `json:"kms-host,omitempty"`
+}
+
+// Validate returns an error if the activation is not valid.
+func (activation LicenseActivation) Validate() error {
+	if err := activation.Product.Validate(); err != nil {
+		return err
+	}
+	if !activation.ProductKey.IsZero() {
+		if err := activation.ProductKey.Validate(); err != nil {
+			return fmt.Errorf("product key: %w", err)
+		}
+		if activation.ProductKey.Value != "" {
+			if err := ValidateProductKey(activation.ProductKey.Value); err != nil {
+				return err
+			}
+		}
+	}
+	if activation.KMSHost != "" {
+		if _, _, err := activation.KMSAddress(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// KMSAddress returns the host and port of the key management service.
+func (activation LicenseActivation) KMSAddress() (host string, port int, err error) {
+	host = activation.KMSHost
+	if strings.Contains(host, ":") {
+		var portString string
+		if host, portString, err = net.SplitHostPort(host); err != nil {
+			return "", 0, fmt.Errorf("the KMS host \"%s\" is not valid: %w", activation.KMSHost, err)
+		}
+		if port, err = strconv.Atoi(portString); err != nil || port < 1 || port > 65535 {
+			return "", 0, fmt.Errorf("the KMS host \"%s\" does not have a valid port", activation.KMSHost)
+		}
+	} else {
+		port = DefaultKMSPort
+	}
+	if host == "" || strings.ContainsAny(host, " \t'\"") {
+		return "", 0, fmt.Errorf("the KMS host \"%s\" is not valid", activation.KMSHost)
+	}
+	return host, port, nil
+}
+
+// ValidateProductKey returns an error if key is not a product key made up
+// of five groups of five characters, such as
+// "XXXXX-XXXXX-XXXXX-XXXXX-XXXXX".
+func ValidateProductKey(key string) error {
+	groups := strings.Split(key, "-")
+	if len(groups) != 5 {
+		return errors.New("the product key must have five groups of five characters")
+	}
+	for _, group := range groups {
+		if len(group) != 5 {
+			return errors.New("the product key must have five groups of five characters")
+		}
+		for i := range len(group) {
+			c := group[i]
+			if !('0' <= c && c <= '9') && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') {
+				return errors.New("the product key contains an invalid character")
+			}
+		}
+	}
+	return nil
+}
+
+// PartialProductKey returns the last five characters of key in upper case,
+// which is how Windows identifies an installed product key.
+func PartialProductKey(key string) string {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if len(key) < 5 {
+		return key
+	}
+	return key[len(key)-5:]
+}
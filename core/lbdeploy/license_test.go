@@ -0,0 +1,79 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestLicenseActivation(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Activation lbdeploy.LicenseActivation
+		Host       string
+		Port       int
+		Valid      bool
+	}{
+		{
+			Name:       "kms-default-port",
+			Activation: lbdeploy.LicenseActivation{Product: "windows", KMSHost: "kms.contoso.com"},
+			Host:       "kms.contoso.com",
+			Port:       1688,
+			Valid:      true,
+		},
+		{
+			Name:       "kms-port",
+			Activation: lbdeploy.LicenseActivation{Product: "office", KMSHost: "kms.contoso.com:1700"},
+			Host:       "kms.contoso.com",
+			Port:       1700,
+			Valid:      true,
+		},
+		{
+			Name:       "key",
+			Activation: lbdeploy.LicenseActivation{Product: "windows", ProductKey: lbdeploy.Secret{Value: "NPPR9-FWDCX-D2C8J-H872K-2YT43"}},
+			Valid:      true,
+		},
+		{
+			Name:       "key-environment",
+			Activation: lbdeploy.LicenseActivation{Product: "office", ProductKey: lbdeploy.Secret{Environment: "OFFICE_KEY"}},
+			Valid:      true,
+		},
+		{
+			Name:       "short-key",
+			Activation: lbdeploy.LicenseActivation{Product: "windows", ProductKey: lbdeploy.Secret{Value: "NPPR9-FWDCX-D2C8J-H872K"}},
+		},
+		{
+			Name:       "bad-port",
+			Activation: lbdeploy.LicenseActivation{Product: "windows", KMSHost: "kms.contoso.com:http"},
+		},
+		{
+			Name:       "unknown-product",
+			Activation: lbdeploy.LicenseActivation{Product: "visio"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := test.Activation.Validate()
+			if test.Valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !test.Valid {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if test.Activation.KMSHost == "" {
+				return
+			}
+			host, port, err := test.Activation.KMSAddress()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if host != test.Host || port != test.Port {
+				t.Errorf("KMS address: got %s:%d, want %s:%d", host, port, test.Host, test.Port)
+			}
+		})
+	}
+}
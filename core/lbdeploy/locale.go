@@ -0,0 +1,23 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidateLocaleName returns a non-nil error if name is not a plausible
+// language tag, such as "de" or "de-DE", or keyboard layout identifier,
+// such as "00000407".
+func ValidateLocaleName(name string) error {
+	if name == "" {
+		return errors.New("a language, locale or keyboard layout was not provided")
+	}
+	for _, c := range name {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-':
+		default:
+			return fmt.Errorf("\"%s\" is not a valid language, locale or keyboard layout", name)
+		}
+	}
+	return nil
+}
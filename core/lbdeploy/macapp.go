@@ -0,0 +1,22 @@
+package lbdeploy
+
+// MacApp identifies an application on macOS.
+//
+// If Bundle is provided, the application is installed when its bundle
+// exists, and its version is read from the bundle's Info.plist file.
+// Otherwise the receipt of the installer package identified by PackageID
+// is consulted.
+type MacApp struct {
+	// Bundle is the absolute path of the application bundle, such as
+	// /Applications/Example.app.
+	Bundle string `json:"bundle,omitempty"`
+
+	// PackageID is the identifier of the installer package that installs
+	// the application, such as com.example.app.pkg.
+	PackageID string `json:"package-id,omitempty"`
+}
+
+// IsZero returns true if the application is not identified.
+func (app MacApp) IsZero() bool {
+	return app == MacApp{}
+}
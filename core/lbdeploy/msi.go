@@ -0,0 +1,272 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
+// MSIOptions holds options for commands that invoke the Windows Installer.
+type MSIOptions struct {
+	// UILevel determines the user interface level that will be displayed
+	// by the Windows Installer. If a level is not specified, the installer
+	// will be run quietly.
+	UILevel MSIUILevel `json:"ui-level,omitempty"`
+
+	// Transforms is an ordered list of transforms to be applied during
+	// installation.
+	Transforms []TransformID `json:"transforms,omitzero"`
+
+	// Properties is a set of public properties to be provided to the
+	// Windows Installer.
+	Properties MSIPropertyMap `json:"properties,omitzero"`
+
+	// Log configures logging by the Windows Installer.
+	Log MSILogOptions `json:"log,omitzero"`
+
+	// Restart determines whether the Windows Installer is permitted to
+	// restart the system. If a restart behavior is not specified, restarts
+	// are suppressed.
+	Restart MSIRestart `json:"restart,omitempty"`
+
+	// BusyTimeout is the maximum amount of time to wait for another
+	// Windows Installer operation to finish before the command is run. If
+	// a timeout is not specified, DefaultMSIBusyTimeout is used.
+	BusyTimeout datatype.Duration `json:"busy-timeout,omitzero"`
+}
+
+// DefaultMSIBusyTimeout is the amount of time that msi-based commands wait
+// for another Windows Installer operation to finish, when a timeout has not
+// been specified.
+const DefaultMSIBusyTimeout = datatype.Duration(time.Minute * 10)
+
+// EffectiveBusyTimeout returns the amount of time to wait for another
+// Windows Installer operation to finish.
+func (opts MSIOptions) EffectiveBusyTimeout() time.Duration {
+	if opts.BusyTimeout > 0 {
+		return time.Duration(opts.BusyTimeout)
+	}
+	return time.Duration(DefaultMSIBusyTimeout)
+}
+
+// IsZero returns true if no options have been specified.
+func (opts MSIOptions) IsZero() bool {
+	return opts.UILevel == "" && len(opts.Transforms) == 0 && len(opts.Properties) == 0 && opts.Log.IsZero() && opts.Restart == "" && opts.BusyTimeout == 0
+}
+
+// Validate returns a non-nil error if the options contain invalid
+// configuration.
+func (opts MSIOptions) Validate() error {
+	if err := opts.UILevel.Validate(); err != nil {
+		return err
+	}
+	for _, transform := range opts.Transforms {
+		if transform == "" || transform == ":" {
+			return errors.New("a transform ID is missing")
+		}
+	}
+	if err := opts.Properties.Validate(); err != nil {
+		return err
+	}
+	if len(opts.Transforms) > 0 {
+		if _, found := opts.Properties["TRANSFORMS"]; found {
+			return errors.New("transforms cannot be specified both as a list and as a TRANSFORMS property")
+		}
+	}
+	if err := opts.Log.Validate(); err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+	if err := opts.Restart.Validate(); err != nil {
+		return err
+	}
+	if opts.Restart.ReallySuppress() {
+		if _, found := opts.Properties["REBOOT"]; found {
+			return fmt.Errorf("the %s restart behavior cannot be combined with a REBOOT property", opts.Restart)
+		}
+	}
+	if opts.BusyTimeout < 0 {
+		return errors.New("the busy timeout must not be negative")
+	}
+	return nil
+}
+
+// MSILogOptions configure logging by the Windows Installer.
+//
+// When a log directory is provided, a verbose log with a unique file name
+// will be written to the directory each time the command is invoked.
+type MSILogOptions struct {
+	// Directory identifies the directory resource that logs will be written
+	// to. If a directory is not specified, logging is disabled.
+	Directory DirectoryResourceID `json:"directory,omitempty"`
+
+	// TailLines is the number of lines at the end of the log that will be
+	// included in event details when the command fails. If zero, the log
+	// will not be examined.
+	TailLines int `json:"tail-lines,omitempty"`
+}
+
+// IsZero returns true if the log options are empty.
+func (opts MSILogOptions) IsZero() bool {
+	return opts == MSILogOptions{}
+}
+
+// Validate returns a non-nil error if the log options are invalid.
+func (opts MSILogOptions) Validate() error {
+	if opts.TailLines < 0 {
+		return errors.New("the number of tail lines must not be negative")
+	}
+	if opts.TailLines > 0 && opts.Directory == "" {
+		return errors.New("tail lines were requested but a log directory was not specified")
+	}
+	return nil
+}
+
+// MSIUILevel identifies a user interface level for the Windows Installer.
+type MSIUILevel string
+
+// Windows Installer user interface levels.
+const (
+	MSIUINone    MSIUILevel = "none"
+	MSIUIPassive MSIUILevel = "passive"
+	MSIUIBasic   MSIUILevel = "basic"
+	MSIUIReduced MSIUILevel = "reduced"
+	MSIUIFull    MSIUILevel = "full"
+)
+
+// Validate returns a non-nil error if the user interface level is not
+// recognized.
+func (level MSIUILevel) Validate() error {
+	switch level {
+	case "", MSIUINone, MSIUIPassive, MSIUIBasic, MSIUIReduced, MSIUIFull:
+		return nil
+	default:
+		return fmt.Errorf("the user interface level \"%s\" is not recognized", level)
+	}
+}
+
+// Flag returns the msiexec command line option for the user interface
+// level. If a level has not been specified, it returns "/quiet".
+func (level MSIUILevel) Flag() string {
+	switch level {
+	case MSIUINone:
+		return "/qn"
+	case MSIUIPassive:
+		return "/passive"
+	case MSIUIBasic:
+		return "/qb"
+	case MSIUIReduced:
+		return "/qr"
+	case MSIUIFull:
+		return "/qf"
+	default:
+		return "/quiet"
+	}
+}
+
+// MSIRestart identifies the restart behavior of the Windows Installer.
+type MSIRestart string
+
+// Windows Installer restart behaviors.
+//
+// MSIRestartSuppress prevents the installer from restarting the system at
+// the end of an operation. MSIRestartReallySuppress additionally sets the
+// REBOOT property to ReallySuppress, which prevents restarts that are
+// scheduled by the package itself. MSIRestartPrompt asks the user before
+// restarting the system.
+const (
+	MSIRestartSuppress       MSIRestart = "suppress"
+	MSIRestartReallySuppress MSIRestart = "really-suppress"
+	MSIRestartPrompt         MSIRestart = "prompt"
+)
+
+// Validate returns a non-nil error if the restart behavior is not
+// recognized.
+func (restart MSIRestart) Validate() error {
+	switch restart {
+	case "", MSIRestartSuppress, MSIRestartReallySuppress, MSIRestartPrompt:
+		return nil
+	default:
+		return fmt.Errorf("the restart behavior \"%s\" is not recognized", restart)
+	}
+}
+
+// Flag returns the msiexec command line option for the restart behavior.
+// If a behavior has not been specified, it returns "/norestart".
+func (restart MSIRestart) Flag() string {
+	if restart == MSIRestartPrompt {
+		return "/promptrestart"
+	}
+	return "/norestart"
+}
+
+// ReallySuppress returns true if the REBOOT property should be set to
+// ReallySuppress.
+func (restart MSIRestart) ReallySuppress() bool {
+	return restart == MSIRestartReallySuppress
+}
+
+// TransformID identifies a transform to be applied by the Windows Installer.
+//
+// For commands applied to archive packages, it identifies the transform file
+// within the archive, and will be interpreted as a PackageFileID.
+//
+// For non-package commands, it identifies the transform file on the local
+// file system, and will be interpreted as a FileResourceID.
+//
+// Transforms that begin with a colon are embedded within the installer
+// package itself, and will be passed to the Windows Installer as-is.
+type TransformID string
+
+// IsEmbedded returns true if the transform is embedded within the installer
+// package.
+func (id TransformID) IsEmbedded() bool {
+	return strings.HasPrefix(string(id), ":")
+}
+
+// MSIPropertyMap holds a set of Windows Installer property values, mapped by
+// their property names.
+type MSIPropertyMap map[string]string
+
+// Names returns the property names in the map in sorted order.
+func (m MSIPropertyMap) Names() []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// Validate returns a non-nil error if any of the property names are not
+// valid public property names.
+//
+// Only public properties can be set on the command line. Public property
+// names do not contain lowercase letters.
+func (m MSIPropertyMap) Validate() error {
+	for name := range m {
+		if err := validateMSIPropertyName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateMSIPropertyName(name string) error {
+	if name == "" {
+		return errors.New("a property name is missing")
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z', r == '_':
+		case (r >= '0' && r <= '9') || r == '.':
+			if i == 0 {
+				return fmt.Errorf("the property name \"%s\" must begin with a letter or underscore", name)
+			}
+		case r >= 'a' && r <= 'z':
+			return fmt.Errorf("the property name \"%s\" is not a public property name because it contains lowercase letters", name)
+		default:
+			return fmt.Errorf("the property name \"%s\" contains an invalid character: %q", name, r)
+		}
+	}
+	return nil
+}
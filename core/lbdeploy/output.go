@@ -0,0 +1,71 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultOutputLimit is the maximum number of bytes of output that will be
+// captured from a command or script when a limit is not specified.
+const DefaultOutputLimit = 1024 * 1024
+
+// OutputTruncation identifies the portion of output that is kept when the
+// output exceeds its limits.
+type OutputTruncation string
+
+// Output truncation strategies.
+const (
+	OutputKeepHead     OutputTruncation = "head"
+	OutputKeepTail     OutputTruncation = "tail"
+	OutputKeepHeadTail OutputTruncation = "head-tail"
+)
+
+// Validate returns a non-nil error if the truncation strategy is not
+// recognized.
+func (t OutputTruncation) Validate() error {
+	switch t {
+	case "", OutputKeepHead, OutputKeepTail, OutputKeepHeadTail:
+		return nil
+	default:
+		return fmt.Errorf("unrecognized output truncation strategy \"%s\"", t)
+	}
+}
+
+// OutputOptions determine how much of the output of a command or script
+// is captured and included in events.
+//
+// MaxBytes limits the number of bytes of output that are captured. If it
+// is zero, DefaultOutputLimit is used. If it is negative, the output is
+// not limited by size. MaxLines, if non-zero, further limits the number of
+// lines that are included in events.
+//
+// Keep determines which portion of the output is retained when it exceeds
+// the limits. If it isn't specified, the beginning of the output is kept.
+// When both the beginning and the end are kept, the limits are split
+// evenly between them.
+//
+// If File identifies a file resource, the complete output is written to
+// that file without regard to the limits.
+type OutputOptions struct {
+	MaxBytes int64            `json:"max-bytes,omitempty"`
+	MaxLines int              `json:"max-lines,omitempty"`
+	Keep     OutputTruncation `json:"keep,omitempty"`
+	File     FileResourceID   `json:"file,omitempty"`
+}
+
+// ByteLimit returns the maximum number of bytes of output that will be
+// captured. It returns a negative value if the output is not limited.
+func (opts OutputOptions) ByteLimit() int64 {
+	if opts.MaxBytes == 0 {
+		return DefaultOutputLimit
+	}
+	return opts.MaxBytes
+}
+
+// Validate returns a non-nil error if the output options are invalid.
+func (opts OutputOptions) Validate() error {
+	if opts.MaxLines < 0 {
+		return errors.New("the maximum number of output lines must not be negative")
+	}
+	return opts.Keep.Validate()
+}
@@ -3,6 +3,9 @@ package lbdeploy
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/leafbridge/leafbridge/core/filehash"
 )
@@ -10,6 +13,11 @@ import (
 // PackageMap holds a set of packages mapped by their identifiers.
 type PackageMap map[PackageID]Package
 
+// IDs returns the package identifiers in the map in sorted order.
+func (m PackageMap) IDs() []PackageID {
+	return slices.Sorted(maps.Keys(m))
+}
+
 // PackageID is a unique identifier for a deployment package.
 type PackageID string
 
@@ -62,17 +70,39 @@ type PackageFormat string
 
 // Package defines a deployment package.
 //
+// The expected hashes of a package are normally provided in its file
+// attributes. They can instead be retrieved from a detached checksums file,
+// in which case the size of the package can be omitted.
+//
+// Downloads of the package are retried according to its retry policy when
+// they fail due to transient network errors.
+//
+// If an archive package has an extraction filter, only the matching
+// entries of the archive are extracted.
+//
+// A package can provide different sources for each architecture in
+// Architectures, along with the attributes or checksums file that verify
+// them. The engine selects the variant that suits the operating system,
+// preferring native code over code that runs under emulation. The
+// package's own sources are used when none of its variants are
+// compatible.
+//
 // TODO: Add support for a destination directory where an archive's extracted
 // files will be extracted to. If a destination is not provided, then fall
 // back to the current approach that extracts files to a temporary directory.
 type Package struct {
-	Name       string          `json:"name,omitempty"`
-	Type       PackageType     `json:"type,omitempty"`
-	Format     PackageFormat   `json:"format,omitempty"`
-	Sources    []PackageSource `json:"sources,omitempty"`
-	Attributes FileAttributes  `json:"attributes,omitzero"`
-	Files      PackageFileMap  `json:"files,omitzero"`
-	Commands   CommandMap      `json:"commands,omitzero"`
+	Name          string                          `json:"name,omitempty"`
+	Type          PackageType                     `json:"type,omitempty"`
+	Format        PackageFormat                   `json:"format,omitempty"`
+	Sources       []PackageSource                 `json:"sources,omitempty"`
+	Retry         RetryPolicy                     `json:"retry,omitzero"`
+	Attributes    FileAttributes                  `json:"attributes,omitzero"`
+	Checksums     ChecksumsFile                   `json:"checksums,omitzero"`
+	Files         PackageFileMap                  `json:"files,omitzero"`
+	Extract       ExtractionFilter                `json:"extract,omitzero"`
+	Quota         ExtractionQuota                 `json:"quota,omitzero"`
+	Commands      CommandMap                      `json:"commands,omitzero"`
+	Architectures map[Architecture]PackageVariant `json:"architectures,omitzero"`
 	//Destinations []DirectoryResourceID `json:"destinations,omitempty"`
 }
 
@@ -123,13 +153,75 @@ func (pkg Package) Validate() error {
 		}
 	}
 
+	// Validate the package retry policy.
+	if err := pkg.Retry.Validate(); err != nil {
+		return fmt.Errorf("package retry policy: %w", err)
+	}
+
 	// Validate package file attributes.
 	if err := pkg.Attributes.Validate(); err != nil {
 		return fmt.Errorf("package file attributes: %w", err)
 	}
 
+	// Validate the package checksums file.
+	if !pkg.Checksums.IsZero() {
+		if len(pkg.Attributes.Hashes) > 0 {
+			return errors.New("package file hashes cannot be provided alongside a checksums file")
+		}
+		if err := pkg.Checksums.Validate(); err != nil {
+			return fmt.Errorf("package checksums file: %w", err)
+		}
+	}
+
+	// Validate the per-architecture variants of the package.
+	for arch, variant := range pkg.Architectures {
+		if err := arch.Validate(); err != nil {
+			return fmt.Errorf("package architectures: %w", err)
+		}
+		if err := variant.Validate(); err != nil {
+			return fmt.Errorf("package architecture %s: %w", arch, err)
+		}
+	}
+
+	// Validate package files.
+	for id, file := range pkg.Files {
+		if err := file.Signature.Validate(); err != nil {
+			return fmt.Errorf("package file \"%s\": %w", id, err)
+		}
+	}
+
+	// Validate the extraction filter. Files that are declared by the
+	// package must not be filtered out.
+	if !pkg.Extract.IsZero() {
+		if pkg.Type != "archive" {
+			return errors.New("an extraction filter is only valid for archive packages")
+		}
+		extract, err := pkg.Extract.Compile()
+		if err != nil {
+			return fmt.Errorf("package extraction filter: %w", err)
+		}
+		for id, file := range pkg.Files {
+			if !extract(file.Path) {
+				return fmt.Errorf("package file \"%s\": the file is excluded by the package's extraction filter", id)
+			}
+		}
+	}
+
+	// Validate the extraction quota.
+	if !pkg.Quota.IsZero() {
+		if pkg.Type != "archive" {
+			return errors.New("an extraction quota is only valid for archive packages")
+		}
+		if err := pkg.Quota.Validate(); err != nil {
+			return fmt.Errorf("package extraction quota: %w", err)
+		}
+	}
+
 	// Validate package commands.
 	for id, command := range pkg.Commands {
+		if err := command.Validate(); err != nil {
+			return fmt.Errorf("package command \"%s\": %w", id, err)
+		}
 		if command.Executable != "" {
 			if pkg.Type != "archive" {
 				return fmt.Errorf("package command \"%s\": an executable file ID is only valid for archive packages", id)
@@ -138,22 +230,51 @@ func (pkg Package) Validate() error {
 				return fmt.Errorf("package command \"%s\": the executable file ID refers to package file \"%s\", which is not defined in the package file set", id, command.Executable)
 			}
 		}
+		for _, transform := range command.MSI.Transforms {
+			if transform.IsEmbedded() {
+				continue
+			}
+			if pkg.Type != "archive" {
+				return fmt.Errorf("package command \"%s\": a transform file ID is only valid for archive packages", id)
+			}
+			if _, ok := pkg.Files[PackageFileID(transform)]; !ok {
+				return fmt.Errorf("package command \"%s\": the transform file ID refers to package file \"%s\", which is not defined in the package file set", id, transform)
+			}
+		}
 	}
 	return nil
 }
 
 // Package source types.
+//
+// Bundle sources identify package files that are read from an offline
+// bundle. They are reported in events, but they cannot be declared by a
+// deployment.
 const (
-	PackageSourceHTTP PackageSourceType = "http"
+	PackageSourceHTTP   PackageSourceType = "http"
+	PackageSourceBundle PackageSourceType = "bundle"
 )
 
 // PackageSourceType declares the type of source for a package.
 type PackageSourceType string
 
 // PackageSource defines a potential source for retrieval of a package.
+//
+// HTTP sources can include additional request headers and an authorization
+// token, which allow packages to be retrieved from authenticated artifact
+// repositories. The authorization is not forwarded when a server redirects
+// the request to a different host.
+//
+// HTTP sources on internal servers can instead use integrated Windows
+// authentication, in which case the credentials of the security context
+// that LeafBridge is running in are presented to the server. When
+// LeafBridge runs as a service, this is the computer account.
 type PackageSource struct {
-	Type PackageSourceType
-	URL  string
+	Type           PackageSourceType   `json:"type"`
+	URL            string              `json:"url"`
+	Headers        HeaderMap           `json:"headers,omitzero"`
+	Authorization  SourceAuthorization `json:"authorization,omitzero"`
+	IntegratedAuth IntegratedAuth      `json:"integrated-auth,omitempty"`
 }
 
 // Validate returns a non-nil error if the package source is invalid.
@@ -166,9 +287,131 @@ func (source PackageSource) Validate() error {
 		return fmt.Errorf("the package source type \"%s\" is not recognized", source.Type)
 	}
 
+	if err := source.Headers.Validate(); err != nil {
+		return fmt.Errorf("headers: %w", err)
+	}
+
+	if err := source.IntegratedAuth.Validate(); err != nil {
+		return err
+	}
+
+	if !source.Authorization.IsZero() {
+		if source.IntegratedAuth != IntegratedAuthNone {
+			return errors.New("authorization settings cannot be combined with integrated authentication")
+		}
+		if err := source.Authorization.Validate(); err != nil {
+			return fmt.Errorf("authorization: %w", err)
+		}
+		for name := range source.Headers {
+			if strings.EqualFold(name, "Authorization") {
+				return errors.New("an authorization header cannot be provided alongside authorization settings")
+			}
+		}
+	}
+
 	return nil
 }
 
+// Redacted returns a copy of the package source with its header values and
+// authorization token removed. It is suitable for inclusion in events and
+// logs.
+func (source PackageSource) Redacted() PackageSource {
+	if len(source.Headers) > 0 {
+		headers := make(HeaderMap, len(source.Headers))
+		for name := range source.Headers {
+			headers[name] = "[redacted]"
+		}
+		source.Headers = headers
+	}
+	source.Authorization.Token = Secret{}
+	return source
+}
+
+// IntegratedAuth identifies a security package that is used to
+// authenticate with an HTTP source.
+type IntegratedAuth string
+
+// Integrated authentication options.
+const (
+	IntegratedAuthNone      IntegratedAuth = ""
+	IntegratedAuthNegotiate IntegratedAuth = "negotiate"
+	IntegratedAuthKerberos  IntegratedAuth = "kerberos"
+	IntegratedAuthNTLM      IntegratedAuth = "ntlm"
+)
+
+// Validate returns a non-nil error if the integrated authentication option
+// is not recognized.
+func (auth IntegratedAuth) Validate() error {
+	switch auth {
+	case IntegratedAuthNone, IntegratedAuthNegotiate, IntegratedAuthKerberos, IntegratedAuthNTLM:
+		return nil
+	default:
+		return fmt.Errorf("the integrated authentication option \"%s\" is not recognized", auth)
+	}
+}
+
+// HeaderMap holds a set of HTTP request header values, mapped by their
+// names.
+type HeaderMap map[string]string
+
+// Validate returns a non-nil error if any of the headers are invalid, or if
+// they would interfere with headers that are managed by LeafBridge.
+func (m HeaderMap) Validate() error {
+	for name, value := range m {
+		if name == "" {
+			return errors.New("a header name is missing")
+		}
+		if strings.ContainsFunc(name, func(r rune) bool {
+			return r <= ' ' || r >= 0x7f || r == ':'
+		}) {
+			return fmt.Errorf("the header name \"%s\" contains an invalid character", name)
+		}
+		if strings.ContainsAny(value, "\r\n\x00") {
+			return fmt.Errorf("the value of the \"%s\" header contains an invalid character", name)
+		}
+		switch strings.ToLower(name) {
+		case "range", "if-range", "if-none-match", "if-modified-since", "host", "content-length":
+			return fmt.Errorf("the \"%s\" header is managed by LeafBridge and cannot be provided", name)
+		}
+	}
+	return nil
+}
+
+// SourceAuthorization describes the credentials that are presented to an
+// HTTP source in its Authorization header. If a scheme is not provided,
+// the token is presented as a bearer token.
+type SourceAuthorization struct {
+	Scheme string `json:"scheme,omitempty"`
+	Token  Secret `json:"token,omitzero"`
+}
+
+// IsZero returns true if no authorization has been specified.
+func (auth SourceAuthorization) IsZero() bool {
+	return auth.Scheme == "" && auth.Token.IsZero()
+}
+
+// Validate returns a non-nil error if the authorization is invalid.
+func (auth SourceAuthorization) Validate() error {
+	if strings.ContainsFunc(auth.Scheme, func(r rune) bool {
+		return r <= ' ' || r >= 0x7f
+	}) {
+		return fmt.Errorf("the authorization scheme \"%s\" contains an invalid character", auth.Scheme)
+	}
+	if err := auth.Token.Validate(); err != nil {
+		return fmt.Errorf("token: %w", err)
+	}
+	return nil
+}
+
+// SchemeOrDefault returns the authorization scheme, or "Bearer" if one has
+// not been specified.
+func (auth SourceAuthorization) SchemeOrDefault() string {
+	if auth.Scheme == "" {
+		return "Bearer"
+	}
+	return auth.Scheme
+}
+
 // PackageFileMap holds a set of package files mapped by their identifiers.
 //
 // It is used by archive packages to verify the presence of important files
@@ -183,4 +426,11 @@ type PackageFileID string
 type PackageFile struct {
 	Path       string         `json:"path"`
 	Attributes FileAttributes `json:"attributes,omitzero"`
+
+	// Signature describes Authenticode signature requirements for the
+	// file. When a command runs the file or applies it as a transform, its
+	// signature is verified immediately before the command is run, after
+	// the archive has been extracted. The file remains locked until the
+	// command has finished.
+	Signature SignatureRequirement `json:"signature,omitzero"`
 }
@@ -0,0 +1,173 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PowerPlan identifies a Windows power plan. It is either the name of one
+// of the plans built into Windows, such as "balanced", or the GUID of a
+// power scheme.
+type PowerPlan string
+
+// Power plans built into Windows.
+const (
+	PowerPlanPowerSaver          PowerPlan = "power-saver"
+	PowerPlanBalanced            PowerPlan = "balanced"
+	PowerPlanHighPerformance     PowerPlan = "high-performance"
+	PowerPlanUltimatePerformance PowerPlan = "ultimate-performance"
+)
+
+// powerPlanGUIDs map the power plans built into Windows to the GUIDs of
+// their power schemes.
+var powerPlanGUIDs = map[PowerPlan]string{
+	PowerPlanPowerSaver:          "a1841308-3541-4fab-bc81-f71556f20b4a",
+	PowerPlanBalanced:            "381b4222-f694-41f0-9685-ff5bb260df2e",
+	PowerPlanHighPerformance:     "8c5e7fda-e8bf-4a96-9a85-a6e23a8c635c",
+	PowerPlanUltimatePerformance: "e9a42b02-d5df-448d-aa00-03f14749eb61",
+}
+
+// Validate returns a non-nil error if the plan is neither a built-in plan
+// nor a GUID.
+func (plan PowerPlan) Validate() error {
+	if plan == "" {
+		return errors.New("a power plan was not provided")
+	}
+	if _, builtIn := powerPlanGUIDs[plan]; builtIn {
+		return nil
+	}
+	if !isGUID(string(plan)) {
+		return fmt.Errorf("the power plan \"%s\" is neither a recognized plan nor a GUID", plan)
+	}
+	return nil
+}
+
+// IsBuiltIn returns true if the plan is one of the plans built into
+// Windows.
+func (plan PowerPlan) IsBuiltIn() bool {
+	_, builtIn := powerPlanGUIDs[plan]
+	return builtIn
+}
+
+// GUID returns the GUID of the plan's power scheme in lower case, without
+// braces.
+func (plan PowerPlan) GUID() string {
+	if guid, builtIn := powerPlanGUIDs[plan]; builtIn {
+		return guid
+	}
+	return strings.ToLower(strings.Trim(string(plan), "{}"))
+}
+
+// isGUID returns true if s is a GUID in its canonical form, optionally
+// enclosed in braces.
+func isGUID(s string) bool {
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	if len(s) != 36 {
+		return false
+	}
+	for i := range len(s) {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9') && !('a' <= c && c <= 'f') && !('A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// PowerSettingName identifies a sleep or display setting of the active
+// power plan.
+type PowerSettingName string
+
+// Recognized power settings.
+const (
+	PowerSettingMonitorTimeout   PowerSettingName = "monitor-timeout"
+	PowerSettingDiskTimeout      PowerSettingName = "disk-timeout"
+	PowerSettingStandbyTimeout   PowerSettingName = "standby-timeout"
+	PowerSettingHibernateTimeout PowerSettingName = "hibernate-timeout"
+	PowerSettingHibernation      PowerSettingName = "hibernation"
+)
+
+// PowerSource identifies the power source that a power setting applies
+// to.
+type PowerSource string
+
+// Recognized power sources. When a power source is not specified, the
+// setting applies to both.
+const (
+	PowerSourceBoth    PowerSource = ""
+	PowerSourceAC      PowerSource = "ac"
+	PowerSourceBattery PowerSource = "dc"
+)
+
+// PowerSetting describes a change to the sleep and display settings of the
+// local system.
+//
+// The timeout settings are applied to the active power plan, and their
+// Minutes determine how long the system must be idle before the display
+// turns off, the disks spin down, or the system sleeps or hibernates. A
+// value of zero means never.
+//
+// The hibernation setting enables or disables hibernation for the whole
+// system, according to Enabled. Disabling hibernation also removes the
+// hibernation file and disables fast startup.
+type PowerSetting struct {
+	Name    PowerSettingName `json:"setting"`
+	Source  PowerSource      `json:"source,omitempty"`
+	Minutes int              `json:"minutes,omitempty"`
+	Enabled bool             `json:"enabled,omitempty"`
+}
+
+// Validate returns an error if the power setting is not valid.
+func (setting PowerSetting) Validate() error {
+	switch setting.Name {
+	case PowerSettingMonitorTimeout, PowerSettingDiskTimeout, PowerSettingStandbyTimeout, PowerSettingHibernateTimeout:
+		switch setting.Source {
+		case PowerSourceBoth, PowerSourceAC, PowerSourceBattery:
+		default:
+			return fmt.Errorf("the power source \"%s\" is not recognized", setting.Source)
+		}
+		if setting.Minutes < 0 {
+			return fmt.Errorf("the %s cannot be negative", setting.Name)
+		}
+		if setting.Enabled {
+			return fmt.Errorf("the %s setting does not accept enabled", setting.Name)
+		}
+	case PowerSettingHibernation:
+		if setting.Source != PowerSourceBoth {
+			return errors.New("hibernation cannot be configured for a single power source")
+		}
+		if setting.Minutes != 0 {
+			return errors.New("the hibernation setting does not accept minutes")
+		}
+	case "":
+		return errors.New("a power setting was not provided")
+	default:
+		return fmt.Errorf("the power setting \"%s\" is not recognized", setting.Name)
+	}
+	return nil
+}
+
+// String returns a description of the setting, such as
+// "standby-timeout-ac 30".
+func (setting PowerSetting) String() string {
+	if setting.Name == PowerSettingHibernation {
+		if setting.Enabled {
+			return "hibernation on"
+		}
+		return "hibernation off"
+	}
+	if setting.Source == PowerSourceBoth {
+		return fmt.Sprintf("%s %d", setting.Name, setting.Minutes)
+	}
+	return fmt.Sprintf("%s-%s %d", setting.Name, setting.Source, setting.Minutes)
+}
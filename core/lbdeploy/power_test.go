@@ -0,0 +1,75 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestPowerPlan(t *testing.T) {
+	tests := []struct {
+		Plan  lbdeploy.PowerPlan
+		GUID  string
+		Valid bool
+	}{
+		{Plan: "balanced", GUID: "381b4222-f694-41f0-9685-ff5bb260df2e", Valid: true},
+		{Plan: "ultimate-performance", GUID: "e9a42b02-d5df-448d-aa00-03f14749eb61", Valid: true},
+		{Plan: "{8C5E7FDA-E8BF-4A96-9A85-A6E23A8C635C}", GUID: "8c5e7fda-e8bf-4a96-9a85-a6e23a8c635c", Valid: true},
+		{Plan: "5f2b6d3e-1c4a-4b7e-9a0d-3e8f6c2b1a90", GUID: "5f2b6d3e-1c4a-4b7e-9a0d-3e8f6c2b1a90", Valid: true},
+		{Plan: "turbo"},
+		{Plan: "5f2b6d3e-1c4a-4b7e-9a0d-3e8f6c2b1a9"},
+		{Plan: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.Plan), func(t *testing.T) {
+			err := test.Plan.Validate()
+			if test.Valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !test.Valid {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if guid := test.Plan.GUID(); guid != test.GUID {
+				t.Errorf("GUID: got \"%s\", want \"%s\"", guid, test.GUID)
+			}
+		})
+	}
+}
+
+func TestPowerSetting(t *testing.T) {
+	tests := []struct {
+		Setting lbdeploy.PowerSetting
+		String  string
+		Valid   bool
+	}{
+		{Setting: lbdeploy.PowerSetting{Name: "standby-timeout", Source: "ac", Minutes: 30}, String: "standby-timeout-ac 30", Valid: true},
+		{Setting: lbdeploy.PowerSetting{Name: "monitor-timeout"}, String: "monitor-timeout 0", Valid: true},
+		{Setting: lbdeploy.PowerSetting{Name: "hibernation"}, String: "hibernation off", Valid: true},
+		{Setting: lbdeploy.PowerSetting{Name: "hibernation", Source: "dc"}},
+		{Setting: lbdeploy.PowerSetting{Name: "disk-timeout", Minutes: -1}},
+		{Setting: lbdeploy.PowerSetting{Name: "disk-timeout", Source: "usb"}},
+		{Setting: lbdeploy.PowerSetting{Name: "lid-action"}},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.Setting.Name), func(t *testing.T) {
+			err := test.Setting.Validate()
+			if test.Valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !test.Valid {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if s := test.Setting.String(); s != test.String {
+				t.Errorf("String: got \"%s\", want \"%s\"", s, test.String)
+			}
+		})
+	}
+}
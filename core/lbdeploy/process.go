@@ -1,5 +1,11 @@
 package lbdeploy
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
 // ProcessResourceMap holds a set of process resources mapped by their
 // identifiers.
 type ProcessResourceMap map[ProcessResourceID]ProcessResource
@@ -21,8 +27,11 @@ type ProcessAttributeID string
 
 // Process Attributes.
 const (
-	ProcessName ProcessAttributeID = "name"
-	//ProcessPath ProcessAttributeID = "path"
+	ProcessName        ProcessAttributeID = "name"
+	ProcessPath        ProcessAttributeID = "path"
+	ProcessCommandLine ProcessAttributeID = "command-line"
+	ProcessUser        ProcessAttributeID = "user"
+	ProcessSession     ProcessAttributeID = "session"
 )
 
 // MatchType defines the type of match to use for a field.
@@ -30,11 +39,12 @@ type MatchType string
 
 // Match Types.
 const (
-	MatchEquals   MatchType = "equals"
-	MatchContains MatchType = "contains"
-	//MatchStartWith         MatchType = "starts-with"
-	//MatchEndsWith          MatchType = "ends-with"
-	//MatchRegularExpression MatchType = "expression"
+	MatchEquals            MatchType = "equals"
+	MatchContains          MatchType = "contains"
+	MatchStartsWith        MatchType = "starts-with"
+	MatchEndsWith          MatchType = "ends-with"
+	MatchGlob              MatchType = "glob"
+	MatchRegularExpression MatchType = "expression"
 )
 
 // ProcessMatch holds information used to identify processes running on a
@@ -47,3 +57,153 @@ type ProcessMatch struct {
 	Any       []ProcessMatch     `json:"any,omitzero"`
 	All       []ProcessMatch     `json:"all,omitzero"`
 }
+
+// ProcessAttributes provides the attributes of a running process.
+//
+// Implementations are expected to collect attributes on demand, because
+// some of them are expensive to retrieve.
+type ProcessAttributes interface {
+	// ProcessAttribute returns the value of the given attribute.
+	//
+	// Users are formatted as DOMAIN\account on Windows and as a user name
+	// elsewhere. Sessions are formatted as a decimal session ID.
+	ProcessAttribute(attr ProcessAttributeID) (string, error)
+}
+
+// ProcessFilter reports whether a process satisfies a set of compiled
+// process match criteria.
+type ProcessFilter func(proc ProcessAttributes) (bool, error)
+
+// Compile prepares a process filter for the match criteria.
+//
+// If fold is true, values are compared without regard to case. Regular
+// expressions are compiled with the case-insensitive flag in that case.
+//
+// Glob patterns support * and ?, neither of which match a path separator,
+// and **, which matches any sequence of characters.
+func (match ProcessMatch) Compile(fold bool) (ProcessFilter, error) {
+	if len(match.Any) > 0 {
+		var filters []ProcessFilter
+		for i, submatch := range match.Any {
+			subfilter, err := submatch.Compile(fold)
+			if err != nil {
+				return nil, fmt.Errorf("Match Any [%d]: %w", i, err)
+			}
+			filters = append(filters, subfilter)
+		}
+		return func(proc ProcessAttributes) (bool, error) {
+			for _, filter := range filters {
+				if matched, err := filter(proc); err != nil || matched {
+					return matched, err
+				}
+			}
+			return false, nil
+		}, nil
+	}
+
+	if len(match.All) > 0 {
+		var filters []ProcessFilter
+		for i, submatch := range match.All {
+			subfilter, err := submatch.Compile(fold)
+			if err != nil {
+				return nil, fmt.Errorf("Match All [%d]: %w", i, err)
+			}
+			filters = append(filters, subfilter)
+		}
+		return func(proc ProcessAttributes) (bool, error) {
+			for _, filter := range filters {
+				if matched, err := filter(proc); err != nil || !matched {
+					return false, err
+				}
+			}
+			return true, nil
+		}, nil
+	}
+
+	switch match.Attribute {
+	case ProcessName, ProcessPath, ProcessCommandLine, ProcessUser, ProcessSession:
+	case "":
+		return nil, fmt.Errorf("a process attribute was not provided")
+	default:
+		return nil, fmt.Errorf("the process attribute \"%s\" is not recognized", match.Attribute)
+	}
+
+	compare, err := compileMatchValue(match.Type, match.Value, fold)
+	if err != nil {
+		return nil, err
+	}
+
+	attr := match.Attribute
+	return func(proc ProcessAttributes) (bool, error) {
+		value, err := proc.ProcessAttribute(attr)
+		if err != nil {
+			return false, fmt.Errorf("failed to retrieve the process %s: %w", attr, err)
+		}
+		return compare(value), nil
+	}, nil
+}
+
+// compileMatchValue returns a function that compares values against
+// the expected value using the given match type.
+func compileMatchValue(t MatchType, expected string, fold bool) (func(string) bool, error) {
+	normalize := func(s string) string { return s }
+	if fold {
+		normalize = strings.ToLower
+	}
+	expected = normalize(expected)
+
+	switch t {
+	case MatchEquals:
+		return func(s string) bool { return normalize(s) == expected }, nil
+	case MatchContains:
+		return func(s string) bool { return strings.Contains(normalize(s), expected) }, nil
+	case MatchStartsWith:
+		return func(s string) bool { return strings.HasPrefix(normalize(s), expected) }, nil
+	case MatchEndsWith:
+		return func(s string) bool { return strings.HasSuffix(normalize(s), expected) }, nil
+	case MatchGlob:
+		re, err := regexp.Compile(globExpression(expected))
+		if err != nil {
+			return nil, fmt.Errorf("the glob pattern \"%s\" is invalid: %w", expected, err)
+		}
+		return func(s string) bool { return re.MatchString(normalize(s)) }, nil
+	case MatchRegularExpression:
+		if fold {
+			expected = "(?i)" + expected
+		}
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return nil, fmt.Errorf("the regular expression is invalid: %w", err)
+		}
+		return re.MatchString, nil
+	case "":
+		return nil, fmt.Errorf("a match type was not provided")
+	default:
+		return nil, fmt.Errorf("the match type \"%s\" is not recognized", t)
+	}
+}
+
+// globExpression converts a glob pattern to an anchored regular
+// expression. Both forward slashes and backslashes are treated as path
+// separators.
+func globExpression(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString(`[^/\\]*`)
+			}
+		case '?':
+			b.WriteString(`[^/\\]`)
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
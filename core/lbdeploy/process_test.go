@@ -0,0 +1,56 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+type testProcess map[lbdeploy.ProcessAttributeID]string
+
+func (p testProcess) ProcessAttribute(attr lbdeploy.ProcessAttributeID) (string, error) {
+	return p[attr], nil
+}
+
+var javaProcess = testProcess{
+	lbdeploy.ProcessName:        "java.exe",
+	lbdeploy.ProcessPath:        `C:\Program Files\Vendor\jre\bin\java.exe`,
+	lbdeploy.ProcessCommandLine: `"C:\Program Files\Vendor\jre\bin\java.exe" -jar server.jar --port 8080`,
+	lbdeploy.ProcessUser:        `CONTOSO\svc-vendor`,
+	lbdeploy.ProcessSession:     "0",
+}
+
+var processMatchFixtures = []struct {
+	Name    string
+	Match   lbdeploy.ProcessMatch
+	Matched bool
+}{
+	{Name: "equals-fold", Match: lbdeploy.ProcessMatch{Attribute: lbdeploy.ProcessName, Type: lbdeploy.MatchEquals, Value: "JAVA.EXE"}, Matched: true},
+	{Name: "glob", Match: lbdeploy.ProcessMatch{Attribute: lbdeploy.ProcessPath, Type: lbdeploy.MatchGlob, Value: `C:\Program Files\*\java.exe`}, Matched: false},
+	{Name: "glob-recursive", Match: lbdeploy.ProcessMatch{Attribute: lbdeploy.ProcessPath, Type: lbdeploy.MatchGlob, Value: `C:\Program Files\**\java.exe`}, Matched: true},
+	{Name: "glob-single", Match: lbdeploy.ProcessMatch{Attribute: lbdeploy.ProcessPath, Type: lbdeploy.MatchGlob, Value: `C:\Program Files\Vendor\jre\bin\java.???`}, Matched: true},
+	{Name: "expression", Match: lbdeploy.ProcessMatch{Attribute: lbdeploy.ProcessCommandLine, Type: lbdeploy.MatchRegularExpression, Value: `-jar\s+server\.jar`}, Matched: true},
+	{Name: "ends-with", Match: lbdeploy.ProcessMatch{Attribute: lbdeploy.ProcessUser, Type: lbdeploy.MatchEndsWith, Value: `\svc-vendor`}, Matched: true},
+	{Name: "all", Match: lbdeploy.ProcessMatch{All: []lbdeploy.ProcessMatch{
+		{Attribute: lbdeploy.ProcessName, Type: lbdeploy.MatchEquals, Value: "java.exe"},
+		{Attribute: lbdeploy.ProcessSession, Type: lbdeploy.MatchEquals, Value: "1"},
+	}}, Matched: false},
+}
+
+func TestProcessMatch(t *testing.T) {
+	for _, fixture := range processMatchFixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			filter, err := fixture.Match.Compile(true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			matched, err := filter(javaProcess)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if matched != fixture.Matched {
+				t.Errorf("expected %t, got %t", fixture.Matched, matched)
+			}
+		})
+	}
+}
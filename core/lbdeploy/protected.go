@@ -0,0 +1,39 @@
+package lbdeploy
+
+import "fmt"
+
+// ProtectedFileBehavior identifies a response to take when the target of a
+// file action is located in a protected folder, such as the Windows system
+// folder.
+type ProtectedFileBehavior string
+
+// Behavior options when the target of a file action is protected.
+//
+// When the take-ownership option is used, the backup, restore and
+// take-ownership privileges are enabled for the duration of the action,
+// for the thread that performs it only. If the target file exists and
+// cannot otherwise be modified, ownership of the file is given to the
+// local Administrators group, which is then granted full control of it.
+// When the action is finished, the previous owner and access control list
+// are restored. Each of these changes is recorded as a security event.
+const (
+	ProtectedFileUnspecified   ProtectedFileBehavior = ""
+	ProtectedFileFail          ProtectedFileBehavior = "fail"
+	ProtectedFileTakeOwnership ProtectedFileBehavior = "take-ownership"
+)
+
+// Validate returns a non-nil error if the behavior is not recognized.
+func (b ProtectedFileBehavior) Validate() error {
+	switch b {
+	case ProtectedFileUnspecified, ProtectedFileFail, ProtectedFileTakeOwnership:
+		return nil
+	default:
+		return fmt.Errorf("the \"%s\" on-protected behavior is not recognized", b)
+	}
+}
+
+// Allowed returns true if the behavior permits changes to files in
+// protected folders.
+func (b ProtectedFileBehavior) Allowed() bool {
+	return b == ProtectedFileTakeOwnership
+}
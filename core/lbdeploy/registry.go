@@ -37,6 +37,10 @@ type RegistryKeyResource struct {
 	// Both forward slashes and backslashes will be interpreted as path
 	// separators.
 	Path string `json:"path,omitempty"`
+
+	// Desired is the state that the key is expected to be in when the
+	// compliance of the deployment is evaluated.
+	Desired DesiredState `json:"desired,omitempty"`
 }
 
 // RegistryKeyRef is a resolved reference to a registry key on the local
@@ -92,6 +96,15 @@ type RegistryValueResource struct {
 
 	// Type is the type of data the value holds.
 	Type lbvalue.Kind `json:"type"`
+
+	// Desired is the state that the value is expected to be in when the
+	// compliance of the deployment is evaluated.
+	Desired DesiredState `json:"desired,omitempty"`
+
+	// DesiredData is the data that the value is expected to hold when the
+	// compliance of the deployment is evaluated. It is only used when the
+	// value is expected to be present.
+	DesiredData lbvalue.Value `json:"desired-data,omitzero"`
 }
 
 // RegistryValueRef is a resolved reference to a registry key on the local
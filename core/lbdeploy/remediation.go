@@ -0,0 +1,91 @@
+package lbdeploy
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
+// Remediation defaults.
+const (
+	DefaultRemediationThreshold   = 1
+	DefaultRemediationCooldown    = time.Hour
+	DefaultRemediationMaxAttempts = 3
+)
+
+// FlowRemediation determines whether a flow is invoked automatically when
+// the compliance of its deployment is evaluated and drift is detected.
+//
+// To prevent remediation storms, a flow is only remediated once drift has
+// been detected in Threshold consecutive evaluations, no more than once per
+// Cooldown, and no more than MaxAttempts times in a row without the
+// deployment returning to compliance. Once the deployment is found to be in
+// compliance, the count of attempts is reset.
+//
+// If a limit is not provided, its default value is used.
+type FlowRemediation struct {
+	OnDrift     bool              `json:"on-drift,omitempty"`
+	Threshold   int               `json:"threshold,omitempty"`
+	Cooldown    datatype.Duration `json:"cooldown,omitzero"`
+	MaxAttempts int               `json:"max-attempts,omitempty"`
+}
+
+// EffectiveThreshold returns the number of consecutive evaluations with
+// drift that are required before the flow is remediated.
+func (r FlowRemediation) EffectiveThreshold() int {
+	if r.Threshold <= 0 {
+		return DefaultRemediationThreshold
+	}
+	return r.Threshold
+}
+
+// EffectiveCooldown returns the minimum amount of time between remediation
+// attempts.
+func (r FlowRemediation) EffectiveCooldown() time.Duration {
+	if r.Cooldown <= 0 {
+		return DefaultRemediationCooldown
+	}
+	return time.Duration(r.Cooldown)
+}
+
+// EffectiveMaxAttempts returns the maximum number of consecutive
+// remediation attempts.
+func (r FlowRemediation) EffectiveMaxAttempts() int {
+	if r.MaxAttempts <= 0 {
+		return DefaultRemediationMaxAttempts
+	}
+	return r.MaxAttempts
+}
+
+// Validate returns a non-nil error if the remediation contains invalid
+// configuration.
+func (r FlowRemediation) Validate() error {
+	if r.Threshold < 0 {
+		return errors.New("a negative threshold was provided")
+	}
+	if r.Cooldown < 0 {
+		return errors.New("a negative cooldown was provided")
+	}
+	if r.MaxAttempts < 0 {
+		return errors.New("a negative number of attempts was provided")
+	}
+	if !r.OnDrift && (r.Threshold != 0 || r.Cooldown != 0 || r.MaxAttempts != 0) {
+		return errors.New("remediation limits were provided for a flow that is not remediated on drift")
+	}
+	return nil
+}
+
+// RemediationFlows returns the identifiers of the flows in the deployment
+// that are remediated on drift, in sorted order.
+func (dep Deployment) RemediationFlows() []FlowID {
+	var flows []FlowID
+	for _, id := range slices.Sorted(maps.Keys(dep.Flows)) {
+		if dep.Flows[id].Remediation.OnDrift {
+			flows = append(flows, id)
+		}
+	}
+	return flows
+}
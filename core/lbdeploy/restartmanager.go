@@ -0,0 +1,38 @@
+package lbdeploy
+
+import "errors"
+
+// RestartManager configures the use of the Windows Restart Manager around
+// a command, typically one that installs or uninstalls software.
+//
+// Before the command runs, the applications and services that are using
+// the given files, or any of the files within the given directories, are
+// identified and recorded. If Shutdown is true, they are shut down before
+// the command runs. If Restart is also true, they are restarted after the
+// command has finished.
+type RestartManager struct {
+	Files       []FileResourceID      `json:"files,omitzero"`
+	Directories []DirectoryResourceID `json:"directories,omitzero"`
+	Shutdown    bool                  `json:"shutdown,omitempty"`
+	Restart     bool                  `json:"restart,omitempty"`
+}
+
+// IsZero returns true if the restart manager configuration is empty.
+func (rm RestartManager) IsZero() bool {
+	return len(rm.Files) == 0 && len(rm.Directories) == 0 && !rm.Shutdown && !rm.Restart
+}
+
+// Validate returns a non-nil error if the restart manager configuration is
+// invalid.
+func (rm RestartManager) Validate() error {
+	if rm.IsZero() {
+		return nil
+	}
+	if len(rm.Files) == 0 && len(rm.Directories) == 0 {
+		return errors.New("no files or directories were provided")
+	}
+	if rm.Restart && !rm.Shutdown {
+		return errors.New("restart was requested without shutdown")
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestRestartManagerValidate(t *testing.T) {
+	fixtures := []struct {
+		Name  string
+		RM    lbdeploy.RestartManager
+		Valid bool
+	}{
+		{Name: "zero", Valid: true},
+		{Name: "files", RM: lbdeploy.RestartManager{Files: []lbdeploy.FileResourceID{"app-exe"}}, Valid: true},
+		{Name: "directories-shutdown-restart", RM: lbdeploy.RestartManager{Directories: []lbdeploy.DirectoryResourceID{"app-dir"}, Shutdown: true, Restart: true}, Valid: true},
+		{Name: "shutdown-without-resources", RM: lbdeploy.RestartManager{Shutdown: true}},
+		{Name: "restart-without-shutdown", RM: lbdeploy.RestartManager{Files: []lbdeploy.FileResourceID{"app-exe"}, Restart: true}},
+	}
+	for _, fixture := range fixtures {
+		err := fixture.RM.Validate()
+		if fixture.Valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", fixture.Name, err)
+		} else if !fixture.Valid && err == nil {
+			t.Errorf("%s: expected an error", fixture.Name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package lbdeploy
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
+// Default retry policy values.
+const (
+	DefaultRetryAttempts     = 3
+	DefaultRetryInitialDelay = datatype.Duration(time.Second)
+	DefaultRetryMaxDelay     = datatype.Duration(time.Second * 30)
+	DefaultRetryMultiplier   = 2
+)
+
+// RetryPolicy describes how transient failures are retried.
+//
+// Attempts is the total number of attempts that will be made, including
+// the first one. The delay before each retry starts at InitialDelay and is
+// multiplied by Multiplier after each retry, up to MaxDelay. Random jitter
+// is applied to each delay so that many clients recovering from the same
+// outage don't retry in lockstep.
+//
+// Zero values are replaced by their defaults.
+type RetryPolicy struct {
+	Attempts     int               `json:"attempts,omitempty"`
+	InitialDelay datatype.Duration `json:"initial-delay,omitzero"`
+	MaxDelay     datatype.Duration `json:"max-delay,omitzero"`
+	Multiplier   float64           `json:"multiplier,omitempty"`
+}
+
+// WithDefaults returns a copy of the retry policy with default values
+// applied to any fields that are unspecified.
+func (policy RetryPolicy) WithDefaults() RetryPolicy {
+	if policy.Attempts == 0 {
+		policy.Attempts = DefaultRetryAttempts
+	}
+	if policy.InitialDelay == 0 {
+		policy.InitialDelay = DefaultRetryInitialDelay
+	}
+	if policy.MaxDelay == 0 {
+		policy.MaxDelay = max(DefaultRetryMaxDelay, policy.InitialDelay)
+	}
+	if policy.Multiplier == 0 {
+		policy.Multiplier = DefaultRetryMultiplier
+	}
+	return policy
+}
+
+// Validate returns a non-nil error if the retry policy is invalid.
+func (policy RetryPolicy) Validate() error {
+	switch {
+	case policy.Attempts < 0:
+		return errors.New("a negative number of attempts was provided")
+	case policy.InitialDelay < 0:
+		return errors.New("a negative initial delay was provided")
+	case policy.MaxDelay < 0:
+		return errors.New("a negative maximum delay was provided")
+	case policy.MaxDelay > 0 && policy.MaxDelay < policy.InitialDelay:
+		return errors.New("the maximum delay is less than the initial delay")
+	case policy.Multiplier != 0 && policy.Multiplier < 1:
+		return errors.New("the multiplier must be at least 1")
+	}
+	return nil
+}
+
+// Delay returns the amount of time to wait before the given retry, which
+// is one-based. Jitter is applied, so that the returned delay falls
+// between half of the computed backoff and the full backoff.
+func (policy RetryPolicy) Delay(retry int) time.Duration {
+	policy = policy.WithDefaults()
+
+	backoff := float64(policy.InitialDelay)
+	for i := 1; i < retry && backoff < float64(policy.MaxDelay); i++ {
+		backoff *= policy.Multiplier
+	}
+	backoff = min(backoff, float64(policy.MaxDelay))
+
+	half := int64(backoff / 2)
+	if half <= 0 {
+		return time.Duration(backoff)
+	}
+	return time.Duration(half + rand.Int64N(half+1))
+}
+
+// ActionRetry describes how an action within a flow is retried when it
+// fails. Actions are not retried unless a retry policy is provided.
+//
+// The embedded retry policy determines the number of attempts and the
+// delay between them. A multiplier of 1 retries after a constant delay.
+//
+// If ExitCodes are provided, only failures of commands and scripts that
+// returned one of those exit codes are retried. Otherwise every failure is
+// retried.
+type ActionRetry struct {
+	RetryPolicy
+	ExitCodes []ExitCode `json:"exit-codes,omitzero"`
+}
+
+// IsZero returns true if the retry policy has not been provided.
+func (retry ActionRetry) IsZero() bool {
+	return retry.RetryPolicy == RetryPolicy{} && len(retry.ExitCodes) == 0
+}
+
+// Retryable returns true if a failure with the given exit code should be
+// retried. If the failure did not produce an exit code, hasCode is false.
+func (retry ActionRetry) Retryable(code ExitCode, hasCode bool) bool {
+	if len(retry.ExitCodes) == 0 {
+		return true
+	}
+	return hasCode && slices.Contains(retry.ExitCodes, code)
+}
@@ -0,0 +1,104 @@
+package lbdeploy_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestActionRetry(t *testing.T) {
+	var action lbdeploy.Action
+	if err := json.Unmarshal([]byte(`{"action":"invoke-command","retry":{"attempts":5,"initial-delay":"2s","multiplier":1,"exit-codes":[1618]}}`), &action); err != nil {
+		t.Fatal(err)
+	}
+
+	retry := action.Retry
+	if retry.IsZero() {
+		t.Fatal("the retry policy was not decoded")
+	}
+	if retry.Attempts != 5 || time.Duration(retry.InitialDelay) != 2*time.Second {
+		t.Errorf("unexpected retry policy: %+v", retry)
+	}
+	if err := retry.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures := []struct {
+		Code    lbdeploy.ExitCode
+		HasCode bool
+		Want    bool
+	}{
+		{Code: 1618, HasCode: true, Want: true},
+		{Code: 1603, HasCode: true, Want: false},
+		{Want: false},
+	}
+	for _, fixture := range fixtures {
+		if got := retry.Retryable(fixture.Code, fixture.HasCode); got != fixture.Want {
+			t.Errorf("exit code %d (%t): got %t, want %t", fixture.Code, fixture.HasCode, got, fixture.Want)
+		}
+	}
+
+	if !(lbdeploy.ActionRetry{}).Retryable(0, false) {
+		t.Error("a retry policy without exit codes should retry every failure")
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	fixtures := []struct {
+		Name   string
+		Policy lbdeploy.RetryPolicy
+		Valid  bool
+	}{
+		{Name: "zero", Valid: true},
+		{Name: "full", Policy: lbdeploy.RetryPolicy{Attempts: 5, InitialDelay: lbdeploy.DefaultRetryInitialDelay, MaxDelay: lbdeploy.DefaultRetryMaxDelay, Multiplier: 1.5}, Valid: true},
+		{Name: "negative-attempts", Policy: lbdeploy.RetryPolicy{Attempts: -1}},
+		{Name: "negative-initial-delay", Policy: lbdeploy.RetryPolicy{InitialDelay: -1}},
+		{Name: "negative-max-delay", Policy: lbdeploy.RetryPolicy{MaxDelay: -1}},
+		{Name: "max-below-initial", Policy: lbdeploy.RetryPolicy{InitialDelay: lbdeploy.DefaultRetryMaxDelay, MaxDelay: lbdeploy.DefaultRetryInitialDelay}},
+		{Name: "small-multiplier", Policy: lbdeploy.RetryPolicy{Multiplier: 0.5}},
+	}
+
+	for _, fixture := range fixtures {
+		if err := fixture.Policy.Validate(); (err == nil) != fixture.Valid {
+			t.Errorf("%s: got error %v, want valid %t", fixture.Name, err, fixture.Valid)
+		}
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := lbdeploy.RetryPolicy{InitialDelay: datatype.Duration(time.Second), MaxDelay: datatype.Duration(10 * time.Second), Multiplier: 2}
+
+	fixtures := []struct {
+		Retry   int
+		Backoff time.Duration
+	}{
+		{Retry: 1, Backoff: time.Second},
+		{Retry: 2, Backoff: 2 * time.Second},
+		{Retry: 3, Backoff: 4 * time.Second},
+		{Retry: 4, Backoff: 8 * time.Second},
+		{Retry: 5, Backoff: 10 * time.Second},
+		{Retry: 50, Backoff: 10 * time.Second},
+	}
+
+	for _, fixture := range fixtures {
+		// Jitter places each delay between half of the backoff and the full
+		// backoff.
+		for range 20 {
+			if got := policy.Delay(fixture.Retry); got < fixture.Backoff/2 || got > fixture.Backoff {
+				t.Errorf("retry %d: got delay %s, want between %s and %s", fixture.Retry, got, fixture.Backoff/2, fixture.Backoff)
+				break
+			}
+		}
+	}
+
+	defaults := lbdeploy.RetryPolicy{}.WithDefaults()
+	if defaults.Attempts != lbdeploy.DefaultRetryAttempts || defaults.InitialDelay != lbdeploy.DefaultRetryInitialDelay || defaults.MaxDelay != lbdeploy.DefaultRetryMaxDelay || defaults.Multiplier != lbdeploy.DefaultRetryMultiplier {
+		t.Errorf("unexpected defaults: %+v", defaults)
+	}
+	if long := (lbdeploy.RetryPolicy{InitialDelay: datatype.Duration(time.Minute)}).WithDefaults(); long.MaxDelay != long.InitialDelay {
+		t.Errorf("the default maximum delay should not be less than the initial delay: %+v", long)
+	}
+}
@@ -0,0 +1,81 @@
+package lbdeploy
+
+import (
+	"fmt"
+	"slices"
+)
+
+// FlowRollback describes how a flow undoes its work when it fails.
+//
+// Each step in Undo compensates for one of the flow's actions, which is
+// identified by its position within the flow, starting at 1. A step only
+// runs if the action it compensates for completed before the flow failed,
+// so that work which never happened is not undone. Steps run in the reverse
+// order of the actions they compensate for, so that the most recent work
+// is undone first.
+//
+// If Flow is provided, it is started after the steps have run, as long as
+// at least one action of the failed flow completed.
+//
+// The rollback runs when the flow fails because of an action that failed.
+// It does not run when a failure is ignored or handled by an on-error
+// policy, or when the deployment is cancelled.
+type FlowRollback struct {
+	Undo []RollbackStep `json:"undo,omitzero"`
+	Flow FlowID         `json:"flow,omitempty"`
+}
+
+// RollbackStep holds the compensating actions for an action within a flow.
+//
+// The actions are invoked in order. If one of them fails, the remaining
+// actions of the step are skipped and the rollback is reported as having
+// failed, but other steps still run.
+type RollbackStep struct {
+	Action  int      `json:"action"`
+	Actions []Action `json:"actions"`
+}
+
+// IsZero returns true if the rollback does not do anything.
+func (rollback FlowRollback) IsZero() bool {
+	return len(rollback.Undo) == 0 && rollback.Flow == ""
+}
+
+// Validate returns an error if the rollback is invalid for a flow with
+// the given number of actions.
+func (rollback FlowRollback) Validate(actions int) error {
+	for i, step := range rollback.Undo {
+		if step.Action < 1 || step.Action > actions {
+			return fmt.Errorf("undo step %d: the flow does not have an action %d", i+1, step.Action)
+		}
+		if len(step.Actions) == 0 {
+			return fmt.Errorf("undo step %d: actions were not provided", i+1)
+		}
+		for a, action := range step.Actions {
+			if err := action.ValidateErrorPolicy(); err != nil {
+				return fmt.Errorf("undo step %d: action %d: %w", i+1, a+1, err)
+			}
+			switch action.OnError {
+			case OnErrorUnspecified, OnErrorStop, OnErrorContinue:
+			default:
+				return fmt.Errorf("undo step %d: action %d: the \"%s\" on-error policy is not supported by rollbacks", i+1, a+1, action.OnError)
+			}
+		}
+	}
+	return nil
+}
+
+// Pending returns the steps that undo the actions which completed, in the
+// order that they should run. The completed slice is indexed by the
+// position of each action within the flow.
+func (rollback FlowRollback) Pending(completed []bool) []RollbackStep {
+	var steps []RollbackStep
+	for _, step := range rollback.Undo {
+		if i := step.Action - 1; i >= 0 && i < len(completed) && completed[i] {
+			steps = append(steps, step)
+		}
+	}
+	slices.SortStableFunc(steps, func(a, b RollbackStep) int {
+		return b.Action - a.Action
+	})
+	return steps
+}
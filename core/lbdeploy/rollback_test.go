@@ -0,0 +1,50 @@
+package lbdeploy_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+var testRollback = lbdeploy.FlowRollback{
+	Undo: []lbdeploy.RollbackStep{
+		{Action: 1, Actions: []lbdeploy.Action{{Type: lbdeploy.ActionDeleteFile}}},
+		{Action: 3, Actions: []lbdeploy.Action{{Type: lbdeploy.ActionRestoreFile}}},
+		{Action: 2, Actions: []lbdeploy.Action{{Type: lbdeploy.ActionInvokeCommand}}},
+		{Action: 3, Actions: []lbdeploy.Action{{Type: lbdeploy.ActionInvokeCommand}}},
+	},
+}
+
+func TestFlowRollbackPending(t *testing.T) {
+	if err := testRollback.Validate(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := testRollback.Validate(2); err == nil {
+		t.Error("expected an error for an undo step that refers to a missing action")
+	}
+
+	fixtures := []struct {
+		Name      string
+		Completed []bool
+		Steps     []int
+	}{
+		{Name: "none", Completed: []bool{false, false, false}},
+		{Name: "first", Completed: []bool{true, false, false}, Steps: []int{0}},
+		{Name: "all", Completed: []bool{true, true, true}, Steps: []int{1, 3, 2, 0}},
+		{Name: "gap", Completed: []bool{true, false, true}, Steps: []int{1, 3, 0}},
+	}
+
+	for _, fixture := range fixtures {
+		var want []lbdeploy.RollbackStep
+		for _, i := range fixture.Steps {
+			want = append(want, testRollback.Undo[i])
+		}
+		got := testRollback.Pending(fixture.Completed)
+		if !slices.EqualFunc(got, want, func(a, b lbdeploy.RollbackStep) bool {
+			return a.Action == b.Action && a.Actions[0].Type == b.Actions[0].Type
+		}) {
+			t.Errorf("%s: got steps %v, want %v", fixture.Name, got, want)
+		}
+	}
+}
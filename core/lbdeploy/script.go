@@ -0,0 +1,62 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Script defines a script to be run by a script action.
+//
+// A script is provided either inline or as a file resource, but not both.
+type Script struct {
+	// Inline holds the body of the script.
+	Inline string `json:"inline,omitempty"`
+
+	// File identifies a file resource that contains the script.
+	File FileResourceID `json:"file,omitempty"`
+
+	// Args is the set of arguments to be passed to the script.
+	Args []string `json:"args,omitzero"`
+
+	// WorkingDirectory specifies a working directory for the script. If no
+	// working directory is specified, the directory containing the script
+	// will be used.
+	WorkingDirectory DirectoryResourceID `json:"working-directory,omitempty"`
+
+	// Environment holds environment variables that will be provided to the
+	// script, in addition to those inherited from LeafBridge and those
+	// defined by the deployment.
+	Environment EnvironmentMap `json:"environment,omitzero"`
+
+	// ExitCodes provide a map of known exit codes for the script.
+	ExitCodes ExitCodeMap `json:"exit-codes,omitzero"`
+
+	// OutputEncoding specifies the text encoding of the script's output.
+	// If an encoding is not specified, it will be detected automatically,
+	// falling back to the system's OEM code page.
+	OutputEncoding OutputEncoding `json:"output-encoding,omitempty"`
+
+	// Output determines how much of the script's output is captured.
+	Output OutputOptions `json:"output,omitzero"`
+}
+
+// Validate returns a non-nil error if the script contains invalid
+// configuration.
+func (s Script) Validate() error {
+	switch {
+	case s.Inline == "" && s.File == "":
+		return errors.New("the script does not provide an inline body or a file")
+	case s.Inline != "" && s.File != "":
+		return errors.New("the script provides both an inline body and a file, which are mutually exclusive")
+	}
+	if err := s.Output.Validate(); err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+	if err := s.OutputEncoding.Validate(); err != nil {
+		return err
+	}
+	if err := s.Environment.Validate(); err != nil {
+		return fmt.Errorf("environment: %w", err)
+	}
+	return nil
+}
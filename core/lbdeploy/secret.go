@@ -0,0 +1,56 @@
+package lbdeploy
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Secret describes a sensitive value, such as an access token, and where
+// it can be found.
+//
+// Exactly one source must be provided:
+//
+//   - Value holds the secret literally within the deployment.
+//   - Environment names an environment variable that holds the secret.
+//   - DPAPI holds a base64-encoded blob that was encrypted with the Windows
+//     Data Protection API in the local machine scope.
+//
+// Secrets are never included in events or logs.
+type Secret struct {
+	Value       string `json:"value,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	DPAPI       string `json:"dpapi,omitempty"`
+}
+
+// IsZero returns true if the secret does not specify a source.
+func (secret Secret) IsZero() bool {
+	return secret.Value == "" && secret.Environment == "" && secret.DPAPI == ""
+}
+
+// Validate returns a non-nil error if the secret is invalid.
+func (secret Secret) Validate() error {
+	var sources int
+	for _, value := range []string{secret.Value, secret.Environment, secret.DPAPI} {
+		if value != "" {
+			sources++
+		}
+	}
+	switch {
+	case sources == 0:
+		return errors.New("a secret source is missing")
+	case sources > 1:
+		return errors.New("more than one secret source was provided")
+	}
+
+	if strings.ContainsAny(secret.Environment, "=\x00") {
+		return fmt.Errorf("the environment variable name \"%s\" contains an invalid character", secret.Environment)
+	}
+	if secret.DPAPI != "" {
+		if _, err := base64.StdEncoding.DecodeString(secret.DPAPI); err != nil {
+			return fmt.Errorf("the dpapi blob is not valid base64: %w", err)
+		}
+	}
+	return nil
+}
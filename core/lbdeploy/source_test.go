@@ -0,0 +1,111 @@
+package lbdeploy_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+type sourceFixture struct {
+	Name   string
+	Source lbdeploy.PackageSource
+	Err    string
+}
+
+func testSourceFixtures(t *testing.T, fixtures []sourceFixture) {
+	t.Helper()
+	for _, fixture := range fixtures {
+		err := fixture.Source.Validate()
+		switch {
+		case fixture.Err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", fixture.Name, err)
+		case fixture.Err != "" && err == nil:
+			t.Errorf("%s: expected an error containing \"%s\"", fixture.Name, fixture.Err)
+		case fixture.Err != "" && !strings.Contains(err.Error(), fixture.Err):
+			t.Errorf("%s: got error \"%v\", want an error containing \"%s\"", fixture.Name, err, fixture.Err)
+		}
+	}
+}
+
+func TestPackageSourceHeaders(t *testing.T) {
+	testSourceFixtures(t, []sourceFixture{
+		{Name: "none", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP}},
+		{Name: "custom", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Headers: lbdeploy.HeaderMap{"X-Api-Key": "abc"}}},
+		{Name: "empty-name", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Headers: lbdeploy.HeaderMap{"": "abc"}}, Err: "header name is missing"},
+		{Name: "colon", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Headers: lbdeploy.HeaderMap{"X-Api:Key": "abc"}}, Err: "invalid character"},
+		{Name: "space", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Headers: lbdeploy.HeaderMap{"X Key": "abc"}}, Err: "invalid character"},
+		{Name: "newline-value", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Headers: lbdeploy.HeaderMap{"X-Key": "abc\r\nHost: evil"}}, Err: "invalid character"},
+		{Name: "managed", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Headers: lbdeploy.HeaderMap{"range": "bytes=0-"}}, Err: "managed by LeafBridge"},
+	})
+}
+
+func TestPackageSourceAuthorization(t *testing.T) {
+	token := lbdeploy.SourceAuthorization{Token: lbdeploy.Secret{Environment: "TOKEN"}}
+	testSourceFixtures(t, []sourceFixture{
+		{Name: "token", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Authorization: token}},
+		{Name: "scheme-only", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Authorization: lbdeploy.SourceAuthorization{Scheme: "Basic"}}, Err: "secret source is missing"},
+		{Name: "scheme-space", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Authorization: lbdeploy.SourceAuthorization{Scheme: "Bad Scheme", Token: token.Token}}, Err: "invalid character"},
+		{Name: "duplicate-header", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, Headers: lbdeploy.HeaderMap{"authorization": "x"}, Authorization: token}, Err: "authorization header"},
+	})
+
+	if got := token.SchemeOrDefault(); got != "Bearer" {
+		t.Errorf("default scheme: got %s, want Bearer", got)
+	}
+}
+
+func TestPackageSourceRedacted(t *testing.T) {
+	source := lbdeploy.PackageSource{
+		Type:          lbdeploy.PackageSourceHTTP,
+		URL:           "https://example.com/package.msi",
+		Headers:       lbdeploy.HeaderMap{"X-Api-Key": "abc"},
+		Authorization: lbdeploy.SourceAuthorization{Scheme: "Basic", Token: lbdeploy.Secret{Value: "secret"}},
+	}
+	redacted := source.Redacted()
+	if got := redacted.Headers["X-Api-Key"]; got != "[redacted]" {
+		t.Errorf("header: got %s, want [redacted]", got)
+	}
+	if !redacted.Authorization.Token.IsZero() {
+		t.Errorf("token: got %+v, want a zero secret", redacted.Authorization.Token)
+	}
+	if redacted.Authorization.Scheme != "Basic" || redacted.URL != source.URL {
+		t.Errorf("redacted source lost non-sensitive fields: %+v", redacted)
+	}
+	if source.Headers["X-Api-Key"] != "abc" {
+		t.Error("redacting the source modified the original headers")
+	}
+}
+
+func TestSecretValidate(t *testing.T) {
+	fixtures := []struct {
+		Name   string
+		Secret lbdeploy.Secret
+		Valid  bool
+	}{
+		{Name: "missing"},
+		{Name: "value", Secret: lbdeploy.Secret{Value: "abc"}, Valid: true},
+		{Name: "environment", Secret: lbdeploy.Secret{Environment: "TOKEN"}, Valid: true},
+		{Name: "environment-equals", Secret: lbdeploy.Secret{Environment: "TOKEN=1"}},
+		{Name: "dpapi", Secret: lbdeploy.Secret{DPAPI: "AQID"}, Valid: true},
+		{Name: "dpapi-invalid", Secret: lbdeploy.Secret{DPAPI: "not base64!"}},
+		{Name: "multiple", Secret: lbdeploy.Secret{Value: "abc", Environment: "TOKEN"}},
+	}
+	for _, fixture := range fixtures {
+		err := fixture.Secret.Validate()
+		if fixture.Valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", fixture.Name, err)
+		} else if !fixture.Valid && err == nil {
+			t.Errorf("%s: expected an error", fixture.Name)
+		}
+	}
+}
+
+func TestPackageSourceIntegratedAuth(t *testing.T) {
+	testSourceFixtures(t, []sourceFixture{
+		{Name: "negotiate", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, IntegratedAuth: lbdeploy.IntegratedAuthNegotiate}},
+		{Name: "kerberos", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, IntegratedAuth: lbdeploy.IntegratedAuthKerberos}},
+		{Name: "ntlm", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, IntegratedAuth: lbdeploy.IntegratedAuthNTLM}},
+		{Name: "unknown", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, IntegratedAuth: "digest"}, Err: "not recognized"},
+		{Name: "with-authorization", Source: lbdeploy.PackageSource{Type: lbdeploy.PackageSourceHTTP, IntegratedAuth: lbdeploy.IntegratedAuthNegotiate, Authorization: lbdeploy.SourceAuthorization{Token: lbdeploy.Secret{Value: "abc"}}}, Err: "cannot be combined"},
+	})
+}
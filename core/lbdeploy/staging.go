@@ -0,0 +1,13 @@
+package lbdeploy
+
+// Staging describes how the packages of a deployment are staged and
+// extracted on the local system.
+//
+// If ExcludeFromAntivirus is true, the staging and extraction directories
+// used by the deployment are temporarily excluded from real-time antivirus
+// scanning while the deployment runs. The exclusions are removed when the
+// deployment finishes. Real-time scanning can double the time it takes to
+// extract large packages.
+type Staging struct {
+	ExcludeFromAntivirus bool `json:"exclude-from-antivirus,omitempty"`
+}
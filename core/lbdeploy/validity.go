@@ -0,0 +1,49 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ValidityWindow is a period of time during which a deployment or flow is
+// permitted to run. A zero time for either bound leaves that side of the
+// window open.
+type ValidityWindow struct {
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// IsZero returns true if the window does not restrict anything.
+func (w ValidityWindow) IsZero() bool {
+	return w.NotBefore.IsZero() && w.NotAfter.IsZero()
+}
+
+// Validate returns a non-nil error if the window is invalid.
+func (w ValidityWindow) Validate() error {
+	if !w.NotBefore.IsZero() && !w.NotAfter.IsZero() && w.NotAfter.Before(w.NotBefore) {
+		return errors.New("the not-after time is earlier than the not-before time")
+	}
+	return nil
+}
+
+// Check returns a non-nil error if t falls outside of the window.
+func (w ValidityWindow) Check(t time.Time) error {
+	switch {
+	case !w.NotBefore.IsZero() && t.Before(w.NotBefore):
+		return fmt.Errorf("it is not valid until %s", w.NotBefore.Format(time.RFC3339))
+	case !w.NotAfter.IsZero() && t.After(w.NotAfter):
+		return fmt.Errorf("it expired on %s", w.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Validity returns the validity window of the deployment.
+func (dep Deployment) Validity() ValidityWindow {
+	return ValidityWindow{NotBefore: dep.NotBefore, NotAfter: dep.NotAfter}
+}
+
+// Validity returns the validity window of the flow.
+func (flow Flow) Validity() ValidityWindow {
+	return ValidityWindow{NotBefore: flow.NotBefore, NotAfter: flow.NotAfter}
+}
@@ -0,0 +1,33 @@
+package lbdeploy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+func TestValidityWindowCheck(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	fixtures := []struct {
+		Window lbdeploy.ValidityWindow
+		Time   time.Time
+		Valid  bool
+	}{
+		{Window: lbdeploy.ValidityWindow{}, Time: start, Valid: true},
+		{Window: lbdeploy.ValidityWindow{NotBefore: start}, Time: start, Valid: true},
+		{Window: lbdeploy.ValidityWindow{NotBefore: start}, Time: start.Add(-time.Second), Valid: false},
+		{Window: lbdeploy.ValidityWindow{NotAfter: end}, Time: end, Valid: true},
+		{Window: lbdeploy.ValidityWindow{NotAfter: end}, Time: end.Add(time.Second), Valid: false},
+		{Window: lbdeploy.ValidityWindow{NotBefore: start, NotAfter: end}, Time: start.AddDate(0, 0, 14), Valid: true},
+		{Window: lbdeploy.ValidityWindow{NotBefore: start, NotAfter: end}, Time: end.AddDate(0, 6, 0), Valid: false},
+	}
+
+	for i, fixture := range fixtures {
+		if err := fixture.Window.Check(fixture.Time); (err == nil) != fixture.Valid {
+			t.Errorf("fixture %d: got %v, want valid = %t", i+1, err, fixture.Valid)
+		}
+	}
+}
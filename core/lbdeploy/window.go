@@ -0,0 +1,157 @@
+package lbdeploy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+)
+
+// DefaultWindowGrace is the amount of time that an unexpected window is
+// tolerated before the on-unexpected policy of a window watchdog is
+// applied, if the watchdog does not specify one.
+const DefaultWindowGrace = 10 * time.Second
+
+// WindowPolicy determines how a window watchdog responds to windows that
+// do not match any of its rules.
+type WindowPolicy string
+
+// Window policies.
+const (
+	WindowPolicyLog  WindowPolicy = "log"
+	WindowPolicyFail WindowPolicy = "fail"
+)
+
+// Validate returns a non-nil error if the policy is not recognized.
+func (policy WindowPolicy) Validate() error {
+	switch policy {
+	case "", WindowPolicyLog, WindowPolicyFail:
+		return nil
+	default:
+		return fmt.Errorf("the window policy \"%s\" is not recognized", policy)
+	}
+}
+
+// WindowAction is an action taken by a window watchdog when a window
+// matches one of its rules.
+type WindowAction string
+
+// Window actions.
+const (
+	WindowIgnore WindowAction = "ignore"
+	WindowClose  WindowAction = "close"
+	WindowClick  WindowAction = "click"
+	WindowFail   WindowAction = "fail"
+)
+
+// WindowWatchdog monitors the interactive windows opened by the processes
+// of a command. It is intended for installers that claim to be silent, but
+// still present dialogs that would otherwise leave them waiting forever
+// for input that will never arrive.
+//
+// The title of each window is recorded. Windows that match a rule are
+// handled by that rule's action. Windows that don't match any rule are
+// handled according to the on-unexpected policy once they have been open
+// for the grace period. The default policy is to log them.
+//
+// A watchdog is only active if it has rules or a policy.
+type WindowWatchdog struct {
+	OnUnexpected WindowPolicy      `json:"on-unexpected,omitempty"`
+	Grace        datatype.Duration `json:"grace,omitzero"`
+	Rules        []WindowRule      `json:"rules,omitzero"`
+}
+
+// IsZero returns true if the watchdog is not configured.
+func (w WindowWatchdog) IsZero() bool {
+	return w.OnUnexpected == "" && w.Grace == 0 && len(w.Rules) == 0
+}
+
+// EffectiveGrace returns the amount of time that an unexpected window is
+// tolerated.
+func (w WindowWatchdog) EffectiveGrace() time.Duration {
+	if w.Grace <= 0 {
+		return DefaultWindowGrace
+	}
+	return time.Duration(w.Grace)
+}
+
+// Validate returns a non-nil error if the watchdog contains invalid
+// configuration.
+func (w WindowWatchdog) Validate() error {
+	if err := w.OnUnexpected.Validate(); err != nil {
+		return err
+	}
+	if w.Grace < 0 {
+		return errors.New("a negative grace period was provided")
+	}
+	for i, rule := range w.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the index of the first rule that matches the given window
+// title. It returns -1 if none of the rules match.
+func (w WindowWatchdog) Match(title string) int {
+	for i, rule := range w.Rules {
+		matches, err := rule.Compile()
+		if err == nil && matches(title) {
+			return i
+		}
+	}
+	return -1
+}
+
+// WindowRule matches windows by their title and describes how a window
+// watchdog responds to them. Titles are compared without regard to case.
+// If a match type is not provided, the title must contain the rule's
+// title.
+//
+// The click action presses the button within the window whose text matches
+// Button, ignoring keyboard accelerators. The close action asks the window
+// to close. The fail action terminates the command.
+type WindowRule struct {
+	Type   MatchType    `json:"type,omitempty"`
+	Title  string       `json:"title"`
+	Action WindowAction `json:"action"`
+	Button string       `json:"button,omitempty"`
+}
+
+// Compile returns a function that reports whether a window title matches
+// the rule.
+func (rule WindowRule) Compile() (func(title string) bool, error) {
+	t := rule.Type
+	if t == "" {
+		t = MatchContains
+	}
+	return compileMatchValue(t, rule.Title, true)
+}
+
+// Validate returns a non-nil error if the rule contains invalid
+// configuration.
+func (rule WindowRule) Validate() error {
+	if rule.Title == "" {
+		return errors.New("a window title was not provided")
+	}
+	if _, err := rule.Compile(); err != nil {
+		return err
+	}
+	switch rule.Action {
+	case WindowIgnore, WindowClose, WindowFail:
+		if rule.Button != "" {
+			return fmt.Errorf("a button was provided for a rule with the \"%s\" action", rule.Action)
+		}
+	case WindowClick:
+		if rule.Button == "" {
+			return errors.New("a button was not provided for a rule with the click action")
+		}
+	case "":
+		return errors.New("an action was not provided")
+	default:
+		return fmt.Errorf("the window action \"%s\" is not recognized", rule.Action)
+	}
+	return nil
+}
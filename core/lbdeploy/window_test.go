@@ -0,0 +1,38 @@
+package lbdeploy_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+var testWatchdog = lbdeploy.WindowWatchdog{
+	Rules: []lbdeploy.WindowRule{
+		{Title: "Setup Complete", Action: lbdeploy.WindowClick, Button: "Finish"},
+		{Type: lbdeploy.MatchRegularExpression, Title: `^Error \d+$`, Action: lbdeploy.WindowFail},
+		{Type: lbdeploy.MatchEquals, Title: "Readme", Action: lbdeploy.WindowClose},
+	},
+}
+
+func TestWindowWatchdogMatch(t *testing.T) {
+	if err := testWatchdog.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures := []struct {
+		Title string
+		Rule  int
+	}{
+		{Title: "Example App - setup complete", Rule: 0},
+		{Title: "error 1603", Rule: 1},
+		{Title: "Error 1603: Fatal", Rule: -1},
+		{Title: "README", Rule: 2},
+		{Title: "Readme.txt - Notepad", Rule: -1},
+	}
+
+	for _, fixture := range fixtures {
+		if got := testWatchdog.Match(fixture.Title); got != fixture.Rule {
+			t.Errorf("%s: matched rule %d, want %d", fixture.Title, got, fixture.Rule)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment local account event types.
+const (
+	LocalUserChangeType       = lbevent.Type("deployment.local-account:user-change")
+	GroupMembershipChangeType = lbevent.Type("deployment.local-account:group-change")
+	UserRightChangeType       = lbevent.Type("deployment.local-account:right-change")
+)
+
+// LocalUserChange is an event that occurs when a local user has been created,
+// updated or removed by a configure-local-users action.
+type LocalUserChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	User        string
+	Removed     bool
+	Changed     bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e LocalUserChange) Type() lbevent.Type {
+	return LocalUserChangeType
+}
+
+// Level returns the level of the event.
+func (e LocalUserChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e LocalUserChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil && e.Removed:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" local user could not be removed due to an error: %s.", e.User, e.Err))
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" local user could not be created or updated due to an error: %s.", e.User, e.Err))
+	case e.Removed && e.Changed:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" local user was removed.", e.User))
+	case e.Removed:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" local user did not exist.", e.User))
+	case e.Changed:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" local user was created.", e.User))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" local user already existed and was updated.", e.User))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e LocalUserChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e LocalUserChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("user", e.User),
+		slog.Bool("removed", e.Removed),
+		slog.Bool("changed", e.Changed),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// GroupMembershipChange is an event that occurs when an account has been added to, or
+// removed from, a local group by a configure-local-groups action.
+type GroupMembershipChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Group       string
+	Member      string
+	Removed     bool
+	Changed     bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e GroupMembershipChange) Type() lbevent.Type {
+	return GroupMembershipChangeType
+}
+
+// Level returns the level of the event.
+func (e GroupMembershipChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e GroupMembershipChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil && e.Removed:
+		builder.WriteStandard(fmt.Sprintf("\"%s\" could not be removed from the \"%s\" local group due to an error: %s.", e.Member, e.Group, e.Err))
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("\"%s\" could not be added to the \"%s\" local group due to an error: %s.", e.Member, e.Group, e.Err))
+	case e.Removed && e.Changed:
+		builder.WriteStandard(fmt.Sprintf("\"%s\" was removed from the \"%s\" local group.", e.Member, e.Group))
+	case e.Removed:
+		builder.WriteStandard(fmt.Sprintf("\"%s\" was not a member of the \"%s\" local group.", e.Member, e.Group))
+	case e.Changed:
+		builder.WriteStandard(fmt.Sprintf("\"%s\" was added to the \"%s\" local group.", e.Member, e.Group))
+	default:
+		builder.WriteStandard(fmt.Sprintf("\"%s\" was already a member of the \"%s\" local group.", e.Member, e.Group))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e GroupMembershipChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e GroupMembershipChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("group", e.Group),
+		slog.String("member", e.Member),
+		slog.Bool("removed", e.Removed),
+		slog.Bool("changed", e.Changed),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// UserRightChange is an event that occurs when a user right has been granted to, or
+// revoked from, an account by a configure-user-rights action.
+type UserRightChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Right       string
+	Account     string
+	Removed     bool
+	Changed     bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e UserRightChange) Type() lbevent.Type {
+	return UserRightChangeType
+}
+
+// Level returns the level of the event.
+func (e UserRightChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e UserRightChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil && e.Removed:
+		builder.WriteStandard(fmt.Sprintf("The %s user right could not be revoked from \"%s\" due to an error: %s.", e.Right, e.Account, e.Err))
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The %s user right could not be granted to \"%s\" due to an error: %s.", e.Right, e.Account, e.Err))
+	case e.Removed && e.Changed:
+		builder.WriteStandard(fmt.Sprintf("The %s user right was revoked from \"%s\".", e.Right, e.Account))
+	case e.Removed:
+		builder.WriteStandard(fmt.Sprintf("The %s user right was not held by \"%s\".", e.Right, e.Account))
+	case e.Changed:
+		builder.WriteStandard(fmt.Sprintf("The %s user right was granted to \"%s\".", e.Right, e.Account))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The %s user right was already held by \"%s\".", e.Right, e.Account))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e UserRightChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e UserRightChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("right", e.Right),
+		slog.String("account", e.Account),
+		slog.Bool("removed", e.Removed),
+		slog.Bool("changed", e.Changed),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
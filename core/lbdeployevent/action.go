@@ -15,6 +15,8 @@ import (
 const (
 	ActionStartedType = lbevent.Type("deployment.action:started")
 	ActionStoppedType = lbevent.Type("deployment.action:stopped")
+	ActionSkippedType = lbevent.Type("deployment.action:skipped")
+	ActionRetriedType = lbevent.Type("deployment.action:retried")
 )
 
 // ActionStarted is an event that occurs when a deployment action has started.
@@ -113,7 +115,7 @@ func (e ActionStopped) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e ActionStopped) Details() string {
-	return ""
+	return withErrorHint("", e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -126,7 +128,7 @@ func (e ActionStopped) Attrs() []slog.Attr {
 		slog.Time("stopped", e.Stopped),
 	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
 	}
 	return attrs
 }
@@ -135,3 +137,143 @@ func (e ActionStopped) Attrs() []slog.Attr {
 func (e ActionStopped) Duration() time.Duration {
 	return e.Stopped.Sub(e.Started)
 }
+
+// ActionSkipped is an event that occurs when a deployment action is skipped
+// because one or more of its conditions did not pass, or because its
+// conditions could not be evaluated.
+type ActionSkipped struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Passed      lbdeploy.ConditionList
+	Failed      lbdeploy.ConditionList
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e ActionSkipped) Type() lbevent.Type {
+	return ActionSkippedType
+}
+
+// Level returns the level of the event.
+func (e ActionSkipped) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e ActionSkipped) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("Skipped action because its conditions could not be evaluated: %s", e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("Skipped action because one or more conditions did not pass: %s.", e.Failed))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ActionSkipped) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ActionSkipped) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("conditions", "passed", e.Passed, "failed", e.Failed),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// ActionRetried is an event that occurs when an attempt to invoke an
+// action has failed and another attempt will be made after a delay, as
+// permitted by the retry policy of the action.
+//
+// If the failure was caused by a command or script that returned an exit
+// code, HasExitCode is true and ExitCode holds it.
+type ActionRetried struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Attempt     int
+	MaxAttempts int
+	Delay       time.Duration
+	ExitCode    lbdeploy.ExitCode
+	HasExitCode bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e ActionRetried) Type() lbevent.Type {
+	return ActionRetriedType
+}
+
+// Level returns the level of the event.
+func (e ActionRetried) Level() slog.Level {
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e ActionRetried) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	builder.WriteStandard(fmt.Sprintf("Attempt %d of %d failed due to an error: %s. Retrying in %s.",
+		e.Attempt,
+		e.MaxAttempts,
+		e.Err,
+		e.Delay.Round(time.Millisecond)))
+
+	if e.HasExitCode {
+		builder.WriteNote(fmt.Sprintf("exit code %d", e.ExitCode))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ActionRetried) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ActionRetried) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Int("attempt", e.Attempt),
+		slog.Int("max-attempts", e.MaxAttempts),
+		slog.Duration("delay", e.Delay),
+	}
+	if e.HasExitCode {
+		attrs = append(attrs, slog.Int("exit-code", int(e.ExitCode)))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
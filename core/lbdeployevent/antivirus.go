@@ -0,0 +1,91 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment antivirus event types.
+const (
+	AntivirusExclusionType = lbevent.Type("deployment.antivirus:exclusion")
+)
+
+// AntivirusOperation identifies a change to an antivirus exclusion.
+type AntivirusOperation string
+
+// Antivirus exclusion operations.
+const (
+	AntivirusExclusionAdded   AntivirusOperation = "add"
+	AntivirusExclusionRemoved AntivirusOperation = "remove"
+)
+
+// AntivirusExclusion is an event that occurs when a temporary antivirus
+// exclusion is added for a staging or extraction directory, or when it is
+// removed.
+type AntivirusExclusion struct {
+	Deployment lbdeploy.DeploymentID
+	Operation  AntivirusOperation
+	Path       string
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e AntivirusExclusion) Type() lbevent.Type {
+	return AntivirusExclusionType
+}
+
+// Level returns the level of the event.
+func (e AntivirusExclusion) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e AntivirusExclusion) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+
+	switch e.Operation {
+	case AntivirusExclusionAdded:
+		if e.Err != nil {
+			builder.WriteStandard(fmt.Sprintf("Failed to exclude \"%s\" from real-time antivirus scanning: %s.", e.Path, e.Err))
+		} else {
+			builder.WriteStandard(fmt.Sprintf("Temporarily excluded \"%s\" from real-time antivirus scanning.", e.Path))
+		}
+	default:
+		if e.Err != nil {
+			builder.WriteStandard(fmt.Sprintf("Failed to remove the temporary antivirus exclusion for \"%s\": %s.", e.Path, e.Err))
+		} else {
+			builder.WriteStandard(fmt.Sprintf("Removed the temporary antivirus exclusion for \"%s\".", e.Path))
+		}
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e AntivirusExclusion) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e AntivirusExclusion) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("operation", string(e.Operation)),
+		slog.String("path", e.Path),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,109 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment approval event types.
+const (
+	DeploymentApprovalType = lbevent.Type("deployment.approval:check")
+)
+
+// DeploymentApproval is an event that occurs when the approval of a
+// deployment has been checked before a flow is invoked. It records the
+// approval metadata of the deployment for auditing.
+//
+// Verified lists the approvers whose signatures were verified against the
+// keys in the execution policy of the machine. Required indicates that the
+// policy requires approval of deployments.
+type DeploymentApproval struct {
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	Ticket     string
+	Approvers  []string
+	Verified   []string
+	Expires    time.Time
+	Required   bool
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e DeploymentApproval) Type() lbevent.Type {
+	return DeploymentApprovalType
+}
+
+// Level returns the level of the event.
+func (e DeploymentApproval) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e DeploymentApproval) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The approval of the deployment was not accepted: %s.", e.Err))
+	case len(e.Approvers) == 0:
+		builder.WriteStandard("The deployment has not been approved.")
+	case len(e.Verified) > 0:
+		builder.WriteStandard(fmt.Sprintf("The deployment was approved by %s (%d verified).", strings.Join(e.Approvers, ", "), len(e.Verified)))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The deployment was approved by %s (unverified).", strings.Join(e.Approvers, ", ")))
+	}
+
+	if e.Ticket != "" {
+		builder.WriteNote(e.Ticket, fieldformat.Label("ticket"))
+	}
+	if !e.Expires.IsZero() {
+		builder.WriteNote(e.Expires.Format(time.DateOnly), fieldformat.Label("expires"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e DeploymentApproval) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e DeploymentApproval) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Bool("required", e.Required),
+	}
+	if e.Ticket != "" {
+		attrs = append(attrs, slog.String("ticket", e.Ticket))
+	}
+	if len(e.Approvers) > 0 {
+		attrs = append(attrs, slog.Any("approvers", e.Approvers))
+	}
+	if len(e.Verified) > 0 {
+		attrs = append(attrs, slog.Any("verified", e.Verified))
+	}
+	if !e.Expires.IsZero() {
+		attrs = append(attrs, slog.Time("expires", e.Expires))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
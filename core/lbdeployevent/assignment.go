@@ -0,0 +1,135 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Assignment event types.
+const (
+	AssignmentsResolvedType = lbevent.Type("assignment:resolved")
+	AssignmentAppliedType   = lbevent.Type("assignment:applied")
+)
+
+// AssignmentsResolved is an event that occurs when an assignment manifest
+// has been evaluated against the local machine.
+type AssignmentsResolved struct {
+	Manifest string
+	Machine  string
+	Groups   []string
+	Tags     []string
+	Matched  []string
+	Total    int
+}
+
+// Type returns the type of the event.
+func (e AssignmentsResolved) Type() lbevent.Type {
+	return AssignmentsResolvedType
+}
+
+// Level returns the level of the event.
+func (e AssignmentsResolved) Level() slog.Level {
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e AssignmentsResolved) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(e.Machine)
+	builder.WriteStandard(fmt.Sprintf("%d of %d %s apply to this machine.", len(e.Matched), e.Total, plural(e.Total, "assignment", "assignments")))
+	builder.WriteNote(e.Manifest)
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e AssignmentsResolved) Details() string {
+	return strings.Join(e.Matched, "\n")
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e AssignmentsResolved) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("manifest", e.Manifest),
+		slog.String("machine", e.Machine),
+		slog.String("groups", strings.Join(e.Groups, ",")),
+		slog.String("tags", strings.Join(e.Tags, ",")),
+		slog.String("matched", strings.Join(e.Matched, ",")),
+		slog.Int("total", e.Total),
+	}
+}
+
+// AssignmentApplied is an event that occurs when an assignment has been
+// applied to the local machine, or when it could not be applied.
+type AssignmentApplied struct {
+	Assignment string
+	Location   string
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	Duration   time.Duration
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e AssignmentApplied) Type() lbevent.Type {
+	return AssignmentAppliedType
+}
+
+// Level returns the level of the event.
+func (e AssignmentApplied) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e AssignmentApplied) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(e.Assignment)
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("Failed to apply the assignment: %s.", e.Err))
+	default:
+		builder.WriteStandard(fmt.Sprintf("Applied the %s flow of the %s deployment.", e.Flow, e.Deployment))
+	}
+
+	if e.Duration > 0 {
+		builder.WriteNote(e.Duration.Round(time.Millisecond * 10).String())
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e AssignmentApplied) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e AssignmentApplied) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("assignment", e.Assignment),
+		slog.String("location", e.Location),
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Duration("duration", e.Duration),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,103 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment default association event types.
+const (
+	DefaultAssociationsChangeType = lbevent.Type("deployment.default-associations:change")
+)
+
+// DefaultAssociationsChange is an event that occurs when a
+// set-default-associations action has been applied to the local system.
+type DefaultAssociationsChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	FilePath    string
+	Set         []string
+	Removed     []string
+	Enforced    bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e DefaultAssociationsChange) Type() lbevent.Type {
+	return DefaultAssociationsChangeType
+}
+
+// Level returns the level of the event.
+func (e DefaultAssociationsChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e DefaultAssociationsChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The default application associations (%s) could not be applied due to an error: %s.", e.FilePath, e.Err))
+	case len(e.Set) == 0 && len(e.Removed) == 0:
+		builder.WriteStandard(fmt.Sprintf("The default application associations (%s) were already up to date and have been applied.", e.FilePath))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The default application associations (%s) were updated and applied.", e.FilePath))
+	}
+	if len(e.Set) > 0 {
+		builder.WriteNote(strings.Join(e.Set, " "), fieldformat.Label("set"))
+	}
+	if len(e.Removed) > 0 {
+		builder.WriteNote(strings.Join(e.Removed, " "), fieldformat.Label("removed"))
+	}
+	if e.Enforced {
+		builder.WriteNote("enforced at logon")
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e DefaultAssociationsChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e DefaultAssociationsChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("path", e.FilePath),
+		slog.Bool("enforced", e.Enforced),
+	}
+	if len(e.Set) > 0 {
+		attrs = append(attrs, slog.Any("set", e.Set))
+	}
+	if len(e.Removed) > 0 {
+		attrs = append(attrs, slog.Any("removed", e.Removed))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
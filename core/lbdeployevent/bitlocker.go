@@ -0,0 +1,90 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment BitLocker event types.
+const (
+	BitLockerProtectionType = lbevent.Type("deployment.bitlocker:protection")
+)
+
+// BitLockerProtection is an event that occurs when a suspend-bitlocker or
+// resume-bitlocker action has been applied to the system drive.
+type BitLockerProtection struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Drive       string
+	RebootCount int
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e BitLockerProtection) Type() lbevent.Type {
+	return BitLockerProtectionType
+}
+
+// Level returns the level of the event.
+func (e BitLockerProtection) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e BitLockerProtection) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	suspend := e.ActionType == lbdeploy.ActionSuspendBitLocker
+
+	switch {
+	case e.Err != nil && suspend:
+		builder.WriteStandard(fmt.Sprintf("BitLocker protection of the %s drive could not be suspended due to an error: %s.", e.Drive, e.Err))
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("BitLocker protection of the %s drive could not be resumed due to an error: %s.", e.Drive, e.Err))
+	case suspend:
+		builder.WriteStandard(fmt.Sprintf("BitLocker protection of the %s drive was suspended for %d %s.", e.Drive, e.RebootCount, plural(e.RebootCount, "reboot", "reboots")))
+	default:
+		builder.WriteStandard(fmt.Sprintf("BitLocker protection of the %s drive was resumed.", e.Drive))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e BitLockerProtection) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e BitLockerProtection) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("drive", e.Drive),
+	}
+	if e.ActionType == lbdeploy.ActionSuspendBitLocker {
+		attrs = append(attrs, slog.Int("reboot-count", e.RebootCount))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
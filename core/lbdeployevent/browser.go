@@ -0,0 +1,171 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment browser event types.
+const (
+	BrowserExtensionChangeType = lbevent.Type("deployment.browser:extension-change")
+	BrowserPolicyChangeType    = lbevent.Type("deployment.browser:policy-change")
+)
+
+// BrowserExtensionChange is an event that occurs when an extension has
+// been added to or removed from the force-install list of a browser.
+type BrowserExtensionChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Browser     lbdeploy.Browser
+	Extension   string
+	URL         string
+	Settings    int
+	Removed     bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e BrowserExtensionChange) Type() lbevent.Type {
+	return BrowserExtensionChangeType
+}
+
+// Level returns the level of the event.
+func (e BrowserExtensionChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e BrowserExtensionChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil && e.Removed:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" extension could not be removed from %s due to an error: %s.", e.Extension, e.Browser, e.Err))
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" extension could not be installed in %s due to an error: %s.", e.Extension, e.Browser, e.Err))
+	case e.Removed:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" extension was removed from the %s force-install list.", e.Extension, e.Browser))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" extension was added to the %s force-install list.", e.Extension, e.Browser))
+		if e.Settings > 0 {
+			builder.WriteNote(strconv.Itoa(e.Settings), fieldformat.Label("settings"))
+		}
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e BrowserExtensionChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e BrowserExtensionChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("browser", string(e.Browser)),
+		slog.String("extension", e.Extension),
+		slog.Bool("removed", e.Removed),
+	}
+	if e.URL != "" {
+		attrs = append(attrs, slog.String("url", e.URL))
+	}
+	if e.Settings > 0 {
+		attrs = append(attrs, slog.Int("settings", e.Settings))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// BrowserPolicyChange is an event that occurs when a policy has been
+// applied to or removed from a browser.
+type BrowserPolicyChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Browser     lbdeploy.Browser
+	Policy      string
+	Removed     bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e BrowserPolicyChange) Type() lbevent.Type {
+	return BrowserPolicyChangeType
+}
+
+// Level returns the level of the event.
+func (e BrowserPolicyChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e BrowserPolicyChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The %s policy of %s could not be updated due to an error: %s.", e.Policy, e.Browser, e.Err))
+	case e.Removed:
+		builder.WriteStandard(fmt.Sprintf("The %s policy of %s was removed.", e.Policy, e.Browser))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The %s policy of %s was applied.", e.Policy, e.Browser))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e BrowserPolicyChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e BrowserPolicyChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("browser", string(e.Browser)),
+		slog.String("policy", e.Policy),
+		slog.Bool("removed", e.Removed),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,94 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment checksums event types.
+const (
+	ChecksumsResolvedType = lbevent.Type("deployment.checksums:resolved")
+)
+
+// ChecksumsResolved is an event that occurs when the expected hash of a
+// package has been retrieved from a detached checksums file.
+type ChecksumsResolved struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Package     lbdeploy.PackageID
+	Source      lbdeploy.PackageSource
+	Entry       string
+	Hash        filehash.Entry
+	Signed      bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e ChecksumsResolved) Type() lbevent.Type {
+	return ChecksumsResolvedType
+}
+
+// Level returns the level of the event.
+func (e ChecksumsResolved) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	if !e.Signed {
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e ChecksumsResolved) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The checksum for \"%s\" could not be retrieved from \"%s\" for the \"%s\" package due to an error: %s.", e.Entry, e.Source.URL, e.Package, e.Err))
+	case e.Signed:
+		builder.WriteStandard(fmt.Sprintf("The %s checksum for \"%s\" was retrieved from the signed checksums file \"%s\" for the \"%s\" package.", e.Hash.Type, e.Entry, e.Source.URL, e.Package))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The %s checksum for \"%s\" was retrieved from the unsigned checksums file \"%s\" for the \"%s\" package.", e.Hash.Type, e.Entry, e.Source.URL, e.Package))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ChecksumsResolved) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ChecksumsResolved) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("package", string(e.Package)),
+		slog.Group("source", "type", string(e.Source.Type), "url", e.Source.URL),
+		slog.String("entry", e.Entry),
+		slog.Bool("signed", e.Signed),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	} else {
+		attrs = append(attrs, slog.Group("hash", "type", string(e.Hash.Type), "value", e.Hash.Value.String()))
+	}
+	return attrs
+}
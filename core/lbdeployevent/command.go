@@ -18,9 +18,14 @@ const (
 	CommandSkippedType = lbevent.Type("deployment.command:skipped")
 	CommandStartedType = lbevent.Type("deployment.command:started")
 	CommandStoppedType = lbevent.Type("deployment.command:stopped")
+	InstallerWaitType  = lbevent.Type("deployment.command:installer-wait")
 )
 
 // CommandSkipped is an event that occurs when a command is skipped.
+//
+// If HeldApp is not empty, the command was skipped because it would have
+// modified an application that is held on the machine, and HoldReason
+// explains why the application is held.
 type CommandSkipped struct {
 	Deployment  lbdeploy.DeploymentID
 	Flow        lbdeploy.FlowID
@@ -29,6 +34,8 @@ type CommandSkipped struct {
 	Package     lbdeploy.PackageID
 	Command     lbdeploy.CommandID
 	Apps        lbdeploy.AppEvaluation
+	HeldApp     lbdeploy.AppID
+	HoldReason  string
 }
 
 // Type returns the type of the event.
@@ -38,6 +45,9 @@ func (e CommandSkipped) Type() lbevent.Type {
 
 // Level returns the level of the event.
 func (e CommandSkipped) Level() slog.Level {
+	if e.HeldApp != "" {
+		return slog.LevelWarn
+	}
 	return slog.LevelInfo
 }
 
@@ -54,6 +64,13 @@ func (e CommandSkipped) Message() string {
 	} else {
 		builder.WritePrimary(fmt.Sprintf("%s.%s", e.Package, e.Command))
 	}
+	if e.HeldApp != "" {
+		builder.WriteStandard(fmt.Sprintf("Skipped command because the \"%s\" app is held on this machine", e.HeldApp))
+		if e.HoldReason != "" {
+			builder.WriteNote(e.HoldReason, fieldformat.Label("reason"))
+		}
+		return builder.String()
+	}
 	builder.WriteStandard("Skipped command")
 	if len(e.Apps.AlreadyInstalled) > 0 {
 		builder.WriteNote(fmt.Sprintf("[%s]", e.Apps.AlreadyInstalled), fieldformat.Label("already installed"))
@@ -90,6 +107,9 @@ func (e CommandSkipped) Attrs() []slog.Attr {
 			"to-install", e.Apps.ToInstall,
 			"to-uninstall", e.Apps.ToUninstall))
 	}
+	if e.HeldApp != "" {
+		attrs = append(attrs, slog.Group("hold", "app", e.HeldApp, "reason", e.HoldReason))
+	}
 	return attrs
 }
 
@@ -104,6 +124,7 @@ type CommandStarted struct {
 	CommandLine          string
 	WorkingDirectory     lbdeploy.DirectoryResourceID
 	WorkingDirectoryPath string
+	LogPath              string
 	Apps                 lbdeploy.AppEvaluation
 }
 
@@ -149,14 +170,23 @@ func (e CommandStarted) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e CommandStarted) Details() string {
+	var out strings.Builder
+
 	switch {
 	case e.WorkingDirectoryPath != "":
-		return fmt.Sprintf("Working Directory: %s", e.WorkingDirectoryPath)
+		out.WriteString(fmt.Sprintf("Working Directory: %s", e.WorkingDirectoryPath))
 	case e.WorkingDirectory != "":
-		return fmt.Sprintf("Working Directory: %s", e.WorkingDirectory)
-	default:
-		return ""
+		out.WriteString(fmt.Sprintf("Working Directory: %s", e.WorkingDirectory))
 	}
+
+	if e.LogPath != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("Log File: %s", e.LogPath))
+	}
+
+	return out.String()
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -173,6 +203,9 @@ func (e CommandStarted) Attrs() []slog.Attr {
 	if e.WorkingDirectory != "" || e.WorkingDirectoryPath != "" {
 		attrs = append(attrs, slog.Group("working-directory", "id", e.WorkingDirectory, "path", e.WorkingDirectoryPath))
 	}
+	if e.LogPath != "" {
+		attrs = append(attrs, slog.Group("log-file", "path", e.LogPath))
+	}
 	if !e.Apps.IsZero() {
 		attrs = append(attrs, slog.Group("affected-apps",
 			"already-installed", e.Apps.AlreadyInstalled,
@@ -184,6 +217,9 @@ func (e CommandStarted) Attrs() []slog.Attr {
 }
 
 // CommandStopped is an event that occurs when a command has stopped.
+//
+// If the command was stopped because its action or flow exceeded a
+// timeout, Timeout holds the timeout that was exceeded.
 type CommandStopped struct {
 	Deployment           lbdeploy.DeploymentID
 	Flow                 lbdeploy.FlowID
@@ -194,12 +230,17 @@ type CommandStopped struct {
 	CommandLine          string
 	Result               lbdeploy.CommandResult
 	Output               string
+	OutputDiscarded      int64
+	OutputPath           string
 	WorkingDirectory     lbdeploy.DirectoryResourceID
 	WorkingDirectoryPath string
+	LogPath              string
+	LogTail              string
 	AppsBefore           lbdeploy.AppEvaluation
 	AppsAfter            lbdeploy.AppSummary
 	Started              time.Time
 	Stopped              time.Time
+	Timeout              time.Duration
 	Err                  error
 }
 
@@ -229,7 +270,9 @@ func (e CommandStopped) Message() string {
 	} else {
 		builder.WritePrimary(fmt.Sprintf("%s.%s", e.Package, e.Command))
 	}
-	if e.Err != nil {
+	if e.Timeout > 0 {
+		builder.WriteStandard(fmt.Sprintf("Stopped command due to a timeout: %s", e.Err))
+	} else if e.Err != nil {
 		builder.WriteStandard(fmt.Sprintf("Stopped command due to an error: %s", e.Err))
 	} else if err := e.AppsAfter.Err(); err != nil {
 		builder.WriteStandard(fmt.Sprintf("Completed command but %s", err))
@@ -240,6 +283,9 @@ func (e CommandStopped) Message() string {
 	if e.Result.ExitCode != 0 {
 		builder.WriteNote(e.Result.String())
 	}
+	if e.OutputDiscarded > 0 {
+		builder.WriteNote(fmt.Sprintf("%d %s", e.OutputDiscarded, plural(e.OutputDiscarded, "byte", "bytes")), fieldformat.Label("output discarded"))
+	}
 
 	return builder.String()
 }
@@ -258,6 +304,20 @@ func (e CommandStopped) Details() string {
 	default:
 	}
 
+	if e.LogPath != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("Log File: %s", e.LogPath))
+	}
+
+	if e.OutputPath != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("Output File: %s", e.OutputPath))
+	}
+
 	if e.CommandLine != "" {
 		if out.Len() > 0 {
 			out.WriteString("\n\n")
@@ -272,7 +332,14 @@ func (e CommandStopped) Details() string {
 		out.WriteString(e.Output)
 	}
 
-	return out.String()
+	if e.LogTail != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n\n")
+		}
+		out.WriteString(e.LogTail)
+	}
+
+	return withErrorHint(out.String(), e.err())
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -290,6 +357,9 @@ func (e CommandStopped) Attrs() []slog.Attr {
 		slog.Time("started", e.Started),
 		slog.Time("stopped", e.Stopped),
 	)
+	if e.Timeout > 0 {
+		attrs = append(attrs, slog.Duration("timeout", e.Timeout))
+	}
 	if e.WorkingDirectory != "" || e.WorkingDirectoryPath != "" {
 		attrs = append(attrs, slog.Group("working-directory", "id", e.WorkingDirectory, "path", e.WorkingDirectoryPath))
 	}
@@ -307,20 +377,131 @@ func (e CommandStopped) Attrs() []slog.Attr {
 			"still-not-installed", e.AppsAfter.StillNotInstalled,
 			"still-not-uninstalled", e.AppsAfter.StillNotUninstalled))
 	}
+	if e.LogPath != "" || e.LogTail != "" {
+		attrs = append(attrs, slog.Group("log-file", "path", e.LogPath, "tail", e.LogTail))
+	}
 	if e.Output != "" {
 		attrs = append(attrs, slog.String("output", e.Output))
 	}
-	err := e.Err
-	if err == nil {
-		err = e.AppsAfter.Err()
+	if e.OutputDiscarded > 0 {
+		attrs = append(attrs, slog.Int64("output-discarded", e.OutputDiscarded))
 	}
-	if err != nil {
-		attrs = append(attrs, slog.String("error", err.Error()))
+	if e.OutputPath != "" {
+		attrs = append(attrs, slog.String("output-file", e.OutputPath))
+	}
+	if err := e.err(); err != nil {
+		attrs = append(attrs, errorAttrs(err)...)
 	}
 	return attrs
 }
 
+// err returns the error that caused the command to fail, if any. When the
+// command itself succeeded, it returns the error for applications that were
+// not installed or uninstalled properly.
+func (e CommandStopped) err() error {
+	if e.Err != nil {
+		return e.Err
+	}
+	return e.AppsAfter.Err()
+}
+
 // Duration returns the duration of the action.
 func (e CommandStopped) Duration() time.Duration {
 	return e.Stopped.Sub(e.Started)
 }
+
+// InstallerWait is an event that occurs when an msi-based command has
+// waited for another Windows Installer operation to finish before it was
+// run.
+//
+// If the operation did not finish within the timeout, Err is non-nil and
+// the command was not run.
+type InstallerWait struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Package     lbdeploy.PackageID
+	Command     lbdeploy.CommandID
+	Blocker     string
+	Started     time.Time
+	Stopped     time.Time
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e InstallerWait) Type() lbevent.Type {
+	return InstallerWaitType
+}
+
+// Level returns the level of the event.
+func (e InstallerWait) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e InstallerWait) Message() string {
+	var builder structformat.Builder
+
+	duration := e.Duration().Round(time.Second)
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	if e.Package == "" {
+		builder.WritePrimary(string(e.Command))
+	} else {
+		builder.WritePrimary(fmt.Sprintf("%s.%s", e.Package, e.Command))
+	}
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The command was not run because another Windows Installer operation was still in progress after %s: %s.", duration, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The command waited %s for another Windows Installer operation to finish.", duration))
+	}
+
+	if e.Blocker != "" {
+		builder.WriteNote(e.Blocker, fieldformat.Label("blocked by"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e InstallerWait) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e InstallerWait) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+	}
+	if e.Package != "" {
+		attrs = append(attrs, slog.String("package", string(e.Package)))
+	}
+	attrs = append(attrs, slog.Group("command", "id", e.Command))
+	if e.Blocker != "" {
+		attrs = append(attrs, slog.String("blocker", e.Blocker))
+	}
+	attrs = append(attrs,
+		slog.Time("started", e.Started),
+		slog.Time("stopped", e.Stopped))
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// Duration returns the amount of time that was spent waiting.
+func (e InstallerWait) Duration() time.Duration {
+	return e.Stopped.Sub(e.Started)
+}
@@ -0,0 +1,208 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbstate"
+)
+
+// Compliance event types.
+const (
+	ComplianceReportType   = lbevent.Type("deployment:compliance")
+	RemediationStartedType = lbevent.Type("deployment:remediation-started")
+	RemediationHeldType    = lbevent.Type("deployment:remediation-held")
+)
+
+// ComplianceReport is an event that occurs when the compliance of a
+// deployment has been evaluated. It describes any drift from the desired
+// state of the deployment.
+type ComplianceReport struct {
+	Deployment lbdeploy.DeploymentID
+	Report     lbdeploy.ComplianceReport
+}
+
+// Type returns the type of the event.
+func (e ComplianceReport) Type() lbevent.Type {
+	return ComplianceReportType
+}
+
+// Level returns the level of the event.
+func (e ComplianceReport) Level() slog.Level {
+	if !e.Report.Compliant() {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e ComplianceReport) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+
+	checks := len(e.Report.Checks)
+	drift := len(e.Report.Drift())
+	if drift > 0 {
+		builder.WriteStandard(fmt.Sprintf("Drift was detected in %d of %d %s.", drift, checks, plural(checks, "check", "checks")))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("All %d %s are in compliance.", checks, plural(checks, "check", "checks")))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ComplianceReport) Details() string {
+	drift := e.Report.Drift()
+	if len(drift) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(drift))
+	for _, check := range drift {
+		lines = append(lines, check.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ComplianceReport) Attrs() []slog.Attr {
+	drift := e.Report.Drift()
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.Bool("compliant", len(drift) == 0),
+		slog.Int("checks", len(e.Report.Checks)),
+		slog.Int("drift", len(drift)),
+	}
+	if len(drift) > 0 {
+		items := make([]string, 0, len(drift))
+		for _, check := range drift {
+			items = append(items, fmt.Sprintf("%s:%s", check.Kind, check.ID))
+		}
+		attrs = append(attrs, slog.String("drifted", strings.Join(items, ",")))
+	}
+	return attrs
+}
+
+// RemediationStarted is an event that occurs when a flow is invoked to
+// remediate drift that was detected by a compliance evaluation.
+type RemediationStarted struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	Attempt     int
+	MaxAttempts int
+}
+
+// Type returns the type of the event.
+func (e RemediationStarted) Type() lbevent.Type {
+	return RemediationStartedType
+}
+
+// Level returns the level of the event.
+func (e RemediationStarted) Level() slog.Level {
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e RemediationStarted) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WriteStandard("Remediating drift.")
+	builder.WriteNote(fmt.Sprintf("attempt %d of %d", e.Attempt, e.MaxAttempts))
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RemediationStarted) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RemediationStarted) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Int("attempt", e.Attempt),
+		slog.Int("max-attempts", e.MaxAttempts),
+	}
+}
+
+// RemediationHeld is an event that occurs when drift was detected, but a
+// remediation flow was held back by its remediation limits.
+type RemediationHeld struct {
+	Deployment       lbdeploy.DeploymentID
+	Flow             lbdeploy.FlowID
+	Hold             lbstate.RemediationHold
+	ConsecutiveDrift int
+	Attempts         int
+	NextAttempt      time.Time
+}
+
+// Type returns the type of the event.
+func (e RemediationHeld) Type() lbevent.Type {
+	return RemediationHeldType
+}
+
+// Level returns the level of the event.
+func (e RemediationHeld) Level() slog.Level {
+	if e.Hold == lbstate.HoldForMaxAttempts {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e RemediationHeld) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+
+	switch e.Hold {
+	case lbstate.HoldForThreshold:
+		builder.WriteStandard(fmt.Sprintf("Remediation is being held until drift persists. Drift has been detected in %d consecutive %s.", e.ConsecutiveDrift, plural(e.ConsecutiveDrift, "evaluation", "evaluations")))
+	case lbstate.HoldForCooldown:
+		builder.WriteStandard(fmt.Sprintf("Remediation is being held until %s.", e.NextAttempt.Local().Format(time.DateTime)))
+	case lbstate.HoldForMaxAttempts:
+		builder.WriteStandard(fmt.Sprintf("Remediation has been abandoned after %d %s failed to restore compliance.", e.Attempts, plural(e.Attempts, "attempt", "attempts")))
+	default:
+		builder.WriteStandard("Remediation is being held.")
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RemediationHeld) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RemediationHeld) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.String("hold", string(e.Hold)),
+		slog.Int("consecutive-drift", e.ConsecutiveDrift),
+		slog.Int("attempts", e.Attempts),
+	}
+	if !e.NextAttempt.IsZero() {
+		attrs = append(attrs, slog.Time("next-attempt", e.NextAttempt))
+	}
+	return attrs
+}
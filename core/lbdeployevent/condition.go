@@ -1 +1,129 @@
 package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment condition event types.
+const (
+	ConditionObservedType = lbevent.Type("deployment.condition:observed")
+)
+
+// ConditionObserved is an event that occurs when condition tracing is
+// enabled and a condition has been evaluated. It describes what was
+// observed on the local system, so that the outcome of the evaluation can
+// be understood.
+//
+// Condition is empty for conditions that are nested within another
+// condition. ActionType is empty for the constraints and preconditions of
+// a flow.
+type ConditionObserved struct {
+	Deployment    lbdeploy.DeploymentID
+	Flow          lbdeploy.FlowID
+	ActionIndex   int
+	ActionType    lbdeploy.ActionType
+	Use           lbdeploy.ConditionUse
+	Condition     lbdeploy.ConditionID
+	ConditionType lbdeploy.ConditionType
+	Subject       string
+	Expected      string
+	Observed      string
+	Negated       bool
+	Result        bool
+	Err           error
+}
+
+// Type returns the type of the event.
+func (e ConditionObserved) Type() lbevent.Type {
+	return ConditionObservedType
+}
+
+// Level returns the level of the event.
+func (e ConditionObserved) Level() slog.Level {
+	return slog.LevelDebug
+}
+
+// Message returns a description of the event.
+func (e ConditionObserved) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	if e.ActionType != "" {
+		builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+		builder.WritePrimary(string(e.ActionType))
+	}
+
+	name := fmt.Sprintf("nested %s", e.Use)
+	if e.Condition != "" {
+		name = fmt.Sprintf("\"%s\" %s", e.Condition, e.Use)
+	}
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The %s could not be evaluated: %s.", name, e.Err))
+	case e.Result:
+		builder.WriteStandard(fmt.Sprintf("The %s passed.", name))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The %s did not pass.", name))
+	}
+
+	if e.ConditionType != "" {
+		builder.WriteNote(string(e.ConditionType), fieldformat.Label("type"))
+	}
+	if e.Subject != "" {
+		builder.WriteNote(e.Subject, fieldformat.Label("subject"))
+	}
+	if e.Observed != "" {
+		builder.WriteNote(e.Observed, fieldformat.Label("observed"))
+	}
+	if e.Expected != "" {
+		builder.WriteNote(e.Expected, fieldformat.Label("expected"))
+	}
+	if e.Negated {
+		builder.WriteNote("negated")
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ConditionObserved) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ConditionObserved) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+	}
+	if e.ActionType != "" {
+		attrs = append(attrs, slog.Group("action", "index", e.ActionIndex, "type", e.ActionType))
+	}
+	attrs = append(attrs,
+		slog.String("use", e.Use.String()),
+		slog.Group("condition",
+			"id", e.Condition,
+			"type", e.ConditionType,
+			"subject", e.Subject,
+			"expected", e.Expected,
+			"observed", e.Observed,
+			"negated", e.Negated,
+			"result", e.Result,
+		),
+	)
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
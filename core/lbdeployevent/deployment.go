@@ -0,0 +1,205 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment event types.
+const (
+	DeploymentTimeoutType   = lbevent.Type("deployment:timeout")
+	DeploymentCancelledType = lbevent.Type("deployment:cancelled")
+	ProgressUIType          = lbevent.Type("deployment:progress-ui")
+)
+
+// DeploymentTimeout is an event that occurs when a deployment is stopped
+// by its watchdog because it exceeded its timeout.
+type DeploymentTimeout struct {
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	Timeout    time.Duration
+	Started    time.Time
+	Stopped    time.Time
+}
+
+// Type returns the type of the event.
+func (e DeploymentTimeout) Type() lbevent.Type {
+	return DeploymentTimeoutType
+}
+
+// Level returns the level of the event.
+func (e DeploymentTimeout) Level() slog.Level {
+	return slog.LevelError
+}
+
+// Message returns a description of the event.
+func (e DeploymentTimeout) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WriteStandard(fmt.Sprintf("Stopped the deployment because it exceeded its %s timeout.", e.Timeout))
+	builder.WriteNote(e.Duration().Round(time.Millisecond * 10).String())
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e DeploymentTimeout) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e DeploymentTimeout) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Duration("timeout", e.Timeout),
+		slog.Time("started", e.Started),
+		slog.Time("stopped", e.Stopped),
+	}
+}
+
+// Duration returns the amount of time that the deployment ran, including
+// the time it took to stop.
+func (e DeploymentTimeout) Duration() time.Duration {
+	return e.Stopped.Sub(e.Started)
+}
+
+// DeploymentCancelled is an event that occurs when a deployment is stopped
+// because it was cancelled, such as by an interrupt, a service stop or a
+// request made through the control pipe.
+type DeploymentCancelled struct {
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	Started    time.Time
+	Stopped    time.Time
+}
+
+// Type returns the type of the event.
+func (e DeploymentCancelled) Type() lbevent.Type {
+	return DeploymentCancelledType
+}
+
+// Level returns the level of the event.
+func (e DeploymentCancelled) Level() slog.Level {
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e DeploymentCancelled) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WriteStandard("The deployment was cancelled.")
+	builder.WriteNote(e.Duration().Round(time.Millisecond * 10).String())
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e DeploymentCancelled) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e DeploymentCancelled) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Time("started", e.Started),
+		slog.Time("stopped", e.Stopped),
+	}
+}
+
+// Duration returns the amount of time that the deployment ran, including
+// the time it took to stop.
+func (e DeploymentCancelled) Duration() time.Duration {
+	return e.Stopped.Sub(e.Started)
+}
+
+// ProgressUI is an event that occurs when a progress user interface has
+// been started for a deployment, or could not be started.
+//
+// The deployment carries on without a progress user interface if it could
+// not be started, or if no user is signed in to present it to.
+type ProgressUI struct {
+	Deployment lbdeploy.DeploymentID
+	Path       string
+	Pipe       string
+	Session    uint32
+	ProcessID  uint32
+	AsCaller   bool
+	NoSession  bool
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e ProgressUI) Type() lbevent.Type {
+	return ProgressUIType
+}
+
+// Level returns the level of the event.
+func (e ProgressUI) Level() slog.Level {
+	switch {
+	case e.NoSession:
+		return slog.LevelDebug
+	case e.Err != nil:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Message returns a description of the event.
+func (e ProgressUI) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	switch {
+	case e.NoSession:
+		builder.WriteStandard("No user is signed in to the console, so the progress user interface was not started.")
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("Unable to start the progress user interface: %s.", e.Err))
+	case e.AsCaller:
+		builder.WriteStandard(fmt.Sprintf("Started the progress user interface as process %d in the current session %d.", e.ProcessID, e.Session))
+	default:
+		builder.WriteStandard(fmt.Sprintf("Started the progress user interface as process %d in session %d.", e.ProcessID, e.Session))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ProgressUI) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ProgressUI) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.Group("progress-ui", "path", e.Path, "pipe", e.Pipe),
+	}
+	switch {
+	case e.NoSession:
+		attrs = append(attrs, slog.Bool("no-session", true))
+	case e.Err != nil:
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	default:
+		attrs = append(attrs, slog.Group("process", "id", e.ProcessID, "session", e.Session, "as-caller", e.AsCaller))
+	}
+	return attrs
+}
@@ -0,0 +1,89 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment disk space event types.
+const (
+	DiskSpaceInsufficientType = lbevent.Type("deployment.disk-space:insufficient")
+)
+
+// DiskSpaceOperation identifies an operation that requires disk space.
+type DiskSpaceOperation string
+
+// Operations that require disk space.
+const (
+	DiskSpaceForDownload   DiskSpaceOperation = "download"
+	DiskSpaceForExtraction DiskSpaceOperation = "extraction"
+)
+
+// DiskSpaceInsufficient is an event that occurs when a volume does not have
+// enough free space for a download or extraction to proceed.
+type DiskSpaceInsufficient struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Operation   DiskSpaceOperation
+	FileName    string
+	Path        string
+	Available   int64
+	Required    int64
+}
+
+// Type returns the type of the event.
+func (e DiskSpaceInsufficient) Type() lbevent.Type {
+	return DiskSpaceInsufficientType
+}
+
+// Level returns the level of the event.
+func (e DiskSpaceInsufficient) Level() slog.Level {
+	return slog.LevelError
+}
+
+// Message returns a description of the event.
+func (e DiskSpaceInsufficient) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	builder.WriteStandard(fmt.Sprintf("The %s of \"%s\" cannot proceed because the volume holding \"%s\" has %d %s available, but %d %s are required.",
+		e.Operation,
+		e.FileName,
+		e.Path,
+		e.Available,
+		plural(e.Available, "byte", "bytes"),
+		e.Required,
+		plural(e.Required, "byte", "bytes")))
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e DiskSpaceInsufficient) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e DiskSpaceInsufficient) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("operation", string(e.Operation)),
+		slog.String("path", e.Path),
+		slog.Int64("available", e.Available),
+		slog.Int64("required", e.Required),
+	}
+}
@@ -16,6 +16,7 @@ const (
 	DownloadStartedType = lbevent.Type("deployment.download:started")
 	DownloadStoppedType = lbevent.Type("deployment.download:stopped")
 	DownloadResetType   = lbevent.Type("deployment.download:reset")
+	DownloadRetryType   = lbevent.Type("deployment.download:retry")
 )
 
 // DownloadStarted is an event that occurs when a file download has started.
@@ -76,7 +77,33 @@ func (e DownloadStarted) Attrs() []slog.Attr {
 	}
 }
 
+// DownloadOutcome describes the outcome of a request that did not transfer
+// the content of a file, because a cached copy of the file was revalidated
+// with its source instead.
+type DownloadOutcome string
+
+// Possible outcomes of cache revalidation.
+const (
+	// DownloadTransferred indicates that file content was transferred.
+	DownloadTransferred DownloadOutcome = ""
+
+	// DownloadCacheRevalidated indicates that the source confirmed that the
+	// cached copy of a file is still current.
+	DownloadCacheRevalidated DownloadOutcome = "cache-revalidated"
+
+	// DownloadCacheStale indicates that the source reported that the file
+	// has changed since the cached copy was downloaded.
+	DownloadCacheStale DownloadOutcome = "cache-stale"
+
+	// DownloadCacheUnverified indicates that the cached copy of a file
+	// could not be revalidated because the request failed.
+	DownloadCacheUnverified DownloadOutcome = "cache-unverified"
+)
+
 // DownloadStopped is an event that occurs when a file download has stopped.
+//
+// If Outcome is not empty, the event describes the revalidation of a
+// cached file with its source, and no file content was transferred.
 type DownloadStopped struct {
 	Deployment  lbdeploy.DeploymentID
 	Flow        lbdeploy.FlowID
@@ -89,6 +116,7 @@ type DownloadStopped struct {
 	FileSize    int64
 	Started     time.Time
 	Stopped     time.Time
+	Outcome     DownloadOutcome
 	Err         error
 }
 
@@ -99,6 +127,12 @@ func (e DownloadStopped) Type() lbevent.Type {
 
 // Level returns the level of the event.
 func (e DownloadStopped) Level() slog.Level {
+	switch e.Outcome {
+	case DownloadCacheRevalidated:
+		return slog.LevelInfo
+	case DownloadCacheStale, DownloadCacheUnverified:
+		return slog.LevelWarn
+	}
 	if e.Err != nil {
 		return slog.LevelError
 	}
@@ -115,7 +149,16 @@ func (e DownloadStopped) Message() string {
 	builder.WritePrimary(string(e.Flow))
 	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
 	builder.WritePrimary("download-package")
-	if e.Err != nil {
+	if e.Outcome != DownloadTransferred {
+		switch e.Outcome {
+		case DownloadCacheUnverified:
+			builder.WriteStandard(fmt.Sprintf("The cached copy of \"%s\" could not be revalidated with \"%s\" due to an error: %s. The cached copy will be used.", e.FileName, e.Source.URL, e.Err))
+		case DownloadCacheStale:
+			builder.WriteStandard(fmt.Sprintf("The source \"%s\" reports that \"%s\" has changed since it was cached. The cached copy still matches the deployment and will be used.", e.Source.URL, e.FileName))
+		default:
+			builder.WriteStandard(fmt.Sprintf("The cached copy of \"%s\" was revalidated with \"%s\" in %s.", e.FileName, e.Source.URL, duration))
+		}
+	} else if e.Err != nil {
 		if e.Downloaded > 0 {
 			builder.WriteStandard(fmt.Sprintf("The download of \"%s\" from \"%s\" failed after receiving %d %s over %s (%s mbps) due to an error: %s.",
 				e.FileName,
@@ -139,7 +182,7 @@ func (e DownloadStopped) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e DownloadStopped) Details() string {
-	return ""
+	return withErrorHint("", e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -156,8 +199,11 @@ func (e DownloadStopped) Attrs() []slog.Attr {
 		slog.Time("started", e.Started),
 		slog.Time("stopped", e.Stopped),
 	}
+	if e.Outcome != DownloadTransferred {
+		attrs = append(attrs, slog.String("outcome", string(e.Outcome)))
+	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
 	}
 	return attrs
 }
@@ -270,3 +316,71 @@ func (e DownloadReset) Attrs() []slog.Attr {
 	}
 	return attrs
 }
+
+// DownloadRetry is an event that occurs when a download attempt has failed
+// with a transient error and another attempt will be made after a delay.
+type DownloadRetry struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Source      lbdeploy.PackageSource
+	FileName    string
+	Attempt     int
+	MaxAttempts int
+	Delay       time.Duration
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e DownloadRetry) Type() lbevent.Type {
+	return DownloadRetryType
+}
+
+// Level returns the level of the event.
+func (e DownloadRetry) Level() slog.Level {
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e DownloadRetry) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	builder.WriteStandard(fmt.Sprintf("Attempt %d of %d to download \"%s\" from \"%s\" failed due to an error: %s. Retrying in %s.",
+		e.Attempt,
+		e.MaxAttempts,
+		e.FileName,
+		e.Source.URL,
+		e.Err,
+		e.Delay.Round(time.Millisecond)))
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e DownloadRetry) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e DownloadRetry) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("source", "type", string(e.Source.Type), "url", e.Source.URL),
+		slog.Int("attempt", e.Attempt),
+		slog.Int("max-attempts", e.MaxAttempts),
+		slog.Duration("delay", e.Delay),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,31 @@
+package lbdeployevent
+
+import (
+	"log/slog"
+
+	"github.com/leafbridge/leafbridge/core/lberror"
+)
+
+// errorAttrs returns structured log attributes that describe err. When the
+// category of err is known, it is included as well.
+func errorAttrs(err error) []slog.Attr {
+	attrs := []slog.Attr{slog.String("error", err.Error())}
+	if category := lberror.CategoryOf(err); category != lberror.Unknown {
+		attrs = append(attrs, slog.String("error-category", string(category)))
+	}
+	return attrs
+}
+
+// withErrorHint returns details with a remediation hint for err placed in
+// front of it. If no hint is known for err, details is returned unmodified.
+func withErrorHint(details string, err error) string {
+	hint := lberror.HintOf(err)
+	switch {
+	case hint == "":
+		return details
+	case details == "":
+		return "Hint: " + hint
+	default:
+		return "Hint: " + hint + "\n\n" + details
+	}
+}
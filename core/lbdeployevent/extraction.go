@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gentlemanautomaton/structformat"
@@ -13,8 +14,12 @@ import (
 
 // Deployment extraction event types.
 const (
-	ExtractionStartedType = lbevent.Type("deployment.extraction:started")
-	ExtractionStoppedType = lbevent.Type("deployment.extraction:stopped")
+	ExtractionStartedType     = lbevent.Type("deployment.extraction:started")
+	ExtractionStoppedType     = lbevent.Type("deployment.extraction:stopped")
+	ExtractionUnsupportedType = lbevent.Type("deployment.extraction:unsupported")
+	ExtractionRejectedType    = lbevent.Type("deployment.extraction:rejected")
+	ExtractionSlowedType      = lbevent.Type("deployment.extraction:slowed")
+	ExtractionQuotaType       = lbevent.Type("deployment.extraction:quota-exceeded")
 )
 
 // ExtractionStats holds information about of files that are being extracted.
@@ -27,6 +32,11 @@ type ExtractionStats struct {
 	TotalBytes  int64
 }
 
+// IsZero returns true if the stats don't include any files or directories.
+func (stats ExtractionStats) IsZero() bool {
+	return stats.Files == 0 && stats.Directories == 0
+}
+
 // String returns a string representation of the stats in the form
 // "100 files and 1000 directories".
 func (stats ExtractionStats) String() string {
@@ -43,8 +53,8 @@ func (stats ExtractionStats) String() string {
 			plural(stats.Files, "file", "files"))
 	case stats.Directories > 0:
 		return fmt.Sprintf("%d %s",
-			stats.Files,
-			plural(stats.Files, "file", "files"))
+			stats.Directories,
+			plural(stats.Directories, "directory", "directories"))
 	default:
 		return "no files and no directories"
 	}
@@ -60,6 +70,9 @@ type ExtractionStarted struct {
 	SourcePath      string
 	DestinationPath string
 	SourceStats     ExtractionStats
+	SkippedStats    ExtractionStats
+	MaxFiles        int
+	MaxBytes        int64
 }
 
 // Type returns the type of the event.
@@ -81,6 +94,12 @@ func (e ExtractionStarted) Message() string {
 	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
 	builder.WritePrimary("extract-package")
 	builder.WriteStandard(fmt.Sprintf("Starting extraction of %s contained in the \"%s\" archive to \"%s\".", e.SourceStats, e.SourcePath, e.DestinationPath))
+	if !e.SkippedStats.IsZero() {
+		builder.WriteNote(fmt.Sprintf("skipping %s", e.SkippedStats))
+	}
+	if quota := quotaString(e.MaxFiles, e.MaxBytes); quota != "" {
+		builder.WriteNote(fmt.Sprintf("quota %s", quota))
+	}
 
 	return builder.String()
 }
@@ -94,13 +113,20 @@ func (e ExtractionStarted) Details() string {
 
 // Attrs returns a set of structured log attributes for the event.
 func (e ExtractionStarted) Attrs() []slog.Attr {
-	return []slog.Attr{
+	attrs := []slog.Attr{
 		slog.String("deployment", string(e.Deployment)),
 		slog.String("flow", string(e.Flow)),
 		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
 		slog.Group("source", "path", e.SourcePath, slog.Group("stats", "files", e.SourceStats.Files, "directories", e.SourceStats.Directories, "total-bytes", e.SourceStats.TotalBytes)),
 		slog.Group("destination", "path", e.DestinationPath),
 	}
+	if !e.SkippedStats.IsZero() {
+		attrs = append(attrs, slog.Group("skipped", "files", e.SkippedStats.Files, "directories", e.SkippedStats.Directories, "total-bytes", e.SkippedStats.TotalBytes))
+	}
+	if e.MaxFiles > 0 || e.MaxBytes > 0 {
+		attrs = append(attrs, slog.Group("quota", "max-files", e.MaxFiles, "max-bytes", e.MaxBytes))
+	}
+	return attrs
 }
 
 // ExtractionStopped is an event that occurs when archive extraction has
@@ -113,6 +139,7 @@ type ExtractionStopped struct {
 	SourcePath       string
 	DestinationPath  string
 	SourceStats      ExtractionStats
+	SkippedStats     ExtractionStats
 	DestinationStats ExtractionStats
 	Started          time.Time
 	Stopped          time.Time
@@ -151,6 +178,9 @@ func (e ExtractionStopped) Message() string {
 	} else {
 		builder.WriteStandard(fmt.Sprintf("The extraction of %s from \"%s\" to \"%s\" was completed in %s (%s mbps).", e.SourceStats, e.SourcePath, e.DestinationPath, duration, e.BitrateInMbps()))
 	}
+	if !e.SkippedStats.IsZero() {
+		builder.WriteNote(fmt.Sprintf("skipped %s", e.SkippedStats))
+	}
 
 	return builder.String()
 }
@@ -159,7 +189,7 @@ func (e ExtractionStopped) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e ExtractionStopped) Details() string {
-	return ""
+	return withErrorHint("", e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -173,8 +203,11 @@ func (e ExtractionStopped) Attrs() []slog.Attr {
 		slog.Time("started", e.Started),
 		slog.Time("stopped", e.Stopped),
 	}
+	if !e.SkippedStats.IsZero() {
+		attrs = append(attrs, slog.Group("skipped", "files", e.SkippedStats.Files, "directories", e.SkippedStats.Directories, "total-bytes", e.SkippedStats.TotalBytes))
+	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
 	}
 	return attrs
 }
@@ -188,3 +221,288 @@ func (e ExtractionStopped) Duration() time.Duration {
 func (e ExtractionStopped) BitrateInMbps() string {
 	return bitrate(e.DestinationStats.TotalBytes, e.Duration())
 }
+
+// ExtractionUnsupported is an event that occurs when an archive cannot be
+// extracted because one of its entries relies on an unsupported feature,
+// such as Deflate64 compression or encryption.
+type ExtractionUnsupported struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	SourcePath  string
+	EntryPath   string
+	Feature     string
+}
+
+// Type returns the type of the event.
+func (e ExtractionUnsupported) Type() lbevent.Type {
+	return ExtractionUnsupportedType
+}
+
+// Level returns the level of the event.
+func (e ExtractionUnsupported) Level() slog.Level {
+	return slog.LevelError
+}
+
+// Message returns a description of the event.
+func (e ExtractionUnsupported) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary("extract-package")
+	builder.WriteStandard(fmt.Sprintf("The \"%s\" archive cannot be extracted because its \"%s\" entry uses %s, which is not supported.", e.SourcePath, e.EntryPath, e.Feature))
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ExtractionUnsupported) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ExtractionUnsupported) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("source", "path", e.SourcePath),
+		slog.String("entry", e.EntryPath),
+		slog.String("feature", e.Feature),
+	}
+}
+
+// ExtractionViolation describes an archive entry with an unsafe path.
+type ExtractionViolation struct {
+	Entry  string
+	Reason string
+}
+
+// ExtractionRejected is an event that occurs when an archive is rejected
+// because some of its entries have paths that could escape the extraction
+// directory. Such archives are treated as a security concern, because
+// extraction often runs with elevated privileges.
+type ExtractionRejected struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	SourcePath  string
+	Violations  []ExtractionViolation
+}
+
+// Type returns the type of the event.
+func (e ExtractionRejected) Type() lbevent.Type {
+	return ExtractionRejectedType
+}
+
+// Level returns the level of the event.
+func (e ExtractionRejected) Level() slog.Level {
+	return slog.LevelError
+}
+
+// Message returns a description of the event.
+func (e ExtractionRejected) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary("extract-package")
+	builder.WriteStandard(fmt.Sprintf("SECURITY: The \"%s\" archive was rejected because %d %s unsafe %s that could escape the extraction directory.",
+		e.SourcePath,
+		len(e.Violations),
+		plural(len(e.Violations), "entry has an", "entries have"),
+		plural(len(e.Violations), "path", "paths")))
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ExtractionRejected) Details() string {
+	var lines []string
+	for _, violation := range e.Violations {
+		lines = append(lines, fmt.Sprintf("%s: %s", violation.Entry, violation.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ExtractionRejected) Attrs() []slog.Attr {
+	entries := make([]any, 0, len(e.Violations))
+	for i, violation := range e.Violations {
+		entries = append(entries, slog.Group(strconv.Itoa(i), "path", violation.Entry, "reason", violation.Reason))
+	}
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("source", "path", e.SourcePath),
+		slog.Group("entries", entries...),
+		slog.String("category", "security"),
+	}
+}
+
+// ExtractionSlowed is an event that occurs when the throughput of an
+// extraction collapses partway through. This is often caused by real-time
+// antivirus scanning of the extracted files.
+type ExtractionSlowed struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	SourcePath  string
+	PeakBytes   int64
+	PeakTime    time.Duration
+	RecentBytes int64
+	RecentTime  time.Duration
+	Excluded    bool
+}
+
+// Type returns the type of the event.
+func (e ExtractionSlowed) Type() lbevent.Type {
+	return ExtractionSlowedType
+}
+
+// Level returns the level of the event.
+func (e ExtractionSlowed) Level() slog.Level {
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e ExtractionSlowed) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary("extract-package")
+	builder.WriteStandard(fmt.Sprintf("The extraction of \"%s\" slowed from %s mbps to %s mbps.", e.SourcePath, e.PeakBitrateInMbps(), e.RecentBitrateInMbps()))
+	if !e.Excluded {
+		builder.WriteNote("real-time antivirus scanning might be interfering")
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ExtractionSlowed) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ExtractionSlowed) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("source", "path", e.SourcePath),
+		slog.Group("peak", "bytes", e.PeakBytes, "duration", e.PeakTime),
+		slog.Group("recent", "bytes", e.RecentBytes, "duration", e.RecentTime),
+		slog.Bool("excluded", e.Excluded),
+	}
+}
+
+// PeakBitrateInMbps returns the peak bitrate of the extraction in mebibits
+// per second.
+func (e ExtractionSlowed) PeakBitrateInMbps() string {
+	return bitrate(e.PeakBytes, e.PeakTime)
+}
+
+// RecentBitrateInMbps returns the recent bitrate of the extraction in
+// mebibits per second.
+func (e ExtractionSlowed) RecentBitrateInMbps() string {
+	return bitrate(e.RecentBytes, e.RecentTime)
+}
+
+// ExtractionQuotaExceeded is an event that occurs when the extraction of an
+// archive has been stopped because its files exceed the extraction quota
+// of its package.
+//
+// If Declared is true, the quota was exceeded by the sizes declared in the
+// archive, and nothing was extracted. Otherwise the quota was exceeded by
+// the files that were written, and Files and TotalBytes hold the amount
+// that had been extracted when the extraction stopped.
+type ExtractionQuotaExceeded struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	SourcePath  string
+	Declared    bool
+	MaxFiles    int
+	MaxBytes    int64
+	Files       int
+	TotalBytes  int64
+}
+
+// Type returns the type of the event.
+func (e ExtractionQuotaExceeded) Type() lbevent.Type {
+	return ExtractionQuotaType
+}
+
+// Level returns the level of the event.
+func (e ExtractionQuotaExceeded) Level() slog.Level {
+	return slog.LevelError
+}
+
+// Message returns a description of the event.
+func (e ExtractionQuotaExceeded) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary("extract-package")
+
+	observed := fmt.Sprintf("%d %s totaling %d %s", e.Files, plural(e.Files, "file", "files"), e.TotalBytes, plural(e.TotalBytes, "byte", "bytes"))
+	if e.Declared {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" archive was rejected because it declares %s, which exceeds the package's quota of %s.", e.SourcePath, observed, quotaString(e.MaxFiles, e.MaxBytes)))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The extraction of the \"%s\" archive was stopped after %s, which exceeds the package's quota of %s.", e.SourcePath, observed, quotaString(e.MaxFiles, e.MaxBytes)))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ExtractionQuotaExceeded) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ExtractionQuotaExceeded) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("source", e.SourcePath),
+		slog.Bool("declared", e.Declared),
+		slog.Group("quota", "max-files", e.MaxFiles, "max-bytes", e.MaxBytes),
+		slog.Group("observed", "files", e.Files, "total-bytes", e.TotalBytes),
+	}
+}
+
+// quotaString returns a description of an extraction quota, such as
+// "110 files and 1100 bytes". Limits that are zero are omitted.
+func quotaString(maxFiles int, maxBytes int64) string {
+	var limits []string
+	if maxFiles > 0 {
+		limits = append(limits, fmt.Sprintf("%d %s", maxFiles, plural(maxFiles, "file", "files")))
+	}
+	if maxBytes > 0 {
+		limits = append(limits, fmt.Sprintf("%d %s", maxBytes, plural(maxBytes, "byte", "bytes")))
+	}
+	return strings.Join(limits, " and ")
+}
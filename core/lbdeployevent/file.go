@@ -18,6 +18,9 @@ const (
 	FileVerificationType = lbevent.Type("deployment.file:verification")
 	FileCopyType         = lbevent.Type("deployment.file:copy")
 	FileDeleteType       = lbevent.Type("deployment.file:delete")
+	FileIntegrityType    = lbevent.Type("deployment.file:integrity")
+	FileBackupType       = lbevent.Type("deployment.file:backup")
+	FileRestoreType      = lbevent.Type("deployment.file:restore")
 )
 
 // FileExtraction is an event that occurs when an archived file has been
@@ -60,7 +63,7 @@ func (e FileExtraction) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e FileExtraction) Details() string {
-	return ""
+	return withErrorHint("", e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -76,7 +79,7 @@ func (e FileExtraction) Attrs() []slog.Attr {
 		slog.Time("stopped", e.Stopped),
 	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
 	}
 	return attrs
 }
@@ -103,6 +106,12 @@ type FileVerification struct {
 	Path        string
 	Expected    lbdeploy.FileAttributes
 	Actual      lbdeploy.FileAttributes
+
+	// Cached is true when the actual attributes were taken from a
+	// verification record written by an earlier run, at the time given by
+	// Verified, instead of hashing the file content again.
+	Cached   bool
+	Verified time.Time
 }
 
 // Type returns the type of the event.
@@ -115,7 +124,7 @@ func (e FileVerification) Level() slog.Level {
 	if len(e.Expected.Features()) == 0 {
 		return slog.LevelWarn
 	}
-	if !lbdeploy.EqualFileAttributes(e.Expected, e.Actual) {
+	if !lbdeploy.MatchFileAttributes(e.Expected, e.Actual) {
 		return slog.LevelError
 	}
 	if len(e.Expected.Hashes) == 0 {
@@ -135,10 +144,12 @@ func (e FileVerification) Message() string {
 
 	if len(e.Expected.Features()) == 0 {
 		builder.WriteStandard(fmt.Sprintf("The \"%s\" file could not be verified because file verification data was not provided.", e.FileName))
-	} else if !lbdeploy.EqualFileAttributes(e.Expected, e.Actual) {
+	} else if !lbdeploy.MatchFileAttributes(e.Expected, e.Actual) {
 		builder.WriteStandard(fmt.Sprintf("The \"%s\" file does not have the expected file attributes and has failed verification.", e.FileName))
 	} else if len(e.Expected.Hashes) == 0 {
 		builder.WriteStandard(fmt.Sprintf("The \"%s\" file has the expected file size, but no file hashes were provided for verification.", e.FileName))
+	} else if e.Cached {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" file was verified with the following features, based on a verification record from %s: %s.", e.FileName, e.Verified.Format(time.RFC3339), strings.Join(e.Actual.Features(), ", ")))
 	} else {
 		builder.WriteStandard(fmt.Sprintf("The \"%s\" file was verified with the following features: %s.", e.FileName, strings.Join(e.Actual.Features(), ", ")))
 	}
@@ -168,9 +179,51 @@ func (e FileVerification) Attrs() []slog.Attr {
 	}
 	attrs = append(attrs, slog.Group("expected", "size", e.Expected.Size, "hashes", e.Expected.Hashes))
 	attrs = append(attrs, slog.Group("actual", "size", e.Actual.Size, "hashes", e.Actual.Hashes))
+	if e.Cached {
+		attrs = append(attrs, slog.Time("verified", e.Verified))
+	}
 	return attrs
 }
 
+// FileLocker describes a process that holds a file open, preventing it
+// from being changed.
+type FileLocker struct {
+	ProcessID uint32
+	Name      string
+	Service   string
+}
+
+// String returns a string representation of the process, in the form
+// "name (pid 1234)".
+func (locker FileLocker) String() string {
+	name := locker.Name
+	if locker.Service != "" {
+		name = fmt.Sprintf("%s service", locker.Service)
+	}
+	if name == "" {
+		return fmt.Sprintf("pid %d", locker.ProcessID)
+	}
+	return fmt.Sprintf("%s (pid %d)", name, locker.ProcessID)
+}
+
+// fileLockerDetails returns a line for each locker.
+func fileLockerDetails(lockers []FileLocker) string {
+	lines := make([]string, len(lockers))
+	for i, locker := range lockers {
+		lines[i] = "In use by " + locker.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fileLockerAttr returns a structured log attribute for a set of lockers.
+func fileLockerAttr(lockers []FileLocker) slog.Attr {
+	members := make([]any, len(lockers))
+	for i, locker := range lockers {
+		members[i] = slog.Group(strconv.Itoa(i), "pid", locker.ProcessID, "name", locker.Name, "service", locker.Service)
+	}
+	return slog.Group("lockers", members...)
+}
+
 // FileCopy is an event that occurs when a file is copied.
 type FileCopy struct {
 	Deployment         lbdeploy.DeploymentID
@@ -182,6 +235,9 @@ type FileCopy struct {
 	DestinationID      lbdeploy.FileResourceID
 	DestinationPath    string
 	DestinationExisted bool
+	Replaced           bool
+	Lockers            []FileLocker
+	ScheduledForReboot bool
 	FileSize           int64
 	Started            time.Time
 	Stopped            time.Time
@@ -225,6 +281,10 @@ func (e FileCopy) Message() string {
 	}
 	if e.Err != nil {
 		builder.WriteStandard(fmt.Sprintf("The file copy from %s to %s failed due to an error: %s.", from, to, e.Err))
+	} else if e.ScheduledForReboot {
+		builder.WriteStandard(fmt.Sprintf("The file copy from %s to %s will be completed when the system restarts, because the destination is in use.", from, to))
+	} else if e.Replaced {
+		builder.WriteStandard(fmt.Sprintf("The file copy from %s to %s replaced the existing file in %s (%s mbps).", from, to, duration, e.BitrateInMbps()))
 	} else if !e.DestinationExisted {
 		builder.WriteStandard(fmt.Sprintf("The file copy from %s to %s was completed in %s (%s mbps).", from, to, duration, e.BitrateInMbps()))
 	} else {
@@ -238,7 +298,7 @@ func (e FileCopy) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e FileCopy) Details() string {
-	return ""
+	return withErrorHint(fileLockerDetails(e.Lockers), e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -248,13 +308,19 @@ func (e FileCopy) Attrs() []slog.Attr {
 		slog.String("flow", string(e.Flow)),
 		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
 		slog.Group("source", "path", e.SourcePath),
-		slog.Group("destination", "path", e.DestinationPath, "existed", e.DestinationExisted),
+		slog.Group("destination", "path", e.DestinationPath, "existed", e.DestinationExisted, "replaced", e.Replaced),
 		slog.Group("file", "size", e.FileSize),
 		slog.Time("started", e.Started),
 		slog.Time("stopped", e.Stopped),
 	}
+	if len(e.Lockers) > 0 {
+		attrs = append(attrs, fileLockerAttr(e.Lockers))
+	}
+	if e.ScheduledForReboot {
+		attrs = append(attrs, slog.Bool("scheduled-for-reboot", true))
+	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
 	}
 	return attrs
 }
@@ -271,17 +337,19 @@ func (e FileCopy) BitrateInMbps() string {
 
 // FileDelete is an event that occurs when a file is deleted.
 type FileDelete struct {
-	Deployment  lbdeploy.DeploymentID
-	Flow        lbdeploy.FlowID
-	ActionIndex int
-	ActionType  lbdeploy.ActionType
-	FileID      lbdeploy.FileResourceID
-	FilePath    string
-	FileSize    int64
-	FileExisted bool
-	Started     time.Time
-	Stopped     time.Time
-	Err         error
+	Deployment         lbdeploy.DeploymentID
+	Flow               lbdeploy.FlowID
+	ActionIndex        int
+	ActionType         lbdeploy.ActionType
+	FileID             lbdeploy.FileResourceID
+	FilePath           string
+	FileSize           int64
+	FileExisted        bool
+	Lockers            []FileLocker
+	ScheduledForReboot bool
+	Started            time.Time
+	Stopped            time.Time
+	Err                error
 }
 
 // Type returns the type of the event.
@@ -316,6 +384,8 @@ func (e FileDelete) Message() string {
 	}
 	if e.Err != nil {
 		builder.WriteStandard(fmt.Sprintf("Deletion of %s failed due to an error: %s.", from, e.Err))
+	} else if e.ScheduledForReboot {
+		builder.WriteStandard(fmt.Sprintf("Deletion of %s will be completed when the system restarts, because the file is in use.", from))
 	} else if e.FileExisted {
 		builder.WriteStandard(fmt.Sprintf("Deletion of %s was completed in %s (%s mbps).", from, duration, e.BitrateInMbps()))
 	} else {
@@ -329,7 +399,7 @@ func (e FileDelete) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e FileDelete) Details() string {
-	return ""
+	return withErrorHint(fileLockerDetails(e.Lockers), e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -342,8 +412,14 @@ func (e FileDelete) Attrs() []slog.Attr {
 		slog.Time("started", e.Started),
 		slog.Time("stopped", e.Stopped),
 	}
+	if len(e.Lockers) > 0 {
+		attrs = append(attrs, fileLockerAttr(e.Lockers))
+	}
+	if e.ScheduledForReboot {
+		attrs = append(attrs, slog.Bool("scheduled-for-reboot", true))
+	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
 	}
 	return attrs
 }
@@ -357,3 +433,236 @@ func (e FileDelete) Duration() time.Duration {
 func (e FileDelete) BitrateInMbps() string {
 	return bitrate(e.FileSize, e.Duration())
 }
+
+// FileIntegrity is an event that records the result of checking a file's
+// integrity requirements before it is run.
+type FileIntegrity struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	FileID      lbdeploy.FileResourceID
+	FilePath    string
+	Required    lbdeploy.FileIntegrity
+	Actual      lbdeploy.FileAttributes
+	Signer      string
+	Thumbprint  string
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e FileIntegrity) Type() lbevent.Type {
+	return FileIntegrityType
+}
+
+// Level returns the level of the event.
+func (e FileIntegrity) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e FileIntegrity) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" file failed its integrity check and will not be run: %s.", e.FileID, e.Err))
+	} else if e.Signer != "" {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" file passed its integrity check and is signed by \"%s\".", e.FileID, e.Signer))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" file passed its integrity check.", e.FileID))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e FileIntegrity) Details() string {
+	if e.FilePath == "" {
+		return withErrorHint("", e.Err)
+	}
+	return withErrorHint(fmt.Sprintf("Path: %s", e.FilePath), e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e FileIntegrity) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("file", "id", e.FileID, "path", e.FilePath),
+		slog.Group("expected", "hashes", e.Required.Hashes, "signed", e.Required.Signature.Required),
+		slog.Group("actual", "hashes", e.Actual.Hashes, "signer", e.Signer, "thumbprint", e.Thumbprint),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// FileBackup is an event that occurs when a file has been backed up, either
+// by a backup-file action or automatically before a flow changes it.
+type FileBackup struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	FileID      lbdeploy.FileResourceID
+	FilePath    string
+	FileExisted bool
+	FileSize    int64
+	BackupPath  string
+	Automatic   bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e FileBackup) Type() lbevent.Type {
+	return FileBackupType
+}
+
+// Level returns the level of the event.
+func (e FileBackup) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e FileBackup) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	var file string
+	if e.FilePath != "" {
+		file = fmt.Sprintf("%s (%s)", e.FileID, e.FilePath)
+	} else {
+		file = string(e.FileID)
+	}
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The backup of %s failed due to an error: %s.", file, e.Err))
+	case !e.FileExisted:
+		builder.WriteStandard(fmt.Sprintf("Recorded that %s does not exist, so that it can be removed if the backup is restored.", file))
+	default:
+		builder.WriteStandard(fmt.Sprintf("Backed up %s to \"%s\" (%d %s).", file, e.BackupPath, e.FileSize, plural(e.FileSize, "byte", "bytes")))
+	}
+	if e.Automatic {
+		builder.WriteNote("automatic")
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e FileBackup) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e FileBackup) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("file", "id", e.FileID, "path", e.FilePath, "size", e.FileSize, "existed", e.FileExisted),
+		slog.Group("backup", "path", e.BackupPath, "automatic", e.Automatic),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// FileRestore is an event that occurs when a file has been restored from
+// a backup.
+type FileRestore struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	FileID      lbdeploy.FileResourceID
+	FilePath    string
+	FileExisted bool
+	BackupPath  string
+	BackupTime  time.Time
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e FileRestore) Type() lbevent.Type {
+	return FileRestoreType
+}
+
+// Level returns the level of the event.
+func (e FileRestore) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e FileRestore) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	var file string
+	if e.FilePath != "" {
+		file = fmt.Sprintf("%s (%s)", e.FileID, e.FilePath)
+	} else {
+		file = string(e.FileID)
+	}
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The restoration of %s failed due to an error: %s.", file, e.Err))
+	case !e.FileExisted:
+		builder.WriteStandard(fmt.Sprintf("Removed %s, which did not exist when it was backed up.", file))
+	default:
+		builder.WriteStandard(fmt.Sprintf("Restored %s from \"%s\".", file, e.BackupPath))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e FileRestore) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e FileRestore) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("file", "id", e.FileID, "path", e.FilePath, "existed", e.FileExisted),
+		slog.Group("backup", "path", e.BackupPath, "time", e.BackupTime),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,72 @@
+package lbdeployevent_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+)
+
+func TestFileLockerString(t *testing.T) {
+	fixtures := []struct {
+		Locker lbdeployevent.FileLocker
+		Want   string
+	}{
+		{Locker: lbdeployevent.FileLocker{ProcessID: 4120, Name: "notepad.exe"}, Want: "notepad.exe (pid 4120)"},
+		{Locker: lbdeployevent.FileLocker{ProcessID: 812, Name: "svchost.exe", Service: "Spooler"}, Want: "Spooler service (pid 812)"},
+		{Locker: lbdeployevent.FileLocker{ProcessID: 5012}, Want: "pid 5012"},
+	}
+	for _, fixture := range fixtures {
+		if got := fixture.Locker.String(); got != fixture.Want {
+			t.Errorf("got %q, want %q", got, fixture.Want)
+		}
+	}
+}
+
+func TestFileBackup(t *testing.T) {
+	fixtures := []struct {
+		Name    string
+		Event   lbdeployevent.FileBackup
+		Level   slog.Level
+		Message string
+	}{
+		{
+			Name: "backed-up",
+			Event: lbdeployevent.FileBackup{
+				Deployment: "app", Flow: "install", ActionType: "backup-file",
+				FileID: "app-config", FilePath: `C:\App\app.json`, FileExisted: true, FileSize: 1,
+				BackupPath: `C:\ProgramData\LeafBridge\Backup\app\run\app-config.bak`,
+			},
+			Level:   slog.LevelInfo,
+			Message: `app: install: 1: backup-file: Backed up app-config (C:\App\app.json) to "C:\ProgramData\LeafBridge\Backup\app\run\app-config.bak" (1 byte).`,
+		},
+		{
+			Name: "absent-automatic",
+			Event: lbdeployevent.FileBackup{
+				Deployment: "app", Flow: "install", ActionIndex: 2, ActionType: "copy-file",
+				FileID: "app-config", Automatic: true,
+			},
+			Level:   slog.LevelInfo,
+			Message: "app: install: 3: copy-file: Recorded that app-config does not exist, so that it can be removed if the backup is restored. (automatic)",
+		},
+		{
+			Name: "error",
+			Event: lbdeployevent.FileBackup{
+				Deployment: "app", Flow: "install", ActionType: "backup-file",
+				FileID: "app-config", Err: errors.New("access is denied"),
+			},
+			Level:   slog.LevelError,
+			Message: "app: install: 1: backup-file: The backup of app-config failed due to an error: access is denied.",
+		},
+	}
+
+	for _, fixture := range fixtures {
+		if got := fixture.Event.Level(); got != fixture.Level {
+			t.Errorf("%s: got level %s, want %s", fixture.Name, got, fixture.Level)
+		}
+		if got := fixture.Event.Message(); got != fixture.Message {
+			t.Errorf("%s: got message %q, want %q", fixture.Name, got, fixture.Message)
+		}
+	}
+}
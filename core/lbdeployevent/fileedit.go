@@ -0,0 +1,110 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment file edit event types.
+const (
+	FileEditType = lbevent.Type("deployment.file:edit")
+)
+
+// FileEdit is an event that occurs when a configuration file has been
+// edited.
+type FileEdit struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	FileID      lbdeploy.FileResourceID
+	FilePath    string
+	Format      lbdeploy.ConfigFormat
+	Edits       int
+	Created     bool
+	Changed     bool
+	Started     time.Time
+	Stopped     time.Time
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e FileEdit) Type() lbevent.Type {
+	return FileEditType
+}
+
+// Level returns the level of the event.
+func (e FileEdit) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e FileEdit) Message() string {
+	var builder structformat.Builder
+
+	duration := e.Duration().Round(time.Millisecond * 10)
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	var file string
+	if e.FilePath != "" {
+		file = fmt.Sprintf("%s (%s)", e.FileID, e.FilePath)
+	} else {
+		file = string(e.FileID)
+	}
+	edits := fmt.Sprintf("%d %s %s", e.Edits, e.Format, plural(e.Edits, "edit", "edits"))
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("Applying %s to %s failed due to an error: %s.", edits, file, e.Err))
+	case e.Created:
+		builder.WriteStandard(fmt.Sprintf("Created %s with %s in %s.", file, edits, duration))
+	case e.Changed:
+		builder.WriteStandard(fmt.Sprintf("Applied %s to %s in %s.", edits, file, duration))
+	default:
+		builder.WriteStandard(fmt.Sprintf("Applying %s to %s was unnecessary as the file already had the expected values.", edits, file))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e FileEdit) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e FileEdit) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("file", "id", e.FileID, "path", e.FilePath, "created", e.Created, "changed", e.Changed),
+		slog.String("format", string(e.Format)),
+		slog.Int("edits", e.Edits),
+		slog.Time("started", e.Started),
+		slog.Time("stopped", e.Stopped),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// Duration returns the duration of the file edit.
+func (e FileEdit) Duration() time.Duration {
+	return e.Stopped.Sub(e.Started)
+}
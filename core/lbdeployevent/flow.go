@@ -3,9 +3,11 @@ package lbdeployevent
 import (
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
 	"github.com/leafbridge/leafbridge/core/lbevent"
 )
@@ -19,6 +21,10 @@ const (
 	FlowConditionType       = lbevent.Type("deployment.flow:condition")
 	FlowLockNotAcquiredType = lbevent.Type("deployment.flow:lock-not-acquired")
 	FlowAlreadyRunningType  = lbevent.Type("deployment.flow:already-running")
+	FlowFrequencyLimitType  = lbevent.Type("deployment.flow:frequency-limit")
+	FlowDeferredType        = lbevent.Type("deployment.flow:deferred")
+	FlowRestorePointType    = lbevent.Type("deployment.flow:restore-point")
+	FlowResumedType         = lbevent.Type("deployment.flow:resumed")
 )
 
 // FlowStarted is an event that occurs when a deployment flow has started.
@@ -64,13 +70,18 @@ func (e FlowStarted) Attrs() []slog.Attr {
 }
 
 // FlowStopped is an event that occurs when a deployment flow has stopped.
+//
+// If Cancelled is true, the flow was stopped because the deployment was
+// cancelled, and Err holds the error that the cancellation caused.
 type FlowStopped struct {
-	Deployment lbdeploy.DeploymentID
-	Flow       lbdeploy.FlowID
-	Stats      lbdeploy.FlowStats
-	Started    time.Time
-	Stopped    time.Time
-	Err        error
+	Deployment     lbdeploy.DeploymentID
+	Flow           lbdeploy.FlowID
+	Stats          lbdeploy.FlowStats
+	Started        time.Time
+	Stopped        time.Time
+	Err            error
+	Cancelled      bool
+	FailureMessage string
 }
 
 // Type returns the type of the event.
@@ -80,6 +91,9 @@ func (e FlowStopped) Type() lbevent.Type {
 
 // Level returns the level of the event.
 func (e FlowStopped) Level() slog.Level {
+	if e.Cancelled {
+		return slog.LevelWarn
+	}
 	if e.Err != nil {
 		return slog.LevelError
 	}
@@ -97,21 +111,36 @@ func (e FlowStopped) Message() string {
 		completed = fmt.Sprintf("%d %s", e.Stats.ActionsCompleted, plural(e.Stats.ActionsCompleted, "action", "actions"))
 		failed    = fmt.Sprintf("%d %s", e.Stats.ActionsFailed, plural(e.Stats.ActionsFailed, "action", "actions"))
 	)
+	var summary string
 	switch {
+	case e.Cancelled && e.Stats.ActionsCompleted > 0:
+		summary = fmt.Sprintf("Cancelled after %s completed successfully.", completed)
+	case e.Cancelled:
+		summary = "Cancelled."
 	case e.Stats.ActionsCompleted > 0 && e.Stats.ActionsFailed > 0:
-		builder.WriteStandard(fmt.Sprintf("Stopped after %s completed successfully and %s encountered an error.", completed, failed))
+		summary = fmt.Sprintf("Stopped after %s completed successfully and %s encountered an error.", completed, failed)
 	case e.Stats.ActionsCompleted > 0:
-		builder.WriteStandard(fmt.Sprintf("Stopped after %s completed successfully.", completed))
+		summary = fmt.Sprintf("Stopped after %s completed successfully.", completed)
 	case e.Stats.ActionsFailed > 1:
-		builder.WriteStandard(fmt.Sprintf("Stopped after %s encountered an error.", failed))
+		summary = fmt.Sprintf("Stopped after %s encountered an error.", failed)
 	case e.Err != nil:
-		builder.WriteStandard(fmt.Sprintf("Stopped after encountering an error: %s.", e.Err))
+		summary = fmt.Sprintf("Stopped after encountering an error: %s.", e.Err)
 	case e.Stats.ActionsFailed > 0:
-		builder.WriteStandard("Stopped.")
+		summary = "Stopped."
 	default:
-		builder.WriteStandard("Completed.")
+		summary = "Completed."
+	}
+	if e.Err != nil && !e.Cancelled && e.FailureMessage != "" {
+		summary = e.FailureMessage + " " + summary
 	}
+	builder.WriteStandard(summary)
 
+	if e.Stats.ActionsIgnored > 0 {
+		builder.WriteNote(fmt.Sprintf("%d ignored %s", e.Stats.ActionsIgnored, plural(e.Stats.ActionsIgnored, "failure", "failures")))
+	}
+	if e.Stats.ActionsSkipped > 0 {
+		builder.WriteNote(fmt.Sprintf("%d skipped", e.Stats.ActionsSkipped))
+	}
 	builder.WriteNote(e.Duration().Round(time.Millisecond * 10).String())
 
 	return builder.String()
@@ -122,9 +151,9 @@ func (e FlowStopped) Message() string {
 // are available.
 func (e FlowStopped) Details() string {
 	if e.Err != nil && (e.Stats.ActionsCompleted > 0 || e.Stats.ActionsFailed > 1) {
-		return e.Err.Error()
+		return withErrorHint(e.Err.Error(), e.Err)
 	}
-	return ""
+	return withErrorHint("", e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -134,10 +163,16 @@ func (e FlowStopped) Attrs() []slog.Attr {
 		slog.String("flow", string(e.Flow)),
 		slog.Time("started", e.Started),
 		slog.Time("stopped", e.Stopped),
-		slog.Group("actions", "completed", e.Stats.ActionsCompleted, "failed", e.Stats.ActionsFailed),
+		slog.Group("actions", "completed", e.Stats.ActionsCompleted, "failed", e.Stats.ActionsFailed, "ignored", e.Stats.ActionsIgnored, "skipped", e.Stats.ActionsSkipped),
+	}
+	if e.Cancelled {
+		attrs = append(attrs, slog.Bool("cancelled", true))
 	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
+		if e.FailureMessage != "" {
+			attrs = append(attrs, slog.String("failure-message", e.FailureMessage))
+		}
 	}
 	return attrs
 }
@@ -150,12 +185,13 @@ func (e FlowStopped) Duration() time.Duration {
 // FlowCondition is an event that occurs when a deployment flow evalutes
 // its preconditions.
 type FlowCondition struct {
-	Deployment lbdeploy.DeploymentID
-	Flow       lbdeploy.FlowID
-	Use        lbdeploy.ConditionUse
-	Passed     lbdeploy.ConditionList
-	Failed     lbdeploy.ConditionList
-	Err        error
+	Deployment      lbdeploy.DeploymentID
+	Flow            lbdeploy.FlowID
+	Use             lbdeploy.ConditionUse
+	Passed          lbdeploy.ConditionList
+	Failed          lbdeploy.ConditionList
+	Err             error
+	FailureMessages []string
 }
 
 // Type returns the type of the event.
@@ -182,6 +218,9 @@ func (e FlowCondition) Message() string {
 	builder.WritePrimary(string(e.Flow))
 	if e.Err != nil {
 		builder.WriteStandard(fmt.Sprintf("Unable to evaluate %s: %s", e.Use.Plural(), e.Err))
+	} else if len(e.Failed) > 0 && len(e.FailureMessages) > 0 {
+		builder.WriteStandard(strings.Join(e.FailureMessages, " "))
+		builder.WriteNote(e.Failed.String(), fieldformat.Label("failed "+e.Use.Plural()))
 	} else if len(e.Failed) > 0 {
 		builder.WriteStandard(fmt.Sprintf("One or more %s did not pass: %s.", e.Use.Plural(), e.Failed))
 	} else {
@@ -195,7 +234,7 @@ func (e FlowCondition) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e FlowCondition) Details() string {
-	return ""
+	return withErrorHint("", e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -206,8 +245,11 @@ func (e FlowCondition) Attrs() []slog.Attr {
 		slog.String("use", string(e.Use)),
 		slog.Group("conditions", "passed", e.Passed, "failed", e.Failed),
 	}
+	if len(e.FailureMessages) > 0 {
+		attrs = append(attrs, slog.Any("failure-messages", e.FailureMessages))
+	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
 	}
 	return attrs
 }
@@ -250,7 +292,7 @@ func (e FlowLockNotAcquired) Message() string {
 // multiple lines of text. An empty string is returned when no details
 // are available.
 func (e FlowLockNotAcquired) Details() string {
-	return ""
+	return withErrorHint("", e.Err)
 }
 
 // Attrs returns a set of structured log attributes for the event.
@@ -263,7 +305,7 @@ func (e FlowLockNotAcquired) Attrs() []slog.Attr {
 		attrs = append(attrs, slog.String("lock", string(e.Lock)))
 	}
 	if e.Err != nil {
-		attrs = append(attrs, slog.String("error", e.Err.Error()))
+		attrs = append(attrs, errorAttrs(e.Err)...)
 	}
 	return attrs
 }
@@ -311,3 +353,261 @@ func (e FlowAlreadyRunning) Attrs() []slog.Attr {
 		slog.String("flow", string(e.Flow)),
 	}
 }
+
+// FlowFrequencyLimit is an event that occurs when a deployment flow is
+// skipped because it has already run as often as its frequency allows.
+type FlowFrequencyLimit struct {
+	Deployment    lbdeploy.DeploymentID
+	Flow          lbdeploy.FlowID
+	Frequency     lbdeploy.FlowFrequency
+	LastCompleted time.Time
+	Completions   int
+	NextRun       time.Time
+}
+
+// Type returns the type of the event.
+func (e FlowFrequencyLimit) Type() lbevent.Type {
+	return FlowFrequencyLimitType
+}
+
+// Level returns the level of the event.
+func (e FlowFrequencyLimit) Level() slog.Level {
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e FlowFrequencyLimit) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+
+	scope := "this machine"
+	if e.Frequency.EffectiveScope() == lbdeploy.FrequencyPerUser {
+		scope = "this user"
+	}
+	if e.NextRun.IsZero() {
+		builder.WriteStandard(fmt.Sprintf("Skipped because it already completed for %s at %s.", scope, e.LastCompleted.Local().Format(time.DateTime)))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("Skipped because it last completed for %s at %s. It can run again after %s.", scope, e.LastCompleted.Local().Format(time.DateTime), e.NextRun.Local().Format(time.DateTime)))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e FlowFrequencyLimit) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e FlowFrequencyLimit) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.String("scope", string(e.Frequency.EffectiveScope())),
+		slog.Time("last-completed", e.LastCompleted),
+		slog.Int("completions", e.Completions),
+	}
+	if !e.NextRun.IsZero() {
+		attrs = append(attrs, slog.Time("next-run", e.NextRun))
+	}
+	return attrs
+}
+
+// FlowDeferred is an event that occurs when a disruptive flow is deferred
+// because the machine is not idle.
+type FlowDeferred struct {
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	Sessions   int
+	IdleTime   time.Duration
+	Required   time.Duration
+	Presenting bool
+	Deferrals  int
+	Deadline   time.Time
+}
+
+// Type returns the type of the event.
+func (e FlowDeferred) Type() lbevent.Type {
+	return FlowDeferredType
+}
+
+// Level returns the level of the event.
+func (e FlowDeferred) Level() slog.Level {
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e FlowDeferred) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+
+	if e.Presenting {
+		builder.WriteStandard("Deferred because a full-screen or presentation app is in use.")
+	} else {
+		builder.WriteStandard(fmt.Sprintf("Deferred because the machine has only been idle for %s of the required %s.", e.IdleTime.Round(time.Second), e.Required))
+	}
+
+	builder.WriteNote(fmt.Sprintf("%d %s", e.Deferrals, plural(e.Deferrals, "deferral", "deferrals")))
+	if !e.Deadline.IsZero() {
+		builder.WriteNote(fmt.Sprintf("deadline %s", e.Deadline.Local().Format(time.DateTime)))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e FlowDeferred) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e FlowDeferred) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Int("sessions", e.Sessions),
+		slog.Duration("idle-time", e.IdleTime),
+		slog.Duration("required", e.Required),
+		slog.Bool("presenting", e.Presenting),
+		slog.Int("deferrals", e.Deferrals),
+	}
+	if !e.Deadline.IsZero() {
+		attrs = append(attrs, slog.Time("deadline", e.Deadline))
+	}
+	return attrs
+}
+
+// FlowRestorePoint is an event that occurs when a system restore point has
+// been created before a flow runs its actions. The sequence number
+// identifies the restore point, so that the system can be rolled back to
+// it.
+type FlowRestorePoint struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	Description string
+	Sequence    int64
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e FlowRestorePoint) Type() lbevent.Type {
+	return FlowRestorePointType
+}
+
+// Level returns the level of the event.
+func (e FlowRestorePoint) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e FlowRestorePoint) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("Unable to create a restore point: %s.", e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("Created restore point %d, \"%s\".", e.Sequence, e.Description))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e FlowRestorePoint) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e FlowRestorePoint) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("restore-point", "sequence", e.Sequence, "description", e.Description),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// FlowResumed is an event that occurs when a flow that was interrupted
+// resumes from its checkpoint. The actions that completed before the
+// interruption are not run again.
+type FlowResumed struct {
+	Deployment        lbdeploy.DeploymentID
+	Flow              lbdeploy.FlowID
+	Interrupted       time.Time
+	Completed         int
+	Actions           int
+	VerifiedPackages  []lbdeploy.PackageID
+	ExtractedPackages []lbdeploy.PackageID
+}
+
+// Type returns the type of the event.
+func (e FlowResumed) Type() lbevent.Type {
+	return FlowResumedType
+}
+
+// Level returns the level of the event.
+func (e FlowResumed) Level() slog.Level {
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e FlowResumed) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+
+	builder.WriteStandard(fmt.Sprintf("Resuming after an interruption at %s. %d of %d %s already completed.", e.Interrupted.Local().Format(time.DateTime), e.Completed, e.Actions, plural(e.Actions, "action", "actions")))
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e FlowResumed) Details() string {
+	var lines []string
+	if len(e.VerifiedPackages) > 0 {
+		lines = append(lines, fmt.Sprintf("Verified packages: %s", joinIDs(e.VerifiedPackages)))
+	}
+	if len(e.ExtractedPackages) > 0 {
+		lines = append(lines, fmt.Sprintf("Extracted packages: %s", joinIDs(e.ExtractedPackages)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e FlowResumed) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Time("interrupted", e.Interrupted),
+		slog.Int("completed", e.Completed),
+		slog.Int("actions", e.Actions),
+	}
+	if len(e.VerifiedPackages) > 0 {
+		attrs = append(attrs, slog.String("verified-packages", joinIDs(e.VerifiedPackages)))
+	}
+	if len(e.ExtractedPackages) > 0 {
+		attrs = append(attrs, slog.String("extracted-packages", joinIDs(e.ExtractedPackages)))
+	}
+	return attrs
+}
@@ -0,0 +1,45 @@
+package lbdeployevent_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+)
+
+func TestFlowRestorePoint(t *testing.T) {
+	fixtures := []struct {
+		Name    string
+		Event   lbdeployevent.FlowRestorePoint
+		Level   slog.Level
+		Message string
+	}{
+		{
+			Name: "created",
+			Event: lbdeployevent.FlowRestorePoint{
+				Deployment: "app", Flow: "install", Description: "LeafBridge: app: install", Sequence: 42,
+			},
+			Level:   slog.LevelInfo,
+			Message: "app: install: Created restore point 42, \"LeafBridge: app: install\".",
+		},
+		{
+			Name: "error",
+			Event: lbdeployevent.FlowRestorePoint{
+				Deployment: "app", Flow: "install", Description: "LeafBridge: app: install",
+				Err: errors.New("system restore is disabled"),
+			},
+			Level:   slog.LevelWarn,
+			Message: "app: install: Unable to create a restore point: system restore is disabled.",
+		},
+	}
+
+	for _, fixture := range fixtures {
+		if got := fixture.Event.Level(); got != fixture.Level {
+			t.Errorf("%s: got level %s, want %s", fixture.Name, got, fixture.Level)
+		}
+		if got := fixture.Event.Message(); got != fixture.Message {
+			t.Errorf("%s: got message %q, want %q", fixture.Name, got, fixture.Message)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment hosts file event types.
+const (
+	HostsFileEditType = lbevent.Type("deployment.hosts-file:edit")
+)
+
+// HostsFileEdit is an event that occurs when a hosts-file action has been
+// applied to the hosts file of the local system.
+type HostsFileEdit struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	FilePath    string
+	BackupPath  string
+	Added       []string
+	Removed     []string
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e HostsFileEdit) Type() lbevent.Type {
+	return HostsFileEditType
+}
+
+// Level returns the level of the event.
+func (e HostsFileEdit) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e HostsFileEdit) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The hosts file (%s) could not be updated due to an error: %s.", e.FilePath, e.Err))
+	case len(e.Added) == 0 && len(e.Removed) == 0:
+		builder.WriteStandard(fmt.Sprintf("The hosts file (%s) already had the expected entries.", e.FilePath))
+	default:
+		var changes []string
+		if len(e.Added) > 0 {
+			changes = append(changes, "added "+strings.Join(e.Added, ", "))
+		}
+		if len(e.Removed) > 0 {
+			changes = append(changes, "removed "+strings.Join(e.Removed, ", "))
+		}
+		builder.WriteStandard(fmt.Sprintf("The hosts file (%s) was updated: %s.", e.FilePath, strings.Join(changes, "; ")))
+		if e.BackupPath != "" {
+			builder.WriteNote(e.BackupPath, fieldformat.Label("backup"))
+		}
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e HostsFileEdit) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e HostsFileEdit) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("path", e.FilePath),
+		slog.Any("added", e.Added),
+		slog.Any("removed", e.Removed),
+	}
+	if e.BackupPath != "" {
+		attrs = append(attrs, slog.String("backup", e.BackupPath))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,109 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment license event types.
+const (
+	LicenseActivationType = lbevent.Type("deployment.license:activation")
+)
+
+// LicenseActivation is an event that occurs when an activate-license action
+// has activated the volume license of a product, or found it to be
+// activated already.
+//
+// PartialProductKey holds the last five characters of the product key, if
+// one was provided. The full key is never recorded.
+type LicenseActivation struct {
+	Deployment        lbdeploy.DeploymentID
+	Flow              lbdeploy.FlowID
+	ActionIndex       int
+	ActionType        lbdeploy.ActionType
+	Product           lbdeploy.LicenseProduct
+	PartialProductKey string
+	KMSHost           string
+	KeyInstalled      bool
+	Activated         bool
+	Status            string
+	Err               error
+}
+
+// Type returns the type of the event.
+func (e LicenseActivation) Type() lbevent.Type {
+	return LicenseActivationType
+}
+
+// Level returns the level of the event.
+func (e LicenseActivation) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e LicenseActivation) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The %s license could not be activated due to an error: %s.", e.Product, e.Err))
+	case e.Activated:
+		builder.WriteStandard(fmt.Sprintf("The %s license was activated.", e.Product))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The %s license was already activated.", e.Product))
+	}
+
+	if e.KeyInstalled {
+		builder.WriteNote(e.PartialProductKey, fieldformat.Label("installed key"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e LicenseActivation) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e LicenseActivation) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("product", string(e.Product)),
+	}
+	if e.PartialProductKey != "" {
+		attrs = append(attrs, slog.String("partial-product-key", e.PartialProductKey))
+	}
+	if e.KMSHost != "" {
+		attrs = append(attrs, slog.String("kms-host", e.KMSHost))
+	}
+	attrs = append(attrs,
+		slog.Bool("key-installed", e.KeyInstalled),
+		slog.Bool("activated", e.Activated),
+	)
+	if e.Status != "" {
+		attrs = append(attrs, slog.String("status", e.Status))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,86 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment logon event types.
+const (
+	LogonFlowRegisteredType = lbevent.Type("deployment.logon:registered")
+)
+
+// LogonFlowRegistered is an event that occurs when a register-logon-flow
+// action has registered a flow to be run when each user logs on.
+type LogonFlowRegistered struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	LogonFlow   lbdeploy.FlowID
+	Registered  time.Time
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e LogonFlowRegistered) Type() lbevent.Type {
+	return LogonFlowRegisteredType
+}
+
+// Level returns the level of the event.
+func (e LogonFlowRegistered) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e LogonFlowRegistered) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" flow could not be registered to run at logon due to an error: %s.", e.LogonFlow, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" flow was registered to run at the next logon of each user.", e.LogonFlow))
+		builder.WriteNote(e.Registered.Format(time.RFC3339), fieldformat.Label("registered"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e LogonFlowRegistered) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e LogonFlowRegistered) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("logon-flow", string(e.LogonFlow)),
+	}
+	if !e.Registered.IsZero() {
+		attrs = append(attrs, slog.Time("registered", e.Registered))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,71 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment policy event types.
+const (
+	PolicyViolationType = lbevent.Type("deployment.policy:violation")
+)
+
+// PolicyViolation is an event that occurs when a deployment is refused
+// because the execution policy of the machine does not permit it.
+type PolicyViolation struct {
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	PolicyFile string
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e PolicyViolation) Type() lbevent.Type {
+	return PolicyViolationType
+}
+
+// Level returns the level of the event.
+func (e PolicyViolation) Level() slog.Level {
+	return slog.LevelError
+}
+
+// Message returns a description of the event.
+func (e PolicyViolation) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WriteStandard(fmt.Sprintf("The flow was not started: %s.", e.Err))
+	if e.PolicyFile != "" {
+		builder.WriteNote(e.PolicyFile, fieldformat.Label("policy"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e PolicyViolation) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e PolicyViolation) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+	}
+	if e.PolicyFile != "" {
+		attrs = append(attrs, slog.String("policy-file", e.PolicyFile))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,161 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment power management event types.
+const (
+	PowerPlanChangeType    = lbevent.Type("deployment.power:plan-change")
+	PowerSettingChangeType = lbevent.Type("deployment.power:setting-change")
+)
+
+// PowerPlanChange is an event that occurs when a set-power-plan action has
+// made a power plan active.
+type PowerPlanChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Plan        lbdeploy.PowerPlan
+	Previous    string
+	Imported    bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e PowerPlanChange) Type() lbevent.Type {
+	return PowerPlanChangeType
+}
+
+// Level returns the level of the event.
+func (e PowerPlanChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e PowerPlanChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" power plan could not be made active due to an error: %s.", e.Plan, e.Err))
+	case e.Imported:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" power plan was imported and made active.", e.Plan))
+	case e.Previous == e.Plan.GUID():
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" power plan was already active.", e.Plan))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" power plan was made active.", e.Plan))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e PowerPlanChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e PowerPlanChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("plan", string(e.Plan)),
+		slog.String("guid", e.Plan.GUID()),
+		slog.String("previous", e.Previous),
+		slog.Bool("imported", e.Imported),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// PowerSettingChange is an event that occurs when a configure-power-settings
+// action has applied a power setting.
+type PowerSettingChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Setting     lbdeploy.PowerSetting
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e PowerSettingChange) Type() lbevent.Type {
+	return PowerSettingChangeType
+}
+
+// Level returns the level of the event.
+func (e PowerSettingChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e PowerSettingChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" power setting could not be applied due to an error: %s.", e.Setting, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" power setting was applied.", e.Setting))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e PowerSettingChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e PowerSettingChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("setting", string(e.Setting.Name)),
+	}
+	if e.Setting.Source != lbdeploy.PowerSourceBoth {
+		attrs = append(attrs, slog.String("source", string(e.Setting.Source)))
+	}
+	if e.Setting.Name == lbdeploy.PowerSettingHibernation {
+		attrs = append(attrs, slog.Bool("enabled", e.Setting.Enabled))
+	} else {
+		attrs = append(attrs, slog.Int("minutes", e.Setting.Minutes))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
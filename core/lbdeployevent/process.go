@@ -0,0 +1,119 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Process event types.
+const (
+	ProcessTreeTerminatedType = lbevent.Type("deployment.process-tree:terminated")
+)
+
+// ProcessTreeTerminated is an event that occurs when a command or script is
+// cancelled and its entire process tree is terminated.
+type ProcessTreeTerminated struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Package     lbdeploy.PackageID
+	Command     lbdeploy.CommandID
+	ScriptFile  lbdeploy.FileResourceID
+	ProcessIDs  []uint32
+	Reason      string
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e ProcessTreeTerminated) Type() lbevent.Type {
+	return ProcessTreeTerminatedType
+}
+
+// Level returns the level of the event.
+func (e ProcessTreeTerminated) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e ProcessTreeTerminated) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	switch {
+	case e.Package != "":
+		builder.WritePrimary(fmt.Sprintf("%s.%s", e.Package, e.Command))
+	case e.Command != "":
+		builder.WritePrimary(string(e.Command))
+	case e.ScriptFile != "":
+		builder.WritePrimary(string(e.ScriptFile))
+	}
+	processes := fmt.Sprintf("%d %s", len(e.ProcessIDs), plural(len(e.ProcessIDs), "process", "processes"))
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("Terminated %s, but encountered an error: %s", processes, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("Terminated %s", processes))
+	}
+	if e.Reason != "" {
+		builder.WriteNote(e.Reason)
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ProcessTreeTerminated) Details() string {
+	if len(e.ProcessIDs) == 0 {
+		return withErrorHint("", e.Err)
+	}
+	return withErrorHint(fmt.Sprintf("Process IDs: %s", e.processList()), e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ProcessTreeTerminated) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+	}
+	if e.Package != "" {
+		attrs = append(attrs, slog.String("package", string(e.Package)))
+	}
+	if e.Command != "" {
+		attrs = append(attrs, slog.String("command", string(e.Command)))
+	}
+	if e.ScriptFile != "" {
+		attrs = append(attrs, slog.String("script-file", string(e.ScriptFile)))
+	}
+	attrs = append(attrs, slog.Any("process-ids", e.ProcessIDs))
+	if e.Reason != "" {
+		attrs = append(attrs, slog.String("reason", e.Reason))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// processList returns the process IDs as a comma-separated list.
+func (e ProcessTreeTerminated) processList() string {
+	ids := make([]string, len(e.ProcessIDs))
+	for i, id := range e.ProcessIDs {
+		ids[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(ids, ", ")
+}
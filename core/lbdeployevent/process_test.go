@@ -0,0 +1,63 @@
+package lbdeployevent_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+)
+
+func TestProcessTreeTerminated(t *testing.T) {
+	fixtures := []struct {
+		Name    string
+		Event   lbdeployevent.ProcessTreeTerminated
+		Level   slog.Level
+		Message string
+		Details string
+	}{
+		{
+			Name: "command",
+			Event: lbdeployevent.ProcessTreeTerminated{
+				Deployment: "app", Flow: "install", ActionIndex: 1, ActionType: "invoke-command",
+				Package: "setup", Command: "install", ProcessIDs: []uint32{4120, 4188, 5012},
+				Reason: "context canceled",
+			},
+			Level:   slog.LevelWarn,
+			Message: "app: install: 2: invoke-command: setup.install: Terminated 3 processes (context canceled)",
+			Details: "Process IDs: 4120, 4188, 5012",
+		},
+		{
+			Name: "script",
+			Event: lbdeployevent.ProcessTreeTerminated{
+				Deployment: "app", Flow: "install", ActionType: "run-script",
+				ScriptFile: "cleanup-ps1", ProcessIDs: []uint32{812},
+			},
+			Level:   slog.LevelWarn,
+			Message: "app: install: 1: run-script: cleanup-ps1: Terminated 1 process",
+			Details: "Process IDs: 812",
+		},
+		{
+			Name: "error",
+			Event: lbdeployevent.ProcessTreeTerminated{
+				Deployment: "app", Flow: "install", ActionType: "invoke-command",
+				Command: "install", Err: errors.New("access is denied"),
+			},
+			Level:   slog.LevelError,
+			Message: "app: install: 1: invoke-command: install: Terminated 0 processes, but encountered an error: access is denied",
+			Details: "",
+		},
+	}
+
+	for _, fixture := range fixtures {
+		if got := fixture.Event.Level(); got != fixture.Level {
+			t.Errorf("%s: got level %s, want %s", fixture.Name, got, fixture.Level)
+		}
+		if got := fixture.Event.Message(); got != fixture.Message {
+			t.Errorf("%s: got message %q, want %q", fixture.Name, got, fixture.Message)
+		}
+		if got := fixture.Event.Details(); got != fixture.Details {
+			t.Errorf("%s: got details %q, want %q", fixture.Name, got, fixture.Details)
+		}
+	}
+}
@@ -7,24 +7,92 @@ import "github.com/leafbridge/leafbridge/core/lbevent"
 //
 // The registrations can be provided to an [lbevent.Registry] to facilitate
 // unmarshaling and event ID assignments.
+//
+// Each registration declares its event ID, so that IDs remain stable across
+// releases. Event IDs must never be reused or changed once they have been
+// released. New event types should be assigned the next unused ID.
 var Registrations = []lbevent.Registration{
-	{Type: FlowStartedType, Unmarshaler: lbevent.UnmarshalRecord[FlowStarted]},
-	{Type: FlowStoppedType, Unmarshaler: lbevent.UnmarshalRecord[FlowStopped]},
-	{Type: FlowConditionType, Unmarshaler: lbevent.UnmarshalRecord[FlowCondition]},
-	{Type: FlowLockNotAcquiredType, Unmarshaler: lbevent.UnmarshalRecord[FlowLockNotAcquired]},
-	{Type: FlowAlreadyRunningType, Unmarshaler: lbevent.UnmarshalRecord[FlowAlreadyRunning]},
-	{Type: ActionStartedType, Unmarshaler: lbevent.UnmarshalRecord[ActionStarted]},
-	{Type: ActionStoppedType, Unmarshaler: lbevent.UnmarshalRecord[ActionStopped]},
-	{Type: CommandSkippedType, Unmarshaler: lbevent.UnmarshalRecord[CommandSkipped]},
-	{Type: CommandStartedType, Unmarshaler: lbevent.UnmarshalRecord[CommandStarted]},
-	{Type: CommandStoppedType, Unmarshaler: lbevent.UnmarshalRecord[CommandStopped]},
-	{Type: DownloadStartedType, Unmarshaler: lbevent.UnmarshalRecord[DownloadStarted]},
-	{Type: DownloadStoppedType, Unmarshaler: lbevent.UnmarshalRecord[DownloadStopped]},
-	{Type: DownloadResetType, Unmarshaler: lbevent.UnmarshalRecord[DownloadReset]},
-	{Type: ExtractionStartedType, Unmarshaler: lbevent.UnmarshalRecord[ExtractionStarted]},
-	{Type: ExtractionStoppedType, Unmarshaler: lbevent.UnmarshalRecord[ExtractionStopped]},
-	{Type: FileExtractionType, Unmarshaler: lbevent.UnmarshalRecord[FileExtraction]},
-	{Type: FileVerificationType, Unmarshaler: lbevent.UnmarshalRecord[FileVerification]},
-	{Type: FileCopyType, Unmarshaler: lbevent.UnmarshalRecord[FileCopy]},
-	{Type: FileDeleteType, Unmarshaler: lbevent.UnmarshalRecord[FileDelete]},
+	{Type: FlowStartedType, ID: 100, Unmarshaler: lbevent.UnmarshalRecord[FlowStarted]},
+	{Type: FlowStoppedType, ID: 101, Unmarshaler: lbevent.UnmarshalRecord[FlowStopped]},
+	{Type: FlowConditionType, ID: 102, Unmarshaler: lbevent.UnmarshalRecord[FlowCondition]},
+	{Type: FlowLockNotAcquiredType, ID: 103, Unmarshaler: lbevent.UnmarshalRecord[FlowLockNotAcquired]},
+	{Type: FlowAlreadyRunningType, ID: 104, Unmarshaler: lbevent.UnmarshalRecord[FlowAlreadyRunning]},
+	{Type: ActionStartedType, ID: 105, Unmarshaler: lbevent.UnmarshalRecord[ActionStarted]},
+	{Type: ActionStoppedType, ID: 106, Unmarshaler: lbevent.UnmarshalRecord[ActionStopped]},
+	{Type: CommandSkippedType, ID: 107, Unmarshaler: lbevent.UnmarshalRecord[CommandSkipped]},
+	{Type: CommandStartedType, ID: 108, Unmarshaler: lbevent.UnmarshalRecord[CommandStarted]},
+	{Type: CommandStoppedType, ID: 109, Unmarshaler: lbevent.UnmarshalRecord[CommandStopped]},
+	{Type: DownloadStartedType, ID: 110, Unmarshaler: lbevent.UnmarshalRecord[DownloadStarted]},
+	{Type: DownloadStoppedType, ID: 111, Unmarshaler: lbevent.UnmarshalRecord[DownloadStopped]},
+	{Type: DownloadResetType, ID: 112, Unmarshaler: lbevent.UnmarshalRecord[DownloadReset]},
+	{Type: ExtractionStartedType, ID: 113, Unmarshaler: lbevent.UnmarshalRecord[ExtractionStarted]},
+	{Type: ExtractionStoppedType, ID: 114, Unmarshaler: lbevent.UnmarshalRecord[ExtractionStopped]},
+	{Type: FileExtractionType, ID: 115, Unmarshaler: lbevent.UnmarshalRecord[FileExtraction]},
+	{Type: FileVerificationType, ID: 116, Unmarshaler: lbevent.UnmarshalRecord[FileVerification]},
+	{Type: FileCopyType, ID: 117, Unmarshaler: lbevent.UnmarshalRecord[FileCopy]},
+	{Type: FileDeleteType, ID: 118, Unmarshaler: lbevent.UnmarshalRecord[FileDelete]},
+	{Type: ScriptStartedType, ID: 119, Unmarshaler: lbevent.UnmarshalRecord[ScriptStarted]},
+	{Type: ScriptStoppedType, ID: 120, Unmarshaler: lbevent.UnmarshalRecord[ScriptStopped]},
+	{Type: FileIntegrityType, ID: 121, Unmarshaler: lbevent.UnmarshalRecord[FileIntegrity]},
+	{Type: ActionSkippedType, ID: 122, Unmarshaler: lbevent.UnmarshalRecord[ActionSkipped]},
+	{Type: DeploymentTimeoutType, ID: 123, Unmarshaler: lbevent.UnmarshalRecord[DeploymentTimeout]},
+	{Type: ProcessTreeTerminatedType, ID: 124, Unmarshaler: lbevent.UnmarshalRecord[ProcessTreeTerminated]},
+	{Type: DiskSpaceInsufficientType, ID: 125, Unmarshaler: lbevent.UnmarshalRecord[DiskSpaceInsufficient]},
+	{Type: DownloadRetryType, ID: 126, Unmarshaler: lbevent.UnmarshalRecord[DownloadRetry]},
+	{Type: ChecksumsResolvedType, ID: 127, Unmarshaler: lbevent.UnmarshalRecord[ChecksumsResolved]},
+	{Type: RegistryWaitType, ID: 128, Unmarshaler: lbevent.UnmarshalRecord[RegistryWait]},
+	{Type: FileEditType, ID: 129, Unmarshaler: lbevent.UnmarshalRecord[FileEdit]},
+	{Type: HostsFileEditType, ID: 130, Unmarshaler: lbevent.UnmarshalRecord[HostsFileEdit]},
+	{Type: BitLockerProtectionType, ID: 131, Unmarshaler: lbevent.UnmarshalRecord[BitLockerProtection]},
+	{Type: TimeZoneChangeType, ID: 132, Unmarshaler: lbevent.UnmarshalRecord[TimeZoneChange]},
+	{Type: RolloutStartedType, ID: 133, Unmarshaler: lbevent.UnmarshalRecord[RolloutStarted]},
+	{Type: RolloutStepType, ID: 134, Unmarshaler: lbevent.UnmarshalRecord[RolloutStep]},
+	{Type: RolloutStoppedType, ID: 135, Unmarshaler: lbevent.UnmarshalRecord[RolloutStopped]},
+	{Type: FlowFrequencyLimitType, ID: 136, Unmarshaler: lbevent.UnmarshalRecord[FlowFrequencyLimit]},
+	{Type: ComplianceReportType, ID: 137, Unmarshaler: lbevent.UnmarshalRecord[ComplianceReport]},
+	{Type: RemediationStartedType, ID: 138, Unmarshaler: lbevent.UnmarshalRecord[RemediationStarted]},
+	{Type: RemediationHeldType, ID: 139, Unmarshaler: lbevent.UnmarshalRecord[RemediationHeld]},
+	{Type: AssignmentsResolvedType, ID: 140, Unmarshaler: lbevent.UnmarshalRecord[AssignmentsResolved]},
+	{Type: AssignmentAppliedType, ID: 141, Unmarshaler: lbevent.UnmarshalRecord[AssignmentApplied]},
+	{Type: FlowDeferredType, ID: 142, Unmarshaler: lbevent.UnmarshalRecord[FlowDeferred]},
+	{Type: CommandWindowType, ID: 143, Unmarshaler: lbevent.UnmarshalRecord[CommandWindow]},
+	{Type: ExtractionUnsupportedType, ID: 144, Unmarshaler: lbevent.UnmarshalRecord[ExtractionUnsupported]},
+	{Type: ExtractionRejectedType, ID: 145, Unmarshaler: lbevent.UnmarshalRecord[ExtractionRejected]},
+	{Type: ExtractionSlowedType, ID: 146, Unmarshaler: lbevent.UnmarshalRecord[ExtractionSlowed]},
+	{Type: AntivirusExclusionType, ID: 147, Unmarshaler: lbevent.UnmarshalRecord[AntivirusExclusion]},
+	{Type: RestartManagerType, ID: 148, Unmarshaler: lbevent.UnmarshalRecord[RestartManager]},
+	{Type: FlowRestorePointType, ID: 149, Unmarshaler: lbevent.UnmarshalRecord[FlowRestorePoint]},
+	{Type: FileBackupType, ID: 150, Unmarshaler: lbevent.UnmarshalRecord[FileBackup]},
+	{Type: FileRestoreType, ID: 151, Unmarshaler: lbevent.UnmarshalRecord[FileRestore]},
+	{Type: ProgressUIType, ID: 152, Unmarshaler: lbevent.UnmarshalRecord[ProgressUI]},
+	{Type: SecurityFileChangeType, ID: 153, Unmarshaler: lbevent.UnmarshalRecord[SecurityChange]},
+	{Type: SecurityHostsFileChangeType, ID: 154, Unmarshaler: lbevent.UnmarshalRecord[SecurityChange]},
+	{Type: SecurityAntivirusExclusionType, ID: 155, Unmarshaler: lbevent.UnmarshalRecord[SecurityChange]},
+	{Type: SecurityBitLockerChangeType, ID: 156, Unmarshaler: lbevent.UnmarshalRecord[SecurityChange]},
+	{Type: PolicyViolationType, ID: 157, Unmarshaler: lbevent.UnmarshalRecord[PolicyViolation]},
+	{Type: DeploymentApprovalType, ID: 158, Unmarshaler: lbevent.UnmarshalRecord[DeploymentApproval]},
+	{Type: OutsideValidityWindowType, ID: 159, Unmarshaler: lbevent.UnmarshalRecord[OutsideValidityWindow]},
+	{Type: SecuritySignatureCheckType, ID: 160, Unmarshaler: lbevent.UnmarshalRecord[SignatureCheck]},
+	{Type: InstallerWaitType, ID: 161, Unmarshaler: lbevent.UnmarshalRecord[InstallerWait]},
+	{Type: LogonFlowRegisteredType, ID: 162, Unmarshaler: lbevent.UnmarshalRecord[LogonFlowRegistered]},
+	{Type: BrowserExtensionChangeType, ID: 163, Unmarshaler: lbevent.UnmarshalRecord[BrowserExtensionChange]},
+	{Type: BrowserPolicyChangeType, ID: 164, Unmarshaler: lbevent.UnmarshalRecord[BrowserPolicyChange]},
+	{Type: DefaultAssociationsChangeType, ID: 165, Unmarshaler: lbevent.UnmarshalRecord[DefaultAssociationsChange]},
+	{Type: StartLayoutChangeType, ID: 166, Unmarshaler: lbevent.UnmarshalRecord[StartLayoutChange]},
+	{Type: LocalUserChangeType, ID: 167, Unmarshaler: lbevent.UnmarshalRecord[LocalUserChange]},
+	{Type: GroupMembershipChangeType, ID: 168, Unmarshaler: lbevent.UnmarshalRecord[GroupMembershipChange]},
+	{Type: UserRightChangeType, ID: 169, Unmarshaler: lbevent.UnmarshalRecord[UserRightChange]},
+	{Type: PowerPlanChangeType, ID: 170, Unmarshaler: lbevent.UnmarshalRecord[PowerPlanChange]},
+	{Type: PowerSettingChangeType, ID: 171, Unmarshaler: lbevent.UnmarshalRecord[PowerSettingChange]},
+	{Type: LicenseActivationType, ID: 172, Unmarshaler: lbevent.UnmarshalRecord[LicenseActivation]},
+	{Type: DeploymentCancelledType, ID: 173, Unmarshaler: lbevent.UnmarshalRecord[DeploymentCancelled]},
+	{Type: ConditionObservedType, ID: 174, Unmarshaler: lbevent.UnmarshalRecord[ConditionObserved]},
+	{Type: RollbackStartedType, ID: 175, Unmarshaler: lbevent.UnmarshalRecord[RollbackStarted]},
+	{Type: RollbackStoppedType, ID: 176, Unmarshaler: lbevent.UnmarshalRecord[RollbackStopped]},
+	{Type: ActionRetriedType, ID: 177, Unmarshaler: lbevent.UnmarshalRecord[ActionRetried]},
+	{Type: SecurityOwnershipChangeType, ID: 178, Unmarshaler: lbevent.UnmarshalRecord[SecurityChange]},
+	{Type: SecurityPrivilegeChangeType, ID: 179, Unmarshaler: lbevent.UnmarshalRecord[SecurityChange]},
+	{Type: SecurityReparsePointType, ID: 180, Unmarshaler: lbevent.UnmarshalRecord[ReparsePointBlocked]},
+	{Type: FlowResumedType, ID: 181, Unmarshaler: lbevent.UnmarshalRecord[FlowResumed]},
+	{Type: ExtractionQuotaType, ID: 182, Unmarshaler: lbevent.UnmarshalRecord[ExtractionQuotaExceeded]},
 }
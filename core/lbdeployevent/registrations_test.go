@@ -0,0 +1,21 @@
+package lbdeployevent_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+func TestRegistrationsDeclareIDs(t *testing.T) {
+	for _, registration := range lbdeployevent.Registrations {
+		if registration.ID == 0 {
+			t.Errorf("the \"%s\" event type does not declare an event ID", registration.Type)
+		}
+	}
+
+	registry := lbevent.NewRegistry(100)
+	if err := registry.Add(lbdeployevent.Registrations...); err != nil {
+		t.Fatal(err)
+	}
+}
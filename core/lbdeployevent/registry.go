@@ -0,0 +1,99 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Registry event types.
+const (
+	RegistryWaitType = lbevent.Type("deployment.registry:wait")
+)
+
+// RegistryWait is an event that occurs when an action has finished waiting
+// for a registry condition to be satisfied.
+type RegistryWait struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Condition   lbdeploy.ConditionID
+	Path        string
+	Changes     int
+	Satisfied   bool
+	Started     time.Time
+	Stopped     time.Time
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e RegistryWait) Type() lbevent.Type {
+	return RegistryWaitType
+}
+
+// Level returns the level of the event.
+func (e RegistryWait) Level() slog.Level {
+	if e.Err != nil || !e.Satisfied {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e RegistryWait) Message() string {
+	var builder structformat.Builder
+
+	duration := e.Duration().Round(time.Millisecond * 10)
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("Waiting for the \"%s\" condition failed after %s due to an error: %s.", e.Condition, duration, e.Err))
+	case !e.Satisfied:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" condition was not satisfied within %s, after %d %s to \"%s\".", e.Condition, duration, e.Changes, plural(e.Changes, "change", "changes"), e.Path))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" condition was satisfied after %s.", e.Condition, duration))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RegistryWait) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RegistryWait) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("condition", string(e.Condition)),
+		slog.String("path", e.Path),
+		slog.Int("changes", e.Changes),
+		slog.Bool("satisfied", e.Satisfied),
+		slog.Time("started", e.Started),
+		slog.Time("stopped", e.Stopped),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// Duration returns the amount of time that was spent waiting.
+func (e RegistryWait) Duration() time.Duration {
+	return e.Stopped.Sub(e.Started)
+}
@@ -0,0 +1,128 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment restart manager event types.
+const (
+	RestartManagerType = lbevent.Type("deployment.command:restart-manager")
+)
+
+// RestartManagerOperation identifies an operation performed through the
+// Windows Restart Manager.
+type RestartManagerOperation string
+
+// Restart manager operations.
+const (
+	RestartManagerDetect   RestartManagerOperation = "detect"
+	RestartManagerShutdown RestartManagerOperation = "shutdown"
+	RestartManagerRestart  RestartManagerOperation = "restart"
+)
+
+// RestartManager is an event that records the applications affected by a
+// command, as reported by the Windows Restart Manager, along with any
+// shutdown or restart of those applications.
+type RestartManager struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Package     lbdeploy.PackageID
+	Command     lbdeploy.CommandID
+	Operation   RestartManagerOperation
+	Files       int
+	Apps        []FileLocker
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e RestartManager) Type() lbevent.Type {
+	return RestartManagerType
+}
+
+// Level returns the level of the event.
+func (e RestartManager) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e RestartManager) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	if e.Package == "" {
+		builder.WritePrimary(string(e.Command))
+	} else {
+		builder.WritePrimary(fmt.Sprintf("%s.%s", e.Package, e.Command))
+	}
+
+	apps := fmt.Sprintf("%d %s", len(e.Apps), plural(len(e.Apps), "application", "applications"))
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The restart manager %s operation failed: %s.", e.Operation, e.Err))
+	case e.Operation == RestartManagerShutdown:
+		builder.WriteStandard(fmt.Sprintf("Shut down %s before running the command.", apps))
+	case e.Operation == RestartManagerRestart:
+		builder.WriteStandard(fmt.Sprintf("Restarted %s after running the command.", apps))
+	case len(e.Apps) == 0:
+		builder.WriteStandard(fmt.Sprintf("None of the %d %s that the command will replace are in use.", e.Files, plural(e.Files, "file", "files")))
+	default:
+		builder.WriteStandard(fmt.Sprintf("%s %s using the %d %s that the command will replace.", apps, plural(len(e.Apps), "is", "are"), e.Files, plural(e.Files, "file", "files")))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RestartManager) Details() string {
+	lines := make([]string, len(e.Apps))
+	for i, app := range e.Apps {
+		lines[i] = app.String()
+	}
+	return withErrorHint(strings.Join(lines, "\n"), e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RestartManager) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+	}
+	if e.Package != "" {
+		attrs = append(attrs, slog.String("package", string(e.Package)))
+	}
+	attrs = append(attrs,
+		slog.String("command", string(e.Command)),
+		slog.String("operation", string(e.Operation)),
+		slog.Int("files", e.Files),
+	)
+	if len(e.Apps) > 0 {
+		apps := make([]any, len(e.Apps))
+		for i, app := range e.Apps {
+			apps[i] = slog.Group(strconv.Itoa(i), "pid", app.ProcessID, "name", app.Name, "service", app.Service)
+		}
+		attrs = append(attrs, slog.Group("apps", apps...))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,145 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment rollback event types.
+const (
+	RollbackStartedType = lbevent.Type("deployment.rollback:started")
+	RollbackStoppedType = lbevent.Type("deployment.rollback:stopped")
+)
+
+// RollbackStarted is an event that occurs when a flow that has failed
+// starts to undo the work of its completed actions.
+//
+// Completed is the number of actions that completed before the flow
+// failed. Steps is the number of undo steps that will run for them. If
+// RollbackFlow is not empty, it is started after the undo steps.
+type RollbackStarted struct {
+	Deployment   lbdeploy.DeploymentID
+	Flow         lbdeploy.FlowID
+	Completed    int
+	Steps        int
+	RollbackFlow lbdeploy.FlowID
+}
+
+// Type returns the type of the event.
+func (e RollbackStarted) Type() lbevent.Type {
+	return RollbackStartedType
+}
+
+// Level returns the level of the event.
+func (e RollbackStarted) Level() slog.Level {
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e RollbackStarted) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WriteStandard(fmt.Sprintf("Rolling back the work of %d completed %s.", e.Completed, plural(e.Completed, "action", "actions")))
+
+	builder.WriteNote(fmt.Sprintf("%d undo %s", e.Steps, plural(e.Steps, "step", "steps")))
+	if e.RollbackFlow != "" {
+		builder.WriteNote(string(e.RollbackFlow), fieldformat.Label("rollback flow"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RollbackStarted) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RollbackStarted) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Int("completed", e.Completed),
+		slog.Int("steps", e.Steps),
+	}
+	if e.RollbackFlow != "" {
+		attrs = append(attrs, slog.String("rollback-flow", string(e.RollbackFlow)))
+	}
+	return attrs
+}
+
+// RollbackStopped is an event that occurs when a flow that has failed
+// has finished undoing the work of its completed actions.
+type RollbackStopped struct {
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	Started    time.Time
+	Stopped    time.Time
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e RollbackStopped) Type() lbevent.Type {
+	return RollbackStoppedType
+}
+
+// Level returns the level of the event.
+func (e RollbackStopped) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e RollbackStopped) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The rollback stopped after encountering an error: %s.", e.Err))
+	} else {
+		builder.WriteStandard("The rollback completed.")
+	}
+	builder.WriteNote(e.Duration().Round(time.Millisecond * 10).String())
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RollbackStopped) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RollbackStopped) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Time("started", e.Started),
+		slog.Time("stopped", e.Stopped),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// Duration returns the duration of the rollback.
+func (e RollbackStopped) Duration() time.Duration {
+	return e.Stopped.Sub(e.Started)
+}
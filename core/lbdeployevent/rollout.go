@@ -0,0 +1,217 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbrollout"
+)
+
+// Rollout event types.
+const (
+	RolloutStartedType = lbevent.Type("rollout:started")
+	RolloutStepType    = lbevent.Type("rollout:step")
+	RolloutStoppedType = lbevent.Type("rollout:stopped")
+)
+
+// RolloutStarted is an event that occurs when a rollout has started.
+type RolloutStarted struct {
+	Rollout lbrollout.RolloutID
+	Steps   int
+}
+
+// Type returns the type of the event.
+func (e RolloutStarted) Type() lbevent.Type {
+	return RolloutStartedType
+}
+
+// Level returns the level of the event.
+func (e RolloutStarted) Level() slog.Level {
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e RolloutStarted) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Rollout))
+	builder.WriteStandard(fmt.Sprintf("Starting %d %s.", e.Steps, plural(e.Steps, "step", "steps")))
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RolloutStarted) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RolloutStarted) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("rollout", string(e.Rollout)),
+		slog.Int("steps", e.Steps),
+	}
+}
+
+// RolloutStep is an event that occurs when a step of a rollout has
+// finished, or when it is skipped or not run.
+type RolloutStep struct {
+	Rollout    lbrollout.RolloutID
+	Step       int
+	Label      string
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	Outcome    lbrollout.StepOutcome
+	Failed     lbdeploy.ConditionList
+	Duration   time.Duration
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e RolloutStep) Type() lbevent.Type {
+	return RolloutStepType
+}
+
+// Level returns the level of the event.
+func (e RolloutStep) Level() slog.Level {
+	switch e.Outcome {
+	case lbrollout.StepFailed:
+		return slog.LevelError
+	case lbrollout.StepNotRun:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Message returns a description of the event.
+func (e RolloutStep) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Rollout))
+	builder.WritePrimary(fmt.Sprintf("Step %d", e.Step))
+	builder.WritePrimary(e.Label)
+
+	switch e.Outcome {
+	case lbrollout.StepCompleted:
+		builder.WriteStandard(fmt.Sprintf("Completed the %s flow of the %s deployment.", e.Flow, e.Deployment))
+	case lbrollout.StepSkipped:
+		builder.WriteStandard(fmt.Sprintf("Skipped because its conditions were not met: %s.", e.Failed))
+	case lbrollout.StepFailed:
+		if e.Err != nil {
+			builder.WriteStandard(fmt.Sprintf("Failed: %s.", e.Err))
+		} else {
+			builder.WriteStandard("Failed.")
+		}
+	case lbrollout.StepNotRun:
+		builder.WriteStandard("Not run because an earlier step failed.")
+	}
+
+	if e.Duration > 0 {
+		builder.WriteNote(e.Duration.Round(time.Millisecond * 10).String())
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RolloutStep) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RolloutStep) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("rollout", string(e.Rollout)),
+		slog.Int("step", e.Step),
+		slog.String("label", e.Label),
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.String("outcome", string(e.Outcome)),
+		slog.Duration("duration", e.Duration),
+	}
+	if len(e.Failed) > 0 {
+		attrs = append(attrs, slog.String("failed-conditions", e.Failed.String()))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// RolloutStopped is an event that occurs when a rollout has stopped. It
+// summarizes the outcome of each of its steps.
+type RolloutStopped struct {
+	Rollout lbrollout.RolloutID
+	Stats   lbrollout.Stats
+	Started time.Time
+	Stopped time.Time
+}
+
+// Type returns the type of the event.
+func (e RolloutStopped) Type() lbevent.Type {
+	return RolloutStoppedType
+}
+
+// Level returns the level of the event.
+func (e RolloutStopped) Level() slog.Level {
+	if e.Stats.StepsFailed > 0 {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e RolloutStopped) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Rollout))
+
+	completed := fmt.Sprintf("%d %s", e.Stats.StepsCompleted, plural(e.Stats.StepsCompleted, "step", "steps"))
+	if e.Stats.StepsFailed > 0 {
+		failed := fmt.Sprintf("%d %s", e.Stats.StepsFailed, plural(e.Stats.StepsFailed, "step", "steps"))
+		builder.WriteStandard(fmt.Sprintf("Stopped after %s completed successfully and %s failed.", completed, failed))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("Completed %s successfully.", completed))
+	}
+
+	if e.Stats.StepsSkipped > 0 {
+		builder.WriteNote(fmt.Sprintf("%d skipped", e.Stats.StepsSkipped))
+	}
+	if e.Stats.StepsNotRun > 0 {
+		builder.WriteNote(fmt.Sprintf("%d not run", e.Stats.StepsNotRun))
+	}
+	builder.WriteNote(e.Duration().Round(time.Millisecond * 10).String())
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e RolloutStopped) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e RolloutStopped) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("rollout", string(e.Rollout)),
+		slog.Time("started", e.Started),
+		slog.Time("stopped", e.Stopped),
+		slog.Group("steps", "completed", e.Stats.StepsCompleted, "failed", e.Stats.StepsFailed, "skipped", e.Stats.StepsSkipped, "not-run", e.Stats.StepsNotRun),
+	}
+}
+
+// Duration returns the duration of the rollout.
+func (e RolloutStopped) Duration() time.Duration {
+	return e.Stopped.Sub(e.Started)
+}
@@ -0,0 +1,223 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment script event types.
+const (
+	ScriptStartedType = lbevent.Type("deployment.script:started")
+	ScriptStoppedType = lbevent.Type("deployment.script:stopped")
+)
+
+// ScriptStarted is an event that occurs when a script has started.
+type ScriptStarted struct {
+	Deployment           lbdeploy.DeploymentID
+	Flow                 lbdeploy.FlowID
+	ActionIndex          int
+	ActionType           lbdeploy.ActionType
+	ScriptFile           lbdeploy.FileResourceID
+	ScriptPath           string
+	CommandLine          string
+	WorkingDirectoryPath string
+}
+
+// Type returns the type of the event.
+func (e ScriptStarted) Type() lbevent.Type {
+	return ScriptStartedType
+}
+
+// Level returns the level of the event.
+func (e ScriptStarted) Level() slog.Level {
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e ScriptStarted) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	if e.ScriptFile != "" {
+		builder.WritePrimary(fmt.Sprintf("Starting the \"%s\" script", e.ScriptFile))
+	} else {
+		builder.WritePrimary("Starting inline script")
+	}
+	builder.WriteStandard(e.CommandLine)
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ScriptStarted) Details() string {
+	var out strings.Builder
+
+	if e.ScriptPath != "" {
+		out.WriteString(fmt.Sprintf("Script: %s", e.ScriptPath))
+	}
+
+	if e.WorkingDirectoryPath != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("Working Directory: %s", e.WorkingDirectoryPath))
+	}
+
+	return out.String()
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ScriptStarted) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("script", "file", e.ScriptFile, "path", e.ScriptPath, "invocation", e.CommandLine),
+		slog.String("working-directory", e.WorkingDirectoryPath),
+	}
+}
+
+// ScriptStopped is an event that occurs when a script has stopped.
+type ScriptStopped struct {
+	Deployment           lbdeploy.DeploymentID
+	Flow                 lbdeploy.FlowID
+	ActionIndex          int
+	ActionType           lbdeploy.ActionType
+	ScriptFile           lbdeploy.FileResourceID
+	ScriptPath           string
+	CommandLine          string
+	WorkingDirectoryPath string
+	Result               lbdeploy.CommandResult
+	Output               string
+	OutputDiscarded      int64
+	OutputPath           string
+	Started              time.Time
+	Stopped              time.Time
+	Err                  error
+}
+
+// Type returns the type of the event.
+func (e ScriptStopped) Type() lbevent.Type {
+	return ScriptStoppedType
+}
+
+// Level returns the level of the event.
+func (e ScriptStopped) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e ScriptStopped) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	if e.ScriptFile != "" {
+		builder.WritePrimary(string(e.ScriptFile))
+	}
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("Stopped script due to an error: %s", e.Err))
+	} else {
+		builder.WriteStandard("Completed script")
+	}
+	builder.WriteNote(e.Duration().Round(time.Millisecond * 10).String())
+	if e.Result.ExitCode != 0 {
+		builder.WriteNote(e.Result.String())
+	}
+	if e.OutputDiscarded > 0 {
+		builder.WriteNote(fmt.Sprintf("%d %s", e.OutputDiscarded, plural(e.OutputDiscarded, "byte", "bytes")), fieldformat.Label("output discarded"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ScriptStopped) Details() string {
+	var out strings.Builder
+
+	if e.ScriptPath != "" {
+		out.WriteString(fmt.Sprintf("Script: %s", e.ScriptPath))
+	}
+
+	if e.WorkingDirectoryPath != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("Working Directory: %s", e.WorkingDirectoryPath))
+	}
+
+	if e.OutputPath != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(fmt.Sprintf("Output File: %s", e.OutputPath))
+	}
+
+	if e.CommandLine != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n\n")
+		}
+		out.WriteString(e.CommandLine)
+	}
+
+	if e.Output != "" {
+		if out.Len() > 0 {
+			out.WriteString("\n\n")
+		}
+		out.WriteString(e.Output)
+	}
+
+	return withErrorHint(out.String(), e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ScriptStopped) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Group("script", "file", e.ScriptFile, "path", e.ScriptPath, "invocation", e.CommandLine),
+		slog.String("working-directory", e.WorkingDirectoryPath),
+		slog.Int("exit-code", int(e.Result.ExitCode)),
+		slog.Time("started", e.Started),
+		slog.Time("stopped", e.Stopped),
+	}
+	if e.Output != "" {
+		attrs = append(attrs, slog.String("output", e.Output))
+	}
+	if e.OutputDiscarded > 0 {
+		attrs = append(attrs, slog.Int64("output-discarded", e.OutputDiscarded))
+	}
+	if e.OutputPath != "" {
+		attrs = append(attrs, slog.String("output-file", e.OutputPath))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// Duration returns the duration of the script.
+func (e ScriptStopped) Duration() time.Duration {
+	return e.Stopped.Sub(e.Started)
+}
@@ -0,0 +1,289 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment security audit event types.
+//
+// These events duplicate information that is also recorded by other events,
+// but they are given their own event types and IDs so that security
+// monitoring rules can alert on privileged changes specifically.
+const (
+	SecurityFileChangeType         = lbevent.Type("deployment.security:file-change")
+	SecurityHostsFileChangeType    = lbevent.Type("deployment.security:hosts-file-change")
+	SecurityAntivirusExclusionType = lbevent.Type("deployment.security:antivirus-exclusion")
+	SecurityBitLockerChangeType    = lbevent.Type("deployment.security:bitlocker-change")
+	SecuritySignatureCheckType     = lbevent.Type("deployment.security:signature-check")
+	SecurityOwnershipChangeType    = lbevent.Type("deployment.security:ownership-change")
+	SecurityPrivilegeChangeType    = lbevent.Type("deployment.security:privilege-change")
+	SecurityReparsePointType       = lbevent.Type("deployment.security:reparse-point")
+)
+
+// SecurityChangeKind identifies the kind of security-relevant change
+// described by a [SecurityChange] event.
+type SecurityChangeKind string
+
+// Kinds of security-relevant changes.
+const (
+	SecurityChangeFile               SecurityChangeKind = "file"
+	SecurityChangeHostsFile          SecurityChangeKind = "hosts-file"
+	SecurityChangeAntivirusExclusion SecurityChangeKind = "antivirus-exclusion"
+	SecurityChangeBitLocker          SecurityChangeKind = "bitlocker"
+	SecurityChangeOwnership          SecurityChangeKind = "ownership"
+	SecurityChangePrivilege          SecurityChangeKind = "privilege"
+)
+
+// SecurityChange is an event that occurs when LeafBridge has made, or
+// attempted to make, a security-relevant change to the local system.
+//
+// Security-relevant changes include writing to folders that only
+// administrators can modify, editing the hosts file, adding or removing
+// antivirus exclusions, changing BitLocker protection, and taking ownership
+// of protected files with elevated privileges.
+//
+// Each kind of change has its own event type. Successful changes are
+// recorded as warnings and failed changes as errors, so that they stand
+// out from routine activity.
+type SecurityChange struct {
+	Deployment    lbdeploy.DeploymentID
+	Flow          lbdeploy.FlowID
+	ActionIndex   int
+	ActionType    lbdeploy.ActionType
+	Kind          SecurityChangeKind
+	Operation     string
+	Folder        lbdeploy.DirectoryResourceID
+	Target        string
+	PreviousOwner string
+	Err           error
+}
+
+// Type returns the type of the event.
+func (e SecurityChange) Type() lbevent.Type {
+	switch e.Kind {
+	case SecurityChangeHostsFile:
+		return SecurityHostsFileChangeType
+	case SecurityChangeAntivirusExclusion:
+		return SecurityAntivirusExclusionType
+	case SecurityChangeBitLocker:
+		return SecurityBitLockerChangeType
+	case SecurityChangeOwnership:
+		return SecurityOwnershipChangeType
+	case SecurityChangePrivilege:
+		return SecurityPrivilegeChangeType
+	default:
+		return SecurityFileChangeType
+	}
+}
+
+// Level returns the level of the event.
+func (e SecurityChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
+
+// Message returns a description of the event.
+func (e SecurityChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	if e.Flow != "" {
+		builder.WritePrimary(string(e.Flow))
+		builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+		builder.WritePrimary(string(e.ActionType))
+	}
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("A privileged %s change (%s) of %s failed: %s.", e.Kind, e.Operation, e.Target, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("A privileged %s change (%s) was made to %s.", e.Kind, e.Operation, e.Target))
+	}
+
+	if e.Folder != "" {
+		builder.WriteNote(string(e.Folder), fieldformat.Label("folder"))
+	}
+	if e.PreviousOwner != "" {
+		builder.WriteNote(e.PreviousOwner, fieldformat.Label("previous owner"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e SecurityChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e SecurityChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+	}
+	if e.Flow != "" {
+		attrs = append(attrs,
+			slog.String("flow", string(e.Flow)),
+			slog.Group("action", "index", e.ActionIndex, "type", e.ActionType))
+	}
+	attrs = append(attrs,
+		slog.String("kind", string(e.Kind)),
+		slog.String("operation", e.Operation),
+		slog.String("target", e.Target))
+	if e.Folder != "" {
+		attrs = append(attrs, slog.String("folder", string(e.Folder)))
+	}
+	if e.PreviousOwner != "" {
+		attrs = append(attrs, slog.String("previous-owner", e.PreviousOwner))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// SignatureCheck is an event that occurs when the Authenticode signature of
+// a file extracted from an archive package has been verified immediately
+// before the file is run.
+//
+// Files that fail the check are not run. Failures are recorded as errors,
+// because they indicate that the file might have been tampered with after
+// the package was downloaded and verified.
+type SignatureCheck struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Package     lbdeploy.PackageID
+	File        lbdeploy.PackageFileID
+	Path        string
+	Signer      string
+	Thumbprint  string
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e SignatureCheck) Type() lbevent.Type {
+	return SecuritySignatureCheckType
+}
+
+// Level returns the level of the event.
+func (e SignatureCheck) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e SignatureCheck) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" file extracted from the \"%s\" package failed its signature check and will not be run: %s.", e.File, e.Package, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" file extracted from the \"%s\" package is signed by \"%s\".", e.File, e.Package, e.Signer))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e SignatureCheck) Details() string {
+	if e.Path == "" {
+		return withErrorHint("", e.Err)
+	}
+	return withErrorHint(fmt.Sprintf("Path: %s", e.Path), e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e SignatureCheck) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("package", string(e.Package)),
+		slog.Group("file", "id", e.File, "path", e.Path),
+		slog.Group("signer", "subject", e.Signer, "thumbprint", e.Thumbprint),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
+
+// ReparsePointBlocked is an event that occurs when a file action has been
+// refused because the file, or a directory above it, is a junction,
+// symbolic link or other reparse point that its directory resource does
+// not allow.
+//
+// Unexpected reparse points can redirect changes to files outside of the
+// intended location, so these events are recorded as errors.
+type ReparsePointBlocked struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	File        lbdeploy.FileResourceID
+	Path        string
+	Kind        string
+}
+
+// Type returns the type of the event.
+func (e ReparsePointBlocked) Type() lbevent.Type {
+	return SecurityReparsePointType
+}
+
+// Level returns the level of the event.
+func (e ReparsePointBlocked) Level() slog.Level {
+	return slog.LevelError
+}
+
+// Message returns a description of the event.
+func (e ReparsePointBlocked) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	builder.WriteStandard(fmt.Sprintf("The \"%s\" file was not modified because %s is an unexpected %s.", e.File, e.Path, e.Kind))
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ReparsePointBlocked) Details() string {
+	return "If the reparse point is intentional, set allow-reparse-point on its directory resource."
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ReparsePointBlocked) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("file", string(e.File)),
+		slog.String("path", e.Path),
+		slog.String("kind", e.Kind),
+	}
+}
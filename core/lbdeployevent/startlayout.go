@@ -0,0 +1,102 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment start layout event types.
+const (
+	StartLayoutChangeType = lbevent.Type("deployment.start-layout:change")
+)
+
+// StartLayoutChange is an event that occurs when a set-start-layout or
+// clear-start-layout action has been applied to the local system.
+type StartLayoutChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	SourcePath  string
+	ProfilePath string
+	PolicyPath  string
+	Cleared     bool
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e StartLayoutChange) Type() lbevent.Type {
+	return StartLayoutChangeType
+}
+
+// Level returns the level of the event.
+func (e StartLayoutChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e StartLayoutChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil && e.Cleared:
+		builder.WriteStandard(fmt.Sprintf("The Start layout could not be cleared due to an error: %s.", e.Err))
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The Start layout could not be deployed due to an error: %s.", e.Err))
+	case e.Cleared:
+		builder.WriteStandard("The Start layout was cleared.")
+	case e.PolicyPath != "":
+		builder.WriteStandard("The Start layout was deployed to the default user profile and enforced through group policy.")
+	default:
+		builder.WriteStandard("The Start layout was deployed to the default user profile.")
+	}
+	if e.SourcePath != "" {
+		builder.WriteNote(e.SourcePath, fieldformat.Label("source"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e StartLayoutChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e StartLayoutChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.Bool("cleared", e.Cleared),
+	}
+	if e.SourcePath != "" {
+		attrs = append(attrs, slog.String("source", e.SourcePath))
+	}
+	if e.ProfilePath != "" {
+		attrs = append(attrs, slog.String("profile-path", e.ProfilePath))
+	}
+	if e.PolicyPath != "" {
+		attrs = append(attrs, slog.String("policy-path", e.PolicyPath))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
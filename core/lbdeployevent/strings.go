@@ -2,6 +2,7 @@ package lbdeployevent
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,14 @@ func plural[T ~int | ~int64](value T, singular, plural string) string {
 	return plural
 }
 
+func joinIDs[T ~string](ids []T) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = string(id)
+	}
+	return strings.Join(values, ", ")
+}
+
 func bitrate(transferred int64, duration time.Duration) string {
 	if transferred == 0 || duration == 0 {
 		return "0"
@@ -0,0 +1,90 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment time zone event types.
+const (
+	TimeZoneChangeType = lbevent.Type("deployment.time-zone:change")
+)
+
+// TimeZoneChange is an event that occurs when a set-time-zone action has
+// been applied to the local system.
+type TimeZoneChange struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Previous    string
+	TimeZone    string
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e TimeZoneChange) Type() lbevent.Type {
+	return TimeZoneChangeType
+}
+
+// Level returns the level of the event.
+func (e TimeZoneChange) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e TimeZoneChange) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The time zone could not be set to \"%s\" due to an error: %s.", e.TimeZone, e.Err))
+	case e.Previous == e.TimeZone:
+		builder.WriteStandard(fmt.Sprintf("The time zone was already set to \"%s\".", e.TimeZone))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The time zone was set to \"%s\".", e.TimeZone))
+		if e.Previous != "" {
+			builder.WriteNote(e.Previous, fieldformat.Label("previous"))
+		}
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e TimeZoneChange) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e TimeZoneChange) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Group("action", "index", e.ActionIndex, "type", e.ActionType),
+		slog.String("time-zone", e.TimeZone),
+	}
+	if e.Previous != "" {
+		attrs = append(attrs, slog.String("previous", e.Previous))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
@@ -0,0 +1,86 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Deployment validity event types.
+const (
+	OutsideValidityWindowType = lbevent.Type("deployment.validity:outside-window")
+)
+
+// OutsideValidityWindow is an event that occurs when a deployment or flow
+// is not run because the current time falls outside of its validity
+// window.
+//
+// If FlowWindow is true, the window belongs to the flow. Otherwise it
+// belongs to the deployment.
+type OutsideValidityWindow struct {
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	FlowWindow bool
+	Window     lbdeploy.ValidityWindow
+	Checked    time.Time
+}
+
+// Type returns the type of the event.
+func (e OutsideValidityWindow) Type() lbevent.Type {
+	return OutsideValidityWindowType
+}
+
+// Level returns the level of the event.
+func (e OutsideValidityWindow) Level() slog.Level {
+	return slog.LevelError
+}
+
+// Message returns a description of the event.
+func (e OutsideValidityWindow) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+
+	subject := "deployment"
+	if e.FlowWindow {
+		subject = "flow"
+	}
+
+	switch {
+	case !e.Window.NotBefore.IsZero() && e.Checked.Before(e.Window.NotBefore):
+		builder.WriteStandard(fmt.Sprintf("The flow was not started because the %s is not valid until %s.", subject, e.Window.NotBefore.Format(time.RFC3339)))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The flow was not started because the %s expired on %s.", subject, e.Window.NotAfter.Format(time.RFC3339)))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e OutsideValidityWindow) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e OutsideValidityWindow) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Bool("flow-window", e.FlowWindow),
+	}
+	if !e.Window.NotBefore.IsZero() {
+		attrs = append(attrs, slog.Time("not-before", e.Window.NotBefore))
+	}
+	if !e.Window.NotAfter.IsZero() {
+		attrs = append(attrs, slog.Time("not-after", e.Window.NotAfter))
+	}
+	attrs = append(attrs, slog.Time("checked", e.Checked))
+	return attrs
+}
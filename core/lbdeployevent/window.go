@@ -0,0 +1,127 @@
+package lbdeployevent
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Window event types.
+const (
+	CommandWindowType = lbevent.Type("deployment.command:window")
+)
+
+// CommandWindow is an event that occurs when the window watchdog of a
+// command observes an interactive window, or when it responds to one.
+//
+// Rule is the one-based index of the watchdog rule that matched the
+// window, or zero if no rule matched. Action is the action that was taken,
+// which is empty when the window was only observed.
+type CommandWindow struct {
+	Deployment  lbdeploy.DeploymentID
+	Flow        lbdeploy.FlowID
+	ActionIndex int
+	ActionType  lbdeploy.ActionType
+	Package     lbdeploy.PackageID
+	Command     lbdeploy.CommandID
+	ProcessID   uint32
+	Title       string
+	Class       string
+	Rule        int
+	Action      lbdeploy.WindowAction
+	Err         error
+}
+
+// Type returns the type of the event.
+func (e CommandWindow) Type() lbevent.Type {
+	return CommandWindowType
+}
+
+// Level returns the level of the event.
+func (e CommandWindow) Level() slog.Level {
+	switch {
+	case e.Err != nil, e.Action == lbdeploy.WindowFail:
+		return slog.LevelError
+	case e.Rule == 0:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Message returns a description of the event.
+func (e CommandWindow) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary(string(e.Deployment))
+	builder.WritePrimary(string(e.Flow))
+	builder.WritePrimary(strconv.Itoa(e.ActionIndex + 1))
+	builder.WritePrimary(string(e.ActionType))
+	if e.Package == "" {
+		builder.WritePrimary(string(e.Command))
+	} else {
+		builder.WritePrimary(fmt.Sprintf("%s.%s", e.Package, e.Command))
+	}
+
+	title := fmt.Sprintf("\"%s\"", e.Title)
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("Failed to respond to the %s window: %s.", title, e.Err))
+	case e.Action == lbdeploy.WindowFail && e.Rule == 0:
+		builder.WriteStandard(fmt.Sprintf("Terminating the command because the unexpected %s window is still open.", title))
+	case e.Action == lbdeploy.WindowFail:
+		builder.WriteStandard(fmt.Sprintf("Terminating the command because it opened the %s window.", title))
+	case e.Action == lbdeploy.WindowClose:
+		builder.WriteStandard(fmt.Sprintf("Closed the %s window.", title))
+	case e.Action == lbdeploy.WindowClick:
+		builder.WriteStandard(fmt.Sprintf("Dismissed the %s window.", title))
+	case e.Action == lbdeploy.WindowIgnore:
+		builder.WriteStandard(fmt.Sprintf("Ignoring the %s window.", title))
+	default:
+		builder.WriteStandard(fmt.Sprintf("The command opened an unexpected %s window.", title))
+	}
+
+	if e.Rule > 0 {
+		builder.WriteNote(fmt.Sprintf("rule %d", e.Rule))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e CommandWindow) Details() string {
+	return withErrorHint("", e.Err)
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e CommandWindow) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("deployment", string(e.Deployment)),
+		slog.String("flow", string(e.Flow)),
+		slog.Int("action", e.ActionIndex+1),
+		slog.String("action-type", string(e.ActionType)),
+		slog.String("command", string(e.Command)),
+		slog.Int64("pid", int64(e.ProcessID)),
+		slog.String("title", e.Title),
+		slog.String("class", e.Class),
+	}
+	if e.Package != "" {
+		attrs = append(attrs, slog.String("package", string(e.Package)))
+	}
+	if e.Rule > 0 {
+		attrs = append(attrs, slog.Int("rule", e.Rule))
+	}
+	if e.Action != "" {
+		attrs = append(attrs, slog.String("response", string(e.Action)))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, errorAttrs(e.Err)...)
+	}
+	return attrs
+}
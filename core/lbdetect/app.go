@@ -0,0 +1,80 @@
+package lbdetect
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Uninstall registry keys that hold the Windows application registry.
+const (
+	uninstallKey    = `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`
+	uninstallKeyX86 = `SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`
+)
+
+// Detectable returns the IDs of applications in the deployment that can be
+// detected on Windows, in sorted order.
+//
+// An application can be detected if it has a presence condition or a
+// product code.
+func Detectable(dep lbdeploy.Deployment) lbdeploy.AppList {
+	var list lbdeploy.AppList
+	for _, id := range slices.Sorted(maps.Keys(dep.Apps)) {
+		app := dep.Apps[id]
+		if app.Detection.Present != "" || app.ProductCode != "" {
+			list = append(list, id)
+		}
+	}
+	return list
+}
+
+// lookupApp returns the definition of an application that can be detected
+// on Windows.
+func lookupApp(dep lbdeploy.Deployment, id lbdeploy.AppID) (lbdeploy.Application, error) {
+	app, found := dep.Apps[id]
+	if !found {
+		return app, fmt.Errorf("the \"%s\" app does not exist within the \"%s\" deployment", id, dep.ID)
+	}
+	if app.Detection.Present == "" && app.ProductCode == "" {
+		return app, fmt.Errorf("the \"%s\" app does not have a presence condition or a product code", id)
+	}
+	return app, nil
+}
+
+// uninstallEntry returns the path of the application's entry in the Windows
+// application registry, as seen by a 64-bit process.
+func uninstallEntry(app lbdeploy.Application) string {
+	switch {
+	case app.Scope == "user":
+		return `HKEY_CURRENT_USER\` + uninstallKey + `\` + string(app.ProductCode)
+	case app.Architecture == "x86":
+		return `HKEY_LOCAL_MACHINE\` + uninstallKeyX86 + `\` + string(app.ProductCode)
+	default:
+		return `HKEY_LOCAL_MACHINE\` + uninstallKey + `\` + string(app.ProductCode)
+	}
+}
+
+// isInstallerProductCode returns true if code is a Windows Installer
+// product code, which is a GUID in braces.
+func isInstallerProductCode(code lbdeploy.ProductCode) bool {
+	s := string(code)
+	if len(s) != 38 || s[0] != '{' || s[37] != '}' {
+		return false
+	}
+	for i, c := range s[1:37] {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
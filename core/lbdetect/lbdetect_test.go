@@ -0,0 +1,135 @@
+package lbdetect_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdetect"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+var detectionDeployment = lbdeploy.Deployment{
+	ID: "example",
+	Apps: lbdeploy.AppMap{
+		"installer": {Name: "Installer App", Architecture: "x64", Scope: "machine", ProductCode: "{12345678-90AB-CDEF-1234-567890ABCDEF}"},
+		"legacy":    {Name: "Legacy App", Architecture: "x86", Scope: "machine", ProductCode: "Legacy App"},
+		"tool":      {Name: "Tool", Detection: lbdeploy.AppDetection{Present: "tool-present"}},
+		"either":    {Name: "Either", Detection: lbdeploy.AppDetection{Present: "either-present"}},
+		"linux":     {Name: "Linux", Package: lbdeploy.DistroPackage{Name: "linux"}},
+	},
+	Conditions: lbdeploy.ConditionMap{
+		"tool-present": {All: []lbdeploy.Condition{
+			{Type: lbdeploy.ConditionTypeFileExists, Subject: "tool-exe"},
+			{Type: lbdeploy.ConditionTypeSubcondition, Subject: "tool-version"},
+		}},
+		"tool-version": {
+			Type:       lbdeploy.ConditionTypeRegistryValueComparison,
+			Subject:    "tool-version",
+			Comparison: lbvalue.CompareGreaterThanOrEquals,
+			Value:      lbvalue.Version(datatype.Version("2.1")),
+		},
+		"either-present": {Any: []lbdeploy.Condition{
+			{Type: lbdeploy.ConditionTypeDirectoryExists, Subject: "tool-dir"},
+			{Type: lbdeploy.ConditionTypeRegistryKeyExists, Subject: "tool-key", Negated: true},
+		}},
+	},
+	Resources: lbdeploy.Resources{
+		Registry: lbdeploy.RegistryResources{
+			Keys: lbdeploy.RegistryKeyResourceMap{
+				"vendor":   {Location: "software", Name: "Vendor"},
+				"tool-key": {Location: "vendor", Path: "Tool/Settings"},
+			},
+			Values: lbdeploy.RegistryValueResourceMap{
+				"tool-version": {Key: "tool-key", Name: "Version", Type: lbvalue.KindVersion},
+			},
+		},
+		FileSystem: lbdeploy.FileSystemResources{
+			Directories: lbdeploy.DirectoryResourceMap{
+				"tool-dir": {Location: "program-files-x86", Path: "Vendor/Tool"},
+			},
+			Files: lbdeploy.FileResourceMap{
+				"tool-exe": {Location: "tool-dir", Path: "bin/tool.exe"},
+			},
+		},
+	},
+}
+
+func TestDetectable(t *testing.T) {
+	got := lbdetect.Detectable(detectionDeployment)
+	want := lbdeploy.AppList{"either", "installer", "legacy", "tool"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRules(t *testing.T) {
+	fixtures := []struct {
+		App   lbdeploy.AppID
+		Rules []lbdetect.Rule
+		Err   bool
+	}{
+		{
+			App: "installer",
+			Rules: []lbdetect.Rule{
+				{Type: lbdetect.RuleTypeProductCode, ProductCode: "{12345678-90AB-CDEF-1234-567890ABCDEF}"},
+			},
+		},
+		{
+			App: "legacy",
+			Rules: []lbdetect.Rule{
+				{Type: lbdetect.RuleTypeRegistry, KeyPath: `HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall\Legacy App`, Check32BitOn64System: true, DetectionType: "exists"},
+			},
+		},
+		{
+			App: "tool",
+			Rules: []lbdetect.Rule{
+				{Type: lbdetect.RuleTypeFileSystem, Path: `%ProgramFiles(x86)%\Vendor\Tool\bin`, FileOrFolderName: "tool.exe", DetectionType: "exists"},
+				{Type: lbdetect.RuleTypeRegistry, KeyPath: `HKEY_LOCAL_MACHINE\SOFTWARE\Vendor\Tool\Settings`, ValueName: "Version", DetectionType: "version", Operator: "greaterThanOrEqual", DetectionValue: "2.1"},
+			},
+		},
+		{App: "either", Err: true},
+		{App: "linux", Err: true},
+		{App: "missing", Err: true},
+	}
+
+	for _, fixture := range fixtures {
+		rules, err := lbdetect.Rules(detectionDeployment, lbdeploy.AppList{fixture.App})
+		if fixture.Err {
+			if err == nil {
+				t.Errorf("%s: expected an error", fixture.App)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", fixture.App, err)
+			continue
+		}
+		if !slices.Equal(rules, fixture.Rules) {
+			t.Errorf("%s: got %+v, want %+v", fixture.App, rules, fixture.Rules)
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+	script, err := lbdetect.Script(detectionDeployment, lbdeploy.AppList{"legacy", "either"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`(Test-Path -LiteralPath 'Registry::HKEY_LOCAL_MACHINE\SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\Legacy App')`,
+		`((Test-Path -LiteralPath (Join-Path ${env:ProgramFiles(x86)} 'Vendor\Tool') -PathType Container) -or (-not (Test-Path -LiteralPath 'Registry::HKEY_LOCAL_MACHINE\SOFTWARE\Vendor\Tool\Settings')))`,
+		"Write-Output 'Detected: legacy, either'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("the script does not contain %s:\n%s", want, script)
+		}
+	}
+
+	if _, err := lbdetect.Script(detectionDeployment, lbdeploy.AppList{"linux"}); err == nil {
+		t.Errorf("linux: expected an error")
+	}
+}
@@ -0,0 +1,194 @@
+package lbdetect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// knownFolder describes the location of a well-known directory in terms of
+// an environment variable that is available to detection scripts and
+// detection rules.
+type knownFolder struct {
+	variable string
+	path     string
+}
+
+// knownFolders maps the well-known directory IDs recognized by LeafBridge
+// to their locations on Windows.
+var knownFolders = map[lbdeploy.DirectoryResourceID]knownFolder{
+	"common-start-menu": {variable: "ProgramData", path: `Microsoft\Windows\Start Menu`},
+	"public-desktop":    {variable: "PUBLIC", path: "Desktop"},
+	"program-data":      {variable: "ProgramData"},
+	"program-files":     {variable: "ProgramFiles"},
+	"program-files-x86": {variable: "ProgramFiles(x86)"},
+	"program-files-x64": {variable: "ProgramW6432"},
+	"system":            {variable: "SystemRoot", path: "System32"},
+}
+
+// registryRoots maps the well-known registry root IDs recognized by
+// LeafBridge to their paths within the Windows registry.
+var registryRoots = map[lbdeploy.RegistryKeyResourceID]string{
+	"software": `HKEY_LOCAL_MACHINE\SOFTWARE`,
+}
+
+// fsLocation is the location of a file or directory relative to an
+// environment variable.
+type fsLocation struct {
+	variable string
+	path     string
+}
+
+// Split returns the location of the parent directory and the name of the
+// file or directory within it.
+func (loc fsLocation) Split() (parent fsLocation, name string, ok bool) {
+	i := strings.LastIndexByte(loc.path, '\\')
+	if i < 0 {
+		if loc.path == "" {
+			return fsLocation{}, "", false
+		}
+		return fsLocation{variable: loc.variable}, loc.path, true
+	}
+	return fsLocation{variable: loc.variable, path: loc.path[:i]}, loc.path[i+1:], true
+}
+
+// String returns the location with its environment variable in the
+// %VARIABLE% form.
+func (loc fsLocation) String() string {
+	if loc.path == "" {
+		return "%" + loc.variable + "%"
+	}
+	return "%" + loc.variable + `%\` + loc.path
+}
+
+// PowerShell returns a PowerShell expression that evaluates to the path of
+// the location.
+func (loc fsLocation) PowerShell() string {
+	variable := "${env:" + loc.variable + "}"
+	if loc.path == "" {
+		return variable
+	}
+	return "(Join-Path " + variable + " " + quote(loc.path) + ")"
+}
+
+// resolveDirectory determines the location of a directory resource.
+func resolveDirectory(resources lbdeploy.FileSystemResources, id lbdeploy.DirectoryResourceID) (fsLocation, error) {
+	var segments []string
+	seen := make(map[lbdeploy.DirectoryResourceID]bool)
+	next := id
+	for {
+		if seen[next] {
+			return fsLocation{}, fmt.Errorf("the \"%s\" directory has a cyclic reference to itself in the deployment's file system resources", next)
+		}
+		seen[next] = true
+
+		if dir, found := resources.Directories[next]; found {
+			if dir.Location == "" {
+				return fsLocation{}, fmt.Errorf("the \"%s\" directory does not have a location", next)
+			}
+			segments = append(segments, windowsPath(dir.Path))
+			next = dir.Location
+			continue
+		}
+
+		folder, found := knownFolders[next]
+		if !found {
+			return fsLocation{}, fmt.Errorf("the \"%s\" directory is not defined in the deployment's resources", next)
+		}
+		segments = append(segments, folder.path)
+		return fsLocation{variable: folder.variable, path: joinReversed(segments)}, nil
+	}
+}
+
+// resolveFile determines the location of a file resource.
+func resolveFile(resources lbdeploy.FileSystemResources, id lbdeploy.FileResourceID) (fsLocation, error) {
+	file, found := resources.Files[id]
+	if !found {
+		return fsLocation{}, fmt.Errorf("the \"%s\" file is not defined in the deployment's resources", id)
+	}
+	if file.Path == "" {
+		return fsLocation{}, fmt.Errorf("the \"%s\" file does not have a path", id)
+	}
+	dir, err := resolveDirectory(resources, file.Location)
+	if err != nil {
+		return fsLocation{}, fmt.Errorf("the \"%s\" file could not be located: %w", id, err)
+	}
+	dir.path = joinReversed([]string{windowsPath(file.Path), dir.path})
+	return dir, nil
+}
+
+// resolveRegistryKey determines the path of a registry key resource.
+func resolveRegistryKey(resources lbdeploy.RegistryResources, id lbdeploy.RegistryKeyResourceID) (string, error) {
+	var segments []string
+	seen := make(map[lbdeploy.RegistryKeyResourceID]bool)
+	next := id
+	for {
+		if seen[next] {
+			return "", fmt.Errorf("the \"%s\" registry key has a cyclic reference to itself in the deployment's registry resources", next)
+		}
+		seen[next] = true
+
+		if key, found := resources.Keys[next]; found {
+			if key.Location == "" {
+				return "", fmt.Errorf("the \"%s\" registry key does not have a location", next)
+			}
+			switch {
+			case key.Name != "":
+				segments = append(segments, key.Name)
+			case key.Path != "":
+				segments = append(segments, windowsPath(key.Path))
+			default:
+				return "", fmt.Errorf("the \"%s\" registry key does not specify a name or path", next)
+			}
+			next = key.Location
+			continue
+		}
+
+		root, found := registryRoots[next]
+		if !found {
+			return "", fmt.Errorf("the \"%s\" registry key is not defined in the deployment's resources", next)
+		}
+		segments = append(segments, root)
+		return joinReversed(segments), nil
+	}
+}
+
+// resolveRegistryValue determines the path of the registry key that holds
+// a registry value resource, along with the value's definition.
+func resolveRegistryValue(resources lbdeploy.RegistryResources, id lbdeploy.RegistryValueResourceID) (key string, value lbdeploy.RegistryValueResource, err error) {
+	value, found := resources.Values[id]
+	if !found {
+		return "", value, fmt.Errorf("the \"%s\" registry value is not defined in the deployment's resources", id)
+	}
+	if value.Key == "" {
+		return "", value, fmt.Errorf("the \"%s\" registry value does not have a key", id)
+	}
+	key, err = resolveRegistryKey(resources, value.Key)
+	if err != nil {
+		return "", value, fmt.Errorf("the \"%s\" registry value could not be located: %w", id, err)
+	}
+	return key, value, nil
+}
+
+// windowsPath converts forward slashes in a relative path to backslashes
+// and trims any leading or trailing separators.
+func windowsPath(path string) string {
+	return strings.Trim(strings.ReplaceAll(path, "/", `\`), `\`)
+}
+
+// joinReversed joins the non-empty segments with backslashes, starting
+// with the last segment.
+func joinReversed(segments []string) string {
+	var out strings.Builder
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] == "" {
+			continue
+		}
+		if out.Len() > 0 {
+			out.WriteByte('\\')
+		}
+		out.WriteString(segments[i])
+	}
+	return out.String()
+}
@@ -0,0 +1,247 @@
+package lbdetect
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// Types of detection rules, identified by the Microsoft Graph types used
+// for Intune Win32 apps.
+const (
+	RuleTypeProductCode = "#microsoft.graph.win32LobAppProductCodeDetection"
+	RuleTypeRegistry    = "#microsoft.graph.win32LobAppRegistryDetection"
+	RuleTypeFileSystem  = "#microsoft.graph.win32LobAppFileSystemDetection"
+)
+
+// Rule is a detection rule for an Intune Win32 app. Its fields match the
+// detection rule types of Microsoft Graph.
+//
+// An application is detected when all of its detection rules are
+// satisfied.
+type Rule struct {
+	Type                 string `json:"@odata.type"`
+	ProductCode          string `json:"productCode,omitempty"`
+	KeyPath              string `json:"keyPath,omitempty"`
+	ValueName            string `json:"valueName,omitempty"`
+	Path                 string `json:"path,omitempty"`
+	FileOrFolderName     string `json:"fileOrFolderName,omitempty"`
+	Check32BitOn64System bool   `json:"check32BitOn64System,omitempty"`
+	DetectionType        string `json:"detectionType,omitempty"`
+	Operator             string `json:"operator,omitempty"`
+	DetectionValue       string `json:"detectionValue,omitempty"`
+}
+
+// Rules returns a set of detection rules for the given applications within
+// the deployment.
+//
+// Applications with a presence condition are detected by translating the
+// condition into rules. Only conditions that check for the existence of
+// registry keys, registry values, files and directories, or that compare
+// registry values, can be translated. Conditions may be combined with
+// "all" and referenced as subconditions, but "any" conditions with more
+// than one member cannot be expressed as rules. Such applications can be
+// detected with a script instead.
+//
+// Other applications are detected by their product code. If the product
+// code is a Windows Installer product code, a product code rule is
+// returned. Otherwise, a rule that looks for the application in the
+// Windows application registry is returned.
+func Rules(dep lbdeploy.Deployment, apps lbdeploy.AppList) ([]Rule, error) {
+	if len(apps) == 0 {
+		return nil, fmt.Errorf("no applications were provided for the \"%s\" deployment", dep.ID)
+	}
+
+	var rules []Rule
+	for _, id := range apps {
+		app, err := lookupApp(dep, id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case app.Detection.Present != "":
+			translated, err := conditionRules(dep, app.Detection.Present, make(map[lbdeploy.ConditionID]bool))
+			if err != nil {
+				return nil, fmt.Errorf("the \"%s\" app cannot be detected by rules: %w", id, err)
+			}
+			rules = append(rules, translated...)
+		case isInstallerProductCode(app.ProductCode):
+			rules = append(rules, Rule{
+				Type:        RuleTypeProductCode,
+				ProductCode: string(app.ProductCode),
+			})
+		default:
+			rule := Rule{
+				Type:          RuleTypeRegistry,
+				KeyPath:       uninstallEntry(app),
+				DetectionType: "exists",
+			}
+			if app.Scope != "user" && app.Architecture == "x86" {
+				// Let the Intune agent apply registry redirection.
+				rule.KeyPath = `HKEY_LOCAL_MACHINE\` + uninstallKey + `\` + string(app.ProductCode)
+				rule.Check32BitOn64System = true
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// conditionRules returns the detection rules that express the named
+// condition.
+func conditionRules(dep lbdeploy.Deployment, id lbdeploy.ConditionID, seen map[lbdeploy.ConditionID]bool) ([]Rule, error) {
+	if seen[id] {
+		return nil, fmt.Errorf("the \"%s\" condition has a cyclic reference to itself", id)
+	}
+	condition, found := dep.Conditions[id]
+	if !found {
+		return nil, fmt.Errorf("the \"%s\" condition is not defined in the deployment", id)
+	}
+
+	seen[id] = true
+	defer delete(seen, id)
+
+	rules, err := translateCondition(dep, condition, seen)
+	if err != nil {
+		return nil, fmt.Errorf("the \"%s\" condition: %w", id, err)
+	}
+	return rules, nil
+}
+
+// translateCondition returns the detection rules that express the given
+// condition.
+func translateCondition(dep lbdeploy.Deployment, condition lbdeploy.Condition, seen map[lbdeploy.ConditionID]bool) ([]Rule, error) {
+	// Combinations of conditions can only be negated as a whole in a
+	// script.
+	if condition.Negated && (len(condition.Any) > 0 || len(condition.All) > 0 || condition.Type == lbdeploy.ConditionTypeSubcondition) {
+		return nil, fmt.Errorf("negated combinations of conditions are not supported")
+	}
+
+	switch {
+	case len(condition.Any) > 1:
+		return nil, fmt.Errorf("\"any\" conditions with more than one member are not supported")
+	case len(condition.Any) == 1:
+		return translateCondition(dep, condition.Any[0], seen)
+	case len(condition.All) > 0:
+		var rules []Rule
+		for i, member := range condition.All {
+			translated, err := translateCondition(dep, member, seen)
+			if err != nil {
+				return nil, fmt.Errorf("subcondition %d: %w", i+1, err)
+			}
+			rules = append(rules, translated...)
+		}
+		return rules, nil
+	}
+
+	exists := "exists"
+	if condition.Negated {
+		exists = "doesNotExist"
+	}
+
+	switch condition.Type {
+	case lbdeploy.ConditionTypeSubcondition:
+		return conditionRules(dep, lbdeploy.ConditionID(condition.Subject), seen)
+	case lbdeploy.ConditionTypeRegistryKeyExists:
+		key, err := resolveRegistryKey(dep.Resources.Registry, lbdeploy.RegistryKeyResourceID(condition.Subject))
+		if err != nil {
+			return nil, err
+		}
+		return []Rule{{Type: RuleTypeRegistry, KeyPath: key, DetectionType: exists}}, nil
+	case lbdeploy.ConditionTypeRegistryValueExists:
+		key, value, err := resolveRegistryValue(dep.Resources.Registry, lbdeploy.RegistryValueResourceID(condition.Subject))
+		if err != nil {
+			return nil, err
+		}
+		return []Rule{{Type: RuleTypeRegistry, KeyPath: key, ValueName: value.Name, DetectionType: exists}}, nil
+	case lbdeploy.ConditionTypeRegistryValueComparison:
+		if condition.Negated {
+			return nil, fmt.Errorf("negated comparisons are not supported")
+		}
+		key, value, err := resolveRegistryValue(dep.Resources.Registry, lbdeploy.RegistryValueResourceID(condition.Subject))
+		if err != nil {
+			return nil, err
+		}
+		detectionType, detectionValue, err := ruleValue(condition.Value)
+		if err != nil {
+			return nil, err
+		}
+		operator, err := ruleOperator(condition.Comparison)
+		if err != nil {
+			return nil, err
+		}
+		return []Rule{{
+			Type:           RuleTypeRegistry,
+			KeyPath:        key,
+			ValueName:      value.Name,
+			DetectionType:  detectionType,
+			Operator:       operator,
+			DetectionValue: detectionValue,
+		}}, nil
+	case lbdeploy.ConditionTypeDirectoryExists:
+		dir, err := resolveDirectory(dep.Resources.FileSystem, lbdeploy.DirectoryResourceID(condition.Subject))
+		if err != nil {
+			return nil, err
+		}
+		return fileSystemRule(dir, exists)
+	case lbdeploy.ConditionTypeFileExists:
+		file, err := resolveFile(dep.Resources.FileSystem, lbdeploy.FileResourceID(condition.Subject))
+		if err != nil {
+			return nil, err
+		}
+		return fileSystemRule(file, exists)
+	default:
+		return nil, fmt.Errorf("conditions of type \"%s\" are not supported", condition.Type)
+	}
+}
+
+// fileSystemRule returns a file system detection rule for the location.
+func fileSystemRule(loc fsLocation, detectionType string) ([]Rule, error) {
+	parent, name, ok := loc.Split()
+	if !ok {
+		return nil, fmt.Errorf("the %s directory cannot be detected by a file system rule", loc)
+	}
+	return []Rule{{
+		Type:             RuleTypeFileSystem,
+		Path:             parent.String(),
+		FileOrFolderName: name,
+		DetectionType:    detectionType,
+	}}, nil
+}
+
+// ruleOperator returns the detection rule operator for a comparison.
+func ruleOperator(comparison lbvalue.Comparison) (string, error) {
+	switch comparison {
+	case lbvalue.CompareEquals:
+		return "equal", nil
+	case lbvalue.CompareLessThan:
+		return "lessThan", nil
+	case lbvalue.CompareLessThanOrEquals:
+		return "lessThanOrEqual", nil
+	case lbvalue.CompareGreaterThan:
+		return "greaterThan", nil
+	case lbvalue.CompareGreaterThanOrEquals:
+		return "greaterThanOrEqual", nil
+	default:
+		return "", fmt.Errorf("the \"%s\" comparison is not supported", comparison)
+	}
+}
+
+// ruleValue returns the detection type and detection value for a value
+// that is compared by a detection rule.
+func ruleValue(value lbvalue.Value) (detectionType, detectionValue string, err error) {
+	switch value.Kind() {
+	case lbvalue.KindInt64:
+		return "integer", strconv.FormatInt(value.Int64(), 10), nil
+	case lbvalue.KindString:
+		return "string", value.String(), nil
+	case lbvalue.KindVersion:
+		return "version", string(value.Version()), nil
+	default:
+		return "", "", fmt.Errorf("comparisons of %s values are not supported", value.Kind())
+	}
+}
@@ -0,0 +1,224 @@
+package lbdetect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// scriptPreamble is included at the start of every detection script.
+const scriptPreamble = `$ErrorActionPreference = 'SilentlyContinue'
+
+function Test-RegistryValue([string]$Path, [string]$Name, [scriptblock]$Test = { $true }) {
+    $item = Get-ItemProperty -LiteralPath $Path -Name $Name -ErrorAction SilentlyContinue
+    if ($null -eq $item) {
+        return $false
+    }
+    try {
+        return [bool](& $Test $item.$Name)
+    } catch {
+        return $false
+    }
+}
+`
+
+// Script returns a PowerShell detection script for the given applications
+// within the deployment.
+//
+// The script follows the conventions of detection scripts for Intune Win32
+// apps and Configuration Manager applications: when every application is
+// detected it writes a message to standard output and exits with code 0.
+// Otherwise it exits with code 1 without writing to standard output.
+//
+// Applications with a presence condition are detected by evaluating the
+// condition. Other applications are detected by looking for their product
+// code in the Windows application registry. Registry paths assume that the
+// script runs as a 64-bit process.
+func Script(dep lbdeploy.Deployment, apps lbdeploy.AppList) (string, error) {
+	if len(apps) == 0 {
+		return "", fmt.Errorf("no applications were provided for the \"%s\" deployment", dep.ID)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# Detection script for the \"%s\" deployment, generated by LeafBridge.\n", dep.ID)
+	fmt.Fprintf(&out, "# Applications: %s\n\n", apps)
+	out.WriteString(scriptPreamble)
+
+	for _, id := range apps {
+		app, err := lookupApp(dep, id)
+		if err != nil {
+			return "", err
+		}
+
+		var expr string
+		if app.Detection.Present != "" {
+			expr, err = conditionScript(dep, app.Detection.Present, make(map[lbdeploy.ConditionID]bool))
+			if err != nil {
+				return "", fmt.Errorf("the \"%s\" app cannot be detected by a script: %w", id, err)
+			}
+		} else {
+			expr = "(Test-Path -LiteralPath " + quote("Registry::"+uninstallEntry(app)) + ")"
+		}
+
+		out.WriteString("\n")
+		if app.Name != "" {
+			fmt.Fprintf(&out, "# %s (%s)\n", comment(app.Name), id)
+		} else {
+			fmt.Fprintf(&out, "# %s\n", id)
+		}
+		fmt.Fprintf(&out, "if (-not %s) {\n    exit 1\n}\n", expr)
+	}
+
+	fmt.Fprintf(&out, "\nWrite-Output %s\nexit 0\n", quote("Detected: "+apps.String()))
+
+	return out.String(), nil
+}
+
+// conditionScript returns a PowerShell expression that evaluates the named
+// condition.
+func conditionScript(dep lbdeploy.Deployment, id lbdeploy.ConditionID, seen map[lbdeploy.ConditionID]bool) (string, error) {
+	if seen[id] {
+		return "", fmt.Errorf("the \"%s\" condition has a cyclic reference to itself", id)
+	}
+	condition, found := dep.Conditions[id]
+	if !found {
+		return "", fmt.Errorf("the \"%s\" condition is not defined in the deployment", id)
+	}
+
+	seen[id] = true
+	defer delete(seen, id)
+
+	expr, err := conditionExpr(dep, condition, seen)
+	if err != nil {
+		return "", fmt.Errorf("the \"%s\" condition: %w", id, err)
+	}
+	return expr, nil
+}
+
+// conditionExpr returns a PowerShell expression that evaluates the given
+// condition.
+func conditionExpr(dep lbdeploy.Deployment, condition lbdeploy.Condition, seen map[lbdeploy.ConditionID]bool) (string, error) {
+	expr, err := func() (string, error) {
+		switch {
+		case len(condition.Any) > 0:
+			return joinConditions(dep, condition.Any, " -or ", seen)
+		case len(condition.All) > 0:
+			return joinConditions(dep, condition.All, " -and ", seen)
+		}
+
+		switch condition.Type {
+		case lbdeploy.ConditionTypeSubcondition:
+			return conditionScript(dep, lbdeploy.ConditionID(condition.Subject), seen)
+		case lbdeploy.ConditionTypeRegistryKeyExists:
+			key, err := resolveRegistryKey(dep.Resources.Registry, lbdeploy.RegistryKeyResourceID(condition.Subject))
+			if err != nil {
+				return "", err
+			}
+			return "(Test-Path -LiteralPath " + quote("Registry::"+key) + ")", nil
+		case lbdeploy.ConditionTypeRegistryValueExists:
+			key, value, err := resolveRegistryValue(dep.Resources.Registry, lbdeploy.RegistryValueResourceID(condition.Subject))
+			if err != nil {
+				return "", err
+			}
+			return "(Test-RegistryValue " + quote("Registry::"+key) + " " + quote(valueName(value.Name)) + ")", nil
+		case lbdeploy.ConditionTypeRegistryValueComparison:
+			key, value, err := resolveRegistryValue(dep.Resources.Registry, lbdeploy.RegistryValueResourceID(condition.Subject))
+			if err != nil {
+				return "", err
+			}
+			test, err := comparisonScript(condition.Comparison, condition.Value)
+			if err != nil {
+				return "", err
+			}
+			return "(Test-RegistryValue " + quote("Registry::"+key) + " " + quote(valueName(value.Name)) + " { " + test + " })", nil
+		case lbdeploy.ConditionTypeDirectoryExists:
+			dir, err := resolveDirectory(dep.Resources.FileSystem, lbdeploy.DirectoryResourceID(condition.Subject))
+			if err != nil {
+				return "", err
+			}
+			return "(Test-Path -LiteralPath " + dir.PowerShell() + " -PathType Container)", nil
+		case lbdeploy.ConditionTypeFileExists:
+			file, err := resolveFile(dep.Resources.FileSystem, lbdeploy.FileResourceID(condition.Subject))
+			if err != nil {
+				return "", err
+			}
+			return "(Test-Path -LiteralPath " + file.PowerShell() + " -PathType Leaf)", nil
+		default:
+			return "", fmt.Errorf("conditions of type \"%s\" are not supported", condition.Type)
+		}
+	}()
+	if err != nil {
+		return "", err
+	}
+	if condition.Negated {
+		return "(-not " + expr + ")", nil
+	}
+	return expr, nil
+}
+
+// joinConditions returns a PowerShell expression that joins the expressions
+// for a set of conditions with the given operator.
+func joinConditions(dep lbdeploy.Deployment, conditions []lbdeploy.Condition, op string, seen map[lbdeploy.ConditionID]bool) (string, error) {
+	exprs := make([]string, 0, len(conditions))
+	for i, condition := range conditions {
+		expr, err := conditionExpr(dep, condition, seen)
+		if err != nil {
+			return "", fmt.Errorf("subcondition %d: %w", i+1, err)
+		}
+		exprs = append(exprs, expr)
+	}
+	return "(" + strings.Join(exprs, op) + ")", nil
+}
+
+// comparisonScript returns a PowerShell expression that compares $args[0]
+// with the given value.
+func comparisonScript(comparison lbvalue.Comparison, value lbvalue.Value) (string, error) {
+	var op string
+	switch comparison {
+	case lbvalue.CompareEquals:
+		op = "eq"
+	case lbvalue.CompareLessThan:
+		op = "lt"
+	case lbvalue.CompareLessThanOrEquals:
+		op = "le"
+	case lbvalue.CompareGreaterThan:
+		op = "gt"
+	case lbvalue.CompareGreaterThanOrEquals:
+		op = "ge"
+	default:
+		return "", fmt.Errorf("the \"%s\" comparison is not supported", comparison)
+	}
+
+	switch value.Kind() {
+	case lbvalue.KindInt64:
+		return "[int64]$args[0] -" + op + " " + strconv.FormatInt(value.Int64(), 10), nil
+	case lbvalue.KindString:
+		return "[string]$args[0] -c" + op + " " + quote(value.String()), nil
+	case lbvalue.KindVersion:
+		return "[version]$args[0] -" + op + " [version]" + quote(string(value.Version())), nil
+	default:
+		return "", fmt.Errorf("comparisons of %s values are not supported", value.Kind())
+	}
+}
+
+// valueName returns the name used by PowerShell for a registry value.
+func valueName(name string) string {
+	if name == "" {
+		return "(default)"
+	}
+	return name
+}
+
+// quote returns s as a single-quoted PowerShell string.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// comment returns s with line breaks removed, so that it can be included
+// in a PowerShell comment.
+func comment(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
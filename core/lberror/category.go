@@ -0,0 +1,154 @@
+// Package lberror classifies the errors encountered by LeafBridge into a
+// small set of categories.
+//
+// Categories are attached to the events that report errors and determine
+// the exit code of leafbridge-deploy, so that central reporting can tell a
+// download that was blocked by a proxy apart from an installer that
+// failed.
+package lberror
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+)
+
+// Category identifies a category of errors.
+type Category string
+
+// Error categories.
+//
+// Network errors prevent LeafBridge from communicating with a server.
+// Verification errors indicate that a file did not match its expected hash,
+// signature or structure. Permission errors indicate that access to a
+// resource was denied. Resolution errors indicate that a resource described
+// by a deployment could not be located. Installer errors are returned by
+// commands and installers invoked by LeafBridge. Policy errors indicate
+// that the execution policy of the machine forbids a deployment. Timeout
+// and cancellation errors stop a deployment before it has finished.
+const (
+	Unknown      Category = ""
+	Network      Category = "network"
+	Verification Category = "verification"
+	Permission   Category = "permission"
+	Resolution   Category = "resource-resolution"
+	Installer    Category = "installer"
+	Policy       Category = "policy"
+	Timeout      Category = "timeout"
+	Cancelled    Category = "cancelled"
+)
+
+// String returns a string representation of the category.
+func (c Category) String() string {
+	if c == Unknown {
+		return "unknown"
+	}
+	return string(c)
+}
+
+// ExitCode returns the exit code that is used by leafbridge-deploy when it
+// stops because of an error in the category. The exit codes match Windows
+// system error codes, so that they are described sensibly by management
+// agents.
+func (c Category) ExitCode() int {
+	switch c {
+	case Network:
+		return 1231 // ERROR_NETWORK_UNREACHABLE
+	case Verification:
+		return 1392 // ERROR_FILE_CORRUPT
+	case Permission:
+		return 5 // ERROR_ACCESS_DENIED
+	case Resolution:
+		return 1168 // ERROR_NOT_FOUND
+	case Installer:
+		return 1603 // ERROR_INSTALL_FAILURE
+	case Policy:
+		return 1260 // ERROR_ACCESS_DISABLED_BY_POLICY
+	case Timeout:
+		return 1460 // ERROR_TIMEOUT
+	case Cancelled:
+		return 1223 // ERROR_CANCELLED
+	default:
+		return 1
+	}
+}
+
+// Categorized is implemented by errors that know their own category.
+type Categorized interface {
+	error
+	Category() Category
+}
+
+// Error is an error with a category. It is created by [New] and [Wrap].
+type Error struct {
+	category Category
+	err      error
+}
+
+// New returns an error with the given category and text.
+func New(category Category, text string) error {
+	return Error{category: category, err: errors.New(text)}
+}
+
+// Wrap returns err with the given category attached. If err is nil, it
+// returns nil. If err already has a category, it is returned unmodified.
+func Wrap(category Category, err error) error {
+	if err == nil {
+		return nil
+	}
+	var categorized Categorized
+	if errors.As(err, &categorized) && categorized.Category() != Unknown {
+		return err
+	}
+	return Error{category: category, err: err}
+}
+
+// Error returns a description of the error.
+func (e Error) Error() string {
+	return e.err.Error()
+}
+
+// Category returns the category of the error.
+func (e Error) Category() Category {
+	return e.category
+}
+
+// Unwrap returns the underlying error.
+func (e Error) Unwrap() error {
+	return e.err
+}
+
+// CategoryOf returns the category of err.
+//
+// The first error in the chain that knows its own category determines the
+// category. Otherwise, well-known errors from the standard library are
+// recognized: cancelled contexts, expired deadlines, denied access and
+// network failures, including network timeouts. If the category cannot be
+// determined, Unknown is returned.
+func CategoryOf(err error) Category {
+	if err == nil {
+		return Unknown
+	}
+
+	var categorized Categorized
+	if errors.As(err, &categorized) {
+		if category := categorized.Category(); category != Unknown {
+			return category
+		}
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Cancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, os.ErrPermission):
+		return Permission
+	case errors.As(err, &netErr):
+		return Network
+	}
+
+	return Unknown
+}
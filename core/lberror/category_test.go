@@ -0,0 +1,45 @@
+package lberror_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lberror"
+)
+
+func TestCategoryOf(t *testing.T) {
+	verification := lberror.New(lberror.Verification, "the hash does not match")
+
+	fixtures := []struct {
+		Err      error
+		Category lberror.Category
+	}{
+		{Err: nil, Category: lberror.Unknown},
+		{Err: errors.New("something happened"), Category: lberror.Unknown},
+		{Err: verification, Category: lberror.Verification},
+		{Err: fmt.Errorf("download failed: %w", verification), Category: lberror.Verification},
+		{Err: lberror.Wrap(lberror.Installer, verification), Category: lberror.Verification},
+		{Err: lberror.Wrap(lberror.Installer, errors.New("exit status 1603")), Category: lberror.Installer},
+		{Err: fmt.Errorf("flow stopped: %w", context.Canceled), Category: lberror.Cancelled},
+		{Err: context.DeadlineExceeded, Category: lberror.Timeout},
+		{Err: &os.PathError{Op: "open", Path: "file", Err: os.ErrPermission}, Category: lberror.Permission},
+		{Err: &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, Category: lberror.Network},
+	}
+
+	for i, fixture := range fixtures {
+		if got := lberror.CategoryOf(fixture.Err); got != fixture.Category {
+			t.Errorf("fixture %d: %v: got %s, want %s", i+1, fixture.Err, got, fixture.Category)
+		}
+	}
+}
+
+func TestWrapNil(t *testing.T) {
+	if err := lberror.Wrap(lberror.Network, nil); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
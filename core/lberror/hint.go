@@ -0,0 +1,91 @@
+package lberror
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"syscall"
+)
+
+// Hinted is implemented by errors that know how they are commonly
+// remedied.
+type Hinted interface {
+	error
+	Hint() string
+}
+
+// HintOf returns a human-readable hint that describes how the cause of err
+// is commonly remedied. It returns an empty string if err is nil or if no
+// hint is known.
+//
+// The first error in the chain that provides its own hint determines the
+// hint. Otherwise, well-known failures are recognized: WinHTTP and Windows
+// Sockets errors, name resolution failures, untrusted certificates, proxy
+// failures, refused connections, network timeouts and denied access to files
+// and folders.
+func HintOf(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var hinted Hinted
+	if errors.As(err, &hinted) {
+		if hint := hinted.Hint(); hint != "" {
+			return hint
+		}
+	}
+
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		if hint, found := errnoHints[uintptr(errno)]; found {
+			return hint
+		}
+	}
+
+	var (
+		dnsErr  *net.DNSError
+		authErr x509.UnknownAuthorityError
+		certErr x509.CertificateInvalidError
+		hostErr x509.HostnameError
+		tlsErr  *tls.CertificateVerificationError
+		opErr   *net.OpError
+		pathErr *fs.PathError
+		netErr  net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("The name \"%s\" could not be resolved. Make sure that the computer is connected to the network and that its DNS servers can resolve public names.", dnsErr.Name)
+	case errors.As(err, &authErr), errors.As(err, &certErr), errors.As(err, &hostErr), errors.As(err, &tlsErr):
+		return "The server's certificate is not trusted. This is often caused by a proxy or security product that inspects TLS traffic. Exclude the download server from inspection or make sure that the inspecting certificate is trusted by the computer."
+	case errors.As(err, &opErr) && opErr.Op == "proxyconnect":
+		return "The connection through the proxy server failed. Make sure that the proxy server allows connections to the download server."
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "The server refused the connection. Make sure that the server is online and that a firewall or proxy is not blocking the connection."
+	case errors.Is(err, os.ErrPermission) && errors.As(err, &pathErr):
+		return fmt.Sprintf("Access to \"%s\" was denied. Make sure that leafbridge-deploy is running as an administrator or as SYSTEM. If the location is protected by Controlled Folder Access or an antivirus product, allow leafbridge-deploy or choose another location.", pathErr.Path)
+	case errors.Is(err, os.ErrPermission):
+		return "Access was denied. Make sure that leafbridge-deploy is running as an administrator or as SYSTEM."
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "The server did not respond in time. Make sure that the computer can reach the server and that a firewall or proxy is not silently dropping the connection."
+	}
+
+	return ""
+}
+
+// errnoHints maps WinHTTP and Windows Sockets error codes to hints. The
+// codes do not overlap with the error numbers used on other platforms.
+var errnoHints = map[uintptr]string{
+	10060: "The server did not respond in time (WSAETIMEDOUT). Make sure that the computer can reach the server and that a firewall or proxy is not silently dropping the connection.",
+	10061: "The server refused the connection (WSAECONNREFUSED). Make sure that the server is online and that a firewall or proxy is not blocking the connection.",
+	12002: "The request timed out (ERROR_WINHTTP_TIMEOUT). Make sure that the computer can reach the server and that a firewall or proxy is not silently dropping the connection.",
+	12007: "The server name could not be resolved (ERROR_WINHTTP_NAME_NOT_RESOLVED). Make sure that the computer is connected to the network and that its DNS servers can resolve public names.",
+	12029: "A connection to the server could not be established (ERROR_WINHTTP_CANNOT_CONNECT). Make sure that a firewall or proxy is not blocking the connection.",
+	12030: "The connection to the server was interrupted (ERROR_WINHTTP_CONNECTION_ERROR). This is often caused by a proxy or security product that resets connections.",
+	12057: "The revocation status of the server's certificate could not be checked (ERROR_WINHTTP_SECURE_CERT_REV_FAILED). Make sure that the computer can reach the certificate authority's revocation servers.",
+	12175: "A secure connection could not be established (ERROR_WINHTTP_SECURE_FAILURE). This is often caused by a proxy or security product that inspects TLS traffic.",
+	12180: "The proxy server could not be detected (ERROR_WINHTTP_AUTODETECTION_FAILED). Make sure that the proxy auto-configuration is reachable, or configure the proxy explicitly.",
+}
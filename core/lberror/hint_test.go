@@ -0,0 +1,47 @@
+package lberror_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lberror"
+)
+
+type hintedError struct{}
+
+func (hintedError) Error() string { return "exit status 1618" }
+func (hintedError) Hint() string  { return "Another installation is already in progress." }
+
+func TestHintOf(t *testing.T) {
+	fixtures := []struct {
+		Err      error
+		Contains string
+	}{
+		{Err: nil, Contains: ""},
+		{Err: errors.New("something happened"), Contains: ""},
+		{Err: fmt.Errorf("command failed: %w", hintedError{}), Contains: "already in progress"},
+		{Err: &url.Error{Op: "Get", URL: "https://example.com", Err: &net.DNSError{Name: "example.com", Err: "no such host"}}, Contains: "\"example.com\" could not be resolved"},
+		{Err: &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "proxyconnect", Err: errors.New("connection reset")}}, Contains: "proxy"},
+		{Err: fmt.Errorf("download failed: %w", syscall.Errno(12175)), Contains: "ERROR_WINHTTP_SECURE_FAILURE"},
+		{Err: &os.PathError{Op: "open", Path: `C:\Program Files\Example`, Err: os.ErrPermission}, Contains: `"C:\Program Files\Example" was denied`},
+	}
+
+	for i, fixture := range fixtures {
+		hint := lberror.HintOf(fixture.Err)
+		if fixture.Contains == "" {
+			if hint != "" {
+				t.Errorf("fixture %d: %v: got hint \"%s\", want none", i+1, fixture.Err, hint)
+			}
+			continue
+		}
+		if !strings.Contains(hint, fixture.Contains) {
+			t.Errorf("fixture %d: %v: got hint \"%s\", want one containing \"%s\"", i+1, fixture.Err, hint, fixture.Contains)
+		}
+	}
+}
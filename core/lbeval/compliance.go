@@ -0,0 +1,212 @@
+package lbeval
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// Actual states reported by compliance checks.
+const (
+	statePresent  = "present"
+	stateAbsent   = "absent"
+	stateModified = "modified"
+	stateTrue     = "true"
+	stateFalse    = "false"
+)
+
+// ComplianceEngine is responsible for evaluating the desired state of a
+// deployment against the local system. It never makes changes to the
+// system.
+type ComplianceEngine struct {
+	deployment lbdeploy.Deployment
+	platform   lbplatform.Platform
+}
+
+// NewComplianceEngine prepares a compliance engine for the given
+// deployment. It observes the local system through the given platform.
+func NewComplianceEngine(dep lbdeploy.Deployment, platform lbplatform.Platform) ComplianceEngine {
+	return ComplianceEngine{
+		deployment: dep,
+		platform:   platform,
+	}
+}
+
+// Evaluate checks every application and resource in the deployment that
+// has a desired state, along with every condition that is marked for
+// compliance. It returns a report describing the outcome of each check.
+//
+// Checks that cannot be evaluated are reported as out of compliance.
+func (engine ComplianceEngine) Evaluate() lbdeploy.ComplianceReport {
+	var report lbdeploy.ComplianceReport
+
+	// Evaluate applications.
+	ae := NewAppEngine(engine.deployment, engine.platform)
+	for _, id := range slices.Sorted(maps.Keys(engine.deployment.Apps)) {
+		desired := engine.deployment.Apps[id].Desired
+		if desired == "" {
+			continue
+		}
+		installed, err := ae.IsInstalled(id)
+		report.Checks = append(report.Checks, presenceCheck(lbdeploy.ComplianceApp, string(id), desired, installed, err))
+	}
+
+	// Evaluate conditions.
+	ce := NewConditionEngine(engine.deployment, engine.platform)
+	for _, id := range slices.Sorted(maps.Keys(engine.deployment.Conditions)) {
+		condition := engine.deployment.Conditions[id]
+		if !condition.Compliance {
+			continue
+		}
+		result, err := ce.Evaluate(id)
+		check := lbdeploy.ComplianceCheck{
+			Kind:      lbdeploy.ComplianceCondition,
+			ID:        string(id),
+			Desired:   stateTrue,
+			Actual:    stateFalse,
+			Violation: condition.Violation,
+			Compliant: err == nil && result,
+			Err:       err,
+		}
+		if result {
+			check.Actual = stateTrue
+		}
+		report.Checks = append(report.Checks, check)
+	}
+
+	// Evaluate directories and files.
+	fs := engine.platform.FileSystem(engine.deployment.Resources.FileSystem)
+	dirs := engine.deployment.Resources.FileSystem.Directories
+	for _, id := range slices.Sorted(maps.Keys(dirs)) {
+		desired := dirs[id].Desired
+		if desired == "" {
+			continue
+		}
+		exists, err := fs.DirectoryExists(id)
+		report.Checks = append(report.Checks, presenceCheck(lbdeploy.ComplianceDirectory, string(id), desired, exists, err))
+	}
+	files := engine.deployment.Resources.FileSystem.Files
+	for _, id := range slices.Sorted(maps.Keys(files)) {
+		if files[id].Desired == "" {
+			continue
+		}
+		report.Checks = append(report.Checks, engine.checkFile(fs, id, files[id]))
+	}
+
+	// Evaluate registry keys and values.
+	registry := engine.platform.Registry(engine.deployment.Resources.Registry)
+	keys := engine.deployment.Resources.Registry.Keys
+	for _, id := range slices.Sorted(maps.Keys(keys)) {
+		desired := keys[id].Desired
+		if desired == "" {
+			continue
+		}
+		exists, err := registry.KeyExists(id)
+		report.Checks = append(report.Checks, presenceCheck(lbdeploy.ComplianceRegistryKey, string(id), desired, exists, err))
+	}
+	values := engine.deployment.Resources.Registry.Values
+	for _, id := range slices.Sorted(maps.Keys(values)) {
+		if values[id].Desired == "" {
+			continue
+		}
+		report.Checks = append(report.Checks, engine.checkRegistryValue(registry, id, values[id]))
+	}
+
+	return report
+}
+
+// checkFile checks the presence of a file, and its hashes if it is expected
+// to be present and has integrity hashes.
+func (engine ComplianceEngine) checkFile(fs lbplatform.FileSystem, id lbdeploy.FileResourceID, file lbdeploy.FileResource) lbdeploy.ComplianceCheck {
+	exists, err := fs.FileExists(id)
+	check := presenceCheck(lbdeploy.ComplianceFile, string(id), file.Desired, exists, err)
+	if err != nil || !exists || file.Desired != lbdeploy.DesiredPresent || len(file.Integrity.Hashes) == 0 {
+		return check
+	}
+
+	matched, err := func() (bool, error) {
+		r, err := fs.OpenFile(id)
+		if err != nil {
+			return false, err
+		}
+		defer r.Close()
+		return matchFileHashes(r, file.Integrity.Hashes)
+	}()
+	switch {
+	case err != nil:
+		check.Compliant = false
+		check.Err = fmt.Errorf("failed to verify the content of the file: %w", err)
+	case !matched:
+		check.Actual = stateModified
+		check.Compliant = false
+	}
+
+	return check
+}
+
+// checkRegistryValue checks the presence of a registry value, and its data
+// if desired data has been provided.
+func (engine ComplianceEngine) checkRegistryValue(registry lbplatform.Registry, id lbdeploy.RegistryValueResourceID, value lbdeploy.RegistryValueResource) lbdeploy.ComplianceCheck {
+	check := lbdeploy.ComplianceCheck{
+		Kind:    lbdeploy.ComplianceRegistryValue,
+		ID:      string(id),
+		Desired: string(value.Desired),
+	}
+
+	data, err := registry.GetValue(id)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		check.Actual = stateAbsent
+		check.Compliant = value.Desired == lbdeploy.DesiredAbsent
+		return check
+	case err != nil:
+		check.Err = err
+		return check
+	}
+
+	check.Actual = statePresent
+	check.Compliant = value.Desired == lbdeploy.DesiredPresent
+	if !check.Compliant || value.DesiredData.Kind() == lbvalue.KindUnknown {
+		return check
+	}
+
+	// Compare the data held by the value with the desired data.
+	check.Desired = value.DesiredData.String()
+	check.Actual = data.String()
+	result, err := lbvalue.TryCompare(data, value.DesiredData)
+	if err != nil {
+		check.Compliant = false
+		check.Err = err
+		return check
+	}
+	check.Compliant = result == 0
+
+	return check
+}
+
+// presenceCheck returns a compliance check that compares the desired state
+// of an item with its presence.
+func presenceCheck(kind lbdeploy.ComplianceKind, id string, desired lbdeploy.DesiredState, present bool, err error) lbdeploy.ComplianceCheck {
+	check := lbdeploy.ComplianceCheck{
+		Kind:    kind,
+		ID:      id,
+		Desired: string(desired),
+		Err:     err,
+	}
+	if err != nil {
+		return check
+	}
+	if present {
+		check.Actual = statePresent
+	} else {
+		check.Actual = stateAbsent
+	}
+	check.Compliant = check.Actual == check.Desired
+	return check
+}
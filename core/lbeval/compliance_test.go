@@ -0,0 +1,100 @@
+package lbeval_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbeval"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+var complianceDeployment = lbdeploy.Deployment{
+	ID: "compliance",
+	Resources: lbdeploy.Resources{
+		FileSystem: lbdeploy.FileSystemResources{
+			Directories: lbdeploy.DirectoryResourceMap{
+				"present-dir": {Desired: lbdeploy.DesiredPresent},
+				"missing-dir": {Desired: lbdeploy.DesiredAbsent},
+				"ignored-dir": {},
+			},
+			Files: lbdeploy.FileResourceMap{
+				"present-file": {
+					Desired:   lbdeploy.DesiredPresent,
+					Integrity: lbdeploy.FileIntegrity{Hashes: filehash.Map{filehash.SHA256: fileHash}},
+				},
+				"missing-file": {Desired: lbdeploy.DesiredPresent},
+			},
+		},
+		Registry: lbdeploy.RegistryResources{
+			Keys: lbdeploy.RegistryKeyResourceMap{
+				"present-key": {Desired: lbdeploy.DesiredAbsent},
+			},
+			Values: lbdeploy.RegistryValueResourceMap{
+				"app-version":   {Desired: lbdeploy.DesiredPresent, DesiredData: lbvalue.Version("2.1.0")},
+				"missing-value": {Desired: lbdeploy.DesiredAbsent},
+			},
+		},
+	},
+	Apps: lbdeploy.AppMap{
+		"registered": {
+			ProductCode: "{B1A0A3A4-5E6F-4A70-9C2B-7E7E3C1E1F01}",
+			Desired:     lbdeploy.DesiredPresent,
+		},
+		"unregistered": {
+			ProductCode: "{B1A0A3A4-5E6F-4A70-9C2B-7E7E3C1E1F02}",
+			Desired:     lbdeploy.DesiredPresent,
+		},
+	},
+	Conditions: lbdeploy.ConditionMap{
+		"dir-present": {Type: lbdeploy.ConditionTypeDirectoryExists, Subject: "present-dir", Compliance: true},
+		"dir-missing": {Type: lbdeploy.ConditionTypeDirectoryExists, Subject: "missing-dir", Compliance: true},
+		"unchecked":   {Type: lbdeploy.ConditionTypeDirectoryExists, Subject: "missing-dir"},
+	},
+}
+
+type complianceFixture struct {
+	Kind      lbdeploy.ComplianceKind
+	ID        string
+	Compliant bool
+}
+
+var complianceFixtures = []complianceFixture{
+	{Kind: lbdeploy.ComplianceApp, ID: "registered", Compliant: true},
+	{Kind: lbdeploy.ComplianceApp, ID: "unregistered", Compliant: false},
+	{Kind: lbdeploy.ComplianceCondition, ID: "dir-missing", Compliant: false},
+	{Kind: lbdeploy.ComplianceCondition, ID: "dir-present", Compliant: true},
+	{Kind: lbdeploy.ComplianceDirectory, ID: "missing-dir", Compliant: true},
+	{Kind: lbdeploy.ComplianceDirectory, ID: "present-dir", Compliant: true},
+	{Kind: lbdeploy.ComplianceFile, ID: "missing-file", Compliant: false},
+	{Kind: lbdeploy.ComplianceFile, ID: "present-file", Compliant: true},
+	{Kind: lbdeploy.ComplianceRegistryKey, ID: "present-key", Compliant: false},
+	{Kind: lbdeploy.ComplianceRegistryValue, ID: "app-version", Compliant: true},
+	{Kind: lbdeploy.ComplianceRegistryValue, ID: "missing-value", Compliant: true},
+}
+
+func TestComplianceEngine(t *testing.T) {
+	report := lbeval.NewComplianceEngine(complianceDeployment, testPlatform).Evaluate()
+	if len(report.Checks) != len(complianceFixtures) {
+		t.Fatalf("got %d checks, want %d: %v", len(report.Checks), len(complianceFixtures), report.Checks)
+	}
+	for i, fixture := range complianceFixtures {
+		check := report.Checks[i]
+		if check.Kind != fixture.Kind || check.ID != fixture.ID {
+			t.Errorf("check %d: got %s %s, want %s %s", i, check.Kind, check.ID, fixture.Kind, fixture.ID)
+			continue
+		}
+		if check.Err != nil {
+			t.Errorf("%s: unexpected error: %v", check.ID, check.Err)
+		}
+		if check.Compliant != fixture.Compliant {
+			t.Errorf("%s: got compliant %t, want %t (%s)", check.ID, check.Compliant, fixture.Compliant, check)
+		}
+	}
+	if report.Compliant() {
+		t.Errorf("expected the report to show drift")
+	}
+	if got := len(report.Drift()); got != 4 {
+		t.Errorf("got %d drifted checks, want 4", got)
+	}
+}
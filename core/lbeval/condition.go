@@ -0,0 +1,388 @@
+// Package lbeval evaluates the conditions and applications of a deployment
+// against the local system.
+package lbeval
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/idset"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// conditionSet keeps track of a set of conditions as they are evaluated.
+type conditionSet = idset.SetOf[lbdeploy.ConditionID]
+
+// ConditionEngine is responsible for evaluating conditions on the local
+// system.
+type ConditionEngine struct {
+	deployment lbdeploy.Deployment
+	platform   lbplatform.Platform
+	tracer     Tracer
+}
+
+// NewConditionEngine prepares a condition engine for the given deployment.
+// It observes the local system through the given platform.
+func NewConditionEngine(dep lbdeploy.Deployment, platform lbplatform.Platform) ConditionEngine {
+	return ConditionEngine{
+		deployment: dep,
+		platform:   platform,
+	}
+}
+
+// Evaluate returns true if the given condition is currently true.
+//
+// TODO: Consider returning some sort of evaluation struct that describes
+// the condition (or subconditions) that failed.
+func (engine ConditionEngine) Evaluate(condition lbdeploy.ConditionID) (bool, error) {
+	// Find the condition within the deployment.
+	definition, found := engine.deployment.Conditions[condition]
+	if !found {
+		return false, fmt.Errorf("the condition \"%s\" does not exist within the \"%s\" deployment", condition, engine.deployment.ID)
+	}
+
+	return engine.evaluate(condition, definition, make(lbdeploy.ConditionCache), make(conditionSet))
+}
+
+func (engine ConditionEngine) evaluate(id lbdeploy.ConditionID, condition lbdeploy.Condition, cache lbdeploy.ConditionCache, seen conditionSet) (bool, error) {
+	// Special handling for conditions that are identified.
+	if id != "" {
+		// If this condition has already been evaluated, return the cached value.
+		if value, computed := cache[id]; computed {
+			return value, nil
+		}
+
+		// Check for recursive calls.
+		if seen.Contains(id) {
+			return false, fmt.Errorf("the \"%s\" condition is recursive and is already being evaluated", id)
+		}
+
+		// Add this condition to evaluation set, then remove it when we're finished.
+		seen.Add(id)
+		defer seen.Remove(id)
+	}
+
+	// Evaluate the condition, keeping a description of what was observed
+	// on the local system.
+	var observed string
+	result, err := func() (bool, error) {
+		// Evaluate "any" conditions.
+		if len(condition.Any) > 0 {
+			for i, candidate := range condition.Any {
+				result, err := engine.evaluate("", candidate, cache, seen)
+				if err != nil {
+					return false, lbdeploy.ConditionError{
+						ID:           id,
+						Label:        condition.Label,
+						Type:         condition.Type,
+						Element:      lbdeploy.ConditionElementAny,
+						SubCondition: i,
+						Err:          err,
+					}
+				}
+				if result {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+
+		// Evaluate "all" conditions.
+		if len(condition.All) > 0 {
+			for i, candidate := range condition.All {
+				result, err := engine.evaluate("", candidate, cache, seen)
+				if err != nil {
+					return false, lbdeploy.ConditionError{
+						ID:           id,
+						Label:        condition.Label,
+						Type:         condition.Type,
+						Element:      lbdeploy.ConditionElementAll,
+						SubCondition: i,
+						Err:          err,
+					}
+				}
+				if !result {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+
+		// Evaluate individual conditions.
+		switch condition.Type {
+		case lbdeploy.ConditionTypeSubcondition:
+			candidateID := lbdeploy.ConditionID(condition.Subject)
+			candidate, found := engine.deployment.Conditions[candidateID]
+			if !found {
+				return false, conditionSelfError(id, condition, fmt.Errorf("the \"%s\" condition is not defined in the deployment", condition.Subject))
+			}
+			return engine.evaluate(candidateID, candidate, cache, seen)
+		case lbdeploy.ConditionTypeProcessIsRunning:
+			process, found := engine.deployment.Resources.Processes[lbdeploy.ProcessResourceID(condition.Subject)]
+			if !found {
+				return false, conditionSelfError(id, condition, fmt.Errorf("the \"%s\" process is not defined in the deployment", condition.Subject))
+			}
+			running, err := engine.platform.Processes().NumberOfRunningProcesses(process.Match)
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = fmt.Sprintf("%d running", running)
+			return running > 0, nil
+		case lbdeploy.ConditionTypeMutexExists:
+			mutex, found := engine.deployment.Resources.Mutexes[lbdeploy.MutexID(condition.Subject)]
+			if !found {
+				return false, conditionSelfError(id, condition, fmt.Errorf("the \"%s\" mutex is not defined in the deployment", condition.Subject))
+			}
+			name, err := mutex.ObjectName()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			exists, err := engine.platform.Processes().MutexExists(name)
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = presence(exists)
+			return exists, nil
+		case lbdeploy.ConditionTypeRegistryKeyExists:
+			registry := engine.platform.Registry(engine.deployment.Resources.Registry)
+			exists, err := registry.KeyExists(lbdeploy.RegistryKeyResourceID(condition.Subject))
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = presence(exists)
+			return exists, nil
+		case lbdeploy.ConditionTypeRegistryValueExists:
+			registry := engine.platform.Registry(engine.deployment.Resources.Registry)
+			exists, err := registry.ValueExists(lbdeploy.RegistryValueResourceID(condition.Subject))
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = presence(exists)
+			return exists, nil
+		case lbdeploy.ConditionTypeRegistryValueComparison:
+			registry := engine.platform.Registry(engine.deployment.Resources.Registry)
+			value, err := registry.GetValue(lbdeploy.RegistryValueResourceID(condition.Subject))
+			if err != nil {
+				if os.IsNotExist(err) {
+					observed = presence(false)
+					return false, nil
+				}
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = describeValue(value)
+			result, err := lbvalue.TryCompare(value, condition.Value)
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			return condition.Comparison.Evaluate(result), nil
+		case lbdeploy.ConditionTypeDirectoryExists:
+			fs := engine.platform.FileSystem(engine.deployment.Resources.FileSystem)
+			exists, err := fs.DirectoryExists(lbdeploy.DirectoryResourceID(condition.Subject))
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = presence(exists)
+			return exists, nil
+		case lbdeploy.ConditionTypeFileExists:
+			fs := engine.platform.FileSystem(engine.deployment.Resources.FileSystem)
+			exists, err := fs.FileExists(lbdeploy.FileResourceID(condition.Subject))
+			if err != nil {
+				return false, conditionSelfError(id, condition, fmt.Errorf("file \"%s\": %w", condition.Subject, err))
+			}
+			observed = presence(exists)
+			return exists, nil
+		case lbdeploy.ConditionTypeCertificateExists:
+			cert, found := engine.deployment.Resources.Certificates[lbdeploy.CertificateID(condition.Subject)]
+			if !found {
+				return false, conditionSelfError(id, condition, fmt.Errorf("the \"%s\" certificate is not defined in the deployment", condition.Subject))
+			}
+			exists, err := engine.platform.Certificates().CertificateExists(cert)
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = presence(exists)
+			return exists, nil
+		case lbdeploy.ConditionTypeMemoryComparison:
+			memory, err := engine.platform.Hardware().InstalledMemory()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = fmt.Sprintf("%d MiB", memory>>20)
+			result, err := lbvalue.TryCompare(lbvalue.Int64(memory>>20), condition.Value)
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			return condition.Comparison.Evaluate(result), nil
+		case lbdeploy.ConditionTypeProcessorComparison:
+			count, err := engine.platform.Hardware().LogicalProcessors()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = fmt.Sprintf("%d logical processors", count)
+			result, err := lbvalue.TryCompare(lbvalue.Int64(int64(count)), condition.Value)
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			return condition.Comparison.Evaluate(result), nil
+		case lbdeploy.ConditionTypeTPMPresent:
+			version, err := engine.platform.Hardware().TPMVersion()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = fmt.Sprintf("TPM version %d", version)
+			return version >= 2, nil
+		case lbdeploy.ConditionTypeSecureBootEnabled:
+			enabled, err := engine.platform.Hardware().SecureBootEnabled()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = choose(enabled, "enabled", "disabled")
+			return enabled, nil
+		case lbdeploy.ConditionTypeVirtualMachine:
+			hypervisor, err := engine.platform.Hardware().Hypervisor()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = choose(hypervisor != "", string(hypervisor), "no hypervisor")
+			if hypervisor == "" {
+				return false, nil
+			}
+			if condition.Value.Kind() == lbvalue.KindString {
+				return hypervisor == lbdeploy.Hypervisor(condition.Value.String()), nil
+			}
+			return true, nil
+		case lbdeploy.ConditionTypeBitLockerProtected:
+			protected, err := engine.platform.Hardware().BitLockerProtected()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = choose(protected, "protected", "not protected")
+			return protected, nil
+		case lbdeploy.ConditionTypeUILanguage:
+			language, err := engine.platform.Locale().UILanguage()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = language
+			return matchLanguageTag(condition.Value.String(), language), nil
+		case lbdeploy.ConditionTypeSystemLocale:
+			locale, err := engine.platform.Locale().SystemLocale()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = locale
+			return matchLanguageTag(condition.Value.String(), locale), nil
+		case lbdeploy.ConditionTypeKeyboardLayout:
+			layouts, err := engine.platform.Locale().KeyboardLayouts()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = describeLayouts(layouts)
+			for _, layout := range layouts {
+				if strings.EqualFold(condition.Value.String(), layout.ID) || matchLanguageTag(condition.Value.String(), layout.Language) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case lbdeploy.ConditionTypeTimeZone:
+			zone, err := engine.platform.Locale().TimeZone()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = zone
+			return strings.EqualFold(condition.Value.String(), zone), nil
+		case lbdeploy.ConditionTypeLocalUserExists:
+			exists, err := engine.platform.Accounts().LocalUserExists(condition.Subject)
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = presence(exists)
+			return exists, nil
+		case lbdeploy.ConditionTypeLocalGroupMember:
+			member, err := engine.platform.Accounts().IsGroupMember(condition.Subject, condition.Value.String())
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = choose(member, "member", "not a member")
+			return member, nil
+		case lbdeploy.ConditionTypeUserRightGranted:
+			granted, err := engine.platform.Accounts().HasUserRight(condition.Subject, condition.Value.String())
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = choose(granted, "granted", "not granted")
+			return granted, nil
+		case lbdeploy.ConditionTypePowerPlanActive:
+			plan, err := engine.platform.Hardware().ActivePowerPlan()
+			if err != nil {
+				return false, conditionSelfError(id, condition, err)
+			}
+			observed = plan
+			return strings.EqualFold(lbdeploy.PowerPlan(condition.Value.String()).GUID(), plan), nil
+		case lbdeploy.ConditionTypeFileContent:
+			fs := engine.platform.FileSystem(engine.deployment.Resources.FileSystem)
+			matched, err := matchFileContent(fs, lbdeploy.FileResourceID(condition.Subject), condition.Content)
+			if err != nil {
+				return false, conditionSelfError(id, condition, fmt.Errorf("file \"%s\": %w", condition.Subject, err))
+			}
+			observed = choose(matched, "content matched", "content did not match")
+			return matched, nil
+		default:
+			return false, conditionSelfError(id, condition, fmt.Errorf("unrecognized condition type: %s", condition.Type))
+		}
+	}()
+
+	// Negate the result if requested.
+	if condition.Negated {
+		result = !result
+	}
+
+	// Record the result in the cache if possible.
+	if id != "" && err == nil {
+		cache[id] = result
+	}
+
+	// Report what was observed, if requested.
+	if engine.tracer != nil {
+		engine.tracer(Observation{
+			Condition:  id,
+			Label:      condition.Label,
+			Type:       condition.Type,
+			Subject:    condition.Subject,
+			Comparison: condition.Comparison,
+			Value:      condition.Value,
+			Negated:    condition.Negated,
+			Observed:   observed,
+			Result:     result,
+			Err:        err,
+		})
+	}
+
+	return result, err
+}
+
+func conditionSelfError(id lbdeploy.ConditionID, c lbdeploy.Condition, err error) error {
+	return lbdeploy.ConditionError{
+		ID:      id,
+		Label:   c.Label,
+		Type:    c.Type,
+		Element: lbdeploy.ConditionElementSelf,
+		Err:     err,
+	}
+}
+
+// matchLanguageTag returns true if the language tag matches the pattern.
+// Tags are compared without regard to case, and a pattern matches every
+// tag that it is a prefix of, such as "de" matching "de-CH".
+func matchLanguageTag(pattern, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	if len(tag) > len(pattern) && tag[len(pattern)] == '-' {
+		tag = tag[:len(pattern)]
+	}
+	return strings.EqualFold(pattern, tag)
+}
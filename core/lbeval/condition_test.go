@@ -0,0 +1,385 @@
+package lbeval_test
+
+import (
+	"crypto/sha256"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbeval"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// fakePlatform is an in-memory implementation of lbplatform.Platform.
+type fakePlatform struct {
+	Dirs      map[lbdeploy.DirectoryResourceID]bool
+	Files     map[lbdeploy.FileResourceID]bool
+	Contents  map[lbdeploy.FileResourceID]string
+	Keys      map[lbdeploy.RegistryKeyResourceID]bool
+	Values    map[lbdeploy.RegistryValueResourceID]lbvalue.Value
+	Installed map[lbdeploy.ProductCode]datatype.Version
+	Running   map[string]int
+	Mutexes   map[string]bool
+	Certs     map[string]bool
+	Memory    int64
+	CPUs      int
+	TPM       int
+}
+
+func (p fakePlatform) FileSystem(lbdeploy.FileSystemResources) lbplatform.FileSystem { return p }
+func (p fakePlatform) Registry(lbdeploy.RegistryResources) lbplatform.Registry       { return p }
+func (p fakePlatform) Apps() lbplatform.AppDetector                                  { return p }
+func (p fakePlatform) Processes() lbplatform.ProcessController                       { return p }
+func (p fakePlatform) Certificates() lbplatform.CertificateDetector                  { return p }
+func (p fakePlatform) Hardware() lbplatform.HardwareDetector                         { return p }
+
+func (p fakePlatform) InstalledMemory() (int64, error)  { return p.Memory, nil }
+func (p fakePlatform) LogicalProcessors() (int, error)  { return p.CPUs, nil }
+func (p fakePlatform) TPMVersion() (int, error)         { return p.TPM, nil }
+func (p fakePlatform) SecureBootEnabled() (bool, error) { return false, nil }
+
+func (p fakePlatform) Hypervisor() (lbdeploy.Hypervisor, error) {
+	return lbdeploy.HypervisorHyperV, nil
+}
+
+func (p fakePlatform) BitLockerProtected() (bool, error) { return true, nil }
+
+func (p fakePlatform) ActivePowerPlan() (string, error) {
+	return "8c5e7fda-e8bf-4a96-9a85-a6e23a8c635c", nil
+}
+
+func (p fakePlatform) Locale() lbplatform.LocaleDetector { return p }
+
+func (p fakePlatform) UILanguage() (string, error)   { return "de-DE", nil }
+func (p fakePlatform) SystemLocale() (string, error) { return "de-CH", nil }
+func (p fakePlatform) TimeZone() (string, error)     { return "W. Europe Standard Time", nil }
+
+func (p fakePlatform) KeyboardLayouts() ([]lbplatform.KeyboardLayout, error) {
+	return []lbplatform.KeyboardLayout{
+		{ID: "00000409", Language: "en-US"},
+		{ID: "00000807", Language: "de-CH"},
+	}, nil
+}
+
+func (p fakePlatform) Accounts() lbplatform.AccountDetector { return p }
+
+func (p fakePlatform) LocalUserExists(name string) (bool, error) {
+	return strings.EqualFold(name, "svc-backup"), nil
+}
+
+func (p fakePlatform) IsGroupMember(group, account string) (bool, error) {
+	return group == "Administrators" && account == `CONTOSO\Workstation Admins`, nil
+}
+
+func (p fakePlatform) HasUserRight(right, account string) (bool, error) {
+	return right == "SeServiceLogonRight" && account == "svc-backup", nil
+}
+
+func (p fakePlatform) CertificateExists(cert lbdeploy.CertificateResource) (bool, error) {
+	return p.Certs[cert.Thumbprint], nil
+}
+
+func (p fakePlatform) DirectoryExists(id lbdeploy.DirectoryResourceID) (bool, error) {
+	return p.Dirs[id], nil
+}
+
+func (p fakePlatform) FileExists(id lbdeploy.FileResourceID) (bool, error) {
+	return p.Files[id], nil
+}
+
+func (p fakePlatform) OpenFile(id lbdeploy.FileResourceID) (io.ReadCloser, error) {
+	content, found := p.Contents[id]
+	if !found {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (p fakePlatform) KeyExists(id lbdeploy.RegistryKeyResourceID) (bool, error) {
+	return p.Keys[id], nil
+}
+
+func (p fakePlatform) ValueExists(id lbdeploy.RegistryValueResourceID) (bool, error) {
+	_, found := p.Values[id]
+	return found, nil
+}
+
+func (p fakePlatform) GetValue(id lbdeploy.RegistryValueResourceID) (lbvalue.Value, error) {
+	value, found := p.Values[id]
+	if !found {
+		return lbvalue.Value{}, os.ErrNotExist
+	}
+	return value, nil
+}
+
+func (p fakePlatform) IsInstalled(app lbdeploy.Application) (bool, error) {
+	_, found := p.Installed[app.ProductCode]
+	return found, nil
+}
+
+func (p fakePlatform) Version(app lbdeploy.Application) (datatype.Version, error) {
+	return p.Installed[app.ProductCode], nil
+}
+
+func (p fakePlatform) NumberOfRunningProcesses(match lbdeploy.ProcessMatch) (int, error) {
+	return p.Running[match.Value], nil
+}
+
+func (p fakePlatform) MutexExists(name string) (bool, error) {
+	return p.Mutexes[name], nil
+}
+
+var testPlatform = fakePlatform{
+	Dirs:  map[lbdeploy.DirectoryResourceID]bool{"present-dir": true},
+	Files: map[lbdeploy.FileResourceID]bool{"present-file": true},
+	Contents: map[lbdeploy.FileResourceID]string{
+		"present-file": "[Update]\nChannel=Stable\nAutoUpdate=1\n",
+	},
+	Keys:   map[lbdeploy.RegistryKeyResourceID]bool{"present-key": true},
+	Values: map[lbdeploy.RegistryValueResourceID]lbvalue.Value{"app-version": lbvalue.Version("2.1.0")},
+	Installed: map[lbdeploy.ProductCode]datatype.Version{
+		"{B1A0A3A4-5E6F-4A70-9C2B-7E7E3C1E1F01}": "5.0.1",
+	},
+	Running: map[string]int{"app.exe": 2},
+	Certs:   map[string]bool{"0563b8630d62d75abbc8ab1e4bdfb5a899b24d43": true},
+	Memory:  16 << 30,
+	CPUs:    8,
+	TPM:     2,
+}
+
+var testDeployment = lbdeploy.Deployment{
+	ID: "test",
+	Resources: lbdeploy.Resources{
+		Processes: lbdeploy.ProcessResourceMap{
+			"app":   {Match: lbdeploy.ProcessMatch{Value: "app.exe"}},
+			"other": {Match: lbdeploy.ProcessMatch{Value: "other.exe"}},
+		},
+		Certificates: lbdeploy.CertificateMap{
+			"root-ca": {Store: "Root", Thumbprint: "0563b8630d62d75abbc8ab1e4bdfb5a899b24d43"},
+		},
+	},
+	Apps: lbdeploy.AppMap{
+		"registered": {ProductCode: "{B1A0A3A4-5E6F-4A70-9C2B-7E7E3C1E1F01}"},
+		"detected": {Detection: lbdeploy.AppDetection{
+			Present: "dir-present",
+			Version: "app-version",
+		}},
+	},
+	Conditions: lbdeploy.ConditionMap{
+		"dir-present":  {Type: lbdeploy.ConditionTypeDirectoryExists, Subject: "present-dir"},
+		"dir-missing":  {Type: lbdeploy.ConditionTypeDirectoryExists, Subject: "missing-dir"},
+		"file-present": {Type: lbdeploy.ConditionTypeFileExists, Subject: "present-file"},
+		"key-present":  {Type: lbdeploy.ConditionTypeRegistryKeyExists, Subject: "present-key"},
+		"file-contains": {
+			Type:    lbdeploy.ConditionTypeFileContent,
+			Subject: "present-file",
+			Content: lbdeploy.FileContentMatch{Type: lbdeploy.MatchContains, Value: "channel=stable", IgnoreCase: true},
+		},
+		"file-expression": {
+			Type:    lbdeploy.ConditionTypeFileContent,
+			Subject: "present-file",
+			Content: lbdeploy.FileContentMatch{Type: lbdeploy.MatchRegularExpression, Value: `^AutoUpdate=0$`},
+		},
+		"file-hash": {
+			Type:    lbdeploy.ConditionTypeFileContent,
+			Subject: "present-file",
+			Content: lbdeploy.FileContentMatch{Hashes: filehash.Map{filehash.SHA256: fileHash}},
+		},
+		"missing-file-contains": {
+			Type:    lbdeploy.ConditionTypeFileContent,
+			Subject: "missing-file",
+			Content: lbdeploy.FileContentMatch{Type: lbdeploy.MatchContains, Value: "Channel"},
+		},
+		"value-missing": {
+			Type:    lbdeploy.ConditionTypeRegistryValueExists,
+			Subject: "missing-value",
+			Negated: true,
+		},
+		"version-at-least-2": {
+			Type:       lbdeploy.ConditionTypeRegistryValueComparison,
+			Subject:    "app-version",
+			Comparison: lbvalue.CompareGreaterThanOrEquals,
+			Value:      lbvalue.Version("2.0"),
+		},
+		"app-running": {Type: lbdeploy.ConditionTypeProcessIsRunning, Subject: "app"},
+		"root-ca":     {Type: lbdeploy.ConditionTypeCertificateExists, Subject: "root-ca"},
+		"memory-at-least-8g": {
+			Type:       lbdeploy.ConditionTypeMemoryComparison,
+			Comparison: lbvalue.CompareGreaterThanOrEquals,
+			Value:      lbvalue.Int64(8192),
+		},
+		"more-than-8-cpus": {
+			Type:       lbdeploy.ConditionTypeProcessorComparison,
+			Comparison: lbvalue.CompareGreaterThan,
+			Value:      lbvalue.Int64(8),
+		},
+		"tpm":           {Type: lbdeploy.ConditionTypeTPMPresent},
+		"secure-boot":   {Type: lbdeploy.ConditionTypeSecureBootEnabled},
+		"virtual":       {Type: lbdeploy.ConditionTypeVirtualMachine},
+		"vmware":        {Type: lbdeploy.ConditionTypeVirtualMachine, Value: lbvalue.String("vmware")},
+		"german-ui":     {Type: lbdeploy.ConditionTypeUILanguage, Value: lbvalue.String("de")},
+		"german-locale": {Type: lbdeploy.ConditionTypeSystemLocale, Value: lbvalue.String("de-DE")},
+		"swiss-keys":    {Type: lbdeploy.ConditionTypeKeyboardLayout, Value: lbvalue.String("00000807")},
+		"us-keys":       {Type: lbdeploy.ConditionTypeKeyboardLayout, Value: lbvalue.String("en")},
+		"french-keys":   {Type: lbdeploy.ConditionTypeKeyboardLayout, Value: lbvalue.String("fr")},
+		"europe":        {Type: lbdeploy.ConditionTypeTimeZone, Value: lbvalue.String("w. europe standard time")},
+		"other-running": {Type: lbdeploy.ConditionTypeProcessIsRunning, Subject: "other"},
+		"backup-user":   {Type: lbdeploy.ConditionTypeLocalUserExists, Subject: "SVC-Backup"},
+		"domain-admins": {Type: lbdeploy.ConditionTypeLocalGroupMember, Subject: "Administrators", Value: lbvalue.String(`CONTOSO\Workstation Admins`)},
+		"service-logon": {Type: lbdeploy.ConditionTypeUserRightGranted, Subject: "SeServiceLogonRight", Value: lbvalue.String("svc-backup")},
+		"batch-logon":   {Type: lbdeploy.ConditionTypeUserRightGranted, Subject: "SeBatchLogonRight", Value: lbvalue.String("svc-backup")},
+		"performance":   {Type: lbdeploy.ConditionTypePowerPlanActive, Value: lbvalue.String("high-performance")},
+		"custom-plan":   {Type: lbdeploy.ConditionTypePowerPlanActive, Value: lbvalue.String("{8C5E7FDA-E8BF-4A96-9A85-A6E23A8C635C}")},
+		"balanced":      {Type: lbdeploy.ConditionTypePowerPlanActive, Value: lbvalue.String("balanced")},
+		"any": {Any: []lbdeploy.Condition{
+			{Type: lbdeploy.ConditionTypeSubcondition, Subject: "dir-missing"},
+			{Type: lbdeploy.ConditionTypeSubcondition, Subject: "app-running"},
+		}},
+		"all": {All: []lbdeploy.Condition{
+			{Type: lbdeploy.ConditionTypeSubcondition, Subject: "file-present"},
+			{Type: lbdeploy.ConditionTypeSubcondition, Subject: "other-running"},
+		}},
+		"recursive": {Type: lbdeploy.ConditionTypeSubcondition, Subject: "recursive"},
+	},
+}
+
+var fileHash = func() filehash.Value {
+	sum := sha256.Sum256([]byte(testPlatform.Contents["present-file"]))
+	return sum[:]
+}()
+
+type conditionFixture struct {
+	Condition lbdeploy.ConditionID
+	Result    bool
+	Err       bool
+}
+
+var conditionFixtures = []conditionFixture{
+	{Condition: "dir-present", Result: true},
+	{Condition: "dir-missing", Result: false},
+	{Condition: "file-present", Result: true},
+	{Condition: "key-present", Result: true},
+	{Condition: "file-contains", Result: true},
+	{Condition: "file-expression", Result: false},
+	{Condition: "file-hash", Result: true},
+	{Condition: "missing-file-contains", Result: false},
+	{Condition: "value-missing", Result: true},
+	{Condition: "version-at-least-2", Result: true},
+	{Condition: "app-running", Result: true},
+	{Condition: "root-ca", Result: true},
+	{Condition: "memory-at-least-8g", Result: true},
+	{Condition: "more-than-8-cpus", Result: false},
+	{Condition: "tpm", Result: true},
+	{Condition: "secure-boot", Result: false},
+	{Condition: "virtual", Result: true},
+	{Condition: "vmware", Result: false},
+	{Condition: "german-ui", Result: true},
+	{Condition: "german-locale", Result: false},
+	{Condition: "swiss-keys", Result: true},
+	{Condition: "us-keys", Result: true},
+	{Condition: "french-keys", Result: false},
+	{Condition: "europe", Result: true},
+	{Condition: "other-running", Result: false},
+	{Condition: "backup-user", Result: true},
+	{Condition: "domain-admins", Result: true},
+	{Condition: "service-logon", Result: true},
+	{Condition: "batch-logon", Result: false},
+	{Condition: "performance", Result: true},
+	{Condition: "custom-plan", Result: true},
+	{Condition: "balanced", Result: false},
+	{Condition: "any", Result: true},
+	{Condition: "all", Result: false},
+	{Condition: "recursive", Err: true},
+	{Condition: "undefined", Err: true},
+}
+
+func TestConditionEngine(t *testing.T) {
+	engine := lbeval.NewConditionEngine(testDeployment, testPlatform)
+	for _, fixture := range conditionFixtures {
+		t.Run(string(fixture.Condition), func(t *testing.T) {
+			result, err := engine.Evaluate(fixture.Condition)
+			if fixture.Err {
+				if err == nil {
+					t.Fatalf("expected an error, got result %t", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != fixture.Result {
+				t.Errorf("got %t, want %t", result, fixture.Result)
+			}
+		})
+	}
+}
+
+func TestAppEngine(t *testing.T) {
+	engine := lbeval.NewAppEngine(testDeployment, testPlatform)
+
+	evaluation, err := engine.EvaluateAppChanges(lbdeploy.AppList{"registered", "detected"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evaluation.AlreadyInstalled) != 2 || len(evaluation.ToInstall) != 0 {
+		t.Errorf("unexpected evaluation: %+v", evaluation)
+	}
+
+	versions := map[lbdeploy.AppID]datatype.Version{"registered": "5.0.1", "detected": "2.1.0"}
+	for app, want := range versions {
+		got, err := engine.Version(app)
+		if err != nil {
+			t.Errorf("%s: %v", app, err)
+		} else if got != want {
+			t.Errorf("%s: got version \"%s\", want \"%s\"", app, got, want)
+		}
+	}
+}
+
+type traceFixture struct {
+	Condition lbdeploy.ConditionID
+	Observed  string
+	Expected  string
+}
+
+var traceFixtures = []traceFixture{
+	{Condition: "dir-missing", Observed: "absent"},
+	{Condition: "version-at-least-2", Observed: `"2.1.0" (Version)`, Expected: `>= "2.0" (Version)`},
+	{Condition: "app-running", Observed: "2 running"},
+	{Condition: "memory-at-least-8g", Observed: "16384 MiB", Expected: `>= "8192" (Int64)`},
+	{Condition: "swiss-keys", Observed: "00000409 (en-US), 00000807 (de-CH)", Expected: `= "00000807" (String)`},
+	{Condition: "file-expression", Observed: "content did not match"},
+}
+
+func TestConditionTracing(t *testing.T) {
+	observations := make(map[lbdeploy.ConditionID]lbeval.Observation)
+	engine := lbeval.NewConditionEngine(testDeployment, testPlatform).WithTracer(func(o lbeval.Observation) {
+		observations[o.Condition] = o
+	})
+	for _, fixture := range traceFixtures {
+		t.Run(string(fixture.Condition), func(t *testing.T) {
+			result, err := engine.Evaluate(fixture.Condition)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			o, found := observations[fixture.Condition]
+			if !found {
+				t.Fatal("the condition was not traced")
+			}
+			if o.Result != result {
+				t.Errorf("traced result %t, want %t", o.Result, result)
+			}
+			if o.Observed != fixture.Observed {
+				t.Errorf("observed \"%s\", want \"%s\"", o.Observed, fixture.Observed)
+			}
+			if got := o.Expected(); got != fixture.Expected {
+				t.Errorf("expected \"%s\", want \"%s\"", got, fixture.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package lbeval
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Explain returns lines of text that explain the observations made while
+// evaluating the condition with the given ID. The condition's own
+// observation is explained first, followed by the observations of the
+// conditions that it depends on.
+//
+// Conditions that were evaluated earlier are not observed again, so their
+// explanations appear under the first condition that needed them.
+func Explain(id lbdeploy.ConditionID, trace []Observation) []string {
+	var lines []string
+
+	// The condition itself is observed after the conditions it depends on.
+	own := slices.IndexFunc(trace, func(o Observation) bool {
+		return o.Condition == id
+	})
+	if own >= 0 {
+		o := trace[own]
+		if o.Type != "" {
+			lines = append(lines, "checks:   "+o.Check())
+		}
+		if expected := o.Expected(); expected != "" {
+			lines = append(lines, "compares: "+expected)
+		}
+		if o.Observed != "" {
+			lines = append(lines, "observed: "+o.Observed)
+		}
+		if o.Negated {
+			lines = append(lines, "negated:  the result is inverted")
+		}
+	}
+
+	for i, o := range trace {
+		if i == own {
+			continue
+		}
+		name := "nested condition"
+		if o.Condition != "" {
+			name = string(o.Condition)
+		}
+		explanation := o.Check()
+		if expected := o.Expected(); expected != "" {
+			explanation += " " + expected
+		}
+		if o.Observed != "" {
+			explanation += ", observed " + o.Observed
+		}
+		if o.Negated {
+			explanation += ", negated"
+		}
+		if o.Err != nil {
+			lines = append(lines, fmt.Sprintf("via %s: %s: %s", name, explanation, o.Err))
+		} else {
+			lines = append(lines, fmt.Sprintf("via %s: %s: %t", name, explanation, o.Result))
+		}
+	}
+
+	return lines
+}
+
+// Check returns a description of what the observed condition checks.
+func (o Observation) Check() string {
+	check := string(o.Type)
+	if check == "" {
+		check = "combination of conditions"
+	}
+	if o.Subject != "" {
+		check += fmt.Sprintf(" of \"%s\"", o.Subject)
+	}
+	if o.Label != "" {
+		check += fmt.Sprintf(" (%s)", o.Label)
+	}
+	return check
+}
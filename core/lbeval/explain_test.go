@@ -0,0 +1,55 @@
+package lbeval_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbeval"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+func TestExplain(t *testing.T) {
+	fixtures := []struct {
+		Name  string
+		ID    lbdeploy.ConditionID
+		Trace []lbeval.Observation
+		Want  []string
+	}{
+		{Name: "empty", ID: "office"},
+		{
+			Name: "single",
+			ID:   "office",
+			Trace: []lbeval.Observation{{
+				Condition: "office", Label: "Office", Type: lbdeploy.ConditionTypeRegistryValueComparison, Subject: "office-version",
+				Comparison: lbvalue.CompareGreaterThanOrEquals, Value: lbvalue.String("16.0"),
+				Observed: "16.0.1", Result: true,
+			}},
+			Want: []string{
+				`checks:   resource.registry.value:comparison of "office-version" (Office)`,
+				`compares: >= "16.0" (String)`,
+				`observed: 16.0.1`,
+			},
+		},
+		{
+			Name: "nested",
+			ID:   "ready",
+			Trace: []lbeval.Observation{
+				{Type: lbdeploy.ConditionTypeTPMPresent, Negated: true, Result: false},
+				{Condition: "office", Type: lbdeploy.ConditionTypeRegistryValueComparison, Subject: "office-version", Err: errors.New("not installed")},
+				{Condition: "ready", Result: false},
+			},
+			Want: []string{
+				"via nested condition: system.tpm:present, negated: false",
+				`via office: resource.registry.value:comparison of "office-version": not installed`,
+			},
+		},
+	}
+
+	for _, fixture := range fixtures {
+		if got := lbeval.Explain(fixture.ID, fixture.Trace); !slices.Equal(got, fixture.Want) {
+			t.Errorf("%s: got %q, want %q", fixture.Name, got, fixture.Want)
+		}
+	}
+}
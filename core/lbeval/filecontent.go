@@ -0,0 +1,85 @@
+package lbeval
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha3"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+)
+
+// matchFileContent returns true if the file exists and its content
+// satisfies the match criteria. It returns false if the file does not
+// exist.
+func matchFileContent(fs lbplatform.FileSystem, id lbdeploy.FileResourceID, match lbdeploy.FileContentMatch) (bool, error) {
+	// Prepare the comparison before touching the file, so that invalid
+	// criteria are reported even when the file is missing.
+	var compare func(string) bool
+	if len(match.Hashes) == 0 {
+		var err error
+		if compare, err = match.Compile(); err != nil {
+			return false, err
+		}
+	}
+
+	file, err := fs.OpenFile(id)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	if compare == nil {
+		return matchFileHashes(file, match.Hashes)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, lbdeploy.MaxFileContentSize+1))
+	if err != nil {
+		return false, err
+	}
+	if len(content) > lbdeploy.MaxFileContentSize {
+		return false, fmt.Errorf("the file exceeds the maximum size of %d bytes for content matching", lbdeploy.MaxFileContentSize)
+	}
+
+	return compare(string(content)), nil
+}
+
+// matchFileHashes returns true if the content read from r matches all of
+// the expected hashes.
+func matchFileHashes(r io.Reader, expected filehash.Map) (bool, error) {
+	hashes := make(map[filehash.Type]hash.Hash, len(expected))
+	writers := make([]io.Writer, 0, len(expected))
+	for typ := range expected {
+		var h hash.Hash
+		switch typ {
+		case filehash.SHA3_256:
+			h = sha3.New256()
+		case filehash.SHA256:
+			h = sha256.New()
+		default:
+			return false, fmt.Errorf("unrecognized file hash type \"%s\"", typ)
+		}
+		hashes[typ] = h
+		writers = append(writers, h)
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return false, err
+	}
+
+	for typ, h := range hashes {
+		if !bytes.Equal(h.Sum(nil), expected[typ]) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
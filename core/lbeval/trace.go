@@ -0,0 +1,90 @@
+package lbeval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// Observation describes the evaluation of a single condition, including
+// what was observed on the local system.
+//
+// Condition is empty for conditions that are nested within another
+// condition. Observed describes what was found, such as the data of a
+// registry value, the number of running processes or the active power
+// plan. It is empty for conditions that combine other conditions, and for
+// conditions that could not be evaluated.
+type Observation struct {
+	Condition  lbdeploy.ConditionID
+	Label      string
+	Type       lbdeploy.ConditionType
+	Subject    string
+	Comparison lbvalue.Comparison
+	Value      lbvalue.Value
+	Negated    bool
+	Observed   string
+	Result     bool
+	Err        error
+}
+
+// Expected returns a description of the value that the condition compares
+// against, such as ">= 16.0". It returns an empty string if the condition
+// does not have a value.
+func (o Observation) Expected() string {
+	if o.Value.Kind() == lbvalue.KindUnknown {
+		return ""
+	}
+	return o.Comparison.String() + " " + describeValue(o.Value)
+}
+
+// Tracer is a function that receives an observation for each condition
+// that is evaluated.
+type Tracer func(Observation)
+
+// WithTracer returns a copy of the engine that sends an observation to
+// tracer each time it evaluates a condition. Nested conditions are traced
+// before the conditions that contain them. Conditions whose results were
+// cached by an earlier evaluation are not traced again.
+func (engine ConditionEngine) WithTracer(tracer Tracer) ConditionEngine {
+	engine.tracer = tracer
+	return engine
+}
+
+// describeValue returns a description of a value that includes its kind.
+func describeValue(value lbvalue.Value) string {
+	return fmt.Sprintf("%q (%s)", value.String(), value.Kind())
+}
+
+// describeLayouts returns a description of the given keyboard layouts.
+func describeLayouts(layouts []lbplatform.KeyboardLayout) string {
+	if len(layouts) == 0 {
+		return "no keyboard layouts"
+	}
+	var out strings.Builder
+	for i, layout := range layouts {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(layout.ID)
+		if layout.Language != "" {
+			out.WriteString(" (" + layout.Language + ")")
+		}
+	}
+	return out.String()
+}
+
+// presence returns a description of whether something exists.
+func presence(exists bool) string {
+	return choose(exists, "present", "absent")
+}
+
+// choose returns a if condition is true, and b otherwise.
+func choose(condition bool, a, b string) string {
+	if condition {
+		return a
+	}
+	return b
+}
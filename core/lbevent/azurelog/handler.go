@@ -0,0 +1,478 @@
+// Package azurelog sends LeafBridge events to an Azure Monitor Log Analytics
+// workspace through the Logs Ingestion API.
+//
+// Events are mapped to [Entry] values and sent in batches to a data
+// collection endpoint. The data collection rule must declare a stream with
+// columns that match the fields of [Entry].
+package azurelog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+const (
+	// apiVersion is the version of the Logs Ingestion API.
+	apiVersion = "2023-01-01"
+
+	// maxPayload is the maximum size of a single upload to the Logs
+	// Ingestion API.
+	maxPayload = 1024 * 1024
+
+	// sendTimeout is the maximum amount of time allowed for each upload.
+	sendTimeout = 30 * time.Second
+
+	// closeTimeout is the maximum amount of time that Close waits for
+	// queued batches to be sent. Batches that have not been sent by then
+	// are spooled.
+	closeTimeout = 30 * time.Second
+
+	// maxErrors is the maximum number of errors encountered by the
+	// background sender that are retained until the next flush.
+	maxErrors = 10
+)
+
+const (
+	// DefaultBatchSize is the number of events that are collected before
+	// they are sent, when a batch size is not specified.
+	DefaultBatchSize = 100
+
+	// DefaultQueueSize is the number of batches that can wait to be sent,
+	// when a queue size is not specified.
+	DefaultQueueSize = 16
+
+	// DefaultMaxSpoolSize is the maximum total size of the spooled batches
+	// in bytes, when a maximum size is not specified.
+	DefaultMaxSpoolSize = 64 * 1024 * 1024
+)
+
+// errClosed is returned when events are recorded after the handler has
+// been closed.
+var errClosed = errors.New("the Azure Log Analytics event handler has been closed")
+
+// Config holds the configuration for an Azure Log Analytics event handler.
+type Config struct {
+	// Endpoint is the logs ingestion URL of a data collection endpoint,
+	// such as https://my-dce.eastus-1.ingest.monitor.azure.com.
+	Endpoint string
+
+	// RuleID is the immutable ID of the data collection rule.
+	RuleID string
+
+	// Stream is the name of the stream within the data collection rule,
+	// such as Custom-LeafBridge_CL.
+	Stream string
+
+	// Credentials provide bearer tokens for the Logs Ingestion API.
+	Credentials TokenSource
+
+	// BatchSize is the number of events that are collected before they are
+	// sent. If zero, DefaultBatchSize is used.
+	BatchSize int
+
+	// QueueSize is the number of batches that can wait to be sent. When
+	// the queue is full, further batches are spooled. If zero,
+	// DefaultQueueSize is used.
+	QueueSize int
+
+	// SpoolDir is a directory that holds batches that could not be sent,
+	// so that they can be sent later. If empty, batches that cannot be
+	// sent are dropped.
+	SpoolDir string
+
+	// MaxSpoolSize is the maximum total size of the spooled batches in
+	// bytes. When it would be exceeded, the oldest batches are dropped. If
+	// zero, DefaultMaxSpoolSize is used.
+	MaxSpoolSize int64
+
+	// Client is the HTTP client used to send events. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Handler is a LeafBridge event handler that sends events to an Azure Log
+// Analytics workspace.
+//
+// Events are collected until a full batch is available, then queued to be
+// sent by a background goroutine, so that recording an event never waits
+// for the network. Any remaining events are queued when the handler is
+// flushed or closed. If a batch cannot be sent, or the queue is full, and
+// a spool directory has been configured, the batch is written to the spool
+// and sent with the next successful batch.
+//
+// Errors encountered while sending are returned by the next call to Flush
+// or Close.
+type Handler struct {
+	config  Config
+	url     string
+	spool   *spool
+	queue   chan request
+	stopped chan struct{}
+
+	// ctx is cancelled when Close gives up waiting for queued batches to
+	// be sent.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex   sync.Mutex
+	pending []Entry
+	closed  bool
+}
+
+// request is a set of batches queued to be sent by the background sender.
+// If flushed is not nil, the sender reports the errors it has encountered
+// since the last flush to it once the batches have been handled.
+type request struct {
+	batches [][]byte
+	flushed chan error
+}
+
+// NewHandler returns a new Azure Log Analytics event handler with the given
+// configuration.
+func NewHandler(config Config) (*Handler, error) {
+	switch {
+	case config.Endpoint == "":
+		return nil, errors.New("an Azure Log Analytics data collection endpoint was not provided")
+	case config.RuleID == "":
+		return nil, errors.New("an Azure Log Analytics data collection rule ID was not provided")
+	case config.Stream == "":
+		return nil, errors.New("an Azure Log Analytics stream name was not provided")
+	case config.Credentials == nil:
+		return nil, errors.New("Azure Log Analytics credentials were not provided")
+	case config.BatchSize < 0:
+		return nil, errors.New("the Azure Log Analytics batch size must not be negative")
+	case config.QueueSize < 0:
+		return nil, errors.New("the Azure Log Analytics queue size must not be negative")
+	case config.MaxSpoolSize < 0:
+		return nil, errors.New("the Azure Log Analytics maximum spool size must not be negative")
+	}
+
+	if config.BatchSize == 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.MaxSpoolSize == 0 {
+		config.MaxSpoolSize = DefaultMaxSpoolSize
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+
+	h := &Handler{
+		config: config,
+		url: fmt.Sprintf("%s/dataCollectionRules/%s/streams/%s?api-version=%s",
+			strings.TrimSuffix(config.Endpoint, "/"),
+			url.PathEscape(config.RuleID),
+			url.PathEscape(config.Stream),
+			apiVersion),
+		queue:   make(chan request, config.QueueSize),
+		stopped: make(chan struct{}),
+	}
+	h.ctx, h.cancel = context.WithCancel(context.Background())
+	if config.SpoolDir != "" {
+		h.spool = &spool{dir: config.SpoolDir, maxSize: config.MaxSpoolSize}
+	}
+
+	go h.run()
+
+	return h, nil
+}
+
+// Name returns a name for the handler.
+func (h *Handler) Name() string {
+	return "azure-log-analytics"
+}
+
+// Handle processes the given event record.
+//
+// When a full batch of events has been collected, it is queued to be sent
+// in the background. If the queue is full, the batch is spooled instead.
+func (h *Handler) Handle(r lbevent.Record) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		return errClosed
+	}
+
+	h.pending = append(h.pending, NewEntry(r))
+	if len(h.pending) < h.config.BatchSize {
+		return nil
+	}
+
+	batches, err := h.takePending()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case h.queue <- request{batches: batches}:
+		return nil
+	default:
+	}
+
+	if h.spool == nil {
+		return fmt.Errorf("%d %s of events could not be queued and were dropped: the queue of batches waiting to be sent is full", len(batches), plural(len(batches), "batch", "batches"))
+	}
+	return h.spoolBatches(batches)
+}
+
+// Flush sends any events that have been collected, and waits for all of
+// the queued batches to be handled. It returns any errors that have been
+// encountered while sending events since the last flush.
+func (h *Handler) Flush() error {
+	h.mutex.Lock()
+	if h.closed {
+		h.mutex.Unlock()
+		return errClosed
+	}
+	batches, err := h.takePending()
+	flushed := make(chan error, 1)
+	h.queue <- request{batches: batches, flushed: flushed}
+	h.mutex.Unlock()
+
+	return errors.Join(err, <-flushed)
+}
+
+// Close sends any events that have been collected and stops the background
+// sender. It returns any errors that have been encountered while sending
+// events since the last flush.
+//
+// If the queued batches have not been sent within a reasonable amount of
+// time, the remaining batches are spooled instead.
+func (h *Handler) Close() error {
+	h.mutex.Lock()
+	if h.closed {
+		h.mutex.Unlock()
+		return nil
+	}
+	h.closed = true
+	batches, err := h.takePending()
+	h.mutex.Unlock()
+
+	timer := time.AfterFunc(closeTimeout, h.cancel)
+	defer timer.Stop()
+	defer h.cancel()
+
+	// Once the handler has been marked as closed, no other goroutine will
+	// send to the queue.
+	flushed := make(chan error, 1)
+	h.queue <- request{batches: batches, flushed: flushed}
+	close(h.queue)
+
+	err = errors.Join(err, <-flushed)
+	<-h.stopped
+
+	return err
+}
+
+// takePending encodes the pending events as batches and clears them. The
+// caller must hold a lock on h.mutex.
+func (h *Handler) takePending() ([][]byte, error) {
+	if len(h.pending) == 0 {
+		return nil, nil
+	}
+	batches, err := encodeBatches(h.pending)
+	h.pending = nil
+	return batches, err
+}
+
+// run sends queued batches until the queue is closed. It is run in its own
+// goroutine.
+func (h *Handler) run() {
+	defer close(h.stopped)
+
+	var (
+		errs    []error
+		omitted int
+	)
+	for req := range h.queue {
+		if err := h.deliver(req.batches); err != nil {
+			if len(errs) < maxErrors {
+				errs = append(errs, err)
+			} else {
+				omitted++
+			}
+		}
+		if req.flushed != nil {
+			if omitted > 0 {
+				errs = append(errs, fmt.Errorf("%d more %s occurred while sending events", omitted, plural(omitted, "error", "errors")))
+			}
+			req.flushed <- errors.Join(errs...)
+			errs, omitted = nil, 0
+		}
+	}
+}
+
+// deliver sends spooled batches, followed by the given batches. Batches
+// that cannot be sent are spooled.
+//
+// If the handler is being closed and has stopped waiting for batches to be
+// sent, the batches are spooled without attempting to send them.
+func (h *Handler) deliver(batches [][]byte) error {
+	if len(batches) == 0 && h.spool == nil {
+		return nil
+	}
+	if h.ctx.Err() != nil {
+		return h.spoolBatches(batches)
+	}
+
+	// Send previously spooled batches first, so that events arrive in the
+	// order they occurred.
+	if err := h.sendSpooled(); err != nil {
+		return errors.Join(err, h.spoolBatches(batches))
+	}
+
+	for i, batch := range batches {
+		if err := h.send(batch); err != nil {
+			return errors.Join(err, h.spoolBatches(batches[i:]))
+		}
+	}
+
+	return nil
+}
+
+// sendSpooled sends each of the spooled batches, oldest first. Batches are
+// removed from the spool after they have been sent.
+//
+// Batches that are removed from the spool by the size limit while they are
+// being sent are skipped.
+func (h *Handler) sendSpooled() error {
+	if h.spool == nil {
+		return nil
+	}
+
+	files, err := h.spool.Files()
+	if err != nil {
+		return fmt.Errorf("failed to read the spool directory: %w", err)
+	}
+
+	for _, file := range files {
+		batch, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to read a spooled batch: %w", err)
+		}
+		if err := h.send(batch); err != nil {
+			return err
+		}
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove a spooled batch: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// spoolBatches writes batches that could not be sent to the spool. It
+// returns an error if any batches were dropped, either because they could
+// not be spooled or because older batches were removed to keep the spool
+// within its size limit.
+func (h *Handler) spoolBatches(batches [][]byte) error {
+	if len(batches) == 0 {
+		return nil
+	}
+	if h.spool == nil {
+		return fmt.Errorf("%d %s of events could not be sent and were dropped", len(batches), plural(len(batches), "batch", "batches"))
+	}
+	var (
+		errs    []error
+		dropped int
+	)
+	for _, batch := range batches {
+		n, err := h.spool.Write(batch)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to spool a batch of events: %w", err))
+		}
+		dropped += n
+	}
+	if dropped > 0 {
+		errs = append(errs, fmt.Errorf("%d spooled %s of events were dropped to keep the spool within %d bytes", dropped, plural(dropped, "batch", "batches"), h.spool.maxSize))
+	}
+	return errors.Join(errs...)
+}
+
+// send uploads a batch to the Logs Ingestion API.
+func (h *Handler) send(batch []byte) error {
+	ctx, cancel := context.WithTimeout(h.ctx, sendTimeout)
+	defer cancel()
+
+	token, err := h.config.Credentials.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send events to Azure Log Analytics: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to send events to Azure Log Analytics: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// encodeBatches encodes entries as JSON arrays that fit within the maximum
+// payload size of the Logs Ingestion API.
+func encodeBatches(entries []Entry) ([][]byte, error) {
+	var (
+		batches [][]byte
+		current bytes.Buffer
+	)
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode a \"%s\" event: %w", entry.EventType, err)
+		}
+		if current.Len() > 0 && current.Len()+len(data)+2 > maxPayload {
+			current.WriteByte(']')
+			batches = append(batches, bytes.Clone(current.Bytes()))
+			current.Reset()
+		}
+		if current.Len() == 0 {
+			current.WriteByte('[')
+		} else {
+			current.WriteByte(',')
+		}
+		current.Write(data)
+	}
+	if current.Len() > 0 {
+		current.WriteByte(']')
+		batches = append(batches, current.Bytes())
+	}
+	return batches, nil
+}
+
+func plural(value int, singular, plural string) string {
+	if value == 1 {
+		return singular
+	}
+	return plural
+}
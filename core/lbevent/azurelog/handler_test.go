@@ -0,0 +1,156 @@
+package azurelog_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbevent/azurelog"
+)
+
+type testEvent struct {
+	Number int
+}
+
+func (e testEvent) Type() lbevent.Type { return "test:event" }
+func (e testEvent) Level() slog.Level  { return slog.LevelInfo }
+func (e testEvent) Message() string    { return "test event" }
+func (e testEvent) Details() string    { return "" }
+func (e testEvent) Attrs() []slog.Attr { return []slog.Attr{slog.Int("number", e.Number)} }
+
+type staticToken string
+
+func (t staticToken) Token(ctx context.Context) (string, error) { return string(t), nil }
+
+type ingestionServer struct {
+	mutex    sync.Mutex
+	fail     bool
+	received []azurelog.Entry
+}
+
+func (s *ingestionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if s.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var entries []azurelog.Entry
+	if err := json.NewDecoder(r.Body).Decode(&entries); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.received = append(s.received, entries...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestHandler(t *testing.T) {
+	server := &ingestionServer{fail: true}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	spoolDir := t.TempDir()
+	handler, err := azurelog.NewHandler(azurelog.Config{
+		Endpoint:    ts.URL,
+		RuleID:      "dcr-test",
+		Stream:      "Custom-LeafBridge_CL",
+		Credentials: staticToken("test-token"),
+		BatchSize:   2,
+		SpoolDir:    spoolDir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := lbevent.Recorder{Handler: handler, Origin: lbevent.Origin{RunID: "run", Machine: "machine"}}
+
+	// The first batch is queued without waiting for the server. It fails
+	// and should be spooled, and the failure reported by the next flush.
+	recorder.Record(testEvent{Number: 1})
+	if err := recorder.Record(testEvent{Number: 2}); err != nil {
+		t.Fatalf("recording an event waited for the server: %v", err)
+	}
+	if err := handler.Flush(); err == nil {
+		t.Fatal("expected an error when the server is unavailable")
+	}
+	if files, _ := os.ReadDir(spoolDir); len(files) != 1 {
+		t.Fatalf("expected 1 spooled batch, found %d", len(files))
+	}
+
+	// The next flush should send the spooled batch, then the pending event.
+	server.mutex.Lock()
+	server.fail = false
+	server.mutex.Unlock()
+	recorder.Record(testEvent{Number: 3})
+	if err := handler.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(spoolDir); len(files) != 0 {
+		t.Fatalf("expected the spool to be empty, found %d batches", len(files))
+	}
+	if err := recorder.Record(testEvent{Number: 4}); err == nil {
+		t.Fatal("expected an error when recording an event after the handler was closed")
+	}
+
+	if len(server.received) != 3 {
+		t.Fatalf("expected 3 entries, received %d", len(server.received))
+	}
+	for i, entry := range server.received {
+		if got := entry.Attributes["number"]; got != float64(i+1) {
+			t.Errorf("entry %d: unexpected number attribute: %v", i, got)
+		}
+		if entry.RunID != "run" || entry.Computer != "machine" {
+			t.Errorf("entry %d: unexpected origin: %s, %s", i, entry.RunID, entry.Computer)
+		}
+		if entry.TimeGenerated.IsZero() || time.Since(entry.TimeGenerated) > time.Minute {
+			t.Errorf("entry %d: unexpected time: %s", i, entry.TimeGenerated)
+		}
+	}
+}
+
+func TestHandlerSpoolLimit(t *testing.T) {
+	server := &ingestionServer{fail: true}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	spoolDir := t.TempDir()
+	handler, err := azurelog.NewHandler(azurelog.Config{
+		Endpoint:     ts.URL,
+		RuleID:       "dcr-test",
+		Stream:       "Custom-LeafBridge_CL",
+		Credentials:  staticToken("test-token"),
+		BatchSize:    1,
+		SpoolDir:     spoolDir,
+		MaxSpoolSize: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	recorder := lbevent.Recorder{Handler: handler, Origin: lbevent.Origin{RunID: "run", Machine: "machine"}}
+	for i := range 3 {
+		recorder.Record(testEvent{Number: i})
+	}
+
+	err = handler.Flush()
+	if err == nil || !strings.Contains(err.Error(), "dropped to keep the spool within 1 bytes") {
+		t.Fatalf("expected an error reporting dropped batches, got %v", err)
+	}
+	if files, _ := os.ReadDir(spoolDir); len(files) != 0 {
+		t.Fatalf("expected the spool to be empty, found %d batches", len(files))
+	}
+}
@@ -0,0 +1,73 @@
+package azurelog
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Entry is a single log entry sent to the Logs Ingestion API. Its fields
+// map to the columns of the destination table, which must be declared by
+// the data collection rule's stream.
+type Entry struct {
+	TimeGenerated time.Time      `json:"TimeGenerated"`
+	EventType     string         `json:"EventType"`
+	Level         string         `json:"Level"`
+	Message       string         `json:"Message"`
+	Details       string         `json:"Details,omitempty"`
+	RunID         string         `json:"RunId,omitempty"`
+	Computer      string         `json:"Computer,omitempty"`
+	Host          map[string]any `json:"Host,omitempty"`
+	Attributes    map[string]any `json:"Attributes,omitempty"`
+}
+
+// NewEntry maps an event record to a log entry.
+func NewEntry(r lbevent.Record) Entry {
+	origin := r.Origin()
+	return Entry{
+		TimeGenerated: r.Time().UTC(),
+		EventType:     string(r.Type()),
+		Level:         r.Level().String(),
+		Message:       r.Message(),
+		Details:       r.Details(),
+		RunID:         string(origin.RunID),
+		Computer:      origin.Machine,
+		Host:          attrMap(origin.Host.Attrs()),
+		Attributes:    attrMap(r.Attrs()),
+	}
+}
+
+// attrMap converts a set of structured logging attributes to a map that
+// can be marshaled as a JSON object. Groups become nested objects.
+func attrMap(attrs []slog.Attr) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		m[attr.Key] = attrValue(attr.Value)
+	}
+	return m
+}
+
+// attrValue converts a structured logging value to a value that can be
+// marshaled as JSON.
+func attrValue(v slog.Value) any {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		return attrMap(v.Group())
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().UTC()
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		return v.Any()
+	default:
+		return v.Any()
+	}
+}
@@ -0,0 +1,96 @@
+package azurelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spoolExt is the file extension used for spooled batches.
+const spoolExt = ".json"
+
+// spool is a directory that holds batches of entries that could not be
+// sent. Each batch is stored in its own file, with names that sort in the
+// order the batches were spooled.
+//
+// The total size of the spooled batches is limited to maxSize. When a
+// batch is written that would exceed the limit, the oldest batches are
+// removed to make room for it.
+type spool struct {
+	dir     string
+	maxSize int64
+
+	mutex sync.Mutex
+	seq   int
+}
+
+// Write stores a batch in the spool. It returns the number of older
+// batches that were removed to keep the spool within its size limit.
+func (s *spool) Write(batch []byte) (dropped int, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return 0, err
+	}
+	s.seq++
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.seq, spoolExt)
+	if err := os.WriteFile(filepath.Join(s.dir, name), batch, 0600); err != nil {
+		return 0, err
+	}
+	return s.trim()
+}
+
+// Files returns the paths of the spooled batches, oldest first.
+func (s *spool) Files() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), spoolExt) {
+			files = append(files, filepath.Join(s.dir, entry.Name()))
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// trim removes the oldest batches until the total size of the spool is
+// within its limit. It returns the number of batches that were removed.
+// The caller must hold a lock on s.mutex.
+func (s *spool) trim() (dropped int, err error) {
+	files, err := s.Files()
+	if err != nil {
+		return 0, err
+	}
+
+	sizes := make([]int64, len(files))
+	var total int64
+	for i, file := range files {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return 0, err
+		}
+		sizes[i] = fi.Size()
+		total += sizes[i]
+	}
+
+	for i := 0; i < len(files) && total > s.maxSize; i++ {
+		if err := os.Remove(files[i]); err != nil {
+			return dropped, err
+		}
+		total -= sizes[i]
+		dropped++
+	}
+
+	return dropped, nil
+}
@@ -0,0 +1,103 @@
+package azurelog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// monitorScope is the OAuth 2.0 scope required by the Logs Ingestion API.
+const monitorScope = "https://monitor.azure.com//.default"
+
+// TokenSource is a source of bearer tokens for the Logs Ingestion API.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// ClientCredentials is a token source that acquires tokens for a Microsoft
+// Entra application with a client secret.
+//
+// Tokens are cached until shortly before they expire.
+type ClientCredentials struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+
+	// Authority is the base URL of the identity platform. If empty,
+	// https://login.microsoftonline.com is used.
+	Authority string
+
+	// Client is the HTTP client used to acquire tokens. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+
+	mutex   sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Token returns a bearer token for the Logs Ingestion API.
+func (c *ClientCredentials) Token(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	authority := c.Authority
+	if authority == "" {
+		authority = "https://login.microsoftonline.com"
+	}
+	endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(authority, "/"), url.PathEscape(c.TenantID))
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"scope":         {monitorScope},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire an access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to acquire an access token: %s", resp.Status)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse the access token response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("the access token response did not include a token")
+	}
+
+	// Renew the token a minute before it expires.
+	c.token = result.AccessToken
+	c.expires = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+
+	return c.token, nil
+}
@@ -0,0 +1,49 @@
+package catalog
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Supported returns the locales of the built-in catalogs.
+func Supported() []string {
+	entries, _ := locales.ReadDir("locales")
+	supported := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		supported = append(supported, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	slices.Sort(supported)
+	return supported
+}
+
+// Lookup returns the built-in catalog for the given locale, such as "de" or
+// "fr-CH". If there is no catalog for a regional locale, the catalog for its
+// language is returned.
+//
+// English is the language of the events themselves, so Lookup returns a nil
+// catalog for English and for an empty locale. A nil catalog returns events
+// messages unmodified.
+//
+// If the locale is not supported, an error is returned.
+func Lookup(locale string) (*Catalog, error) {
+	name := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	language, _, _ := strings.Cut(name, "-")
+	if language == "" || language == "en" {
+		return nil, nil
+	}
+
+	for _, candidate := range []string{name, language} {
+		data, err := locales.ReadFile(path.Join("locales", candidate+".json"))
+		if err == nil {
+			return Parse(data)
+		}
+	}
+
+	return nil, fmt.Errorf("the \"%s\" locale is not supported (supported locales: en, %s)", locale, strings.Join(Supported(), ", "))
+}
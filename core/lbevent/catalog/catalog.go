@@ -0,0 +1,116 @@
+// Package catalog localizes the messages of LeafBridge events.
+//
+// A catalog holds message templates for a single locale, keyed by event
+// type. Each template is a [text/template] that is executed with the event
+// as its data. Events without a template keep their original message, which
+// is written in English.
+//
+// Catalogs for German and French are built in. Additional catalogs can be
+// parsed from JSON data in the same format:
+//
+//	{
+//		"locale": "de",
+//		"messages": {
+//			"deployment.flow:started": "{{.Deployment}}: {{.Flow}}: Wird gestartet."
+//		}
+//	}
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Catalog holds localized message templates for a locale.
+type Catalog struct {
+	locale    string
+	templates map[lbevent.Type]*template.Template
+}
+
+// file is the JSON representation of a catalog.
+type file struct {
+	Locale   string                  `json:"locale"`
+	Messages map[lbevent.Type]string `json:"messages"`
+}
+
+// Parse parses a catalog from the given JSON data.
+func Parse(data []byte) (*Catalog, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse message catalog: %w", err)
+	}
+	if f.Locale == "" {
+		return nil, fmt.Errorf("the message catalog does not specify a locale")
+	}
+
+	c := &Catalog{
+		locale:    f.Locale,
+		templates: make(map[lbevent.Type]*template.Template, len(f.Messages)),
+	}
+	for eventType, text := range f.Messages {
+		tmpl, err := template.New(string(eventType)).Funcs(funcs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("the \"%s\" message catalog has an invalid template for \"%s\": %w", f.Locale, eventType, err)
+		}
+		c.templates[eventType] = tmpl
+	}
+
+	return c, nil
+}
+
+// Locale returns the locale of the catalog.
+func (c *Catalog) Locale() string {
+	if c == nil {
+		return ""
+	}
+	return c.locale
+}
+
+// Types returns the event types that have a template in the catalog.
+func (c *Catalog) Types() []lbevent.Type {
+	if c == nil {
+		return nil
+	}
+	types := make([]lbevent.Type, 0, len(c.templates))
+	for eventType := range c.templates {
+		types = append(types, eventType)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Message returns the localized message for the given event. If the
+// catalog does not have a template for the event's type, or if the template
+// cannot be executed, the event's own message is returned.
+func (c *Catalog) Message(event lbevent.Interface) string {
+	if c == nil {
+		return event.Message()
+	}
+	tmpl, ok := c.templates[event.Type()]
+	if !ok {
+		return event.Message()
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, event); err != nil {
+		return event.Message()
+	}
+	return out.String()
+}
+
+// funcs are the functions that are available to message templates.
+var funcs = template.FuncMap{
+	// number returns the one-based number of a zero-based index.
+	"number": func(index int) int {
+		return index + 1
+	},
+	// round rounds a duration to the nearest hundredth of a second.
+	"round": func(d time.Duration) time.Duration {
+		return d.Round(time.Millisecond * 10)
+	},
+}
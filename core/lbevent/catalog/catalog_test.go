@@ -0,0 +1,92 @@
+package catalog_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbevent/catalog"
+)
+
+func TestBuiltinCatalogs(t *testing.T) {
+	events := lbevent.NewRegistry(100)
+	if err := events.Add(lbdeployevent.Registrations...); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, locale := range catalog.Supported() {
+		c, err := catalog.Lookup(locale)
+		if err != nil {
+			t.Fatalf("%s: %v", locale, err)
+		}
+		for _, eventType := range c.Types() {
+			record, err := events.UnmarshalRecord(fmt.Appendf(nil, `{"type":%q,"data":{}}`, eventType))
+			if err != nil {
+				t.Errorf("%s: %s: %v", locale, eventType, err)
+				continue
+			}
+			if c.Message(record.Data()) == record.Message() {
+				t.Errorf("%s: %s: the message was not localized", locale, eventType)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	fixtures := []struct {
+		Locale string
+		Want   string
+		Err    bool
+	}{
+		{Locale: "", Want: ""},
+		{Locale: "en-US", Want: ""},
+		{Locale: "de", Want: "de"},
+		{Locale: "de_AT", Want: "de"},
+		{Locale: "fr-CH", Want: "fr"},
+		{Locale: "xx", Err: true},
+	}
+
+	for _, fixture := range fixtures {
+		c, err := catalog.Lookup(fixture.Locale)
+		switch {
+		case fixture.Err && err == nil:
+			t.Errorf("%s: expected an error", fixture.Locale)
+		case !fixture.Err && err != nil:
+			t.Errorf("%s: %v", fixture.Locale, err)
+		case c.Locale() != fixture.Want:
+			t.Errorf("%s: got catalog \"%s\", want \"%s\"", fixture.Locale, c.Locale(), fixture.Want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	c, err := catalog.Lookup("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := lbdeployevent.FlowCondition{
+		Deployment:      "example",
+		Flow:            "install",
+		Use:             lbdeploy.ConditionUsePrecondition,
+		Failed:          lbdeploy.ConditionList{"windows-11"},
+		FailureMessages: []string{"Diese App erfordert Windows 11 22H2 oder höher."},
+	}
+	if got, want := c.Message(event), "example: install: Diese App erfordert Windows 11 22H2 oder höher. (nicht erfüllt: windows-11)"; got != want {
+		t.Errorf("got \"%s\", want \"%s\"", got, want)
+	}
+
+	stopped := lbdeployevent.FlowStopped{Deployment: "example", Flow: "install", Err: errors.New("exit status 1603")}
+	if got := c.Message(stopped); !strings.Contains(got, "Nach einem Fehler beendet: exit status 1603.") {
+		t.Errorf("got \"%s\"", got)
+	}
+
+	unknown := lbdeployevent.FlowLockNotAcquired{Deployment: "example", Flow: "install", Lock: "msi"}
+	if got, want := c.Message(unknown), unknown.Message(); got != want {
+		t.Errorf("got \"%s\", want \"%s\"", got, want)
+	}
+}
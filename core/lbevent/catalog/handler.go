@@ -0,0 +1,55 @@
+package catalog
+
+import (
+	"log/slog"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Handler is a LeafBridge event handler that localizes the messages of
+// events before passing them to another handler.
+type Handler struct {
+	catalog *Catalog
+	next    lbevent.Handler
+}
+
+// NewHandler returns a handler that localizes events with c and passes them
+// to next. If c is nil, events are passed to next unmodified.
+func NewHandler(c *Catalog, next lbevent.Handler) Handler {
+	return Handler{catalog: c, next: next}
+}
+
+// Name returns a name for the handler.
+func (h Handler) Name() string {
+	return h.next.Name()
+}
+
+// Handle processes the given event record.
+func (h Handler) Handle(r lbevent.Record) error {
+	if h.catalog == nil {
+		return h.next.Handle(r)
+	}
+	return h.next.Handle(localizedRecord{
+		Record:  r,
+		message: h.catalog.Message(r.Data()),
+	})
+}
+
+// localizedRecord is an event record with a localized message.
+type localizedRecord struct {
+	lbevent.Record
+	message string
+}
+
+// Message returns the localized message of the event.
+func (r localizedRecord) Message() string {
+	return r.message
+}
+
+// ToLog returns the event record as a structured logging record with the
+// localized message.
+func (r localizedRecord) ToLog() slog.Record {
+	out := r.Record.ToLog()
+	out.Message = r.message
+	return out
+}
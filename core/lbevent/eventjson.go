@@ -0,0 +1,128 @@
+package lbevent
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// RecordedError is an error that has been restored from a stored event
+// record. It holds the text of the original error.
+type RecordedError string
+
+// Error returns the text of the original error.
+func (e RecordedError) Error() string {
+	return string(e)
+}
+
+// errorType is the reflected type of the error interface.
+var errorType = reflect.TypeFor[error]()
+
+// errorField describes a field of an event that holds an error.
+type errorField struct {
+	index int
+	name  string
+}
+
+// errorFields returns the fields of t that hold an error. It returns nil if
+// t is not a struct.
+func errorFields(t reflect.Type) []errorField {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []errorField
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() || field.Type != errorType {
+			continue
+		}
+		name := field.Name
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fields = append(fields, errorField{index: i, name: name})
+	}
+	return fields
+}
+
+// marshalEvent marshals event as JSON data.
+//
+// Errors held by the event are encoded as strings, because most errors
+// don't have any exported fields and would otherwise be lost.
+func marshalEvent(event any) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	value := reflect.ValueOf(event)
+	fields := errorFields(value.Type())
+	if len(fields) == 0 {
+		return data, nil
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		fv := value.Field(field.index)
+		if fv.IsNil() {
+			continue
+		}
+		text, err := json.Marshal(fv.Interface().(error).Error())
+		if err != nil {
+			return nil, err
+		}
+		m[field.name] = text
+	}
+	return json.Marshal(m)
+}
+
+// unmarshalEvent unmarshals the JSON data produced by marshalEvent into the
+// event that event points to. Errors are restored as [RecordedError]
+// values.
+func unmarshalEvent(data []byte, event any) error {
+	value := reflect.ValueOf(event).Elem()
+	fields := errorFields(value.Type())
+	if len(fields) == 0 {
+		return json.Unmarshal(data, event)
+	}
+
+	// Remove the errors from the data before unmarshaling it, because
+	// they can't be unmarshaled into an error interface.
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	errs := make(map[int]error, len(fields))
+	for _, field := range fields {
+		raw, found := m[field.name]
+		if !found {
+			continue
+		}
+		delete(m, field.name)
+		var text string
+		switch {
+		case string(raw) == "null":
+			continue
+		case json.Unmarshal(raw, &text) == nil:
+			errs[field.index] = RecordedError(text)
+		default:
+			errs[field.index] = RecordedError("an error was recorded without a description")
+		}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, event); err != nil {
+		return err
+	}
+	for index, err := range errs {
+		value.Field(index).Set(reflect.ValueOf(err))
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Package gelf sends LeafBridge events to Graylog and other log servers
+// that accept the Graylog Extended Log Format (GELF).
+//
+// Messages are sent over UDP or TCP. UDP messages are compressed with gzip
+// and split into chunks when they don't fit within a single datagram. TCP
+// messages are terminated by a null byte, as required by GELF.
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Supported networks.
+const (
+	NetworkUDP = "udp"
+	NetworkTCP = "tcp"
+)
+
+// DefaultChunkSize is the maximum size of each UDP datagram when a chunk
+// size is not specified. It is small enough to traverse most networks
+// without fragmentation.
+const DefaultChunkSize = 1420
+
+const (
+	// chunkHeaderSize is the size of the header of each chunk.
+	chunkHeaderSize = 12
+
+	// maxChunks is the maximum number of chunks in a message.
+	maxChunks = 128
+
+	// dialTimeout is the maximum amount of time allowed to connect to a
+	// server over TCP.
+	dialTimeout = 10 * time.Second
+
+	// writeTimeout is the maximum amount of time allowed for each message
+	// to be written.
+	writeTimeout = 10 * time.Second
+)
+
+// chunkMagic identifies a chunked GELF message.
+var chunkMagic = [2]byte{0x1e, 0x0f}
+
+// Config holds the configuration for a GELF event handler.
+type Config struct {
+	// Address is the host and port of the GELF input, such as
+	// graylog.example.com:12201.
+	Address string
+
+	// Network is either "udp" or "tcp". If empty, UDP is used.
+	Network string
+
+	// Host identifies the computer in each message. If empty, the machine
+	// of each event's origin is used, or the host name of the computer if
+	// the origin doesn't have one.
+	Host string
+
+	// ChunkSize is the maximum size of each UDP datagram. If zero,
+	// DefaultChunkSize is used.
+	ChunkSize int
+}
+
+// Handler is a LeafBridge event handler that sends events to a GELF input.
+//
+// Each event is sent as it is handled. TCP connections are established
+// when they are first needed, and reestablished if they fail.
+type Handler struct {
+	config Config
+	mutex  sync.Mutex
+	conn   net.Conn
+}
+
+// NewHandler returns a new GELF event handler with the given configuration.
+func NewHandler(config Config) (*Handler, error) {
+	if config.Network == "" {
+		config.Network = NetworkUDP
+	}
+	switch {
+	case config.Address == "":
+		return nil, errors.New("a GELF server address was not provided")
+	case config.Network != NetworkUDP && config.Network != NetworkTCP:
+		return nil, fmt.Errorf("the \"%s\" network is not supported for GELF (use udp or tcp)", config.Network)
+	case config.ChunkSize < 0:
+		return nil, errors.New("the GELF chunk size must not be negative")
+	case config.ChunkSize > 0 && config.ChunkSize <= chunkHeaderSize:
+		return nil, fmt.Errorf("the GELF chunk size must be larger than %d bytes", chunkHeaderSize)
+	}
+
+	if config.ChunkSize == 0 {
+		config.ChunkSize = DefaultChunkSize
+	}
+	if config.Host == "" {
+		config.Host, _ = os.Hostname()
+	}
+
+	return &Handler{config: config}, nil
+}
+
+// Name returns a name for the handler.
+func (h *Handler) Name() string {
+	return "gelf"
+}
+
+// Handle processes the given event record.
+func (h *Handler) Handle(r lbevent.Record) error {
+	msg, err := Encode(r, h.config.Host)
+	if err != nil {
+		return fmt.Errorf("failed to encode a \"%s\" event for GELF: %w", r.Type(), err)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.config.Network == NetworkTCP {
+		return h.sendTCP(msg)
+	}
+	return h.sendUDP(msg)
+}
+
+// Close closes the connection to the server, if one is open.
+func (h *Handler) Close() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// connect returns the connection to the server, establishing it if
+// necessary. The caller must hold a lock on h.mutex.
+func (h *Handler) connect() (net.Conn, error) {
+	if h.conn != nil {
+		return h.conn, nil
+	}
+	conn, err := net.DialTimeout(h.config.Network, h.config.Address, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the GELF server at %s: %w", h.config.Address, err)
+	}
+	h.conn = conn
+	return conn, nil
+}
+
+// sendTCP sends msg over TCP, followed by a null byte. If the write fails
+// on an existing connection, it is retried once on a new connection. The
+// caller must hold a lock on h.mutex.
+func (h *Handler) sendTCP(msg []byte) error {
+	frame := append(msg, 0)
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var conn net.Conn
+		if conn, err = h.connect(); err != nil {
+			return err
+		}
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err = conn.Write(frame); err == nil {
+			return nil
+		}
+		conn.Close()
+		h.conn = nil
+	}
+	return fmt.Errorf("failed to send an event to the GELF server at %s: %w", h.config.Address, err)
+}
+
+// sendUDP compresses msg and sends it over UDP, splitting it into chunks
+// if necessary. The caller must hold a lock on h.mutex.
+func (h *Handler) sendUDP(msg []byte) error {
+	conn, err := h.connect()
+	if err != nil {
+		return err
+	}
+
+	datagrams, err := Chunk(compress(msg), h.config.ChunkSize)
+	if err != nil {
+		return err
+	}
+	for _, datagram := range datagrams {
+		if _, err := conn.Write(datagram); err != nil {
+			return fmt.Errorf("failed to send an event to the GELF server at %s: %w", h.config.Address, err)
+		}
+	}
+	return nil
+}
+
+// compress returns msg compressed with gzip.
+func compress(msg []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(msg)
+	w.Close()
+	return buf.Bytes()
+}
+
+// Chunk splits data into GELF chunks that are no larger than size. If data
+// fits within size, it is returned as a single datagram without a chunk
+// header.
+//
+// An error is returned if the data would require more than 128 chunks.
+func Chunk(data []byte, size int) ([][]byte, error) {
+	if len(data) <= size {
+		return [][]byte{data}, nil
+	}
+
+	payload := size - chunkHeaderSize
+	count := (len(data) + payload - 1) / payload
+	if count > maxChunks {
+		return nil, fmt.Errorf("the GELF message is %d bytes, which would require %d chunks (the limit is %d)", len(data), count, maxChunks)
+	}
+
+	var id [8]byte
+	rand.Read(id[:])
+
+	chunks := make([][]byte, 0, count)
+	for i := range count {
+		part := data[i*payload : min((i+1)*payload, len(data))]
+		chunk := make([]byte, 0, chunkHeaderSize+len(part))
+		chunk = append(chunk, chunkMagic[:]...)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, part...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
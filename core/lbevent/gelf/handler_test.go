@@ -0,0 +1,163 @@
+package gelf_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbevent/gelf"
+)
+
+type testEvent struct {
+	Text string
+	Err  error
+}
+
+func (e testEvent) Type() lbevent.Type { return "test:event" }
+func (e testEvent) Level() slog.Level  { return slog.LevelError }
+func (e testEvent) Message() string    { return e.Text }
+func (e testEvent) Details() string    { return "details" }
+func (e testEvent) Attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("error-category", "installer"),
+		slog.Group("actions", "completed", 2),
+	}
+}
+
+func TestHandlerUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	handler, err := gelf.NewHandler(gelf.Config{Address: conn.LocalAddr().String(), Host: "workstation", ChunkSize: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	// Use a message that doesn't compress well, so that it is chunked.
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog ", 4) + time.Now().String()
+	recorder := lbevent.Recorder{Handler: handler, Origin: lbevent.Origin{RunID: "run"}}
+	if err := recorder.Record(testEvent{Text: text, Err: errors.New("failure")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Collect the chunks.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var chunks [][]byte
+	for count := -1; count != len(chunks); {
+		buf := make([]byte, 2048)
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > 64 {
+			t.Fatalf("received a datagram of %d bytes, which exceeds the chunk size", n)
+		}
+		if buf[0] != 0x1e || buf[1] != 0x0f {
+			t.Fatal("received a datagram without a chunk header")
+		}
+		if count < 0 {
+			count = int(buf[11])
+			chunks = make([][]byte, 0, count)
+		}
+		chunks = append(chunks, buf[:n])
+	}
+
+	// Reassemble the message.
+	parts := make([][]byte, len(chunks))
+	for _, chunk := range chunks {
+		parts[chunk[10]] = chunk[12:]
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(bytes.Join(parts, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkMessage(t, data, text)
+}
+
+func TestHandlerTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+		frame, _ := bufio.NewReader(conn).ReadBytes(0)
+		received <- frame
+	}()
+
+	handler, err := gelf.NewHandler(gelf.Config{Address: listener.Addr().String(), Network: gelf.NetworkTCP, Host: "workstation"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+
+	recorder := lbevent.Recorder{Handler: handler, Origin: lbevent.Origin{RunID: "run"}}
+	if err := recorder.Record(testEvent{Text: "installation failed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case frame := <-received:
+		if len(frame) == 0 || frame[len(frame)-1] != 0 {
+			t.Fatal("the message was not terminated by a null byte")
+		}
+		checkMessage(t, frame[:len(frame)-1], "installation failed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the message")
+	}
+}
+
+func checkMessage(t *testing.T, data []byte, text string) {
+	t.Helper()
+
+	var msg map[string]any
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"version":            "1.1",
+		"host":               "workstation",
+		"short_message":      text,
+		"full_message":       "details",
+		"level":              float64(3),
+		"_event_type":        "test:event",
+		"_run_id":            "run",
+		"_error_category":    "installer",
+		"_actions_completed": float64(2),
+	}
+	for key, want := range expected {
+		if got := msg[key]; got != want {
+			t.Errorf("%s: got %v, want %v", key, got, want)
+		}
+	}
+	if _, ok := msg["timestamp"].(float64); !ok {
+		t.Errorf("timestamp: got %v, want a number", msg["timestamp"])
+	}
+}
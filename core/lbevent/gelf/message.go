@@ -0,0 +1,109 @@
+package gelf
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// version is the version of the GELF specification that messages conform
+// to.
+const version = "1.1"
+
+// Syslog severity levels used by GELF.
+const (
+	levelError         = 3
+	levelWarning       = 4
+	levelInformational = 6
+	levelDebug         = 7
+)
+
+// Encode maps an event record to a GELF message and returns it as JSON.
+//
+// The event's message becomes the short message and its details become the
+// full message. The event type, origin and attributes are included as
+// additional fields. Attributes within groups are flattened, with their
+// keys joined by underscores.
+func Encode(r lbevent.Record, host string) ([]byte, error) {
+	origin := r.Origin()
+	if origin.Machine != "" {
+		host = origin.Machine
+	}
+
+	msg := map[string]any{
+		"version":       version,
+		"host":          host,
+		"short_message": r.Message(),
+		"timestamp":     float64(r.Time().UnixMicro()) / 1e6,
+		"level":         level(r.Level()),
+		"_event_type":   string(r.Type()),
+	}
+	if details := r.Details(); details != "" {
+		msg["full_message"] = details
+	}
+	if origin.RunID != "" {
+		msg["_run_id"] = string(origin.RunID)
+	}
+	addAttrs(msg, "host", origin.Host.Attrs())
+	addAttrs(msg, "", r.Attrs())
+
+	return json.Marshal(msg)
+}
+
+// level maps a structured logging level to a syslog severity level.
+func level(l slog.Level) int {
+	switch {
+	case l >= slog.LevelError:
+		return levelError
+	case l >= slog.LevelWarn:
+		return levelWarning
+	case l >= slog.LevelInfo:
+		return levelInformational
+	default:
+		return levelDebug
+	}
+}
+
+// addAttrs adds attrs to msg as additional fields. Each key is given
+// prefix, if one is provided.
+func addAttrs(msg map[string]any, prefix string, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		key := fieldName(attr.Key)
+		switch {
+		case prefix != "":
+			key = prefix + "_" + key
+		case key == "id":
+			key = "id_" // The "_id" field is reserved.
+		}
+		value := attr.Value.Resolve()
+		switch value.Kind() {
+		case slog.KindGroup:
+			addAttrs(msg, key, value.Group())
+			continue
+		case slog.KindDuration:
+			msg["_"+key] = value.Duration().String()
+		case slog.KindTime:
+			msg["_"+key] = value.Time().UTC().Format(time.RFC3339Nano)
+		case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+			msg["_"+key] = value.Any()
+		default:
+			msg["_"+key] = value.String()
+		}
+	}
+}
+
+// fieldName returns key as the name of a GELF field. Characters other than
+// letters, digits, underscores and periods are replaced by underscores.
+func fieldName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
@@ -0,0 +1,42 @@
+package lbevent
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSONHandler is a LeafBridge event handler that writes event records to an
+// io.Writer as JSON, one record per line. The records can be read back with
+// [Registry.UnmarshalRecord].
+type JSONHandler struct {
+	mutex *sync.Mutex
+	w     io.Writer
+}
+
+// NewJSONHandler returns a JSONHandler that will write to w.
+func NewJSONHandler(w io.Writer) JSONHandler {
+	return JSONHandler{
+		mutex: new(sync.Mutex),
+		w:     w,
+	}
+}
+
+// Name returns a name for the handler.
+func (h JSONHandler) Name() string {
+	return "json"
+}
+
+// Handle processes the given event record.
+func (h JSONHandler) Handle(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	_, err = h.w.Write(data)
+	return err
+}
@@ -0,0 +1,84 @@
+package lbevent
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+)
+
+// RunID is a unique identifier for an invocation of LeafBridge. It is
+// used to correlate the events produced by a single invocation.
+type RunID string
+
+// NewRunID returns a new random run ID in the form of a version 4 UUID.
+func NewRunID() RunID {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // Variant 10
+	return RunID(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]))
+}
+
+// Origin describes where and when an event was produced. It is attached to
+// each event record by a [Recorder].
+type Origin struct {
+	RunID   RunID  `json:"run-id,omitempty"`
+	Machine string `json:"machine,omitempty"`
+	Host    Host   `json:"host,omitzero"`
+}
+
+// Host holds optional information about the host and session in which
+// LeafBridge is running. Fields that are unknown are left empty.
+type Host struct {
+	Domain       string `json:"domain,omitempty"`
+	OSBuild      string `json:"os-build,omitempty"`
+	Architecture string `json:"architecture,omitempty"`
+	User         string `json:"user,omitempty"`
+	Session      string `json:"session,omitempty"`
+}
+
+// IsZero returns true if no host information is present.
+func (h Host) IsZero() bool {
+	return h == Host{}
+}
+
+// Attrs returns a set of structured logging attributes for the host.
+func (h Host) Attrs() []slog.Attr {
+	var attrs []slog.Attr
+	if h.Domain != "" {
+		attrs = append(attrs, slog.String("domain", h.Domain))
+	}
+	if h.OSBuild != "" {
+		attrs = append(attrs, slog.String("os-build", h.OSBuild))
+	}
+	if h.Architecture != "" {
+		attrs = append(attrs, slog.String("architecture", h.Architecture))
+	}
+	if h.User != "" {
+		attrs = append(attrs, slog.String("user", h.User))
+	}
+	if h.Session != "" {
+		attrs = append(attrs, slog.String("session", h.Session))
+	}
+	return attrs
+}
+
+// IsZero returns true if the origin is empty.
+func (o Origin) IsZero() bool {
+	return o == Origin{}
+}
+
+// Attrs returns a set of structured logging attributes for the origin.
+func (o Origin) Attrs() []slog.Attr {
+	var attrs []slog.Attr
+	if o.RunID != "" {
+		attrs = append(attrs, slog.String("run-id", string(o.RunID)))
+	}
+	if o.Machine != "" {
+		attrs = append(attrs, slog.String("machine", o.Machine))
+	}
+	if hostAttrs := o.Host.Attrs(); len(hostAttrs) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "host", Value: slog.GroupValue(hostAttrs...)})
+	}
+	return attrs
+}
@@ -15,15 +15,18 @@ type RecordUnmarshaler func([]byte) (Record, error)
 // implemented by all event records.
 type Record interface {
 	Time() time.Time
+	Origin() Origin
 	ToLog() slog.Record
+	Data() Interface
 	Interface
 }
 
 // RecordOf holds information about an event within LeafBridge of type T.
 type RecordOf[T Interface] struct {
-	time  time.Time
-	pc    uintptr
-	Event T
+	time   time.Time
+	pc     uintptr
+	origin Origin
+	Event  T
 }
 
 // NewRecord returns a record for the given event and program counter. It uses
@@ -33,7 +36,7 @@ type RecordOf[T Interface] struct {
 // records.
 func NewRecord[T Interface](at time.Time, pc uintptr, event T) RecordOf[T] {
 	return RecordOf[T]{
-		time:  time.Now(),
+		time:  at,
 		pc:    pc,
 		Event: event,
 	}
@@ -55,6 +58,22 @@ func (r RecordOf[T]) Time() time.Time {
 	return r.time
 }
 
+// Origin returns the origin of the event.
+func (r RecordOf[T]) Origin() Origin {
+	return r.origin
+}
+
+// WithOrigin returns a copy of the record with the given origin.
+func (r RecordOf[T]) WithOrigin(origin Origin) RecordOf[T] {
+	r.origin = origin
+	return r
+}
+
+// Data returns the event held by the record.
+func (r RecordOf[T]) Data() Interface {
+	return r.Event
+}
+
 // Type returns the type of the event.
 func (r RecordOf[T]) Type() Type {
 	return r.Event.Type()
@@ -82,21 +101,32 @@ func (r RecordOf[T]) Attrs() []slog.Attr {
 	return r.Event.Attrs()
 }
 
-// ToLog returns the event record as a structured logging record.
+// ToLog returns the event record as a structured logging record. The
+// attributes of the record's origin are included ahead of the event's own
+// attributes.
 func (r RecordOf[T]) ToLog() slog.Record {
 	out := slog.NewRecord(r.time, r.Event.Level(), r.Event.Message(), r.pc)
+	out.AddAttrs(r.origin.Attrs()...)
 	out.AddAttrs(r.Event.Attrs()...)
 	return out
 }
 
 // MarshalJSON marshals the record as JSON data.
 //
+// Errors held by the event are encoded as strings. When the record is
+// unmarshaled, they are restored as [RecordedError] values.
+//
 // TODO: Consider encoding data that can be gleaned from the program counter.
 func (r RecordOf[T]) MarshalJSON() ([]byte, error) {
-	return json.Marshal(recordOf[T]{
-		Time: r.time,
-		Type: r.Type(),
-		Data: r.Event,
+	data, err := marshalEvent(r.Event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event record of type \"%s\": %w", r.Type(), err)
+	}
+	return json.Marshal(recordJSON{
+		Time:   r.time,
+		Type:   r.Type(),
+		Origin: r.origin,
+		Data:   data,
 	})
 }
 
@@ -105,22 +135,30 @@ func (r RecordOf[T]) MarshalJSON() ([]byte, error) {
 // If the data is for an event record of a different type, or if the
 // unmarshaling fails, an error is returned.
 func (r *RecordOf[T]) UnmarshalJSON(b []byte) error {
-	var aux recordOf[T]
+	var aux recordJSON
 	if err := json.Unmarshal(b, &aux); err != nil {
 		return fmt.Errorf("failed to unmarshal event record of type \"%s\": %w", r.Type(), err)
 	}
 	if aux.Type != r.Type() {
 		return fmt.Errorf("attempted to unmarshal event record of type \"%s\" into a structure for type \"%s\"", aux.Type, r.Type())
 	}
+	var event T
+	if len(aux.Data) > 0 {
+		if err := unmarshalEvent(aux.Data, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal event record of type \"%s\": %w", r.Type(), err)
+		}
+	}
 	*r = RecordOf[T]{
-		time:  aux.Time,
-		Event: aux.Data,
+		time:   aux.Time,
+		origin: aux.Origin,
+		Event:  event,
 	}
 	return nil
 }
 
-type recordOf[T Interface] struct {
+type recordJSON struct {
 	Time time.Time `json:"time"`
 	Type Type      `json:"type"`
-	Data T         `json:"data"`
+	Origin
+	Data json.RawMessage `json:"data"`
 }
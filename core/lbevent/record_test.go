@@ -0,0 +1,74 @@
+package lbevent_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+type testEvent struct {
+	Name string
+	Err  error
+}
+
+func (e testEvent) Type() lbevent.Type { return "test:event" }
+func (e testEvent) Level() slog.Level  { return slog.LevelInfo }
+func (e testEvent) Message() string    { return e.Name }
+func (e testEvent) Details() string    { return "" }
+func (e testEvent) Attrs() []slog.Attr { return nil }
+
+func TestRecordRoundTrip(t *testing.T) {
+	registry := lbevent.NewRegistry(100)
+	if err := registry.Add(lbevent.Registration{Type: "test:event", Unmarshaler: lbevent.UnmarshalRecord[testEvent]}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	handler := lbevent.NewJSONHandler(&buf)
+	recorder := lbevent.Recorder{Handler: handler, Origin: lbevent.Origin{RunID: "run", Machine: "machine"}}
+	recorder.Record(testEvent{Name: "failed", Err: errors.New("access is denied")})
+	recorder.Record(testEvent{Name: "succeeded"})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+
+	fixtures := []struct {
+		Name string
+		Err  string
+	}{
+		{Name: "failed", Err: "access is denied"},
+		{Name: "succeeded"},
+	}
+
+	for i, fixture := range fixtures {
+		record, err := registry.UnmarshalRecord(lines[i])
+		if err != nil {
+			t.Fatalf("record %d: %v", i+1, err)
+		}
+		event, ok := record.Data().(testEvent)
+		if !ok {
+			t.Fatalf("record %d: unexpected event type %T", i+1, record.Data())
+		}
+		if event.Name != fixture.Name {
+			t.Errorf("record %d: got name \"%s\", want \"%s\"", i+1, event.Name, fixture.Name)
+		}
+		switch {
+		case fixture.Err == "" && event.Err != nil:
+			t.Errorf("record %d: got error \"%v\", want none", i+1, event.Err)
+		case fixture.Err != "" && (event.Err == nil || event.Err.Error() != fixture.Err):
+			t.Errorf("record %d: got error \"%v\", want \"%s\"", i+1, event.Err, fixture.Err)
+		}
+		if origin := record.Origin(); origin.RunID != "run" || origin.Machine != "machine" {
+			t.Errorf("record %d: got origin %+v", i+1, origin)
+		}
+		if record.Time().IsZero() || time.Since(record.Time()) > time.Minute {
+			t.Errorf("record %d: unexpected time %s", i+1, record.Time())
+		}
+	}
+}
@@ -8,9 +8,13 @@ import (
 // Recorder is a LeafBridge event recorder. It collects information about
 // events that happen within LeafBridge and passes them to an event handler.
 //
+// The recorder's origin is attached to each event record, so that events
+// from the same invocation of LeafBridge can be correlated.
+//
 // If the recorder's handler is nil, it silently discards all events.
 type Recorder struct {
 	Handler Handler
+	Origin  Origin
 }
 
 // Record records the given event and passes it to the recorder's handler.
@@ -34,7 +38,7 @@ func (rec Recorder) Record(event Interface) error {
 	}
 
 	// Prepare an event record.
-	record := NewRecord(at, pc, event)
+	record := NewRecord(at, pc, event).WithOrigin(rec.Origin)
 
 	// Send the event record to the event handler.
 	err := rec.Handler.Handle(record)
@@ -47,7 +51,7 @@ func (rec Recorder) Record(event Interface) error {
 	if err != nil {
 		if event, ok := err.(Interface); ok {
 			at := time.Now()
-			rec.Handler.Handle(NewRecord(at, pc, event))
+			rec.Handler.Handle(NewRecord(at, pc, event).WithOrigin(rec.Origin))
 		}
 	}
 
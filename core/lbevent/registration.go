@@ -2,7 +2,14 @@ package lbevent
 
 // Registration holds information about an event that can be added to an event
 // [Registry].
+//
+// If ID is non-zero, it declares the event ID for the event type. Declared
+// IDs remain stable when other event types are added or removed, so they
+// should be provided for any event type with an ID that is visible outside
+// of LeafBridge, such as in the Windows event log. If ID is zero, an ID is
+// assigned by the registry.
 type Registration struct {
 	Type        Type
+	ID          ID
 	Unmarshaler RecordUnmarshaler
 }
@@ -31,24 +31,59 @@ func NewRegistry(start ID) *Registry {
 
 // Add adds the given events to the event registry in the order provided.
 //
-// As new events are added, monotonically increasing event IDs are assigned
-// to them by the registry.
+// Events with a declared ID are assigned that ID. Other events are assigned
+// monotonically increasing event IDs by the registry, skipping any IDs that
+// are already in use.
 //
 // If an existing registration exists for an event, the registration is
 // updated but the previously assigned event ID is preserved.
-func (r *Registry) Add(events ...Registration) {
+//
+// If a declared ID is already assigned to a different event type, or if an
+// event type is re-registered with a different declared ID, an error is
+// returned and none of the events are added.
+func (r *Registry) Add(events ...Registration) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	// Check for collisions before making any changes.
+	owners := make(map[ID]Type, len(r.ids)+len(events))
+	for t, id := range r.ids {
+		owners[id] = t
+	}
+	for _, event := range events {
+		if event.ID == 0 {
+			continue
+		}
+		if id, exists := r.ids[event.Type]; exists && id != event.ID {
+			return fmt.Errorf("the \"%s\" event type was previously assigned event ID %d and cannot be assigned event ID %d", event.Type, id, event.ID)
+		}
+		if owner, exists := owners[event.ID]; exists && owner != event.Type {
+			return fmt.Errorf("event ID %d cannot be assigned to the \"%s\" event type because it is already assigned to the \"%s\" event type", event.ID, event.Type, owner)
+		}
+		owners[event.ID] = event.Type
+	}
+
 	for _, event := range events {
 		if _, exists := r.ids[event.Type]; !exists {
-			id := r.next
-			r.next++
+			id := event.ID
+			if id == 0 {
+				for {
+					if _, taken := owners[r.next]; !taken {
+						break
+					}
+					r.next++
+				}
+				id = r.next
+				r.next++
+				owners[id] = event.Type
+			}
 			r.ids[event.Type] = id
 			r.types = append(r.types, event.Type)
 		}
 		r.unmarshalers[event.Type] = event.Unmarshaler
 	}
+
+	return nil
 }
 
 // EventID returns the registered event [ID] for the given event [Type].
@@ -0,0 +1,42 @@
+package lbevent_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+func TestRegistryDeclaredIDs(t *testing.T) {
+	registry := lbevent.NewRegistry(100)
+	err := registry.Add(
+		lbevent.Registration{Type: "a", ID: 101},
+		lbevent.Registration{Type: "b"},
+		lbevent.Registration{Type: "c"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[lbevent.Type]lbevent.ID{"a": 101, "b": 100, "c": 102}
+	for eventType, want := range expected {
+		if got, _ := registry.EventID(eventType); got != want {
+			t.Errorf("event type \"%s\": got ID %d, want %d", eventType, got, want)
+		}
+	}
+
+	// Re-registering with the same ID is permitted.
+	if err := registry.Add(lbevent.Registration{Type: "a", ID: 101}); err != nil {
+		t.Errorf("unexpected error when re-registering an event type: %v", err)
+	}
+
+	// Collisions must be detected.
+	if err := registry.Add(lbevent.Registration{Type: "d", ID: 102}); err == nil {
+		t.Error("expected an error when declaring an ID that is already assigned")
+	}
+	if err := registry.Add(lbevent.Registration{Type: "a", ID: 105}); err == nil {
+		t.Error("expected an error when changing the declared ID of an event type")
+	}
+	if _, ok := registry.EventID("d"); ok {
+		t.Error("an event type was registered despite a collision")
+	}
+}
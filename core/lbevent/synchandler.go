@@ -0,0 +1,31 @@
+package lbevent
+
+import "sync"
+
+// SyncHandler is a LeafBridge event handler that serializes calls to
+// another handler, so that handlers that aren't safe for concurrent use
+// can be shared by deployments that run concurrently.
+type SyncHandler struct {
+	mutex   *sync.Mutex
+	handler Handler
+}
+
+// NewSyncHandler returns a SyncHandler that will forward records to h.
+func NewSyncHandler(h Handler) SyncHandler {
+	return SyncHandler{
+		mutex:   new(sync.Mutex),
+		handler: h,
+	}
+}
+
+// Name returns a name for the handler.
+func (h SyncHandler) Name() string {
+	return h.handler.Name()
+}
+
+// Handle processes the given event record.
+func (h SyncHandler) Handle(r Record) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.handler.Handle(r)
+}
@@ -0,0 +1,137 @@
+// Package lbexpand expands placeholders within LeafBridge templates.
+//
+// A placeholder takes the form {kind:name}, where kind is one of the
+// recognized placeholder kinds and name identifies a resource or variable.
+// Text enclosed in braces that does not match this form is left as-is,
+// which allows templates to contain script blocks and other literal braces.
+package lbexpand
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Kind identifies a kind of placeholder.
+type Kind string
+
+// Recognized placeholder kinds.
+const (
+	KindFile Kind = "file" // A file resource
+	KindDir  Kind = "dir"  // A directory resource
+	KindVar  Kind = "var"  // A deployment variable
+)
+
+// IsRecognized returns true if k is a recognized placeholder kind.
+func (k Kind) IsRecognized() bool {
+	switch k {
+	case KindFile, KindDir, KindVar:
+		return true
+	default:
+		return false
+	}
+}
+
+// Placeholder identifies a value to be substituted into a template.
+type Placeholder struct {
+	Kind Kind
+	Name string
+}
+
+// String returns the placeholder in its template form.
+func (p Placeholder) String() string {
+	return "{" + string(p.Kind) + ":" + p.Name + "}"
+}
+
+// ResolverFunc is a function that resolves a placeholder to its value.
+type ResolverFunc func(Placeholder) (string, error)
+
+// Expand replaces each placeholder in s with the value returned by resolve.
+//
+// If resolve returns an error, expansion stops and the error is returned.
+func Expand(s string, resolve ResolverFunc) (string, error) {
+	if !strings.Contains(s, "{") {
+		return s, nil
+	}
+
+	var out strings.Builder
+	for len(s) > 0 {
+		start, end, p, found := next(s)
+		if !found {
+			out.WriteString(s)
+			break
+		}
+		value, err := resolve(p)
+		if err != nil {
+			return "", fmt.Errorf("the %s placeholder could not be expanded: %w", p, err)
+		}
+		out.WriteString(s[:start])
+		out.WriteString(value)
+		s = s[end:]
+	}
+
+	return out.String(), nil
+}
+
+// Placeholders returns an iterator over the placeholders in s.
+func Placeholders(s string) iter.Seq[Placeholder] {
+	return func(yield func(Placeholder) bool) {
+		for len(s) > 0 {
+			_, end, p, found := next(s)
+			if !found || !yield(p) {
+				return
+			}
+			s = s[end:]
+		}
+	}
+}
+
+// next returns the position of the next placeholder in s. If a placeholder
+// is not found, found will be false.
+func next(s string) (start, end int, p Placeholder, found bool) {
+	offset := 0
+	for {
+		open := strings.IndexByte(s[offset:], '{')
+		if open < 0 {
+			return 0, 0, Placeholder{}, false
+		}
+		open += offset
+
+		close := strings.IndexByte(s[open:], '}')
+		if close < 0 {
+			return 0, 0, Placeholder{}, false
+		}
+		close += open
+
+		if p, ok := parse(s[open+1 : close]); ok {
+			return open, close + 1, p, true
+		}
+
+		offset = open + 1
+	}
+}
+
+// parse attempts to interpret s as the inner text of a placeholder.
+func parse(s string) (p Placeholder, ok bool) {
+	kind, name, found := strings.Cut(s, ":")
+	if !found || !Kind(kind).IsRecognized() || !validName(name) {
+		return Placeholder{}, false
+	}
+	return Placeholder{Kind: Kind(kind), Name: name}, true
+}
+
+// validName returns true if name is a valid resource or variable name.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
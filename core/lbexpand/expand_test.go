@@ -0,0 +1,58 @@
+package lbexpand_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbexpand"
+)
+
+type expandInOut struct {
+	In, Out string
+}
+
+var expandFixtures = []expandInOut{
+	{In: "", Out: ""},
+	{In: "plain", Out: "plain"},
+	{In: "{dir:install-root}", Out: `C:\Program Files\Example`},
+	{In: "--config={file:config}", Out: `--config=C:\Data\config.json`},
+	{In: "{var:channel}/{var:channel}", Out: "beta/beta"},
+	{In: "{ $_.Name }", Out: "{ $_.Name }"},
+	{In: `{"key":"value"}`, Out: `{"key":"value"}`},
+	{In: "{unknown:thing} {var:channel}", Out: "{unknown:thing} beta"},
+	{In: "{{var:channel}}", Out: "{beta}"},
+	{In: "{var:unterminated", Out: "{var:unterminated"},
+}
+
+func resolve(p lbexpand.Placeholder) (string, error) {
+	switch p {
+	case lbexpand.Placeholder{Kind: lbexpand.KindDir, Name: "install-root"}:
+		return `C:\Program Files\Example`, nil
+	case lbexpand.Placeholder{Kind: lbexpand.KindFile, Name: "config"}:
+		return `C:\Data\config.json`, nil
+	case lbexpand.Placeholder{Kind: lbexpand.KindVar, Name: "channel"}:
+		return "beta", nil
+	}
+	return "", errors.New("not defined")
+}
+
+func TestExpand(t *testing.T) {
+	for i, fixture := range expandFixtures {
+		t.Run(fmt.Sprintf("%d:%s", i, fixture.In), func(t *testing.T) {
+			out, err := lbexpand.Expand(fixture.In, resolve)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != fixture.Out {
+				t.Fatalf("unexpected expansion: got %s, want %s", out, fixture.Out)
+			}
+		})
+	}
+}
+
+func TestExpandError(t *testing.T) {
+	if _, err := lbexpand.Expand("{var:missing}", resolve); err == nil {
+		t.Fatal("expected an error for an undefined variable")
+	}
+}
@@ -0,0 +1,125 @@
+// Package lblogon keeps track of deployment flows that LeafBridge runs in
+// the context of each user when they log on.
+//
+// A flow is registered by a deployment that runs in the context of the
+// machine. The registration is kept alongside a copy of the deployment, so
+// that the flow can be run at logon without access to the original
+// deployment file. Whether each user has already run the flow for the
+// current registration is tracked in the user's own state.
+//
+// Where the registrations are kept, and how LeafBridge is started at logon,
+// is determined by each platform.
+package lblogon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Registration records that a flow has been registered to run at logon.
+//
+// Registered is the time that the flow was last registered. A user runs
+// the flow once for each registration.
+type Registration struct {
+	Flow       lbdeploy.FlowID `json:"flow"`
+	Registered time.Time       `json:"registered"`
+}
+
+// Entry holds the logon registrations of a deployment, together with a
+// copy of the deployment that made them.
+type Entry struct {
+	Deployment    lbdeploy.Deployment `json:"deployment"`
+	Registrations []Registration      `json:"registrations"`
+}
+
+// Dir is a directory that holds logon registrations. The registrations of
+// each deployment are kept in their own JSON file.
+type Dir string
+
+// Register registers the given flow of the deployment to run at the next
+// logon of each user. The copy of the deployment is updated, and the time
+// of any existing registration of the flow is replaced.
+func (dir Dir) Register(dep lbdeploy.Deployment, flow lbdeploy.FlowID, now time.Time) (Registration, error) {
+	path := dir.path(dep.ID)
+	entry, err := readEntry(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Registration{}, err
+	}
+
+	reg := Registration{Flow: flow, Registered: now.UTC()}
+	entry.Deployment = dep
+	entry.Registrations = slices.DeleteFunc(entry.Registrations, func(existing Registration) bool {
+		return existing.Flow == flow
+	})
+	entry.Registrations = append(entry.Registrations, reg)
+
+	data, err := json.MarshalIndent(entry, "", "\t")
+	if err != nil {
+		return Registration{}, err
+	}
+	if err := os.MkdirAll(string(dir), 0755); err != nil {
+		return Registration{}, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return Registration{}, err
+	}
+
+	return reg, nil
+}
+
+// Entries returns the logon registrations of all deployments, sorted by
+// deployment ID. If the directory does not exist, it returns nil.
+func (dir Dir) Entries() ([]Entry, error) {
+	files, err := os.ReadDir(string(dir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entry, err := readEntry(filepath.Join(string(dir), file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(string(a.Deployment.ID), string(b.Deployment.ID))
+	})
+
+	return entries, nil
+}
+
+// path returns the path of the file that holds the registrations of the
+// given deployment.
+func (dir Dir) path(id lbdeploy.DeploymentID) string {
+	return filepath.Join(string(dir), string(id)+".json")
+}
+
+// readEntry reads the logon registrations in the file at path.
+func readEntry(path string) (Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("failed to parse the logon registrations in \"%s\": %w", path, err)
+	}
+	return entry, nil
+}
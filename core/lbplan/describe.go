@@ -0,0 +1,273 @@
+package lbplan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// describe records the operations that an action would perform in step.
+// It updates the outcome of the step if the action would be skipped.
+func (p *planner) describe(action lbdeploy.Action, step *Step) error {
+	op := func(format string, args ...any) {
+		step.Operations = append(step.Operations, fmt.Sprintf(format, args...))
+	}
+
+	switch action.Type {
+	case lbdeploy.ActionStartFlow:
+		op("starts the \"%s\" flow", action.Flow)
+	case lbdeploy.ActionPreparePackage:
+		return p.describePackage(action, step)
+	case lbdeploy.ActionInvokeCommand:
+		return p.describeCommand(action, step)
+	case lbdeploy.ActionCopyFile:
+		if action.Replace {
+			op("copies %s to %s, replacing it if it exists", p.file(action.SourceFile), p.file(action.DestinationFile))
+		} else {
+			op("copies %s to %s, unless it exists", p.file(action.SourceFile), p.file(action.DestinationFile))
+		}
+		if action.OnLocked == lbdeploy.FileLockedReplaceOnReboot {
+			op("replaces the destination file when the system restarts if it is locked")
+		}
+	case lbdeploy.ActionDeleteFile:
+		op("deletes %s", p.file(action.DestinationFile))
+		if action.OnLocked == lbdeploy.FileLockedReplaceOnReboot {
+			op("deletes the file when the system restarts if it is locked")
+		}
+	case lbdeploy.ActionBackupFile:
+		op("backs up %s", p.file(action.DestinationFile))
+	case lbdeploy.ActionRestoreFile:
+		op("restores %s from its most recent backup", p.file(action.DestinationFile))
+	case lbdeploy.ActionPowerShellScript, lbdeploy.ActionCmdScript, lbdeploy.ActionShellScript:
+		p.describeScript(action, step)
+	case lbdeploy.ActionWaitForRegistry:
+		result, err := p.conditions.Evaluate(action.Condition)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate the \"%s\" condition: %w", action.Condition, err)
+		}
+		if result {
+			op("waits for the \"%s\" condition, which is already satisfied", action.Condition)
+		} else {
+			op("waits for the \"%s\" condition, which is not yet satisfied", action.Condition)
+		}
+	case lbdeploy.ActionEditINIFile, lbdeploy.ActionEditXMLFile, lbdeploy.ActionEditJSONFile:
+		op("applies %d %s to %s", len(action.Edits), plural(len(action.Edits), "edit", "edits"), p.file(action.DestinationFile))
+	case lbdeploy.ActionEditHostsFile:
+		for _, entry := range action.HostsEntries {
+			if entry.Remove {
+				op("removes the hosts entry for %s", entry.Hostname)
+			} else {
+				op("maps %s to %s in the hosts file", entry.Hostname, entry.Address)
+			}
+		}
+	case lbdeploy.ActionSuspendBitLocker:
+		op("suspends BitLocker protection of the system drive for %d %s", action.RebootCount, plural(action.RebootCount, "reboot", "reboots"))
+	case lbdeploy.ActionResumeBitLocker:
+		op("resumes BitLocker protection of the system drive")
+	case lbdeploy.ActionSetTimeZone:
+		op("sets the time zone to \"%s\"", action.TimeZone)
+	case lbdeploy.ActionRegisterLogonFlow:
+		op("registers the \"%s\" flow to run when each user logs on", action.Flow)
+	case lbdeploy.ActionConfigureBrowserExtensions:
+		for _, ext := range action.Extensions {
+			if ext.Remove {
+				op("removes the %s extension %s", ext.Browser, ext.ID)
+			} else {
+				op("force-installs the %s extension %s", ext.Browser, ext.ID)
+			}
+		}
+	case lbdeploy.ActionSetBrowserPolicies:
+		for _, policy := range action.BrowserPolicies {
+			op("sets the %s policy %s to %s", policy.Browser, policy.Name, policy.Value)
+		}
+	case lbdeploy.ActionSetDefaultAssociations:
+		for _, assoc := range action.Associations {
+			if assoc.Remove {
+				op("removes the default association for %s", assoc.Identifier)
+			} else {
+				op("associates %s with %s", assoc.Identifier, assoc.ProgID)
+			}
+		}
+		if action.Enforce {
+			op("enforces the associations at each logon")
+		}
+	case lbdeploy.ActionSetStartLayout:
+		op("deploys the Start layout in %s", p.file(action.SourceFile))
+		if action.Enforce {
+			op("enforces the layout for every user")
+		}
+	case lbdeploy.ActionClearStartLayout:
+		op("removes the deployed Start layout")
+	case lbdeploy.ActionConfigureLocalUsers:
+		for _, user := range action.LocalUsers {
+			if user.Remove {
+				op("removes the local user %s", user.Name)
+			} else {
+				op("creates or updates the local user %s", user.Name)
+			}
+		}
+	case lbdeploy.ActionConfigureLocalGroups:
+		for _, membership := range action.GroupMembers {
+			if membership.Remove {
+				op("removes %s from the local group %s", membership.Member, membership.Group)
+			} else {
+				op("adds %s to the local group %s", membership.Member, membership.Group)
+			}
+		}
+	case lbdeploy.ActionConfigureUserRights:
+		for _, assignment := range action.UserRights {
+			if assignment.Remove {
+				op("revokes %s from %s", assignment.Right, assignment.Account)
+			} else {
+				op("grants %s to %s", assignment.Right, assignment.Account)
+			}
+		}
+	case lbdeploy.ActionSetPowerPlan:
+		if action.SourceFile != "" {
+			op("imports the power scheme in %s", p.file(action.SourceFile))
+		}
+		op("activates the %s power plan", action.PowerPlan)
+	case lbdeploy.ActionConfigurePowerSettings:
+		op("applies %d power %s to the active plan", len(action.PowerSettings), plural(len(action.PowerSettings), "setting", "settings"))
+	case lbdeploy.ActionActivateLicense:
+		if !action.Activation.ProductKey.IsZero() {
+			op("installs a product key for %s", action.Activation.Product)
+		}
+		op("activates the license of %s", action.Activation.Product)
+	default:
+		op("runs the %s action", action.Type)
+	}
+
+	if action.OnProtected.Allowed() {
+		op("takes ownership of the file if it is protected by the operating system")
+	}
+
+	return nil
+}
+
+// describePackage records the operations of a prepare-package action.
+func (p *planner) describePackage(action lbdeploy.Action, step *Step) error {
+	pkg, found := p.deployment.Resources.Packages[action.Package]
+	if !found {
+		return fmt.Errorf("the \"%s\" package does not exist within the \"%s\" deployment", action.Package, p.deployment.ID)
+	}
+
+	step.Reason = "package " + string(action.Package)
+	switch len(pkg.Sources) {
+	case 0:
+		step.Operations = append(step.Operations, fmt.Sprintf("stages \"%s\" from a bundle or cache", pkg.FileName()))
+	case 1:
+		step.Operations = append(step.Operations, fmt.Sprintf("downloads \"%s\" from %s, unless it is already staged", pkg.FileName(), pkg.Sources[0].Redacted().URL))
+	default:
+		step.Operations = append(step.Operations, fmt.Sprintf("downloads \"%s\" from %s or one of %d other sources, unless it is already staged", pkg.FileName(), pkg.Sources[0].Redacted().URL, len(pkg.Sources)-1))
+	}
+	step.Operations = append(step.Operations, "verifies the package")
+	if pkg.Type.IsArchive() {
+		step.Operations = append(step.Operations, fmt.Sprintf("extracts \"%s\"", pkg.FileName()))
+	}
+	return nil
+}
+
+// describeCommand records the operations of an invoke-command action.
+// Commands whose application changes are already in effect are skipped
+// unless they are forced.
+func (p *planner) describeCommand(action lbdeploy.Action, step *Step) error {
+	var (
+		command lbdeploy.Command
+		found   bool
+	)
+	if action.Package != "" {
+		pkg, ok := p.deployment.Resources.Packages[action.Package]
+		if !ok {
+			return fmt.Errorf("the \"%s\" package does not exist within the \"%s\" deployment", action.Package, p.deployment.ID)
+		}
+		command, found = pkg.Commands[action.Command]
+	} else {
+		command, found = p.deployment.Commands[action.Command]
+	}
+	if !found {
+		return fmt.Errorf("the \"%s\" command does not exist within the \"%s\" deployment", action.Command, p.deployment.ID)
+	}
+
+	evaluation, err := p.evaluateAppChanges(command.Installs, command.Uninstalls)
+	if err != nil {
+		return fmt.Errorf("the evaluation of potential application changes did not succeed: %w", err)
+	}
+
+	step.Reason = "command " + string(action.Command)
+	hasApps := len(command.Installs) > 0 || len(command.Uninstalls) > 0
+	if hasApps && !evaluation.ActionsNeeded() && !(action.Force || p.opts.Force) {
+		step.Outcome = OutcomeSkipped
+		step.Reason += ": applications already in their desired state"
+		return nil
+	}
+
+	// Describe the command line.
+	line := string(command.Executable)
+	if action.Package != "" {
+		line = fmt.Sprintf("%s from the \"%s\" package", line, action.Package)
+	} else if command.Executable != "" {
+		line = p.file(lbdeploy.FileResourceID(command.Executable))
+	}
+	if command.Type.IsMSI() {
+		line = "msiexec for " + line
+	}
+	if len(command.Args) > 0 {
+		line += " with arguments " + strings.Join(command.Args, " ")
+	}
+	step.Operations = append(step.Operations, "runs "+line)
+	if len(evaluation.ToInstall) > 0 {
+		step.Operations = append(step.Operations, "installs "+evaluation.ToInstall.String())
+	}
+	if len(evaluation.ToUninstall) > 0 {
+		step.Operations = append(step.Operations, "uninstalls "+evaluation.ToUninstall.String())
+	}
+
+	// Assume that the command succeeds.
+	for _, app := range command.Installs {
+		p.changes[app] = true
+	}
+	for _, app := range command.Uninstalls {
+		p.changes[app] = false
+	}
+
+	return nil
+}
+
+// describeScript records the operations of a script action.
+func (p *planner) describeScript(action lbdeploy.Action, step *Step) {
+	var kind string
+	switch action.Type {
+	case lbdeploy.ActionPowerShellScript:
+		kind = "PowerShell"
+	case lbdeploy.ActionCmdScript:
+		kind = "cmd"
+	default:
+		kind = "shell"
+	}
+
+	script := action.Script
+	var line string
+	if script.File != "" {
+		line = fmt.Sprintf("runs the %s script in %s", kind, p.file(script.File))
+	} else {
+		lines := strings.Count(strings.TrimSpace(script.Inline), "\n") + 1
+		line = fmt.Sprintf("runs an inline %s script of %d %s", kind, lines, plural(lines, "line", "lines"))
+	}
+	if len(script.Args) > 0 {
+		line += " with arguments " + strings.Join(script.Args, " ")
+	}
+	if script.WorkingDirectory != "" {
+		line += " in " + p.dir(script.WorkingDirectory)
+	}
+	step.Operations = append(step.Operations, line)
+}
+
+// plural returns singular if value is 1, and plural otherwise.
+func plural(value int, singular, plural string) string {
+	if value == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,268 @@
+// Package lbplan describes what a flow within a LeafBridge deployment would
+// do on a system, without changing the system.
+//
+// Flow constraints, preconditions and action conditions are evaluated
+// against the current state of the system. Commands that install or
+// uninstall applications are assumed to succeed, so later actions see the
+// applications in their desired state. Other effects of earlier actions
+// are not anticipated.
+package lbplan
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/idset"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbeval"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+)
+
+// Outcome describes what would happen to a flow or action.
+type Outcome string
+
+// Planned outcomes.
+const (
+	OutcomeRun     Outcome = "run"
+	OutcomeSkipped Outcome = "skipped"
+)
+
+// Step describes what would happen to a flow or one of its actions.
+//
+// Steps with a negative index describe the flow itself. They are only
+// included when the flow would be skipped or refused.
+//
+// Operations describe the work that an action would perform, such as the
+// files it would download and the commands it would run.
+type Step struct {
+	Flow       lbdeploy.FlowID
+	Index      int
+	Type       lbdeploy.ActionType
+	Outcome    Outcome
+	Reason     string
+	Operations []string
+}
+
+// ID returns an identifier for the step, in the form flow/n, where n is the
+// one-based index of the action. Steps that describe a flow are identified
+// by the flow ID alone.
+func (step Step) ID() string {
+	if step.Index < 0 {
+		return string(step.Flow)
+	}
+	return string(step.Flow) + "/" + strconv.Itoa(step.Index+1)
+}
+
+// String returns a description of the step, followed by its operations on
+// separate lines.
+func (step Step) String() string {
+	var b strings.Builder
+	b.WriteString(step.ID())
+	b.WriteString(": ")
+	if step.Type != "" {
+		b.WriteString(string(step.Type) + " ")
+	}
+	b.WriteString(string(step.Outcome))
+	if step.Reason != "" {
+		b.WriteString(" (" + step.Reason + ")")
+	}
+	for _, op := range step.Operations {
+		b.WriteString("\n    " + op)
+	}
+	return b.String()
+}
+
+// Options adjust how a plan is made.
+//
+// If Force is true, commands are planned to run even when the applications
+// they install or uninstall are already in their desired state.
+//
+// ResolveFile and ResolveDir return the local paths of file and directory
+// resources. When they are nil, or when they fail, resources are described
+// by their IDs.
+type Options struct {
+	Force       bool
+	ResolveFile func(lbdeploy.FileResourceID) (string, error)
+	ResolveDir  func(lbdeploy.DirectoryResourceID) (string, error)
+}
+
+// Make walks a flow within the deployment and returns the steps that
+// describe what it would do on the given platform, in order. Flows started
+// by the flow are walked as well.
+//
+// An error is returned if the flow could not be planned, or if it would
+// fail, such as when its preconditions are not met. The steps leading up
+// to the failure are returned with it.
+func Make(ctx context.Context, dep lbdeploy.Deployment, flow lbdeploy.FlowID, platform lbplatform.Platform, opts Options) ([]Step, error) {
+	if err := dep.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := planner{
+		deployment: dep,
+		conditions: lbeval.NewConditionEngine(dep, platform),
+		apps:       lbeval.NewAppEngine(dep, platform),
+		changes:    make(map[lbdeploy.AppID]bool),
+		active:     make(idset.SetOf[lbdeploy.FlowID]),
+		opts:       opts,
+	}
+	err := p.planFlow(ctx, flow)
+	return p.steps, err
+}
+
+// planner holds the state of a plan that is being made.
+type planner struct {
+	deployment lbdeploy.Deployment
+	conditions lbeval.ConditionEngine
+	apps       lbeval.AppEngine
+	changes    map[lbdeploy.AppID]bool // Planned app changes, true if installed
+	active     idset.SetOf[lbdeploy.FlowID]
+	opts       Options
+	steps      []Step
+}
+
+func (p *planner) planFlow(ctx context.Context, id lbdeploy.FlowID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	flow, found := p.deployment.Flows[id]
+	if !found {
+		return fmt.Errorf("the \"%s\" flow does not exist within the \"%s\" deployment", id, p.deployment.ID)
+	}
+
+	if p.active.Contains(id) {
+		return fmt.Errorf("the \"%s\" flow is already running", id)
+	}
+	p.active.Add(id)
+	defer p.active.Remove(id)
+
+	// If any constraints fail, the flow is skipped.
+	failed, err := p.evaluateAll(flow.Constraints)
+	if err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to evaluate its constraints: %w", id, err)
+	}
+	if len(failed) > 0 {
+		p.steps = append(p.steps, Step{
+			Flow:    id,
+			Index:   -1,
+			Outcome: OutcomeSkipped,
+			Reason:  fmt.Sprintf("constraints not met: %s", failed),
+		})
+		return nil
+	}
+
+	// If any preconditions fail, the flow fails.
+	failed, err = p.evaluateAll(flow.Preconditions)
+	if err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to evaluate its preconditions: %w", id, err)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("the \"%s\" flow is unable to run because one or more preconditions failed: %s", id, failed)
+	}
+
+	for i, action := range flow.Actions {
+		step := Step{Flow: id, Index: i, Type: action.Type, Outcome: OutcomeRun}
+
+		// Evaluate the action's conditions.
+		failed, err := p.evaluateAll(action.Conditions)
+		if err != nil {
+			return fmt.Errorf("the \"%s\" flow: action %d failed to evaluate its conditions: %w", id, i+1, err)
+		}
+		if len(failed) > 0 {
+			step.Outcome = OutcomeSkipped
+			step.Reason = fmt.Sprintf("conditions not met: %s", failed)
+			p.steps = append(p.steps, step)
+			continue
+		}
+
+		// Describe what the action would do.
+		if err := p.describe(action, &step); err != nil {
+			return fmt.Errorf("the \"%s\" flow: action %d: %w", id, i+1, err)
+		}
+		p.steps = append(p.steps, step)
+
+		// Walk the flows that the action starts.
+		if action.Type == lbdeploy.ActionStartFlow && step.Outcome == OutcomeRun {
+			if err := p.planFlow(ctx, action.Flow); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// evaluateAll evaluates each of the conditions and returns the ones that
+// failed.
+func (p *planner) evaluateAll(conditions lbdeploy.ConditionList) (failed lbdeploy.ConditionList, err error) {
+	for _, condition := range conditions {
+		result, err := p.conditions.Evaluate(condition)
+		if err != nil {
+			return nil, err
+		}
+		if !result {
+			failed = append(failed, condition)
+		}
+	}
+	return failed, nil
+}
+
+// isInstalled reports whether an app would be installed at this point in
+// the plan.
+func (p *planner) isInstalled(app lbdeploy.AppID) (bool, error) {
+	if installed, planned := p.changes[app]; planned {
+		return installed, nil
+	}
+	return p.apps.IsInstalled(app)
+}
+
+// evaluateAppChanges evaluates the changes needed to effect the given set
+// of application installs and uninstalls at this point in the plan.
+func (p *planner) evaluateAppChanges(installs, uninstalls lbdeploy.AppList) (changes lbdeploy.AppEvaluation, err error) {
+	for _, app := range installs {
+		installed, err := p.isInstalled(app)
+		if err != nil {
+			return changes, err
+		}
+		if installed {
+			changes.AlreadyInstalled = append(changes.AlreadyInstalled, app)
+		} else {
+			changes.ToInstall = append(changes.ToInstall, app)
+		}
+	}
+	for _, app := range uninstalls {
+		installed, err := p.isInstalled(app)
+		if err != nil {
+			return changes, err
+		}
+		if installed {
+			changes.ToUninstall = append(changes.ToUninstall, app)
+		} else {
+			changes.AlreadyUninstalled = append(changes.AlreadyUninstalled, app)
+		}
+	}
+	return changes, nil
+}
+
+// file returns a description of a file resource.
+func (p *planner) file(id lbdeploy.FileResourceID) string {
+	if p.opts.ResolveFile != nil {
+		if path, err := p.opts.ResolveFile(id); err == nil {
+			return fmt.Sprintf("\"%s\"", path)
+		}
+	}
+	return fmt.Sprintf("file resource \"%s\"", id)
+}
+
+// dir returns a description of a directory resource.
+func (p *planner) dir(id lbdeploy.DirectoryResourceID) string {
+	if p.opts.ResolveDir != nil {
+		if path, err := p.opts.ResolveDir(id); err == nil {
+			return fmt.Sprintf("\"%s\"", path)
+		}
+	}
+	return fmt.Sprintf("directory resource \"%s\"", id)
+}
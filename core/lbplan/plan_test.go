@@ -0,0 +1,116 @@
+package lbplan_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplan"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+)
+
+var testDeployment = lbdeploy.Deployment{
+	ID: "test",
+	Apps: lbdeploy.AppMap{
+		"editor": {Name: "Editor", ProductCode: "{6F1C9E8A-3B2D-4C5E-9F70-1A2B3C4D5E6F}"},
+	},
+	Conditions: lbdeploy.ConditionMap{
+		"has-config": {Type: lbdeploy.ConditionTypeFileExists, Subject: "config"},
+	},
+	Resources: lbdeploy.Resources{
+		FileSystem: lbdeploy.FileSystemResources{
+			Files: lbdeploy.FileResourceMap{
+				"config": {Location: "program-data", Path: "Editor/config.json"},
+			},
+		},
+		Packages: lbdeploy.PackageMap{
+			"editor": {
+				Name:    "editor-setup",
+				Type:    "msi",
+				Sources: []lbdeploy.PackageSource{{Type: lbdeploy.PackageSourceHTTP, URL: "https://example.com/editor.msi"}},
+				Commands: lbdeploy.CommandMap{
+					"install": {Type: "msi-install", Executable: "editor-setup.msi", Installs: lbdeploy.AppList{"editor"}},
+				},
+			},
+		},
+	},
+	Flows: lbdeploy.FlowMap{
+		"install": {
+			Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionPreparePackage, Package: "editor"},
+				{Type: lbdeploy.ActionInvokeCommand, Package: "editor", Command: "install"},
+				{Type: lbdeploy.ActionDeleteFile, DestinationFile: "config", Conditions: lbdeploy.ConditionList{"has-config"}},
+				{Type: lbdeploy.ActionStartFlow, Flow: "again"},
+			},
+		},
+		"again": {
+			Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionInvokeCommand, Package: "editor", Command: "install"},
+			},
+		},
+	},
+}
+
+func TestMake(t *testing.T) {
+	fixtures := []struct {
+		Name    string
+		System  lbtest.System
+		Force   bool
+		Run     []string
+		Skipped []string
+	}{
+		{
+			Name:    "fresh",
+			Run:     []string{"install/1", "install/2", "install/4"},
+			Skipped: []string{"install/3", "again/1"},
+		},
+		{
+			Name:    "installed",
+			System:  lbtest.System{Files: []lbdeploy.FileResourceID{"config"}, Apps: map[lbdeploy.AppID]datatype.Version{"editor": "1.0"}},
+			Run:     []string{"install/1", "install/3", "install/4"},
+			Skipped: []string{"install/2", "again/1"},
+		},
+		{
+			Name:    "forced",
+			Force:   true,
+			Run:     []string{"install/1", "install/2", "install/4", "again/1"},
+			Skipped: []string{"install/3"},
+		},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			platform, err := lbtest.NewPlatform(testDeployment, fixture.System)
+			if err != nil {
+				t.Fatal(err)
+			}
+			steps, err := lbplan.Make(context.Background(), testDeployment, "install", platform, lbplan.Options{Force: fixture.Force})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var run, skipped []string
+			for _, step := range steps {
+				switch step.Outcome {
+				case lbplan.OutcomeRun:
+					run = append(run, step.ID())
+				case lbplan.OutcomeSkipped:
+					skipped = append(skipped, step.ID())
+				}
+			}
+			if !slices.Equal(run, fixture.Run) {
+				t.Errorf("run: got %v, want %v", run, fixture.Run)
+			}
+			if !slices.Equal(skipped, fixture.Skipped) {
+				t.Errorf("skipped: got %v, want %v", skipped, fixture.Skipped)
+			}
+			if t.Failed() {
+				for _, step := range steps {
+					t.Log(step)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package lbplatform defines the interfaces through which LeafBridge
+// observes the local system.
+//
+// The interfaces allow deployments to be parsed, validated and evaluated
+// by platform-independent code. Implementations for each supported
+// operating system live beneath the platform directory.
+package lbplatform
+
+import (
+	"io"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// Platform provides access to the local system.
+type Platform interface {
+	// FileSystem returns a file system that resolves resources from the
+	// given set of file system resources.
+	FileSystem(resources lbdeploy.FileSystemResources) FileSystem
+
+	// Registry returns a registry that resolves resources from the given
+	// set of registry resources.
+	Registry(resources lbdeploy.RegistryResources) Registry
+
+	// Apps returns an application detector for the local system.
+	Apps() AppDetector
+
+	// Processes returns a process controller for the local system.
+	Processes() ProcessController
+
+	// Certificates returns a certificate detector for the local system.
+	Certificates() CertificateDetector
+
+	// Hardware returns a hardware detector for the local system.
+	Hardware() HardwareDetector
+
+	// Locale returns a locale detector for the local system.
+	Locale() LocaleDetector
+
+	// Accounts returns an account detector for the local system.
+	Accounts() AccountDetector
+}
+
+// FileSystem resolves file system resources and reports on their presence.
+type FileSystem interface {
+	// DirectoryExists returns true if the directory exists.
+	DirectoryExists(dir lbdeploy.DirectoryResourceID) (bool, error)
+
+	// FileExists returns true if the file exists. It returns an error if
+	// the file's path exists but is not a regular file.
+	FileExists(file lbdeploy.FileResourceID) (bool, error)
+
+	// OpenFile opens the file for reading. If the file does not exist it
+	// returns an error that satisfies os.IsNotExist.
+	OpenFile(file lbdeploy.FileResourceID) (io.ReadCloser, error)
+}
+
+// Registry resolves registry resources and provides read access to them.
+type Registry interface {
+	// KeyExists returns true if the registry key exists.
+	KeyExists(key lbdeploy.RegistryKeyResourceID) (bool, error)
+
+	// ValueExists returns true if the registry value exists.
+	ValueExists(value lbdeploy.RegistryValueResourceID) (bool, error)
+
+	// GetValue returns the registry value. If the key or value does not
+	// exist it returns an error that satisfies os.IsNotExist.
+	GetValue(value lbdeploy.RegistryValueResourceID) (lbvalue.Value, error)
+}
+
+// AppDetector looks up applications in the local system's inventory of
+// installed software.
+type AppDetector interface {
+	// IsInstalled returns true if the application is present in the
+	// inventory.
+	IsInstalled(app lbdeploy.Application) (bool, error)
+
+	// Version returns the version of the application recorded in the
+	// inventory.
+	Version(app lbdeploy.Application) (datatype.Version, error)
+}
+
+// ProcessController provides information about processes and the kernel
+// objects they hold.
+type ProcessController interface {
+	// NumberOfRunningProcesses returns the number of running processes
+	// that match the given criteria.
+	NumberOfRunningProcesses(match lbdeploy.ProcessMatch) (int, error)
+
+	// MutexExists returns true if a mutex with the given object name
+	// exists.
+	MutexExists(name string) (bool, error)
+}
+
+// CertificateDetector looks up certificates in the certificate stores of
+// the local machine.
+type CertificateDetector interface {
+	// CertificateExists returns true if a certificate matching the
+	// resource is present in its store.
+	CertificateExists(cert lbdeploy.CertificateResource) (bool, error)
+}
+
+// HardwareDetector reports on the hardware and firmware of the local
+// system, on the encryption of its system drive, and on its power
+// management.
+type HardwareDetector interface {
+	// InstalledMemory returns the amount of installed memory in bytes.
+	InstalledMemory() (int64, error)
+
+	// LogicalProcessors returns the number of logical processors.
+	LogicalProcessors() (int, error)
+
+	// TPMVersion returns the major version of the trusted platform module.
+	// It returns zero if a TPM is not present.
+	TPMVersion() (int, error)
+
+	// SecureBootEnabled returns true if UEFI Secure Boot is enabled.
+	SecureBootEnabled() (bool, error)
+
+	// Hypervisor returns the hypervisor that the local system is running
+	// on. It returns an empty string if the system is a physical machine.
+	Hypervisor() (lbdeploy.Hypervisor, error)
+
+	// BitLockerProtected returns true if BitLocker protection of the
+	// system drive is on. It returns false if protection is off or
+	// suspended.
+	BitLockerProtected() (bool, error)
+
+	// ActivePowerPlan returns the GUID of the active power scheme in lower
+	// case, without braces.
+	ActivePowerPlan() (string, error)
+}
+
+// LocaleDetector reports on the language and regional settings of the
+// local system. Languages and locales are reported as language tags, such
+// as "en-US".
+type LocaleDetector interface {
+	// UILanguage returns the display language of the operating system.
+	UILanguage() (string, error)
+
+	// SystemLocale returns the system locale, which determines the code
+	// page used by programs that are not Unicode aware.
+	SystemLocale() (string, error)
+
+	// KeyboardLayouts returns the keyboard layouts of the current user.
+	KeyboardLayouts() ([]KeyboardLayout, error)
+
+	// TimeZone returns the identifier of the current time zone.
+	TimeZone() (string, error)
+}
+
+// AccountDetector reports on the local user accounts, local groups and
+// user rights of the local system. Accounts may be qualified by a domain,
+// such as "CONTOSO\Workstation Admins".
+type AccountDetector interface {
+	// LocalUserExists returns true if a local user account with the given
+	// name exists.
+	LocalUserExists(name string) (bool, error)
+
+	// IsGroupMember returns true if the account is a direct member of the
+	// local group. It returns an error if the group does not exist.
+	IsGroupMember(group, account string) (bool, error)
+
+	// HasUserRight returns true if the user right has been granted to the
+	// account directly.
+	HasUserRight(right, account string) (bool, error)
+}
+
+// KeyboardLayout identifies a keyboard layout. The language of a layout is
+// empty if it is not known.
+type KeyboardLayout struct {
+	ID       string
+	Language string
+}
@@ -0,0 +1,118 @@
+package lbpolicy
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lberror"
+)
+
+// DefaultMinApprovers is the number of approvals required when an approval
+// requirement doesn't specify one.
+const DefaultMinApprovers = 2
+
+// ApprovalRequirement describes the approvals that deployments must carry
+// before any of their flows are permitted to run.
+//
+// Keys maps the identity of each trusted approver to their base64-encoded
+// ed25519 public key. Only signatures made with these keys are counted, so
+// a deployment can't approve itself.
+type ApprovalRequirement struct {
+	Required     bool              `json:"required,omitempty"`
+	MinApprovers int               `json:"min-approvers,omitempty"`
+	Keys         map[string]string `json:"keys,omitzero"`
+}
+
+// IsZero returns true if the requirement does not require anything.
+func (req ApprovalRequirement) IsZero() bool {
+	return !req.Required && req.MinApprovers == 0 && len(req.Keys) == 0
+}
+
+// Validate returns a non-nil error if the requirement is invalid.
+func (req ApprovalRequirement) Validate() error {
+	if req.MinApprovers < 0 {
+		return errors.New("a negative number of approvers was provided")
+	}
+	if req.Required && len(req.Keys) < req.minApprovers() {
+		return fmt.Errorf("%d approvals are required but only %d approver keys were provided", req.minApprovers(), len(req.Keys))
+	}
+	for approver := range req.Keys {
+		if _, err := req.publicKey(approver); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify checks the approval of dep at the given time. It returns the
+// approvers whose signatures were verified. Signatures by anyone that isn't
+// listed as an approver of dep are ignored.
+//
+// If approval isn't required, Verify returns a nil error regardless of
+// the approval of dep, but still returns the approvers that it verified.
+func (req ApprovalRequirement) Verify(dep lbdeploy.Deployment, now time.Time) (approvers []string, err error) {
+	approval := dep.Approval
+
+	if !approval.IsZero() {
+		digest, err := dep.ApprovalDigest()
+		if err != nil {
+			return nil, err
+		}
+		for _, sig := range approval.Signatures {
+			if slices.Contains(approvers, sig.Approver) || !slices.Contains(approval.Approvers, sig.Approver) {
+				continue
+			}
+			key, err := req.publicKey(sig.Approver)
+			if err != nil {
+				continue
+			}
+			signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+			if err != nil || !ed25519.Verify(key, digest, signature) {
+				continue
+			}
+			approvers = append(approvers, sig.Approver)
+		}
+	}
+
+	if !req.Required {
+		return approvers, nil
+	}
+
+	switch {
+	case approval.IsZero():
+		return approvers, lberror.New(lberror.Policy, "the execution policy of this machine requires approval of deployments, but the deployment has not been approved")
+	case approval.Expired(now):
+		return approvers, lberror.New(lberror.Policy, fmt.Sprintf("the approval of the deployment expired on %s", approval.Expires.Format(time.DateOnly)))
+	case len(approvers) < req.minApprovers():
+		return approvers, lberror.New(lberror.Policy, fmt.Sprintf("the execution policy of this machine requires %d verified approvals, but the deployment has %d", req.minApprovers(), len(approvers)))
+	}
+
+	return approvers, nil
+}
+
+func (req ApprovalRequirement) minApprovers() int {
+	if req.MinApprovers == 0 {
+		return DefaultMinApprovers
+	}
+	return req.MinApprovers
+}
+
+func (req ApprovalRequirement) publicKey(approver string) (ed25519.PublicKey, error) {
+	encoded, ok := req.Keys[approver]
+	if !ok {
+		return nil, fmt.Errorf("a key for the \"%s\" approver was not provided", approver)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("the key for the \"%s\" approver is not valid base64: %w", approver, err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("the key for the \"%s\" approver is %d bytes long, but ed25519 public keys are %d bytes long", approver, len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
@@ -0,0 +1,133 @@
+package lbpolicy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Hold prevents LeafBridge from installing, uninstalling or otherwise
+// modifying an application on a machine, even when a deployment demands
+// it. Holds are typically used to pin lab machines to exact versions of
+// an application.
+//
+// A hold identifies an application by its app ID, its product code or
+// both. When both are provided, an application matches if either of them
+// matches.
+type Hold struct {
+	App         lbdeploy.AppID       `json:"app,omitempty"`
+	ProductCode lbdeploy.ProductCode `json:"product-code,omitempty"`
+	Reason      string               `json:"reason,omitempty"`
+	Created     time.Time            `json:"created,omitzero"`
+}
+
+// Validate returns a non-nil error if the hold does not identify an
+// application.
+func (hold Hold) Validate() error {
+	if hold.App == "" && hold.ProductCode == "" {
+		return errors.New("a hold must identify an app ID or a product code")
+	}
+	return nil
+}
+
+// Matches returns true if the hold applies to the application with the
+// given ID and definition. Product codes are compared without regard to
+// case.
+func (hold Hold) Matches(id lbdeploy.AppID, app lbdeploy.Application) bool {
+	if hold.App != "" && hold.App == id {
+		return true
+	}
+	if hold.ProductCode != "" && strings.EqualFold(string(hold.ProductCode), string(app.ProductCode)) {
+		return true
+	}
+	return false
+}
+
+// String returns a description of the held application.
+func (hold Hold) String() string {
+	switch {
+	case hold.App != "" && hold.ProductCode != "":
+		return fmt.Sprintf("%s (%s)", hold.App, hold.ProductCode)
+	case hold.App != "":
+		return string(hold.App)
+	default:
+		return string(hold.ProductCode)
+	}
+}
+
+// HoldList is a list of application holds.
+type HoldList []Hold
+
+// Validate returns a non-nil error if any of the holds are invalid.
+func (list HoldList) Validate() error {
+	for i, hold := range list {
+		if err := hold.Validate(); err != nil {
+			return fmt.Errorf("hold %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Find returns the first hold that applies to any of the given apps, which
+// are looked up in the apps of the deployment. It returns false if none of
+// the apps are held.
+func (list HoldList) Find(apps lbdeploy.AppMap, ids ...lbdeploy.AppID) (lbdeploy.AppID, Hold, bool) {
+	for _, id := range ids {
+		for _, hold := range list {
+			if hold.Matches(id, apps[id]) {
+				return id, hold, true
+			}
+		}
+	}
+	return "", Hold{}, false
+}
+
+// Remove returns a copy of the list without the holds that match the given
+// app ID or product code.
+func (list HoldList) Remove(id lbdeploy.AppID, productCode lbdeploy.ProductCode) HoldList {
+	return slices.DeleteFunc(slices.Clone(list), func(hold Hold) bool {
+		return (id != "" && hold.App == id) || (productCode != "" && strings.EqualFold(string(hold.ProductCode), string(productCode)))
+	})
+}
+
+// LoadHolds reads a list of holds from the JSON file at the given path. If
+// the file does not exist, an empty list is returned.
+func LoadHolds(path string) (HoldList, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the app holds could not be read: %w", err)
+	}
+
+	var list HoldList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("the app holds at \"%s\" are not valid JSON: %w", path, err)
+	}
+	if err := list.Validate(); err != nil {
+		return nil, fmt.Errorf("the app holds at \"%s\" are invalid: %w", path, err)
+	}
+
+	return list, nil
+}
+
+// SaveHolds writes the list of holds to the JSON file at the given path.
+// The directory that holds the file is created if necessary.
+func SaveHolds(path string, list HoldList) error {
+	data, err := json.MarshalIndent(list, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
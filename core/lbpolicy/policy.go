@@ -0,0 +1,284 @@
+// Package lbpolicy restricts the deployments that LeafBridge is permitted
+// to execute on a machine.
+//
+// An execution policy is a JSON document that is controlled by the
+// administrators of the machine. It lists the deployment IDs, package
+// source domains and action types that are allowed or denied. A policy is
+// checked against the whole deployment before any of its flows start, so
+// that a compromised deployment document can only do what the machine's
+// administrators have agreed to.
+//
+// Where the policy is kept is determined by each platform.
+package lbpolicy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lberror"
+)
+
+// Policy is an execution policy for LeafBridge deployments.
+//
+// Deployment IDs and action types are matched against the patterns of a
+// rule with [path.Match]. Source domain patterns match the host name of a
+// package source and all of its subdomains, so "example.com" matches both
+// "example.com" and "cdn.example.com".
+//
+// Approval describes the approvals that deployments must carry before
+// any of their flows are permitted to run.
+//
+// Holds lists applications that LeafBridge must not modify on the machine.
+//
+// A zero policy permits everything.
+type Policy struct {
+	Deployments   Rule                `json:"deployments,omitzero"`
+	SourceDomains Rule                `json:"source-domains,omitzero"`
+	ActionTypes   Rule                `json:"action-types,omitzero"`
+	Approval      ApprovalRequirement `json:"approval,omitzero"`
+	Holds         HoldList            `json:"holds,omitzero"`
+}
+
+// Load reads an execution policy from the JSON file at the given path. If
+// the file does not exist, a zero policy is returned.
+func Load(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Policy{}, nil
+	}
+	if err != nil {
+		return Policy{}, fmt.Errorf("the execution policy could not be read: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return Policy{}, fmt.Errorf("the execution policy at \"%s\" is not valid JSON: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return Policy{}, fmt.Errorf("the execution policy at \"%s\" is invalid: %w", path, err)
+	}
+
+	return policy, nil
+}
+
+// IsZero returns true if the policy does not restrict anything.
+func (policy Policy) IsZero() bool {
+	return policy.Deployments.IsZero() && policy.SourceDomains.IsZero() && policy.ActionTypes.IsZero() && policy.Approval.IsZero() && len(policy.Holds) == 0
+}
+
+// Validate returns a non-nil error if the policy contains invalid patterns.
+func (policy Policy) Validate() error {
+	for subject, rule := range map[Subject]Rule{SubjectDeployment: policy.Deployments, SubjectActionType: policy.ActionTypes} {
+		for _, pattern := range slices.Concat(rule.Allow, rule.Deny) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s: the \"%s\" pattern is invalid: %w", subject, pattern, err)
+			}
+		}
+	}
+	for _, pattern := range slices.Concat(policy.SourceDomains.Allow, policy.SourceDomains.Deny) {
+		if pattern == "" || strings.ContainsAny(pattern, "/:") {
+			return fmt.Errorf("%s: the \"%s\" pattern is not a domain name", SubjectSourceDomain, pattern)
+		}
+	}
+	if err := policy.Approval.Validate(); err != nil {
+		return fmt.Errorf("approval: %w", err)
+	}
+	if err := policy.Holds.Validate(); err != nil {
+		return fmt.Errorf("holds: %w", err)
+	}
+	return nil
+}
+
+// Check returns a [Violation] if the policy does not permit the deployment.
+// Approvals are verified separately, by [ApprovalRequirement.Verify].
+//
+// The ID of the deployment, the sources of all of its packages and
+// checksums files, and the types of all of the actions, hooks and undo
+// steps in all of its flows are checked. Flows can invoke one another, so flows that
+// won't necessarily be invoked are checked as well.
+func (policy Policy) Check(dep lbdeploy.Deployment) error {
+	if policy.IsZero() {
+		return nil
+	}
+
+	if !policy.Deployments.permits(string(dep.ID), matchPattern) {
+		return Violation{Deployment: dep.ID, Subject: SubjectDeployment, Value: string(dep.ID)}
+	}
+
+	if !policy.SourceDomains.IsZero() {
+		for _, id := range sortedKeys(dep.Resources.Packages) {
+			var sources []lbdeploy.PackageSource
+			for _, pkg := range dep.Resources.Packages[id].Variants() {
+				sources = append(sources, pkg.Sources...)
+				if !pkg.Checksums.IsZero() {
+					sources = append(sources, pkg.Checksums.Source)
+				}
+			}
+			for _, source := range sources {
+				host := sourceHost(source.URL)
+				if !policy.SourceDomains.permits(host, matchDomain) {
+					return Violation{
+						Deployment: dep.ID,
+						Subject:    SubjectSourceDomain,
+						Value:      host,
+						Location:   fmt.Sprintf("package \"%s\"", id),
+					}
+				}
+			}
+		}
+	}
+
+	if !policy.ActionTypes.IsZero() {
+		for _, id := range sortedKeys(dep.Flows) {
+			flow := dep.Flows[id]
+			type stage struct {
+				Name    string
+				Actions []lbdeploy.Action
+			}
+			stages := []stage{
+				{Name: "action", Actions: flow.Actions},
+				{Name: "before hook", Actions: flow.Hooks.Before},
+				{Name: "after hook", Actions: flow.Hooks.After},
+			}
+			for i, step := range flow.OnFailure.Undo {
+				stages = append(stages, stage{Name: fmt.Sprintf("undo step %d action", i+1), Actions: step.Actions})
+			}
+			for _, stage := range stages {
+				for i, action := range stage.Actions {
+					if !policy.ActionTypes.permits(string(action.Type), matchPattern) {
+						return Violation{
+							Deployment: dep.ID,
+							Subject:    SubjectActionType,
+							Value:      string(action.Type),
+							Location:   fmt.Sprintf("flow \"%s\" %s %d", id, stage.Name, i+1),
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Rule lists the values that are allowed or denied by a policy.
+//
+// A value is permitted if it does not match any of the deny patterns, and
+// either the allow list is empty or the value matches one of its patterns.
+type Rule struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// IsZero returns true if the rule does not restrict anything.
+func (rule Rule) IsZero() bool {
+	return len(rule.Allow) == 0 && len(rule.Deny) == 0
+}
+
+// permits returns true if the rule permits value, using match to compare
+// value against each pattern.
+func (rule Rule) permits(value string, match func(pattern, value string) bool) bool {
+	for _, pattern := range rule.Deny {
+		if match(pattern, value) {
+			return false
+		}
+	}
+	if len(rule.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range rule.Allow {
+		if match(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subject identifies the part of a deployment that a policy rule applies
+// to.
+type Subject string
+
+// Policy subjects.
+const (
+	SubjectDeployment   Subject = "deployment"
+	SubjectSourceDomain Subject = "source-domain"
+	SubjectActionType   Subject = "action-type"
+)
+
+// Violation is returned when an execution policy does not permit a
+// deployment.
+type Violation struct {
+	Deployment lbdeploy.DeploymentID
+	Subject    Subject
+	Value      string
+	Location   string
+}
+
+// Error returns a description of the violation.
+func (v Violation) Error() string {
+	var subject string
+	switch v.Subject {
+	case SubjectDeployment:
+		return fmt.Sprintf("the execution policy of this machine does not permit the \"%s\" deployment", v.Deployment)
+	case SubjectSourceDomain:
+		subject = "package sources on the domain"
+	case SubjectActionType:
+		subject = "the action type"
+	default:
+		subject = string(v.Subject)
+	}
+	if v.Location != "" {
+		return fmt.Sprintf("the execution policy of this machine does not permit %s \"%s\" (%s)", subject, v.Value, v.Location)
+	}
+	return fmt.Sprintf("the execution policy of this machine does not permit %s \"%s\"", subject, v.Value)
+}
+
+// Category returns the category of the error.
+func (v Violation) Category() lberror.Category {
+	return lberror.Policy
+}
+
+// matchPattern returns true if value matches the given path pattern.
+func matchPattern(pattern, value string) bool {
+	matched, _ := path.Match(pattern, value)
+	return matched
+}
+
+// matchDomain returns true if host is the given domain or one of its
+// subdomains. The comparison is not case-sensitive.
+func matchDomain(domain, host string) bool {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// sourceHost returns the host name of a package source URL. It returns an
+// empty string if the URL can't be parsed, which no domain pattern matches.
+func sourceHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// sortedKeys returns the keys of m in sorted order, so that violations
+// are reported consistently.
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
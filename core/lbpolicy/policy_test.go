@@ -0,0 +1,223 @@
+package lbpolicy_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/core/lbpolicy"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	dep := lbdeploy.Deployment{
+		ID: "contoso-app",
+		Resources: lbdeploy.Resources{
+			Packages: lbdeploy.PackageMap{
+				"installer": {Sources: []lbdeploy.PackageSource{{Type: lbdeploy.PackageSourceHTTP, URL: "https://cdn.contoso.com/app.msi"}}},
+			},
+		},
+		Flows: lbdeploy.FlowMap{
+			"install": {
+				Actions: []lbdeploy.Action{{Type: lbdeploy.ActionInvokeCommand}},
+				Hooks:   lbdeploy.ActionHooks{After: []lbdeploy.Action{{Type: lbdeploy.ActionEditHostsFile}}},
+			},
+		},
+	}
+
+	fixtures := []struct {
+		Policy  lbpolicy.Policy
+		Subject lbpolicy.Subject
+	}{
+		{Policy: lbpolicy.Policy{}},
+		{Policy: lbpolicy.Policy{Deployments: lbpolicy.Rule{Allow: []string{"contoso-*"}}}},
+		{Policy: lbpolicy.Policy{Deployments: lbpolicy.Rule{Allow: []string{"fabrikam-*"}}}, Subject: lbpolicy.SubjectDeployment},
+		{Policy: lbpolicy.Policy{Deployments: lbpolicy.Rule{Allow: []string{"*"}, Deny: []string{"contoso-app"}}}, Subject: lbpolicy.SubjectDeployment},
+		{Policy: lbpolicy.Policy{SourceDomains: lbpolicy.Rule{Allow: []string{"contoso.com"}}}},
+		{Policy: lbpolicy.Policy{SourceDomains: lbpolicy.Rule{Allow: []string{"CDN.Contoso.com."}}}},
+		{Policy: lbpolicy.Policy{SourceDomains: lbpolicy.Rule{Allow: []string{"tosocontoso.com"}}}, Subject: lbpolicy.SubjectSourceDomain},
+		{Policy: lbpolicy.Policy{SourceDomains: lbpolicy.Rule{Deny: []string{"contoso.com"}}}, Subject: lbpolicy.SubjectSourceDomain},
+		{Policy: lbpolicy.Policy{ActionTypes: lbpolicy.Rule{Allow: []string{string(lbdeploy.ActionInvokeCommand)}}}, Subject: lbpolicy.SubjectActionType},
+		{Policy: lbpolicy.Policy{ActionTypes: lbpolicy.Rule{Deny: []string{string(lbdeploy.ActionEditHostsFile)}}}, Subject: lbpolicy.SubjectActionType},
+		{Policy: lbpolicy.Policy{ActionTypes: lbpolicy.Rule{Allow: []string{"*"}}}},
+	}
+
+	for i, fixture := range fixtures {
+		err := fixture.Policy.Check(dep)
+		var violation lbpolicy.Violation
+		switch {
+		case fixture.Subject == "" && err != nil:
+			t.Errorf("fixture %d: unexpected violation: %v", i+1, err)
+		case fixture.Subject == "":
+		case !errors.As(err, &violation):
+			t.Errorf("fixture %d: got %v, want a %s violation", i+1, err, fixture.Subject)
+		case violation.Subject != fixture.Subject:
+			t.Errorf("fixture %d: got a %s violation, want a %s violation", i+1, violation.Subject, fixture.Subject)
+		case lberror.CategoryOf(err) != lberror.Policy:
+			t.Errorf("fixture %d: got the %s category, want %s", i+1, lberror.CategoryOf(err), lberror.Policy)
+		}
+	}
+}
+
+func TestPolicyCheckRollback(t *testing.T) {
+	dep := lbdeploy.Deployment{
+		ID: "contoso-app",
+		Flows: lbdeploy.FlowMap{
+			"install": {
+				Actions: []lbdeploy.Action{{Type: lbdeploy.ActionInvokeCommand}},
+				OnFailure: lbdeploy.FlowRollback{Undo: []lbdeploy.RollbackStep{
+					{Action: 1, Actions: []lbdeploy.Action{{Type: lbdeploy.ActionDeleteFile}}},
+				}},
+			},
+		},
+	}
+
+	policy := lbpolicy.Policy{ActionTypes: lbpolicy.Rule{Deny: []string{string(lbdeploy.ActionDeleteFile)}}}
+	err := policy.Check(dep)
+	var violation lbpolicy.Violation
+	if !errors.As(err, &violation) {
+		t.Fatalf("got %v, want an action type violation", err)
+	}
+	if want := `flow "install" undo step 1 action 1`; violation.Location != want {
+		t.Errorf("got location %q, want %q", violation.Location, want)
+	}
+}
+
+func TestApprovalVerify(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	keys := make(map[string]string)
+	private := make(map[string]ed25519.PrivateKey)
+	for _, approver := range []string{"alice", "bob", "carol", "mallory"} {
+		seed := make([]byte, ed25519.SeedSize)
+		copy(seed, approver)
+		private[approver] = ed25519.NewKeyFromSeed(seed)
+		if approver != "mallory" {
+			keys[approver] = base64.StdEncoding.EncodeToString(private[approver].Public().(ed25519.PublicKey))
+		}
+	}
+	req := lbpolicy.ApprovalRequirement{Required: true, Keys: keys}
+
+	// decode returns the deployment that is decoded from its document, so
+	// that its approval is verified against the document.
+	decode := func(dep lbdeploy.Deployment) lbdeploy.Deployment {
+		data, err := json.Marshal(dep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded lbdeploy.Deployment
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		return decoded
+	}
+
+	approve := func(dep lbdeploy.Deployment, signers ...string) lbdeploy.Deployment {
+		digest, err := decode(dep).ApprovalDigest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, signer := range signers {
+			dep.Approval.Signatures = append(dep.Approval.Signatures, lbdeploy.ApprovalSignature{
+				Approver:  signer,
+				Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(private[signer], digest)),
+			})
+		}
+		return decode(dep)
+	}
+
+	base := lbdeploy.Deployment{
+		ID: "contoso-app",
+		Approval: lbdeploy.Approval{
+			Approvers: []string{"alice", "bob", "mallory"},
+			Ticket:    "CHG-1234",
+			Expires:   now.Add(24 * time.Hour),
+		},
+	}
+	expired := base
+	expired.Approval.Expires = now.Add(-time.Hour)
+	tampered := approve(base, "alice", "bob")
+	tampered.Approval.Ticket = "CHG-9999"
+	tampered = decode(tampered)
+
+	// A field that LeafBridge doesn't recognize is still covered by the
+	// approval, so it can't be added after the deployment is signed.
+	var extended lbdeploy.Deployment
+	{
+		data, err := json.Marshal(approve(base, "alice", "bob"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append([]byte(`{"x-unsigned":true,`), data[1:]...)
+		if err := json.Unmarshal(data, &extended); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fixtures := []struct {
+		Deployment lbdeploy.Deployment
+		Verified   int
+		Permitted  bool
+	}{
+		{Deployment: decode(lbdeploy.Deployment{ID: "contoso-app"})},
+		{Deployment: approve(base, "alice"), Verified: 1},
+		{Deployment: approve(base, "alice", "alice"), Verified: 1},
+		{Deployment: approve(base, "alice", "bob"), Verified: 2, Permitted: true},
+		{Deployment: approve(base, "alice", "carol"), Verified: 1},
+		{Deployment: approve(base, "alice", "mallory"), Verified: 1},
+		{Deployment: approve(expired, "alice", "bob"), Verified: 2},
+		{Deployment: tampered},
+		{Deployment: extended},
+	}
+
+	for i, fixture := range fixtures {
+		verified, err := req.Verify(fixture.Deployment, now)
+		if len(verified) != fixture.Verified {
+			t.Errorf("fixture %d: got %d verified approvers, want %d", i+1, len(verified), fixture.Verified)
+		}
+		switch {
+		case fixture.Permitted && err != nil:
+			t.Errorf("fixture %d: unexpected error: %v", i+1, err)
+		case !fixture.Permitted && err == nil:
+			t.Errorf("fixture %d: the approval was accepted, but it should have been refused", i+1)
+		case err != nil && lberror.CategoryOf(err) != lberror.Policy:
+			t.Errorf("fixture %d: got the %s category, want %s", i+1, lberror.CategoryOf(err), lberror.Policy)
+		}
+	}
+}
+
+func TestHoldListFind(t *testing.T) {
+	apps := lbdeploy.AppMap{
+		"app-a": {Name: "App A", ProductCode: "{AAAAAAAA-0000-0000-0000-000000000000}"},
+		"app-b": {Name: "App B"},
+	}
+	holds := lbpolicy.HoldList{
+		{ProductCode: "{aaaaaaaa-0000-0000-0000-000000000000}", Reason: "lab image"},
+		{App: "app-c"},
+	}
+
+	fixtures := []struct {
+		Apps []lbdeploy.AppID
+		Held lbdeploy.AppID
+	}{
+		{Apps: nil},
+		{Apps: []lbdeploy.AppID{"app-b"}},
+		{Apps: []lbdeploy.AppID{"app-b", "app-a"}, Held: "app-a"},
+		{Apps: []lbdeploy.AppID{"app-c"}, Held: "app-c"},
+	}
+
+	for i, fixture := range fixtures {
+		app, _, held := holds.Find(apps, fixture.Apps...)
+		if held != (fixture.Held != "") || app != fixture.Held {
+			t.Errorf("fixture %d: got held app \"%s\", want \"%s\"", i, app, fixture.Held)
+		}
+	}
+
+	if remaining := holds.Remove("", "{AAAAAAAA-0000-0000-0000-000000000000}"); len(remaining) != 1 || remaining[0].App != "app-c" {
+		t.Errorf("unexpected holds after removal: %v", remaining)
+	}
+}
@@ -0,0 +1,74 @@
+// Package lbprogress tracks the progress of LeafBridge deployments, so that
+// it can be presented to end users by a separate process.
+package lbprogress
+
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// State identifies the state of a deployment.
+type State string
+
+// Deployment states.
+const (
+	StateWaiting   State = "waiting"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Status is a snapshot of the progress of a deployment.
+//
+// Flow, Action and Actions describe the outermost flow that is running.
+// Actions are numbered from 1. Progress is an estimate of the fraction of
+// the outermost flow that has been completed, from 0 to 1, which accounts
+// for the progress of any nested flows. Actions count toward it in
+// proportion to their estimated cost, so a large download counts for more
+// than a quick file copy. It never decreases while the flow is running.
+//
+// ETA is the predicted time that the outermost flow will be completed.
+// It is only provided when the durations of the flow's actions were
+// recorded when it last completed on this system.
+//
+// Message is the message of the most recent event that was recorded at
+// the info level or above.
+type Status struct {
+	Time           time.Time             `json:"time"`
+	State          State                 `json:"state"`
+	Deployment     lbdeploy.DeploymentID `json:"deployment,omitempty"`
+	DeploymentName string                `json:"deployment-name,omitempty"`
+	Flow           lbdeploy.FlowID       `json:"flow,omitempty"`
+	Action         int                   `json:"action,omitempty"`
+	Actions        int                   `json:"actions,omitempty"`
+	ActionType     lbdeploy.ActionType   `json:"action-type,omitempty"`
+	Progress       float64               `json:"progress"`
+	ETA            time.Time             `json:"eta,omitzero"`
+	Message        string                `json:"message,omitempty"`
+	Error          string                `json:"error,omitempty"`
+}
+
+// Finished returns true if the deployment has succeeded, failed or been
+// cancelled. A finished status is the last status that is published for a
+// flow.
+func (s Status) Finished() bool {
+	return s.State == StateSucceeded || s.State == StateFailed || s.State == StateCancelled
+}
+
+// Publisher is an interface that receives status updates.
+type Publisher interface {
+	Publish(Status)
+}
+
+// Publishers is a publisher that sends each status update to all of its
+// members.
+type Publishers []Publisher
+
+// Publish sends status to each of the publishers.
+func (p Publishers) Publish(status Status) {
+	for _, publisher := range p {
+		publisher.Publish(status)
+	}
+}
@@ -0,0 +1,51 @@
+package lbprogress
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// StatusFile is a publisher that writes each status update to a JSON file,
+// so that the progress of a deployment can be read by other tools.
+//
+// Each update replaces the file as a whole, so readers never see a
+// partially written status. Failures to write the file are ignored, as
+// they must not interfere with the deployment.
+type StatusFile struct {
+	path string
+}
+
+// NewStatusFile returns a status file that is written to the given path.
+func NewStatusFile(path string) StatusFile {
+	return StatusFile{path: path}
+}
+
+// Path returns the path of the status file.
+func (f StatusFile) Path() string {
+	return f.path
+}
+
+// Publish writes status to the file.
+func (f StatusFile) Publish(status Status) {
+	data, err := json.MarshalIndent(status, "", "\t")
+	if err != nil {
+		return
+	}
+
+	dir := filepath.Dir(f.path)
+	file, err := os.CreateTemp(dir, filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+}
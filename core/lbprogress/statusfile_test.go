@@ -0,0 +1,31 @@
+package lbprogress_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbprogress"
+)
+
+func TestStatusFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.json")
+	file := lbprogress.NewStatusFile(path)
+
+	for _, state := range []lbprogress.State{lbprogress.StateRunning, lbprogress.StateSucceeded} {
+		file.Publish(lbprogress.Status{State: state, Deployment: "example"})
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var status lbprogress.Status
+		if err := json.Unmarshal(data, &status); err != nil {
+			t.Fatal(err)
+		}
+		if status.State != state || status.Deployment != "example" {
+			t.Errorf("got %+v from the status file, want state %s", status, state)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package lbprogress
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Tracker is a LeafBridge event handler that tracks the progress of a
+// deployment. It sends a status update to its publisher each time the
+// progress changes.
+type Tracker struct {
+	deployment lbdeploy.Deployment
+	weights    Weights
+	publisher  Publisher
+
+	mutex  sync.Mutex
+	status Status
+	flows  []flowProgress
+}
+
+// flowProgress records the progress of a running flow.
+type flowProgress struct {
+	id        lbdeploy.FlowID
+	weights   []float64
+	completed int
+}
+
+// NewTracker returns a tracker for the given deployment that sends status
+// updates to publisher.
+//
+// The progress of each flow is weighted by the estimated cost of its
+// actions. If weights is empty, they are estimated from the deployment
+// alone. An estimated time of completion is only reported for flows whose
+// weights were measured.
+func NewTracker(dep lbdeploy.Deployment, weights Weights, publisher Publisher) *Tracker {
+	if weights.Flows == nil {
+		weights = EstimateWeights(dep, nil)
+	}
+	return &Tracker{
+		deployment: dep,
+		weights:    weights,
+		publisher:  publisher,
+		status: Status{
+			State:          StateWaiting,
+			Deployment:     dep.ID,
+			DeploymentName: dep.Name,
+		},
+	}
+}
+
+// Name returns a name for the handler.
+func (t *Tracker) Name() string {
+	return "progress-tracker"
+}
+
+// Status returns the current status of the deployment.
+func (t *Tracker) Status() Status {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.status
+}
+
+// Handle processes the given event record.
+func (t *Tracker) Handle(r lbevent.Record) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.update(r) {
+		return nil
+	}
+
+	t.status.Time = r.Time()
+	if r.Level() >= slog.LevelInfo {
+		t.status.Message = r.Message()
+	}
+
+	if t.publisher != nil {
+		t.publisher.Publish(t.status)
+	}
+
+	return nil
+}
+
+// update applies the event record to the status of the deployment. It
+// returns false if the record is not relevant.
+func (t *Tracker) update(r lbevent.Record) bool {
+	switch event := r.Data().(type) {
+	case lbdeployevent.FlowStarted:
+		if event.Deployment != t.deployment.ID {
+			return false
+		}
+		if len(t.flows) == 0 {
+			t.status = Status{
+				State:          StateRunning,
+				Deployment:     t.deployment.ID,
+				DeploymentName: t.deployment.Name,
+				Flow:           event.Flow,
+				Actions:        len(t.deployment.Flows[event.Flow].Actions),
+			}
+		}
+		t.flows = append(t.flows, flowProgress{
+			id:      event.Flow,
+			weights: t.weights.Flows[event.Flow],
+		})
+	case lbdeployevent.FlowStopped:
+		n := len(t.flows)
+		if event.Deployment != t.deployment.ID || n == 0 || t.flows[n-1].id != event.Flow {
+			return false
+		}
+		t.flows = t.flows[:n-1]
+		if len(t.flows) == 0 {
+			t.status.ActionType = ""
+			t.status.ETA = time.Time{}
+			if event.Cancelled {
+				t.status.State = StateCancelled
+			} else if event.Err != nil {
+				t.status.State = StateFailed
+				t.status.Error = event.Err.Error()
+			} else {
+				t.status.State = StateSucceeded
+				t.status.Action = t.status.Actions
+				t.status.Progress = 1
+			}
+		}
+	case lbdeployevent.ActionStarted:
+		n := len(t.flows)
+		if event.Deployment != t.deployment.ID || n == 0 || t.flows[n-1].id != event.Flow {
+			return false
+		}
+		t.flows[n-1].completed = event.ActionIndex
+		if n == 1 {
+			t.status.Action = event.ActionIndex + 1
+			t.status.ActionType = event.ActionType
+		}
+	case lbdeployevent.ActionStopped:
+		n := len(t.flows)
+		if event.Deployment != t.deployment.ID || n == 0 || t.flows[n-1].id != event.Flow {
+			return false
+		}
+		t.flows[n-1].completed = event.ActionIndex + 1
+	default:
+		// Keep the message current while the deployment is running.
+		return len(t.flows) > 0 && r.Level() >= slog.LevelInfo
+	}
+
+	// Progress never moves backward, even when a nested flow has stopped
+	// before the action that invoked it.
+	if len(t.flows) > 0 {
+		t.status.Progress = max(t.status.Progress, t.progress())
+		t.status.ETA = t.eta(r.Time())
+	}
+
+	return true
+}
+
+// progress returns the fraction of the outermost flow that has been
+// completed, according to the weights of its actions. The progress of each
+// nested flow is counted as a fraction of the action in its parent that
+// invoked it.
+func (t *Tracker) progress() float64 {
+	progress, scale := 0.0, 1.0
+	for _, flow := range t.flows {
+		total := sum(flow.weights)
+		if total <= 0 {
+			break
+		}
+		completed := min(flow.completed, len(flow.weights))
+		progress += scale * sum(flow.weights[:completed]) / total
+		if completed == len(flow.weights) {
+			break
+		}
+		scale *= flow.weights[completed] / total
+	}
+	return progress
+}
+
+// eta returns the predicted time of completion of the outermost flow,
+// based on its progress at the given time. It returns a zero time if the
+// weights of the flow were not measured.
+func (t *Tracker) eta(now time.Time) time.Time {
+	flow := t.flows[0].id
+	if !t.weights.Measured[flow] {
+		return time.Time{}
+	}
+	remaining := (1 - t.status.Progress) * t.weights.Total(flow)
+	return now.Add(time.Duration(remaining * float64(time.Second))).Round(time.Second)
+}
@@ -0,0 +1,83 @@
+package lbprogress_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbprogress"
+)
+
+type publisher []lbprogress.Status
+
+func (p *publisher) Publish(status lbprogress.Status) {
+	*p = append(*p, status)
+}
+
+func TestTracker(t *testing.T) {
+	dep := lbdeploy.Deployment{
+		ID: "example",
+		Flows: lbdeploy.FlowMap{
+			"install": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionStartFlow, Flow: "prepare"},
+				{Type: lbdeploy.ActionInvokeCommand},
+			}},
+			"prepare": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionCopyFile},
+				{Type: lbdeploy.ActionCopyFile},
+				{Type: lbdeploy.ActionDeleteFile},
+				{Type: lbdeploy.ActionDeleteFile},
+			}},
+		},
+	}
+
+	fixtures := []struct {
+		Event    lbevent.Interface
+		State    lbprogress.State
+		Action   int
+		Progress float64
+	}{
+		{Event: lbdeployevent.FlowStarted{Deployment: "example", Flow: "install"}, State: lbprogress.StateRunning, Progress: 0},
+		{Event: lbdeployevent.ActionStarted{Deployment: "example", Flow: "install", ActionIndex: 0}, State: lbprogress.StateRunning, Action: 1, Progress: 0},
+		{Event: lbdeployevent.FlowStarted{Deployment: "example", Flow: "prepare"}, State: lbprogress.StateRunning, Action: 1, Progress: 0},
+		{Event: lbdeployevent.ActionStopped{Deployment: "example", Flow: "prepare", ActionIndex: 2}, State: lbprogress.StateRunning, Action: 1, Progress: 0.375},
+		{Event: lbdeployevent.FlowStopped{Deployment: "example", Flow: "prepare"}, State: lbprogress.StateRunning, Action: 1, Progress: 0.375},
+		{Event: lbdeployevent.ActionStopped{Deployment: "example", Flow: "install", ActionIndex: 0}, State: lbprogress.StateRunning, Action: 1, Progress: 0.5},
+		{Event: lbdeployevent.ActionStarted{Deployment: "example", Flow: "install", ActionIndex: 1}, State: lbprogress.StateRunning, Action: 2, Progress: 0.5},
+		{Event: lbdeployevent.FlowStopped{Deployment: "example", Flow: "install"}, State: lbprogress.StateSucceeded, Action: 2, Progress: 1},
+	}
+
+	var updates publisher
+	weights := lbprogress.Weights{Flows: map[lbdeploy.FlowID][]float64{"install": {1, 1}, "prepare": {1, 1, 1, 1}}}
+	tracker := lbprogress.NewTracker(dep, weights, &updates)
+	for i, fixture := range fixtures {
+		if err := tracker.Handle(lbevent.NewRecord(time.Now(), 0, fixture.Event)); err != nil {
+			t.Fatal(err)
+		}
+		status := tracker.Status()
+		if status.State != fixture.State || status.Action != fixture.Action || status.Progress != fixture.Progress {
+			t.Errorf("event %d: got state %s, action %d, progress %g; want state %s, action %d, progress %g", i+1, status.State, status.Action, status.Progress, fixture.State, fixture.Action, fixture.Progress)
+		}
+	}
+	if len(updates) != len(fixtures) {
+		t.Errorf("got %d status updates, want %d", len(updates), len(fixtures))
+	}
+
+	// A failed flow is reported with its error.
+	tracker.Handle(lbevent.NewRecord(time.Now(), 0, lbdeployevent.FlowStarted{Deployment: "example", Flow: "install"}))
+	tracker.Handle(lbevent.NewRecord(time.Now(), 0, lbdeployevent.FlowStopped{Deployment: "example", Flow: "install", Err: errors.New("failure")}))
+	if status := tracker.Status(); status.State != lbprogress.StateFailed || status.Error != "failure" {
+		t.Errorf("got state %s with error \"%s\", want state %s with error \"failure\"", status.State, status.Error, lbprogress.StateFailed)
+	}
+
+	// A cancelled flow is reported as cancelled rather than failed.
+	tracker.Handle(lbevent.NewRecord(time.Now(), 0, lbdeployevent.FlowStarted{Deployment: "example", Flow: "install"}))
+	tracker.Handle(lbevent.NewRecord(time.Now(), 0, lbdeployevent.FlowStopped{Deployment: "example", Flow: "install", Err: context.Canceled, Cancelled: true}))
+	if status := tracker.Status(); status.State != lbprogress.StateCancelled || !status.Finished() {
+		t.Errorf("got state %s, want finished state %s", status.State, lbprogress.StateCancelled)
+	}
+}
@@ -0,0 +1,144 @@
+package lbprogress
+
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Rough rates and costs that are used to estimate how long an action will
+// take when it has no recorded history. They only need to be accurate
+// relative to each other.
+const (
+	downloadRate       = 10 << 20 // bytes per second
+	extractionRate     = 50 << 20 // bytes per second
+	unknownPackageCost = 30       // seconds
+	fileCost           = 0.1      // seconds per extracted file
+	commandCost        = 20       // seconds
+	scriptCost         = 5        // seconds
+	itemCost           = 0.5      // seconds per configured item
+	actionCost         = 1        // seconds
+)
+
+// Weights holds the estimated cost of each action within each flow of a
+// deployment. Costs are measured in seconds, so that the costs of actions
+// of different kinds can be compared.
+//
+// Measured records the flows whose costs were taken from the durations
+// that were recorded when the flow last completed. Only the costs of
+// measured flows are reliable enough to predict how long they will take.
+type Weights struct {
+	Flows    map[lbdeploy.FlowID][]float64
+	Measured map[lbdeploy.FlowID]bool
+}
+
+// EstimateWeights estimates the cost of each action within each flow of
+// dep.
+//
+// History holds the duration of each action in a flow when the flow last
+// completed, as recorded in its state store. Recorded durations are
+// preferred over estimates. Actions without a recorded duration are
+// estimated from the size of the packages they download, the number of
+// files they extract and the number of items they configure. The cost of
+// a start-flow action is the total cost of the flow it starts.
+func EstimateWeights(dep lbdeploy.Deployment, history map[lbdeploy.FlowID][]time.Duration) Weights {
+	e := estimator{
+		deployment: dep,
+		history:    history,
+		weights: Weights{
+			Flows:    make(map[lbdeploy.FlowID][]float64, len(dep.Flows)),
+			Measured: make(map[lbdeploy.FlowID]bool),
+		},
+		visiting: make(map[lbdeploy.FlowID]bool),
+	}
+	for flow := range dep.Flows {
+		e.flow(flow)
+	}
+	return e.weights
+}
+
+// Total returns the total cost of the actions in the given flow.
+func (w Weights) Total(flow lbdeploy.FlowID) float64 {
+	return sum(w.Flows[flow])
+}
+
+// estimator estimates the weights of a deployment's flows.
+type estimator struct {
+	deployment lbdeploy.Deployment
+	history    map[lbdeploy.FlowID][]time.Duration
+	weights    Weights
+	visiting   map[lbdeploy.FlowID]bool
+}
+
+// flow returns the estimated weights of the given flow's actions.
+func (e estimator) flow(id lbdeploy.FlowID) []float64 {
+	if weights, done := e.weights.Flows[id]; done {
+		return weights
+	}
+
+	// Flows that start themselves, directly or indirectly, will fail when
+	// they run.
+	if e.visiting[id] {
+		return nil
+	}
+	e.visiting[id] = true
+	defer delete(e.visiting, id)
+
+	actions := e.deployment.Flows[id].Actions
+	recorded := e.history[id]
+	if len(recorded) != len(actions) {
+		recorded = nil
+	}
+	if len(recorded) > 0 {
+		e.weights.Measured[id] = true
+	}
+
+	weights := make([]float64, len(actions))
+	for i, action := range actions {
+		if recorded != nil && recorded[i] > 0 {
+			weights[i] = max(recorded[i].Seconds(), actionCost)
+		} else {
+			weights[i] = e.action(action)
+		}
+	}
+	e.weights.Flows[id] = weights
+
+	return weights
+}
+
+// action returns the estimated cost of an action.
+func (e estimator) action(action lbdeploy.Action) float64 {
+	switch action.Type {
+	case lbdeploy.ActionStartFlow:
+		return max(sum(e.flow(action.Flow)), actionCost)
+	case lbdeploy.ActionPreparePackage:
+		pkg, found := e.deployment.Resources.Packages[action.Package]
+		if !found || pkg.Attributes.Size <= 0 {
+			return unknownPackageCost
+		}
+		cost := float64(pkg.Attributes.Size) / downloadRate
+		if pkg.Type.IsArchive() {
+			cost += float64(pkg.Attributes.Size)/extractionRate + fileCost*float64(len(pkg.Files))
+		}
+		return max(cost, actionCost)
+	case lbdeploy.ActionInvokeCommand:
+		return commandCost
+	case lbdeploy.ActionPowerShellScript, lbdeploy.ActionCmdScript, lbdeploy.ActionShellScript:
+		return scriptCost
+	}
+
+	items := len(action.Edits) + len(action.HostsEntries) + len(action.Extensions) +
+		len(action.BrowserPolicies) + len(action.Associations) + len(action.LocalUsers) +
+		len(action.GroupMembers) + len(action.UserRights) + len(action.PowerSettings)
+
+	return actionCost + itemCost*float64(items)
+}
+
+// sum returns the sum of the given weights.
+func sum(weights []float64) float64 {
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+	return total
+}
@@ -0,0 +1,107 @@
+package lbprogress_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbprogress"
+)
+
+func TestEstimateWeights(t *testing.T) {
+	dep := lbdeploy.Deployment{
+		ID: "example",
+		Resources: lbdeploy.Resources{
+			Packages: lbdeploy.PackageMap{
+				"installer": {Type: "msi", Attributes: lbdeploy.FileAttributes{Size: 100 << 20}},
+				"unknown":   {Type: "msi"},
+			},
+		},
+		Flows: lbdeploy.FlowMap{
+			"install": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionStartFlow, Flow: "prepare"},
+				{Type: lbdeploy.ActionInvokeCommand},
+			}},
+			"prepare": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionPreparePackage, Package: "installer"},
+				{Type: lbdeploy.ActionPreparePackage, Package: "unknown"},
+				{Type: lbdeploy.ActionDeleteFile},
+			}},
+			"configure": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionEditHostsFile, HostsEntries: make([]lbdeploy.HostsEntry, 4)},
+				{Type: lbdeploy.ActionCopyFile},
+			}},
+			"loop": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionStartFlow, Flow: "loop"},
+			}},
+		},
+	}
+
+	fixtures := []struct {
+		Name    string
+		History map[lbdeploy.FlowID][]time.Duration
+		Flow    lbdeploy.FlowID
+		Weights []float64
+	}{
+		{Name: "Downloads", Flow: "prepare", Weights: []float64{10, 30, 1}},
+		{Name: "NestedFlow", Flow: "install", Weights: []float64{41, 20}},
+		{Name: "Items", Flow: "configure", Weights: []float64{3, 1}},
+		{Name: "Cycle", Flow: "loop", Weights: []float64{1}},
+		{Name: "History", History: map[lbdeploy.FlowID][]time.Duration{"prepare": {2 * time.Minute, 0, 500 * time.Millisecond}}, Flow: "prepare", Weights: []float64{120, 30, 1}},
+		{Name: "HistoryOfNestedFlow", History: map[lbdeploy.FlowID][]time.Duration{"prepare": {2 * time.Minute, 0, 0}}, Flow: "install", Weights: []float64{151, 20}},
+		{Name: "StaleHistory", History: map[lbdeploy.FlowID][]time.Duration{"prepare": {2 * time.Minute}}, Flow: "prepare", Weights: []float64{10, 30, 1}},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			weights := lbprogress.EstimateWeights(dep, fixture.History)
+			if got := weights.Flows[fixture.Flow]; !slices.Equal(got, fixture.Weights) {
+				t.Errorf("got weights %v, want %v", got, fixture.Weights)
+			}
+		})
+	}
+}
+
+func TestTrackerWeights(t *testing.T) {
+	dep := lbdeploy.Deployment{
+		ID: "example",
+		Flows: lbdeploy.FlowMap{
+			"install": {Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionPreparePackage},
+				{Type: lbdeploy.ActionDeleteFile},
+			}},
+		},
+	}
+	weights := lbprogress.Weights{Flows: map[lbdeploy.FlowID][]float64{"install": {30, 10}}}
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	tracker := lbprogress.NewTracker(dep, weights, nil)
+	tracker.Handle(lbevent.NewRecord(now, 0, lbdeployevent.FlowStarted{Deployment: "example", Flow: "install"}))
+	tracker.Handle(lbevent.NewRecord(now, 0, lbdeployevent.ActionStopped{Deployment: "example", Flow: "install", ActionIndex: 0}))
+	status := tracker.Status()
+	if status.Progress != 0.75 {
+		t.Errorf("got progress %g after the first action, want 0.75", status.Progress)
+	}
+	if !status.ETA.IsZero() {
+		t.Errorf("got an ETA of %s for a flow that was not measured", status.ETA)
+	}
+
+	// Measured flows predict their time of completion.
+	weights.Measured = map[lbdeploy.FlowID]bool{"install": true}
+	tracker = lbprogress.NewTracker(dep, weights, nil)
+	tracker.Handle(lbevent.NewRecord(now, 0, lbdeployevent.FlowStarted{Deployment: "example", Flow: "install"}))
+	if eta := tracker.Status().ETA; !eta.Equal(now.Add(40 * time.Second)) {
+		t.Errorf("got an ETA of %s at the start of the flow, want %s", eta, now.Add(40*time.Second))
+	}
+	tracker.Handle(lbevent.NewRecord(now.Add(time.Minute), 0, lbdeployevent.ActionStopped{Deployment: "example", Flow: "install", ActionIndex: 0}))
+	if eta := tracker.Status().ETA; !eta.Equal(now.Add(70 * time.Second)) {
+		t.Errorf("got an ETA of %s after the first action, want %s", eta, now.Add(70*time.Second))
+	}
+	tracker.Handle(lbevent.NewRecord(now.Add(time.Minute), 0, lbdeployevent.FlowStopped{Deployment: "example", Flow: "install"}))
+	if eta := tracker.Status().ETA; !eta.IsZero() {
+		t.Errorf("got an ETA of %s after the flow stopped", eta)
+	}
+}
@@ -0,0 +1,61 @@
+package lbrollout
+
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// StepOutcome describes the outcome of a rollout step.
+type StepOutcome string
+
+// Step outcomes.
+const (
+	StepCompleted StepOutcome = "completed"
+	StepSkipped   StepOutcome = "skipped"
+	StepFailed    StepOutcome = "failed"
+	StepNotRun    StepOutcome = "not-run"
+)
+
+// StepResult records the result of a rollout step.
+type StepResult struct {
+	Index      int
+	Label      string
+	Deployment lbdeploy.DeploymentID
+	Flow       lbdeploy.FlowID
+	Outcome    StepOutcome
+	Duration   time.Duration
+	Err        error
+}
+
+// Report records the results of a rollout.
+type Report struct {
+	Rollout RolloutID
+	Steps   []StepResult
+}
+
+// Stats returns the number of steps with each outcome.
+func (report Report) Stats() Stats {
+	var stats Stats
+	for _, step := range report.Steps {
+		switch step.Outcome {
+		case StepCompleted:
+			stats.StepsCompleted++
+		case StepSkipped:
+			stats.StepsSkipped++
+		case StepFailed:
+			stats.StepsFailed++
+		case StepNotRun:
+			stats.StepsNotRun++
+		}
+	}
+	return stats
+}
+
+// Stats hold statistics about a rollout that has been invoked.
+type Stats struct {
+	StepsCompleted int
+	StepsSkipped   int
+	StepsFailed    int
+	StepsNotRun    int
+}
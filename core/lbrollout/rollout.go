@@ -0,0 +1,127 @@
+// Package lbrollout describes rollouts, which invoke a sequence of
+// LeafBridge deployments in order.
+//
+// A rollout is described by a rollout file, which lists the deployment
+// files to invoke along with the flow to invoke within each of them:
+//
+//	{
+//	  "id": "workstation-image",
+//	  "name": "Workstation Image",
+//	  "steps": [
+//	    {"deployment": "runtime/deploy.json", "flow": "install"},
+//	    {"deployment": "office/deploy.json", "flow": "install", "conditions": ["not-kiosk"]},
+//	    {"deployment": "agent/deploy.json", "flow": "install", "on-failure": "continue"}
+//	  ]
+//	}
+//
+// Deployment paths are relative to the directory of the rollout file.
+package lbrollout
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// RolloutID is a unique identifier for a rollout.
+type RolloutID string
+
+// Rollout describes a sequence of deployments that are invoked in order.
+//
+// When a step fails, the rollout's failure policy determines whether the
+// remaining steps are invoked. Each step can override the policy.
+type Rollout struct {
+	ID        RolloutID     `json:"id"`
+	Name      string        `json:"name,omitempty"`
+	OnFailure FailurePolicy `json:"on-failure,omitempty"`
+	Steps     []Step        `json:"steps"`
+}
+
+// Validate returns a non-nil error if the rollout contains invalid
+// configuration.
+func (r Rollout) Validate() error {
+	if r.ID == "" {
+		return errors.New("the rollout ID is missing")
+	}
+	if err := r.OnFailure.Validate(); err != nil {
+		return err
+	}
+	if len(r.Steps) == 0 {
+		return errors.New("the rollout does not have any steps")
+	}
+	for i, step := range r.Steps {
+		if err := step.Validate(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Step is a step within a rollout that invokes a flow within a deployment.
+//
+// If conditions are provided, the step is skipped unless all of them are
+// true. The conditions are defined by the step's deployment.
+type Step struct {
+	Name       string                 `json:"name,omitempty"`
+	Deployment string                 `json:"deployment"`
+	Flow       lbdeploy.FlowID        `json:"flow"`
+	Conditions lbdeploy.ConditionList `json:"conditions,omitzero"`
+	OnFailure  FailurePolicy          `json:"on-failure,omitempty"`
+}
+
+// Validate returns a non-nil error if the step contains invalid
+// configuration.
+func (step Step) Validate() error {
+	if step.Deployment == "" {
+		return errors.New("a deployment file path is missing")
+	}
+	if p := strings.ReplaceAll(step.Deployment, "\\", "/"); path.IsAbs(p) || strings.Contains(p, ":") {
+		return fmt.Errorf("the deployment file path \"%s\" must be relative to the rollout file", step.Deployment)
+	}
+	if !strings.HasSuffix(step.Deployment, "deploy.json") {
+		return fmt.Errorf("the deployment file path \"%s\" must end in deploy.json", step.Deployment)
+	}
+	if step.Flow == "" {
+		return errors.New("a flow is missing")
+	}
+	return step.OnFailure.Validate()
+}
+
+// Label returns a label for the step, which is its name if it has one.
+// Otherwise it is the path of its deployment file.
+func (step Step) Label() string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return step.Deployment
+}
+
+// FailurePolicy determines what a rollout does when one of its steps fails.
+type FailurePolicy string
+
+// Failure policies.
+const (
+	StopOnFailure     FailurePolicy = "stop"
+	ContinueOnFailure FailurePolicy = "continue"
+)
+
+// Validate returns a non-nil error if the policy is not recognized.
+func (policy FailurePolicy) Validate() error {
+	switch policy {
+	case "", StopOnFailure, ContinueOnFailure:
+		return nil
+	default:
+		return fmt.Errorf("the failure policy \"%s\" is not recognized", policy)
+	}
+}
+
+// Or returns policy if it is set, and fallback otherwise.
+func (policy FailurePolicy) Or(fallback FailurePolicy) FailurePolicy {
+	if policy != "" {
+		return policy
+	}
+	return fallback
+}
@@ -0,0 +1,38 @@
+package lbrollout_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbrollout"
+)
+
+func TestRolloutValidate(t *testing.T) {
+	valid := lbrollout.Step{Deployment: "office/deploy.json", Flow: "install"}
+
+	fixtures := []struct {
+		Name    string
+		Rollout lbrollout.Rollout
+		OK      bool
+	}{
+		{Name: "valid", Rollout: lbrollout.Rollout{ID: "image", Steps: []lbrollout.Step{valid}}, OK: true},
+		{Name: "continue", Rollout: lbrollout.Rollout{ID: "image", OnFailure: lbrollout.ContinueOnFailure, Steps: []lbrollout.Step{valid}}, OK: true},
+		{Name: "missing-id", Rollout: lbrollout.Rollout{Steps: []lbrollout.Step{valid}}, OK: false},
+		{Name: "no-steps", Rollout: lbrollout.Rollout{ID: "image"}, OK: false},
+		{Name: "bad-policy", Rollout: lbrollout.Rollout{ID: "image", OnFailure: "retry", Steps: []lbrollout.Step{valid}}, OK: false},
+		{Name: "missing-flow", Rollout: lbrollout.Rollout{ID: "image", Steps: []lbrollout.Step{{Deployment: "office/deploy.json"}}}, OK: false},
+		{Name: "absolute-path", Rollout: lbrollout.Rollout{ID: "image", Steps: []lbrollout.Step{{Deployment: `C:\office\deploy.json`, Flow: "install"}}}, OK: false},
+		{Name: "wrong-suffix", Rollout: lbrollout.Rollout{ID: "image", Steps: []lbrollout.Step{{Deployment: "office/office.json", Flow: "install"}}}, OK: false},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			err := fixture.Rollout.Validate()
+			if fixture.OK && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !fixture.OK && err == nil {
+				t.Fatal("expected an error but none was returned")
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package lbschedule
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// DeploymentResources returns the keys of the resources that a deployment
+// contends for:
+//
+//   - The deployment itself, so that two invocations of it don't overlap
+//   - The mutex of each lock that its flows acquire
+//   - The source of each of its packages
+//
+// The keys are sorted and free of duplicates.
+func DeploymentResources(dep lbdeploy.Deployment) []string {
+	resources := []string{"deployment:" + string(dep.ID)}
+
+	for _, flow := range dep.Flows {
+		for _, lock := range flow.Locks {
+			definition, found := dep.Resources.Locks[lock]
+			if !found {
+				continue
+			}
+			mutex, found := dep.Resources.Mutexes[definition.Mutex]
+			if !found {
+				continue
+			}
+			name, err := mutex.ObjectName()
+			if err != nil {
+				continue
+			}
+			resources = append(resources, "mutex:"+name)
+		}
+	}
+
+	for _, pkg := range dep.Resources.Packages {
+		for _, variant := range pkg.Variants() {
+			for _, source := range variant.Sources {
+				resources = append(resources, "package:"+strings.ToLower(source.URL))
+			}
+		}
+	}
+
+	slices.Sort(resources)
+	return slices.Compact(resources)
+}
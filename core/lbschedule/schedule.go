@@ -0,0 +1,135 @@
+// Package lbschedule runs sets of deployments concurrently, while keeping
+// deployments that contend for the same resources from running at the
+// same time.
+//
+// Each job declares the resources that it contends for as a set of
+// opaque keys. Jobs that share a key are run one at a time, in order of
+// priority. Independent jobs run concurrently, up to a limit.
+package lbschedule
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+)
+
+// Job is a unit of work that is run by a [Scheduler].
+//
+// Jobs with a higher priority are started first. Jobs with the same
+// priority are started in the order that they were provided.
+type Job struct {
+	Name      string
+	Priority  int
+	Resources []string
+	Run       func(ctx context.Context) error
+}
+
+// Scheduler runs jobs concurrently, up to a limit. If MaxConcurrent is
+// less than one, jobs are run one at a time.
+type Scheduler struct {
+	MaxConcurrent int
+}
+
+// Run runs all of the given jobs and waits for them to finish. It returns
+// the error of each job, in the order that the jobs were provided.
+//
+// A job is started when fewer than the maximum number of jobs are running
+// and none of its resources are in use. Resources needed by a job that is
+// waiting are reserved for it, so that jobs with a lower priority can't
+// keep it waiting by claiming them first.
+//
+// If ctx is cancelled, jobs that haven't started are not run, and their
+// error is the cause of the cancellation.
+func (s Scheduler) Run(ctx context.Context, jobs []Job) []error {
+	limit := max(s.MaxConcurrent, 1)
+	errs := make([]error, len(jobs))
+
+	// Order the jobs by priority.
+	pending := make([]int, len(jobs))
+	for i := range jobs {
+		pending[i] = i
+	}
+	slices.SortStableFunc(pending, func(a, b int) int {
+		return cmp.Compare(jobs[b].Priority, jobs[a].Priority)
+	})
+
+	var (
+		mutex    sync.Mutex
+		finished = sync.NewCond(&mutex)
+		running  int
+		inUse    = make(map[string]bool)
+		wg       sync.WaitGroup
+	)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for len(pending) > 0 {
+		if ctx.Err() != nil {
+			for _, i := range pending {
+				errs[i] = context.Cause(ctx)
+			}
+			break
+		}
+
+		// Find the first job that can be started, reserving the resources
+		// of the jobs that can't.
+		next := -1
+		if running < limit {
+			reserved := make(map[string]bool)
+			for p, i := range pending {
+				if available(jobs[i].Resources, inUse, reserved) {
+					next = p
+					break
+				}
+				for _, resource := range jobs[i].Resources {
+					reserved[resource] = true
+				}
+			}
+		}
+		if next < 0 {
+			finished.Wait()
+			continue
+		}
+
+		// Start the job.
+		i := pending[next]
+		pending = slices.Delete(pending, next, next+1)
+		for _, resource := range jobs[i].Resources {
+			inUse[resource] = true
+		}
+		running++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := jobs[i].Run(ctx)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			errs[i] = err
+			for _, resource := range jobs[i].Resources {
+				delete(inUse, resource)
+			}
+			running--
+			finished.Broadcast()
+		}()
+	}
+
+	mutex.Unlock()
+	wg.Wait()
+	mutex.Lock()
+
+	return errs
+}
+
+// available returns true if none of the given resources are in use or
+// reserved.
+func available(resources []string, inUse, reserved map[string]bool) bool {
+	for _, resource := range resources {
+		if inUse[resource] || reserved[resource] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package lbschedule_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbschedule"
+)
+
+func TestSchedulerRun(t *testing.T) {
+	fixtures := []struct {
+		Name      string
+		Priority  int
+		Resources []string
+		Err       error
+	}{
+		{Name: "a", Resources: []string{"mutex:msi"}},
+		{Name: "b", Resources: []string{"mutex:msi"}, Err: errors.New("failed")},
+		{Name: "c", Resources: []string{"package:x"}},
+		{Name: "d", Priority: 10, Resources: []string{"mutex:msi", "package:x"}},
+		{Name: "e"},
+	}
+
+	var (
+		mutex   sync.Mutex
+		inUse   = make(map[string]string)
+		order   []string
+		peak    int
+		running int
+	)
+
+	jobs := make([]lbschedule.Job, len(fixtures))
+	for i, fixture := range fixtures {
+		jobs[i] = lbschedule.Job{
+			Name:      fixture.Name,
+			Priority:  fixture.Priority,
+			Resources: fixture.Resources,
+			Run: func(ctx context.Context) error {
+				mutex.Lock()
+				order = append(order, fixture.Name)
+				running++
+				peak = max(peak, running)
+				for _, resource := range fixture.Resources {
+					if holder, held := inUse[resource]; held {
+						t.Errorf("%s: the %s resource is already in use by %s", fixture.Name, resource, holder)
+					}
+					inUse[resource] = fixture.Name
+				}
+				mutex.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mutex.Lock()
+				for _, resource := range fixture.Resources {
+					delete(inUse, resource)
+				}
+				running--
+				mutex.Unlock()
+				return fixture.Err
+			},
+		}
+	}
+
+	errs := lbschedule.Scheduler{MaxConcurrent: 2}.Run(context.Background(), jobs)
+
+	for i, fixture := range fixtures {
+		if errs[i] != fixture.Err {
+			t.Errorf("%s: got error %v, want %v", fixture.Name, errs[i], fixture.Err)
+		}
+	}
+	if len(order) != len(fixtures) {
+		t.Fatalf("%d jobs ran, want %d", len(order), len(fixtures))
+	}
+	if first := slices.Index(order, "d"); first > 1 || first > slices.Index(order, "a") || first > slices.Index(order, "c") {
+		t.Errorf("the jobs ran in the order %v, but d has the highest priority", order)
+	}
+	if peak > 2 {
+		t.Errorf("%d jobs ran at the same time, want no more than 2", peak)
+	}
+}
@@ -0,0 +1,155 @@
+package lbstate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Checkpoint records the progress of a flow that has started but has not
+// finished, so that it can resume from its last completed action if it is
+// interrupted by a crash or a restart of the system.
+//
+// Invoked is true if the flow was invoked directly, rather than started by
+// another flow. Flows started by an invoked flow are resumed along with it.
+//
+// Digest identifies the definition of the flow when the checkpoint was
+// made. A checkpoint is only honored while the definition of the flow is
+// unchanged.
+//
+// Completed holds the zero-based indices of the actions that completed.
+// VerifiedPackages and ExtractedPackages hold the packages that had been
+// verified and extracted when the checkpoint was last updated.
+type Checkpoint struct {
+	Invoked           bool                 `json:"invoked,omitempty"`
+	Digest            string               `json:"digest"`
+	Started           time.Time            `json:"started"`
+	Updated           time.Time            `json:"updated"`
+	Completed         []int                `json:"completed,omitempty"`
+	VerifiedPackages  []lbdeploy.PackageID `json:"verified-packages,omitempty"`
+	ExtractedPackages []PackageExtraction  `json:"extracted-packages,omitempty"`
+}
+
+// PackageExtraction identifies the temporary directory that holds the
+// extracted files of a package. Dir is the name of the directory within
+// the temporary directory of the run that extracted it.
+type PackageExtraction struct {
+	Package lbdeploy.PackageID `json:"package"`
+	Run     lbevent.RunID      `json:"run"`
+	Dir     string             `json:"dir"`
+}
+
+// NewCheckpoint returns an empty checkpoint for the given flow, which
+// started at the given time.
+func NewCheckpoint(flow lbdeploy.Flow, invoked bool, started time.Time) (Checkpoint, error) {
+	digest, err := flowDigest(flow)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	return Checkpoint{
+		Invoked: invoked,
+		Digest:  digest,
+		Started: started.UTC(),
+		Updated: started.UTC(),
+	}, nil
+}
+
+// Matches returns true if the checkpoint was made by the given definition
+// of a flow.
+func (c Checkpoint) Matches(flow lbdeploy.Flow) bool {
+	digest, err := flowDigest(flow)
+	return err == nil && c.Digest == digest
+}
+
+// Done returns true if the action with the given zero-based index had
+// completed when the checkpoint was made.
+func (c Checkpoint) Done(index int) bool {
+	return slices.Contains(c.Completed, index)
+}
+
+// Extracted returns the IDs of the packages that had been extracted when
+// the checkpoint was made.
+func (c Checkpoint) Extracted() []lbdeploy.PackageID {
+	ids := make([]lbdeploy.PackageID, len(c.ExtractedPackages))
+	for i, extraction := range c.ExtractedPackages {
+		ids[i] = extraction.Package
+	}
+	return ids
+}
+
+// flowDigest returns the hex-encoded SHA-256 digest of the JSON encoding
+// of flow.
+func flowDigest(flow lbdeploy.Flow) (string, error) {
+	data, err := json.Marshal(flow)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// Checkpoint returns the checkpoint of the given flow. It returns false if
+// the flow does not have one.
+func (s Store) Checkpoint(flow lbdeploy.FlowID) (Checkpoint, bool, error) {
+	state, err := s.Load()
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	checkpoint, found := state.Checkpoints[flow]
+	return checkpoint, found, nil
+}
+
+// Interrupted returns the invoked flow with the most recently updated
+// checkpoint. It returns false if no invoked flow has a checkpoint.
+func (s Store) Interrupted() (lbdeploy.FlowID, Checkpoint, bool, error) {
+	state, err := s.Load()
+	if err != nil {
+		return "", Checkpoint{}, false, err
+	}
+	var (
+		latest     lbdeploy.FlowID
+		checkpoint Checkpoint
+		found      bool
+	)
+	for flow, c := range state.Checkpoints {
+		if !c.Invoked {
+			continue
+		}
+		if !found || c.Updated.After(checkpoint.Updated) || (c.Updated.Equal(checkpoint.Updated) && flow < latest) {
+			latest, checkpoint, found = flow, c, true
+		}
+	}
+	return latest, checkpoint, found, nil
+}
+
+// SaveCheckpoint records the checkpoint of the given flow, replacing any
+// checkpoint it already has.
+func (s Store) SaveCheckpoint(flow lbdeploy.FlowID, checkpoint Checkpoint) error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if state.Checkpoints == nil {
+		state.Checkpoints = make(map[lbdeploy.FlowID]Checkpoint)
+	}
+	state.Checkpoints[flow] = checkpoint
+	return s.save(state)
+}
+
+// ClearCheckpoint removes the checkpoint of the given flow, if it has one.
+func (s Store) ClearCheckpoint(flow lbdeploy.FlowID) error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if _, found := state.Checkpoints[flow]; !found {
+		return nil
+	}
+	delete(state.Checkpoints, flow)
+	return s.save(state)
+}
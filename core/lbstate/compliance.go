@@ -0,0 +1,115 @@
+package lbstate
+
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// ComplianceRecord records the history of compliance evaluations for a
+// deployment, along with the remediation attempts that followed them.
+type ComplianceRecord struct {
+	LastEvaluated    time.Time                             `json:"last-evaluated,omitzero"`
+	ConsecutiveDrift int                                   `json:"consecutive-drift,omitempty"`
+	Remediations     map[lbdeploy.FlowID]RemediationRecord `json:"remediations,omitempty"`
+}
+
+// RemediationRecord records the remediation attempts of a flow since its
+// deployment was last found to be in compliance.
+type RemediationRecord struct {
+	LastAttempt time.Time `json:"last-attempt,omitzero"`
+	Attempts    int       `json:"attempts,omitempty"`
+}
+
+// RemediationHold identifies the reason that a remediation flow is being
+// held back. An empty hold means that remediation is permitted.
+type RemediationHold string
+
+// Remediation holds.
+const (
+	RemediationPermitted RemediationHold = ""
+	HoldForThreshold     RemediationHold = "threshold"
+	HoldForCooldown      RemediationHold = "cooldown"
+	HoldForMaxAttempts   RemediationHold = "max-attempts"
+)
+
+// Hold returns the reason that the given flow cannot be remediated at the
+// given time. It returns RemediationPermitted if the flow can be
+// remediated.
+func (record ComplianceRecord) Hold(flow lbdeploy.FlowID, remediation lbdeploy.FlowRemediation, now time.Time) RemediationHold {
+	if record.ConsecutiveDrift < remediation.EffectiveThreshold() {
+		return HoldForThreshold
+	}
+	attempts := record.Remediations[flow]
+	if attempts.Attempts >= remediation.EffectiveMaxAttempts() {
+		return HoldForMaxAttempts
+	}
+	if attempts.Attempts > 0 && now.Before(record.NextAttempt(flow, remediation)) {
+		return HoldForCooldown
+	}
+	return RemediationPermitted
+}
+
+// NextAttempt returns the earliest time that the given flow can be
+// remediated again once its cooldown has elapsed. It returns a zero time if
+// the flow has not been remediated since the deployment was last in
+// compliance.
+func (record ComplianceRecord) NextAttempt(flow lbdeploy.FlowID, remediation lbdeploy.FlowRemediation) time.Time {
+	attempts := record.Remediations[flow]
+	if attempts.Attempts == 0 {
+		return time.Time{}
+	}
+	return attempts.LastAttempt.Add(remediation.EffectiveCooldown())
+}
+
+// Compliance returns the compliance record of the deployment.
+func (s Store) Compliance() (ComplianceRecord, error) {
+	state, err := s.Load()
+	if err != nil {
+		return ComplianceRecord{}, err
+	}
+	return state.Compliance, nil
+}
+
+// RecordEvaluation records the outcome of a compliance evaluation that
+// took place at the given time, and returns the updated compliance record.
+//
+// When the deployment is in compliance, the count of consecutive drift
+// and all remediation attempts are reset.
+func (s Store) RecordEvaluation(compliant bool, evaluated time.Time) (ComplianceRecord, error) {
+	state, err := s.Load()
+	if err != nil {
+		return ComplianceRecord{}, err
+	}
+
+	record := state.Compliance
+	record.LastEvaluated = evaluated.UTC()
+	if compliant {
+		record.ConsecutiveDrift = 0
+		record.Remediations = nil
+	} else {
+		record.ConsecutiveDrift++
+	}
+	state.Compliance = record
+
+	return record, s.save(state)
+}
+
+// RecordRemediation records that a remediation attempt of the given flow
+// was started at the given time.
+func (s Store) RecordRemediation(flow lbdeploy.FlowID, attempted time.Time) error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if state.Compliance.Remediations == nil {
+		state.Compliance.Remediations = make(map[lbdeploy.FlowID]RemediationRecord)
+	}
+
+	record := state.Compliance.Remediations[flow]
+	record.LastAttempt = attempted.UTC()
+	record.Attempts++
+	state.Compliance.Remediations[flow] = record
+
+	return s.save(state)
+}
@@ -0,0 +1,319 @@
+// Package lbstate persists the execution history of deployment flows, so
+// that it survives between invocations of LeafBridge.
+//
+// The state of each deployment is kept in its own JSON file. Where the
+// files are kept is determined by each platform.
+package lbstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// DeploymentState holds the persistent state of a deployment.
+//
+// Logon maps each logon flow that has run for a user to the time of the
+// registration that it ran for. It is only present in user state.
+//
+// Checkpoints holds the progress of flows that have started but have not
+// finished.
+type DeploymentState struct {
+	Flows       map[lbdeploy.FlowID]FlowRecord `json:"flows,omitempty"`
+	Compliance  ComplianceRecord               `json:"compliance,omitzero"`
+	Logon       map[lbdeploy.FlowID]time.Time  `json:"logon,omitempty"`
+	Checkpoints map[lbdeploy.FlowID]Checkpoint `json:"checkpoints,omitempty"`
+}
+
+// FlowRecord records the execution history of a flow.
+//
+// FirstDeferred and Deferrals describe the deferrals of a disruptive flow
+// since it last completed.
+//
+// Durations holds the amount of time that each of the flow's actions took
+// when the flow last completed. Actions that were skipped have a duration
+// of zero. The durations are used to estimate the progress of later runs.
+type FlowRecord struct {
+	LastCompleted time.Time           `json:"last-completed,omitzero"`
+	Completions   int                 `json:"completions,omitempty"`
+	FirstDeferred time.Time           `json:"first-deferred,omitzero"`
+	Deferrals     int                 `json:"deferrals,omitempty"`
+	Durations     []datatype.Duration `json:"durations,omitempty"`
+}
+
+// ActionDurations returns the durations of the flow's actions when it last
+// completed. It returns nil if they have not been recorded.
+func (record FlowRecord) ActionDurations() []time.Duration {
+	if len(record.Durations) == 0 {
+		return nil
+	}
+	durations := make([]time.Duration, len(record.Durations))
+	for i, d := range record.Durations {
+		durations[i] = time.Duration(d)
+	}
+	return durations
+}
+
+// Permits returns true if the given frequency permits the flow to run at
+// the given time.
+func (record FlowRecord) Permits(frequency lbdeploy.FlowFrequency, now time.Time) bool {
+	if record.Completions == 0 {
+		return true
+	}
+	if frequency.Once {
+		return false
+	}
+	if frequency.Interval > 0 {
+		return !now.Before(record.NextRun(frequency))
+	}
+	return true
+}
+
+// DeadlinePassed returns true if the given idle requirement has a deadline
+// that passed at the given time.
+func (record FlowRecord) DeadlinePassed(idle lbdeploy.FlowIdle, now time.Time) bool {
+	if idle.Deadline <= 0 || record.Deferrals == 0 {
+		return false
+	}
+	return !now.Before(record.Deadline(idle))
+}
+
+// Deadline returns the time after which the flow is no longer deferred.
+// It returns a zero time if the flow has not been deferred, or if its
+// idle requirement does not have a deadline.
+func (record FlowRecord) Deadline(idle lbdeploy.FlowIdle) time.Time {
+	if idle.Deadline <= 0 || record.Deferrals == 0 {
+		return time.Time{}
+	}
+	return record.FirstDeferred.Add(time.Duration(idle.Deadline))
+}
+
+// NextRun returns the earliest time that the given frequency permits the
+// flow to run again. It returns a zero time if the flow will never run
+// again, or if it can run at any time.
+func (record FlowRecord) NextRun(frequency lbdeploy.FlowFrequency) time.Time {
+	if record.Completions == 0 || frequency.Interval <= 0 {
+		return time.Time{}
+	}
+	return record.LastCompleted.Add(time.Duration(frequency.Interval))
+}
+
+// Store reads and writes the state of a deployment in a JSON file.
+//
+// The file is always opened through an [os.Root] for its directory, so
+// that its name can't refer to a file outside of that directory.
+//
+// If the store has a trust function, the file is only read if the
+// function returns true for it. Otherwise it is treated as though it does
+// not exist, and is replaced when the state is next saved.
+type Store struct {
+	dir   string
+	name  string
+	trust TrustFunc
+}
+
+// TrustFunc returns true if the state file at path can be trusted.
+type TrustFunc func(path string) bool
+
+// NewStore returns a store that keeps its state in the file at the given
+// path.
+func NewStore(path string) Store {
+	return Store{dir: filepath.Dir(path), name: filepath.Base(path)}
+}
+
+// WithTrust returns a copy of the store that only reads its file if trust
+// returns true for it.
+func (s Store) WithTrust(trust TrustFunc) Store {
+	s.trust = trust
+	return s
+}
+
+// Path returns the path of the store's file.
+func (s Store) Path() string {
+	return filepath.Join(s.dir, s.name)
+}
+
+// Load reads the state from the store. If the store's file does not exist,
+// an empty state is returned.
+func (s Store) Load() (DeploymentState, error) {
+	if err := s.checkName(); err != nil {
+		return DeploymentState{}, err
+	}
+
+	root, err := os.OpenRoot(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return DeploymentState{}, nil
+	}
+	if err != nil {
+		return DeploymentState{}, err
+	}
+	defer root.Close()
+
+	file, err := root.Open(s.name)
+	if errors.Is(err, os.ErrNotExist) {
+		return DeploymentState{}, nil
+	}
+	if err != nil {
+		return DeploymentState{}, err
+	}
+	defer file.Close()
+
+	// Ignore state that can't be trusted.
+	if s.trust != nil && !s.trust(s.Path()) {
+		return DeploymentState{}, nil
+	}
+
+	var state DeploymentState
+	if err := json.NewDecoder(file).Decode(&state); err != nil {
+		return DeploymentState{}, fmt.Errorf("failed to parse the state file \"%s\": %w", s.Path(), err)
+	}
+	return state, nil
+}
+
+// Flow returns the record of the given flow.
+func (s Store) Flow(flow lbdeploy.FlowID) (FlowRecord, error) {
+	state, err := s.Load()
+	if err != nil {
+		return FlowRecord{}, err
+	}
+	return state.Flows[flow], nil
+}
+
+// RecordCompletion records that the given flow completed successfully at
+// the given time.
+func (s Store) RecordCompletion(flow lbdeploy.FlowID, completed time.Time) error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if state.Flows == nil {
+		state.Flows = make(map[lbdeploy.FlowID]FlowRecord)
+	}
+
+	record := state.Flows[flow]
+	record.LastCompleted = completed.UTC()
+	record.Completions++
+	record.FirstDeferred = time.Time{}
+	record.Deferrals = 0
+	state.Flows[flow] = record
+
+	return s.save(state)
+}
+
+// RecordDurations records the amount of time that each of the given flow's
+// actions took when it last completed.
+func (s Store) RecordDurations(flow lbdeploy.FlowID, durations []time.Duration) error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if state.Flows == nil {
+		state.Flows = make(map[lbdeploy.FlowID]FlowRecord)
+	}
+
+	record := state.Flows[flow]
+	record.Durations = make([]datatype.Duration, len(durations))
+	for i, d := range durations {
+		record.Durations[i] = datatype.Duration(d.Round(time.Millisecond))
+	}
+	state.Flows[flow] = record
+
+	return s.save(state)
+}
+
+// RecordDeferral records that the given flow was deferred at the given
+// time, and returns the updated record.
+func (s Store) RecordDeferral(flow lbdeploy.FlowID, deferred time.Time) (FlowRecord, error) {
+	state, err := s.Load()
+	if err != nil {
+		return FlowRecord{}, err
+	}
+	if state.Flows == nil {
+		state.Flows = make(map[lbdeploy.FlowID]FlowRecord)
+	}
+
+	record := state.Flows[flow]
+	if record.Deferrals == 0 {
+		record.FirstDeferred = deferred.UTC()
+	}
+	record.Deferrals++
+	state.Flows[flow] = record
+
+	return record, s.save(state)
+}
+
+// ClearDeferrals clears the deferrals of the given flow, if it has any.
+func (s Store) ClearDeferrals(flow lbdeploy.FlowID) error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	record, found := state.Flows[flow]
+	if !found || record.Deferrals == 0 {
+		return nil
+	}
+	record.FirstDeferred = time.Time{}
+	record.Deferrals = 0
+	state.Flows[flow] = record
+
+	return s.save(state)
+}
+
+// save writes the state to a temporary file and then moves it into place,
+// so that the store's file is never left partially written.
+func (s Store) save(state DeploymentState) error {
+	if err := s.checkName(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(s.dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	temp := fmt.Sprintf("%s.%s.tmp", s.name, strconv.FormatUint(rand.Uint64(), 36))
+	file, err := root.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		// Both names are local to the root's directory, and renaming a
+		// symbolic link replaces the link rather than its target.
+		err = os.Rename(filepath.Join(s.dir, temp), s.Path())
+	}
+	if err != nil {
+		root.Remove(temp)
+	}
+	return err
+}
+
+// checkName returns a non-nil error if the name of the store's file is not
+// a single, local file name.
+func (s Store) checkName() error {
+	if !filepath.IsLocal(s.name) || filepath.Base(s.name) != s.name {
+		return fmt.Errorf("the state file name \"%s\" is not valid", s.name)
+	}
+	return nil
+}
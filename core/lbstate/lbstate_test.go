@@ -0,0 +1,326 @@
+package lbstate_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbstate"
+)
+
+func TestFlowRecordPermits(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	week := datatype.Duration(7 * 24 * time.Hour)
+
+	fixtures := []struct {
+		Name      string
+		Frequency lbdeploy.FlowFrequency
+		Record    lbstate.FlowRecord
+		Permits   bool
+	}{
+		{Name: "once-never-run", Frequency: lbdeploy.FlowFrequency{Once: true}, Permits: true},
+		{Name: "once-already-run", Frequency: lbdeploy.FlowFrequency{Once: true}, Record: lbstate.FlowRecord{LastCompleted: now.AddDate(-1, 0, 0), Completions: 1}, Permits: false},
+		{Name: "interval-never-run", Frequency: lbdeploy.FlowFrequency{Interval: week}, Permits: true},
+		{Name: "interval-recent", Frequency: lbdeploy.FlowFrequency{Interval: week}, Record: lbstate.FlowRecord{LastCompleted: now.AddDate(0, 0, -3), Completions: 4}, Permits: false},
+		{Name: "interval-elapsed", Frequency: lbdeploy.FlowFrequency{Interval: week}, Record: lbstate.FlowRecord{LastCompleted: now.AddDate(0, 0, -7), Completions: 4}, Permits: true},
+		{Name: "unlimited", Record: lbstate.FlowRecord{LastCompleted: now, Completions: 9}, Permits: true},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			if got := fixture.Record.Permits(fixture.Frequency, now); got != fixture.Permits {
+				t.Errorf("Permits returned %t, expected %t", got, fixture.Permits)
+			}
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := lbstate.NewStore(filepath.Join(t.TempDir(), "state", "example.json"))
+
+	record, err := store.Flow("install")
+	if err != nil {
+		t.Fatalf("failed to read an empty store: %v", err)
+	}
+	if record.Completions != 0 {
+		t.Fatalf("an empty store reported %d completions", record.Completions)
+	}
+
+	completed := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	for range 2 {
+		if err := store.RecordCompletion("install", completed); err != nil {
+			t.Fatalf("failed to record a completion: %v", err)
+		}
+	}
+
+	record, err = store.Flow("install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Completions != 2 || !record.LastCompleted.Equal(completed) {
+		t.Errorf("unexpected record: %+v", record)
+	}
+
+	// Action durations are kept alongside the completions.
+	if err := store.RecordDurations("install", []time.Duration{0, 1500 * time.Millisecond}); err != nil {
+		t.Fatalf("failed to record action durations: %v", err)
+	}
+	record, err = store.Flow("install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if durations := record.ActionDurations(); record.Completions != 2 || !slices.Equal(durations, []time.Duration{0, 1500 * time.Millisecond}) {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
+
+func TestStoreEscape(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "outside.json")
+	if err := os.WriteFile(outside, []byte(`{"flows":{"install":{"completions":5}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stateDir := filepath.Join(dir, "state")
+	if err := os.Mkdir(stateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(stateDir, "example.json")); err != nil {
+		t.Skipf("symbolic links are not available: %v", err)
+	}
+
+	// The state file is a link that leads out of its directory, so it
+	// must not be followed.
+	store := lbstate.NewStore(filepath.Join(stateDir, "example.json"))
+	if _, err := store.Flow("install"); err == nil {
+		t.Error("the store followed a link out of its directory")
+	}
+
+	if err := store.RecordCompletion("install", time.Now()); err == nil {
+		t.Error("the store recorded a completion through a link out of its directory")
+	}
+	if data, err := os.ReadFile(outside); err != nil || !strings.Contains(string(data), `"completions":5`) {
+		t.Errorf("the file outside of the state directory was modified: %s", data)
+	}
+
+	// Names that aren't local are rejected.
+	for _, name := range []string{"..", "."} {
+		store := lbstate.NewStore(stateDir + string(filepath.Separator) + name)
+		if _, err := store.Flow("install"); err == nil {
+			t.Errorf("%s: the store accepted a name that is not local", name)
+		}
+	}
+}
+
+func TestStoreTrust(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "example.json")
+	if err := os.WriteFile(path, []byte(`{"flows":{"install":{"completions":5}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	trusted := false
+	store := lbstate.NewStore(path).WithTrust(func(string) bool { return trusted })
+
+	// State that isn't trusted is treated as though it does not exist.
+	record, err := store.Flow("install")
+	if err != nil {
+		t.Fatalf("failed to read an untrusted store: %v", err)
+	}
+	if record.Completions != 0 {
+		t.Fatalf("an untrusted store reported %d completions", record.Completions)
+	}
+
+	// Saving replaces the untrusted state.
+	if err := store.RecordCompletion("install", time.Now()); err != nil {
+		t.Fatalf("failed to record a completion: %v", err)
+	}
+	trusted = true
+	record, err = store.Flow("install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Completions != 1 {
+		t.Errorf("the store reported %d completions after replacing untrusted state, expected 1", record.Completions)
+	}
+}
+
+func TestComplianceRecordHold(t *testing.T) {
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	remediation := lbdeploy.FlowRemediation{OnDrift: true, Threshold: 2, Cooldown: datatype.Duration(time.Hour), MaxAttempts: 2}
+
+	fixtures := []struct {
+		Name   string
+		Record lbstate.ComplianceRecord
+		Hold   lbstate.RemediationHold
+	}{
+		{Name: "below-threshold", Record: lbstate.ComplianceRecord{ConsecutiveDrift: 1}, Hold: lbstate.HoldForThreshold},
+		{Name: "first-attempt", Record: lbstate.ComplianceRecord{ConsecutiveDrift: 2}, Hold: lbstate.RemediationPermitted},
+		{Name: "cooling-down", Record: lbstate.ComplianceRecord{ConsecutiveDrift: 3, Remediations: map[lbdeploy.FlowID]lbstate.RemediationRecord{
+			"install": {LastAttempt: now.Add(-time.Minute * 30), Attempts: 1},
+		}}, Hold: lbstate.HoldForCooldown},
+		{Name: "cooled-down", Record: lbstate.ComplianceRecord{ConsecutiveDrift: 3, Remediations: map[lbdeploy.FlowID]lbstate.RemediationRecord{
+			"install": {LastAttempt: now.Add(-time.Hour), Attempts: 1},
+		}}, Hold: lbstate.RemediationPermitted},
+		{Name: "exhausted", Record: lbstate.ComplianceRecord{ConsecutiveDrift: 9, Remediations: map[lbdeploy.FlowID]lbstate.RemediationRecord{
+			"install": {LastAttempt: now.AddDate(0, 0, -1), Attempts: 2},
+		}}, Hold: lbstate.HoldForMaxAttempts},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			if got := fixture.Record.Hold("install", remediation, now); got != fixture.Hold {
+				t.Errorf("Hold returned \"%s\", expected \"%s\"", got, fixture.Hold)
+			}
+		})
+	}
+}
+
+func TestStoreCompliance(t *testing.T) {
+	store := lbstate.NewStore(filepath.Join(t.TempDir(), "example.json"))
+	now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	for range 2 {
+		if _, err := store.RecordEvaluation(false, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.RecordRemediation("install", now); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := store.Compliance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.ConsecutiveDrift != 2 || record.Remediations["install"].Attempts != 1 {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+
+	record, err = store.RecordEvaluation(true, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.ConsecutiveDrift != 0 || len(record.Remediations) != 0 {
+		t.Errorf("compliance did not reset the record: %+v", record)
+	}
+}
+
+func TestStoreDeferral(t *testing.T) {
+	store := lbstate.NewStore(filepath.Join(t.TempDir(), "example.json"))
+	first := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	idle := lbdeploy.FlowIdle{Deadline: datatype.Duration(48 * time.Hour)}
+
+	for i := range 3 {
+		record, err := store.RecordDeferral("upgrade", first.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !record.FirstDeferred.Equal(first) || record.Deferrals != i+1 {
+			t.Fatalf("unexpected record after deferral %d: %+v", i+1, record)
+		}
+	}
+
+	record, err := store.Flow("upgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.DeadlinePassed(idle, first.Add(47*time.Hour)) {
+		t.Error("the deadline passed too early")
+	}
+	if !record.DeadlinePassed(idle, first.Add(48*time.Hour)) {
+		t.Error("the deadline did not pass")
+	}
+
+	if err := store.RecordCompletion("upgrade", first.Add(49*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if record, _ = store.Flow("upgrade"); record.Deferrals != 0 || !record.FirstDeferred.IsZero() {
+		t.Errorf("completion did not clear the deferrals: %+v", record)
+	}
+}
+
+func TestStoreLogon(t *testing.T) {
+	store := lbstate.NewStore(filepath.Join(t.TempDir(), "state", "example.json"))
+	first := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	steps := []struct {
+		Record     time.Time
+		Registered time.Time
+		Pending    bool
+	}{
+		{Registered: first, Pending: true},
+		{Record: first, Registered: first, Pending: false},
+		{Registered: second, Pending: true},
+		{Record: second, Registered: second, Pending: false},
+	}
+
+	for i, step := range steps {
+		if !step.Record.IsZero() {
+			if err := store.RecordLogon("configure-user", step.Record); err != nil {
+				t.Fatalf("step %d: failed to record a logon flow: %v", i, err)
+			}
+		}
+		pending, err := store.LogonPending("configure-user", step.Registered)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if pending != step.Pending {
+			t.Errorf("step %d: LogonPending returned %t, expected %t", i, pending, step.Pending)
+		}
+	}
+}
+
+func TestStoreCheckpoint(t *testing.T) {
+	store := lbstate.NewStore(filepath.Join(t.TempDir(), "example.json"))
+	started := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	flow := lbdeploy.Flow{Actions: []lbdeploy.Action{{Type: lbdeploy.ActionPreparePackage}, {Type: lbdeploy.ActionStartFlow, Flow: "configure"}}}
+
+	checkpoint, err := lbstate.NewCheckpoint(flow, true, started)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint.Completed = append(checkpoint.Completed, 0)
+	checkpoint.Updated = started.Add(time.Minute)
+	if err := store.SaveCheckpoint("install", checkpoint); err != nil {
+		t.Fatalf("failed to save a checkpoint: %v", err)
+	}
+
+	// Flows started by the invoked flow are not reported as interrupted.
+	nested, err := lbstate.NewCheckpoint(lbdeploy.Flow{}, false, started.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveCheckpoint("configure", nested); err != nil {
+		t.Fatal(err)
+	}
+
+	id, loaded, found, err := store.Interrupted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || id != "install" {
+		t.Fatalf("Interrupted returned \"%s\" (found: %t), expected \"install\"", id, found)
+	}
+	if !loaded.Matches(flow) || !loaded.Done(0) || loaded.Done(1) {
+		t.Errorf("unexpected checkpoint: %+v", loaded)
+	}
+
+	// A checkpoint does not match a changed flow.
+	changed := flow
+	changed.Actions = flow.Actions[:1]
+	if loaded.Matches(changed) {
+		t.Errorf("the checkpoint matched a flow with a different definition")
+	}
+
+	if err := store.ClearCheckpoint("install"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, found, err := store.Interrupted(); err != nil || found {
+		t.Errorf("Interrupted reported a flow after its checkpoint was cleared (err: %v)", err)
+	}
+}
@@ -0,0 +1,31 @@
+package lbstate
+
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// LogonPending returns true if the given logon flow has not yet run for the
+// registration made at the given time.
+func (s Store) LogonPending(flow lbdeploy.FlowID, registered time.Time) (bool, error) {
+	state, err := s.Load()
+	if err != nil {
+		return false, err
+	}
+	return !state.Logon[flow].Equal(registered.UTC()), nil
+}
+
+// RecordLogon records that the given logon flow has run for the
+// registration made at the given time.
+func (s Store) RecordLogon(flow lbdeploy.FlowID, registered time.Time) error {
+	state, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if state.Logon == nil {
+		state.Logon = make(map[lbdeploy.FlowID]time.Time)
+	}
+	state.Logon[flow] = registered.UTC()
+	return s.save(state)
+}
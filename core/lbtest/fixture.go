@@ -0,0 +1,249 @@
+// Package lbtest runs LeafBridge deployments against a simulated system.
+//
+// A fixture describes the state of the simulated system and the actions
+// that are expected to run when a flow is invoked. Deployment authors can
+// use fixtures to test their deployment documents without a disposable
+// machine. Commands, scripts and file operations are never executed;
+// their effects on installed applications are simulated instead.
+package lbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// Fixture describes a test of a deployment. It identifies the flow to be
+// invoked, the state of the simulated system and the expected outcome.
+type Fixture struct {
+	Name   string          `json:"name,omitempty"`
+	Flow   lbdeploy.FlowID `json:"flow"`
+	System System          `json:"system,omitzero"`
+	Expect Expectation     `json:"expect,omitzero"`
+}
+
+// System describes the state of a simulated system. Resources are
+// identified by their resource IDs within the deployment. Running
+// processes are described by their attributes. Files with content are
+// present on the simulated system; other files are empty.
+type System struct {
+	Directories    []lbdeploy.DirectoryResourceID                     `json:"directories,omitzero"`
+	Files          []lbdeploy.FileResourceID                          `json:"files,omitzero"`
+	FileContents   map[lbdeploy.FileResourceID]string                 `json:"file-contents,omitzero"`
+	RegistryKeys   []lbdeploy.RegistryKeyResourceID                   `json:"registry-keys,omitzero"`
+	RegistryValues map[lbdeploy.RegistryValueResourceID]lbvalue.Value `json:"registry-values,omitzero"`
+	Apps           map[lbdeploy.AppID]datatype.Version                `json:"apps,omitzero"`
+	Processes      []Process                                          `json:"processes,omitzero"`
+	Mutexes        []lbdeploy.MutexID                                 `json:"mutexes,omitzero"`
+	Certificates   []lbdeploy.CertificateID                           `json:"certificates,omitzero"`
+	Hardware       Hardware                                           `json:"hardware,omitzero"`
+	Locale         Locale                                             `json:"locale,omitzero"`
+	Accounts       Accounts                                           `json:"accounts,omitzero"`
+}
+
+// Hardware describes the hardware and firmware of a simulated system.
+// Memory is measured in mebibytes. A hypervisor is only present on
+// virtual machines. The power plan is a plan name or the GUID of a power
+// scheme, and is assumed to be balanced if it is not provided.
+type Hardware struct {
+	Memory     int64               `json:"memory,omitempty"`
+	Processors int                 `json:"processors,omitempty"`
+	TPMVersion int                 `json:"tpm-version,omitempty"`
+	SecureBoot bool                `json:"secure-boot,omitempty"`
+	Hypervisor lbdeploy.Hypervisor `json:"hypervisor,omitempty"`
+	BitLocker  bool                `json:"bitlocker,omitempty"`
+	PowerPlan  lbdeploy.PowerPlan  `json:"power-plan,omitempty"`
+}
+
+// Locale describes the language and regional settings of a simulated
+// system. Keyboard layouts are identified by layout identifiers or
+// language tags.
+type Locale struct {
+	UILanguage      string   `json:"ui-language,omitempty"`
+	SystemLocale    string   `json:"system-locale,omitempty"`
+	KeyboardLayouts []string `json:"keyboard-layouts,omitzero"`
+	TimeZone        string   `json:"time-zone,omitempty"`
+}
+
+// Accounts describes the local accounts of a simulated system. Groups and
+// user rights map to the accounts that hold them. Names are compared
+// without regard to case, but they are not resolved, so an account must be
+// named the same way by the system and by the deployment.
+type Accounts struct {
+	Users  []string            `json:"users,omitzero"`
+	Groups map[string][]string `json:"groups,omitzero"`
+	Rights map[string][]string `json:"rights,omitzero"`
+}
+
+// setUser adds or removes a local user.
+func (a *Accounts) setUser(name string, present bool) {
+	a.Users = setName(a.Users, name, present)
+}
+
+// setGroupMember adds an account to, or removes it from, a local group.
+// The group is created if necessary.
+func (a *Accounts) setGroupMember(group, account string, present bool) {
+	a.Groups = setMapName(a.Groups, group, account, present)
+}
+
+// setUserRight grants or revokes a user right.
+func (a *Accounts) setUserRight(right, account string, present bool) {
+	a.Rights = setMapName(a.Rights, right, account, present)
+}
+
+// clone returns a deep copy of the accounts.
+func (a Accounts) clone() Accounts {
+	clone := Accounts{Users: slices.Clone(a.Users)}
+	if a.Groups != nil {
+		clone.Groups = make(map[string][]string, len(a.Groups))
+		for group, members := range a.Groups {
+			clone.Groups[group] = slices.Clone(members)
+		}
+	}
+	if a.Rights != nil {
+		clone.Rights = make(map[string][]string, len(a.Rights))
+		for right, accounts := range a.Rights {
+			clone.Rights[right] = slices.Clone(accounts)
+		}
+	}
+	return clone
+}
+
+// lookupName returns the entry of m whose key matches name without regard
+// to case.
+func lookupName(m map[string][]string, name string) (key string, names []string, found bool) {
+	for key, names := range m {
+		if strings.EqualFold(key, name) {
+			return key, names, true
+		}
+	}
+	return "", nil, false
+}
+
+// containsName returns true if names contains name without regard to
+// case.
+func containsName(names []string, name string) bool {
+	return slices.ContainsFunc(names, func(s string) bool {
+		return strings.EqualFold(s, name)
+	})
+}
+
+// setName adds name to names or removes it, without regard to case.
+func setName(names []string, name string, present bool) []string {
+	names = slices.DeleteFunc(names, func(s string) bool {
+		return strings.EqualFold(s, name)
+	})
+	if present {
+		names = append(names, name)
+	}
+	return names
+}
+
+// setMapName adds name to, or removes it from, the entry of m for key.
+func setMapName(m map[string][]string, key, name string, present bool) map[string][]string {
+	if m == nil {
+		m = make(map[string][]string)
+	}
+	if existing, _, found := lookupName(m, key); found {
+		key = existing
+	}
+	m[key] = setName(m[key], name, present)
+	return m
+}
+
+// Process describes a simulated process. In JSON, a process may also be
+// provided as a string holding its name.
+type Process struct {
+	Name        string `json:"name"`
+	Path        string `json:"path,omitempty"`
+	CommandLine string `json:"command-line,omitempty"`
+	User        string `json:"user,omitempty"`
+	Session     string `json:"session,omitempty"`
+}
+
+// UnmarshalJSON unmarshals a process from a name or from an object.
+func (p *Process) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = Process{Name: name}
+		return nil
+	}
+	type plain Process
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// ProcessAttribute returns the value of the given process attribute.
+func (p Process) ProcessAttribute(attr lbdeploy.ProcessAttributeID) (string, error) {
+	switch attr {
+	case lbdeploy.ProcessName:
+		return p.Name, nil
+	case lbdeploy.ProcessPath:
+		return p.Path, nil
+	case lbdeploy.ProcessCommandLine:
+		return p.CommandLine, nil
+	case lbdeploy.ProcessUser:
+		return p.User, nil
+	case lbdeploy.ProcessSession:
+		return p.Session, nil
+	default:
+		return "", fmt.Errorf("the process attribute \"%s\" is not recognized", attr)
+	}
+}
+
+// Expectation describes the expected outcome of a simulated flow.
+//
+// Run lists every action that is expected to run, in order. Skipped lists
+// actions that are expected to be skipped. If Error is true, the flow is
+// expected to fail.
+type Expectation struct {
+	Run     []StepID `json:"run,omitzero"`
+	Skipped []StepID `json:"skipped,omitzero"`
+	Error   bool     `json:"error,omitempty"`
+}
+
+// Check compares the outcome of a simulation with the expectation. It
+// returns a list of discrepancies, which is empty if the outcome matched.
+func (expect Expectation) Check(steps []Step, err error) (problems []string) {
+	switch {
+	case expect.Error && err == nil:
+		problems = append(problems, "the flow was expected to fail, but it succeeded")
+	case !expect.Error && err != nil:
+		problems = append(problems, fmt.Sprintf("the flow failed: %v", err))
+	}
+
+	var ran, skipped []StepID
+	for _, step := range steps {
+		switch step.Outcome {
+		case OutcomeRun:
+			ran = append(ran, step.ID())
+		case OutcomeSkipped:
+			skipped = append(skipped, step.ID())
+		}
+	}
+
+	if !slices.Equal(ran, expect.Run) {
+		problems = append(problems, fmt.Sprintf("expected actions [%s] to run, but [%s] ran", joinSteps(expect.Run), joinSteps(ran)))
+	}
+
+	for _, id := range expect.Skipped {
+		if !slices.Contains(skipped, id) {
+			problems = append(problems, fmt.Sprintf("expected action %s to be skipped, but it was not", id))
+		}
+	}
+
+	return problems
+}
+
+// joinSteps returns a comma-separated list of step IDs.
+func joinSteps(ids []StepID) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = string(id)
+	}
+	return strings.Join(s, ", ")
+}
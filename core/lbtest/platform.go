@@ -0,0 +1,328 @@
+package lbtest
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/idset"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// Platform is a simulated system that implements lbplatform.Platform. Its
+// state is seeded from a System description.
+//
+// Applications passed to the platform are identified by matching their
+// definitions against the applications of the deployment.
+type Platform struct {
+	directories    idset.SetOf[lbdeploy.DirectoryResourceID]
+	files          idset.SetOf[lbdeploy.FileResourceID]
+	fileContents   map[lbdeploy.FileResourceID]string
+	registryKeys   idset.SetOf[lbdeploy.RegistryKeyResourceID]
+	registryValues map[lbdeploy.RegistryValueResourceID]lbvalue.Value
+	apps           map[lbdeploy.AppID]datatype.Version
+	appIDs         map[lbdeploy.Application]lbdeploy.AppID
+	processes      []Process
+	mutexes        idset.SetOf[string]
+	certificates   map[lbdeploy.CertificateResource]bool
+	hardware       Hardware
+	locale         Locale
+	accounts       Accounts
+}
+
+// Verify that Platform satisfies the lbplatform.Platform interface.
+var _ lbplatform.Platform = (*Platform)(nil)
+
+// NewPlatform returns a simulated system for the given deployment, seeded
+// with the given state.
+func NewPlatform(dep lbdeploy.Deployment, system System) (*Platform, error) {
+	p := &Platform{
+		directories:    make(idset.SetOf[lbdeploy.DirectoryResourceID]),
+		files:          make(idset.SetOf[lbdeploy.FileResourceID]),
+		fileContents:   maps.Clone(system.FileContents),
+		registryKeys:   make(idset.SetOf[lbdeploy.RegistryKeyResourceID]),
+		registryValues: maps.Clone(system.RegistryValues),
+		apps:           make(map[lbdeploy.AppID]datatype.Version),
+		appIDs:         make(map[lbdeploy.Application]lbdeploy.AppID),
+		processes:      slices.Clone(system.Processes),
+		mutexes:        make(idset.SetOf[string]),
+		certificates:   make(map[lbdeploy.CertificateResource]bool),
+		hardware:       system.Hardware,
+		locale:         system.Locale,
+		accounts:       system.Accounts.clone(),
+	}
+	if p.registryValues == nil {
+		p.registryValues = make(map[lbdeploy.RegistryValueResourceID]lbvalue.Value)
+	}
+	for _, id := range system.Directories {
+		p.directories.Add(id)
+	}
+	for _, id := range system.Files {
+		p.files.Add(id)
+	}
+	for id := range system.FileContents {
+		p.files.Add(id)
+	}
+	for _, id := range system.RegistryKeys {
+		p.registryKeys.Add(id)
+	}
+	for id, version := range system.Apps {
+		if _, found := dep.Apps[id]; !found {
+			return nil, fmt.Errorf("the \"%s\" app is not defined in the deployment", id)
+		}
+		p.apps[id] = version
+	}
+	for id, app := range dep.Apps {
+		p.appIDs[app] = id
+	}
+	for _, id := range system.Mutexes {
+		mutex, found := dep.Resources.Mutexes[id]
+		if !found {
+			return nil, fmt.Errorf("the \"%s\" mutex is not defined in the deployment", id)
+		}
+		name, err := mutex.ObjectName()
+		if err != nil {
+			return nil, fmt.Errorf("mutex \"%s\": %w", id, err)
+		}
+		p.mutexes.Add(name)
+	}
+	for _, id := range system.Certificates {
+		cert, found := dep.Resources.Certificates[id]
+		if !found {
+			return nil, fmt.Errorf("the \"%s\" certificate is not defined in the deployment", id)
+		}
+		p.certificates[cert] = true
+	}
+	return p, nil
+}
+
+// Install marks an application as installed.
+func (p *Platform) Install(app lbdeploy.AppID) {
+	if _, installed := p.apps[app]; !installed {
+		p.apps[app] = ""
+	}
+}
+
+// Uninstall marks an application as not installed.
+func (p *Platform) Uninstall(app lbdeploy.AppID) {
+	delete(p.apps, app)
+}
+
+// FileSystem returns the simulated file system.
+func (p *Platform) FileSystem(lbdeploy.FileSystemResources) lbplatform.FileSystem {
+	return fileSystem{p}
+}
+
+// Registry returns the simulated registry.
+func (p *Platform) Registry(lbdeploy.RegistryResources) lbplatform.Registry {
+	return registry{p}
+}
+
+// Apps returns the simulated application inventory.
+func (p *Platform) Apps() lbplatform.AppDetector {
+	return appDetector{p}
+}
+
+// Processes returns the simulated process table.
+func (p *Platform) Processes() lbplatform.ProcessController {
+	return processController{p}
+}
+
+type fileSystem struct{ p *Platform }
+
+func (fs fileSystem) DirectoryExists(id lbdeploy.DirectoryResourceID) (bool, error) {
+	return fs.p.directories.Contains(id), nil
+}
+
+func (fs fileSystem) FileExists(id lbdeploy.FileResourceID) (bool, error) {
+	return fs.p.files.Contains(id), nil
+}
+
+func (fs fileSystem) OpenFile(id lbdeploy.FileResourceID) (io.ReadCloser, error) {
+	if !fs.p.files.Contains(id) {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(fs.p.fileContents[id])), nil
+}
+
+type registry struct{ p *Platform }
+
+func (r registry) KeyExists(id lbdeploy.RegistryKeyResourceID) (bool, error) {
+	return r.p.registryKeys.Contains(id), nil
+}
+
+func (r registry) ValueExists(id lbdeploy.RegistryValueResourceID) (bool, error) {
+	_, found := r.p.registryValues[id]
+	return found, nil
+}
+
+func (r registry) GetValue(id lbdeploy.RegistryValueResourceID) (lbvalue.Value, error) {
+	value, found := r.p.registryValues[id]
+	if !found {
+		return lbvalue.Value{}, fs.ErrNotExist
+	}
+	return value, nil
+}
+
+type appDetector struct{ p *Platform }
+
+func (d appDetector) IsInstalled(app lbdeploy.Application) (bool, error) {
+	id, err := d.lookup(app)
+	if err != nil {
+		return false, err
+	}
+	_, installed := d.p.apps[id]
+	return installed, nil
+}
+
+func (d appDetector) Version(app lbdeploy.Application) (datatype.Version, error) {
+	id, err := d.lookup(app)
+	if err != nil {
+		return "", err
+	}
+	return d.p.apps[id], nil
+}
+
+func (d appDetector) lookup(app lbdeploy.Application) (lbdeploy.AppID, error) {
+	id, found := d.p.appIDs[app]
+	if !found {
+		return "", fmt.Errorf("the \"%s\" application is not defined in the deployment", app.Name)
+	}
+	return id, nil
+}
+
+// Certificates returns the simulated certificate stores.
+func (p *Platform) Certificates() lbplatform.CertificateDetector {
+	return certificateDetector{p}
+}
+
+type certificateDetector struct{ p *Platform }
+
+func (d certificateDetector) CertificateExists(cert lbdeploy.CertificateResource) (bool, error) {
+	return d.p.certificates[cert], nil
+}
+
+// Hardware returns the simulated hardware.
+func (p *Platform) Hardware() lbplatform.HardwareDetector {
+	return hardwareDetector{p}
+}
+
+type hardwareDetector struct{ p *Platform }
+
+func (d hardwareDetector) InstalledMemory() (int64, error) {
+	return d.p.hardware.Memory << 20, nil
+}
+
+func (d hardwareDetector) LogicalProcessors() (int, error) {
+	return d.p.hardware.Processors, nil
+}
+
+func (d hardwareDetector) TPMVersion() (int, error) {
+	return d.p.hardware.TPMVersion, nil
+}
+
+func (d hardwareDetector) SecureBootEnabled() (bool, error) {
+	return d.p.hardware.SecureBoot, nil
+}
+
+func (d hardwareDetector) Hypervisor() (lbdeploy.Hypervisor, error) {
+	return d.p.hardware.Hypervisor, nil
+}
+
+func (d hardwareDetector) BitLockerProtected() (bool, error) {
+	return d.p.hardware.BitLocker, nil
+}
+
+func (d hardwareDetector) ActivePowerPlan() (string, error) {
+	if d.p.hardware.PowerPlan == "" {
+		return lbdeploy.PowerPlanBalanced.GUID(), nil
+	}
+	return d.p.hardware.PowerPlan.GUID(), nil
+}
+
+// Locale returns the simulated language and regional settings.
+func (p *Platform) Locale() lbplatform.LocaleDetector {
+	return localeDetector{p}
+}
+
+type localeDetector struct{ p *Platform }
+
+func (d localeDetector) UILanguage() (string, error) {
+	return d.p.locale.UILanguage, nil
+}
+
+func (d localeDetector) SystemLocale() (string, error) {
+	return d.p.locale.SystemLocale, nil
+}
+
+// KeyboardLayouts returns the simulated keyboard layouts. Each layout is
+// reported as both an identifier and a language, so that conditions may
+// match either.
+func (d localeDetector) KeyboardLayouts() ([]lbplatform.KeyboardLayout, error) {
+	layouts := make([]lbplatform.KeyboardLayout, 0, len(d.p.locale.KeyboardLayouts))
+	for _, layout := range d.p.locale.KeyboardLayouts {
+		layouts = append(layouts, lbplatform.KeyboardLayout{ID: layout, Language: layout})
+	}
+	return layouts, nil
+}
+
+func (d localeDetector) TimeZone() (string, error) {
+	return d.p.locale.TimeZone, nil
+}
+
+// Accounts returns the simulated local accounts.
+func (p *Platform) Accounts() lbplatform.AccountDetector {
+	return accountDetector{p}
+}
+
+type accountDetector struct{ p *Platform }
+
+func (d accountDetector) LocalUserExists(name string) (bool, error) {
+	return containsName(d.p.accounts.Users, name), nil
+}
+
+func (d accountDetector) IsGroupMember(group, account string) (bool, error) {
+	_, members, found := lookupName(d.p.accounts.Groups, group)
+	if !found {
+		return false, fmt.Errorf("the \"%s\" local group does not exist", group)
+	}
+	return containsName(members, account), nil
+}
+
+func (d accountDetector) HasUserRight(right, account string) (bool, error) {
+	_, accounts, _ := lookupName(d.p.accounts.Rights, right)
+	return containsName(accounts, account), nil
+}
+
+type processController struct{ p *Platform }
+
+// NumberOfRunningProcesses counts the simulated processes that match the
+// given criteria. Values are compared without regard to case.
+func (c processController) NumberOfRunningProcesses(match lbdeploy.ProcessMatch) (int, error) {
+	filter, err := match.Compile(true)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, proc := range c.p.processes {
+		matched, err := filter(proc)
+		if err != nil {
+			return 0, err
+		}
+		if matched {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (c processController) MutexExists(name string) (bool, error) {
+	return c.p.mutexes.Contains(name), nil
+}
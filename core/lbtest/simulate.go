@@ -0,0 +1,275 @@
+package lbtest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/idset"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbeval"
+)
+
+// StepID identifies an action within a flow, in the form flow/n, where n
+// is the one-based index of the action.
+type StepID string
+
+// Outcome describes what happened to an action in a simulation.
+type Outcome string
+
+// Simulated outcomes.
+const (
+	OutcomeRun     Outcome = "run"
+	OutcomeSkipped Outcome = "skipped"
+)
+
+// Step records the outcome of a simulated action.
+type Step struct {
+	Flow    lbdeploy.FlowID
+	Index   int
+	Type    lbdeploy.ActionType
+	Outcome Outcome
+	Reason  string
+}
+
+// ID returns the step ID of the action.
+func (step Step) ID() StepID {
+	return StepID(string(step.Flow) + "/" + strconv.Itoa(step.Index+1))
+}
+
+// String returns a description of the step.
+func (step Step) String() string {
+	s := fmt.Sprintf("%s: %s %s", step.ID(), step.Type, step.Outcome)
+	if step.Reason != "" {
+		s += " (" + step.Reason + ")"
+	}
+	return s
+}
+
+// Run simulates the given fixture against the deployment. It returns the
+// steps that were simulated, in order, and the error that the flow
+// returned.
+func Run(ctx context.Context, dep lbdeploy.Deployment, fixture Fixture) ([]Step, error) {
+	platform, err := NewPlatform(dep, fixture.System)
+	if err != nil {
+		return nil, err
+	}
+	return Simulate(ctx, dep, fixture.Flow, platform)
+}
+
+// Simulate invokes a flow within the deployment against the simulated
+// platform. It returns the steps that were simulated, in order, and the
+// error that the flow returned.
+//
+// Flow constraints, preconditions and action conditions are evaluated as
+// they would be on a real system. Commands that install or uninstall
+// applications update the platform as though they had succeeded. All
+// other actions are assumed to succeed without side effects.
+func Simulate(ctx context.Context, dep lbdeploy.Deployment, flow lbdeploy.FlowID, platform *Platform) ([]Step, error) {
+	if err := dep.Validate(); err != nil {
+		return nil, err
+	}
+
+	sim := simulation{
+		deployment: dep,
+		platform:   platform,
+		active:     make(idset.SetOf[lbdeploy.FlowID]),
+	}
+	err := sim.runFlow(ctx, flow)
+	return sim.steps, err
+}
+
+// simulation holds the state of a simulated deployment.
+type simulation struct {
+	deployment lbdeploy.Deployment
+	platform   *Platform
+	active     idset.SetOf[lbdeploy.FlowID]
+	steps      []Step
+}
+
+func (sim *simulation) runFlow(ctx context.Context, id lbdeploy.FlowID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	flow, found := sim.deployment.Flows[id]
+	if !found {
+		return fmt.Errorf("the flow \"%s\" does not exist within the \"%s\" deployment", id, sim.deployment.ID)
+	}
+
+	if sim.active.Contains(id) {
+		return fmt.Errorf("the \"%s\" flow is already running", id)
+	}
+	sim.active.Add(id)
+	defer sim.active.Remove(id)
+
+	ce := lbeval.NewConditionEngine(sim.deployment, sim.platform)
+
+	// If any constraints fail, the flow is skipped.
+	failed, err := evaluateAll(ce, flow.Constraints)
+	if err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to evaluate its constraints: %w", id, err)
+	}
+	if len(failed) > 0 {
+		return nil
+	}
+
+	// If any preconditions fail, the flow fails.
+	failed, err = evaluateAll(ce, flow.Preconditions)
+	if err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to evaluate its preconditions: %w", id, err)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("the \"%s\" flow is unable to run because one or more preconditions failed: %s", id, failed)
+	}
+
+	for i, action := range flow.Actions {
+		step := Step{Flow: id, Index: i, Type: action.Type}
+
+		// Evaluate the action's conditions.
+		failed, err := evaluateAll(ce, action.Conditions)
+		if err != nil {
+			return fmt.Errorf("action %d failed to evaluate its conditions: %w", i+1, err)
+		}
+		if len(failed) > 0 {
+			step.Outcome = OutcomeSkipped
+			step.Reason = fmt.Sprintf("conditions not met: %s", failed)
+			sim.steps = append(sim.steps, step)
+			continue
+		}
+
+		switch action.Type {
+		case lbdeploy.ActionStartFlow:
+			step.Outcome = OutcomeRun
+			step.Reason = "starts " + string(action.Flow)
+			sim.steps = append(sim.steps, step)
+			if err := sim.runFlow(ctx, action.Flow); err != nil {
+				return err
+			}
+		case lbdeploy.ActionInvokeCommand:
+			if err := sim.invokeCommand(action, &step); err != nil {
+				return fmt.Errorf("action %d: %w", i+1, err)
+			}
+			sim.steps = append(sim.steps, step)
+		case lbdeploy.ActionWaitForRegistry:
+			// The simulated system never changes on its own, so the wait
+			// only succeeds if the condition is already satisfied.
+			result, err := ce.Evaluate(action.Condition)
+			if err != nil {
+				return fmt.Errorf("action %d failed to evaluate its condition: %w", i+1, err)
+			}
+			step.Outcome = OutcomeRun
+			sim.steps = append(sim.steps, step)
+			if !result {
+				return fmt.Errorf("action %d: the \"%s\" condition would not be satisfied", i+1, action.Condition)
+			}
+		case lbdeploy.ActionSuspendBitLocker, lbdeploy.ActionResumeBitLocker:
+			step.Outcome = OutcomeRun
+			sim.steps = append(sim.steps, step)
+			sim.platform.hardware.BitLocker = action.Type == lbdeploy.ActionResumeBitLocker
+		case lbdeploy.ActionSetPowerPlan:
+			step.Outcome = OutcomeRun
+			sim.steps = append(sim.steps, step)
+			sim.platform.hardware.PowerPlan = action.PowerPlan
+		case lbdeploy.ActionSetTimeZone:
+			step.Outcome = OutcomeRun
+			sim.steps = append(sim.steps, step)
+			sim.platform.locale.TimeZone = action.TimeZone
+		case lbdeploy.ActionConfigureLocalUsers:
+			step.Outcome = OutcomeRun
+			sim.steps = append(sim.steps, step)
+			for _, user := range action.LocalUsers {
+				sim.platform.accounts.setUser(user.Name, !user.Remove)
+			}
+		case lbdeploy.ActionConfigureLocalGroups:
+			step.Outcome = OutcomeRun
+			sim.steps = append(sim.steps, step)
+			for _, membership := range action.GroupMembers {
+				sim.platform.accounts.setGroupMember(membership.Group, membership.Member, !membership.Remove)
+			}
+		case lbdeploy.ActionConfigureUserRights:
+			step.Outcome = OutcomeRun
+			sim.steps = append(sim.steps, step)
+			for _, assignment := range action.UserRights {
+				sim.platform.accounts.setUserRight(assignment.Right, assignment.Account, !assignment.Remove)
+			}
+		default:
+			step.Outcome = OutcomeRun
+			sim.steps = append(sim.steps, step)
+		}
+	}
+
+	return nil
+}
+
+// invokeCommand simulates a command. Commands whose application changes
+// are already in effect are skipped unless they are forced.
+func (sim *simulation) invokeCommand(action lbdeploy.Action, step *Step) error {
+	var (
+		command lbdeploy.Command
+		found   bool
+	)
+	if action.Package != "" {
+		pkg, ok := sim.deployment.Resources.Packages[action.Package]
+		if !ok {
+			return fmt.Errorf("the \"%s\" package does not exist within the \"%s\" deployment", action.Package, sim.deployment.ID)
+		}
+		command, found = pkg.Commands[action.Command]
+	} else {
+		command, found = sim.deployment.Commands[action.Command]
+	}
+	if !found {
+		return fmt.Errorf("the \"%s\" command does not exist within the \"%s\" deployment", action.Command, sim.deployment.ID)
+	}
+
+	ae := lbeval.NewAppEngine(sim.deployment, sim.platform)
+	evaluation, err := ae.EvaluateAppChanges(command.Installs, command.Uninstalls)
+	if err != nil {
+		return fmt.Errorf("the evaluation of potential application changes did not succeed: %w", err)
+	}
+
+	hasApps := len(command.Installs) > 0 || len(command.Uninstalls) > 0
+	if hasApps && !evaluation.ActionsNeeded() && !action.Force {
+		step.Outcome = OutcomeSkipped
+		step.Reason = fmt.Sprintf("command %s: applications already in their desired state", action.Command)
+		return nil
+	}
+
+	// Apply the command's application changes to the simulated system.
+	for _, app := range command.Installs {
+		sim.platform.Install(app)
+	}
+	for _, app := range command.Uninstalls {
+		sim.platform.Uninstall(app)
+	}
+
+	step.Outcome = OutcomeRun
+	var changes []string
+	if len(evaluation.ToInstall) > 0 {
+		changes = append(changes, "installs "+evaluation.ToInstall.String())
+	}
+	if len(evaluation.ToUninstall) > 0 {
+		changes = append(changes, "uninstalls "+evaluation.ToUninstall.String())
+	}
+	step.Reason = "command " + string(action.Command)
+	if len(changes) > 0 {
+		step.Reason += ": " + strings.Join(changes, "; ")
+	}
+	return nil
+}
+
+// evaluateAll evaluates each of the conditions and returns the ones that
+// failed.
+func evaluateAll(ce lbeval.ConditionEngine, conditions lbdeploy.ConditionList) (failed lbdeploy.ConditionList, err error) {
+	for _, condition := range conditions {
+		result, err := ce.Evaluate(condition)
+		if err != nil {
+			return nil, err
+		}
+		if !result {
+			failed = append(failed, condition)
+		}
+	}
+	return failed, nil
+}
@@ -0,0 +1,97 @@
+package lbtest_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+)
+
+var testDeployment = lbdeploy.Deployment{
+	ID: "test",
+	Apps: lbdeploy.AppMap{
+		"editor": {Name: "Editor", ProductCode: "{6F1C9E8A-3B2D-4C5E-9F70-1A2B3C4D5E6F}"},
+	},
+	Conditions: lbdeploy.ConditionMap{
+		"has-config": {Type: lbdeploy.ConditionTypeFileExists, Subject: "config"},
+		"is-running": {Type: lbdeploy.ConditionTypeProcessIsRunning, Subject: "editor"},
+	},
+	Resources: lbdeploy.Resources{
+		FileSystem: lbdeploy.FileSystemResources{
+			Files: lbdeploy.FileResourceMap{
+				"config": {Location: "program-data", Path: "Editor/config.json"},
+			},
+		},
+		Processes: lbdeploy.ProcessResourceMap{
+			"editor": {Match: lbdeploy.ProcessMatch{Attribute: lbdeploy.ProcessName, Type: lbdeploy.MatchEquals, Value: "editor.exe"}},
+		},
+	},
+	Commands: lbdeploy.CommandMap{
+		"install-editor": {Installs: lbdeploy.AppList{"editor"}},
+		"remove-editor":  {Uninstalls: lbdeploy.AppList{"editor"}},
+	},
+	Flows: lbdeploy.FlowMap{
+		"install": {
+			Preconditions: lbdeploy.ConditionList{"is-running"},
+			Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionInvokeCommand, Command: "install-editor"},
+				{Type: lbdeploy.ActionCopyFile, Conditions: lbdeploy.ConditionList{"has-config"}},
+				{Type: lbdeploy.ActionStartFlow, Flow: "reinstall"},
+			},
+		},
+		"reinstall": {
+			Actions: []lbdeploy.Action{
+				{Type: lbdeploy.ActionInvokeCommand, Command: "remove-editor"},
+				{Type: lbdeploy.ActionInvokeCommand, Command: "install-editor"},
+			},
+		},
+	},
+}
+
+var simulationFixtures = []string{
+	`{
+		"name": "fresh install",
+		"flow": "install",
+		"system": {"processes": ["EDITOR.EXE"]},
+		"expect": {
+			"run": ["install/1", "install/3", "reinstall/1", "reinstall/2"],
+			"skipped": ["install/2"]
+		}
+	}`,
+	`{
+		"name": "already installed",
+		"flow": "install",
+		"system": {"apps": {"editor": "1.0"}, "files": ["config"], "processes": ["editor.exe"]},
+		"expect": {
+			"run": ["install/2", "install/3", "reinstall/1", "reinstall/2"],
+			"skipped": ["install/1"]
+		}
+	}`,
+	`{
+		"name": "not running",
+		"flow": "install",
+		"expect": {"error": true}
+	}`,
+	`{
+		"name": "undefined flow",
+		"flow": "missing",
+		"expect": {"error": true}
+	}`,
+}
+
+func TestSimulate(t *testing.T) {
+	for _, data := range simulationFixtures {
+		var fixture lbtest.Fixture
+		if err := json.Unmarshal([]byte(data), &fixture); err != nil {
+			t.Fatalf("failed to parse fixture: %v", err)
+		}
+		t.Run(fixture.Name, func(t *testing.T) {
+			steps, err := lbtest.Run(context.Background(), testDeployment, fixture)
+			for _, problem := range fixture.Expect.Check(steps, err) {
+				t.Error(problem)
+			}
+		})
+	}
+}
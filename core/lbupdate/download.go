@@ -0,0 +1,71 @@
+package lbupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+)
+
+// Download retrieves the build and writes it to a new file at path. The
+// size and sha256 hash of the data are verified before it returns. If the
+// download or verification fails, the file is removed.
+//
+// The build must declare its size, which must not exceed [MaxBuildSize].
+func Download(ctx context.Context, client *http.Client, build Build, path string) (size int64, err error) {
+	if err := build.Validate(); err != nil {
+		return 0, fmt.Errorf("the build %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, build.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("the build could not be retrieved: %s", resp.Status)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o755)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	// Stop reading one byte past the expected size, so that oversized
+	// responses are detected without reading them in full.
+	body := io.LimitReader(resp.Body, build.Size+1)
+
+	hash := sha256.New()
+	size, err = io.Copy(io.MultiWriter(file, hash), body)
+	if err != nil {
+		return size, err
+	}
+
+	if size != build.Size {
+		return size, fmt.Errorf("the build has a size of %d bytes instead of the expected %d bytes", size, build.Size)
+	}
+	if actual := filehash.Value(hash.Sum(nil)); !bytes.Equal(actual, build.SHA256) {
+		return size, fmt.Errorf("the build has a sha256 hash of %s instead of the expected %s", actual, build.SHA256)
+	}
+
+	return size, nil
+}
@@ -0,0 +1,78 @@
+package lbupdate_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbupdate"
+)
+
+func TestDownload(t *testing.T) {
+	content := []byte("leafbridge-deploy build")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(content)
+	wrong := sha256.Sum256([]byte("something else"))
+
+	fixtures := []struct {
+		Name  string
+		Build lbupdate.Build
+		OK    bool
+	}{
+		{Name: "match", Build: lbupdate.Build{URL: server.URL, Size: int64(len(content)), SHA256: sum[:]}, OK: true},
+		{Name: "missing-size", Build: lbupdate.Build{URL: server.URL, SHA256: sum[:]}, OK: false},
+		{Name: "oversized", Build: lbupdate.Build{URL: server.URL, Size: lbupdate.MaxBuildSize + 1, SHA256: sum[:]}, OK: false},
+		{Name: "wrong-size", Build: lbupdate.Build{URL: server.URL, Size: 4, SHA256: sum[:]}, OK: false},
+		{Name: "wrong-hash", Build: lbupdate.Build{URL: server.URL, Size: int64(len(content)), SHA256: wrong[:]}, OK: false},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "download.exe")
+			_, err := lbupdate.Download(context.Background(), server.Client(), fixture.Build, path)
+			_, statErr := os.Stat(path)
+			switch {
+			case fixture.OK && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case !fixture.OK && err == nil:
+				t.Fatal("expected an error but none was returned")
+			case !fixture.OK && statErr == nil:
+				t.Fatal("the file was not removed after a failed download")
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "leafbridge-deploy.exe")
+	replacement := filepath.Join(dir, "leafbridge-deploy.exe.new")
+	os.WriteFile(path, []byte("old"), 0o755)
+	os.WriteFile(replacement, []byte("new"), 0o755)
+
+	backup, err := lbupdate.Replace(path, replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("the executable was not replaced: %q", data)
+	}
+	if data, _ := os.ReadFile(backup); string(data) != "old" {
+		t.Errorf("the backup does not hold the previous executable: %q", data)
+	}
+
+	if err := lbupdate.RemoveBackup(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(backup); !os.IsNotExist(err) {
+		t.Errorf("the backup was not removed")
+	}
+}
@@ -0,0 +1,140 @@
+// Package lbupdate retrieves newer builds of LeafBridge from a published
+// release channel and swaps them into place.
+//
+// A release channel is described by a manifest, which is a JSON document
+// published at a well-known URL. It declares the latest version and the
+// location and hash of a build for each supported platform:
+//
+//	{
+//	  "version": "1.4.0.0",
+//	  "builds": {
+//	    "windows/amd64": {
+//	      "url": "https://example.com/leafbridge/1.4.0.0/leafbridge-deploy.exe",
+//	      "size": 14680064,
+//	      "sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+//	    }
+//	  }
+//	}
+//
+// Signature verification of downloaded builds is platform-specific and is
+// left to the caller.
+package lbupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/internal/buildinfo"
+)
+
+// MaxManifestSize is the maximum size of a channel manifest.
+const MaxManifestSize = 1 << 20
+
+// MaxBuildSize is the maximum size of a build. A manifest that declares a
+// larger build is rejected, so that a compromised channel can't fill the
+// disk.
+const MaxBuildSize = 512 << 20
+
+// Manifest describes the latest release published to a channel.
+type Manifest struct {
+	Version buildinfo.Version `json:"version"`
+	Builds  map[string]Build  `json:"builds"`
+}
+
+// Build describes a build of LeafBridge for a particular platform. The
+// size and sha256 hash of the build are required.
+type Build struct {
+	URL    string         `json:"url"`
+	Size   int64          `json:"size"`
+	SHA256 filehash.Value `json:"sha256"`
+}
+
+// Platform returns the platform identifier of the running program, such as
+// "windows/amd64".
+func Platform() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// Validate returns a non-nil error if the manifest is incomplete.
+func (m Manifest) Validate() error {
+	if m.Version == "" {
+		return errors.New("the manifest does not declare a version")
+	}
+	if len(m.Builds) == 0 {
+		return errors.New("the manifest does not provide any builds")
+	}
+	for platform, build := range m.Builds {
+		if err := build.Validate(); err != nil {
+			return fmt.Errorf("the \"%s\" build %w", platform, err)
+		}
+	}
+	return nil
+}
+
+// Validate returns a non-nil error if the build is incomplete, or if it
+// exceeds the maximum build size.
+func (b Build) Validate() error {
+	switch {
+	case b.URL == "":
+		return errors.New("does not provide a URL")
+	case b.Size <= 0:
+		return errors.New("does not provide a size")
+	case b.Size > MaxBuildSize:
+		return fmt.Errorf("has a size of %d bytes, which exceeds the maximum of %d bytes", b.Size, MaxBuildSize)
+	case len(b.SHA256) == 0:
+		return errors.New("does not provide a sha256 hash")
+	}
+	return nil
+}
+
+// Build returns the build for the given platform.
+func (m Manifest) Build(platform string) (Build, error) {
+	build, found := m.Builds[platform]
+	if !found {
+		return Build{}, fmt.Errorf("the manifest for version %s does not provide a build for %s", m.Version, platform)
+	}
+	return build, nil
+}
+
+// FetchManifest retrieves and validates the channel manifest at url.
+func FetchManifest(ctx context.Context, client *http.Client, url string) (Manifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Manifest{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Manifest{}, fmt.Errorf("the channel manifest could not be retrieved: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxManifestSize+1))
+	if err != nil {
+		return Manifest{}, err
+	}
+	if len(data) > MaxManifestSize {
+		return Manifest{}, fmt.Errorf("the channel manifest exceeds the maximum size of %d bytes", MaxManifestSize)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("the channel manifest could not be parsed: %w", err)
+	}
+	if err := manifest.Validate(); err != nil {
+		return Manifest{}, err
+	}
+
+	return manifest, nil
+}
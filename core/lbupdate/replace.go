@@ -0,0 +1,50 @@
+package lbupdate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// BackupPath returns the path that the executable at path is moved to when
+// it is replaced.
+func BackupPath(path string) string {
+	return path + ".old"
+}
+
+// Replace swaps the executable at path for the replacement, which must be
+// on the same volume. The current executable is moved to its backup path,
+// which works even while it is running. If the replacement cannot be moved
+// into place, the current executable is restored.
+//
+// Any backup left behind by a previous replacement is removed first.
+func Replace(path, replacement string) (backup string, err error) {
+	backup = BackupPath(path)
+	if err := os.Remove(backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to remove the backup of a previous update: %w", err)
+	}
+
+	if err := os.Rename(path, backup); err != nil {
+		return "", fmt.Errorf("failed to move the current executable aside: %w", err)
+	}
+
+	if err := os.Rename(replacement, path); err != nil {
+		if restoreErr := os.Rename(backup, path); restoreErr != nil {
+			return backup, fmt.Errorf("failed to move the new executable into place: %w (the current executable could not be restored from %s: %v)", err, backup, restoreErr)
+		}
+		return "", fmt.Errorf("failed to move the new executable into place: %w", err)
+	}
+
+	return backup, nil
+}
+
+// RemoveBackup removes the backup left behind by a previous replacement of
+// the executable at path, if there is one. It fails if the previous
+// executable is still running.
+func RemoveBackup(path string) error {
+	err := os.Remove(BackupPath(path))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
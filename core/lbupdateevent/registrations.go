@@ -0,0 +1,17 @@
+package lbupdateevent
+
+import "github.com/leafbridge/leafbridge/core/lbevent"
+
+// Registrations is an ordered list of all event registrations for the
+// update system.
+//
+// Update events are assigned IDs from 200 onward, so that they do not
+// collide with deployment events. Event IDs must never be reused or changed
+// once they have been released.
+var Registrations = []lbevent.Registration{
+	{Type: UpdateCheckType, ID: 200, Unmarshaler: lbevent.UnmarshalRecord[UpdateCheck]},
+	{Type: UpdateDownloadType, ID: 201, Unmarshaler: lbevent.UnmarshalRecord[UpdateDownload]},
+	{Type: UpdateVerificationType, ID: 202, Unmarshaler: lbevent.UnmarshalRecord[UpdateVerification]},
+	{Type: UpdateInstallType, ID: 203, Unmarshaler: lbevent.UnmarshalRecord[UpdateInstall]},
+	{Type: ServiceRestartType, ID: 204, Unmarshaler: lbevent.UnmarshalRecord[ServiceRestart]},
+}
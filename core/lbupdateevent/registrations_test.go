@@ -0,0 +1,19 @@
+package lbupdateevent_test
+
+import (
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbupdateevent"
+)
+
+func TestRegistrationsDoNotCollide(t *testing.T) {
+	registry := lbevent.NewRegistry(100)
+	if err := registry.Add(lbdeployevent.Registrations...); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Add(lbupdateevent.Registrations...); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,68 @@
+package lbupdateevent
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Service event types.
+const (
+	ServiceRestartType = lbevent.Type("update.service:restart")
+)
+
+// ServiceRestart is an event that occurs when a service has been
+// restarted so that it picks up an updated build.
+type ServiceRestart struct {
+	Service string
+	Err     error
+}
+
+// Type returns the type of the event.
+func (e ServiceRestart) Type() lbevent.Type {
+	return ServiceRestartType
+}
+
+// Level returns the level of the event.
+func (e ServiceRestart) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e ServiceRestart) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary("update")
+	builder.WritePrimary(e.Service)
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" service could not be restarted due to an error: %s.", e.Service, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The \"%s\" service was restarted.", e.Service))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e ServiceRestart) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e ServiceRestart) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("service", e.Service),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+	return attrs
+}
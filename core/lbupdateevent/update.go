@@ -0,0 +1,277 @@
+// Package lbupdateevent defines the events recorded while LeafBridge
+// updates itself.
+package lbupdateevent
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gentlemanautomaton/structformat"
+	"github.com/gentlemanautomaton/structformat/fieldformat"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/internal/buildinfo"
+)
+
+// Update event types.
+const (
+	UpdateCheckType        = lbevent.Type("update:check")
+	UpdateDownloadType     = lbevent.Type("update:download")
+	UpdateVerificationType = lbevent.Type("update:verification")
+	UpdateInstallType      = lbevent.Type("update:install")
+)
+
+// UpdateCheck is an event that occurs when a release channel has been
+// checked for a newer version.
+type UpdateCheck struct {
+	Channel   string
+	Current   buildinfo.Version
+	Available buildinfo.Version
+	Err       error
+}
+
+// Type returns the type of the event.
+func (e UpdateCheck) Type() lbevent.Type {
+	return UpdateCheckType
+}
+
+// Level returns the level of the event.
+func (e UpdateCheck) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e UpdateCheck) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary("update")
+
+	switch {
+	case e.Err != nil:
+		builder.WriteStandard(fmt.Sprintf("The release channel could not be checked due to an error: %s.", e.Err))
+	case e.Available.Compare(e.Current) > 0:
+		builder.WriteStandard(fmt.Sprintf("Version %s is available.", e.Available))
+		builder.WriteNote(string(e.Current), fieldformat.Label("current"))
+	default:
+		builder.WriteStandard(fmt.Sprintf("Version %s is up to date.", e.Current))
+		builder.WriteNote(string(e.Available), fieldformat.Label("published"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e UpdateCheck) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e UpdateCheck) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("channel", e.Channel),
+		slog.String("current", string(e.Current)),
+	}
+	if e.Available != "" {
+		attrs = append(attrs, slog.String("available", string(e.Available)))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+	return attrs
+}
+
+// UpdateDownload is an event that occurs when a build has been downloaded
+// and its hash has been checked.
+type UpdateDownload struct {
+	Version  buildinfo.Version
+	URL      string
+	Path     string
+	Size     int64
+	Duration time.Duration
+	Err      error
+}
+
+// Type returns the type of the event.
+func (e UpdateDownload) Type() lbevent.Type {
+	return UpdateDownloadType
+}
+
+// Level returns the level of the event.
+func (e UpdateDownload) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e UpdateDownload) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary("update")
+	builder.WritePrimary(string(e.Version))
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The build could not be downloaded from %s due to an error: %s.", e.URL, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The build was downloaded from %s.", e.URL))
+		builder.WriteNote(fmt.Sprintf("%d bytes", e.Size))
+		builder.WriteNote(e.Duration.Round(time.Millisecond).String())
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e UpdateDownload) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e UpdateDownload) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("version", string(e.Version)),
+		slog.String("url", e.URL),
+		slog.String("path", e.Path),
+		slog.Int64("size", e.Size),
+		slog.Duration("duration", e.Duration),
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+	return attrs
+}
+
+// UpdateVerification is an event that occurs when the signature of a
+// downloaded build has been verified.
+type UpdateVerification struct {
+	Version    buildinfo.Version
+	Path       string
+	Signer     string
+	Thumbprint string
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e UpdateVerification) Type() lbevent.Type {
+	return UpdateVerificationType
+}
+
+// Level returns the level of the event.
+func (e UpdateVerification) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e UpdateVerification) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary("update")
+	builder.WritePrimary(string(e.Version))
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The signature of the build could not be verified: %s.", e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("The build is signed by %s.", e.Signer))
+		builder.WriteNote(e.Thumbprint, fieldformat.Label("thumbprint"))
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e UpdateVerification) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e UpdateVerification) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("version", string(e.Version)),
+		slog.String("path", e.Path),
+	}
+	if e.Signer != "" {
+		attrs = append(attrs, slog.Group("signer", "subject", e.Signer, "thumbprint", e.Thumbprint))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+	return attrs
+}
+
+// UpdateInstall is an event that occurs when a verified build has been
+// swapped into place.
+type UpdateInstall struct {
+	Previous   buildinfo.Version
+	Version    buildinfo.Version
+	Path       string
+	BackupPath string
+	Err        error
+}
+
+// Type returns the type of the event.
+func (e UpdateInstall) Type() lbevent.Type {
+	return UpdateInstallType
+}
+
+// Level returns the level of the event.
+func (e UpdateInstall) Level() slog.Level {
+	if e.Err != nil {
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Message returns a description of the event.
+func (e UpdateInstall) Message() string {
+	var builder structformat.Builder
+
+	builder.WritePrimary("update")
+	builder.WritePrimary(string(e.Version))
+
+	if e.Err != nil {
+		builder.WriteStandard(fmt.Sprintf("The build could not be installed at %s due to an error: %s.", e.Path, e.Err))
+	} else {
+		builder.WriteStandard(fmt.Sprintf("Version %s replaced version %s at %s.", e.Version, e.Previous, e.Path))
+		if e.BackupPath != "" {
+			builder.WriteNote(e.BackupPath, fieldformat.Label("backup"))
+		}
+	}
+
+	return builder.String()
+}
+
+// Details returns additional details about the event. It might include
+// multiple lines of text. An empty string is returned when no details
+// are available.
+func (e UpdateInstall) Details() string {
+	return ""
+}
+
+// Attrs returns a set of structured log attributes for the event.
+func (e UpdateInstall) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("previous", string(e.Previous)),
+		slog.String("version", string(e.Version)),
+		slog.String("path", e.Path),
+	}
+	if e.BackupPath != "" {
+		attrs = append(attrs, slog.String("backup", e.BackupPath))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+	return attrs
+}
@@ -0,0 +1,99 @@
+package msicmd
+
+// Operation is an msiexec command line option that selects the operation
+// performed by the Windows Installer.
+type Operation string
+
+// Windows Installer operations.
+const (
+	Install   Operation = "/i"
+	Update    Operation = "/update"
+	Uninstall Operation = "/x"
+)
+
+// Restart is an msiexec command line option that controls whether the
+// Windows Installer restarts the system after an operation.
+type Restart string
+
+// Windows Installer restart options.
+const (
+	NoRestart     Restart = "/norestart"
+	PromptRestart Restart = "/promptrestart"
+)
+
+// PropertyValue is the name and value of a Windows Installer property.
+type PropertyValue struct {
+	Name  string
+	Value string
+}
+
+// Invocation describes an invocation of msiexec in structured form. Its
+// fields are translated into a set of correctly quoted command line
+// arguments by the Args method.
+type Invocation struct {
+	// Operation is the operation to perform.
+	Operation Operation
+
+	// Target is the path of the installer package or patch, or the product
+	// code of an installed product.
+	Target string
+
+	// UI is the command line option for the user interface level, such
+	// as "/quiet" or "/qn".
+	UI string
+
+	// Restart controls restarts after the operation. If empty, NoRestart
+	// is used.
+	Restart Restart
+
+	// SuppressReboot sets the REBOOT property to ReallySuppress, which
+	// prevents the installer from restarting the system even when the
+	// package schedules a restart.
+	SuppressReboot bool
+
+	// LogPath is the path of a verbose log file. If empty, no log is
+	// written.
+	LogPath string
+
+	// Transforms is an ordered list of transforms to apply.
+	Transforms []string
+
+	// Properties is an ordered list of public property values.
+	Properties []PropertyValue
+
+	// Extra holds additional arguments that are appended to the command
+	// line after being prepared with Arg.
+	Extra []string
+}
+
+// Args returns the msiexec command line arguments for the invocation. The
+// arguments have already been quoted for msiexec, and can be passed to
+// CommandLine.
+func (inv Invocation) Args() []string {
+	restart := inv.Restart
+	if restart == "" {
+		restart = NoRestart
+	}
+
+	args := []string{string(inv.Operation), QuoteArg(inv.Target)}
+	if inv.UI != "" {
+		args = append(args, inv.UI)
+	}
+	args = append(args, string(restart))
+	if inv.LogPath != "" {
+		args = append(args, "/l*v", QuoteArg(inv.LogPath))
+	}
+	if len(inv.Transforms) > 0 {
+		args = append(args, Transforms(inv.Transforms...))
+	}
+	if inv.SuppressReboot {
+		args = append(args, Property("REBOOT", "ReallySuppress"))
+	}
+	for _, prop := range inv.Properties {
+		args = append(args, Property(prop.Name, prop.Value))
+	}
+	for _, arg := range inv.Extra {
+		args = append(args, Arg(arg))
+	}
+	return args
+}
@@ -0,0 +1,52 @@
+package msicmd_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/msi/msicmd"
+)
+
+type invocationInOut struct {
+	Name string
+	In   msicmd.Invocation
+	Out  string
+}
+
+var invocationFixtures = []invocationInOut{
+	{
+		Name: "install",
+		In:   msicmd.Invocation{Operation: msicmd.Install, Target: `C:\Temp Dir\app.msi`, UI: "/quiet"},
+		Out:  `/i "C:\Temp Dir\app.msi" /quiet /norestart`,
+	},
+	{
+		Name: "install-full",
+		In: msicmd.Invocation{
+			Operation:      msicmd.Install,
+			Target:         `app.msi`,
+			UI:             "/qn",
+			SuppressReboot: true,
+			LogPath:        `C:\Logs\app install.log`,
+			Transforms:     []string{"a.mst"},
+			Properties:     []msicmd.PropertyValue{{Name: "INSTALLDIR", Value: `C:\Program Files\App`}},
+			Extra:          []string{"ALLUSERS=1"},
+		},
+		Out: `/i app.msi /qn /norestart /l*v "C:\Logs\app install.log" TRANSFORMS=a.mst REBOOT=ReallySuppress INSTALLDIR="C:\Program Files\App" ALLUSERS=1`,
+	},
+	{
+		Name: "uninstall-prompt",
+		In:   msicmd.Invocation{Operation: msicmd.Uninstall, Target: "{00000000-0000-0000-0000-000000000000}", UI: "/passive", Restart: msicmd.PromptRestart},
+		Out:  `/x {00000000-0000-0000-0000-000000000000} /passive /promptrestart`,
+	},
+}
+
+func TestInvocationArgs(t *testing.T) {
+	for i, fixture := range invocationFixtures {
+		t.Run(fmt.Sprintf("%d:%s", i, fixture.Name), func(t *testing.T) {
+			if out := strings.Join(fixture.In.Args(), " "); out != fixture.Out {
+				t.Fatalf("unexpected arguments:\n got: %s\nwant: %s", out, fixture.Out)
+			}
+		})
+	}
+}
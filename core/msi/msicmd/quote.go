@@ -0,0 +1,85 @@
+// Package msicmd prepares command lines for the Windows Installer
+// executable, msiexec.
+//
+// The msiexec utility parses its own command line, and it does not follow
+// the conventions used by most Windows programs. Property values that contain
+// spaces must be quoted after the equals sign, and quotation marks within a
+// value are escaped by doubling them. The functions in this package produce
+// arguments that follow those rules.
+package msicmd
+
+import "strings"
+
+// QuoteArg returns s quoted for use on an msiexec command line. If s does not
+// contain any spaces, tabs or quotation marks it is returned unmodified.
+func QuoteArg(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Property returns an msiexec command line argument that assigns value to
+// the Windows Installer property with the given name.
+func Property(name, value string) string {
+	return name + "=" + QuoteArg(value)
+}
+
+// Arg returns s prepared for use as an msiexec command line argument.
+//
+// Arguments that assign a value to a public property, in the form
+// NAME=value, have their values quoted as msiexec expects. Values that are
+// already enclosed in quotation marks are left as-is. All other arguments
+// are quoted as a whole when necessary.
+func Arg(s string) string {
+	name, value, found := strings.Cut(s, "=")
+	if !found || !isPublicPropertyName(name) {
+		return QuoteArg(s)
+	}
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return s
+	}
+	return Property(name, value)
+}
+
+// isPublicPropertyName returns true if name is a valid public property
+// name. Public property names do not contain lowercase letters.
+func isPublicPropertyName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z', r == '_':
+		case (r >= '0' && r <= '9') || r == '.':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Transforms returns an msiexec command line argument that applies the
+// given set of transforms, in order.
+func Transforms(paths ...string) string {
+	return Property("TRANSFORMS", strings.Join(paths, ";"))
+}
+
+// CommandLine returns a complete command line for msiexec. The executable
+// path is quoted as needed, but args are expected to have already been
+// prepared for msiexec.
+func CommandLine(execPath string, args ...string) string {
+	var builder strings.Builder
+	builder.WriteString(QuoteArg(execPath))
+	for _, arg := range args {
+		builder.WriteByte(' ')
+		builder.WriteString(arg)
+	}
+	return builder.String()
+}
@@ -0,0 +1,62 @@
+package msicmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/msi/msicmd"
+)
+
+type propertyInOut struct {
+	Name, Value string
+	Out         string
+}
+
+var propertyFixtures = []propertyInOut{
+	{Name: "ALLUSERS", Value: "1", Out: `ALLUSERS=1`},
+	{Name: "INSTALLDIR", Value: `C:\Program Files\Example`, Out: `INSTALLDIR="C:\Program Files\Example"`},
+	{Name: "COMMENT", Value: `say "hi"`, Out: `COMMENT="say ""hi"""`},
+	{Name: "EMPTY", Value: "", Out: `EMPTY=""`},
+}
+
+func TestProperty(t *testing.T) {
+	for i, fixture := range propertyFixtures {
+		t.Run(fmt.Sprintf("%d:%s", i, fixture.Name), func(t *testing.T) {
+			if out := msicmd.Property(fixture.Name, fixture.Value); out != fixture.Out {
+				t.Fatalf("unexpected property argument: got %s, want %s", out, fixture.Out)
+			}
+		})
+	}
+}
+
+type argInOut struct {
+	In, Out string
+}
+
+var argFixtures = []argInOut{
+	{In: "/l*v", Out: "/l*v"},
+	{In: `C:\Temp Dir\log.txt`, Out: `"C:\Temp Dir\log.txt"`},
+	{In: `INSTALLDIR=C:\Program Files\Example`, Out: `INSTALLDIR="C:\Program Files\Example"`},
+	{In: `INSTALLDIR="C:\Program Files\Example"`, Out: `INSTALLDIR="C:\Program Files\Example"`},
+	{In: "lower=case value", Out: `"lower=case value"`},
+}
+
+func TestArg(t *testing.T) {
+	for i, fixture := range argFixtures {
+		t.Run(fmt.Sprintf("%d:%s", i, fixture.In), func(t *testing.T) {
+			if out := msicmd.Arg(fixture.In); out != fixture.Out {
+				t.Fatalf("unexpected argument: got %s, want %s", out, fixture.Out)
+			}
+		})
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	const want = `C:\Windows\System32\msiexec.exe /i "C:\Temp Dir\app.msi" /quiet TRANSFORMS="a.mst;C:\Temp Dir\b.mst"`
+	out := msicmd.CommandLine(`C:\Windows\System32\msiexec.exe`,
+		"/i", msicmd.QuoteArg(`C:\Temp Dir\app.msi`), "/quiet",
+		msicmd.Transforms("a.mst", `C:\Temp Dir\b.mst`))
+	if out != want {
+		t.Fatalf("unexpected command line:\n got: %s\nwant: %s", out, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lberror"
 )
 
 // ExitCode is an exit code produced by msiexec.
@@ -39,3 +40,14 @@ func (code ExitCode) Error() string {
 
 	return out
 }
+
+// Hint returns a hint that describes how the failure is commonly remedied.
+// It returns an empty string if no hint is known.
+func (code ExitCode) Hint() string {
+	return HintMap[code]
+}
+
+// Category returns the category of the error.
+func (code ExitCode) Category() lberror.Category {
+	return lberror.Installer
+}
@@ -0,0 +1,12 @@
+package msiresult
+
+// HintMap maps well-known msiexec exit codes to hints that describe how
+// the failure is commonly remedied.
+var HintMap = map[ExitCode]string{
+	InstallFailure:             "The installation failed with a generic error. Search the installer log for \"Return value 3\" to find the action that failed. Common causes are a pending restart, files that are in use, a damaged prior installation, and insufficient access to the installation folder.",
+	InstallAlreadyRunning:      "Another installation is already in progress. Wait for it to finish, or for Windows Update to finish installing updates, and try again.",
+	InstallPackageOpenFailed:   "The installer package could not be opened. Make sure that the package exists, that it is not damaged, and that it is readable by the account that runs the installation.",
+	InstallPackageRejected:     "The installation is prohibited by system policy. Review the Windows Installer and software restriction policies that apply to the computer.",
+	InstallPlatformUnsupported: "The package is not supported on this platform. Make sure that the package was built for the computer's processor architecture, such as x64 or ARM64.",
+	ProductVersion:             "Another version of the product is already installed. Uninstall the existing version, or use a package that upgrades it.",
+}
@@ -0,0 +1,146 @@
+package pgpsig
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Public key algorithms.
+const (
+	algorithmRSA         = 1
+	algorithmRSASignOnly = 3
+	algorithmEdDSALegacy = 22
+	algorithmEd25519     = 27
+)
+
+// minRSABits is the minimum size of an RSA key that is accepted.
+const minRSABits = 2048
+
+// ed25519OID is the curve OID of Ed25519 in EdDSA (legacy) keys.
+var ed25519OID = []byte{0x2B, 0x06, 0x01, 0x04, 0x01, 0xDA, 0x47, 0x0F, 0x01}
+
+// errUnsupportedKey is returned when a key uses an algorithm that cannot
+// be used to verify signatures.
+var errUnsupportedKey = errors.New("the key algorithm is not supported")
+
+// key is an OpenPGP v4 public key or subkey.
+type key struct {
+	body        []byte
+	algorithm   byte
+	rsa         *rsa.PublicKey
+	ed25519     ed25519.PublicKey
+	fingerprint [20]byte
+}
+
+// parseKey parses the body of a public key or public subkey packet.
+func parseKey(body []byte) (*key, error) {
+	if len(body) < 6 {
+		return nil, errors.New("the key packet is too short")
+	}
+	if body[0] != 4 {
+		return nil, fmt.Errorf("version %d keys are not supported", body[0])
+	}
+
+	k := &key{
+		body:        body,
+		algorithm:   body[5],
+		fingerprint: sha1.Sum(k4Prefix(body)),
+	}
+	material := body[6:]
+
+	switch k.algorithm {
+	case algorithmRSA, algorithmRSASignOnly:
+		n, rest, err := readMPI(material)
+		if err != nil {
+			return nil, err
+		}
+		e, _, err := readMPI(rest)
+		if err != nil {
+			return nil, err
+		}
+		modulus := new(big.Int).SetBytes(n)
+		exponent := new(big.Int).SetBytes(e)
+		if modulus.BitLen() < minRSABits {
+			return nil, fmt.Errorf("the %d-bit RSA key is smaller than the minimum of %d bits", modulus.BitLen(), minRSABits)
+		}
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("the RSA key has an invalid exponent")
+		}
+		k.rsa = &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}
+	case algorithmEdDSALegacy:
+		if len(material) < 1 || len(material)-1 < int(material[0]) {
+			return nil, errors.New("the EdDSA key is too short")
+		}
+		oid := material[1 : 1+int(material[0])]
+		if !bytes.Equal(oid, ed25519OID) {
+			return nil, fmt.Errorf("%w: the EdDSA key does not use Ed25519", errUnsupportedKey)
+		}
+		point, _, err := readMPI(material[1+len(oid):])
+		if err != nil {
+			return nil, err
+		}
+		if len(point) != ed25519.PublicKeySize+1 || point[0] != 0x40 {
+			return nil, errors.New("the EdDSA key is not a valid Ed25519 point")
+		}
+		k.ed25519 = ed25519.PublicKey(point[1:])
+	case algorithmEd25519:
+		if len(material) < ed25519.PublicKeySize {
+			return nil, errors.New("the Ed25519 key is too short")
+		}
+		k.ed25519 = ed25519.PublicKey(material[:ed25519.PublicKeySize])
+	default:
+		return nil, fmt.Errorf("%w: algorithm %d", errUnsupportedKey, k.algorithm)
+	}
+
+	return k, nil
+}
+
+// k4Prefix returns the key packet body with the prefix that is used when
+// v4 keys are hashed.
+func k4Prefix(body []byte) []byte {
+	prefixed := make([]byte, 3, 3+len(body))
+	prefixed[0] = 0x99
+	binary.BigEndian.PutUint16(prefixed[1:], uint16(len(body)))
+	return append(prefixed, body...)
+}
+
+// KeyID returns the 64-bit key ID of the key.
+func (k *key) KeyID() uint64 {
+	return binary.BigEndian.Uint64(k.fingerprint[12:])
+}
+
+// Verify returns a non-nil error if sig is not a valid signature of the
+// given digest by the key.
+func (k *key) Verify(sig *signature, digest []byte) error {
+	if sig.algorithm != k.algorithm && !(k.algorithm == algorithmRSASignOnly && sig.algorithm == algorithmRSA) {
+		return errors.New("the signature algorithm does not match the key")
+	}
+	if digest[0] != sig.left16[0] || digest[1] != sig.left16[1] {
+		return errors.New("the signature does not match the signed data")
+	}
+
+	switch {
+	case k.rsa != nil:
+		s := leftPad(sig.rsaS, k.rsa.Size())
+		if s == nil {
+			return errors.New("the RSA signature is larger than the key")
+		}
+		if err := rsa.VerifyPKCS1v15(k.rsa, sig.hash, digest, s); err != nil {
+			return errors.New("the signature does not match the signed data")
+		}
+	case k.ed25519 != nil:
+		if !ed25519.Verify(k.ed25519, digest, sig.ed25519) {
+			return errors.New("the signature does not match the signed data")
+		}
+	default:
+		return errUnsupportedKey
+	}
+
+	return nil
+}
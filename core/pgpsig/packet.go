@@ -0,0 +1,225 @@
+package pgpsig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Packet tags.
+const (
+	tagSignature = 2
+	tagPublicKey = 6
+	tagTrust     = 12
+	tagUserID    = 13
+	tagSubkey    = 14
+	tagUserAttr  = 17
+)
+
+// packet is an OpenPGP packet.
+type packet struct {
+	tag  byte
+	body []byte
+}
+
+// decode returns the binary packets held in data. If data is ASCII-armored,
+// it must be an armored block of the given type, such as "SIGNATURE". If it
+// is base64-encoded, it is decoded. Otherwise it is assumed to be binary.
+func decode(data []byte, blockType string) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return nil, errors.New("the data is empty")
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN PGP ")):
+		return dearmor(trimmed, blockType)
+	case trimmed[0]&0x80 != 0:
+		return data, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(trimmed))
+	if err != nil {
+		return nil, errors.New("the data is not an armored, base64-encoded or binary OpenPGP block")
+	}
+	return decoded, nil
+}
+
+// dearmor decodes an ASCII-armored block of the given type and verifies its
+// checksum, if it has one.
+func dearmor(data []byte, blockType string) ([]byte, error) {
+	begin := "-----BEGIN PGP " + blockType + "-----"
+	end := "-----END PGP " + blockType + "-----"
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != begin {
+		return nil, fmt.Errorf("the armored data is not a PGP %s block", strings.ToLower(blockType))
+	}
+
+	// Skip the armor headers, which end with a blank line.
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			break
+		}
+		if !strings.Contains(line, ": ") {
+			return nil, errors.New("the armored data does not have a blank line after its headers")
+		}
+	}
+
+	var (
+		encoded  strings.Builder
+		checksum string
+		ended    bool
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == end {
+			ended = true
+			break
+		}
+		if strings.HasPrefix(line, "=") {
+			checksum = line[1:]
+			continue
+		}
+		encoded.WriteString(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !ended {
+		return nil, fmt.Errorf("the armored data does not end with \"%s\"", end)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return nil, fmt.Errorf("the armored data is not valid base64: %w", err)
+	}
+
+	if checksum != "" {
+		sum, err := base64.StdEncoding.DecodeString(checksum)
+		if err != nil || len(sum) != 3 {
+			return nil, errors.New("the armor checksum is not valid")
+		}
+		crc := crc24(decoded)
+		if sum[0] != byte(crc>>16) || sum[1] != byte(crc>>8) || sum[2] != byte(crc) {
+			return nil, errors.New("the armor checksum does not match the data")
+		}
+	}
+
+	return decoded, nil
+}
+
+// crc24 returns the CRC-24 checksum used by ASCII armor.
+func crc24(data []byte) uint32 {
+	const (
+		init = 0xB704CE
+		poly = 0x1864CFB
+	)
+	crc := uint32(init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for range 8 {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= poly
+			}
+		}
+	}
+	return crc & 0xFFFFFF
+}
+
+// readPackets splits data into packets. Packets with partial body lengths
+// are not supported, because they are not used by keys or signatures.
+func readPackets(data []byte) ([]packet, error) {
+	var packets []packet
+	for len(data) > 0 {
+		header := data[0]
+		if header&0x80 == 0 {
+			return nil, errors.New("the data contains an invalid packet header")
+		}
+
+		var (
+			tag    byte
+			length int
+			offset int
+		)
+		if header&0x40 != 0 {
+			// New format.
+			tag = header & 0x3F
+			if len(data) < 2 {
+				return nil, errors.New("the data ends within a packet header")
+			}
+			switch first := int(data[1]); {
+			case first < 192:
+				length, offset = first, 2
+			case first < 224:
+				if len(data) < 3 {
+					return nil, errors.New("the data ends within a packet header")
+				}
+				length, offset = (first-192)<<8+int(data[2])+192, 3
+			case first == 255:
+				if len(data) < 6 {
+					return nil, errors.New("the data ends within a packet header")
+				}
+				length, offset = int(uint32(data[2])<<24|uint32(data[3])<<16|uint32(data[4])<<8|uint32(data[5])), 6
+			default:
+				return nil, errors.New("the data contains a packet with a partial body length, which is not supported")
+			}
+		} else {
+			// Old format.
+			tag = (header >> 2) & 0x0F
+			switch header & 0x03 {
+			case 0:
+				if len(data) < 2 {
+					return nil, errors.New("the data ends within a packet header")
+				}
+				length, offset = int(data[1]), 2
+			case 1:
+				if len(data) < 3 {
+					return nil, errors.New("the data ends within a packet header")
+				}
+				length, offset = int(data[1])<<8|int(data[2]), 3
+			case 2:
+				if len(data) < 5 {
+					return nil, errors.New("the data ends within a packet header")
+				}
+				length, offset = int(uint32(data[1])<<24|uint32(data[2])<<16|uint32(data[3])<<8|uint32(data[4])), 5
+			default:
+				length, offset = len(data)-1, 1
+			}
+		}
+
+		if length < 0 || len(data)-offset < length {
+			return nil, errors.New("the data ends within a packet")
+		}
+		packets = append(packets, packet{tag: tag, body: data[offset : offset+length]})
+		data = data[offset+length:]
+	}
+	return packets, nil
+}
+
+// readMPI reads a multiprecision integer from the start of data. It returns
+// the bytes of the integer and the remaining data.
+func readMPI(data []byte) (value, rest []byte, err error) {
+	if len(data) < 2 {
+		return nil, nil, errors.New("the data ends within a multiprecision integer")
+	}
+	bits := int(data[0])<<8 | int(data[1])
+	n := (bits + 7) / 8
+	if len(data)-2 < n {
+		return nil, nil, errors.New("the data ends within a multiprecision integer")
+	}
+	return data[2 : 2+n], data[2+n:], nil
+}
+
+// leftPad returns value padded with leading zeros to the given size. It
+// returns nil if value is longer than size.
+func leftPad(value []byte, size int) []byte {
+	if len(value) > size {
+		return nil
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(value):], value)
+	return padded
+}
@@ -0,0 +1,242 @@
+// Package pgpsig verifies detached OpenPGP signatures, such as those that
+// vendors publish alongside SHA256SUMS files.
+//
+// Only what is needed to verify a signature against a known public key is
+// implemented. Version 4 keys and signatures are supported, with RSA and
+// Ed25519 (EdDSA) keys and the SHA-2 family of hashes. Signatures made by
+// signing subkeys are accepted when the subkey is bound to the primary key
+// by a valid binding signature, and the binding carries a valid primary key
+// binding signature made by the subkey. Signatures that have passed their
+// expiration time are rejected.
+//
+// The public key is trusted because it has been provided by the caller, so
+// web of trust, key expiration and key servers are not considered. Keys and
+// subkeys that carry a valid revocation signature are rejected.
+package pgpsig
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PublicKey is an OpenPGP public key, along with the signing subkeys that
+// are bound to it.
+type PublicKey struct {
+	primary *key
+	subkeys []*key
+}
+
+// ParsePublicKey parses an OpenPGP public key. The key can be provided as
+// an ASCII-armored public key block, as base64-encoded packets or as binary
+// packets. It must hold exactly one primary key.
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	decoded, err := decode(data, "PUBLIC KEY BLOCK")
+	if err != nil {
+		return nil, err
+	}
+	packets, err := readPackets(decoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 || packets[0].tag != tagPublicKey {
+		return nil, errors.New("the data does not start with a public key")
+	}
+
+	primary, err := parseKey(packets[0].body)
+	if err != nil {
+		return nil, fmt.Errorf("the primary key could not be used: %w", err)
+	}
+	pk := &PublicKey{primary: primary}
+	now := time.Now()
+
+	// Examine the signatures that follow the primary key and each subkey.
+	var (
+		inSubkey  bool
+		subkey    *key
+		bound     bool
+		revoked   bool
+		finishKey = func() {
+			if subkey != nil && bound && !revoked {
+				pk.subkeys = append(pk.subkeys, subkey)
+			}
+			subkey, bound, revoked = nil, false, false
+		}
+	)
+	for _, p := range packets[1:] {
+		switch p.tag {
+		case tagPublicKey:
+			return nil, errors.New("the data holds more than one public key")
+		case tagSubkey:
+			finishKey()
+			inSubkey = true
+			subkey, err = parseKey(p.body)
+			if err != nil {
+				if !errors.Is(err, errUnsupportedKey) {
+					return nil, fmt.Errorf("a subkey could not be parsed: %w", err)
+				}
+				// Subkeys that cannot sign, such as encryption subkeys,
+				// are ignored.
+				subkey = nil
+			}
+		case tagSignature:
+			sig, err := parseSignature(p.body)
+			if err != nil {
+				// Signatures that cannot be verified, such as
+				// certifications made with unsupported algorithms, are
+				// ignored.
+				continue
+			}
+			switch {
+			case !inSubkey && sig.sigType == sigKeyRevocation:
+				if pk.primary.Verify(sig, sig.sum(keyHash(sig, pk.primary))) == nil {
+					return nil, errors.New("the public key has been revoked")
+				}
+			case subkey != nil && sig.sigType == sigSubkeyBinding:
+				if !sig.expired(now) && pk.primary.Verify(sig, sig.sum(keyHash(sig, pk.primary, subkey))) == nil {
+					bound = sig.canSign() && backSigned(sig, pk.primary, subkey, now)
+				}
+			case subkey != nil && sig.sigType == sigSubkeyRevocation:
+				if pk.primary.Verify(sig, sig.sum(keyHash(sig, pk.primary, subkey))) == nil {
+					revoked = true
+				}
+			}
+		case tagUserID, tagUserAttr, tagTrust:
+		default:
+			return nil, fmt.Errorf("the public key contains an unexpected packet with tag %d", p.tag)
+		}
+	}
+	finishKey()
+
+	return pk, nil
+}
+
+// Fingerprint returns the fingerprint of the primary key as an upper case
+// hexadecimal string.
+func (pk *PublicKey) Fingerprint() string {
+	return strings.ToUpper(hex.EncodeToString(pk.primary.fingerprint[:]))
+}
+
+// VerifyDetached returns a non-nil error if signature is not a valid
+// detached signature of data, made by the primary key or one of its signing
+// subkeys.
+//
+// The signature can be provided as an ASCII-armored signature block, as
+// base64-encoded packets or as binary packets. If it holds more than one
+// signature, at least one of them must be valid.
+func (pk *PublicKey) VerifyDetached(data, signature []byte) error {
+	decoded, err := decode(signature, "SIGNATURE")
+	if err != nil {
+		return err
+	}
+	packets, err := readPackets(decoded)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs []error
+	for _, p := range packets {
+		if p.tag != tagSignature {
+			continue
+		}
+		sig, err := parseSignature(p.body)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if sig.sigType != sigBinary && sig.sigType != sigText {
+			errs = append(errs, fmt.Errorf("the signature has type 0x%02x, which is not a document signature", sig.sigType))
+			continue
+		}
+
+		keys := pk.keysFor(sig)
+		if len(keys) == 0 {
+			errs = append(errs, errors.New("the signature was not made by the public key or one of its signing subkeys"))
+			continue
+		}
+
+		h := sig.newHash()
+		if sig.sigType == sigText {
+			h.Write(canonicalText(data))
+		} else {
+			h.Write(data)
+		}
+		digest := sig.sum(h)
+
+		for _, k := range keys {
+			err := k.Verify(sig, digest)
+			if err == nil && sig.expired(now) {
+				err = fmt.Errorf("the signature expired at %s", sig.expires().UTC().Format(time.RFC3339))
+			}
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return errors.New("the data does not contain a signature")
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
+
+// keysFor returns the keys that might have made sig. If the signature does
+// not identify its issuer, all of the keys are returned.
+func (pk *PublicKey) keysFor(sig *signature) []*key {
+	all := append([]*key{pk.primary}, pk.subkeys...)
+	if len(sig.issuers) == 0 {
+		return all
+	}
+	var keys []*key
+	for _, k := range all {
+		if slices.Contains(sig.issuers, k.KeyID()) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// keyHash returns a hash for sig that has been written with the given
+// keys, as required for signatures over keys and subkeys.
+func keyHash(sig *signature, keys ...*key) hash.Hash {
+	h := sig.newHash()
+	for _, k := range keys {
+		h.Write(k4Prefix(k.body))
+	}
+	return h
+}
+
+// backSigned returns true if the subkey binding signature sig carries a
+// valid primary key binding signature made by subkey. This proves that the
+// subkey's owner agreed to the binding, so that a primary key can't claim
+// the signatures of somebody else's subkey.
+func backSigned(sig *signature, primary, subkey *key, now time.Time) bool {
+	for _, body := range sig.embedded {
+		back, err := parseSignature(body)
+		if err != nil || back.sigType != sigPrimaryBinding || back.expired(now) {
+			continue
+		}
+		if subkey.Verify(back, back.sum(keyHash(back, primary, subkey))) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalText returns data with each line ending converted to CRLF, as
+// required for text signatures.
+func canonicalText(data []byte) []byte {
+	normalized := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n"))
+}
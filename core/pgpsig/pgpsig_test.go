@@ -0,0 +1,294 @@
+package pgpsig_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/pgpsig"
+)
+
+// The keys and signatures below were produced by GnuPG 2.2.
+
+const testData = `3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855a  tool-1.2.0-amd64.zip
+2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824  tool-1.2.0.msi
+`
+
+const testEd25519Key = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatIaoxYJKwYBBAHaRw8BAQdAxDuhUW4GWLoU7+4Yxjo6B44V6janEDLy5yzt
+qUcBCp+0GEVkIFRlc3QgPGVkQGV4YW1wbGUuY29tPoiQBBMWCAA4FiEEzrom7cu6
+kevfU4USp+1+D0X9xkIFAmrSGqMCGwMFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AA
+CgkQp+1+D0X9xkIJKQD8CQp0aGJ+DsIlhLY6u2RnA/Zhj7ofdw9AtFmTy5Kuo5sA
+/0SYQSd/Lx1eM0x+Uw3yCeUnSSqTZWXg47Lr6s765WAM
+=xpd/
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+const testEd25519Sig = `-----BEGIN PGP SIGNATURE-----
+
+iIUEABYIAC0WIQTOuibty7qR699ThRKn7X4PRf3GQgUCatIaow8cZWRAZXhhbXBs
+ZS5jb20ACgkQp+1+D0X9xkIIWwD8DtVd9AOjiBtHWXiANSmJxTydcnOMuVuXzkuj
+7El1BjYBAM1IPFdPyaFBq04AHtHoh5+rDumrB0ONIyxB1iYo8K0A
+=IRNu
+-----END PGP SIGNATURE-----
+`
+
+const testEd25519BinarySig = "iIUEABYIAC0WIQTOuibty7qR699ThRKn7X4PRf3GQgUCatIaow8cZWRAZXhhbXBsZS5jb20ACgkQp+1+D0X9xkIIWwD8DtVd9AOjiBtHWXiANSmJxTydcnOMuVuXzkuj7El1BjYBAM1IPFdPyaFBq04AHtHoh5+rDumrB0ONIyxB1iYo8K0A"
+
+const testEd25519SHA1Sig = `-----BEGIN PGP SIGNATURE-----
+
+iIUEABYCAC0WIQTOuibty7qR699ThRKn7X4PRf3GQgUCatIaow8cZWRAZXhhbXBs
+ZS5jb20ACgkQp+1+D0X9xkKtXgD/biN/yhQz1H+EgkWvzjpPEnObanpMxzP6Tvs7
+HPip5qIA/0W1KfXugu++hOjo6HNJyvANRyRDxH2fImyjxznfwAwF
+=Q7wp
+-----END PGP SIGNATURE-----
+`
+
+const testEd25519RevokedKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatIaoxYJKwYBBAHaRw8BAQdAxDuhUW4GWLoU7+4Yxjo6B44V6janEDLy5yzt
+qUcBCp+IeAQgFggAIBYhBM66Ju3LupHr31OFEqftfg9F/cZCBQJq0hqjAh0AAAoJ
+EKftfg9F/cZCwmcA/108epxwyZWObs6aTi7By2vUajVDhdds1DjlnaaJyQKKAP9H
+tCXDZOZm/Zn2yWiFQZXqKaufNk3Wa/0+ZQD+WrttBLQYRWQgVGVzdCA8ZWRAZXhh
+bXBsZS5jb20+iJAEExYIADgWIQTOuibty7qR699ThRKn7X4PRf3GQgUCatIaowIb
+AwULCQgHAgYVCgkICwIEFgIDAQIeAQIXgAAKCRCn7X4PRf3GQgkpAPwJCnRoYn4O
+wiWEtjq7ZGcD9mGPuh93D0C0WZPLkq6jmwD/RJhBJ38vHV4zTH5TDfIJ5SdJKpNl
+ZeDjsuvqzvrlYAw=
+=FxP6
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+const testRSAKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mQENBGrSGqMBCADagnDYydASBtNjKGKqijQCAS9WxAgoAbyZ9pg/RZWMAa1utkmw
+p/P1XiCeBR1T5RTopoOzK5i59mfqSpxiEdqNVz68h/X0fufhZE0rv5KF7DA7TwyK
+sKyPFA0Ync73CmLo6v9aeGGaEIU3qngt+/miwunROhQ7lyoaGq/9TKY+M2wCconc
+sTykePWuY8dT3uhQf3wDVyPyc1rnKXxA0QE1PnjA/0pFCbv4eLrL7KIhD4H79BN3
+EmitnIQMIFFflai2SczYIkQM5GXT9p45x9Vq55OEX/p4G73/GFmVjXqp09iwsz99
+/DnRLMsYcY04Y889m5AQtYrFaaIutg/Bl6nzABEBAAG0GlJTQSBUZXN0IDxyc2FA
+ZXhhbXBsZS5jb20+iQFOBBMBCgA4FiEENAmFbdx2WJOlqJG42nNLBPdVpUAFAmrS
+GqMCGwMFCwkIBwIGFQoJCAsCBBYCAwECHgECF4AACgkQ2nNLBPdVpUDBnQf+LpOE
+wQFWztZs1YODJ4pJqv/KDvg68VAFUhqr1J2tH/sQD4R+omVE7wohey7zZ99Sv0kg
+3BLBHThBNNimki5liq7THqwf119u0Aoeub0/HV57AKAh8scyzc+PuvBQDvoZDTZm
+tBVZWAPfiOedqm53rQf93VN7uVCvWUg8YJU4z2ruLKXL6kSz/ci8GEbaLl1Yh2ZW
+Yv9SmTHWqD+NRqF1CA/WyblB9Upp0/4EQQRid2fd8qCj0E2qXpYZX4ZUntjuGpgQ
+Nk0UaUdxFhSCUpvagtUdcL7hvkauTLS2JZu/3apJaAHqrakYn0eeSY/qs+sy0rTx
+MenX5jf1PFQy9irrIQ==
+=M2Zy
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+const testRSASig = `-----BEGIN PGP SIGNATURE-----
+
+iQFEBAABCgAuFiEENAmFbdx2WJOlqJG42nNLBPdVpUAFAmrSGqMQHHJzYUBleGFt
+cGxlLmNvbQAKCRDac0sE91WlQOsMB/9EVfT5i+uZUEi6BO1QNBq3nDSYm0IESNX6
+ZqJ39vwkeIGANBnGjEPOApyzw0wralPDRlfHd2U6wkSncoi/Dxi2qgSv74w5vA74
+qZ4q+4N0bDjYcQYQoGEmQtItRDz0I3gahIBTcX0eOtT0gN9j/nEXk5iNbXq3790l
+qYm9t/m/wf4GnMRIS63k+yoScXqvNLa9Yq4cUbe91uDq2EBYMDbtqTmK8xs6gsac
+//hnYKEh51JRA1ZCEQ4uXbY6DXMIGBp5qQ7tmx6MTqa6obIdbvzTJO1GlcBqmrkh
+3t4PtqBXPPSDhy7ZuA/l57+DKBUSe/CInjLB0VKbSfDduhasgJi4
+=mn9s
+-----END PGP SIGNATURE-----
+`
+
+const testRSATextSig = `-----BEGIN PGP SIGNATURE-----
+
+iQFEBAEBCgAuFiEENAmFbdx2WJOlqJG42nNLBPdVpUAFAmrSGqMQHHJzYUBleGFt
+cGxlLmNvbQAKCRDac0sE91WlQKbWCACLt4phiYiAPrTzMouS6wV6lVDfi5DT+D1V
+cKjSfqvTBk60rRDZUk+hkx0CNyOoUpSP7UKAX2k5xcTYMxUXIgu8B1XCnn1JsNIi
+zX4jNoWvUgOOpDY8UBCunwWMvbA+Z7A2Qc4oZMnDWNBkiL45m3GkRv3bEtlhCl22
+Ezrz5TzwjjZ2qgGsYL+gKBnVlHq8vQ5miYSsjMb8jiRDp/VunMkFGgujI0CYHW05
+2hyH/v0gFxKpPWUfZS6i/bZbc0bq4bE1hTIWrQnaS0DZG/+KIx1U1lKJ4n3yqeey
+pwE4+931B/UqS4EhoJpObDY1m78KqrLMOTxRmfkOipfKAriEIAcb
+=I+hU
+-----END PGP SIGNATURE-----
+`
+
+// testSubkeyKey is a certification-only Ed25519 primary key with an Ed25519
+// signing subkey. testSubkeySig was made by the subkey.
+const testSubkeyKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatIaoxYJKwYBBAHaRw8BAQdAnaxByo1DrX4vDn7XzJsTvJnp78ju5OsLxHAU
+X8OdMNW0GlN1YiBUZXN0IDxzdWJAZXhhbXBsZS5jb20+iJAEExYIADgWIQSO02Mo
+cmUfZBxfBh9xHyHp1eHnlwUCatIaowIbAQULCQgHAgYVCgkICwIEFgIDAQIeAQIX
+gAAKCRBxHyHp1eHnl3mfAP45hyYCCh7FVv+1aIwsKFUS822zNNXoelkOGNMbwmEf
+KQD/diUlrUzpoUpjz/sEFPLJIRIVCjpzh2taAtzeAfc0HQG4MwRq0hqjFgkrBgEE
+AdpHDwEBB0CkFWw+9ifjseZsb6W8kuylO+3Qech001m0DaPjfr922YjvBBgWCAAg
+FiEEjtNjKHJlH2QcXwYfcR8h6dXh55cFAmrSGqMCGwIAgQkQcR8h6dXh55d2IAQZ
+FggAHRYhBD8Ip93qrzwB/kRQrULXW87nm8RTBQJq0hqjAAoJEELXW87nm8RTHq0B
+AMuFyJgHhkyEJB2oJkO0pHxuXcNRuxPdDwzMJdCkoOLGAP9gcIYNuNAtPh82nJ6z
+muDEhtDl/MatIHL0JBD9YrOABdNpAQCN3PNg1FVtfNXsPQY2KT0Eb03eeerCJYQy
+Ms8SocFGxgD+N94Z/Wl23+opfG10sgZWVHuTrFO4tJCHP7bOCVUDRQ0=
+=o2ei
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+const testSubkeySig = `-----BEGIN PGP SIGNATURE-----
+
+iIYEABYIAC4WIQQ/CKfd6q88Af5EUK1C11vO55vEUwUCatIaoxAcc3ViQGV4YW1w
+bGUuY29tAAoJEELXW87nm8RTkqMA/jRHtUUNK0cNSXsiUQt93w7jmyBxXdtx2Bl+
+hjecIqS6AQC3Vw0Fwrj3vt7k1Q5HRqunrt1S/Ha+W2GDtsgapqqVBg==
+=IAk8
+-----END PGP SIGNATURE-----
+`
+
+// testRSAExpiredSig expired one minute after it was made.
+const testRSAExpiredSig = `-----BEGIN PGP SIGNATURE-----
+
+iQE5BAABCgAjFiEENAmFbdx2WJOlqJG42nNLBPdVpUAFAmrSGyAFgwAAADwACgkQ
+2nNLBPdVpUCEJwf9H0C65gUQXR4CljQzrASdO4Sqx18lXaqN40EvwIgQfiOyhWs5
+Gzh7WoUhLCza9HmnxvvHFwJKv6HB/H/+HbCgvAqiUh7Vz/f7uH0K4XM/mTEA5pFW
+dmYGQpyDVktGscoHQJ+attNRhw/NQGWyCQAVi88OEgcNJIMQZQLg5U2zjsj6dC2j
+1L7f/e0JLcJtOEmncEtmPt4iSs0Kfltd+e0RruvjrNI/nYlFXYvwcyeUEEprfbw7
+JHBDKWYgYEdINUqHPm9q79rEv2k6587z0Fi3yNd0IsgOA+/IGRmMm0x9cCO40T8U
+Y8e9FKIp7cKv7H8M94HevaZxSvMlBs7PsNB7jg==
+=l//i
+-----END PGP SIGNATURE-----
+`
+
+// testRSAExpiringSig expires in 2076.
+const testRSAExpiringSig = `-----BEGIN PGP SIGNATURE-----
+
+iQE5BAABCgAjFiEENAmFbdx2WJOlqJG42nNLBPdVpUAFAmrSGyAFg138DwAACgkQ
+2nNLBPdVpUB7mgf9GovTypB84bnHlt4SQ4UiJkpxYuj8uRISUq7lGXJu1dI3Y0/c
+wIkoW87gVbDYXdjNisfBdQwgBXDBghyblaLml1TUbzbtvynz7mx13GptM+NiiiWp
+3d1dqd2RqgO5fEjvFGiKJi6Al0DZzDjvbJBpyf8RhNrNsyUdIMpFjwA2x2BjpclY
+l008QQcKS+0xHWlWYt40uR4OTpBZbEkbGgXqD0DXKJpc+1toSVaRK7sGbrrNLrjd
+UVmoFKlm6KwnBcfJDVJawXXsAp2MpscK1p7+iEPZsAH6Ftp6ZJSLPGgOZPyXRdfy
+ntr1hRIeUA4TUU+BGrzOzz3zKU4752QJec8psg==
+=dhYa
+-----END PGP SIGNATURE-----
+`
+
+// testSubkeyNoBackSigKey is testSubkeyKey with the primary key binding
+// signature removed from the unhashed subpackets of its subkey binding, so
+// the binding itself is still valid.
+const testSubkeyNoBackSigKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatIaoxYJKwYBBAHaRw8BAQdAnaxByo1DrX4vDn7XzJsTvJnp78ju5OsLxHAU
+X8OdMNW0GlN1YiBUZXN0IDxzdWJAZXhhbXBsZS5jb20+iJAEExYIADgWIQSO02Mo
+cmUfZBxfBh9xHyHp1eHnlwUCatIaowIbAQULCQgHAgYVCgkICwIEFgIDAQIeAQIX
+gAAKCRBxHyHp1eHnl3mfAP45hyYCCh7FVv+1aIwsKFUS822zNNXoelkOGNMbwmEf
+KQD/diUlrUzpoUpjz/sEFPLJIRIVCjpzh2taAtzeAfc0HQG4MwRq0hqjFgkrBgEE
+AdpHDwEBB0CkFWw+9ifjseZsb6W8kuylO+3Qech001m0DaPjfr922Yh4BBgWCAAg
+FiEEjtNjKHJlH2QcXwYfcR8h6dXh55cFAmrSGqMCGwIACgkQcR8h6dXh55fTaQEA
+jdzzYNRVbXzV7D0GNik9BG9N3nnqwiWEMjLPEqHBRsYA/jfeGf1pdt/qKXxtdLIG
+VlR7k6xTuLSQhz+2zglVA0UN
+=dnXF
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// testSubkeyBadBackSigKey is testSubkeyKey with a corrupted primary key
+// binding signature.
+const testSubkeyBadBackSigKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mDMEatIaoxYJKwYBBAHaRw8BAQdAnaxByo1DrX4vDn7XzJsTvJnp78ju5OsLxHAU
+X8OdMNW0GlN1YiBUZXN0IDxzdWJAZXhhbXBsZS5jb20+iJAEExYIADgWIQSO02Mo
+cmUfZBxfBh9xHyHp1eHnlwUCatIaowIbAQULCQgHAgYVCgkICwIEFgIDAQIeAQIX
+gAAKCRBxHyHp1eHnl3mfAP45hyYCCh7FVv+1aIwsKFUS822zNNXoelkOGNMbwmEf
+KQD/diUlrUzpoUpjz/sEFPLJIRIVCjpzh2taAtzeAfc0HQG4MwRq0hqjFgkrBgEE
+AdpHDwEBB0CkFWw+9ifjseZsb6W8kuylO+3Qech001m0DaPjfr922YjvBBgWCAAg
+FiEEjtNjKHJlH2QcXwYfcR8h6dXh55cFAmrSGqMCGwIAgQkQcR8h6dXh55d2IAQZ
+FggAHRYhBD8Ip93qrzwB/kRQrULXW87nm8RTBQJq0hqjAAoJEELXW87nm8RTHq0B
+AMuFyJgHhkyEJB2oJkO0pHxuXcNRuxPdDwzMJdCkoOLGAP9gcIYNuNAtPh82nJ6z
+muDEhtDl/MatIHL0JBD9YrOABNNpAQCN3PNg1FVtfNXsPQY2KT0Eb03eeerCJYQy
+Ms8SocFGxgD+N94Z/Wl23+opfG10sgZWVHuTrFO4tJCHP7bOCVUDRQ0=
+=SdWk
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func testBinarySig(t *testing.T) string {
+	t.Helper()
+	sig, err := base64.StdEncoding.DecodeString(testEd25519BinarySig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(sig)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	fixtures := []struct {
+		Name        string
+		Key         string
+		Fingerprint string
+		Err         string
+	}{
+		{Name: "ed25519", Key: testEd25519Key, Fingerprint: "CEBA26EDCBBA91EBDF538512A7ED7E0F45FDC642"},
+		{Name: "rsa", Key: testRSAKey, Fingerprint: "3409856DDC765893A5A891B8DA734B04F755A540"},
+		{Name: "subkey", Key: testSubkeyKey, Fingerprint: "8ED3632872651F641C5F061F711F21E9D5E1E797"},
+		{Name: "subkey-no-back-sig", Key: testSubkeyNoBackSigKey, Fingerprint: "8ED3632872651F641C5F061F711F21E9D5E1E797"},
+		{Name: "revoked", Key: testEd25519RevokedKey, Err: "revoked"},
+		{Name: "signature", Key: testEd25519Sig, Err: "public key block"},
+		{Name: "concatenated", Key: strings.Replace(testEd25519Key, "-----END", "-----BEGIN", 1), Err: "does not end"},
+		{Name: "garbage", Key: "not a key", Err: "not an armored"},
+	}
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			key, err := pgpsig.ParsePublicKey([]byte(fixture.Key))
+			if fixture.Err != "" {
+				if err == nil || !strings.Contains(err.Error(), fixture.Err) {
+					t.Fatalf("got error %v, want an error containing \"%s\"", err, fixture.Err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := key.Fingerprint(); got != fixture.Fingerprint {
+				t.Errorf("fingerprint: got %s, want %s", got, fixture.Fingerprint)
+			}
+		})
+	}
+}
+
+func TestVerifyDetached(t *testing.T) {
+	fixtures := []struct {
+		Name string
+		Key  string
+		Sig  string
+		Data string
+		Err  string
+	}{
+		{Name: "ed25519", Key: testEd25519Key, Sig: testEd25519Sig},
+		{Name: "ed25519-base64", Key: testEd25519Key, Sig: testEd25519BinarySig},
+		{Name: "ed25519-binary", Key: testEd25519Key, Sig: testBinarySig(t)},
+		{Name: "rsa", Key: testRSAKey, Sig: testRSASig},
+		{Name: "rsa-text", Key: testRSAKey, Sig: testRSATextSig},
+		{Name: "rsa-text-crlf", Key: testRSAKey, Sig: testRSATextSig, Data: strings.ReplaceAll(testData, "\n", "\r\n")},
+		{Name: "subkey", Key: testSubkeyKey, Sig: testSubkeySig},
+		{Name: "subkey-no-back-sig", Key: testSubkeyNoBackSigKey, Sig: testSubkeySig, Err: "not made by the public key"},
+		{Name: "subkey-bad-back-sig", Key: testSubkeyBadBackSigKey, Sig: testSubkeySig, Err: "not made by the public key"},
+		{Name: "rsa-expiring", Key: testRSAKey, Sig: testRSAExpiringSig},
+		{Name: "rsa-expired", Key: testRSAKey, Sig: testRSAExpiredSig, Err: "expired at 2026-10-16T12:41:00Z"},
+		{Name: "tampered", Key: testEd25519Key, Sig: testEd25519Sig, Data: testData + "x", Err: "does not match"},
+		{Name: "rsa-tampered", Key: testRSAKey, Sig: testRSASig, Data: strings.ToUpper(testData), Err: "does not match"},
+		{Name: "binary-crlf", Key: testRSAKey, Sig: testRSASig, Data: strings.ReplaceAll(testData, "\n", "\r\n"), Err: "does not match"},
+		{Name: "sha1", Key: testEd25519Key, Sig: testEd25519SHA1Sig, Err: "not accepted"},
+		{Name: "wrong-key", Key: testEd25519Key, Sig: testRSASig, Err: "not made by the public key"},
+		{Name: "primary-for-subkey", Key: testEd25519Key, Sig: testSubkeySig, Err: "not made by the public key"},
+		{Name: "key-as-signature", Key: testEd25519Key, Sig: testEd25519Key, Err: "signature block"},
+	}
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			key, err := pgpsig.ParsePublicKey([]byte(fixture.Key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := fixture.Data
+			if data == "" {
+				data = testData
+			}
+			err = key.VerifyDetached([]byte(data), []byte(fixture.Sig))
+			if fixture.Err != "" {
+				if err == nil || !strings.Contains(err.Error(), fixture.Err) {
+					t.Fatalf("got error %v, want an error containing \"%s\"", err, fixture.Err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package pgpsig
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	_ "crypto/sha256" // Register SHA-224 and SHA-256.
+	_ "crypto/sha512" // Register SHA-384 and SHA-512.
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"time"
+)
+
+// Signature types.
+const (
+	sigBinary           = 0x00
+	sigText             = 0x01
+	sigSubkeyBinding    = 0x18
+	sigPrimaryBinding   = 0x19
+	sigKeyRevocation    = 0x20
+	sigSubkeyRevocation = 0x28
+)
+
+// Signature subpacket types.
+const (
+	subpacketCreationTime       = 2
+	subpacketExpirationTime     = 3
+	subpacketKeyExpirationTime  = 9
+	subpacketPreferredSymmetric = 11
+	subpacketIssuer             = 16
+	subpacketNotation           = 20
+	subpacketPreferredHash      = 21
+	subpacketPreferredCompress  = 22
+	subpacketKeyServerPrefs     = 23
+	subpacketPrimaryUserID      = 25
+	subpacketKeyFlags           = 27
+	subpacketReasonRevoked      = 29
+	subpacketFeatures           = 30
+	subpacketEmbeddedSignature  = 32
+	subpacketIssuerFingerprint  = 33
+	subpacketPreferredAEAD      = 34
+)
+
+// keyFlagSign is the key flag that permits a key to sign data.
+const keyFlagSign = 0x02
+
+// hashAlgorithms maps OpenPGP hash algorithm IDs to the hash functions
+// that are accepted. MD5 and SHA-1 are deliberately absent.
+var hashAlgorithms = map[byte]crypto.Hash{
+	8:  crypto.SHA256,
+	9:  crypto.SHA384,
+	10: crypto.SHA512,
+	11: crypto.SHA224,
+}
+
+// signature is an OpenPGP v4 signature.
+type signature struct {
+	sigType   byte
+	algorithm byte
+	hash      crypto.Hash
+
+	// hashed holds the part of the signature packet that is included in
+	// the signed digest, from the version through the hashed subpackets.
+	hashed []byte
+
+	// keyFlags holds the key flags subpacket, if present.
+	keyFlags []byte
+
+	// created and lifetime hold the creation time of the signature and
+	// the period after which it expires. A lifetime of zero means that the
+	// signature does not expire.
+	created  time.Time
+	lifetime time.Duration
+
+	// embedded holds the bodies of any embedded signatures, which carry
+	// the primary key binding signatures of signing subkeys.
+	embedded [][]byte
+
+	// issuers holds the key IDs that identify the key that made the
+	// signature.
+	issuers []uint64
+
+	left16  [2]byte
+	rsaS    []byte
+	ed25519 []byte
+}
+
+// parseSignature parses the body of a signature packet.
+func parseSignature(body []byte) (*signature, error) {
+	if len(body) < 6 {
+		return nil, errors.New("the signature packet is too short")
+	}
+	if body[0] != 4 {
+		return nil, fmt.Errorf("version %d signatures are not supported", body[0])
+	}
+
+	sig := &signature{
+		sigType:   body[1],
+		algorithm: body[2],
+	}
+	h, ok := hashAlgorithms[body[3]]
+	if !ok {
+		return nil, fmt.Errorf("the signature uses hash algorithm %d, which is not accepted", body[3])
+	}
+	sig.hash = h
+
+	// Read the hashed subpackets.
+	hashedLen := int(binary.BigEndian.Uint16(body[4:6]))
+	if len(body) < 6+hashedLen+2 {
+		return nil, errors.New("the signature packet is too short")
+	}
+	sig.hashed = body[:6+hashedLen]
+	if err := sig.readSubpackets(body[6:6+hashedLen], true); err != nil {
+		return nil, err
+	}
+	rest := body[6+hashedLen:]
+
+	// Read the unhashed subpackets. Only issuer information is taken from
+	// them, since it is only used to select a key.
+	unhashedLen := int(binary.BigEndian.Uint16(rest[:2]))
+	if len(rest) < 2+unhashedLen+2 {
+		return nil, errors.New("the signature packet is too short")
+	}
+	if err := sig.readSubpackets(rest[2:2+unhashedLen], false); err != nil {
+		return nil, err
+	}
+	rest = rest[2+unhashedLen:]
+
+	copy(sig.left16[:], rest[:2])
+	rest = rest[2:]
+
+	// Read the signature values.
+	switch sig.algorithm {
+	case algorithmRSA, algorithmRSASignOnly:
+		s, _, err := readMPI(rest)
+		if err != nil {
+			return nil, err
+		}
+		sig.rsaS = s
+	case algorithmEdDSALegacy:
+		r, rest, err := readMPI(rest)
+		if err != nil {
+			return nil, err
+		}
+		s, _, err := readMPI(rest)
+		if err != nil {
+			return nil, err
+		}
+		r, s = leftPad(r, 32), leftPad(s, 32)
+		if r == nil || s == nil {
+			return nil, errors.New("the EdDSA signature is not valid")
+		}
+		sig.ed25519 = append(r, s...)
+	case algorithmEd25519:
+		if len(rest) < ed25519.SignatureSize {
+			return nil, errors.New("the Ed25519 signature is too short")
+		}
+		sig.ed25519 = rest[:ed25519.SignatureSize]
+	default:
+		return nil, fmt.Errorf("the signature uses public key algorithm %d, which is not supported", sig.algorithm)
+	}
+
+	return sig, nil
+}
+
+// readSubpackets reads the issuer, key flags, time and embedded signature
+// subpackets from data. If hashed is true, it returns an error for
+// unrecognized critical subpackets, and key flags and times are recorded.
+// Times are only taken from the hashed subpackets because they can't be
+// trusted otherwise.
+func (sig *signature) readSubpackets(data []byte, hashed bool) error {
+	for len(data) > 0 {
+		var length, offset int
+		switch first := int(data[0]); {
+		case first < 192:
+			length, offset = first, 1
+		case first < 255:
+			if len(data) < 2 {
+				return errors.New("the signature ends within a subpacket")
+			}
+			length, offset = (first-192)<<8+int(data[1])+192, 2
+		default:
+			if len(data) < 5 {
+				return errors.New("the signature ends within a subpacket")
+			}
+			length, offset = int(binary.BigEndian.Uint32(data[1:5])), 5
+		}
+		if length < 1 || len(data)-offset < length {
+			return errors.New("the signature ends within a subpacket")
+		}
+		critical := data[offset]&0x80 != 0
+		kind := data[offset] & 0x7F
+		content := data[offset+1 : offset+length]
+		data = data[offset+length:]
+
+		switch kind {
+		case subpacketIssuer:
+			if len(content) == 8 {
+				sig.issuers = append(sig.issuers, binary.BigEndian.Uint64(content))
+			}
+		case subpacketIssuerFingerprint:
+			if len(content) == 21 && content[0] == 4 {
+				sig.issuers = append(sig.issuers, binary.BigEndian.Uint64(content[13:]))
+			}
+		case subpacketKeyFlags:
+			if hashed {
+				sig.keyFlags = content
+			}
+		case subpacketCreationTime:
+			if hashed && len(content) == 4 {
+				sig.created = time.Unix(int64(binary.BigEndian.Uint32(content)), 0)
+			}
+		case subpacketExpirationTime:
+			if hashed && len(content) == 4 {
+				sig.lifetime = time.Duration(binary.BigEndian.Uint32(content)) * time.Second
+			}
+		case subpacketEmbeddedSignature:
+			// Embedded signatures are verified on their own, so they are
+			// accepted from either set of subpackets.
+			sig.embedded = append(sig.embedded, content)
+		case subpacketKeyExpirationTime, subpacketPreferredSymmetric, subpacketNotation,
+			subpacketPreferredHash, subpacketPreferredCompress, subpacketKeyServerPrefs,
+			subpacketPrimaryUserID, subpacketReasonRevoked, subpacketFeatures,
+			subpacketPreferredAEAD:
+		default:
+			if hashed && critical {
+				return fmt.Errorf("the signature has a critical subpacket of type %d, which is not recognized", kind)
+			}
+		}
+	}
+	return nil
+}
+
+// canSign returns true if the signature's key flags permit the key it
+// binds to sign data. If the signature does not have key flags, the key is
+// permitted to sign.
+func (sig *signature) canSign() bool {
+	return len(sig.keyFlags) == 0 || sig.keyFlags[0]&keyFlagSign != 0
+}
+
+// expired returns true if the signature has an expiration time that has
+// passed at the given time.
+func (sig *signature) expired(now time.Time) bool {
+	return sig.lifetime > 0 && !now.Before(sig.expires())
+}
+
+// expires returns the time at which the signature expires.
+func (sig *signature) expires() time.Time {
+	return sig.created.Add(sig.lifetime)
+}
+
+// newHash returns a hash for computing the signature's digest.
+func (sig *signature) newHash() hash.Hash {
+	return sig.hash.New()
+}
+
+// sum writes the hashed part of the signature and its trailer to h, and
+// returns the resulting digest.
+func (sig *signature) sum(h hash.Hash) []byte {
+	h.Write(sig.hashed)
+	var trailer [6]byte
+	trailer[0] = 4
+	trailer[1] = 0xFF
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(sig.hashed)))
+	h.Write(trailer[:])
+	return h.Sum(nil)
+}
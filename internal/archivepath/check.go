@@ -0,0 +1,77 @@
+package archivepath
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// Reason describes why an archive entry path is unsafe to extract.
+type Reason string
+
+// Reasons that an archive entry path can be unsafe.
+const (
+	ReasonEmpty     Reason = "empty path"
+	ReasonAbsolute  Reason = "absolute path"
+	ReasonParent    Reason = "parent directory reference"
+	ReasonVolume    Reason = "drive letter or alternate data stream"
+	ReasonBackslash Reason = "backslash separator"
+	ReasonDevice    Reason = "reserved device name"
+	ReasonTrailing  Reason = "trailing dot or space"
+	ReasonLink      Reason = "symbolic link"
+)
+
+// Check returns the reason that an archive entry with the given name and
+// mode is unsafe to extract. It returns an empty string if the entry is
+// safe.
+//
+// The name must use forward slashes as separators. Names that could
+// resolve to a location outside of the extraction directory on Windows are
+// considered unsafe, as are symbolic links, which could be used to redirect
+// subsequent entries.
+func Check(name string, mode fs.FileMode) Reason {
+	if mode&fs.ModeSymlink != 0 {
+		return ReasonLink
+	}
+
+	name = strings.TrimSuffix(name, "/")
+	switch {
+	case name == "":
+		return ReasonEmpty
+	case strings.HasPrefix(name, "/"):
+		return ReasonAbsolute
+	case strings.Contains(name, `\`):
+		return ReasonBackslash
+	case strings.Contains(name, ":"):
+		return ReasonVolume
+	}
+
+	for segment := range strings.SplitSeq(name, "/") {
+		switch {
+		case segment == "..":
+			return ReasonParent
+		case segment == "" || segment == ".":
+			continue
+		case strings.HasSuffix(segment, ".") || strings.HasSuffix(segment, " "):
+			return ReasonTrailing
+		case isDeviceName(segment):
+			return ReasonDevice
+		}
+	}
+
+	return ""
+}
+
+// isDeviceName returns true if segment refers to a reserved Windows device,
+// with or without an extension.
+func isDeviceName(segment string) bool {
+	base, _, _ := strings.Cut(segment, ".")
+	base = strings.ToUpper(strings.TrimRight(base, " "))
+	switch base {
+	case "CON", "PRN", "AUX", "NUL", "CONIN$", "CONOUT$":
+		return true
+	}
+	if len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) {
+		return base[3] >= '1' && base[3] <= '9'
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package archivepath_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/internal/archivepath"
+)
+
+func TestCheck(t *testing.T) {
+	fixtures := []struct {
+		Name   string
+		Mode   fs.FileMode
+		Reason archivepath.Reason
+	}{
+		{Name: "setup.exe"},
+		{Name: "lang/en-US/"},
+		{Name: "lang/./strings.dll"},
+		{Name: "console.exe"},
+		{Name: "", Reason: archivepath.ReasonEmpty},
+		{Name: "/etc/passwd", Reason: archivepath.ReasonAbsolute},
+		{Name: "../../Windows/System32/evil.dll", Reason: archivepath.ReasonParent},
+		{Name: "lang/../../evil.dll", Reason: archivepath.ReasonParent},
+		{Name: `..\evil.dll`, Reason: archivepath.ReasonBackslash},
+		{Name: "C:/Windows/evil.dll", Reason: archivepath.ReasonVolume},
+		{Name: "setup.exe:stream", Reason: archivepath.ReasonVolume},
+		{Name: "lang/NUL.txt", Reason: archivepath.ReasonDevice},
+		{Name: "com1", Reason: archivepath.ReasonDevice},
+		{Name: "evil.dll.", Reason: archivepath.ReasonTrailing},
+		{Name: "link", Mode: fs.ModeSymlink, Reason: archivepath.ReasonLink},
+	}
+
+	for _, fixture := range fixtures {
+		if got := archivepath.Check(fixture.Name, fixture.Mode); got != fixture.Reason {
+			t.Errorf("%q: got %q, want %q", fixture.Name, got, fixture.Reason)
+		}
+	}
+}
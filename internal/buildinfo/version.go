@@ -77,6 +77,22 @@ func (v Version) Build() int {
 	return getVersionSegment(v, 3)
 }
 
+// Compare returns an integer comparing v with other, segment by segment.
+// The result is -1 if v is older than other, 1 if v is newer than other,
+// and 0 if they are the same.
+func (v Version) Compare(other Version) int {
+	for i := range 4 {
+		a, b := getVersionSegment(v, i), getVersionSegment(other, i)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	return 0
+}
+
 func getVersionSegment(v Version, index int) int {
 	parts := strings.Split(string(v), ".")
 	if len(parts) <= index {
@@ -0,0 +1,58 @@
+// Package httpcache handles the HTTP cache validators that allow a cached
+// download to be revalidated or resumed.
+package httpcache
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Validators hold the HTTP cache validators that were returned by the
+// source of a file when it was downloaded.
+type Validators struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last-modified,omitempty"`
+}
+
+// FromResponse returns the cache validators for url that are present in
+// the headers of resp.
+func FromResponse(url string, resp *http.Response) Validators {
+	return Validators{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// IsZero returns true if the cache validators are empty.
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// SetConditional adds headers to req that make it conditional on the
+// resource having changed since the validators were recorded.
+func (v Validators) SetConditional(req *http.Request) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
+
+// SetIfRange adds an If-Range header to req, so that the server sends the
+// entire resource instead of the requested range if it has changed since
+// the validators were recorded.
+//
+// Weak entity tags cannot be used with If-Range, so the modification time
+// is used in their place. If neither validator is usable, req is not
+// modified.
+func (v Validators) SetIfRange(req *http.Request) {
+	switch {
+	case v.ETag != "" && !strings.HasPrefix(v.ETag, "W/"):
+		req.Header.Set("If-Range", v.ETag)
+	case v.LastModified != "":
+		req.Header.Set("If-Range", v.LastModified)
+	}
+}
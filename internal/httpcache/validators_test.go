@@ -0,0 +1,61 @@
+package httpcache_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/internal/httpcache"
+)
+
+const testLastModified = "Wed, 21 Oct 2015 07:28:00 GMT"
+
+func TestValidators(t *testing.T) {
+	fixtures := []struct {
+		Name            string
+		Validators      httpcache.Validators
+		Zero            bool
+		IfNoneMatch     string
+		IfModifiedSince string
+		IfRange         string
+	}{
+		{Name: "empty", Validators: httpcache.Validators{URL: "https://example.com/setup.msi"}, Zero: true},
+		{Name: "etag", Validators: httpcache.Validators{ETag: `"v2"`}, IfNoneMatch: `"v2"`, IfRange: `"v2"`},
+		{Name: "weak-etag", Validators: httpcache.Validators{ETag: `W/"v2"`}, IfNoneMatch: `W/"v2"`},
+		{Name: "last-modified", Validators: httpcache.Validators{LastModified: testLastModified}, IfModifiedSince: testLastModified, IfRange: testLastModified},
+		{Name: "both", Validators: httpcache.Validators{ETag: `"v2"`, LastModified: testLastModified}, IfNoneMatch: `"v2"`, IfModifiedSince: testLastModified, IfRange: `"v2"`},
+		{Name: "weak-both", Validators: httpcache.Validators{ETag: `W/"v2"`, LastModified: testLastModified}, IfNoneMatch: `W/"v2"`, IfModifiedSince: testLastModified, IfRange: testLastModified},
+	}
+
+	for _, fixture := range fixtures {
+		if got := fixture.Validators.IsZero(); got != fixture.Zero {
+			t.Errorf("%s: got zero %t, want %t", fixture.Name, got, fixture.Zero)
+		}
+
+		conditional, _ := http.NewRequest("GET", "https://example.com/setup.msi", nil)
+		fixture.Validators.SetConditional(conditional)
+		if got := conditional.Header.Get("If-None-Match"); got != fixture.IfNoneMatch {
+			t.Errorf("%s: got If-None-Match %q, want %q", fixture.Name, got, fixture.IfNoneMatch)
+		}
+		if got := conditional.Header.Get("If-Modified-Since"); got != fixture.IfModifiedSince {
+			t.Errorf("%s: got If-Modified-Since %q, want %q", fixture.Name, got, fixture.IfModifiedSince)
+		}
+
+		ranged, _ := http.NewRequest("GET", "https://example.com/setup.msi", nil)
+		fixture.Validators.SetIfRange(ranged)
+		if got := ranged.Header.Get("If-Range"); got != fixture.IfRange {
+			t.Errorf("%s: got If-Range %q, want %q", fixture.Name, got, fixture.IfRange)
+		}
+	}
+}
+
+func TestFromResponse(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("ETag", `"abc"`)
+	resp.Header.Set("Last-Modified", testLastModified)
+
+	got := httpcache.FromResponse("https://example.com/setup.msi", resp)
+	want := httpcache.Validators{URL: "https://example.com/setup.msi", ETag: `"abc"`, LastModified: testLastModified}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,4 @@
+// Package logfile names the log files written by installers and collects
+// the lines at their end, so that they can be reported when an installer
+// fails.
+package logfile
@@ -0,0 +1,110 @@
+package logfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/utility/bytesconv"
+)
+
+// MaxTailBytes is the maximum number of bytes that will be read from the
+// end of a log file when collecting its tail.
+const MaxTailBytes = 64 * 1024
+
+// ReadTail returns up to the given number of lines from the end of the log
+// file at path.
+//
+// Windows Installer logs might be encoded as UTF-16, so a byte order mark
+// at the start of the file is used to interpret its contents.
+func ReadTail(path string, lines int) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := fi.Size()
+
+	// Look for a byte order mark.
+	var bom [2]byte
+	if _, err := io.ReadFull(file, bom[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return "", nil
+		}
+		return "", err
+	}
+	var order binary.ByteOrder
+	switch {
+	case bytesconv.HasUTF16BOM(bom[:], binary.LittleEndian):
+		order = binary.LittleEndian
+	case bytesconv.HasUTF16BOM(bom[:], binary.BigEndian):
+		order = binary.BigEndian
+	}
+
+	// Determine where to start reading. UTF-16 data must remain aligned to
+	// two byte boundaries.
+	offset := int64(0)
+	if order != nil {
+		offset = 2
+	}
+	if start := size - MaxTailBytes; start > offset {
+		offset = start
+		if order != nil && offset%2 != 0 {
+			offset++
+		}
+	}
+
+	data := make([]byte, size-offset)
+	if _, err := file.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	var text string
+	if order != nil {
+		text = bytesconv.DecodeUTF16(data, order)
+	} else {
+		text = bytesconv.DecodeString(data)
+	}
+
+	return Tail(text, lines), nil
+}
+
+// Tail returns the last n lines of s.
+func Tail(s string, n int) string {
+	s = strings.TrimRight(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	if n <= 0 || s == "" {
+		return ""
+	}
+	end := len(s)
+	for i := 0; i < n; i++ {
+		index := strings.LastIndexByte(s[:end], '\n')
+		if index < 0 {
+			return s
+		}
+		end = index
+	}
+	return s[end+1:]
+}
+
+// Name returns a file name built from the given parts, which are joined
+// with periods. Characters that are not suitable for use in file names are
+// replaced with underscores.
+func Name(parts ...string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.Join(parts, "."))
+}
@@ -0,0 +1,90 @@
+package logfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/internal/logfile"
+)
+
+func TestTail(t *testing.T) {
+	fixtures := []struct {
+		Text  string
+		Lines int
+		Tail  string
+	}{
+		{Text: "", Lines: 3, Tail: ""},
+		{Text: "one\ntwo\nthree", Lines: 0, Tail: ""},
+		{Text: "one\ntwo\nthree", Lines: -1, Tail: ""},
+		{Text: "one\ntwo\nthree", Lines: 1, Tail: "three"},
+		{Text: "one\ntwo\nthree", Lines: 2, Tail: "two\nthree"},
+		{Text: "one\ntwo\nthree", Lines: 5, Tail: "one\ntwo\nthree"},
+		{Text: "one\r\ntwo\r\nthree\r\n", Lines: 2, Tail: "two\nthree"},
+		{Text: "one\ntwo\n\n\n", Lines: 1, Tail: "two"},
+		{Text: "one\n\nthree", Lines: 2, Tail: "\nthree"},
+	}
+
+	for _, fixture := range fixtures {
+		if got := logfile.Tail(fixture.Text, fixture.Lines); got != fixture.Tail {
+			t.Errorf("%q (%d lines): got %q, want %q", fixture.Text, fixture.Lines, got, fixture.Tail)
+		}
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	long := strings.Repeat("filler line\n", logfile.MaxTailBytes/12+100) + "last line\n"
+
+	fixtures := []struct {
+		Name  string
+		Data  []byte
+		Lines int
+		Tail  string
+	}{
+		{Name: "empty", Data: nil, Lines: 2, Tail: ""},
+		{Name: "one-byte", Data: []byte("x"), Lines: 2, Tail: ""},
+		{Name: "ansi", Data: []byte("MSI (s) (1C:20) Starting\r\nAction ended: Return value 3.\r\n"), Lines: 1, Tail: "Action ended: Return value 3."},
+		{Name: "utf16le", Data: []byte("\xff\xfea\x00\n\x00b\x00\n\x00"), Lines: 1, Tail: "b"},
+		{Name: "utf16be", Data: []byte("\xfe\xff\x00a\x00\n\x00b\x00\n"), Lines: 2, Tail: "a\nb"},
+		{Name: "long", Data: []byte(long), Lines: 2, Tail: "filler line\nlast line"},
+	}
+
+	dir := t.TempDir()
+	for _, fixture := range fixtures {
+		path := filepath.Join(dir, fixture.Name+".log")
+		if err := os.WriteFile(path, fixture.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := logfile.ReadTail(path, fixture.Lines)
+		if err != nil {
+			t.Errorf("%s: %v", fixture.Name, err)
+			continue
+		}
+		if got != fixture.Tail {
+			t.Errorf("%s: got %q, want %q", fixture.Name, got, fixture.Tail)
+		}
+	}
+
+	if _, err := logfile.ReadTail(filepath.Join(dir, "missing.log"), 1); err == nil {
+		t.Errorf("missing: expected an error")
+	}
+}
+
+func TestName(t *testing.T) {
+	fixtures := []struct {
+		Parts []string
+		Name  string
+	}{
+		{Parts: []string{"deployment", "command"}, Name: "deployment.command"},
+		{Parts: []string{"my-app", "install_x64", "20250102T030405Z"}, Name: "my-app.install_x64.20250102T030405Z"},
+		{Parts: []string{`..\..\Windows`, "cmd"}, Name: ".._.._Windows.cmd"},
+		{Parts: []string{"a b/c:d", "é"}, Name: "a_b_c_d._"},
+	}
+
+	for _, fixture := range fixtures {
+		if got := logfile.Name(fixture.Parts...); got != fixture.Name {
+			t.Errorf("%q: got %q, want %q", fixture.Parts, got, fixture.Name)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Package outputbuffer captures the output of commands and scripts within
+// the limits of their output options.
+package outputbuffer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// Buffer is a writer that captures output within the limits of a set of
+// output options. Output beyond the limits is discarded, but the number of
+// discarded bytes is recorded.
+//
+// Depending on the options, the beginning of the output, the end of the
+// output, or both are retained.
+//
+// Writes to a buffer always succeed, so that the process producing the
+// output is never blocked.
+type Buffer struct {
+	keep      lbdeploy.OutputTruncation
+	maxLines  int
+	even      bool
+	headLimit int64 // A negative value indicates no limit
+	tailLimit int64
+	head      bytes.Buffer
+	tail      []byte
+	discarded int64
+	file      io.Writer
+	fileErr   error
+}
+
+// New returns a buffer that captures output according to opts.
+//
+// If even is true, the portions of output that are retained always hold an
+// even number of bytes, so that UTF-16 output remains aligned.
+func New(opts lbdeploy.OutputOptions, even bool) *Buffer {
+	b := &Buffer{
+		keep:     opts.Keep,
+		maxLines: opts.MaxLines,
+		even:     even,
+	}
+
+	limit := opts.ByteLimit()
+	switch {
+	case limit < 0:
+		b.headLimit = -1
+	case opts.Keep == lbdeploy.OutputKeepTail:
+		b.tailLimit = b.align(limit)
+	case opts.Keep == lbdeploy.OutputKeepHeadTail:
+		b.headLimit = b.align(limit / 2)
+		b.tailLimit = b.align(limit - limit/2)
+	default:
+		b.headLimit = b.align(limit)
+	}
+
+	return b
+}
+
+// align rounds n down to an even number if the buffer keeps its output
+// aligned.
+func (b *Buffer) align(n int64) int64 {
+	if b.even {
+		return n &^ 1
+	}
+	return n
+}
+
+// Tee causes all output written to b to also be written to w, without
+// regard to the limits of b. If a write to w fails, no further output is
+// written to it.
+func (b *Buffer) Tee(w io.Writer) {
+	b.file = w
+}
+
+// Write captures as much of p as the limits allow. It always returns len(p)
+// and a nil error.
+func (b *Buffer) Write(p []byte) (int, error) {
+	n := len(p)
+
+	// Send all of the output to the tee, if there is one.
+	if b.file != nil && b.fileErr == nil {
+		_, b.fileErr = b.file.Write(p)
+	}
+
+	// Fill the head first.
+	if b.headLimit < 0 {
+		b.head.Write(p)
+		return n, nil
+	}
+	if remaining := b.headLimit - int64(b.head.Len()); remaining > 0 {
+		take := min(remaining, int64(len(p)))
+		b.head.Write(p[:take])
+		p = p[take:]
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+
+	// Send the rest to the tail, if there is one.
+	if b.tailLimit == 0 {
+		b.discarded += int64(len(p))
+		return n, nil
+	}
+	b.tail = append(b.tail, p...)
+
+	// Avoid trimming the tail on every write by letting it grow to twice
+	// its limit.
+	if int64(len(b.tail)) > b.tailLimit*2 {
+		b.trimTail()
+	}
+
+	return n, nil
+}
+
+// trimTail discards the beginning of the tail until it fits within its
+// limit.
+func (b *Buffer) trimTail() {
+	excess := int64(len(b.tail)) - b.tailLimit
+	if excess <= 0 {
+		return
+	}
+	if b.even {
+		excess += excess & 1
+	}
+	b.discarded += excess
+	b.tail = append(b.tail[:0], b.tail[excess:]...)
+}
+
+// Discarded returns the number of bytes that were discarded.
+func (b *Buffer) Discarded() int64 {
+	b.trimTail()
+	return b.discarded
+}
+
+// TeeErr returns the first error encountered while writing to the tee,
+// if any.
+func (b *Buffer) TeeErr() error {
+	return b.fileErr
+}
+
+// Bytes returns the retained beginning and end of the output. The end is
+// only non-empty when the buffer keeps the end of the output.
+func (b *Buffer) Bytes() (head, tail []byte) {
+	b.trimTail()
+	return b.head.Bytes(), b.tail
+}
+
+// String returns the captured output as a string, which is assumed to be
+// UTF-8. Invalid sequences are replaced. If any of the output was omitted,
+// a note indicating how much was omitted is included in its place.
+func (b *Buffer) String() string {
+	head, tail := b.Bytes()
+	if b.discarded > 0 {
+		head = TrimPartialUTF8(head, false)
+		tail = TrimPartialUTF8(tail, true)
+	}
+	return b.Format(strings.ToValidUTF8(string(head), "\uFFFD"), strings.ToValidUTF8(string(tail), "\uFFFD"))
+}
+
+// Format returns head and tail, which are the decoded portions of output
+// returned by Bytes, joined together. If any of the output was omitted, a
+// note indicating how much was omitted is included in its place. The
+// result is limited to the maximum number of lines of the buffer.
+func (b *Buffer) Format(head, tail string) string {
+	b.trimTail()
+
+	var out strings.Builder
+	out.WriteString(head)
+	if b.discarded > 0 {
+		writeOmission(&out, b.discarded, "byte", "bytes")
+	}
+	out.WriteString(tail)
+
+	return LimitLines(out.String(), b.maxLines, b.keep)
+}
+
+// TrimPartialUTF8 removes an incomplete UTF-8 sequence from the end of
+// data, or from the start of data if leading is true. If data is not
+// UTF-8, it is returned as-is.
+func TrimPartialUTF8(data []byte, leading bool) []byte {
+	if utf8.Valid(data) {
+		return data
+	}
+	for i := 1; i < utf8.UTFMax && i < len(data); i++ {
+		if leading {
+			if utf8.Valid(data[i:]) {
+				return data[i:]
+			}
+		} else if utf8.Valid(data[:len(data)-i]) {
+			return data[:len(data)-i]
+		}
+	}
+	return data
+}
+
+// LimitLines limits text to the given number of lines, keeping the portion
+// of text indicated by keep. If any lines are omitted, a note indicating
+// how many were omitted is included in their place. If max is zero, text
+// is returned as-is.
+func LimitLines(text string, max int, keep lbdeploy.OutputTruncation) string {
+	if max <= 0 {
+		return text
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= max {
+		return text
+	}
+	omitted := int64(len(lines) - max)
+
+	var head, tail []string
+	switch keep {
+	case lbdeploy.OutputKeepTail:
+		tail = lines[len(lines)-max:]
+	case lbdeploy.OutputKeepHeadTail:
+		head = lines[:max/2]
+		tail = lines[len(lines)-(max-max/2):]
+	default:
+		head = lines[:max]
+	}
+
+	var out strings.Builder
+	out.WriteString(strings.Join(head, ""))
+	writeOmission(&out, omitted, "line", "lines")
+	out.WriteString(strings.Join(tail, ""))
+	return out.String()
+}
+
+// writeOmission writes a note to out indicating that n units of output were
+// omitted. The note is written on its own line.
+func writeOmission(out *strings.Builder, n int64, singular, plural string) {
+	if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		out.WriteString("\n")
+	}
+	unit := plural
+	if n == 1 {
+		unit = singular
+	}
+	fmt.Fprintf(out, "[%d %s omitted]\n", n, unit)
+}
@@ -0,0 +1,87 @@
+package outputbuffer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/internal/outputbuffer"
+)
+
+func TestBuffer(t *testing.T) {
+	fixtures := []struct {
+		Name      string
+		Options   lbdeploy.OutputOptions
+		Even      bool
+		Writes    []string
+		Output    string
+		Discarded int64
+	}{
+		{Name: "empty", Options: lbdeploy.OutputOptions{MaxBytes: 8}, Output: ""},
+		{Name: "within", Options: lbdeploy.OutputOptions{MaxBytes: 8}, Writes: []string{"abc", "def"}, Output: "abcdef"},
+		{Name: "unlimited", Options: lbdeploy.OutputOptions{MaxBytes: -1}, Writes: []string{strings.Repeat("x", 100)}, Output: strings.Repeat("x", 100)},
+		{Name: "head", Options: lbdeploy.OutputOptions{MaxBytes: 4}, Writes: []string{"abc", "def", "ghi"}, Output: "abcd\n[5 bytes omitted]\n", Discarded: 5},
+		{Name: "tail", Options: lbdeploy.OutputOptions{MaxBytes: 4, Keep: lbdeploy.OutputKeepTail}, Writes: []string{"abc", "def", "ghi"}, Output: "[5 bytes omitted]\nfghi", Discarded: 5},
+		{Name: "head-tail", Options: lbdeploy.OutputOptions{MaxBytes: 4, Keep: lbdeploy.OutputKeepHeadTail}, Writes: []string{"abcdefghi"}, Output: "ab\n[5 bytes omitted]\nhi", Discarded: 5},
+		{Name: "odd", Options: lbdeploy.OutputOptions{MaxBytes: 5}, Writes: []string{"abcdefg"}, Output: "abcde\n[2 bytes omitted]\n", Discarded: 2},
+		{Name: "odd-even", Options: lbdeploy.OutputOptions{MaxBytes: 5}, Even: true, Writes: []string{"abcdefg"}, Output: "abcd\n[3 bytes omitted]\n", Discarded: 3},
+		{Name: "tail-even", Options: lbdeploy.OutputOptions{MaxBytes: 5, Keep: lbdeploy.OutputKeepTail}, Even: true, Writes: []string{"abcdefg"}, Output: "[4 bytes omitted]\nefg", Discarded: 4},
+		{Name: "one-byte", Options: lbdeploy.OutputOptions{MaxBytes: 3}, Writes: []string{"abcd"}, Output: "abc\n[1 byte omitted]\n", Discarded: 1},
+		{Name: "utf8-head", Options: lbdeploy.OutputOptions{MaxBytes: 4}, Writes: []string{"abcäöü"}, Output: "abc\n[5 bytes omitted]\n", Discarded: 5},
+		{Name: "utf8-tail", Options: lbdeploy.OutputOptions{MaxBytes: 3, Keep: lbdeploy.OutputKeepTail}, Writes: []string{"äöü"}, Output: "[3 bytes omitted]\nü", Discarded: 3},
+		{Name: "lines-head", Options: lbdeploy.OutputOptions{MaxLines: 2}, Writes: []string{"one\ntwo\nthree\nfour\n"}, Output: "one\ntwo\n[2 lines omitted]\n"},
+		{Name: "lines-tail", Options: lbdeploy.OutputOptions{MaxLines: 2, Keep: lbdeploy.OutputKeepTail}, Writes: []string{"one\ntwo\nthree\nfour"}, Output: "[2 lines omitted]\nthree\nfour"},
+		{Name: "lines-head-tail", Options: lbdeploy.OutputOptions{MaxLines: 3, Keep: lbdeploy.OutputKeepHeadTail}, Writes: []string{"one\ntwo\nthree\nfour\nfive\n"}, Output: "one\n[2 lines omitted]\nfour\nfive\n"},
+		{Name: "lines-within", Options: lbdeploy.OutputOptions{MaxLines: 3}, Writes: []string{"one\ntwo\nthree\n"}, Output: "one\ntwo\nthree\n"},
+	}
+
+	for _, fixture := range fixtures {
+		b := outputbuffer.New(fixture.Options, fixture.Even)
+		for _, w := range fixture.Writes {
+			if n, err := b.Write([]byte(w)); n != len(w) || err != nil {
+				t.Errorf("%s: write returned %d, %v", fixture.Name, n, err)
+			}
+		}
+		if got := b.String(); got != fixture.Output {
+			t.Errorf("%s: got output %q, want %q", fixture.Name, got, fixture.Output)
+		}
+		if got := b.Discarded(); got != fixture.Discarded {
+			t.Errorf("%s: got %d bytes discarded, want %d", fixture.Name, got, fixture.Discarded)
+		}
+	}
+}
+
+func TestBufferTee(t *testing.T) {
+	var file strings.Builder
+	b := outputbuffer.New(lbdeploy.OutputOptions{MaxBytes: 2}, false)
+	b.Tee(&file)
+	b.Write([]byte("abc"))
+	b.Write([]byte("def"))
+	if got := file.String(); got != "abcdef" {
+		t.Errorf("got tee output %q, want %q", got, "abcdef")
+	}
+	if err := b.TeeErr(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTrimPartialUTF8(t *testing.T) {
+	fixtures := []struct {
+		Data    string
+		Leading bool
+		Out     string
+	}{
+		{Data: "abc", Out: "abc"},
+		{Data: "ab\xc3", Out: "ab"},
+		{Data: "ab\xe2\x82", Out: "ab"},
+		{Data: "\xa4bc", Leading: true, Out: "bc"},
+		{Data: "\x82\xacbc", Leading: true, Out: "bc"},
+		{Data: "\xff\xfe\xfd\xfc", Out: "\xff\xfe\xfd\xfc"},
+	}
+
+	for _, fixture := range fixtures {
+		if got := string(outputbuffer.TrimPartialUTF8([]byte(fixture.Data), fixture.Leading)); got != fixture.Out {
+			t.Errorf("%q (leading %t): got %q, want %q", fixture.Data, fixture.Leading, got, fixture.Out)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Package throughput detects a collapse in the throughput of long-running
+// file operations, such as the extraction of large packages.
+package throughput
+
+import "time"
+
+// Window is the number of bytes in each window that is measured by a
+// monitor.
+const Window = 64 << 20
+
+// Collapse is the factor by which the throughput of a window must fall
+// below the peak throughput for it to be considered collapsed.
+const Collapse = 4
+
+// Monitor watches the throughput of a file operation in fixed size
+// windows, and detects when it collapses.
+//
+// The zero value is not ready for use; use NewMonitor instead.
+type Monitor struct {
+	windowStarted time.Time
+	windowBytes   int64
+	peakBytes     int64
+	peakTime      time.Duration
+	reported      bool
+}
+
+// NewMonitor returns a throughput monitor with its first window starting
+// at the given time.
+func NewMonitor(started time.Time) Monitor {
+	return Monitor{windowStarted: started}
+}
+
+// Add records n bytes that were written at the given time. It returns true
+// the first time that a complete window's throughput falls well below the
+// peak throughput, along with the measurements of that window.
+func (m *Monitor) Add(n int64, now time.Time) (collapsed bool, bytes int64, elapsed time.Duration) {
+	m.windowBytes += n
+	if m.windowBytes < Window {
+		return false, 0, 0
+	}
+
+	bytes, elapsed = m.windowBytes, now.Sub(m.windowStarted)
+	m.windowBytes, m.windowStarted = 0, now
+	if elapsed <= 0 {
+		return false, 0, 0
+	}
+
+	rate := float64(bytes) / elapsed.Seconds()
+	switch peak := float64(m.peakBytes) / m.peakTime.Seconds(); {
+	case m.peakTime == 0 || rate > peak:
+		m.peakBytes, m.peakTime = bytes, elapsed
+	case !m.reported && rate*Collapse < peak:
+		m.reported = true
+		return true, bytes, elapsed
+	}
+
+	return false, 0, 0
+}
+
+// Peak returns the measurements of the window with the highest throughput
+// that has been observed so far.
+func (m *Monitor) Peak() (bytes int64, elapsed time.Duration) {
+	return m.peakBytes, m.peakTime
+}
@@ -0,0 +1,74 @@
+package throughput_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/internal/throughput"
+)
+
+func TestMonitor(t *testing.T) {
+	type step struct {
+		Bytes     int64
+		Elapsed   time.Duration
+		Collapsed bool
+	}
+
+	fixtures := []struct {
+		Name  string
+		Steps []step
+	}{
+		{Name: "partial-window", Steps: []step{{Bytes: throughput.Window - 1, Elapsed: time.Hour}}},
+		{Name: "steady", Steps: []step{
+			{Bytes: throughput.Window, Elapsed: time.Second},
+			{Bytes: throughput.Window, Elapsed: 2 * time.Second},
+			{Bytes: throughput.Window, Elapsed: time.Second},
+		}},
+		{Name: "collapse", Steps: []step{
+			{Bytes: throughput.Window, Elapsed: time.Second},
+			{Bytes: throughput.Window, Elapsed: 5 * time.Second, Collapsed: true},
+		}},
+		{Name: "collapse-reported-once", Steps: []step{
+			{Bytes: throughput.Window, Elapsed: time.Second},
+			{Bytes: throughput.Window, Elapsed: 5 * time.Second, Collapsed: true},
+			{Bytes: throughput.Window, Elapsed: 5 * time.Second},
+		}},
+		{Name: "accumulated", Steps: []step{
+			{Bytes: throughput.Window / 2, Elapsed: time.Second / 2},
+			{Bytes: throughput.Window / 2, Elapsed: time.Second / 2},
+			{Bytes: throughput.Window / 2, Elapsed: 5 * time.Second},
+			{Bytes: throughput.Window / 2, Elapsed: 5 * time.Second, Collapsed: true},
+		}},
+		{Name: "no-elapsed-time", Steps: []step{
+			{Bytes: throughput.Window, Elapsed: 0},
+			{Bytes: throughput.Window, Elapsed: time.Hour},
+		}},
+	}
+
+	for _, fixture := range fixtures {
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		monitor := throughput.NewMonitor(now)
+		for i, s := range fixture.Steps {
+			now = now.Add(s.Elapsed)
+			collapsed, bytes, elapsed := monitor.Add(s.Bytes, now)
+			if collapsed != s.Collapsed {
+				t.Errorf("%s: step %d: got collapsed %t, want %t", fixture.Name, i, collapsed, s.Collapsed)
+			}
+			if collapsed && (bytes != throughput.Window || elapsed <= 0) {
+				t.Errorf("%s: step %d: unexpected window measurements: %d bytes in %s", fixture.Name, i, bytes, elapsed)
+			}
+		}
+	}
+}
+
+func TestMonitorPeak(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	monitor := throughput.NewMonitor(now)
+	monitor.Add(throughput.Window, now.Add(2*time.Second))
+	monitor.Add(throughput.Window, now.Add(3*time.Second))
+	monitor.Add(throughput.Window, now.Add(6*time.Second))
+
+	if bytes, elapsed := monitor.Peak(); bytes != throughput.Window || elapsed != time.Second {
+		t.Errorf("got peak of %d bytes in %s, want %d bytes in 1s", bytes, elapsed, throughput.Window)
+	}
+}
@@ -0,0 +1,38 @@
+package leafbridge
+
+import (
+	"context"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplan"
+	"github.com/leafbridge/leafbridge/platform/windows/lbengine"
+)
+
+// Invoke validates the deployment and invokes the given flow within it on
+// the local system. It returns when the flow has finished, or when ctx is
+// cancelled.
+func Invoke(ctx context.Context, dep lbdeploy.Deployment, flow lbdeploy.FlowID, opts Options) error {
+	engine := lbengine.NewDeploymentEngine(dep, lbengine.Options{
+		Events:   opts.recorder(),
+		Force:    opts.Force,
+		Timeout:  opts.Timeout,
+		Bundle:   opts.Bundle,
+		Agent:    opts.Agent,
+		Reverify: opts.Reverify,
+		Resume:   opts.Resume,
+
+		TraceConditions: opts.TraceConditions,
+	})
+	return engine.Invoke(ctx, flow)
+}
+
+// Plan describes what the given flow within the deployment would do on the
+// local system, without changing it. It returns the steps of the plan in
+// order. Only the Force and Bundle options are used.
+func Plan(ctx context.Context, dep lbdeploy.Deployment, flow lbdeploy.FlowID, opts Options) ([]lbplan.Step, error) {
+	engine := lbengine.NewDeploymentEngine(dep, lbengine.Options{
+		Force:  opts.Force,
+		Bundle: opts.Bundle,
+	})
+	return engine.Plan(ctx, flow)
+}
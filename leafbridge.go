@@ -0,0 +1,116 @@
+// Package leafbridge exposes the deployment pipeline of leafbridge-deploy
+// to other Go programs, so that they can embed LeafBridge instead of
+// running the command line tool.
+//
+// A deployment is loaded with LoadDeployment or ReadDeployment, checked
+// with Validate, tested against simulated systems with Test, planned on the
+// local system with Plan, and invoked on the local system with Invoke.
+// Events are delivered to the handler provided in Options, and can be
+// decoded with the registry returned by NewEventRegistry.
+//
+// The functions in this package are the supported interface for embedding
+// LeafBridge. The core and platform packages that they are built on may
+// change between releases.
+package leafbridge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbrollout"
+	"github.com/leafbridge/leafbridge/core/lbupdateevent"
+)
+
+// startingEventID is the start of the event ID number sequence for events
+// sent to the Windows event log.
+const startingEventID = 100
+
+// NewEventRegistry returns an event registry that holds all of the event
+// types that LeafBridge may record.
+func NewEventRegistry() (*lbevent.Registry, error) {
+	events := lbevent.NewRegistry(startingEventID)
+	if err := events.Add(lbdeployevent.Registrations...); err != nil {
+		return nil, err
+	}
+	if err := events.Add(lbupdateevent.Registrations...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// LoadDeployment reads the deployment file at path, which must end in
+// deploy.json. The deployment is not validated.
+func LoadDeployment(path string) (dep lbdeploy.Deployment, err error) {
+	if path == "" {
+		return dep, errors.New("missing deployment configuraiton file path")
+	}
+	if !strings.HasSuffix(path, "deploy.json") {
+		return dep, errors.New("the provided deployment file path must end in deploy.json")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return dep, err
+	}
+	defer file.Close()
+	return ReadDeployment(file)
+}
+
+// ReadDeployment decodes a deployment from r. The deployment is not
+// validated.
+func ReadDeployment(r io.Reader) (dep lbdeploy.Deployment, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return dep, err
+	}
+	err = json.Unmarshal(data, &dep)
+	return
+}
+
+// LoadRollout reads the rollout file at path, which must end in
+// rollout.json, and validates it. It also reads the deployment of each
+// step, relative to the rollout file.
+func LoadRollout(path string) (rollout lbrollout.Rollout, deployments []lbdeploy.Deployment, err error) {
+	if path == "" {
+		return rollout, nil, errors.New("missing rollout file path")
+	}
+	if !strings.HasSuffix(path, "rollout.json") {
+		return rollout, nil, errors.New("the provided rollout file path must end in rollout.json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rollout, nil, err
+	}
+	if err := json.Unmarshal(data, &rollout); err != nil {
+		return rollout, nil, err
+	}
+	if err := rollout.Validate(); err != nil {
+		return rollout, nil, err
+	}
+
+	// Load the deployment for each step, relative to the rollout file.
+	dir := filepath.Dir(path)
+	for i, step := range rollout.Steps {
+		dep, err := LoadDeployment(filepath.Join(dir, filepath.FromSlash(step.Deployment)))
+		if err != nil {
+			return rollout, nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		deployments = append(deployments, dep)
+	}
+
+	return rollout, deployments, nil
+}
+
+// Validate returns an error if the deployment is not valid. Invoke
+// validates the deployment itself, so calling Validate first is only
+// necessary to reject a deployment before anything else is done with it.
+func Validate(dep lbdeploy.Deployment) error {
+	return dep.Validate()
+}
@@ -0,0 +1,32 @@
+package leafbridge_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge"
+)
+
+func TestReadDeployment(t *testing.T) {
+	dep, err := leafbridge.ReadDeployment(strings.NewReader(`{"id": "contoso-app", "name": "Contoso App"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dep.ID != "contoso-app" {
+		t.Errorf("ID: got \"%s\", want \"contoso-app\"", dep.ID)
+	}
+}
+
+func TestLoadDeploymentPath(t *testing.T) {
+	for _, path := range []string{"", "contoso.json", "contoso.deploy.yaml"} {
+		if _, err := leafbridge.LoadDeployment(path); err == nil {
+			t.Errorf("expected an error for \"%s\"", path)
+		}
+	}
+}
+
+func TestNewEventRegistry(t *testing.T) {
+	if _, err := leafbridge.NewEventRegistry(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package leafbridge
+
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbbundle"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Options hold configuration options for the invocation of a deployment.
+//
+// Events recorded during the invocation are passed to Handler. If Handler
+// is nil, events are discarded. Origin is included in each event record.
+// If its run ID is empty, a new one is assigned to each invocation.
+//
+// If Timeout is non-zero, it overrides the timeout of the deployment.
+//
+// If Bundle is non-nil, the deployment is invoked entirely from the bundle.
+// Package files are copied from the bundle and verified, and no network
+// requests are made.
+//
+// If Agent is true, the deployment is invoked unattended on behalf of an
+// agent, and disruptive flows are deferred until the machine is idle.
+//
+// If Reverify is true, staged package files are hashed again even when a
+// verification record from an earlier run says that they can be trusted.
+//
+// If Resume is true, flows that were interrupted by a crash or a restart
+// of the system continue from their last completed action instead of
+// starting over.
+//
+// If TraceConditions is true, a debug event is recorded for each condition
+// that is evaluated, describing what was observed on the system.
+//
+// Bundle, Agent, Reverify and Resume are only supported on Windows.
+type Options struct {
+	Handler         lbevent.Handler
+	Origin          lbevent.Origin
+	Force           bool
+	Timeout         time.Duration
+	Bundle          *lbbundle.Bundle
+	Agent           bool
+	Reverify        bool
+	Resume          bool
+	TraceConditions bool
+}
+
+// recorder returns an event recorder for the options.
+func (opts Options) recorder() lbevent.Recorder {
+	return lbevent.Recorder{Handler: opts.Handler, Origin: opts.Origin}
+}
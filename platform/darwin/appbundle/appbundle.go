@@ -0,0 +1,51 @@
+// Package appbundle installs macOS application bundles.
+package appbundle
+
+import (
+	"fmt"
+	"os"
+)
+
+// Replace moves the application bundle that has been copied from source to
+// staging into place at destination.
+//
+// An existing bundle at destination is moved aside first, and is restored
+// if the new bundle cannot be moved into place. It is removed once the new
+// bundle is in place.
+func Replace(source, staging, destination string) error {
+	// The staging directory is created with restricted permissions, which
+	// ditto leaves in place, so apply the permissions of the source bundle.
+	fi, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(staging, fi.Mode().Perm()); err != nil {
+		return err
+	}
+
+	// Move any existing bundle aside.
+	var previous string
+	if _, err := os.Lstat(destination); err == nil {
+		previous = staging + "-previous"
+		if err := os.Rename(destination, previous); err != nil {
+			return fmt.Errorf("the existing \"%s\" bundle could not be moved aside: %w", destination, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	// Move the new bundle into place, restoring the existing bundle if that
+	// fails.
+	if err := os.Rename(staging, destination); err != nil {
+		if previous != "" {
+			os.Rename(previous, destination)
+		}
+		return fmt.Errorf("the \"%s\" bundle could not be moved into place: %w", destination, err)
+	}
+
+	if previous != "" {
+		os.RemoveAll(previous)
+	}
+
+	return nil
+}
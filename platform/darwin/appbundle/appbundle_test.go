@@ -0,0 +1,109 @@
+package appbundle_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/platform/darwin/appbundle"
+)
+
+// writeBundle creates a bundle at path that holds a single file with the
+// given name.
+func writeBundle(t *testing.T, path, file string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, file), []byte(file), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	fixtures := []struct {
+		Name     string
+		Existing bool
+	}{
+		{Name: "install"},
+		{Name: "replace", Existing: true},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			dir := t.TempDir()
+			source := filepath.Join(dir, "image", "Contoso.app")
+			staging := filepath.Join(dir, "Applications", ".Contoso.app-123")
+			destination := filepath.Join(dir, "Applications", "Contoso.app")
+			writeBundle(t, source, "new")
+			writeBundle(t, staging, "new")
+			if err := os.Chmod(staging, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			if fixture.Existing {
+				writeBundle(t, destination, "old")
+			}
+
+			if err := appbundle.Replace(source, staging, destination); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := os.Stat(filepath.Join(destination, "new")); err != nil {
+				t.Errorf("the new bundle is not in place: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(destination, "old")); err == nil {
+				t.Error("a file from the previous bundle was left behind")
+			}
+			fi, err := os.Stat(destination)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := fi.Mode().Perm(); perm != 0o755 {
+				t.Errorf("got permissions %v, want %v", perm, os.FileMode(0o755))
+			}
+			entries, err := os.ReadDir(filepath.Dir(destination))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("got %d entries beside the bundle, want only the bundle", len(entries))
+			}
+		})
+	}
+}
+
+func TestReplaceRestore(t *testing.T) {
+	// Place the destination within the staging directory, so that the new
+	// bundle can't be moved into place after the existing bundle has been
+	// moved aside.
+	dir := t.TempDir()
+	source := filepath.Join(dir, "image", "Contoso.app")
+	staging := filepath.Join(dir, "staging")
+	destination := filepath.Join(staging, "Contoso.app")
+	writeBundle(t, source, "new")
+	writeBundle(t, destination, "old")
+
+	if err := appbundle.Replace(source, staging, destination); err == nil {
+		t.Fatal("the bundle was replaced")
+	}
+
+	if _, err := os.Stat(filepath.Join(destination, "old")); err != nil {
+		t.Errorf("the existing bundle was not restored: %v", err)
+	}
+	if _, err := os.Stat(staging + "-previous"); err == nil {
+		t.Error("the existing bundle was left aside")
+	}
+}
+
+func TestReplaceMissingSource(t *testing.T) {
+	dir := t.TempDir()
+	destination := filepath.Join(dir, "Contoso.app")
+	writeBundle(t, destination, "old")
+
+	if err := appbundle.Replace(filepath.Join(dir, "missing"), filepath.Join(dir, "staging"), destination); err == nil {
+		t.Fatal("the bundle was replaced without a source")
+	}
+	if _, err := os.Stat(filepath.Join(destination, "old")); err != nil {
+		t.Errorf("the existing bundle was disturbed: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package darwinfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// knownFolderMap is a map of predefined directory resource IDs to known
+// folder locations.
+type knownFolderMap map[lbdeploy.DirectoryResourceID]knownFolder
+
+// knownFolder describes how to locate a known folder on macOS.
+type knownFolder struct {
+	path       string
+	home       bool // Path is relative to the user's home directory
+	protected  bool
+	privileged bool // Only administrators can modify the folder
+}
+
+// Known folders that are recognized by their resource IDs.
+//
+// The folders used by Windows deployments are mapped to their closest
+// equivalents on macOS, so that one deployment document can serve both
+// platforms.
+var knownFolders = knownFolderMap{
+	"program-data":        knownFolder{path: "/Library/Application Support", privileged: true},
+	"program-files":       knownFolder{path: "/Applications", privileged: true},
+	"program-files-x64":   knownFolder{path: "/Applications", privileged: true},
+	"system":              knownFolder{path: "/usr/bin", protected: true, privileged: true},
+	"applications":        knownFolder{path: "/Applications", privileged: true},
+	"library":             knownFolder{path: "/Library", privileged: true},
+	"application-support": knownFolder{path: "/Library/Application Support", privileged: true},
+	"launch-daemons":      knownFolder{path: "/Library/LaunchDaemons", privileged: true},
+	"launch-agents":       knownFolder{path: "/Library/LaunchAgents", privileged: true},
+	"preferences":         knownFolder{path: "/Library/Preferences", privileged: true},
+	"usr-local":           knownFolder{path: "/usr/local", privileged: true},
+	"user-applications":   knownFolder{path: "Applications", home: true},
+	"user-library":        knownFolder{path: "Library", home: true},
+}
+
+// Path returns the absolute path of the folder.
+func (folder knownFolder) Path() (string, error) {
+	if !folder.home {
+		return folder.path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if home == "" {
+		return "", errors.New("the home directory of the current user is not known")
+	}
+	return filepath.Join(home, filepath.FromSlash(folder.path)), nil
+}
@@ -0,0 +1,52 @@
+package darwinplatform
+
+import (
+	"errors"
+	"os/user"
+	"slices"
+)
+
+// AccountDetector reports on the local users and groups of the local
+// system.
+type AccountDetector struct{}
+
+// LocalUserExists returns true if a user with the given name exists.
+func (AccountDetector) LocalUserExists(name string) (bool, error) {
+	_, err := user.Lookup(name)
+	if err != nil {
+		var unknown user.UnknownUserError
+		if errors.As(err, &unknown) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// IsGroupMember returns true if the user named by account is a member of
+// the group. It returns an error if the group does not exist.
+func (AccountDetector) IsGroupMember(group, account string) (bool, error) {
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return false, err
+	}
+	u, err := user.Lookup(account)
+	if err != nil {
+		var unknown user.UnknownUserError
+		if errors.As(err, &unknown) {
+			return false, nil
+		}
+		return false, err
+	}
+	gids, err := u.GroupIds()
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(gids, g.Gid), nil
+}
+
+// HasUserRight returns an error, because user rights are not available on
+// macOS.
+func (AccountDetector) HasUserRight(right, account string) (bool, error) {
+	return false, errors.New("user rights are not available on macOS")
+}
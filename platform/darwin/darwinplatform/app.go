@@ -0,0 +1,100 @@
+package darwinplatform
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// AppDetector looks up applications by their application bundles and
+// installer package receipts.
+type AppDetector struct{}
+
+// IsInstalled returns true if the application's bundle exists, or if the
+// receipt for its installer package is present.
+func (AppDetector) IsInstalled(app lbdeploy.Application) (bool, error) {
+	switch {
+	case app.Mac.Bundle != "":
+		fi, err := os.Stat(app.Mac.Bundle)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return fi.IsDir(), nil
+	case app.Mac.PackageID != "":
+		_, installed, err := packageVersion(app.Mac.PackageID)
+		return installed, err
+	default:
+		return false, fmt.Errorf("the \"%s\" application does not identify a bundle or package for macOS", app.Name)
+	}
+}
+
+// Version returns the version of the application. The version of a bundle
+// is taken from its CFBundleShortVersionString. The version of a package
+// is taken from its receipt.
+//
+// If the application is not installed, it returns an empty string.
+func (AppDetector) Version(app lbdeploy.Application) (datatype.Version, error) {
+	switch {
+	case app.Mac.Bundle != "":
+		return bundleVersion(app.Mac.Bundle)
+	case app.Mac.PackageID != "":
+		version, _, err := packageVersion(app.Mac.PackageID)
+		return version, err
+	default:
+		return "", fmt.Errorf("the \"%s\" application does not identify a bundle or package for macOS", app.Name)
+	}
+}
+
+// bundleVersion reads the version from the Info.plist file of the bundle
+// at path. PlistBuddy is used so that binary property lists can be read.
+func bundleVersion(path string) (datatype.Version, error) {
+	plist := filepath.Join(path, "Contents", "Info.plist")
+	if _, err := os.Stat(plist); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	output, err := exec.Command("/usr/libexec/PlistBuddy", "-c", "Print :CFBundleShortVersionString", plist).Output()
+	if err != nil {
+		return "", fmt.Errorf("the version of the \"%s\" bundle could not be read: %w", path, err)
+	}
+
+	return datatype.Version(strings.TrimSpace(string(output))), nil
+}
+
+// packageVersion returns the version recorded in the receipt of the
+// installer package with the given identifier. If the package is not
+// installed, it returns false.
+func packageVersion(id string) (version datatype.Version, installed bool, err error) {
+	output, err := exec.CommandContext(context.Background(), "pkgutil", "--pkg-info", id).Output()
+	if err != nil {
+		// pkgutil exits with a status of 1 when there is no receipt.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to query the receipt for the \"%s\" package: %w", id, err)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		if value, found := strings.CutPrefix(scanner.Text(), "version: "); found {
+			return datatype.Version(strings.TrimSpace(value)), true, nil
+		}
+	}
+
+	return "", true, nil
+}
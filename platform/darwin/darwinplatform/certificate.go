@@ -0,0 +1,17 @@
+package darwinplatform
+
+import (
+	"errors"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// CertificateDetector reports that certificate stores are not available
+// on macOS.
+type CertificateDetector struct{}
+
+// CertificateExists returns an error, because certificate stores are not
+// available on macOS.
+func (CertificateDetector) CertificateExists(cert lbdeploy.CertificateResource) (bool, error) {
+	return false, errors.New("certificate stores are not available on macOS")
+}
@@ -0,0 +1,91 @@
+package darwinplatform
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// HardwareDetector reports on the hardware and firmware of the local
+// system.
+type HardwareDetector struct{}
+
+// InstalledMemory returns the amount of installed memory in bytes.
+func (HardwareDetector) InstalledMemory() (int64, error) {
+	output, err := sysctl("hw.memsize")
+	if err != nil {
+		return 0, fmt.Errorf("failed to determine the amount of installed memory: %w", err)
+	}
+	memory, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the amount of installed memory: %w", err)
+	}
+	return memory, nil
+}
+
+// LogicalProcessors returns the number of logical processors.
+func (HardwareDetector) LogicalProcessors() (int, error) {
+	return runtime.NumCPU(), nil
+}
+
+// TPMVersion always returns zero, because Macs do not have a trusted
+// platform module.
+func (HardwareDetector) TPMVersion() (int, error) {
+	return 0, nil
+}
+
+// SecureBootEnabled returns an error, because UEFI Secure Boot is not
+// available on macOS.
+func (HardwareDetector) SecureBootEnabled() (bool, error) {
+	return false, errors.New("UEFI Secure Boot is not available on macOS")
+}
+
+// Hypervisor returns the hypervisor that the local system is running on.
+// It returns an empty string if the system is a physical machine.
+//
+// The hypervisor is identified by the model name of the virtual hardware.
+// Virtual machines with unrecognized model names are reported as
+// running on some other hypervisor.
+func (HardwareDetector) Hypervisor() (lbdeploy.Hypervisor, error) {
+	present, err := sysctl("kern.hv_vmm_present")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine whether a hypervisor is present: %w", err)
+	}
+	if present != "1" {
+		return "", nil
+	}
+	model, err := sysctl("hw.model")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the hardware model: %w", err)
+	}
+	if hypervisor := lbdeploy.IdentifyHypervisor("", model); hypervisor != "" {
+		return hypervisor, nil
+	}
+	return lbdeploy.HypervisorOther, nil
+}
+
+// sysctl returns the value of a kernel state variable.
+func sysctl(name string) (string, error) {
+	output, err := exec.Command("sysctl", "-n", name).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// BitLockerProtected returns an error, because BitLocker is not available
+// on macOS.
+func (HardwareDetector) BitLockerProtected() (bool, error) {
+	return false, errors.New("BitLocker is not available on macOS")
+}
+
+// ActivePowerPlan returns an error, because Windows power plans are not
+// available on macOS.
+func (HardwareDetector) ActivePowerPlan() (string, error) {
+	return "", errors.New("power plans are not available on macOS")
+}
@@ -0,0 +1,59 @@
+package darwinplatform
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+)
+
+// globalPreferences is the domain that holds the system-wide language and
+// region settings.
+const globalPreferences = "/Library/Preferences/.GlobalPreferences"
+
+// LocaleDetector reports on the language and regional settings of the
+// local system.
+type LocaleDetector struct{}
+
+// UILanguage returns the preferred display language of the system.
+func (LocaleDetector) UILanguage() (string, error) {
+	output, err := exec.Command("defaults", "read", globalPreferences, "AppleLanguages").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the system languages: %w", err)
+	}
+
+	// The languages are printed as a property list array:
+	//
+	//	(
+	//	    "en-US",
+	//	    de
+	//	)
+	for line := range strings.Lines(string(output)) {
+		line = strings.Trim(strings.TrimSpace(line), `",`)
+		if line != "" && line != "(" && line != ")" {
+			return line, nil
+		}
+	}
+	return "", nil
+}
+
+// SystemLocale returns the region setting of the system.
+func (LocaleDetector) SystemLocale() (string, error) {
+	output, err := exec.Command("defaults", "read", globalPreferences, "AppleLocale").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the system locale: %w", err)
+	}
+	locale := strings.TrimSpace(string(output))
+	if i := strings.IndexByte(locale, '@'); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.ReplaceAll(locale, "_", "-"), nil
+}
+
+// KeyboardLayouts returns an error, because keyboard layouts are not
+// available on macOS.
+func (LocaleDetector) KeyboardLayouts() ([]lbplatform.KeyboardLayout, error) {
+	return nil, errors.New("keyboard layouts are not available on macOS")
+}
@@ -0,0 +1,94 @@
+package darwinplatform
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// ProcessController provides information about processes on the local
+// macOS system.
+type ProcessController struct{}
+
+// NumberOfRunningProcesses returns the number of processes running on the
+// local system that match the given criteria.
+//
+// Processes are identified by the name of their executable, as reported by
+// ps. Sessions are not available on macOS.
+func (ProcessController) NumberOfRunningProcesses(match lbdeploy.ProcessMatch) (n int, err error) {
+	filter, err := match.Compile(false)
+	if err != nil {
+		return 0, err
+	}
+
+	output, err := exec.Command("ps", "-axww", "-o", "pid=,user=,comm=").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list running processes: %w", err)
+	}
+
+	for line := range strings.Lines(string(output)) {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		pid := fields[0]
+		fields = strings.SplitN(strings.TrimSpace(fields[1]), " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		proc := &processInfo{
+			pid:  pid,
+			user: fields[0],
+			path: strings.TrimSpace(fields[1]),
+		}
+		matched, err := filter(proc)
+		if err != nil {
+			return 0, err
+		}
+		if matched {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// MutexExists returns an error, because named mutexes are not available on
+// macOS.
+func (ProcessController) MutexExists(name string) (bool, error) {
+	return false, errors.New("mutexes are not available on macOS")
+}
+
+// processInfo provides the attributes of a process reported by ps.
+type processInfo struct {
+	pid  string
+	user string
+	path string
+}
+
+// ProcessAttribute returns the value of the given process attribute.
+func (p *processInfo) ProcessAttribute(attr lbdeploy.ProcessAttributeID) (string, error) {
+	switch attr {
+	case lbdeploy.ProcessName:
+		return filepath.Base(p.path), nil
+	case lbdeploy.ProcessPath:
+		return p.path, nil
+	case lbdeploy.ProcessUser:
+		return p.user, nil
+	case lbdeploy.ProcessCommandLine:
+		output, err := exec.Command("ps", "-ww", "-o", "args=", "-p", p.pid).Output()
+		if err != nil {
+			// The process exited while it was being inspected.
+			return "", nil
+		}
+		return strings.TrimSpace(string(output)), nil
+	case lbdeploy.ProcessSession:
+		return "", errors.New("process sessions are not available on macOS")
+	default:
+		return "", fmt.Errorf("the process attribute \"%s\" is not recognized", attr)
+	}
+}
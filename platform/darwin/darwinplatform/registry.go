@@ -0,0 +1,31 @@
+package darwinplatform
+
+import (
+	"errors"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+)
+
+// ErrNoRegistry is returned when a deployment refers to registry resources
+// on macOS.
+var ErrNoRegistry = errors.New("the registry is not available on macOS")
+
+// Registry is a registry that is not available. All of its methods return
+// ErrNoRegistry.
+type Registry struct{}
+
+// KeyExists returns ErrNoRegistry.
+func (Registry) KeyExists(lbdeploy.RegistryKeyResourceID) (bool, error) {
+	return false, ErrNoRegistry
+}
+
+// ValueExists returns ErrNoRegistry.
+func (Registry) ValueExists(lbdeploy.RegistryValueResourceID) (bool, error) {
+	return false, ErrNoRegistry
+}
+
+// GetValue returns ErrNoRegistry.
+func (Registry) GetValue(lbdeploy.RegistryValueResourceID) (lbvalue.Value, error) {
+	return lbvalue.Value{}, ErrNoRegistry
+}
@@ -0,0 +1,20 @@
+package darwinplatform
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TimeZone returns the IANA name of the system time zone, such as
+// "Europe/Berlin". It is determined from the target of /etc/localtime.
+func (LocaleDetector) TimeZone() (string, error) {
+	target, err := os.Readlink("/etc/localtime")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the system time zone: %w", err)
+	}
+	if _, name, found := strings.Cut(target, "zoneinfo/"); found {
+		return name, nil
+	}
+	return "", fmt.Errorf("the system time zone could not be determined from %s", target)
+}
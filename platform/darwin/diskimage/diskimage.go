@@ -0,0 +1,52 @@
+// Package diskimage mounts and unmounts macOS disk images with hdiutil.
+package diskimage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Image is a disk image that has been attached to the local system.
+type Image struct {
+	mountPoint string
+}
+
+// Attach mounts the disk image at path read-only, at a temporary mount
+// point that is hidden from the Finder. Any license agreement that is
+// embedded in the image is accepted.
+//
+// The caller must call Detach when finished with the image.
+func Attach(ctx context.Context, path string) (Image, error) {
+	mountPoint, err := os.MkdirTemp("", "leafbridge-image-")
+	if err != nil {
+		return Image{}, fmt.Errorf("a mount point could not be created for the disk image: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "hdiutil", "attach", "-nobrowse", "-readonly", "-noautoopen", "-mountpoint", mountPoint, path)
+	cmd.Stdin = strings.NewReader("Y\n")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(mountPoint)
+		return Image{}, fmt.Errorf("the disk image could not be attached: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return Image{mountPoint: mountPoint}, nil
+}
+
+// Path returns the path at which the image is mounted.
+func (img Image) Path() string {
+	return img.mountPoint
+}
+
+// Detach unmounts the image and removes its mount point. If the image is
+// busy, it is forcibly detached.
+func (img Image) Detach() error {
+	if err := exec.Command("hdiutil", "detach", img.mountPoint, "-quiet").Run(); err != nil {
+		if output, err := exec.Command("hdiutil", "detach", img.mountPoint, "-force").CombinedOutput(); err != nil {
+			return fmt.Errorf("the disk image could not be detached: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+	return os.Remove(img.mountPoint)
+}
@@ -0,0 +1,126 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/darwin/appbundle"
+	"github.com/leafbridge/leafbridge/platform/darwin/diskimage"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+// applicationsDir is the directory that application bundles are installed
+// into.
+const applicationsDir = "/Applications"
+
+// installerCommand prepares a pkg-install or bundle-install command.
+//
+// If the command has a disk image, the image is mounted while the command
+// runs and the executable is a path within it. Otherwise the executable is
+// a file resource that identifies an installer package.
+func installerCommand(ctx context.Context, req unixengine.CommandRequest) (cmd *unixengine.Command, err error) {
+	definition := req.Definition()
+
+	// Release anything that has been acquired if the command cannot be
+	// prepared.
+	var cleanup cleanupList
+	defer func() {
+		if err != nil {
+			cleanup.Run()
+		}
+	}()
+
+	// Locate the installer package or application bundle.
+	var source string
+	if definition.DiskImage != "" {
+		imagePath, err := req.LocalFilePath(definition.DiskImage)
+		if err != nil {
+			return nil, fmt.Errorf("%s refers to a disk image \"%s\" that could not be resolved: %w", req.Desc(), definition.DiskImage, err)
+		}
+		if err := req.VerifyIntegrity(definition.DiskImage, imagePath); err != nil {
+			return nil, err
+		}
+
+		relative := filepath.FromSlash(string(definition.Executable))
+		if !filepath.IsLocal(relative) {
+			return nil, fmt.Errorf("%s refers to \"%s\", which is not a local path within its disk image", req.Desc(), definition.Executable)
+		}
+
+		image, err := diskimage.Attach(ctx, imagePath)
+		if err != nil {
+			return nil, fmt.Errorf("%s could not mount its disk image: %w", req.Desc(), err)
+		}
+		cleanup.Add(func() { image.Detach() })
+
+		source = filepath.Join(image.Path(), relative)
+		if _, err := os.Stat(source); err != nil {
+			return nil, fmt.Errorf("%s refers to \"%s\", which could not be found within its disk image: %w", req.Desc(), definition.Executable, err)
+		}
+	} else {
+		if definition.Type == lbdeploy.CommandTypeBundleInstall {
+			return nil, fmt.Errorf("%s installs an application bundle, which requires a disk image", req.Desc())
+		}
+		fileID := lbdeploy.FileResourceID(definition.Executable)
+		path, err := req.LocalFilePath(fileID)
+		if err != nil {
+			return nil, fmt.Errorf("%s refers to an installer package \"%s\" that could not be resolved: %w", req.Desc(), fileID, err)
+		}
+		if err := req.VerifyIntegrity(fileID, path); err != nil {
+			return nil, err
+		}
+		source = path
+	}
+
+	// Prepare the command.
+	switch definition.Type {
+	case lbdeploy.CommandTypePkgInstall:
+		args, err := req.ExpandArgs(definition.Args)
+		if err != nil {
+			return nil, fmt.Errorf("the arguments for %s could not be prepared: %w", req.Desc(), err)
+		}
+		cmd = &unixengine.Command{
+			Cmd: exec.CommandContext(ctx, "/usr/sbin/installer", append([]string{"-pkg", source, "-target", "/"}, args...)...),
+		}
+	case lbdeploy.CommandTypeBundleInstall:
+		// The bundle is copied to a hidden staging directory beside its
+		// destination, then moved into place once the copy has succeeded.
+		// Any existing copy of the bundle is kept until then, so that a
+		// failed copy does not leave the application missing or incomplete,
+		// and files from a previous version are not left behind.
+		destination := filepath.Join(applicationsDir, filepath.Base(source))
+		staging, err := os.MkdirTemp(applicationsDir, "."+filepath.Base(source)+"-")
+		if err != nil {
+			return nil, fmt.Errorf("%s could not prepare a staging directory for the \"%s\" bundle: %w", req.Desc(), destination, err)
+		}
+		cleanup.Add(func() { os.RemoveAll(staging) })
+		cmd = &unixengine.Command{
+			Cmd: exec.CommandContext(ctx, "/usr/bin/ditto", source, staging),
+			Complete: func() error {
+				return appbundle.Replace(source, staging, destination)
+			},
+		}
+	}
+
+	cmd.Cleanup = cleanup.Run
+	return cmd, nil
+}
+
+// cleanupList is a list of cleanup functions that are run in reverse order.
+type cleanupList []func()
+
+// Add adds fn to the list.
+func (list *cleanupList) Add(fn func()) {
+	*list = append(*list, fn)
+}
+
+// Run runs each function in the list, in the reverse order that they were
+// added.
+func (list cleanupList) Run() {
+	for i := len(list) - 1; i >= 0; i-- {
+		list[i]()
+	}
+}
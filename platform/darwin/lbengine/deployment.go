@@ -0,0 +1,64 @@
+// Package lbengine invokes LeafBridge deployments on macOS.
+//
+// Installer packages (.pkg) are installed with the installer utility, and
+// application bundles are copied from disk images (.dmg) that are mounted
+// for the duration of a command. Applications are detected by their
+// bundles or by the receipts of their installer packages. Commands are
+// executed directly and scripts are run with sh.
+//
+// The deployment engine is shared with other Unix-like operating systems,
+// and is provided by the unixengine package. This package supplies the
+// parts that are specific to macOS.
+package lbengine
+
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbeval"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+// ErrDeploymentTimeout is returned when a deployment is stopped because it
+// exceeded its timeout.
+var ErrDeploymentTimeout = unixengine.ErrDeploymentTimeout
+
+// ErrDeploymentCancelled is returned when a deployment is stopped because
+// its context was cancelled.
+var ErrDeploymentCancelled = unixengine.ErrDeploymentCancelled
+
+// DeploymentEngine is a LeafBridge engine that is responsible for invocation
+// of deployments on macOS.
+type DeploymentEngine = unixengine.DeploymentEngine
+
+// Options hold configuration options for a LeafBridge deployment engine.
+type Options = unixengine.Options
+
+// NewDeploymentEngine returns a new LeafBridge deployment engine for the
+// given deployment and options.
+func NewDeploymentEngine(deployment lbdeploy.Deployment, opts Options) DeploymentEngine {
+	return unixengine.NewDeploymentEngine(system{}, deployment, opts)
+}
+
+// NewConditionEngine prepares a condition engine for the given deployment.
+func NewConditionEngine(dep lbdeploy.Deployment) lbeval.ConditionEngine {
+	return unixengine.NewConditionEngine(system{}, dep)
+}
+
+// NewAppEngine prepares an app engine for the given deployment.
+func NewAppEngine(dep lbdeploy.Deployment) lbeval.AppEngine {
+	return unixengine.NewAppEngine(system{}, dep)
+}
+
+// NewComplianceEngine prepares a compliance engine for the given
+// deployment.
+func NewComplianceEngine(dep lbdeploy.Deployment) lbeval.ComplianceEngine {
+	return unixengine.NewComplianceEngine(system{}, dep)
+}
+
+// ActionDurations returns the duration of each action in the deployment's
+// flows when the flow last completed, as recorded in the state stores of
+// the local system. Flows without recorded durations are omitted.
+func ActionDurations(dep lbdeploy.Deployment) map[lbdeploy.FlowID][]time.Duration {
+	return unixengine.ActionDurations(system{}, dep)
+}
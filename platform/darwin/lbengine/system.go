@@ -0,0 +1,66 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"github.com/leafbridge/leafbridge/platform/darwin/darwinfs"
+	"github.com/leafbridge/leafbridge/platform/darwin/darwinplatform"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+// system provides the parts of the deployment engine that are specific to
+// macOS.
+type system struct{}
+
+// Name returns the name of the operating system.
+func (system) Name() string {
+	return "macOS"
+}
+
+// Platform returns the macOS platform.
+func (system) Platform() lbplatform.Platform {
+	return darwinplatform.New()
+}
+
+// Resolver returns a resolver for the given file system resources.
+func (system) Resolver(resources lbdeploy.FileSystemResources) unixengine.Resolver {
+	resolver := darwinfs.NewResolver(resources)
+	return &resolver
+}
+
+// StateDir returns the directory that holds the persistent state of
+// deployments in the given frequency scope.
+//
+// Machine state is kept in /Library/Application Support/LeafBridge/State.
+// User state is kept in the same location within the home directory of the
+// user that is running the deployment.
+func (system) StateDir(scope lbdeploy.FrequencyScope) (string, error) {
+	base := "/Library/Application Support"
+	if scope == lbdeploy.FrequencyPerUser {
+		var err error
+		if base, err = os.UserConfigDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(base, "LeafBridge", "State"), nil
+}
+
+// ConfigDir returns the directory that holds the machine's configuration,
+// which is /Library/Application Support/LeafBridge.
+func (system) ConfigDir() (string, error) {
+	return "/Library/Application Support/LeafBridge", nil
+}
+
+// PrepareCommand prepares a pkg-install or bundle-install command. Other
+// command types are not supported.
+func (system) PrepareCommand(ctx context.Context, req unixengine.CommandRequest) (*unixengine.Command, error) {
+	if !req.Definition().Type.IsMacInstaller() {
+		return nil, errors.ErrUnsupported
+	}
+	return installerCommand(ctx, req)
+}
@@ -0,0 +1,77 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/linux/pkgmgr"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+// packageCommand prepares a package manager command for the applications
+// that the command installs or uninstalls. Applications that are already in
+// the desired state are left alone unless the command is forced.
+//
+// The command includes the environment variables that keep the package
+// manager from prompting. If there is nothing for the package manager to
+// do, it returns a nil command.
+func packageCommand(ctx context.Context, req unixengine.CommandRequest) (*unixengine.Command, error) {
+	definition := req.Definition()
+	dep := req.Deployment()
+
+	// Select the applications that need to change.
+	forced := req.Forced()
+	var apps lbdeploy.AppList
+	switch definition.Type {
+	case lbdeploy.CommandTypePackageInstall:
+		if len(definition.Uninstalls) > 0 {
+			return nil, fmt.Errorf("%s installs packages, but it lists applications to uninstall", req.Desc())
+		}
+		apps = req.Apps().ToInstall
+		if forced {
+			apps = definition.Installs
+		}
+	case lbdeploy.CommandTypePackageRemove:
+		if len(definition.Installs) > 0 {
+			return nil, fmt.Errorf("%s removes packages, but it lists applications to install", req.Desc())
+		}
+		apps = req.Apps().ToUninstall
+		if forced {
+			apps = definition.Uninstalls
+		}
+	}
+	if len(apps) == 0 {
+		return nil, nil
+	}
+
+	// Map each application to the name of its package.
+	manager, err := pkgmgr.Detect()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(apps))
+	for _, appID := range apps {
+		app, found := dep.Apps[appID]
+		if !found {
+			return nil, fmt.Errorf("the \"%s\" app does not exist within the \"%s\" deployment", appID, dep.ID)
+		}
+		name := app.Package.NameFor(manager)
+		if name == "" {
+			return nil, fmt.Errorf("the \"%s\" app does not identify a package for %s", appID, manager)
+		}
+		names = append(names, name)
+	}
+
+	var cmd *exec.Cmd
+	if definition.Type == lbdeploy.CommandTypePackageRemove {
+		cmd, err = pkgmgr.RemoveCommand(ctx, manager, names...)
+	} else {
+		cmd, err = pkgmgr.InstallCommand(ctx, manager, names...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &unixengine.Command{Cmd: cmd, Env: pkgmgr.Variables(manager)}, nil
+}
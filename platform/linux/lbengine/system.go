@@ -0,0 +1,69 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"github.com/leafbridge/leafbridge/platform/linux/linuxfs"
+	"github.com/leafbridge/leafbridge/platform/linux/linuxplatform"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+// system provides the parts of the deployment engine that are specific to
+// Linux.
+type system struct{}
+
+// Name returns the name of the operating system.
+func (system) Name() string {
+	return "Linux"
+}
+
+// Platform returns the Linux platform.
+func (system) Platform() lbplatform.Platform {
+	return linuxplatform.New()
+}
+
+// Resolver returns a resolver for the given file system resources.
+func (system) Resolver(resources lbdeploy.FileSystemResources) unixengine.Resolver {
+	resolver := linuxfs.NewResolver(resources)
+	return &resolver
+}
+
+// StateDir returns the directory that holds the persistent state of
+// deployments in the given frequency scope.
+//
+// Machine state is kept in /var/lib/leafbridge/state. User state is kept in
+// $XDG_STATE_HOME/leafbridge, which defaults to ~/.local/state/leafbridge.
+func (system) StateDir(scope lbdeploy.FrequencyScope) (string, error) {
+	if scope != lbdeploy.FrequencyPerUser {
+		return "/var/lib/leafbridge/state", nil
+	}
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "leafbridge"), nil
+}
+
+// ConfigDir returns the directory that holds the machine's configuration,
+// which is /etc/leafbridge.
+func (system) ConfigDir() (string, error) {
+	return "/etc/leafbridge", nil
+}
+
+// PrepareCommand prepares a package manager command. Other command types
+// are not supported.
+func (system) PrepareCommand(ctx context.Context, req unixengine.CommandRequest) (*unixengine.Command, error) {
+	if !req.Definition().Type.IsPackageManager() {
+		return nil, errors.ErrUnsupported
+	}
+	return packageCommand(ctx, req)
+}
@@ -0,0 +1,68 @@
+package linuxfs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// knownFolderMap is a map of predefined directory resource IDs to known
+// folder locations.
+type knownFolderMap map[lbdeploy.DirectoryResourceID]knownFolder
+
+// knownFolder describes how to locate a known folder on Linux.
+//
+// Folders defined by the XDG Base Directory Specification are taken from
+// their environment variable when it holds an absolute path. Otherwise
+// their path is relative to the user's home directory.
+type knownFolder struct {
+	path       string
+	env        string // XDG environment variable, if any
+	home       bool   // Path is relative to the user's home directory
+	protected  bool
+	privileged bool // Only root can modify the folder
+}
+
+// Known folders that are recognized by their resource IDs.
+//
+// The folders used by Windows deployments are mapped to their closest
+// equivalents in the Filesystem Hierarchy Standard, so that one deployment
+// document can serve both platforms.
+var knownFolders = knownFolderMap{
+	"common-start-menu": knownFolder{path: "/usr/share/applications", privileged: true},
+	"program-data":      knownFolder{path: "/var/lib", privileged: true},
+	"program-files":     knownFolder{path: "/opt", privileged: true},
+	"program-files-x64": knownFolder{path: "/opt", privileged: true},
+	"system":            knownFolder{path: "/usr/bin", protected: true, privileged: true},
+	"etc":               knownFolder{path: "/etc", protected: true, privileged: true},
+	"opt":               knownFolder{path: "/opt", privileged: true},
+	"usr-local":         knownFolder{path: "/usr/local", privileged: true},
+	"var-lib":           knownFolder{path: "/var/lib", privileged: true},
+	"var-log":           knownFolder{path: "/var/log", privileged: true},
+	"xdg-config-home":   knownFolder{path: ".config", env: "XDG_CONFIG_HOME", home: true},
+	"xdg-data-home":     knownFolder{path: ".local/share", env: "XDG_DATA_HOME", home: true},
+	"xdg-state-home":    knownFolder{path: ".local/state", env: "XDG_STATE_HOME", home: true},
+	"xdg-cache-home":    knownFolder{path: ".cache", env: "XDG_CACHE_HOME", home: true},
+}
+
+// Path returns the absolute path of the folder.
+func (folder knownFolder) Path() (string, error) {
+	if folder.env != "" {
+		if path := os.Getenv(folder.env); filepath.IsAbs(path) {
+			return path, nil
+		}
+	}
+	if !folder.home {
+		return folder.path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if home == "" {
+		return "", errors.New("the home directory of the current user is not known")
+	}
+	return filepath.Join(home, filepath.FromSlash(folder.path)), nil
+}
@@ -0,0 +1,51 @@
+package linuxfs_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/linux/linuxfs"
+)
+
+var testResources = lbdeploy.FileSystemResources{
+	Directories: lbdeploy.DirectoryResourceMap{
+		"app":    {Location: "program-files", Path: "example"},
+		"config": {Location: "xdg-config-home", Path: "example"},
+	},
+	Files: lbdeploy.FileResourceMap{
+		"app-binary":  {Location: "app", Path: "bin/example"},
+		"config-file": {Location: "config", Path: "settings.json"},
+	},
+}
+
+type resolveFixture struct {
+	File lbdeploy.FileResourceID
+	Path string
+}
+
+var resolveFixtures = []resolveFixture{
+	{File: "app-binary", Path: "/opt/example/bin/example"},
+	{File: "config-file", Path: "/xdg/config/example/settings.json"},
+}
+
+func TestResolveFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+
+	resolver := linuxfs.NewResolver(testResources)
+	for _, fixture := range resolveFixtures {
+		t.Run(string(fixture.File), func(t *testing.T) {
+			ref, err := resolver.ResolveFile(fixture.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path, err := ref.Path()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.FromSlash(fixture.Path); path != want {
+				t.Errorf("got \"%s\", want \"%s\"", path, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package linuxplatform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/linux/pkgmgr"
+)
+
+// AppDetector looks up applications in the package database of the local
+// system's package manager.
+type AppDetector struct{}
+
+// IsInstalled returns true if the application's distribution package is
+// installed.
+func (detector AppDetector) IsInstalled(app lbdeploy.Application) (bool, error) {
+	_, installed, err := detector.query(app)
+	return installed, err
+}
+
+// Version returns the installed version of the application's distribution
+// package. If the package is not installed, it returns an empty string.
+func (detector AppDetector) Version(app lbdeploy.Application) (datatype.Version, error) {
+	version, _, err := detector.query(app)
+	return version, err
+}
+
+func (AppDetector) query(app lbdeploy.Application) (datatype.Version, bool, error) {
+	manager, err := pkgmgr.Detect()
+	if err != nil {
+		return "", false, err
+	}
+	name := app.Package.NameFor(manager)
+	if name == "" {
+		return "", false, fmt.Errorf("the \"%s\" application does not identify a package for %s", app.Name, manager)
+	}
+	return pkgmgr.Query(context.Background(), manager, name)
+}
@@ -0,0 +1,17 @@
+package linuxplatform
+
+import (
+	"errors"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// CertificateDetector reports that certificate stores are not available
+// on Linux.
+type CertificateDetector struct{}
+
+// CertificateExists returns an error, because certificate stores are not
+// available on Linux.
+func (CertificateDetector) CertificateExists(cert lbdeploy.CertificateResource) (bool, error) {
+	return false, errors.New("certificate stores are not available on Linux")
+}
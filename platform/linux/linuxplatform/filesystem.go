@@ -0,0 +1,80 @@
+package linuxplatform
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/linux/linuxfs"
+)
+
+// FileSystem provides access to file system resources on the local Linux
+// system.
+type FileSystem struct {
+	resources lbdeploy.FileSystemResources
+}
+
+// DirectoryExists returns true if the directory exists.
+func (fs FileSystem) DirectoryExists(id lbdeploy.DirectoryResourceID) (bool, error) {
+	resolver := linuxfs.NewResolver(fs.resources)
+	ref, err := resolver.ResolveDirectory(id)
+	if err != nil {
+		return false, err
+	}
+	path, err := ref.Path()
+	if err != nil {
+		return false, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !fi.IsDir() {
+		return false, fmt.Errorf("the \"%s\" path exists but it is not a directory", path)
+	}
+	return true, nil
+}
+
+// FileExists returns true if the file exists. It returns an error if the
+// file's path exists but is not a regular file.
+func (fs FileSystem) FileExists(id lbdeploy.FileResourceID) (bool, error) {
+	resolver := linuxfs.NewResolver(fs.resources)
+	ref, err := resolver.ResolveFile(id)
+	if err != nil {
+		return false, err
+	}
+	path, err := ref.Path()
+	if err != nil {
+		return false, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !fi.Mode().IsRegular() {
+		return false, fmt.Errorf("the \"%s\" path exists but it is not a regular file", path)
+	}
+	return true, nil
+}
+
+// OpenFile opens the file for reading. If the file does not exist it
+// returns an error that satisfies os.IsNotExist.
+func (fs FileSystem) OpenFile(id lbdeploy.FileResourceID) (io.ReadCloser, error) {
+	resolver := linuxfs.NewResolver(fs.resources)
+	ref, err := resolver.ResolveFile(id)
+	if err != nil {
+		return nil, err
+	}
+	path, err := ref.Path()
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
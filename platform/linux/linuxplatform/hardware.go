@@ -0,0 +1,131 @@
+package linuxplatform
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// secureBootVariable is the path of the EFI variable that records the
+// Secure Boot state.
+const secureBootVariable = "/sys/firmware/efi/efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+// HardwareDetector reports on the hardware and firmware of the local
+// system.
+type HardwareDetector struct{}
+
+// InstalledMemory returns the total amount of memory available to the
+// kernel in bytes. This is slightly less than the physically installed
+// memory.
+func (HardwareDetector) InstalledMemory() (int64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, found := strings.CutPrefix(scanner.Text(), "MemTotal:")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "kB"))
+		kilobytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse the total memory reported by /proc/meminfo: %w", err)
+		}
+		return kilobytes << 10, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("the total memory is not reported by /proc/meminfo")
+}
+
+// LogicalProcessors returns the number of logical processors that are
+// usable by the current process.
+func (HardwareDetector) LogicalProcessors() (int, error) {
+	return runtime.NumCPU(), nil
+}
+
+// TPMVersion returns the major version of the first trusted platform
+// module known to the kernel. It returns zero if a TPM is not present.
+func (HardwareDetector) TPMVersion() (int, error) {
+	data, err := os.ReadFile("/sys/class/tpm/tpm0/tpm_version_major")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the TPM version: %w", err)
+	}
+	return version, nil
+}
+
+// SecureBootEnabled returns true if UEFI Secure Boot is enabled. Systems
+// that boot through legacy BIOS report that it is disabled.
+func (HardwareDetector) SecureBootEnabled() (bool, error) {
+	data, err := os.ReadFile(secureBootVariable)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// The variable data is preceded by four bytes of attributes.
+	if len(data) < 5 {
+		return false, errors.New("the SecureBoot EFI variable is malformed")
+	}
+	return data[4] == 1, nil
+}
+
+// Hypervisor returns the hypervisor that the local system is running on,
+// based on the system vendor and product names reported by the firmware.
+// It returns an empty string if the system is a physical machine.
+func (HardwareDetector) Hypervisor() (lbdeploy.Hypervisor, error) {
+	vendor, err := readDMI("sys_vendor")
+	if err != nil {
+		return "", err
+	}
+	product, err := readDMI("product_name")
+	if err != nil {
+		return "", err
+	}
+	return lbdeploy.IdentifyHypervisor(vendor, product), nil
+}
+
+// readDMI returns the value of a DMI attribute exposed by the kernel. It
+// returns an empty string if the attribute is not present.
+func readDMI(name string) (string, error) {
+	data, err := os.ReadFile("/sys/class/dmi/id/" + name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// BitLockerProtected returns an error, because BitLocker is not available
+// on Linux.
+func (HardwareDetector) BitLockerProtected() (bool, error) {
+	return false, errors.New("BitLocker is not available on Linux")
+}
+
+// ActivePowerPlan returns an error, because Windows power plans are not
+// available on Linux.
+func (HardwareDetector) ActivePowerPlan() (string, error) {
+	return "", errors.New("power plans are not available on Linux")
+}
@@ -0,0 +1,106 @@
+package linuxplatform
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+)
+
+// LocaleDetector reports on the language and regional settings of the
+// local system.
+type LocaleDetector struct{}
+
+// UILanguage returns the language of the system locale, because Linux
+// does not distinguish between the two.
+func (d LocaleDetector) UILanguage() (string, error) {
+	return d.SystemLocale()
+}
+
+// SystemLocale returns the system locale configured in /etc/locale.conf or
+// /etc/default/locale. It returns an empty string for the C and POSIX
+// locales.
+func (LocaleDetector) SystemLocale() (string, error) {
+	for _, path := range []string{"/etc/locale.conf", "/etc/default/locale"} {
+		values, err := readShellVariables(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", err
+		}
+		if lang := values["LANG"]; lang != "" {
+			return languageTag(lang), nil
+		}
+	}
+	return "", nil
+}
+
+// KeyboardLayouts returns the XKB layouts configured in
+// /etc/default/keyboard, or the console keymap configured in
+// /etc/vconsole.conf. Their languages are not known.
+func (LocaleDetector) KeyboardLayouts() ([]lbplatform.KeyboardLayout, error) {
+	var layouts []lbplatform.KeyboardLayout
+
+	values, err := readShellVariables("/etc/default/keyboard")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for layout := range strings.SplitSeq(values["XKBLAYOUT"], ",") {
+		if layout = strings.TrimSpace(layout); layout != "" {
+			layouts = append(layouts, lbplatform.KeyboardLayout{ID: layout})
+		}
+	}
+	if len(layouts) > 0 {
+		return layouts, nil
+	}
+
+	values, err = readShellVariables("/etc/vconsole.conf")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if keymap := values["KEYMAP"]; keymap != "" {
+		layouts = append(layouts, lbplatform.KeyboardLayout{ID: keymap})
+	}
+
+	return layouts, nil
+}
+
+// languageTag converts a POSIX locale name, such as "de_DE.UTF-8", to a
+// language tag, such as "de-DE".
+func languageTag(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(locale, "_", "-")
+}
+
+// readShellVariables reads the variable assignments in a configuration
+// file that uses shell syntax.
+func readShellVariables(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !found {
+			continue
+		}
+		values[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return values, scanner.Err()
+}
@@ -0,0 +1,62 @@
+// Package linuxplatform provides the Linux implementation of the LeafBridge
+// platform interfaces.
+package linuxplatform
+
+import (
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+)
+
+// Platform provides access to the local Linux system.
+type Platform struct{}
+
+// Verify that Platform satisfies the lbplatform.Platform interface.
+var _ lbplatform.Platform = Platform{}
+
+// New returns a platform for the local Linux system.
+func New() Platform {
+	return Platform{}
+}
+
+// FileSystem returns a file system that resolves resources from the given
+// set of file system resources.
+func (Platform) FileSystem(resources lbdeploy.FileSystemResources) lbplatform.FileSystem {
+	return FileSystem{resources: resources}
+}
+
+// Registry returns a registry that reports that it is not available on
+// Linux.
+func (Platform) Registry(resources lbdeploy.RegistryResources) lbplatform.Registry {
+	return Registry{}
+}
+
+// Apps returns an application detector for the local system.
+func (Platform) Apps() lbplatform.AppDetector {
+	return AppDetector{}
+}
+
+// Processes returns a process controller for the local system.
+func (Platform) Processes() lbplatform.ProcessController {
+	return ProcessController{}
+}
+
+// Certificates returns a certificate detector that reports that it is not
+// available on Linux.
+func (Platform) Certificates() lbplatform.CertificateDetector {
+	return CertificateDetector{}
+}
+
+// Hardware returns a hardware detector for the local system.
+func (Platform) Hardware() lbplatform.HardwareDetector {
+	return HardwareDetector{}
+}
+
+// Locale returns a locale detector for the local system.
+func (Platform) Locale() lbplatform.LocaleDetector {
+	return LocaleDetector{}
+}
+
+// Accounts returns an account detector for the local system.
+func (Platform) Accounts() lbplatform.AccountDetector {
+	return AccountDetector{}
+}
@@ -0,0 +1,152 @@
+package linuxplatform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// ProcessController provides information about processes on the local
+// Linux system.
+type ProcessController struct{}
+
+// NumberOfRunningProcesses returns the number of processes running on the
+// local system that match the given criteria.
+//
+// Processes are identified by the base name of their executable. Processes
+// that cannot be inspected are identified by their command name instead.
+//
+// Sessions are identified by the audit session ID of each process.
+func (ProcessController) NumberOfRunningProcesses(match lbdeploy.ProcessMatch) (n int, err error) {
+	filter, err := match.Compile(false)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		name, ok := processName(entry.Name())
+		if !ok {
+			continue
+		}
+		matched, err := filter(&processInfo{pid: entry.Name(), name: name})
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// The process exited while it was being inspected.
+				continue
+			}
+			return 0, err
+		}
+		if matched {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// MutexExists returns an error, because named mutexes are not available on
+// Linux.
+func (ProcessController) MutexExists(name string) (bool, error) {
+	return false, errors.New("mutexes are not available on Linux")
+}
+
+// processName returns the name of the process with the given ID. It returns
+// false if the process has exited.
+func processName(pid string) (string, bool) {
+	if target, err := os.Readlink(filepath.Join("/proc", pid, "exe")); err == nil {
+		return filepath.Base(strings.TrimSuffix(target, " (deleted)")), true
+	}
+	comm, err := os.ReadFile(filepath.Join("/proc", pid, "comm"))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(comm)), true
+}
+
+// processInfo provides the attributes of a process from the proc file
+// system.
+type processInfo struct {
+	pid  string
+	name string
+}
+
+// ProcessAttribute returns the value of the given process attribute.
+//
+// Attributes that cannot be read because of insufficient permissions
+// are returned as empty strings, so that they never match.
+func (p *processInfo) ProcessAttribute(attr lbdeploy.ProcessAttributeID) (string, error) {
+	value, err := p.readAttribute(attr)
+	if errors.Is(err, os.ErrPermission) {
+		return "", nil
+	}
+	return value, err
+}
+
+func (p *processInfo) readAttribute(attr lbdeploy.ProcessAttributeID) (string, error) {
+	dir := filepath.Join("/proc", p.pid)
+	switch attr {
+	case lbdeploy.ProcessName:
+		return p.name, nil
+	case lbdeploy.ProcessPath:
+		target, err := os.Readlink(filepath.Join(dir, "exe"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(target, " (deleted)"), nil
+	case lbdeploy.ProcessCommandLine:
+		data, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+		if err != nil {
+			return "", err
+		}
+		args := strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+		return strings.Join(args, " "), nil
+	case lbdeploy.ProcessUser:
+		uid, err := processUID(dir)
+		if err != nil {
+			return "", err
+		}
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username, nil
+		}
+		return uid, nil
+	case lbdeploy.ProcessSession:
+		data, err := os.ReadFile(filepath.Join(dir, "sessionid"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("the process attribute \"%s\" is not recognized", attr)
+	}
+}
+
+// processUID returns the real user ID of the process, as recorded in its
+// status file.
+func processUID(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return "", err
+	}
+	for line := range strings.Lines(string(data)) {
+		if value, found := strings.CutPrefix(line, "Uid:"); found {
+			if fields := strings.Fields(value); len(fields) > 0 {
+				return fields[0], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("the owner of the process could not be determined")
+}
@@ -0,0 +1,28 @@
+package linuxplatform
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// TimeZone returns the IANA name of the system time zone, such as
+// "Europe/Berlin". It is determined from the target of /etc/localtime,
+// falling back to /etc/timezone. It returns an empty string if the time
+// zone is not configured.
+func (LocaleDetector) TimeZone() (string, error) {
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, found := strings.Cut(target, "zoneinfo/"); found {
+			return name, nil
+		}
+	}
+
+	data, err := os.ReadFile("/etc/timezone")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,145 @@
+// Package pkgmgr queries and drives the apt, dnf and zypper package
+// managers on Linux.
+package pkgmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// ErrNotFound is returned when a supported package manager could not be
+// found on the local system.
+var ErrNotFound = errors.New("a supported package manager (apt, dnf or zypper) was not found")
+
+// managerExecutables maps each package manager to the executable that is
+// used to detect it, in order of preference.
+var managerExecutables = []struct {
+	Manager    lbdeploy.PackageManager
+	Executable string
+}{
+	{Manager: lbdeploy.PackageManagerApt, Executable: "apt-get"},
+	{Manager: lbdeploy.PackageManagerDnf, Executable: "dnf"},
+	{Manager: lbdeploy.PackageManagerZypper, Executable: "zypper"},
+}
+
+// Detect returns the package manager that is available on the local system.
+func Detect() (lbdeploy.PackageManager, error) {
+	for _, candidate := range managerExecutables {
+		if _, err := exec.LookPath(candidate.Executable); err == nil {
+			return candidate.Manager, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Query returns the installed version of the named package. If the package
+// is not installed, it returns false.
+func Query(ctx context.Context, manager lbdeploy.PackageManager, name string) (version datatype.Version, installed bool, err error) {
+	if err := checkName(name); err != nil {
+		return "", false, err
+	}
+
+	var cmd *exec.Cmd
+	switch manager {
+	case lbdeploy.PackageManagerApt:
+		cmd = exec.CommandContext(ctx, "dpkg-query", "--show", "--showformat=${db:Status-Status} ${Version}", name)
+	case lbdeploy.PackageManagerDnf, lbdeploy.PackageManagerZypper:
+		cmd = exec.CommandContext(ctx, "rpm", "--query", "--queryformat=installed %{VERSION}-%{RELEASE}", name)
+	default:
+		return "", false, fmt.Errorf("the \"%s\" package manager is not supported", manager)
+	}
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+
+	output, err := cmd.Output()
+	if err != nil {
+		// Both dpkg-query and rpm exit with a status of 1 when a package
+		// is not installed.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to query the \"%s\" package: %w", name, err)
+	}
+
+	status, value, _ := strings.Cut(strings.TrimSpace(string(output)), " ")
+	if status != "installed" {
+		return "", false, nil
+	}
+
+	return datatype.Version(value), true, nil
+}
+
+// InstallCommand returns a command that installs the named packages
+// without prompting.
+func InstallCommand(ctx context.Context, manager lbdeploy.PackageManager, names ...string) (*exec.Cmd, error) {
+	return command(ctx, manager, "install", names)
+}
+
+// RemoveCommand returns a command that removes the named packages without
+// prompting.
+func RemoveCommand(ctx context.Context, manager lbdeploy.PackageManager, names ...string) (*exec.Cmd, error) {
+	return command(ctx, manager, "remove", names)
+}
+
+// command returns a non-interactive package manager command that applies
+// the operation to the named packages.
+func command(ctx context.Context, manager lbdeploy.PackageManager, operation string, names []string) (*exec.Cmd, error) {
+	if len(names) == 0 {
+		return nil, errors.New("no packages were provided")
+	}
+	for _, name := range names {
+		if err := checkName(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var cmd *exec.Cmd
+	switch manager {
+	case lbdeploy.PackageManagerApt:
+		args := append([]string{operation, "--yes", "--quiet"}, names...)
+		cmd = exec.CommandContext(ctx, "apt-get", args...)
+	case lbdeploy.PackageManagerDnf:
+		args := append([]string{operation, "--assumeyes", "--quiet"}, names...)
+		cmd = exec.CommandContext(ctx, "dnf", args...)
+	case lbdeploy.PackageManagerZypper:
+		args := append([]string{"--non-interactive", "--quiet", operation}, names...)
+		cmd = exec.CommandContext(ctx, "zypper", args...)
+	default:
+		return nil, fmt.Errorf("the \"%s\" package manager is not supported", manager)
+	}
+	if vars := Variables(manager); len(vars) > 0 {
+		cmd.Env = append(os.Environ(), vars...)
+	}
+
+	return cmd, nil
+}
+
+// Variables returns the environment variables that keep the package
+// manager from prompting, in the form "name=value".
+func Variables(manager lbdeploy.PackageManager) []string {
+	switch manager {
+	case lbdeploy.PackageManagerApt:
+		return []string{"DEBIAN_FRONTEND=noninteractive"}
+	default:
+		return nil
+	}
+}
+
+// checkName returns an error if name could be mistaken for an option by a
+// package manager.
+func checkName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("a package name was not provided")
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("the package name \"%s\" is not valid", name)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package pkgmgr_test
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/linux/pkgmgr"
+)
+
+func TestCommand(t *testing.T) {
+	type commandFunc func(context.Context, lbdeploy.PackageManager, ...string) (*exec.Cmd, error)
+
+	fixtures := []struct {
+		Name    string
+		Func    commandFunc
+		Manager lbdeploy.PackageManager
+		Names   []string
+		Args    []string
+		Env     string
+		Err     string
+	}{
+		{Name: "apt-install", Func: pkgmgr.InstallCommand, Manager: lbdeploy.PackageManagerApt, Names: []string{"curl", "jq"}, Args: []string{"apt-get", "install", "--yes", "--quiet", "curl", "jq"}, Env: "DEBIAN_FRONTEND=noninteractive"},
+		{Name: "apt-remove", Func: pkgmgr.RemoveCommand, Manager: lbdeploy.PackageManagerApt, Names: []string{"curl"}, Args: []string{"apt-get", "remove", "--yes", "--quiet", "curl"}, Env: "DEBIAN_FRONTEND=noninteractive"},
+		{Name: "dnf-install", Func: pkgmgr.InstallCommand, Manager: lbdeploy.PackageManagerDnf, Names: []string{"curl", "jq"}, Args: []string{"dnf", "install", "--assumeyes", "--quiet", "curl", "jq"}},
+		{Name: "dnf-remove", Func: pkgmgr.RemoveCommand, Manager: lbdeploy.PackageManagerDnf, Names: []string{"curl"}, Args: []string{"dnf", "remove", "--assumeyes", "--quiet", "curl"}},
+		{Name: "zypper-install", Func: pkgmgr.InstallCommand, Manager: lbdeploy.PackageManagerZypper, Names: []string{"curl", "jq"}, Args: []string{"zypper", "--non-interactive", "--quiet", "install", "curl", "jq"}},
+		{Name: "zypper-remove", Func: pkgmgr.RemoveCommand, Manager: lbdeploy.PackageManagerZypper, Names: []string{"curl"}, Args: []string{"zypper", "--non-interactive", "--quiet", "remove", "curl"}},
+		{Name: "apt-option", Func: pkgmgr.InstallCommand, Manager: lbdeploy.PackageManagerApt, Names: []string{"-oAPT::Get::AllowUnauthenticated=true"}, Err: "is not valid"},
+		{Name: "dnf-option", Func: pkgmgr.RemoveCommand, Manager: lbdeploy.PackageManagerDnf, Names: []string{"curl", "--noautoremove"}, Err: "is not valid"},
+		{Name: "zypper-option", Func: pkgmgr.InstallCommand, Manager: lbdeploy.PackageManagerZypper, Names: []string{"-n"}, Err: "is not valid"},
+		{Name: "empty-name", Func: pkgmgr.InstallCommand, Manager: lbdeploy.PackageManagerApt, Names: []string{""}, Err: "was not provided"},
+		{Name: "no-names", Func: pkgmgr.RemoveCommand, Manager: lbdeploy.PackageManagerDnf, Err: "no packages"},
+		{Name: "unsupported", Func: pkgmgr.InstallCommand, Manager: "pacman", Names: []string{"curl"}, Err: "not supported"},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			cmd, err := fixture.Func(context.Background(), fixture.Manager, fixture.Names...)
+			if fixture.Err != "" {
+				if err == nil || !strings.Contains(err.Error(), fixture.Err) {
+					t.Fatalf("got error %v, want an error containing \"%s\"", err, fixture.Err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(cmd.Args, fixture.Args) {
+				t.Errorf("got arguments %q, want %q", cmd.Args, fixture.Args)
+			}
+			switch {
+			case fixture.Env == "" && cmd.Env != nil:
+				t.Errorf("got environment %q, want the inherited environment", cmd.Env)
+			case fixture.Env != "" && !slices.Contains(cmd.Env, fixture.Env):
+				t.Errorf("the environment does not include %s", fixture.Env)
+			}
+		})
+	}
+}
+
+func TestQueryName(t *testing.T) {
+	for _, name := range []string{"", "-a", "--all"} {
+		if _, _, err := pkgmgr.Query(context.Background(), lbdeploy.PackageManagerApt, name); err == nil {
+			t.Errorf("\"%s\": the package name was accepted", name)
+		}
+	}
+}
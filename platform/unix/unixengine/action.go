@@ -0,0 +1,258 @@
+package unixengine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// actionData holds the index and definition for an action.
+type actionData struct {
+	Index      int
+	Definition lbdeploy.Action
+}
+
+// actionEngine manages execution of an action within a flow.
+type actionEngine struct {
+	deployment lbdeploy.Deployment
+	system     System
+	flow       flowData
+	action     actionData
+	events     lbevent.Recorder
+	force      bool
+	state      *engineState
+}
+
+// EvaluateConditions evaluates the conditions for the action. It returns
+// true if all of the conditions passed.
+//
+// If any of the conditions failed or could not be evaluated, an event is
+// recorded that indicates the action was skipped.
+func (engine *actionEngine) EvaluateConditions() (bool, error) {
+	ce := traceConditions(NewConditionEngine(engine.system, engine.deployment), engine.state, engine.events, lbdeployevent.ConditionObserved{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+	})
+
+	var passed, failed lbdeploy.ConditionList
+	for i, condition := range engine.action.Definition.Conditions {
+		result, err := ce.Evaluate(condition)
+		if err != nil {
+			// Record the evaluation failure.
+			engine.events.Record(lbdeployevent.ActionSkipped{
+				Deployment:  engine.deployment.ID,
+				Flow:        engine.flow.ID,
+				ActionIndex: engine.action.Index,
+				ActionType:  engine.action.Definition.Type,
+				Err:         err,
+			})
+
+			return false, fmt.Errorf("action %d failed to evaluate condition %d: %w", engine.action.Index+1, i+1, err)
+		}
+		if !result {
+			failed = append(failed, condition)
+		} else {
+			passed = append(passed, condition)
+		}
+	}
+
+	if len(failed) > 0 {
+		// Record that the action was skipped.
+		engine.events.Record(lbdeployevent.ActionSkipped{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Passed:      passed,
+			Failed:      failed,
+		})
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (engine *actionEngine) Invoke(ctx context.Context) error {
+	// Record the start of the action.
+	engine.events.Record(lbdeployevent.ActionStarted{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+	})
+
+	// Record the time that the action started.
+	started := time.Now()
+
+	// Execute the action.
+	err := func() error {
+		switch engine.action.Definition.Type {
+		case lbdeploy.ActionStartFlow:
+			if err := engine.startFlow(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionInvokeCommand:
+			if err := engine.invokeCommand(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionShellScript:
+			if err := engine.runShellScript(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionPreparePackage, lbdeploy.ActionCopyFile, lbdeploy.ActionDeleteFile, lbdeploy.ActionBackupFile, lbdeploy.ActionRestoreFile, lbdeploy.ActionPowerShellScript, lbdeploy.ActionCmdScript, lbdeploy.ActionWaitForRegistry,
+			lbdeploy.ActionEditINIFile, lbdeploy.ActionEditXMLFile, lbdeploy.ActionEditJSONFile, lbdeploy.ActionEditHostsFile,
+			lbdeploy.ActionSuspendBitLocker, lbdeploy.ActionResumeBitLocker, lbdeploy.ActionSetTimeZone,
+			lbdeploy.ActionRegisterLogonFlow, lbdeploy.ActionConfigureBrowserExtensions, lbdeploy.ActionSetBrowserPolicies, lbdeploy.ActionSetDefaultAssociations,
+			lbdeploy.ActionSetStartLayout, lbdeploy.ActionClearStartLayout,
+			lbdeploy.ActionConfigureLocalUsers, lbdeploy.ActionConfigureLocalGroups, lbdeploy.ActionConfigureUserRights,
+			lbdeploy.ActionSetPowerPlan, lbdeploy.ActionConfigurePowerSettings, lbdeploy.ActionActivateLicense:
+			return fmt.Errorf("the \"%s\" action is not supported on %s", engine.action.Definition.Type, engine.system.Name())
+		default:
+			return fmt.Errorf("unrecognized deployment action type \"%s\"", engine.action.Definition.Type)
+		}
+		return nil
+	}()
+
+	// Record the time that the action stopped.
+	stopped := time.Now()
+
+	// Record the end of the action.
+	engine.events.Record(lbdeployevent.ActionStopped{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Started:     started,
+		Stopped:     stopped,
+		Err:         err,
+	})
+
+	return err
+}
+
+// startFlow starts another flow within the LeafBridge deployment.
+func (engine *actionEngine) startFlow(ctx context.Context) error {
+	flow := engine.action.Definition.Flow
+
+	// Find the requested flow within the deployment.
+	definition, found := engine.deployment.Flows[flow]
+	if !found {
+		return fmt.Errorf("the \"%s\" flow does not exist within the \"%s\" deployment", flow, engine.deployment.ID)
+	}
+
+	// Prepare the flow engine.
+	fe := flowEngine{
+		deployment: engine.deployment,
+		flow: flowData{
+			ID:         flow,
+			Definition: definition,
+		},
+		system: engine.system,
+		events: engine.events,
+		force:  engine.force,
+		state:  engine.state,
+	}
+
+	// Invoke the requested flow.
+	return fe.Invoke(ctx)
+}
+
+// invokeCommand invokes a command action.
+func (engine *actionEngine) invokeCommand(ctx context.Context) error {
+	// Packages are only supported on Windows.
+	if engine.action.Definition.Package != "" {
+		return fmt.Errorf("the \"%s\" package cannot be used because packages are not supported on %s", engine.action.Definition.Package, engine.system.Name())
+	}
+
+	// Look up the command by its ID.
+	var command commandData
+	{
+		definition, found := engine.deployment.Commands[engine.action.Definition.Command]
+		if !found {
+			return fmt.Errorf("the \"%s\" command does not exist within the \"%s\" deployment", engine.action.Definition.Command, engine.deployment.ID)
+		}
+		command = commandData{ID: engine.action.Definition.Command, Definition: definition}
+	}
+
+	// Determine whether any app changes are anticipated.
+	ae := NewAppEngine(engine.system, engine.deployment)
+	appEvaluation, err := ae.EvaluateAppChanges(command.Definition.Installs, command.Definition.Uninstalls)
+	if err != nil {
+		return fmt.Errorf("the evaluation of potential application changes did not succeed: %w", err)
+	}
+
+	// If the command would modify an app that is held on this machine,
+	// skip it, even if command invocation is forced.
+	if app, hold, held := heldApp(engine.state, engine.deployment, command.Definition); held {
+		engine.events.Record(lbdeployevent.CommandSkipped{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Command:     command.ID,
+			Apps:        appEvaluation,
+			HeldApp:     app,
+			HoldReason:  hold.Reason,
+		})
+		return nil
+	}
+
+	// If the command declares that it installs or uninstalls something,
+	// review the app evaluation to determine whether any application changes
+	// are anticpated.
+	if len(command.Definition.Installs) > 0 || len(command.Definition.Uninstalls) > 0 {
+		if !appEvaluation.ActionsNeeded() {
+			// If all app installs and uninstalls are already in effect,
+			// and command invocation isn't forced, skip this command.
+			if !engine.force && !engine.action.Definition.Force {
+				// Record that this command is being skipped.
+				engine.events.Record(lbdeployevent.CommandSkipped{
+					Deployment:  engine.deployment.ID,
+					Flow:        engine.flow.ID,
+					ActionIndex: engine.action.Index,
+					ActionType:  engine.action.Definition.Type,
+					Command:     command.ID,
+					Apps:        appEvaluation,
+				})
+
+				return nil
+			}
+		}
+	}
+
+	// Prepare a command engine.
+	ce := commandEngine{
+		deployment: engine.deployment,
+		system:     engine.system,
+		flow:       engine.flow,
+		action:     engine.action,
+		command:    command,
+		apps:       appEvaluation,
+		events:     engine.events,
+		force:      engine.force,
+	}
+
+	// Invoke the command.
+	return ce.Invoke(ctx)
+}
+
+// runShellScript runs a script with the POSIX shell.
+func (engine *actionEngine) runShellScript(ctx context.Context) error {
+	// Prepare a script engine.
+	se := scriptEngine{
+		deployment: engine.deployment,
+		system:     engine.system,
+		flow:       engine.flow,
+		action:     engine.action,
+		events:     engine.events,
+	}
+
+	// Execute the shell-script action via the script engine.
+	return se.InvokeShell(ctx)
+}
@@ -0,0 +1,87 @@
+package unixengine
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+)
+
+// invokeWithRetry invokes the action managed by ae. If the action has a
+// retry policy, attempts that fail are retried according to the policy.
+// Each retry is recorded, along with the delay that precedes it. The
+// timeout of the action applies to each attempt separately.
+func (engine flowEngine) invokeWithRetry(ctx context.Context, ae *actionEngine) error {
+	retry := ae.action.Definition.Retry
+	if retry.IsZero() {
+		return invokeAttempt(ctx, ae)
+	}
+	policy := retry.WithDefaults()
+
+	for attempt := 1; ; attempt++ {
+		err := invokeAttempt(ctx, ae)
+		if err == nil || attempt >= policy.Attempts || ctx.Err() != nil {
+			return err
+		}
+
+		// Only retry failures that the policy covers.
+		code, hasCode := exitCodeOf(err)
+		if !retry.Retryable(code, hasCode) {
+			return err
+		}
+
+		// Record the failed attempt and wait before trying again.
+		delay := policy.Delay(attempt)
+		engine.events.Record(lbdeployevent.ActionRetried{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: ae.action.Index,
+			ActionType:  ae.action.Definition.Type,
+			Attempt:     attempt,
+			MaxAttempts: policy.Attempts,
+			Delay:       delay,
+			ExitCode:    code,
+			HasExitCode: hasCode,
+			Err:         err,
+		})
+
+		if err := sleepWithContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// invokeAttempt invokes the action managed by ae once. If the action has a
+// timeout, the attempt is stopped when it is exceeded.
+func invokeAttempt(ctx context.Context, ae *actionEngine) error {
+	ctx, cancel := withActionTimeout(ctx, ae)
+	defer cancel()
+	return timeoutCause(ctx, ae.Invoke(ctx))
+}
+
+// exitCodeOf returns the exit code of the command or script that caused
+// err. It returns false if err was not caused by a process that exited.
+func exitCodeOf(err error) (code lbdeploy.ExitCode, ok bool) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ProcessState != nil && exitErr.ProcessState.Exited() {
+		return lbdeploy.ExitCode(exitErr.ExitCode()), true
+	}
+	return 0, false
+}
+
+// sleepWithContext waits for the given duration to elapse. It returns early
+// with an error if the context is cancelled.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,19 @@
+package unixengine
+
+import (
+	"runtime"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// nativeArchitecture returns the architecture of the operating system.
+func nativeArchitecture() lbdeploy.Architecture {
+	switch runtime.GOARCH {
+	case "arm64":
+		return lbdeploy.ArchitectureARM64
+	case "386":
+		return lbdeploy.ArchitectureX86
+	default:
+		return lbdeploy.ArchitectureX64
+	}
+}
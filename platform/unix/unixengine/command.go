@@ -0,0 +1,346 @@
+package unixengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/internal/mergereader"
+)
+
+// commandData holds the ID and definition for a command.
+type commandData struct {
+	ID         lbdeploy.CommandID
+	Definition lbdeploy.Command
+}
+
+// commandEngine manages invocation of a command.
+type commandEngine struct {
+	deployment lbdeploy.Deployment
+	system     System
+	flow       flowData
+	action     actionData
+	command    commandData
+	apps       lbdeploy.AppEvaluation
+	events     lbevent.Recorder
+	force      bool
+}
+
+// Invoke runs the command.
+//
+// Executable commands run an executable file directly. Commands of all
+// other types are prepared by the system, which supports the commands that
+// install applications on its operating system.
+func (engine *commandEngine) Invoke(ctx context.Context) error {
+	definition := engine.command.Definition
+	if err := definition.Validate(); err != nil {
+		return fmt.Errorf("%s is not valid: %w", engine.cmdDesc(), err)
+	}
+
+	var (
+		cmd *Command
+		err error
+	)
+	switch definition.Type {
+	case "", lbdeploy.CommandTypeExe:
+		cmd, err = engine.exeCommand(ctx)
+	default:
+		cmd, err = engine.system.PrepareCommand(ctx, CommandRequest{engine: engine})
+		if errors.Is(err, errors.ErrUnsupported) {
+			return fmt.Errorf("%s has a command type of \"%s\", which is not supported on %s", engine.cmdDesc(), definition.Type, engine.system.Name())
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if cmd == nil {
+		return nil
+	}
+	if cmd.Cleanup != nil {
+		defer cmd.Cleanup()
+	}
+
+	return engine.run(ctx, cmd)
+}
+
+// exeCommand prepares a command that runs the command's executable file.
+func (engine *commandEngine) exeCommand(ctx context.Context) (*Command, error) {
+	// Resolve the executable file and make sure it exists.
+	fileID := lbdeploy.FileResourceID(engine.command.Definition.Executable)
+	execPath, err := localFilePath(engine.system, engine.deployment.Resources.FileSystem, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("%s refers to an executable file \"%s\" that could not be resolved: %w", engine.cmdDesc(), fileID, err)
+	}
+
+	// Make sure the executable satisfies any integrity requirements before
+	// it is run.
+	if err := engine.verifyIntegrity(fileID, execPath); err != nil {
+		return nil, err
+	}
+
+	// Expand placeholders in the arguments.
+	args, err := expandArgs(engine.system, engine.deployment, engine.command.Definition.Args)
+	if err != nil {
+		return nil, fmt.Errorf("the arguments for %s could not be prepared: %w", engine.cmdDesc(), err)
+	}
+
+	// Determine the working directory.
+	workingDir, err := engine.workingDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("a working directory could not be determined for %s: %w", engine.cmdDesc(), err)
+	}
+	if workingDir == "" {
+		workingDir = filepath.Dir(execPath)
+	}
+
+	cmd := exec.CommandContext(ctx, execPath, args...)
+	cmd.Dir = workingDir
+	return &Command{Cmd: cmd}, nil
+}
+
+// run executes the prepared command, records its progress and verifies
+// that the expected application changes took effect.
+//
+// The environment of the command is replaced by the environment of the
+// deployment and command, followed by any variables provided by the
+// system.
+func (engine *commandEngine) run(ctx context.Context, command *Command) (err error) {
+	definition := engine.command.Definition
+	cmd := command.Cmd
+
+	// Prepare the environment.
+	env, err := buildEnvironment(ctx, engine.system, engine.deployment, definition.Environment)
+	if err != nil {
+		return err
+	}
+	if len(command.Env) > 0 {
+		if env == nil {
+			env = os.Environ()
+		}
+		env = append(env, command.Env...)
+	}
+	cmd.Env = env
+
+	// Run the command in its own process group, so that the entire group is
+	// terminated if the context is cancelled.
+	setProcessGroup(cmd)
+
+	// Give the command up to one minute to exit after it has been asked to
+	// stop.
+	cmd.WaitDelay = time.Minute
+
+	// Prepare two sets of output pipes for the command.
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	// Record the start of the command.
+	engine.events.Record(lbdeployevent.CommandStarted{
+		Deployment:           engine.deployment.ID,
+		Flow:                 engine.flow.ID,
+		ActionIndex:          engine.action.Index,
+		ActionType:           engine.action.Definition.Type,
+		Command:              engine.command.ID,
+		CommandLine:          cmd.String(),
+		WorkingDirectory:     definition.WorkingDirectory,
+		WorkingDirectoryPath: cmd.Dir,
+		Apps:                 engine.apps,
+	})
+
+	// Prepare a buffer to hold a limited amount of the combined output.
+	output := newOutputBuffer(definition.Output)
+
+	// If requested, write the complete output to a file.
+	var outputPath string
+	if id := definition.Output.File; id != "" {
+		file, err := createOutputFile(engine.system, engine.deployment.Resources.FileSystem, id)
+		if err != nil {
+			return fmt.Errorf("the output file for %s could not be created: %w", engine.cmdDesc(), err)
+		}
+		defer file.Close()
+		output.Tee(file)
+		outputPath = file.Name()
+	}
+
+	// Record the time that the command started.
+	started := time.Now()
+
+	// Start the command.
+	err = cmd.Start()
+
+	// If the command started successfully, send its output to stdout and
+	// stderr as well as the output buffer, then wait for it to finish.
+	if err == nil {
+		r1 := io.TeeReader(stdout, os.Stdout)
+		r2 := io.TeeReader(stderr, os.Stderr)
+		io.Copy(output, mergereader.New(r1, r2))
+		err = cmd.Wait()
+	}
+
+	// Record the time that the command stopped.
+	stopped := time.Now()
+
+	// Analyze the exit code of the command.
+	result, err := buildCommandResult(err, definition.ExitCodes)
+
+	// If the command succeeded, let the system complete its work before
+	// the application changes are verified.
+	if err == nil && command.Complete != nil {
+		if err = command.Complete(); err != nil {
+			err = fmt.Errorf("%s could not be completed: %w", engine.cmdDesc(), err)
+		}
+	}
+
+	// If the command was terminated because its action or flow exceeded its
+	// timeout, report that instead of its exit code.
+	var timeout time.Duration
+	var timeoutErr TimeoutError
+	if err != nil && errors.As(context.Cause(ctx), &timeoutErr) {
+		err = fmt.Errorf("%s was stopped because %w", engine.cmdDesc(), timeoutErr)
+		timeout = timeoutErr.Timeout
+	}
+
+	// Evaluate the effectiveness of any expected application changes.
+	ae := NewAppEngine(engine.system, engine.deployment)
+	appSummary, appSummaryErr := ae.SummarizeAppChanges(engine.apps)
+	if appSummaryErr != nil {
+		appSummaryErr = fmt.Errorf("failed to determine the state of installed applications after the command was invoked: %w", appSummaryErr)
+		if err == nil {
+			err = appSummaryErr
+		}
+	}
+
+	// Record the end of the command.
+	engine.events.Record(lbdeployevent.CommandStopped{
+		Deployment:           engine.deployment.ID,
+		Flow:                 engine.flow.ID,
+		ActionIndex:          engine.action.Index,
+		ActionType:           engine.action.Definition.Type,
+		Command:              engine.command.ID,
+		CommandLine:          cmd.String(),
+		Result:               result,
+		Output:               output.String(),
+		OutputDiscarded:      output.Discarded(),
+		OutputPath:           outputPath,
+		WorkingDirectory:     definition.WorkingDirectory,
+		WorkingDirectoryPath: cmd.Dir,
+		AppsBefore:           engine.apps,
+		AppsAfter:            appSummary,
+		Started:              started,
+		Stopped:              stopped,
+		Timeout:              timeout,
+		Err:                  err,
+	})
+
+	// If the command returned an error, return that.
+	if err != nil {
+		return err
+	}
+
+	// If the application summary indicates that an expected change to the
+	// installed set of applications didn't take effect, return the error.
+	return appSummary.Err()
+}
+
+// verifyIntegrity checks the integrity requirements of the given executable
+// file and records the result. Files without requirements are not checked.
+func (engine *commandEngine) verifyIntegrity(fileID lbdeploy.FileResourceID, path string) error {
+	integrity := engine.deployment.Resources.FileSystem.Files[fileID].Integrity
+	if integrity.IsZero() {
+		return nil
+	}
+
+	actual, err := checkFileIntegrity(path, integrity)
+	if err != nil {
+		err = fmt.Errorf("the executable file \"%s\" for %s failed its integrity check: %w", fileID, engine.cmdDesc(), err)
+	}
+
+	engine.events.Record(lbdeployevent.FileIntegrity{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		FileID:      fileID,
+		FilePath:    path,
+		Required:    integrity,
+		Actual:      actual,
+		Err:         err,
+	})
+
+	return err
+}
+
+// cmdDesc returns a string describing the command. It is used to build
+// error messages.
+func (engine *commandEngine) cmdDesc() string {
+	return fmt.Sprintf("the \"%s\" command", engine.command.ID)
+}
+
+// workingDirectory returns an absolute path to the command's working
+// directory. If a working directory was not provided for the command, it
+// returns an empty string.
+//
+// If the working directory could not be resolved or does not exist, it
+// returns an error.
+func (engine *commandEngine) workingDirectory() (path string, err error) {
+	dirID := engine.command.Definition.WorkingDirectory
+	if dirID == "" {
+		return "", nil
+	}
+
+	return localDirPath(engine.system, engine.deployment.Resources.FileSystem, dirID)
+}
+
+// buildCommandResult examines the error returned by a command and
+// interprets its exit code. The exit code is looked up in exitCodes.
+//
+// If the exit code is known to be successful, a nil error is returned.
+func buildCommandResult(cmdError error, exitCodes lbdeploy.ExitCodeMap) (result lbdeploy.CommandResult, err error) {
+	// If the command returned an error, examine it.
+	if cmdError != nil {
+		// Assume that any error returned by cmd.Wait() is a real error,
+		// unless we later succeed in looking up an exit code that we're
+		// familiar with and proving that it's okay.
+		err = cmdError
+
+		// If we can't interpret the error as an exit error, then something
+		// strange happened when trying to run the command.
+		var exitErr *exec.ExitError
+		if !errors.As(cmdError, &exitErr) {
+			return
+		}
+
+		// If the process state is missing or the process was terminated by
+		// a signal, there is no exit code.
+		if exitErr.ProcessState == nil || !exitErr.ProcessState.Exited() {
+			return
+		}
+
+		// Record the exit code returned by the command.
+		result.ExitCode = lbdeploy.ExitCode(exitErr.ExitCode())
+	}
+
+	// Attempt to look up the error code information in the command.
+	if info, found := exitCodes[result.ExitCode]; found {
+		result.Info = info
+		if info.OK {
+			err = nil
+		}
+	}
+
+	return
+}
@@ -0,0 +1,90 @@
+package unixengine_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+func TestCommandResult(t *testing.T) {
+	fixtures := []struct {
+		Name      string
+		Script    string
+		ExitCodes lbdeploy.ExitCodeMap
+		Result    lbdeploy.CommandResult
+		Err       string
+	}{
+		{Name: "success", Script: "exit 0"},
+		{Name: "failure", Script: "exit 3", Result: lbdeploy.CommandResult{ExitCode: 3}, Err: "exit status 3"},
+		{
+			Name:      "known-failure",
+			Script:    "exit 3",
+			ExitCodes: lbdeploy.ExitCodeMap{3: {Name: "locked"}},
+			Result:    lbdeploy.CommandResult{ExitCode: 3, Info: lbdeploy.ExitCodeInfo{Name: "locked"}},
+			Err:       "exit status 3",
+		},
+		{
+			Name:      "known-success",
+			Script:    "exit 3",
+			ExitCodes: lbdeploy.ExitCodeMap{3: {Name: "restart-required", OK: true}},
+			Result:    lbdeploy.CommandResult{ExitCode: 3, Info: lbdeploy.ExitCodeInfo{Name: "restart-required", OK: true}},
+		},
+		{
+			// A command that is terminated by a signal has no exit code,
+			// so it must not be mistaken for one that exited with zero.
+			Name:      "signal",
+			Script:    "kill -TERM $$",
+			ExitCodes: lbdeploy.ExitCodeMap{0: {Name: "success", OK: true}},
+			Err:       "signal: terminated",
+		},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			dep := lbdeploy.Deployment{
+				ID: "contoso-app",
+				Resources: lbdeploy.Resources{
+					FileSystem: lbdeploy.FileSystemResources{
+						Files: lbdeploy.FileResourceMap{"script": {Path: "script.sh"}},
+					},
+				},
+				Commands: lbdeploy.CommandMap{
+					"run": {Executable: "script", ExitCodes: fixture.ExitCodes},
+				},
+				Flows: lbdeploy.FlowMap{
+					"install": {Actions: []lbdeploy.Action{{Type: lbdeploy.ActionInvokeCommand, Command: "run"}}},
+				},
+			}
+			system := newTestSystem(t, dep, lbtest.System{})
+			system.writeScript(t, "script.sh", fixture.Script)
+
+			var log eventLog
+			engine := unixengine.NewDeploymentEngine(system, dep, unixengine.Options{Events: lbevent.Recorder{Handler: &log}})
+			err := engine.Invoke(context.Background(), "install")
+
+			switch {
+			case fixture.Err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case fixture.Err != "" && (err == nil || !strings.Contains(err.Error(), fixture.Err)):
+				t.Fatalf("got error %v, want an error containing \"%s\"", err, fixture.Err)
+			}
+
+			stopped, found := findEvent[lbdeployevent.CommandStopped](&log)
+			if !found {
+				t.Fatal("the end of the command was not recorded")
+			}
+			if stopped.Result != fixture.Result {
+				t.Errorf("got result %+v, want %+v", stopped.Result, fixture.Result)
+			}
+			if (stopped.Err == nil) != (fixture.Err == "") {
+				t.Errorf("got recorded error %v, want an error: %t", stopped.Err, fixture.Err != "")
+			}
+		})
+	}
+}
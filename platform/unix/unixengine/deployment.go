@@ -0,0 +1,156 @@
+// Package unixengine invokes LeafBridge deployments on Unix-like operating
+// systems.
+//
+// Flows, actions, hooks, rollback and scripts are handled the same way on
+// each system. Commands are executed directly and scripts are run with sh.
+// The parts that differ, such as the location of files and state and the
+// commands that install applications, are provided by a [System].
+//
+// Features that depend on Windows, such as the registry, mutexes, locks,
+// packages and msiexec, are reported as unsupported when they are used.
+package unixengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// ErrDeploymentTimeout is returned when a deployment is stopped because it
+// exceeded its timeout.
+var ErrDeploymentTimeout = lberror.New(lberror.Timeout, "the deployment exceeded its timeout")
+
+// ErrDeploymentCancelled is returned when a deployment is stopped because
+// its context was cancelled.
+var ErrDeploymentCancelled = lberror.New(lberror.Cancelled, "the deployment was cancelled")
+
+// DeploymentEngine is a LeafBridge engine that is responsible for invocation
+// of deployments on a Unix-like operating system.
+type DeploymentEngine struct {
+	deployment lbdeploy.Deployment
+	system     System
+	events     lbevent.Recorder
+	force      bool
+	timeout    time.Duration
+	state      *engineState
+}
+
+// NewDeploymentEngine returns a new LeafBridge deployment engine for the
+// given system, deployment and options.
+func NewDeploymentEngine(system System, deployment lbdeploy.Deployment, opts Options) DeploymentEngine {
+	state := newEngineState()
+	state.traceConditions = opts.TraceConditions
+	return DeploymentEngine{
+		deployment: deployment,
+		system:     system,
+		events:     opts.Events,
+		force:      opts.Force,
+		timeout:    opts.Timeout,
+		state:      state,
+	}
+}
+
+// Invoke executes a flow within a LeafBridge deployment.
+func (engine DeploymentEngine) Invoke(ctx context.Context, flow lbdeploy.FlowID) error {
+	// Assign a unique run ID to this invocation, along with the name of the
+	// machine, so that its events can be correlated during log analysis.
+	events := engine.events
+	if events.Origin.RunID == "" {
+		events.Origin.RunID = lbevent.NewRunID()
+	}
+	if events.Origin.Machine == "" {
+		events.Origin.Machine, _ = os.Hostname()
+	}
+	engine.events = events
+
+	// Ensure that the deployment is valid.
+	if err := engine.deployment.Validate(); err != nil {
+		return err
+	}
+
+	// Select the packages that suit the architecture of the system.
+	dep, err := engine.deployment.ForArchitecture(nativeArchitecture())
+	if err != nil {
+		return err
+	}
+	engine.deployment = dep
+
+	// Find the requested flow within the deployment.
+	definition, found := engine.deployment.Flows[flow]
+	if !found {
+		return fmt.Errorf("the flow \"%s\" does not exist within the \"%s\" deployment", flow, engine.deployment.ID)
+	}
+
+	// Refuse to run outside of the deployment's validity window.
+	if err := checkValidity(engine.events, engine.deployment.ID, flow, engine.deployment.Validity(), false); err != nil {
+		return err
+	}
+
+	// Make sure that the execution policy of the machine permits the
+	// deployment before anything is started.
+	if err := engine.checkPolicy(flow); err != nil {
+		return err
+	}
+
+	// If the deployment has a timeout, enforce it. When the timeout is
+	// exceeded the context is cancelled, which terminates any process
+	// groups that are running.
+	timeout := engine.timeout
+	if timeout == 0 {
+		timeout = time.Duration(engine.deployment.Timeout)
+	}
+	started := time.Now()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrDeploymentTimeout)
+		defer cancel()
+	}
+
+	// Invoke the requested flow.
+	fe := flowEngine{
+		deployment: engine.deployment,
+		flow: flowData{
+			ID:         flow,
+			Definition: definition,
+		},
+		system: engine.system,
+		events: engine.events,
+		force:  engine.force,
+		state:  engine.state,
+	}
+
+	err = fe.Invoke(ctx)
+
+	// If the deployment timed out, record it and report it to the caller.
+	if timeout > 0 && errors.Is(context.Cause(ctx), ErrDeploymentTimeout) {
+		engine.events.Record(lbdeployevent.DeploymentTimeout{
+			Deployment: engine.deployment.ID,
+			Flow:       flow,
+			Timeout:    timeout,
+			Started:    started,
+			Stopped:    time.Now(),
+		})
+		return fmt.Errorf("the \"%s\" deployment was stopped after %s: %w", engine.deployment.ID, timeout, ErrDeploymentTimeout)
+	}
+
+	// If the deployment was cancelled, record it and report it to the
+	// caller as a cancellation rather than a failure.
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		engine.events.Record(lbdeployevent.DeploymentCancelled{
+			Deployment: engine.deployment.ID,
+			Flow:       flow,
+			Started:    started,
+			Stopped:    time.Now(),
+		})
+		return fmt.Errorf("the \"%s\" deployment was stopped: %w", engine.deployment.ID, ErrDeploymentCancelled)
+	}
+
+	return err
+}
@@ -0,0 +1,152 @@
+package unixengine_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+// testSystem is a system that keeps its configuration and state in a
+// temporary directory. Conditions and applications are evaluated against a
+// simulated platform.
+type testSystem struct {
+	dir      string
+	platform lbplatform.Platform
+}
+
+func newTestSystem(t *testing.T, dep lbdeploy.Deployment, state lbtest.System) testSystem {
+	t.Helper()
+	platform, err := lbtest.NewPlatform(dep, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testSystem{dir: t.TempDir(), platform: platform}
+}
+
+func (s testSystem) Name() string { return "Test" }
+
+func (s testSystem) Platform() lbplatform.Platform { return s.platform }
+
+func (s testSystem) Resolver(resources lbdeploy.FileSystemResources) unixengine.Resolver {
+	return testResolver{root: s.dir, resources: resources}
+}
+
+func (s testSystem) StateDir(scope lbdeploy.FrequencyScope) (string, error) {
+	return filepath.Join(s.dir, "state", string(scope)), nil
+}
+
+func (s testSystem) ConfigDir() (string, error) {
+	return filepath.Join(s.dir, "config"), nil
+}
+
+func (s testSystem) PrepareCommand(ctx context.Context, req unixengine.CommandRequest) (*unixengine.Command, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// writeConfig writes a file to the configuration directory of the system.
+func (s testSystem) writeConfig(t *testing.T, name, content string) {
+	t.Helper()
+	dir, _ := s.ConfigDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testResolver resolves files and directories relative to the root of a
+// test system. Locations are ignored.
+type testResolver struct {
+	root      string
+	resources lbdeploy.FileSystemResources
+}
+
+func (r testResolver) ResolveDirectory(id lbdeploy.DirectoryResourceID) (lbdeploy.DirRef, error) {
+	dir, found := r.resources.Directories[id]
+	if !found {
+		return lbdeploy.DirRef{}, errors.New("the directory is not defined")
+	}
+	return lbdeploy.DirRef{Root: lbdeploy.KnownFolder{Path: r.root}, Lineage: []lbdeploy.DirectoryResource{dir}}, nil
+}
+
+func (r testResolver) ResolveFile(id lbdeploy.FileResourceID) (lbdeploy.FileRef, error) {
+	file, found := r.resources.Files[id]
+	if !found {
+		return lbdeploy.FileRef{}, errors.New("the file is not defined")
+	}
+	return lbdeploy.FileRef{Root: lbdeploy.KnownFolder{Path: r.root}, FileID: id, FilePath: file.Path}, nil
+}
+
+// eventLog is an event handler that collects the events it receives.
+type eventLog struct {
+	events []lbevent.Interface
+}
+
+func (l *eventLog) Name() string { return "test" }
+
+func (l *eventLog) Handle(record lbevent.Record) error {
+	l.events = append(l.events, record.Data())
+	return nil
+}
+
+// findEvent returns the first event of type T in the log.
+func findEvent[T lbevent.Interface](l *eventLog) (T, bool) {
+	for _, event := range l.events {
+		if e, ok := event.(T); ok {
+			return e, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// findEvents returns the events of type T in the log that satisfy match.
+func findEvents[T lbevent.Interface](l *eventLog, match func(T) bool) []T {
+	var events []T
+	for _, event := range l.events {
+		if e, ok := event.(T); ok && match(e) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// writeScript writes an executable shell script with the given body to the
+// root of the system.
+func (s testSystem) writeScript(t *testing.T, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// scriptAction returns a shell script action that runs the given script.
+func scriptAction(script string) lbdeploy.Action {
+	return lbdeploy.Action{
+		Type:   lbdeploy.ActionShellScript,
+		Script: lbdeploy.Script{Inline: script},
+	}
+}
+
+// touchAction returns a shell script action that creates the file at path.
+func touchAction(path string) lbdeploy.Action {
+	return lbdeploy.Action{
+		Type:   lbdeploy.ActionShellScript,
+		Script: lbdeploy.Script{Inline: `touch "$1"`, Args: []string{path}},
+	}
+}
+
+// exists returns true if a file exists at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,79 @@
+package unixengine_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+func TestFlowOnError(t *testing.T) {
+	fixtures := []struct {
+		Name     string
+		Behavior lbdeploy.OnErrorBehavior
+		OnError  lbdeploy.OnErrorBehavior
+		Flow     lbdeploy.FlowID
+		Failed   bool
+		Next     bool
+		Repaired bool
+		Stats    lbdeploy.FlowStats
+	}{
+		{Name: "unspecified", Failed: true, Stats: lbdeploy.FlowStats{ActionsFailed: 1}},
+		{Name: "stop", OnError: lbdeploy.OnErrorStop, Failed: true, Stats: lbdeploy.FlowStats{ActionsFailed: 1}},
+		{Name: "continue", OnError: lbdeploy.OnErrorContinue, Next: true, Stats: lbdeploy.FlowStats{ActionsIgnored: 1, ActionsCompleted: 1}},
+		{Name: "flow-continue", Behavior: lbdeploy.OnErrorContinue, Failed: true, Next: true, Stats: lbdeploy.FlowStats{ActionsFailed: 1, ActionsCompleted: 1}},
+		{Name: "flow-continue-action-stop", Behavior: lbdeploy.OnErrorContinue, OnError: lbdeploy.OnErrorStop, Failed: true, Stats: lbdeploy.FlowStats{ActionsFailed: 1}},
+		{Name: "goto-flow", OnError: lbdeploy.OnErrorGotoFlow, Flow: "repair", Repaired: true, Stats: lbdeploy.FlowStats{ActionsIgnored: 1}},
+		{Name: "goto-flow-failed", OnError: lbdeploy.OnErrorGotoFlow, Flow: "broken", Failed: true, Stats: lbdeploy.FlowStats{ActionsFailed: 1}},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			dir := t.TempDir()
+			next, repaired := filepath.Join(dir, "next"), filepath.Join(dir, "repaired")
+
+			failing := scriptAction("exit 1")
+			failing.OnError = fixture.OnError
+			failing.OnErrorFlow = fixture.Flow
+			dep := lbdeploy.Deployment{
+				ID: "contoso-app",
+				Flows: lbdeploy.FlowMap{
+					"install": {
+						Behavior: lbdeploy.Behavior{OnError: fixture.Behavior},
+						Actions:  []lbdeploy.Action{failing, touchAction(next)},
+					},
+					"repair": {Actions: []lbdeploy.Action{touchAction(repaired)}},
+					"broken": {Actions: []lbdeploy.Action{scriptAction("exit 2")}},
+				},
+			}
+			system := newTestSystem(t, dep, lbtest.System{})
+
+			var log eventLog
+			engine := unixengine.NewDeploymentEngine(system, dep, unixengine.Options{Events: lbevent.Recorder{Handler: &log}})
+			err := engine.Invoke(context.Background(), "install")
+
+			if failed := err != nil; failed != fixture.Failed {
+				t.Errorf("got error %v, want failure: %t", err, fixture.Failed)
+			}
+			if ran := exists(next); ran != fixture.Next {
+				t.Errorf("the next action ran: %t, want %t", ran, fixture.Next)
+			}
+			if ran := exists(repaired); ran != fixture.Repaired {
+				t.Errorf("the repair flow ran: %t, want %t", ran, fixture.Repaired)
+			}
+
+			stopped := findEvents(&log, func(e lbdeployevent.FlowStopped) bool { return e.Flow == "install" })
+			if len(stopped) != 1 {
+				t.Fatalf("got %d records of the flow stopping, want 1", len(stopped))
+			}
+			if stopped[0].Stats != fixture.Stats {
+				t.Errorf("got stats %+v, want %+v", stopped[0].Stats, fixture.Stats)
+			}
+		})
+	}
+}
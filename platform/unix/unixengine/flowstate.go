@@ -0,0 +1,112 @@
+package unixengine
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbstate"
+)
+
+// openFlowState returns the persistent state store for a deployment in the
+// given frequency scope.
+//
+// The location of the store is determined by the system.
+func openFlowState(system System, id lbdeploy.DeploymentID, scope lbdeploy.FrequencyScope) (lbstate.Store, error) {
+	if err := id.Validate(); err != nil {
+		return lbstate.Store{}, err
+	}
+	dir, err := system.StateDir(scope)
+	if err != nil {
+		return lbstate.Store{}, err
+	}
+	return lbstate.NewStore(filepath.Join(dir, string(id)+".json")), nil
+}
+
+// checkFrequency returns false if the flow has already run as often as its
+// frequency allows, in which case an event is recorded. Forced invocations
+// are always permitted.
+func (engine flowEngine) checkFrequency() (bool, error) {
+	frequency := engine.flow.Definition.Frequency
+	if frequency.IsZero() || engine.force {
+		return true, nil
+	}
+
+	store, err := openFlowState(engine.system, engine.deployment.ID, frequency.EffectiveScope())
+	if err != nil {
+		return false, fmt.Errorf("the \"%s\" flow failed to open its state store: %w", engine.flow.ID, err)
+	}
+	record, err := store.Flow(engine.flow.ID)
+	if err != nil {
+		return false, fmt.Errorf("the \"%s\" flow failed to read its execution history: %w", engine.flow.ID, err)
+	}
+
+	if record.Permits(frequency, time.Now()) {
+		return true, nil
+	}
+
+	engine.events.Record(lbdeployevent.FlowFrequencyLimit{
+		Deployment:    engine.deployment.ID,
+		Flow:          engine.flow.ID,
+		Frequency:     frequency,
+		LastCompleted: record.LastCompleted,
+		Completions:   record.Completions,
+		NextRun:       record.NextRun(frequency),
+	})
+
+	return false, nil
+}
+
+// recordCompletion records the successful completion of the flow in its
+// state store, if the flow has a frequency.
+func (engine flowEngine) recordCompletion(completed time.Time) error {
+	frequency := engine.flow.Definition.Frequency
+	if frequency.IsZero() {
+		return nil
+	}
+
+	store, err := openFlowState(engine.system, engine.deployment.ID, frequency.EffectiveScope())
+	if err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to open its state store: %w", engine.flow.ID, err)
+	}
+	if err := store.RecordCompletion(engine.flow.ID, completed); err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to record its completion: %w", engine.flow.ID, err)
+	}
+
+	return nil
+}
+
+// recordDurations records the duration of each of the flow's actions in
+// its state store, so that the progress of later runs can be estimated.
+// Progress estimates are not essential, so failures are ignored.
+func (engine flowEngine) recordDurations(durations []time.Duration) {
+	store, err := openFlowState(engine.system, engine.deployment.ID, engine.flow.Definition.Frequency.EffectiveScope())
+	if err != nil {
+		return
+	}
+	store.RecordDurations(engine.flow.ID, durations)
+}
+
+// ActionDurations returns the duration of each action in the deployment's
+// flows when the flow last completed, as recorded in the state stores of
+// the given system. Flows without recorded durations are omitted.
+func ActionDurations(system System, dep lbdeploy.Deployment) map[lbdeploy.FlowID][]time.Duration {
+	durations := make(map[lbdeploy.FlowID][]time.Duration)
+	states := make(map[lbdeploy.FrequencyScope]lbstate.DeploymentState)
+	for id, flow := range dep.Flows {
+		scope := flow.Frequency.EffectiveScope()
+		state, loaded := states[scope]
+		if !loaded {
+			if store, err := openFlowState(system, dep.ID, scope); err == nil {
+				state, _ = store.Load()
+			}
+			states[scope] = state
+		}
+		if recorded := state.Flows[id].ActionDurations(); recorded != nil {
+			durations[id] = recorded
+		}
+	}
+	return durations
+}
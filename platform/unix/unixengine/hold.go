@@ -0,0 +1,22 @@
+package unixengine
+
+import (
+	"slices"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbpolicy"
+)
+
+// holdsFile is the name of the file in the configuration directory that
+// holds the app holds that have been placed on the machine.
+const holdsFile = "holds.json"
+
+// heldApp returns the first app installed or uninstalled by the command
+// that is held on the machine, along with its hold. It returns false if
+// none of the apps are held.
+func heldApp(state *engineState, dep lbdeploy.Deployment, command lbdeploy.Command) (lbdeploy.AppID, lbpolicy.Hold, bool) {
+	if len(state.holds) == 0 {
+		return "", lbpolicy.Hold{}, false
+	}
+	return state.holds.Find(dep.Apps, slices.Concat(command.Installs, command.Uninstalls)...)
+}
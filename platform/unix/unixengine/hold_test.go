@@ -0,0 +1,89 @@
+package unixengine_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+func TestHolds(t *testing.T) {
+	fixtures := []struct {
+		Name   string
+		Policy string
+		Holds  string
+		Held   bool
+	}{
+		{Name: "none"},
+		{Name: "holds-file", Holds: `[{"app": "contoso", "reason": "CHG-1234"}]`, Held: true},
+		{Name: "policy", Policy: `{"holds": [{"app": "contoso", "reason": "CHG-1234"}]}`, Held: true},
+		{Name: "other-app", Holds: `[{"app": "fabrikam", "reason": "CHG-1234"}]`},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			dep := lbdeploy.Deployment{
+				ID: "contoso-app",
+				Apps: lbdeploy.AppMap{
+					"contoso":  {Name: "Contoso"},
+					"fabrikam": {Name: "Fabrikam"},
+				},
+				Resources: lbdeploy.Resources{
+					FileSystem: lbdeploy.FileSystemResources{
+						Files: lbdeploy.FileResourceMap{"setup": {Path: "setup.sh"}},
+					},
+				},
+				Commands: lbdeploy.CommandMap{
+					"install": {Installs: lbdeploy.AppList{"contoso"}, Executable: "setup"},
+				},
+				Flows: lbdeploy.FlowMap{
+					"install": {Actions: []lbdeploy.Action{{Type: lbdeploy.ActionInvokeCommand, Command: "install"}}},
+				},
+			}
+
+			// The app is already installed and the command is forced, so
+			// only a hold keeps the command from running.
+			system := newTestSystem(t, dep, lbtest.System{Apps: map[lbdeploy.AppID]datatype.Version{"contoso": "1.0"}})
+			marker := filepath.Join(system.dir, "ran")
+			script := fmt.Sprintf("#!/bin/sh\ntouch '%s'\n", marker)
+			if err := os.WriteFile(filepath.Join(system.dir, "setup.sh"), []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if fixture.Policy != "" {
+				system.writeConfig(t, "policy.json", fixture.Policy)
+			}
+			if fixture.Holds != "" {
+				system.writeConfig(t, "holds.json", fixture.Holds)
+			}
+
+			var log eventLog
+			engine := unixengine.NewDeploymentEngine(system, dep, unixengine.Options{Events: lbevent.Recorder{Handler: &log}, Force: true})
+			if err := engine.Invoke(context.Background(), "install"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			skipped, found := findEvent[lbdeployevent.CommandSkipped](&log)
+			switch {
+			case !fixture.Held && found:
+				t.Errorf("the command was skipped because the \"%s\" app is held", skipped.HeldApp)
+			case !fixture.Held && !exists(marker):
+				t.Error("the command did not run")
+			case !fixture.Held:
+			case !found:
+				t.Error("no skipped command was recorded")
+			case skipped.HeldApp != "contoso" || skipped.HoldReason != "CHG-1234":
+				t.Errorf("got held app \"%s\" with reason \"%s\", want \"contoso\" with reason \"CHG-1234\"", skipped.HeldApp, skipped.HoldReason)
+			case exists(marker):
+				t.Error("the command ran even though its app is held")
+			}
+		})
+	}
+}
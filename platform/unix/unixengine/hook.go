@@ -0,0 +1,108 @@
+package unixengine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// hookVariablesKey is the context key for hook environment variables.
+type hookVariablesKey struct{}
+
+// withHookVariables returns a copy of ctx that carries the given
+// environment variables, in NAME=value form. Processes started on behalf
+// of ctx will receive them.
+func withHookVariables(ctx context.Context, vars []string) context.Context {
+	return context.WithValue(ctx, hookVariablesKey{}, vars)
+}
+
+// hookVariables returns the hook environment variables carried by ctx.
+func hookVariables(ctx context.Context) []string {
+	vars, _ := ctx.Value(hookVariablesKey{}).([]string)
+	return vars
+}
+
+// hookResult describes the outcome of an action for the hooks that run
+// after it.
+type hookResult struct {
+	Err error
+}
+
+// runHooks invokes the given hook actions for an action within the flow.
+//
+// For hooks that run after the action, result describes the outcome of the
+// action. It is ignored for hooks that run before the action.
+func (engine flowEngine) runHooks(ctx context.Context, stage lbdeploy.HookStage, hooks []lbdeploy.Action, action actionData, result hookResult) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	// Describe the action to the hooks.
+	vars := []string{
+		"LEAFBRIDGE_HOOK=" + string(stage),
+		"LEAFBRIDGE_DEPLOYMENT=" + string(engine.deployment.ID),
+		"LEAFBRIDGE_FLOW=" + string(engine.flow.ID),
+		"LEAFBRIDGE_ACTION_INDEX=" + strconv.Itoa(action.Index+1),
+		"LEAFBRIDGE_ACTION_TYPE=" + string(action.Definition.Type),
+	}
+	if stage == lbdeploy.HookAfter {
+		if result.Err != nil {
+			vars = append(vars, "LEAFBRIDGE_ACTION_RESULT=failed", "LEAFBRIDGE_ACTION_ERROR="+result.Err.Error())
+		} else {
+			vars = append(vars, "LEAFBRIDGE_ACTION_RESULT=completed")
+		}
+	}
+	hookCtx := withHookVariables(ctx, append(hookVariables(ctx), vars...))
+
+	// Invoke each hook in order.
+	for i, hook := range hooks {
+		// Check for context cancellation.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Prepare an action engine for the hook. Events recorded by the hook
+		// are attributed to the action it is attached to.
+		ae := actionEngine{
+			deployment: engine.deployment,
+			flow:       engine.flow,
+			action: actionData{
+				Index:      action.Index,
+				Definition: hook,
+			},
+			system: engine.system,
+			events: engine.events,
+			force:  engine.force,
+			state:  engine.state,
+		}
+
+		// Evaluate the conditions for the hook, if it has any.
+		if len(hook.Conditions) > 0 {
+			passed, err := ae.EvaluateConditions()
+			if err != nil {
+				if hook.OnError == lbdeploy.OnErrorContinue {
+					continue
+				}
+				return fmt.Errorf("%s hook %d: %w", stage, i+1, err)
+			}
+			if !passed {
+				continue
+			}
+		}
+
+		// Invoke the hook.
+		if err := ae.Invoke(hookCtx); err != nil {
+			if ctx.Err() == err {
+				return err
+			}
+			if hook.OnError == lbdeploy.OnErrorContinue {
+				continue
+			}
+			return fmt.Errorf("%s hook %d: %w", stage, i+1, err)
+		}
+	}
+
+	return nil
+}
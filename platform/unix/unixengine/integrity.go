@@ -0,0 +1,52 @@
+package unixengine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// checkFileIntegrity verifies that the file at path satisfies the given
+// integrity requirements. It returns the computed attributes of the file,
+// if they were examined.
+//
+// Authenticode signatures can only be verified on Windows, so a requirement
+// for a signature always fails.
+//
+// If the file does not satisfy the requirements, it returns an error.
+func checkFileIntegrity(path string, integrity lbdeploy.FileIntegrity) (actual lbdeploy.FileAttributes, err error) {
+	// Verify the file's hashes.
+	if len(integrity.Hashes) > 0 {
+		verifier, err := NewFileVerifier(integrity.Hashes.Types()...)
+		if err != nil {
+			return actual, err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return actual, err
+		}
+		_, err = verifier.ReadFrom(file)
+		file.Close()
+		if err != nil {
+			return actual, fmt.Errorf("the file could not be read: %w", err)
+		}
+
+		actual = verifier.State()
+		for _, expected := range integrity.Hashes.ToList() {
+			if !bytes.Equal(actual.Hashes[expected.Type], expected.Value) {
+				return actual, fmt.Errorf("the %s hash of the file does not match the expected value", expected.Type)
+			}
+		}
+	}
+
+	// Signatures are not supported.
+	if integrity.Signature.Required {
+		return actual, errors.New("the file requires an Authenticode signature, which can only be verified on Windows")
+	}
+
+	return actual, nil
+}
@@ -0,0 +1,61 @@
+package unixengine
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// localDirPath resolves a directory resource and returns its absolute path.
+//
+// If the directory could not be resolved or does not exist, it returns an
+// error.
+func localDirPath(system System, resources lbdeploy.FileSystemResources, id lbdeploy.DirectoryResourceID) (string, error) {
+	resolver := system.Resolver(resources)
+	dirRef, err := resolver.ResolveDirectory(id)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := dirRef.Path()
+	if err != nil {
+		return "", err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return "", fmt.Errorf("the \"%s\" path exists but it is not a directory", path)
+	}
+
+	return path, nil
+}
+
+// localFilePath resolves a file resource and returns its absolute path.
+//
+// If the file could not be resolved or does not exist, it returns an error.
+func localFilePath(system System, resources lbdeploy.FileSystemResources, id lbdeploy.FileResourceID) (string, error) {
+	resolver := system.Resolver(resources)
+	fileRef, err := resolver.ResolveFile(id)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := fileRef.Path()
+	if err != nil {
+		return "", err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", fmt.Errorf("the \"%s\" path exists but it is not a regular file", path)
+	}
+
+	return path, nil
+}
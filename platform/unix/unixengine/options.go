@@ -0,0 +1,20 @@
+package unixengine
+
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// Options hold configuration options for a LeafBridge deployment engine.
+//
+// If Timeout is non-zero, it overrides the timeout of the deployment.
+//
+// If TraceConditions is true, an event is recorded for each condition that
+// is evaluated, describing what was observed on the system.
+type Options struct {
+	Events          lbevent.Recorder
+	Force           bool
+	Timeout         time.Duration
+	TraceConditions bool
+}
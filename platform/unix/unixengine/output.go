@@ -0,0 +1,29 @@
+package unixengine
+
+import (
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/internal/outputbuffer"
+)
+
+// newOutputBuffer returns an output buffer that captures output according
+// to opts. Output is assumed to be UTF-8.
+func newOutputBuffer(opts lbdeploy.OutputOptions) *outputbuffer.Buffer {
+	return outputbuffer.New(opts, false)
+}
+
+// createOutputFile creates or truncates the file resource that will hold
+// the complete output of a command or script.
+func createOutputFile(system System, resources lbdeploy.FileSystemResources, id lbdeploy.FileResourceID) (*os.File, error) {
+	resolver := system.Resolver(resources)
+	ref, err := resolver.ResolveFile(id)
+	if err != nil {
+		return nil, err
+	}
+	path, err := ref.Path()
+	if err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
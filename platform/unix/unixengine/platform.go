@@ -0,0 +1,46 @@
+package unixengine
+
+import (
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbeval"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// NewConditionEngine prepares a condition engine for the given deployment
+// on the given system.
+func NewConditionEngine(system System, dep lbdeploy.Deployment) lbeval.ConditionEngine {
+	return lbeval.NewConditionEngine(dep, system.Platform())
+}
+
+// traceConditions returns ce with a tracer that records an event for each
+// condition it evaluates, if condition tracing is enabled. The given event
+// identifies where the conditions are being used.
+func traceConditions(ce lbeval.ConditionEngine, state *engineState, events lbevent.Recorder, event lbdeployevent.ConditionObserved) lbeval.ConditionEngine {
+	if !state.traceConditions {
+		return ce
+	}
+	return ce.WithTracer(func(o lbeval.Observation) {
+		event.Condition = o.Condition
+		event.ConditionType = o.Type
+		event.Subject = o.Subject
+		event.Expected = o.Expected()
+		event.Observed = o.Observed
+		event.Negated = o.Negated
+		event.Result = o.Result
+		event.Err = o.Err
+		events.Record(event)
+	})
+}
+
+// NewAppEngine prepares an app engine for the given deployment on the given
+// system.
+func NewAppEngine(system System, dep lbdeploy.Deployment) lbeval.AppEngine {
+	return lbeval.NewAppEngine(dep, system.Platform())
+}
+
+// NewComplianceEngine prepares a compliance engine for the given
+// deployment on the given system.
+func NewComplianceEngine(system System, dep lbdeploy.Deployment) lbeval.ComplianceEngine {
+	return lbeval.NewComplianceEngine(dep, system.Platform())
+}
@@ -0,0 +1,83 @@
+package unixengine
+
+import (
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/core/lbpolicy"
+)
+
+// policyFile is the name of the file that holds the execution policy of
+// the machine.
+const policyFile = "policy.json"
+
+// checkPolicy returns an error if the execution policy of the machine
+// does not permit the deployment. A violation is recorded as an event.
+//
+// If the policy can't be read, the deployment is refused.
+//
+// The app holds of the machine are loaded as well, and an error is returned
+// if they can't be read.
+//
+// The approval of the deployment is checked as well. If the policy requires
+// approval and the deployment doesn't satisfy it, the deployment is refused
+// entirely.
+func (engine DeploymentEngine) checkPolicy(flow lbdeploy.FlowID) error {
+	dir, err := engine.system.ConfigDir()
+	if err != nil {
+		return lberror.Wrap(lberror.Policy, err)
+	}
+	path := filepath.Join(dir, policyFile)
+	policy, err := lbpolicy.Load(path)
+	if err != nil {
+		return lberror.Wrap(lberror.Policy, err)
+	}
+	if err := policy.Check(engine.deployment); err != nil {
+		engine.events.Record(lbdeployevent.PolicyViolation{
+			Deployment: engine.deployment.ID,
+			Flow:       flow,
+			PolicyFile: path,
+			Err:        err,
+		})
+		return err
+	}
+	if err := engine.checkApproval(policy.Approval, flow); err != nil {
+		return err
+	}
+
+	// Collect the app holds of the machine, which are placed either by
+	// the policy or in the holds file.
+	holds, err := lbpolicy.LoadHolds(filepath.Join(dir, holdsFile))
+	if err != nil {
+		return lberror.Wrap(lberror.Policy, err)
+	}
+	engine.state.holds = slices.Concat(policy.Holds, holds)
+
+	return nil
+}
+
+// checkApproval verifies the approval of the deployment against the given
+// requirement, and records the approval for auditing if the deployment
+// carries one or the requirement demands one.
+func (engine DeploymentEngine) checkApproval(req lbpolicy.ApprovalRequirement, flow lbdeploy.FlowID) error {
+	approval := engine.deployment.Approval
+	verified, err := req.Verify(engine.deployment, time.Now())
+	if approval.IsZero() && !req.Required {
+		return nil
+	}
+	engine.events.Record(lbdeployevent.DeploymentApproval{
+		Deployment: engine.deployment.ID,
+		Flow:       flow,
+		Ticket:     approval.Ticket,
+		Approvers:  approval.Approvers,
+		Verified:   verified,
+		Expires:    approval.Expires,
+		Required:   req.Required,
+		Err:        err,
+	})
+	return err
+}
@@ -0,0 +1,196 @@
+package unixengine_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+func TestPolicy(t *testing.T) {
+	fixtures := []struct {
+		Name    string
+		Policy  string
+		Refused bool
+	}{
+		{Name: "none"},
+		{Name: "allowed", Policy: `{"deployments": {"allow": ["contoso-*"]}}`},
+		{Name: "denied", Policy: `{"deployments": {"deny": ["contoso-app"]}}`, Refused: true},
+		{Name: "action-denied", Policy: `{"action-types": {"deny": ["shell-script"]}}`, Refused: true},
+		{Name: "invalid", Policy: `{"deployments": `, Refused: true},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			marker := filepath.Join(t.TempDir(), "ran")
+			dep := lbdeploy.Deployment{
+				ID: "contoso-app",
+				Flows: lbdeploy.FlowMap{
+					"install": {Actions: []lbdeploy.Action{touchAction(marker)}},
+				},
+			}
+			system := newTestSystem(t, dep, lbtest.System{})
+			if fixture.Policy != "" {
+				system.writeConfig(t, "policy.json", fixture.Policy)
+			}
+
+			var log eventLog
+			engine := unixengine.NewDeploymentEngine(system, dep, unixengine.Options{Events: lbevent.Recorder{Handler: &log}})
+			err := engine.Invoke(context.Background(), "install")
+
+			switch {
+			case !fixture.Refused && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case !fixture.Refused:
+				if !exists(marker) {
+					t.Error("the flow did not run")
+				}
+			case err == nil:
+				t.Fatal("the deployment was not refused")
+			case lberror.CategoryOf(err) != lberror.Policy:
+				t.Errorf("got the %s category, want %s", lberror.CategoryOf(err), lberror.Policy)
+			case exists(marker):
+				t.Error("the flow ran even though the deployment was refused")
+			}
+		})
+	}
+}
+
+func TestPolicyViolationEvent(t *testing.T) {
+	dep := lbdeploy.Deployment{
+		ID: "contoso-app",
+		Flows: lbdeploy.FlowMap{
+			"install": {Actions: []lbdeploy.Action{touchAction(filepath.Join(t.TempDir(), "ran"))}},
+		},
+	}
+	system := newTestSystem(t, dep, lbtest.System{})
+	system.writeConfig(t, "policy.json", `{"deployments": {"allow": ["fabrikam-*"]}}`)
+
+	var log eventLog
+	engine := unixengine.NewDeploymentEngine(system, dep, unixengine.Options{Events: lbevent.Recorder{Handler: &log}})
+	if err := engine.Invoke(context.Background(), "install"); err == nil {
+		t.Fatal("the deployment was not refused")
+	}
+
+	event, found := findEvent[lbdeployevent.PolicyViolation](&log)
+	if !found {
+		t.Fatal("no policy violation was recorded")
+	}
+	dir, _ := system.ConfigDir()
+	if want := filepath.Join(dir, "policy.json"); event.PolicyFile != want {
+		t.Errorf("got policy file \"%s\", want \"%s\"", event.PolicyFile, want)
+	}
+	if event.Deployment != dep.ID || event.Flow != "install" {
+		t.Errorf("got deployment \"%s\" and flow \"%s\", want \"%s\" and \"install\"", event.Deployment, event.Flow, dep.ID)
+	}
+}
+
+func TestApproval(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	copy(seed, "alice")
+	key := ed25519.NewKeyFromSeed(seed)
+	policy := fmt.Sprintf(`{"approval": {"required": true, "min-approvers": 1, "keys": {"alice": "%s"}}}`, base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)))
+
+	// decode returns the deployment that is decoded from its document, so
+	// that its approval is verified against the document.
+	decode := func(dep lbdeploy.Deployment) lbdeploy.Deployment {
+		data, err := json.Marshal(dep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded lbdeploy.Deployment
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		return decoded
+	}
+
+	approve := func(dep lbdeploy.Deployment) lbdeploy.Deployment {
+		dep.Approval = lbdeploy.Approval{
+			Approvers: []string{"alice"},
+			Ticket:    "CHG-1234",
+			Expires:   time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second),
+		}
+		digest, err := decode(dep).ApprovalDigest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dep.Approval.Signatures = []lbdeploy.ApprovalSignature{{
+			Approver:  "alice",
+			Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest)),
+		}}
+		return decode(dep)
+	}
+
+	fixtures := []struct {
+		Name     string
+		Approved bool
+	}{
+		{Name: "unapproved"},
+		{Name: "approved", Approved: true},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			marker := filepath.Join(t.TempDir(), "ran")
+			dep := decode(lbdeploy.Deployment{
+				ID: "contoso-app",
+				Flows: lbdeploy.FlowMap{
+					"install": {Actions: []lbdeploy.Action{touchAction(marker)}},
+				},
+			})
+			if fixture.Approved {
+				dep = approve(dep)
+			}
+			system := newTestSystem(t, dep, lbtest.System{})
+			system.writeConfig(t, "policy.json", policy)
+
+			var log eventLog
+			engine := unixengine.NewDeploymentEngine(system, dep, unixengine.Options{Events: lbevent.Recorder{Handler: &log}})
+			err := engine.Invoke(context.Background(), "install")
+
+			event, found := findEvent[lbdeployevent.DeploymentApproval](&log)
+			if !found {
+				t.Fatal("no approval was recorded")
+			}
+			if !event.Required {
+				t.Error("the recorded approval was not required")
+			}
+
+			if fixture.Approved {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(event.Verified) != 1 || event.Verified[0] != "alice" {
+					t.Errorf("got verified approvers %v, want [alice]", event.Verified)
+				}
+				if !exists(marker) {
+					t.Error("the flow did not run")
+				}
+				return
+			}
+
+			switch {
+			case err == nil:
+				t.Fatal("the unapproved deployment was not refused")
+			case lberror.CategoryOf(err) != lberror.Policy:
+				t.Errorf("got the %s category, want %s", lberror.CategoryOf(err), lberror.Policy)
+			case event.Err == nil:
+				t.Error("the recorded approval has no error")
+			case exists(marker):
+				t.Error("the flow ran even though the deployment was refused")
+			}
+		})
+	}
+}
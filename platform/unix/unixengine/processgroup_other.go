@@ -0,0 +1,9 @@
+//go:build !unix
+
+package unixengine
+
+import "os/exec"
+
+// setProcessGroup does nothing on platforms without process groups. When
+// the context of cmd is cancelled, only its own process is killed.
+func setProcessGroup(cmd *exec.Cmd) {}
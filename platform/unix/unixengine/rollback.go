@@ -0,0 +1,135 @@
+package unixengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+)
+
+// rollback undoes the work of the flow's completed actions after the flow
+// has failed with err, as described by the on-failure section of the flow.
+// The completed slice is indexed by the position of each action within the
+// flow.
+//
+// It returns err, joined with any error encountered during the rollback.
+func (engine flowEngine) rollback(ctx context.Context, completed []bool, err error) error {
+	rollback := engine.flow.Definition.OnFailure
+	if rollback.IsZero() {
+		return err
+	}
+
+	// Determine which of the undo steps apply.
+	var count int
+	for _, done := range completed {
+		if done {
+			count++
+		}
+	}
+	steps := rollback.Pending(completed)
+	rollbackFlow := rollback.Flow
+	if count == 0 {
+		rollbackFlow = ""
+	}
+	if len(steps) == 0 && rollbackFlow == "" {
+		return err
+	}
+
+	// Record the start of the rollback.
+	engine.events.Record(lbdeployevent.RollbackStarted{
+		Deployment:   engine.deployment.ID,
+		Flow:         engine.flow.ID,
+		Completed:    count,
+		Steps:        len(steps),
+		RollbackFlow: rollbackFlow,
+	})
+
+	started := time.Now()
+
+	// Run each step, followed by the rollback flow.
+	var errs []error
+	for _, step := range steps {
+		if err := engine.runRollbackStep(ctx, step); err != nil {
+			errs = append(errs, fmt.Errorf("undo of action %d: %w", step.Action, err))
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	if rollbackFlow != "" && ctx.Err() == nil {
+		if err := engine.startFlow(ctx, rollbackFlow); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	rollbackErr := errors.Join(errs...)
+
+	// Record the end of the rollback.
+	engine.events.Record(lbdeployevent.RollbackStopped{
+		Deployment: engine.deployment.ID,
+		Flow:       engine.flow.ID,
+		Started:    started,
+		Stopped:    time.Now(),
+		Err:        rollbackErr,
+	})
+
+	if rollbackErr != nil {
+		return errors.Join(err, fmt.Errorf("the \"%s\" flow failed to roll back: %w", engine.flow.ID, rollbackErr))
+	}
+	return err
+}
+
+// runRollbackStep invokes the compensating actions of a rollback step in
+// order. Events recorded by the actions are attributed to the action that
+// they compensate for.
+func (engine flowEngine) runRollbackStep(ctx context.Context, step lbdeploy.RollbackStep) error {
+	for i, action := range step.Actions {
+		// Check for context cancellation.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Prepare an action engine for the compensating action.
+		ae := actionEngine{
+			deployment: engine.deployment,
+			flow:       engine.flow,
+			action: actionData{
+				Index:      step.Action - 1,
+				Definition: action,
+			},
+			system: engine.system,
+			events: engine.events,
+			force:  engine.force,
+			state:  engine.state,
+		}
+
+		// Evaluate the conditions for the action, if it has any.
+		if len(action.Conditions) > 0 {
+			passed, err := ae.EvaluateConditions()
+			if err != nil {
+				if action.OnError == lbdeploy.OnErrorContinue {
+					continue
+				}
+				return fmt.Errorf("action %d: %w", i+1, err)
+			}
+			if !passed {
+				continue
+			}
+		}
+
+		// Invoke the action.
+		if err := ae.Invoke(ctx); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			if action.OnError == lbdeploy.OnErrorContinue {
+				continue
+			}
+			return fmt.Errorf("action %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
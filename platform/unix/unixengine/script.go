@@ -0,0 +1,219 @@
+package unixengine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/internal/mergereader"
+)
+
+// shellPath is the path of the POSIX shell that runs shell scripts.
+const shellPath = "/bin/sh"
+
+// scriptEngine manages invocation of a script action.
+type scriptEngine struct {
+	deployment lbdeploy.Deployment
+	system     System
+	flow       flowData
+	action     actionData
+	events     lbevent.Recorder
+}
+
+// InvokeShell runs the action's script with the POSIX shell.
+//
+// Inline scripts are written to a file in a temporary directory that is
+// removed after the script has run.
+func (engine *scriptEngine) InvokeShell(ctx context.Context) error {
+	script := engine.action.Definition.Script
+	if err := script.Validate(); err != nil {
+		return fmt.Errorf("the script is not valid: %w", err)
+	}
+
+	// Expand placeholders in the script arguments.
+	args, err := expandArgs(engine.system, engine.deployment, script.Args)
+	if err != nil {
+		return fmt.Errorf("the script arguments could not be prepared: %w", err)
+	}
+
+	// Determine the location of the script.
+	scriptPath, cleanup, err := engine.scriptPath()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Determine the working directory.
+	workingDir := filepath.Dir(scriptPath)
+	if dirID := script.WorkingDirectory; dirID != "" {
+		workingDir, err = localDirPath(engine.system, engine.deployment.Resources.FileSystem, dirID)
+		if err != nil {
+			return fmt.Errorf("a working directory could not be determined for the script: %w", err)
+		}
+	}
+
+	// Check for cancellation before starting the script.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Prepare a command that will be terminated when ctx is cancelled.
+	cmd := exec.CommandContext(ctx, shellPath, append([]string{scriptPath}, args...)...)
+	cmd.Dir = workingDir
+	cmd.Env, err = buildEnvironment(ctx, engine.system, engine.deployment, script.Environment)
+	if err != nil {
+		return err
+	}
+	setProcessGroup(cmd)
+
+	// Configure the command to wait up to one minute for the script to close
+	// out gracefully when its context is cancelled.
+	cmd.WaitDelay = time.Minute
+
+	// Prepare two sets of output pipes for the command.
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	// Record the start of the script.
+	engine.events.Record(lbdeployevent.ScriptStarted{
+		Deployment:           engine.deployment.ID,
+		Flow:                 engine.flow.ID,
+		ActionIndex:          engine.action.Index,
+		ActionType:           engine.action.Definition.Type,
+		ScriptFile:           script.File,
+		ScriptPath:           scriptPath,
+		CommandLine:          cmd.String(),
+		WorkingDirectoryPath: workingDir,
+	})
+
+	// Prepare a buffer to hold a limited amount of the combined output.
+	output := newOutputBuffer(script.Output)
+
+	// If requested, write the complete output to a file.
+	var outputPath string
+	if id := script.Output.File; id != "" {
+		file, err := createOutputFile(engine.system, engine.deployment.Resources.FileSystem, id)
+		if err != nil {
+			return fmt.Errorf("the output file for the script could not be created: %w", err)
+		}
+		defer file.Close()
+		output.Tee(file)
+		outputPath = file.Name()
+	}
+
+	// Record the time that the script started.
+	started := time.Now()
+
+	// Start the script.
+	err = cmd.Start()
+
+	// If the script started successfully, send its output to stdout and
+	// stderr as well as the output buffer, then wait for it to finish.
+	if err == nil {
+		r1 := io.TeeReader(stdout, os.Stdout)
+		r2 := io.TeeReader(stderr, os.Stderr)
+		io.Copy(output, mergereader.New(r1, r2))
+		err = cmd.Wait()
+	}
+
+	// Record the time that the script stopped.
+	stopped := time.Now()
+
+	// Analyze the exit code of the script.
+	result, err := buildCommandResult(err, script.ExitCodes)
+
+	// Record the end of the script.
+	engine.events.Record(lbdeployevent.ScriptStopped{
+		Deployment:           engine.deployment.ID,
+		Flow:                 engine.flow.ID,
+		ActionIndex:          engine.action.Index,
+		ActionType:           engine.action.Definition.Type,
+		ScriptFile:           script.File,
+		ScriptPath:           scriptPath,
+		CommandLine:          cmd.String(),
+		WorkingDirectoryPath: workingDir,
+		Result:               result,
+		Output:               output.String(),
+		OutputDiscarded:      output.Discarded(),
+		OutputPath:           outputPath,
+		Started:              started,
+		Stopped:              stopped,
+		Err:                  err,
+	})
+
+	return err
+}
+
+// scriptPath returns the path of the script to be run, along with a
+// function that removes any temporary files when the script is finished.
+func (engine *scriptEngine) scriptPath() (path string, cleanup func(), err error) {
+	script := engine.action.Definition.Script
+	if script.File == "" {
+		dir, err := os.MkdirTemp("", "leafbridge-script-")
+		if err != nil {
+			return "", nil, fmt.Errorf("a temporary directory could not be created for the script: %w", err)
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+		path = filepath.Join(dir, "script.sh")
+		if err := os.WriteFile(path, []byte(script.Inline), 0700); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("the inline script could not be written to disk: %w", err)
+		}
+		return path, cleanup, nil
+	}
+
+	path, err = localFilePath(engine.system, engine.deployment.Resources.FileSystem, script.File)
+	if err != nil {
+		return "", nil, fmt.Errorf("the script file \"%s\" could not be resolved: %w", script.File, err)
+	}
+
+	// Make sure the script satisfies any integrity requirements before it
+	// is run.
+	if err := engine.verifyIntegrity(script.File, path); err != nil {
+		return "", nil, err
+	}
+
+	return path, func() {}, nil
+}
+
+// verifyIntegrity checks the integrity requirements of the given script
+// file and records the result. Files without requirements are not checked.
+func (engine *scriptEngine) verifyIntegrity(fileID lbdeploy.FileResourceID, path string) error {
+	integrity := engine.deployment.Resources.FileSystem.Files[fileID].Integrity
+	if integrity.IsZero() {
+		return nil
+	}
+
+	actual, err := checkFileIntegrity(path, integrity)
+	if err != nil {
+		err = fmt.Errorf("the script file \"%s\" failed its integrity check: %w", fileID, err)
+	}
+
+	engine.events.Record(lbdeployevent.FileIntegrity{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		FileID:      fileID,
+		FilePath:    path,
+		Required:    integrity,
+		Actual:      actual,
+		Err:         err,
+	})
+
+	return err
+}
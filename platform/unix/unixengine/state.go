@@ -0,0 +1,23 @@
+package unixengine
+
+import (
+	"github.com/leafbridge/leafbridge/core/idset"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbpolicy"
+)
+
+// engineState keeps track of the overall state of a deployment.
+type engineState struct {
+	activeFlows     flowSet
+	holds           lbpolicy.HoldList
+	traceConditions bool
+}
+
+func newEngineState() *engineState {
+	return &engineState{
+		activeFlows: make(flowSet),
+	}
+}
+
+// flowSet keeps track of a set of flows.
+type flowSet = idset.SetOf[lbdeploy.FlowID]
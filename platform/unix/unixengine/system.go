@@ -0,0 +1,121 @@
+package unixengine
+
+import (
+	"context"
+	"os/exec"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+)
+
+// System provides the parts of the deployment engine that differ between
+// Unix-like operating systems.
+type System interface {
+	// Name returns the name of the operating system, such as "Linux". It
+	// is used to build error messages.
+	Name() string
+
+	// Platform returns the platform used to evaluate conditions and detect
+	// applications.
+	Platform() lbplatform.Platform
+
+	// Resolver returns a resolver for the given file system resources.
+	Resolver(resources lbdeploy.FileSystemResources) Resolver
+
+	// StateDir returns the directory that holds the persistent state of
+	// deployments in the given frequency scope.
+	StateDir(scope lbdeploy.FrequencyScope) (string, error)
+
+	// ConfigDir returns the directory that holds the machine's
+	// configuration, including its execution policy. Only administrators
+	// should be able to modify it.
+	ConfigDir() (string, error)
+
+	// PrepareCommand prepares a command with a type that is specific to
+	// the operating system. If there is nothing for the command to do, it
+	// returns a nil command.
+	//
+	// If the command type is not supported, it returns an error that
+	// satisfies errors.Is(err, errors.ErrUnsupported). If it returns an
+	// error, it releases anything that it acquired while preparing the
+	// command.
+	PrepareCommand(ctx context.Context, req CommandRequest) (*Command, error)
+}
+
+// Resolver resolves file system resources to references to local files and
+// directories.
+type Resolver interface {
+	ResolveDirectory(id lbdeploy.DirectoryResourceID) (lbdeploy.DirRef, error)
+	ResolveFile(id lbdeploy.FileResourceID) (lbdeploy.FileRef, error)
+}
+
+// Command is a command that has been prepared by a [System].
+//
+// Env holds environment variables of the form "name=value" that are
+// applied after the environment of the deployment and command.
+//
+// If Complete is not nil, it is called after the command has exited
+// successfully, before the expected application changes are verified. If
+// it returns an error, the command fails.
+//
+// If Cleanup is not nil, it is called after the command has finished,
+// whether or not it succeeded.
+type Command struct {
+	Cmd      *exec.Cmd
+	Env      []string
+	Complete func() error
+	Cleanup  func()
+}
+
+// CommandRequest describes a command that a [System] has been asked to
+// prepare. It gives the system access to the deployment engine that is
+// invoking the command.
+type CommandRequest struct {
+	engine *commandEngine
+}
+
+// Deployment returns the deployment that the command belongs to.
+func (req CommandRequest) Deployment() lbdeploy.Deployment {
+	return req.engine.deployment
+}
+
+// Definition returns the definition of the command.
+func (req CommandRequest) Definition() lbdeploy.Command {
+	return req.engine.command.Definition
+}
+
+// Apps returns the evaluation of the applications that the command
+// installs or uninstalls.
+func (req CommandRequest) Apps() lbdeploy.AppEvaluation {
+	return req.engine.apps
+}
+
+// Forced returns true if the command should run even when its
+// applications are already in the desired state.
+func (req CommandRequest) Forced() bool {
+	return req.engine.force || req.engine.action.Definition.Force
+}
+
+// Desc returns a string describing the command. It is used to build error
+// messages.
+func (req CommandRequest) Desc() string {
+	return req.engine.cmdDesc()
+}
+
+// LocalFilePath resolves a file resource and returns its absolute path.
+//
+// If the file could not be resolved or does not exist, it returns an error.
+func (req CommandRequest) LocalFilePath(id lbdeploy.FileResourceID) (string, error) {
+	return localFilePath(req.engine.system, req.engine.deployment.Resources.FileSystem, id)
+}
+
+// VerifyIntegrity checks the integrity requirements of the given file and
+// records the result. Files without requirements are not checked.
+func (req CommandRequest) VerifyIntegrity(id lbdeploy.FileResourceID, path string) error {
+	return req.engine.verifyIntegrity(id, path)
+}
+
+// ExpandArgs returns a copy of args with placeholders expanded.
+func (req CommandRequest) ExpandArgs(args []string) ([]string, error) {
+	return expandArgs(req.engine.system, req.engine.deployment, args)
+}
@@ -0,0 +1,115 @@
+package unixengine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+	"github.com/leafbridge/leafbridge/platform/unix/unixengine"
+)
+
+// testTimeout is a timeout that is exceeded by the commands in the tests.
+const testTimeout = 200 * time.Millisecond
+
+// timeoutDeployment returns a deployment with an "install" flow that runs
+// a command for far longer than testTimeout.
+func timeoutDeployment(flowTimeout, actionTimeout time.Duration) lbdeploy.Deployment {
+	return lbdeploy.Deployment{
+		ID: "contoso-app",
+		Resources: lbdeploy.Resources{
+			FileSystem: lbdeploy.FileSystemResources{
+				Files: lbdeploy.FileResourceMap{"script": {Path: "script.sh"}},
+			},
+		},
+		Commands: lbdeploy.CommandMap{
+			"run": {Executable: "script"},
+		},
+		Flows: lbdeploy.FlowMap{
+			"install": {
+				Timeout: datatype.Duration(flowTimeout),
+				Actions: []lbdeploy.Action{{Type: lbdeploy.ActionInvokeCommand, Command: "run", Timeout: datatype.Duration(actionTimeout)}},
+			},
+		},
+	}
+}
+
+func TestDeploymentTimeout(t *testing.T) {
+	dep := timeoutDeployment(0, 0)
+	system := newTestSystem(t, dep, lbtest.System{})
+	system.writeScript(t, "script.sh", "sleep 30")
+
+	var log eventLog
+	engine := unixengine.NewDeploymentEngine(system, dep, unixengine.Options{Events: lbevent.Recorder{Handler: &log}, Timeout: testTimeout})
+	started := time.Now()
+	err := engine.Invoke(context.Background(), "install")
+
+	if !errors.Is(err, unixengine.ErrDeploymentTimeout) {
+		t.Fatalf("got error %v, want %v", err, unixengine.ErrDeploymentTimeout)
+	}
+	if lberror.CategoryOf(err) != lberror.Timeout {
+		t.Errorf("got the %s category, want %s", lberror.CategoryOf(err), lberror.Timeout)
+	}
+	if elapsed := time.Since(started); elapsed > 10*time.Second {
+		t.Errorf("the command was not stopped until %s had passed", elapsed)
+	}
+	event, found := findEvent[lbdeployevent.DeploymentTimeout](&log)
+	if !found {
+		t.Fatal("the timeout was not recorded")
+	}
+	if event.Timeout != testTimeout || event.Flow != "install" {
+		t.Errorf("got a %s timeout for the \"%s\" flow, want a %s timeout for the \"install\" flow", event.Timeout, event.Flow, testTimeout)
+	}
+}
+
+func TestFlowTimeouts(t *testing.T) {
+	fixtures := []struct {
+		Name          string
+		FlowTimeout   time.Duration
+		ActionTimeout time.Duration
+		Action        int
+	}{
+		{Name: "flow", FlowTimeout: testTimeout},
+		{Name: "action", ActionTimeout: testTimeout, Action: 1},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			dep := timeoutDeployment(fixture.FlowTimeout, fixture.ActionTimeout)
+			system := newTestSystem(t, dep, lbtest.System{})
+			system.writeScript(t, "script.sh", "sleep 30")
+
+			var log eventLog
+			engine := unixengine.NewDeploymentEngine(system, dep, unixengine.Options{Events: lbevent.Recorder{Handler: &log}})
+			err := engine.Invoke(context.Background(), "install")
+
+			want := unixengine.TimeoutError{Flow: "install", Action: fixture.Action, Timeout: testTimeout}
+			var timeoutErr unixengine.TimeoutError
+			switch {
+			case !errors.As(err, &timeoutErr):
+				t.Fatalf("got error %v, want %v", err, want)
+			case timeoutErr != want:
+				t.Errorf("got timeout error %+v, want %+v", timeoutErr, want)
+			case lberror.CategoryOf(err) != lberror.Timeout:
+				t.Errorf("got the %s category, want %s", lberror.CategoryOf(err), lberror.Timeout)
+			}
+
+			stopped, found := findEvent[lbdeployevent.CommandStopped](&log)
+			if !found {
+				t.Fatal("the end of the command was not recorded")
+			}
+			if stopped.Timeout != testTimeout {
+				t.Errorf("got a recorded timeout of %s, want %s", stopped.Timeout, testTimeout)
+			}
+			if _, found := findEvent[lbdeployevent.DeploymentTimeout](&log); found {
+				t.Error("a deployment timeout was recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package authenticode verifies Authenticode signatures of files on Windows.
+package authenticode
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modcrypt32           = windows.NewLazySystemDLL("crypt32.dll")
+	procCryptMsgGetParam = modcrypt32.NewProc("CryptMsgGetParam")
+	procCryptMsgClose    = modcrypt32.NewProc("CryptMsgClose")
+)
+
+// cmsgSignerInfoParam is the CMSG_SIGNER_INFO_PARAM parameter type for
+// CryptMsgGetParam.
+const cmsgSignerInfoParam = 6
+
+// cmsgSignerInfo holds the leading fields of a CMSG_SIGNER_INFO structure.
+type cmsgSignerInfo struct {
+	Version      uint32
+	Issuer       windows.CertNameBlob
+	SerialNumber windows.CryptIntegerBlob
+}
+
+// Signer holds information about the signer of a file.
+type Signer struct {
+	// Subject is the simple display name of the signing certificate's
+	// subject.
+	Subject string
+
+	// Thumbprint is the SHA-1 thumbprint of the signing certificate in
+	// hexadecimal format.
+	Thumbprint string
+}
+
+// Verify checks the Authenticode signature embedded in the file at path. If
+// the file has a valid signature that chains to a trusted root, it returns
+// information about the signer. Otherwise it returns an error.
+//
+// Certificate revocation is not checked, because deployments are frequently
+// run on systems without network access.
+func Verify(path string) (Signer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Signer{}, err
+	}
+	defer file.Close()
+
+	return VerifyFile(file)
+}
+
+// VerifyFile checks the Authenticode signature embedded in file, in the same
+// manner as Verify.
+//
+// The signature is verified through the file's handle. The caller should
+// open the file in a way that prevents it from being modified, and keep it
+// open until the file has been used, so that the file that was verified is
+// the file that is used.
+func VerifyFile(file *os.File) (Signer, error) {
+	pathPtr, err := windows.UTF16PtrFromString(file.Name())
+	if err != nil {
+		return Signer{}, err
+	}
+
+	if err := verifyTrust(pathPtr, windows.Handle(file.Fd())); err != nil {
+		return Signer{}, fmt.Errorf("the file does not have a valid signature: %w", err)
+	}
+
+	signer, err := readSigner(pathPtr)
+	if err != nil {
+		return Signer{}, fmt.Errorf("the signer of the file could not be determined: %w", err)
+	}
+
+	return signer, nil
+}
+
+// verifyTrust calls WinVerifyTrust for the open file handle. The path is
+// used by the trust provider to determine the type of the file.
+func verifyTrust(path *uint16, handle windows.Handle) error {
+	fileInfo := windows.WinTrustFileInfo{
+		Size:     uint32(unsafe.Sizeof(windows.WinTrustFileInfo{})),
+		FilePath: path,
+		File:     handle,
+	}
+	data := windows.WinTrustData{
+		Size:                            uint32(unsafe.Sizeof(windows.WinTrustData{})),
+		UIChoice:                        windows.WTD_UI_NONE,
+		RevocationChecks:                windows.WTD_REVOKE_NONE,
+		UnionChoice:                     windows.WTD_CHOICE_FILE,
+		FileOrCatalogOrBlobOrSgnrOrCert: unsafe.Pointer(&fileInfo),
+		StateAction:                     windows.WTD_STATEACTION_VERIFY,
+	}
+
+	err := windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, &data)
+
+	// Always release the state data held by the trust provider.
+	data.StateAction = windows.WTD_STATEACTION_CLOSE
+	windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, &data)
+
+	return err
+}
+
+// readSigner finds the signing certificate embedded in the file at path.
+// The file must be held open by VerifyFile's caller, so that it cannot be
+// replaced after its signature has been verified.
+func readSigner(path *uint16) (Signer, error) {
+	var (
+		encoding uint32
+		store    windows.Handle
+		msg      windows.Handle
+	)
+	err := windows.CryptQueryObject(
+		windows.CERT_QUERY_OBJECT_FILE,
+		unsafe.Pointer(path),
+		windows.CERT_QUERY_CONTENT_FLAG_PKCS7_SIGNED_EMBED,
+		windows.CERT_QUERY_FORMAT_FLAG_BINARY,
+		0,
+		&encoding,
+		nil,
+		nil,
+		&store,
+		&msg,
+		nil)
+	if err != nil {
+		return Signer{}, err
+	}
+	defer windows.CertCloseStore(store, 0)
+	defer procCryptMsgClose.Call(uintptr(msg))
+
+	// Retrieve the signer information from the signed message.
+	var size uint32
+	if r, _, err := procCryptMsgGetParam.Call(uintptr(msg), cmsgSignerInfoParam, 0, 0, uintptr(unsafe.Pointer(&size))); r == 0 {
+		return Signer{}, err
+	}
+	buf := make([]byte, size)
+	if r, _, err := procCryptMsgGetParam.Call(uintptr(msg), cmsgSignerInfoParam, 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size))); r == 0 {
+		return Signer{}, err
+	}
+	info := (*cmsgSignerInfo)(unsafe.Pointer(&buf[0]))
+
+	// Find the signer's certificate within the message's certificate store.
+	certInfo := windows.CertInfo{
+		Issuer:       info.Issuer,
+		SerialNumber: info.SerialNumber,
+	}
+	cert, err := windows.CertFindCertificateInStore(store, encoding, 0, windows.CERT_FIND_SUBJECT_CERT, unsafe.Pointer(&certInfo), nil)
+	if err != nil {
+		return Signer{}, err
+	}
+	defer windows.CertFreeCertificateContext(cert)
+
+	// Determine the subject name of the certificate.
+	var subject string
+	if n := windows.CertGetNameString(cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, nil, 0); n > 1 {
+		name := make([]uint16, n)
+		windows.CertGetNameString(cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, &name[0], n)
+		subject = windows.UTF16ToString(name)
+	}
+
+	// Compute the certificate's thumbprint.
+	encoded := unsafe.Slice(cert.EncodedCert, cert.Length)
+	thumbprint := sha1.Sum(encoded)
+
+	return Signer{
+		Subject:    subject,
+		Thumbprint: hex.EncodeToString(thumbprint[:]),
+	}, nil
+}
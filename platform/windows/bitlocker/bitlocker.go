@@ -0,0 +1,160 @@
+// Package bitlocker queries and controls BitLocker drive encryption on the
+// local system.
+//
+// It works through the Win32_EncryptableVolume class of Windows Management
+// Instrumentation, which it reaches through Windows PowerShell. The
+// protection status and method results are reported as numbers, so the
+// output is not affected by the display language of the system.
+package bitlocker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// MaxRebootCount is the largest number of reboots that protection can be
+// suspended for.
+const MaxRebootCount = 15
+
+// ProtectionStatus is the protection status of a volume.
+type ProtectionStatus int
+
+// Protection status values, as defined by Win32_EncryptableVolume.
+const (
+	ProtectionOff     ProtectionStatus = 0
+	ProtectionOn      ProtectionStatus = 1
+	ProtectionUnknown ProtectionStatus = 2
+)
+
+// String returns a string representation of the status.
+func (s ProtectionStatus) String() string {
+	switch s {
+	case ProtectionOff:
+		return "off"
+	case ProtectionOn:
+		return "on"
+	case ProtectionUnknown:
+		return "unknown"
+	default:
+		return fmt.Sprintf("<unknown protection status %d>", int(s))
+	}
+}
+
+// notEncryptable is written by the scripts when the volume is not known to
+// BitLocker, or when BitLocker is not available on the system.
+const notEncryptable = "none"
+
+// volumeScript locates the encryptable volume for a drive letter and
+// stores it in $volume.
+const volumeScript = `$ErrorActionPreference = 'Stop'
+try {
+	$volume = Get-CimInstance -Namespace 'root/CIMV2/Security/MicrosoftVolumeEncryption' -ClassName 'Win32_EncryptableVolume' -Filter "DriveLetter='%s'"
+} catch [Microsoft.Management.Infrastructure.CimException] {
+	if ($_.Exception.NativeErrorCode -eq 'InvalidNamespace') { Write-Output 'none'; exit 0 }
+	throw
+}
+if ($null -eq $volume) { Write-Output 'none'; exit 0 }
+`
+
+// SystemDrive returns the drive letter of the volume that Windows is
+// installed on, such as "C:".
+func SystemDrive() (string, error) {
+	dir, err := windows.GetWindowsDirectory()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the Windows directory: %w", err)
+	}
+	return filepath.VolumeName(dir), nil
+}
+
+// Status returns the protection status of the given drive. If BitLocker is
+// not available for the drive, it returns ProtectionOff.
+func Status(ctx context.Context, drive string) (ProtectionStatus, error) {
+	output, err := run(ctx, drive, `Write-Output $volume.ProtectionStatus`)
+	if err != nil {
+		return ProtectionUnknown, err
+	}
+	if output == notEncryptable {
+		return ProtectionOff, nil
+	}
+	status, err := strconv.Atoi(output)
+	if err != nil {
+		return ProtectionUnknown, fmt.Errorf("unexpected protection status \"%s\"", output)
+	}
+	return ProtectionStatus(status), nil
+}
+
+// Suspend suspends protection of the given drive for the given number of
+// reboots, after which it resumes automatically. The encryption of the
+// drive is unaffected.
+func Suspend(ctx context.Context, drive string, reboots int) error {
+	if reboots < 1 || reboots > MaxRebootCount {
+		return fmt.Errorf("the reboot count must be between 1 and %d", MaxRebootCount)
+	}
+	return invoke(ctx, drive, fmt.Sprintf(`DisableKeyProtectors -Arguments @{RebootCount=[uint32]%d}`, reboots))
+}
+
+// Resume resumes protection of the given drive.
+func Resume(ctx context.Context, drive string) error {
+	return invoke(ctx, drive, `EnableKeyProtectors`)
+}
+
+// invoke calls a method of the encryptable volume for the given drive and
+// checks its result.
+func invoke(ctx context.Context, drive, method string) error {
+	output, err := run(ctx, drive, fmt.Sprintf(`$result = Invoke-CimMethod -InputObject $volume -MethodName %s
+Write-Output $result.ReturnValue`, method))
+	if err != nil {
+		return err
+	}
+	if output == notEncryptable {
+		return fmt.Errorf("BitLocker is not available for the %s drive", drive)
+	}
+	result, err := strconv.ParseUint(output, 10, 32)
+	if err != nil {
+		return fmt.Errorf("unexpected method result \"%s\"", output)
+	}
+	if result != 0 {
+		return fmt.Errorf("the BitLocker operation failed: %w", windows.Errno(result))
+	}
+	return nil
+}
+
+// run locates the encryptable volume for drive, then runs script against
+// it. It returns the trimmed output of the script.
+func run(ctx context.Context, drive, script string) (string, error) {
+	if len(drive) != 2 || drive[1] != ':' || !isLetter(drive[0]) {
+		return "", fmt.Errorf("\"%s\" is not a valid drive letter", drive)
+	}
+
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return "", err
+	}
+	powershell := filepath.Join(system, "WindowsPowerShell", "v1.0", "powershell.exe")
+
+	command := fmt.Sprintf(volumeScript, strings.ToUpper(drive)) + script
+	cmd := exec.CommandContext(ctx, powershell, "-NoLogo", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", command)
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", errors.New(message)
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,188 @@
+// Package browserpolicy applies machine policies to Chrome, Edge and
+// Firefox through the registry keys that each browser reads its group
+// policy from.
+//
+// JSON values are written to the registry in the form the browsers expect.
+// Booleans and integers are written as DWORD values and strings as string
+// values. Lists are written as subkeys holding values named 1, 2, 3 and so
+// on. Objects are written as subkeys, except for Chrome and Edge policies
+// that take a dictionary, which are written as JSON strings.
+package browserpolicy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"golang.org/x/sys/windows/registry"
+)
+
+// KeyPath returns the path of the registry key, relative to
+// HKEY_LOCAL_MACHINE, that holds the policies of the browser.
+func KeyPath(browser lbdeploy.Browser) (string, error) {
+	switch browser {
+	case lbdeploy.BrowserChrome:
+		return `SOFTWARE\Policies\Google\Chrome`, nil
+	case lbdeploy.BrowserEdge:
+		return `SOFTWARE\Policies\Microsoft\Edge`, nil
+	case lbdeploy.BrowserFirefox:
+		return `SOFTWARE\Policies\Mozilla\Firefox`, nil
+	default:
+		return "", fmt.Errorf("the \"%s\" browser is not supported", browser)
+	}
+}
+
+// SetPolicy applies the policy to its browser. If the value of the policy
+// is null, the policy is removed.
+func SetPolicy(policy lbdeploy.BrowserPolicy) error {
+	path, err := KeyPath(policy.Browser)
+	if err != nil {
+		return err
+	}
+	if policy.Removes() {
+		return removeEntry(path, policy.Name)
+	}
+	key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, path, registry.ALL_ACCESS)
+	if err != nil {
+		return fmt.Errorf("failed to open the \"%s\" registry key: %w", path, err)
+	}
+	defer key.Close()
+
+	return writeValue(key, path, policy.Name, policy.Value, policy.Browser.IsChromium())
+}
+
+// writeValue writes a JSON value to the registry as the named entry of
+// key, replacing any value or subkey with the same name. The path of key
+// is used to create subkeys. If objectsAsJSON is true, objects are
+// written as JSON strings instead of subkeys.
+func writeValue(key registry.Key, path, name string, raw json.RawMessage, objectsAsJSON bool) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("the value of \"%s\" is not valid JSON: %w", name, err)
+	}
+
+	// Clear the existing value or subkey, so that entries that are no
+	// longer present in lists or objects are not left behind.
+	if err := removeEntry(path, name); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case bool:
+		var dword uint32
+		if v {
+			dword = 1
+		}
+		return key.SetDWordValue(name, dword)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil || n < math.MinInt32 || n > math.MaxUint32 {
+			return fmt.Errorf("the value of \"%s\" is not an integer that fits in a DWORD", name)
+		}
+		return key.SetDWordValue(name, uint32(n))
+	case string:
+		return key.SetStringValue(name, v)
+	case []any:
+		subpath := path + `\` + name
+		subkey, _, err := registry.CreateKey(registry.LOCAL_MACHINE, subpath, registry.ALL_ACCESS)
+		if err != nil {
+			return fmt.Errorf("failed to create the \"%s\" registry key: %w", subpath, err)
+		}
+		defer subkey.Close()
+		for i, elem := range v {
+			data, err := json.Marshal(elem)
+			if err != nil {
+				return err
+			}
+			if err := writeValue(subkey, subpath, strconv.Itoa(i+1), data, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]any:
+		if objectsAsJSON {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			return key.SetStringValue(name, string(data))
+		}
+		subpath := path + `\` + name
+		subkey, _, err := registry.CreateKey(registry.LOCAL_MACHINE, subpath, registry.ALL_ACCESS)
+		if err != nil {
+			return fmt.Errorf("failed to create the \"%s\" registry key: %w", subpath, err)
+		}
+		defer subkey.Close()
+		for member, elem := range v {
+			data, err := json.Marshal(elem)
+			if err != nil {
+				return err
+			}
+			if err := writeValue(subkey, subpath, member, data, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("the value of \"%s\" has an unsupported type", name)
+	}
+}
+
+// removeEntry removes the named value and the named subkey of the key at
+// path, if they exist.
+func removeEntry(path, name string) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.SET_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to open the \"%s\" registry key: %w", path, err)
+	}
+	err = key.DeleteValue(name)
+	key.Close()
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to remove the \"%s\" value of the \"%s\" registry key: %w", name, path, err)
+	}
+	return deleteKeyTree(path + `\` + name)
+}
+
+// deleteKeyTree deletes the key at path and all of its subkeys. It
+// returns nil if the key does not exist.
+func deleteKeyTree(path string) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to open the \"%s\" registry key: %w", path, err)
+	}
+	names, err := key.ReadSubKeyNames(-1)
+	key.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read the subkeys of the \"%s\" registry key: %w", path, err)
+	}
+	for _, name := range names {
+		if err := deleteKeyTree(path + `\` + name); err != nil {
+			return err
+		}
+	}
+	if err := registry.DeleteKey(registry.LOCAL_MACHINE, path); err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to delete the \"%s\" registry key: %w", path, err)
+	}
+	return nil
+}
+
+// trimmedJoin joins lines of a multi-string registry value into a single
+// string.
+func trimmedJoin(lines []string) string {
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,206 @@
+package browserpolicy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// forceListKey is the name of the key beneath the policy key of Chrome
+	// and Edge that holds the extensions they force-install.
+	forceListKey = "ExtensionInstallForcelist"
+
+	// extensionSettingsValue is the name of the Firefox policy that holds
+	// the installation settings of its extensions, as JSON.
+	extensionSettingsValue = "ExtensionSettings"
+)
+
+// InstallExtension adds the extension to the force-install list of its
+// browser. If settings are provided for the extension, they replace any
+// managed preferences that were previously applied to it.
+func InstallExtension(ext lbdeploy.BrowserExtension) error {
+	path, err := KeyPath(ext.Browser)
+	if err != nil {
+		return err
+	}
+
+	if ext.Browser.IsChromium() {
+		err = updateForceList(path, ext.ID, ext.ID+";"+ext.EffectiveURL())
+	} else {
+		err = updateFirefoxSettings(path, ext.ID, map[string]string{
+			"installation_mode": "force_installed",
+			"install_url":       ext.EffectiveURL(),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(ext.Settings) == 0 {
+		return nil
+	}
+	settingsPath := extensionSettingsPath(path, ext)
+	if err := deleteKeyTree(settingsPath); err != nil {
+		return err
+	}
+	key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, settingsPath, registry.ALL_ACCESS)
+	if err != nil {
+		return fmt.Errorf("failed to create the \"%s\" registry key: %w", settingsPath, err)
+	}
+	defer key.Close()
+	for _, name := range slices.Sorted(maps.Keys(ext.Settings)) {
+		if err := writeValue(key, settingsPath, name, ext.Settings[name], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveExtension removes the extension from the force-install list of its
+// browser, along with any managed preferences applied to it.
+func RemoveExtension(ext lbdeploy.BrowserExtension) error {
+	path, err := KeyPath(ext.Browser)
+	if err != nil {
+		return err
+	}
+
+	if ext.Browser.IsChromium() {
+		err = updateForceList(path, ext.ID, "")
+	} else {
+		err = updateFirefoxSettings(path, ext.ID, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	return deleteKeyTree(extensionSettingsPath(path, ext))
+}
+
+// extensionSettingsPath returns the path of the registry key that holds
+// the managed preferences of the extension.
+func extensionSettingsPath(path string, ext lbdeploy.BrowserExtension) string {
+	if ext.Browser.IsChromium() {
+		return path + `\3rdparty\extensions\` + ext.ID + `\policy`
+	}
+	return path + `\3rdparty\Extensions\` + ext.ID
+}
+
+// updateForceList updates the force-install list of a Chromium browser
+// whose policies are kept at path. Any entries for the extension with the
+// given ID are replaced by entry. If entry is empty, they are removed.
+func updateForceList(path, id, entry string) error {
+	listPath := path + `\` + forceListKey
+	key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, listPath, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open the \"%s\" registry key: %w", listPath, err)
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(-1)
+	if err != nil {
+		return fmt.Errorf("failed to read the values of the \"%s\" registry key: %w", listPath, err)
+	}
+
+	// Find the entries for the extension, and the largest entry number.
+	var (
+		matches []string
+		last    int
+	)
+	for _, name := range names {
+		if n, err := strconv.Atoi(name); err == nil && n > last {
+			last = n
+		}
+		value, _, err := key.GetStringValue(name)
+		if err != nil {
+			continue
+		}
+		if existing, _, _ := strings.Cut(value, ";"); existing == id {
+			matches = append(matches, name)
+		}
+	}
+
+	// Keep the first matching entry in place, so that the order of the
+	// list is preserved when an extension is updated.
+	if entry != "" {
+		name := strconv.Itoa(last + 1)
+		if len(matches) > 0 {
+			name, matches = matches[0], matches[1:]
+		}
+		if err := key.SetStringValue(name, entry); err != nil {
+			return fmt.Errorf("failed to update the \"%s\" registry key: %w", listPath, err)
+		}
+	}
+	for _, name := range matches {
+		if err := key.DeleteValue(name); err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return fmt.Errorf("failed to update the \"%s\" registry key: %w", listPath, err)
+		}
+	}
+	return nil
+}
+
+// updateFirefoxSettings updates the installation settings of the Firefox
+// extension with the given ID. If settings is nil, the settings of the
+// extension are removed.
+func updateFirefoxSettings(path, id string, settings map[string]string) error {
+	key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open the \"%s\" registry key: %w", path, err)
+	}
+	defer key.Close()
+
+	// The policy can be stored as a single string or as multiple lines.
+	var existing string
+	lines, _, err := key.GetStringsValue(extensionSettingsValue)
+	switch {
+	case err == nil:
+		existing = trimmedJoin(lines)
+	case errors.Is(err, registry.ErrUnexpectedType):
+		if existing, _, err = key.GetStringValue(extensionSettingsValue); err != nil {
+			return fmt.Errorf("failed to read the %s policy: %w", extensionSettingsValue, err)
+		}
+	case !errors.Is(err, registry.ErrNotExist):
+		return fmt.Errorf("failed to read the %s policy: %w", extensionSettingsValue, err)
+	}
+
+	all := make(map[string]json.RawMessage)
+	if existing != "" {
+		if err := json.Unmarshal([]byte(existing), &all); err != nil {
+			return fmt.Errorf("the existing %s policy is not valid JSON: %w", extensionSettingsValue, err)
+		}
+	}
+	if settings == nil {
+		if _, found := all[id]; !found {
+			return nil
+		}
+		delete(all, id)
+	} else {
+		data, err := json.Marshal(settings)
+		if err != nil {
+			return err
+		}
+		all[id] = data
+	}
+
+	if len(all) == 0 {
+		if err := key.DeleteValue(extensionSettingsValue); err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return fmt.Errorf("failed to remove the %s policy: %w", extensionSettingsValue, err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := key.SetStringsValue(extensionSettingsValue, strings.Split(string(data), "\n")); err != nil {
+		return fmt.Errorf("failed to write the %s policy: %w", extensionSettingsValue, err)
+	}
+	return nil
+}
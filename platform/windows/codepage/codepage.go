@@ -0,0 +1,48 @@
+// Package codepage converts text encoded with Windows code pages to UTF-8.
+package codepage
+
+import (
+	"errors"
+	"math"
+	"unicode/utf16"
+
+	"golang.org/x/sys/windows"
+)
+
+// CodePage identifies a Windows code page.
+type CodePage uint32
+
+// Code pages that are determined by the system's locale settings.
+const (
+	ANSI CodePage = 0 // CP_ACP
+	OEM  CodePage = 1 // CP_OEMCP
+)
+
+// Decode interprets p as text encoded with the given code page and returns
+// it as a UTF-8 string.
+func Decode(p []byte, cp CodePage) (string, error) {
+	if len(p) == 0 {
+		return "", nil
+	}
+	if len(p) > math.MaxInt32 {
+		return "", errors.New("the text is too large to be converted")
+	}
+
+	// Determine the length of the converted text.
+	n, err := windows.MultiByteToWideChar(uint32(cp), 0, &p[0], int32(len(p)), nil, 0)
+	if err != nil {
+		return "", err
+	}
+	if n <= 0 {
+		return "", nil
+	}
+
+	// Perform the conversion.
+	buf := make([]uint16, n)
+	n, err = windows.MultiByteToWideChar(uint32(cp), 0, &p[0], int32(len(p)), &buf[0], n)
+	if err != nil {
+		return "", err
+	}
+
+	return string(utf16.Decode(buf[:n])), nil
+}
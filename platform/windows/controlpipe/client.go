@@ -0,0 +1,45 @@
+package controlpipe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// Send connects to the control pipe with the given name, sends a request
+// with the given verb and waits for its response. If name is empty,
+// DefaultName is used.
+func Send(name string, verb Verb) error {
+	if name == "" {
+		name = DefaultName
+	}
+
+	name16, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	pipe, err := windows.CreateFile(name16, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the \"%s\" control pipe: %w", name, err)
+	}
+	defer windows.CloseHandle(pipe)
+
+	if err := writeJSON(pipe, Request{Verb: verb}); err != nil {
+		return fmt.Errorf("failed to send the request: %w", err)
+	}
+
+	data, err := readLine(pipe)
+	if err != nil {
+		return fmt.Errorf("failed to read the response: %w", err)
+	}
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("the response could not be parsed: %w", err)
+	}
+	if !response.OK {
+		return errors.New(response.Error)
+	}
+	return nil
+}
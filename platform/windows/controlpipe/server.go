@@ -0,0 +1,244 @@
+// Package controlpipe lets other processes control a running LeafBridge
+// deployment through a named pipe.
+//
+// A client connects to the pipe, writes a single request and reads a
+// single response. Requests and responses are JSON documents terminated by
+// a newline. The only verb that is currently supported is cancel, which
+// cancels the deployment:
+//
+//	{"verb":"cancel"}
+//	{"ok":true}
+//
+// Cancelling a deployment stops it promptly. Downloads, extractions and
+// running commands are abandoned, and the resources that the deployment
+// holds are released before it exits.
+//
+// Its security descriptor only grants access to SYSTEM and to
+// administrators, because requests can stop a deployment.
+package controlpipe
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"golang.org/x/sys/windows"
+)
+
+// DefaultName is the default name of the control pipe.
+const DefaultName = `\\.\pipe\LeafBridge\Control`
+
+// RunName returns a pipe name that is unique to the given run of
+// LeafBridge.
+func RunName(run lbevent.RunID) string {
+	return DefaultName + `\` + string(run)
+}
+
+// securityDescriptor grants full control of the pipe to SYSTEM and to
+// administrators.
+const securityDescriptor = "D:P(A;;GA;;;SY)(A;;GA;;;BA)"
+
+// bufferSize is the size of the pipe's buffers, and the largest request
+// that is accepted.
+const bufferSize = 4096
+
+// Verb identifies an operation requested through the control pipe.
+type Verb string
+
+// Supported verbs.
+const (
+	VerbCancel Verb = "cancel"
+)
+
+// Request is a request sent to the control pipe.
+type Request struct {
+	Verb Verb `json:"verb"`
+}
+
+// Response is the response to a request.
+type Response struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Server is a named pipe server that accepts control requests for a
+// deployment.
+type Server struct {
+	name   string
+	sa     windows.SecurityAttributes
+	cancel func()
+
+	mutex  sync.Mutex
+	closed bool
+	done   chan struct{}
+}
+
+// Listen creates a named pipe with the given name and starts accepting
+// requests. When a cancel request is received, cancel is called. If name
+// is empty, DefaultName is used.
+//
+// An error is returned if the pipe already exists, which prevents another
+// process from intercepting requests.
+func Listen(name string, cancel func()) (*Server, error) {
+	if name == "" {
+		name = DefaultName
+	}
+
+	sd, err := windows.SecurityDescriptorFromString(securityDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare a security descriptor for the control pipe: %w", err)
+	}
+
+	s := &Server{
+		name:   name,
+		sa:     windows.SecurityAttributes{SecurityDescriptor: sd},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	s.sa.Length = uint32(unsafe.Sizeof(s.sa))
+
+	pipe, err := s.create(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the \"%s\" control pipe: %w", name, err)
+	}
+
+	go s.accept(pipe)
+
+	return s, nil
+}
+
+// Name returns the name of the pipe.
+func (s *Server) Name() string {
+	return s.name
+}
+
+// Close stops accepting requests.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mutex.Unlock()
+
+	// Wake the accept loop by connecting to the pipe.
+	if name, err := windows.UTF16PtrFromString(s.name); err == nil {
+		if h, err := windows.CreateFile(name, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil, windows.OPEN_EXISTING, 0, 0); err == nil {
+			windows.CloseHandle(h)
+		}
+	}
+	<-s.done
+
+	return nil
+}
+
+// create creates a new instance of the pipe.
+func (s *Server) create(first bool) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(s.name)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	mode := uint32(windows.PIPE_TYPE_BYTE | windows.PIPE_READMODE_BYTE | windows.PIPE_WAIT | windows.PIPE_REJECT_REMOTE_CLIENTS)
+
+	return windows.CreateNamedPipe(name, flags, mode, 1, bufferSize, bufferSize, 0, &s.sa)
+}
+
+// accept serves clients one at a time until the server is closed.
+func (s *Server) accept(pipe windows.Handle) {
+	defer close(s.done)
+	defer windows.CloseHandle(pipe)
+
+	for {
+		err := windows.ConnectNamedPipe(pipe, nil)
+		if err != nil && !errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+			return
+		}
+
+		s.mutex.Lock()
+		closed := s.closed
+		s.mutex.Unlock()
+		if closed {
+			windows.DisconnectNamedPipe(pipe)
+			return
+		}
+
+		s.serve(pipe)
+		windows.FlushFileBuffers(pipe)
+		windows.DisconnectNamedPipe(pipe)
+	}
+}
+
+// serve reads a single request from a connected client and writes its
+// response.
+func (s *Server) serve(pipe windows.Handle) {
+	data, err := readLine(pipe)
+	if err != nil {
+		return
+	}
+
+	var response Response
+	var request Request
+	if err := json.Unmarshal(data, &request); err != nil {
+		response.Error = fmt.Sprintf("the request could not be parsed: %v", err)
+	} else {
+		switch request.Verb {
+		case VerbCancel:
+			s.cancel()
+			response.OK = true
+		default:
+			response.Error = fmt.Sprintf("the \"%s\" verb is not recognized", request.Verb)
+		}
+	}
+
+	writeJSON(pipe, response)
+}
+
+// readLine reads from the pipe until it reads a newline, and returns the
+// data that preceded it.
+func readLine(pipe windows.Handle) ([]byte, error) {
+	var (
+		data []byte
+		buf  [bufferSize]byte
+	)
+	for {
+		var n uint32
+		err := windows.ReadFile(pipe, buf[:], &n, nil)
+		data = append(data, buf[:n]...)
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			return data[:i], nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 || len(data) > bufferSize {
+			return nil, errors.New("the message is incomplete or too large")
+		}
+	}
+}
+
+// writeJSON writes v to the pipe as JSON, followed by a newline.
+func writeJSON(pipe windows.Handle, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	for len(data) > 0 {
+		var n uint32
+		if err := windows.WriteFile(pipe, data, &n, nil); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
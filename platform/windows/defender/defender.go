@@ -0,0 +1,95 @@
+// Package defender manages path exclusions for Microsoft Defender Antivirus
+// on the local system.
+//
+// It works through the Defender PowerShell cmdlets, which it reaches through
+// Windows PowerShell. The cmdlets are not available when Defender has been
+// replaced by another antivirus product, in which case the functions in
+// this package return [ErrUnavailable].
+package defender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// ErrUnavailable is returned when Microsoft Defender Antivirus cannot be
+// managed on the local system.
+var ErrUnavailable = errors.New("microsoft defender antivirus is not available")
+
+// unavailable is written by the scripts when the Defender cmdlets are not
+// available.
+const unavailable = "unavailable"
+
+// preferenceScript verifies that the Defender cmdlets are available.
+const preferenceScript = `$ErrorActionPreference = 'Stop'
+if ($null -eq (Get-Command -Name 'Get-MpPreference' -ErrorAction SilentlyContinue)) { Write-Output 'unavailable'; exit 0 }
+`
+
+// PathExcluded returns true if path is already excluded from real-time
+// scanning. Paths are compared without regard to case.
+func PathExcluded(ctx context.Context, path string) (bool, error) {
+	output, err := run(ctx, `(Get-MpPreference).ExclusionPath | ForEach-Object { Write-Output $_ }`)
+	if err != nil {
+		return false, err
+	}
+	path = filepath.Clean(path)
+	for line := range strings.Lines(output) {
+		if strings.EqualFold(filepath.Clean(strings.TrimSpace(line)), path) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AddPathExclusion excludes path from real-time scanning.
+func AddPathExclusion(ctx context.Context, path string) error {
+	_, err := run(ctx, fmt.Sprintf(`Add-MpPreference -ExclusionPath %s`, quote(path)))
+	return err
+}
+
+// RemovePathExclusion removes a path exclusion that was previously added
+// by AddPathExclusion.
+func RemovePathExclusion(ctx context.Context, path string) error {
+	_, err := run(ctx, fmt.Sprintf(`Remove-MpPreference -ExclusionPath %s`, quote(path)))
+	return err
+}
+
+// run verifies that the Defender cmdlets are available, then runs script.
+// It returns the trimmed output of the script.
+func run(ctx context.Context, script string) (string, error) {
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return "", err
+	}
+	powershell := filepath.Join(system, "WindowsPowerShell", "v1.0", "powershell.exe")
+
+	cmd := exec.CommandContext(ctx, powershell, "-NoLogo", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", preferenceScript+script)
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", errors.New(message)
+		}
+		return "", err
+	}
+
+	result := strings.TrimSpace(string(output))
+	if result == unavailable {
+		return "", ErrUnavailable
+	}
+
+	return result, nil
+}
+
+// quote returns s as a single-quoted PowerShell string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,27 @@
+// Package diskspace reports the free space of Windows volumes.
+package diskspace
+
+import (
+	"math"
+
+	"golang.org/x/sys/windows"
+)
+
+// Available returns the number of bytes that are available to the current
+// user on the volume that holds the given directory.
+//
+// The directory must exist. Disk quotas that apply to the current user are
+// taken into account.
+func Available(dir string) (int64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, &total, &free); err != nil {
+		return 0, err
+	}
+
+	return int64(min(available, math.MaxInt64)), nil
+}
@@ -0,0 +1,32 @@
+// Package dpapi decrypts secrets that were protected with the Windows Data
+// Protection API.
+package dpapi
+
+import (
+	"errors"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Decrypt decrypts data that was encrypted by CryptProtectData. The data
+// must have been encrypted in a scope that is accessible to the current
+// user, such as the local machine scope.
+func Decrypt(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no data was provided for decryption")
+	}
+
+	in := windows.DataBlob{
+		Size: uint32(len(data)),
+		Data: &data[0],
+	}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	// Copy the decrypted data out of the buffer allocated by the system.
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
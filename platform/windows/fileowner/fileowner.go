@@ -0,0 +1,181 @@
+// Package fileowner changes the ownership of files that are protected by
+// the operating system, such as those owned by TrustedInstaller.
+package fileowner
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileAllAccess is the FILE_ALL_ACCESS access mask.
+const fileAllAccess = 0x1F01FF
+
+// CanModify returns true if the current process is able to replace or
+// delete the file at path without changing its security descriptor.
+//
+// A file that is merely in use by another process is considered to be
+// modifiable.
+func CanModify(path string) (bool, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false, err
+	}
+
+	handle, err := windows.CreateFile(name,
+		windows.DELETE|windows.FILE_WRITE_DATA,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, 0, 0)
+	switch {
+	case err == nil:
+		windows.CloseHandle(handle)
+		return true, nil
+	case errors.Is(err, windows.ERROR_SHARING_VIOLATION):
+		return true, nil
+	case errors.Is(err, windows.ERROR_ACCESS_DENIED):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Owner returns the name of the account that owns the file at path. If the
+// account name can't be determined, the security identifier of the owner
+// is returned instead.
+func Owner(path string) (string, error) {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION)
+	if err != nil {
+		return "", err
+	}
+	owner, _, err := sd.Owner()
+	if err != nil {
+		return "", err
+	}
+	if owner == nil {
+		return "", nil
+	}
+	return accountName(owner), nil
+}
+
+// Ownership records the owner and access control list that a file had
+// before ownership of it was taken by Take.
+type Ownership struct {
+	path     string
+	previous *windows.SECURITY_DESCRIPTOR
+}
+
+// Previous returns the name of the account that owned the file before
+// ownership of it was taken. If the account name can't be determined, the
+// security identifier of the owner is returned instead.
+func (o *Ownership) Previous() string {
+	if o == nil {
+		return ""
+	}
+	owner, _, err := o.previous.Owner()
+	if err != nil || owner == nil {
+		return ""
+	}
+	return accountName(owner)
+}
+
+// Restore makes the previous owner the owner of the file again, and
+// restores the access control list that the file had before ownership of
+// it was taken. If the file has been replaced, the previous owner and
+// access control list are applied to the replacement. If the file no
+// longer exists, it does nothing.
+//
+// Making another account the owner of a file requires the restore
+// privilege to be enabled.
+func (o *Ownership) Restore() error {
+	owner, _, err := o.previous.Owner()
+	if err != nil {
+		return err
+	}
+	dacl, _, err := o.previous.DACL()
+	if err != nil {
+		return err
+	}
+	control, _, err := o.previous.Control()
+	if err != nil {
+		return err
+	}
+
+	info := windows.SECURITY_INFORMATION(windows.OWNER_SECURITY_INFORMATION | windows.DACL_SECURITY_INFORMATION)
+	if control&windows.SE_DACL_PROTECTED != 0 {
+		info |= windows.PROTECTED_DACL_SECURITY_INFORMATION
+	} else {
+		info |= windows.UNPROTECTED_DACL_SECURITY_INFORMATION
+	}
+
+	err = windows.SetNamedSecurityInfo(o.path, windows.SE_FILE_OBJECT, info, owner, nil, dacl, nil)
+	if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to restore the owner of the file: %w", err)
+	}
+	return nil
+}
+
+// Take makes the local Administrators group the owner of the file at path
+// and grants the group full control of the file. It returns the previous
+// ownership of the file, which should be restored once the file has been
+// modified.
+//
+// Taking ownership of a file that the current account has no access to
+// requires the take-ownership privilege to be enabled.
+func Take(path string) (*Ownership, error) {
+	previous, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION|windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the owner of the file: %w", err)
+	}
+	ownership := &Ownership{path: path, previous: previous}
+
+	admins, err := windows.CreateWellKnownSid(windows.WinBuiltinAdministratorsSid)
+	if err != nil {
+		return ownership, err
+	}
+
+	// Change the owner of the file.
+	if err := windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION, admins, nil, nil, nil); err != nil {
+		return ownership, fmt.Errorf("unable to change the owner of the file: %w", err)
+	}
+
+	// As the owner, grant full control of the file to administrators while
+	// keeping the rest of its access control list intact.
+	dacl, _, err := previous.DACL()
+	if err != nil {
+		return ownership, fmt.Errorf("unable to read the access control list of the file: %w", err)
+	}
+	dacl, err = windows.ACLFromEntries([]windows.EXPLICIT_ACCESS{{
+		AccessPermissions: fileAllAccess,
+		AccessMode:        windows.GRANT_ACCESS,
+		Inheritance:       windows.NO_INHERITANCE,
+		Trustee: windows.TRUSTEE{
+			TrusteeForm:  windows.TRUSTEE_IS_SID,
+			TrusteeType:  windows.TRUSTEE_IS_WELL_KNOWN_GROUP,
+			TrusteeValue: windows.TrusteeValueFromSID(admins),
+		},
+	}}, dacl)
+	if err != nil {
+		return ownership, err
+	}
+	if err := windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION, nil, nil, dacl, nil); err != nil {
+		return ownership, fmt.Errorf("unable to grant administrators access to the file: %w", err)
+	}
+
+	return ownership, nil
+}
+
+// accountName returns the qualified name of the account identified by sid.
+func accountName(sid *windows.SID) string {
+	account, domain, _, err := sid.LookupAccount("")
+	if err != nil {
+		return sid.String()
+	}
+	if domain == "" {
+		return account
+	}
+	return domain + `\` + account
+}
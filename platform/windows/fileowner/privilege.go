@@ -0,0 +1,131 @@
+package fileowner
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modadvapi32               = windows.NewLazySystemDLL("advapi32.dll")
+	procAdjustTokenPrivileges = modadvapi32.NewProc("AdjustTokenPrivileges")
+)
+
+// Privilege is the name of a Windows privilege.
+type Privilege string
+
+// Privileges that allow files to be modified regardless of their
+// security descriptors.
+const (
+	BackupPrivilege        Privilege = "SeBackupPrivilege"
+	RestorePrivilege       Privilege = "SeRestorePrivilege"
+	TakeOwnershipPrivilege Privilege = "SeTakeOwnershipPrivilege"
+)
+
+// Privileges is a set of privileges that have been enabled for the calling
+// goroutine by EnablePrivileges. They remain enabled until Restore is
+// called.
+type Privileges struct {
+	token windows.Token
+}
+
+// EnablePrivileges enables the given privileges for the calling goroutine.
+// The privileges must be held by the account the process runs as, which
+// is typically the case for administrators and the local system account.
+//
+// The privileges are enabled in an impersonation token for the current
+// thread, which is a copy of the process token. The goroutine is locked to
+// the thread until Restore is called, so the privileges don't apply to any
+// other goroutine. Restore must be called from the same goroutine.
+func EnablePrivileges(privileges ...Privilege) (*Privileges, error) {
+	runtime.LockOSThread()
+
+	if err := windows.ImpersonateSelf(windows.SecurityImpersonation); err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("unable to impersonate the process token: %w", err)
+	}
+
+	var token windows.Token
+	if err := windows.OpenThreadToken(windows.CurrentThread(), windows.TOKEN_ADJUST_PRIVILEGES|windows.TOKEN_QUERY, true, &token); err != nil {
+		windows.RevertToSelf()
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("unable to open the thread token: %w", err)
+	}
+
+	enabled := &Privileges{token: token}
+	for _, privilege := range privileges {
+		if err := enablePrivilege(token, privilege); err != nil {
+			enabled.Restore()
+			return nil, fmt.Errorf("unable to enable %s: %w", privilege, err)
+		}
+	}
+
+	return enabled, nil
+}
+
+// Restore discards the thread's impersonation token, which returns the
+// thread to the privileges of the process, and unlocks the goroutine from
+// the thread.
+func (p *Privileges) Restore() error {
+	if p.token == 0 {
+		return nil
+	}
+
+	first := p.token.Close()
+	p.token = 0
+
+	// If the thread can't stop impersonating, it must not be used by other
+	// goroutines. Leaving it locked causes it to exit with the goroutine.
+	if err := windows.RevertToSelf(); err != nil {
+		if first == nil {
+			first = err
+		}
+		return first
+	}
+	runtime.UnlockOSThread()
+
+	return first
+}
+
+// enablePrivilege enables a single privilege for token.
+func enablePrivilege(token windows.Token, privilege Privilege) error {
+	name, err := windows.UTF16PtrFromString(string(privilege))
+	if err != nil {
+		return err
+	}
+
+	var luid windows.LUID
+	if err := windows.LookupPrivilegeValue(nil, name, &luid); err != nil {
+		return err
+	}
+
+	state := windows.Tokenprivileges{PrivilegeCount: 1}
+	state.Privileges[0] = windows.LUIDAndAttributes{
+		Luid:       luid,
+		Attributes: windows.SE_PRIVILEGE_ENABLED,
+	}
+	return adjustTokenPrivileges(token, &state)
+}
+
+// adjustTokenPrivileges calls AdjustTokenPrivileges. Unlike the wrapper in
+// the windows package, it reports privileges that are not held by the
+// token, which the function otherwise treats as a success.
+func adjustTokenPrivileges(token windows.Token, state *windows.Tokenprivileges) error {
+	r1, _, e1 := procAdjustTokenPrivileges.Call(
+		uintptr(token),
+		0,
+		uintptr(unsafe.Pointer(state)),
+		0,
+		0,
+		0)
+	if r1 == 0 {
+		return e1
+	}
+	if e1 == windows.ERROR_NOT_ALL_ASSIGNED {
+		return errors.New("the privilege is not held by the current account")
+	}
+	return nil
+}
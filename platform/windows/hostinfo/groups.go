@@ -0,0 +1,146 @@
+package hostinfo
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+var (
+	modnetapi32          = windows.NewLazySystemDLL("netapi32.dll")
+	procDsGetDcNameW     = modnetapi32.NewProc("DsGetDcNameW")
+	procNetUserGetGroups = modnetapi32.NewProc("NetUserGetGroups")
+)
+
+// Network management constants.
+const (
+	dsDirectoryServiceRequired = 0x00000010
+	maxPreferredLength         = 0xFFFFFFFF
+	nerrSuccess                = 0
+)
+
+// tagsKeyPath is the registry key that holds the tags of the computer.
+const tagsKeyPath = `SOFTWARE\LeafBridge`
+
+// domainControllerInfo is the leading portion of the
+// DOMAIN_CONTROLLER_INFOW structure returned by DsGetDcName.
+type domainControllerInfo struct {
+	domainControllerName *uint16
+}
+
+// groupUsersInfo0 is the GROUP_USERS_INFO_0 structure returned by
+// NetUserGetGroups.
+type groupUsersInfo0 struct {
+	name *uint16
+}
+
+// ComputerName returns the NetBIOS name of the computer.
+func ComputerName() (string, error) {
+	n := uint32(64)
+	for {
+		buf := make([]uint16, n)
+		err := windows.GetComputerNameEx(windows.ComputerNameNetBIOS, &buf[0], &n)
+		if err == windows.ERROR_MORE_DATA {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return windows.UTF16ToString(buf[:n]), nil
+	}
+}
+
+// ComputerGroups returns the names of the directory groups that the
+// computer's account is a direct member of. It queries a domain controller
+// of the domain that the computer belongs to.
+//
+// It returns an empty list if the computer is not joined to a domain.
+func ComputerGroups() ([]string, error) {
+	if domain() == "" {
+		return nil, nil
+	}
+	name, err := ComputerName()
+	if err != nil {
+		return nil, err
+	}
+
+	// Locate a domain controller.
+	if err := procDsGetDcNameW.Find(); err != nil {
+		return nil, err
+	}
+	var dc *domainControllerInfo
+	r1, _, _ := procDsGetDcNameW.Call(0, 0, 0, 0, dsDirectoryServiceRequired, uintptr(unsafe.Pointer(&dc)))
+	if r1 != 0 {
+		return nil, fmt.Errorf("failed to locate a domain controller: %w", windows.Errno(r1))
+	}
+	defer windows.NetApiBufferFree((*byte)(unsafe.Pointer(dc)))
+
+	// Ask the domain controller for the groups of the computer account,
+	// which is named after the computer with a trailing dollar sign.
+	account, err := windows.UTF16PtrFromString(name + "$")
+	if err != nil {
+		return nil, err
+	}
+	if err := procNetUserGetGroups.Find(); err != nil {
+		return nil, err
+	}
+	var (
+		buf          *byte
+		entriesRead  uint32
+		totalEntries uint32
+	)
+	r1, _, _ = procNetUserGetGroups.Call(
+		uintptr(unsafe.Pointer(dc.domainControllerName)),
+		uintptr(unsafe.Pointer(account)),
+		0,
+		uintptr(unsafe.Pointer(&buf)),
+		maxPreferredLength,
+		uintptr(unsafe.Pointer(&entriesRead)),
+		uintptr(unsafe.Pointer(&totalEntries)))
+	if r1 != nerrSuccess {
+		return nil, fmt.Errorf("failed to retrieve the groups of the \"%s\" computer account: %w", name, windows.Errno(r1))
+	}
+	defer windows.NetApiBufferFree(buf)
+
+	entries := unsafe.Slice((*groupUsersInfo0)(unsafe.Pointer(buf)), entriesRead)
+	groups := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		groups = append(groups, windows.UTF16PtrToString(entry.name))
+	}
+	return groups, nil
+}
+
+// Tags returns the tags that have been assigned to the computer by an
+// administrator. Tags are kept in the "Tags" multi-string value of the
+// HKLM\SOFTWARE\LeafBridge registry key.
+//
+// It returns an empty list if no tags have been assigned.
+func Tags() ([]string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, tagsKeyPath, registry.QUERY_VALUE)
+	if err == registry.ErrNotExist {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	values, _, err := key.GetStringsValue("Tags")
+	if err == registry.ErrNotExist {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			tags = append(tags, value)
+		}
+	}
+	return tags, nil
+}
@@ -0,0 +1,100 @@
+// Package hostinfo collects information about the Windows host and session
+// that LeafBridge is running in.
+package hostinfo
+
+import (
+	"debug/pe"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"golang.org/x/sys/windows"
+)
+
+// Session types.
+const (
+	SessionService = "service"
+	SessionConsole = "console"
+	SessionRemote  = "remote"
+)
+
+// Collect returns information about the current host and session.
+//
+// Collection is best-effort. Any information that cannot be determined is
+// left empty.
+func Collect() lbevent.Host {
+	return lbevent.Host{
+		Domain:       domain(),
+		OSBuild:      osBuild(),
+		Architecture: Architecture(),
+		User:         userName(),
+		Session:      sessionType(),
+	}
+}
+
+// domain returns the DNS domain that the computer belongs to.
+func domain() string {
+	n := uint32(256)
+	for {
+		buf := make([]uint16, n)
+		err := windows.GetComputerNameEx(windows.ComputerNameDnsDomain, &buf[0], &n)
+		if err == windows.ERROR_MORE_DATA {
+			continue
+		}
+		if err != nil {
+			return ""
+		}
+		return windows.UTF16ToString(buf[:n])
+	}
+}
+
+// osBuild returns the version and build number of the operating system.
+func osBuild() string {
+	info := windows.RtlGetVersion()
+	return fmt.Sprintf("%d.%d.%d", info.MajorVersion, info.MinorVersion, info.BuildNumber)
+}
+
+// Architecture returns the native architecture of the operating system,
+// which might differ from the architecture of the running process. It is
+// expressed in the same form as runtime.GOARCH.
+func Architecture() string {
+	var processMachine, nativeMachine uint16
+	if err := windows.IsWow64Process2(windows.CurrentProcess(), &processMachine, &nativeMachine); err == nil {
+		switch nativeMachine {
+		case pe.IMAGE_FILE_MACHINE_AMD64:
+			return "amd64"
+		case pe.IMAGE_FILE_MACHINE_ARM64:
+			return "arm64"
+		case pe.IMAGE_FILE_MACHINE_I386:
+			return "386"
+		}
+	}
+	return runtime.GOARCH
+}
+
+// userName returns the name of the user that LeafBridge is running as.
+func userName() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
+// sessionType returns the type of session that LeafBridge is running in.
+func sessionType() string {
+	var session uint32
+	if err := windows.ProcessIdToSessionId(windows.GetCurrentProcessId(), &session); err != nil {
+		return ""
+	}
+	if session == 0 {
+		return SessionService
+	}
+	if strings.HasPrefix(strings.ToUpper(os.Getenv("SESSIONNAME")), "RDP-") {
+		return SessionRemote
+	}
+	return SessionConsole
+}
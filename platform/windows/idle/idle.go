@@ -0,0 +1,204 @@
+// Package idle determines whether the users of a Windows machine are idle.
+package idle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modkernel32                      = windows.NewLazySystemDLL("kernel32.dll")
+	moduser32                        = windows.NewLazySystemDLL("user32.dll")
+	modshell32                       = windows.NewLazySystemDLL("shell32.dll")
+	modwtsapi32                      = windows.NewLazySystemDLL("wtsapi32.dll")
+	procGetTickCount64               = modkernel32.NewProc("GetTickCount64")
+	procGetLastInputInfo             = moduser32.NewProc("GetLastInputInfo")
+	procSHQueryUserNotificationState = modshell32.NewProc("SHQueryUserNotificationState")
+	procWTSEnumerateSessionsW        = modwtsapi32.NewProc("WTSEnumerateSessionsW")
+	procWTSQuerySessionInformationW  = modwtsapi32.NewProc("WTSQuerySessionInformationW")
+	procWTSFreeMemory                = modwtsapi32.NewProc("WTSFreeMemory")
+)
+
+// Windows Terminal Services constants.
+const (
+	wtsActive      = 0
+	wtsSessionInfo = 24
+)
+
+// User notification states returned by SHQueryUserNotificationState.
+const (
+	qunsBusy                 = 2
+	qunsRunningD3DFullScreen = 3
+	qunsPresentationMode     = 4
+)
+
+// lastInputInfo is the LASTINPUTINFO structure.
+type lastInputInfo struct {
+	size uint32
+	time uint32
+}
+
+// sessionInfo is the WTS_SESSION_INFOW structure.
+type sessionInfo struct {
+	sessionID      uint32
+	winStationName *uint16
+	state          uint32
+}
+
+// wtsInfo is the WTSINFOW structure.
+type wtsInfo struct {
+	state                   uint32
+	sessionID               uint32
+	incomingBytes           uint32
+	outgoingBytes           uint32
+	incomingFrames          uint32
+	outgoingFrames          uint32
+	incomingCompressedBytes uint32
+	outgoingCompressedBytes uint32
+	winStationName          [32]uint16
+	domain                  [17]uint16
+	userName                [21]uint16
+	connectTime             int64
+	disconnectTime          int64
+	lastInputTime           int64
+	logonTime               int64
+	currentTime             int64
+}
+
+// Status describes the activity of the users of a machine.
+//
+// IdleTime is the amount of time since the most recent user input in any
+// active session. Sessions is the number of active sessions. If there are
+// no active sessions, IdleTime is zero and the machine is idle.
+//
+// Presenting is true if a full-screen app, a Direct3D game or presentation
+// mode is in use. It can only be determined when called from within an
+// interactive session.
+type Status struct {
+	Sessions   int
+	IdleTime   time.Duration
+	Presenting bool
+}
+
+// Idle returns true if the machine has been idle for at least the given
+// amount of time, and nothing is being presented.
+func (s Status) Idle(required time.Duration) bool {
+	if s.Presenting {
+		return false
+	}
+	return s.Sessions == 0 || s.IdleTime >= required
+}
+
+// Current returns the current activity status of the machine.
+//
+// When called from an interactive session, the status of that session is
+// returned. When called from a service, the status of all active sessions
+// is collected through Terminal Services.
+func Current() (Status, error) {
+	var session uint32
+	if err := windows.ProcessIdToSessionId(windows.GetCurrentProcessId(), &session); err != nil {
+		return Status{}, err
+	}
+	if session == 0 {
+		return sessionStatus()
+	}
+	return interactiveStatus()
+}
+
+// interactiveStatus returns the status of the current interactive session.
+func interactiveStatus() (Status, error) {
+	if err := procGetLastInputInfo.Find(); err != nil {
+		return Status{}, err
+	}
+	if err := procGetTickCount64.Find(); err != nil {
+		return Status{}, err
+	}
+
+	info := lastInputInfo{size: uint32(unsafe.Sizeof(lastInputInfo{}))}
+	r1, _, err := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(&info)))
+	if r1 == 0 {
+		return Status{}, fmt.Errorf("failed to determine the time of the last user input: %w", err)
+	}
+	now, _, _ := procGetTickCount64.Call()
+
+	// The last input time is a 32-bit tick count that wraps around, so
+	// only the low 32 bits of the current tick count are compared.
+	elapsed := uint32(now) - info.time
+	status := Status{
+		Sessions: 1,
+		IdleTime: time.Duration(elapsed) * time.Millisecond,
+	}
+
+	if procSHQueryUserNotificationState.Find() == nil {
+		var state uint32
+		if hr, _, _ := procSHQueryUserNotificationState.Call(uintptr(unsafe.Pointer(&state))); hr == 0 {
+			switch state {
+			case qunsBusy, qunsRunningD3DFullScreen, qunsPresentationMode:
+				status.Presenting = true
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// sessionStatus returns the combined status of all active sessions on the
+// machine, as reported by Terminal Services.
+func sessionStatus() (Status, error) {
+	for _, proc := range []*windows.LazyProc{procWTSEnumerateSessionsW, procWTSQuerySessionInformationW, procWTSFreeMemory} {
+		if err := proc.Find(); err != nil {
+			return Status{}, err
+		}
+	}
+
+	var (
+		sessions *sessionInfo
+		count    uint32
+	)
+	r1, _, err := procWTSEnumerateSessionsW.Call(0, 0, 1, uintptr(unsafe.Pointer(&sessions)), uintptr(unsafe.Pointer(&count)))
+	if r1 == 0 {
+		return Status{}, fmt.Errorf("failed to enumerate sessions: %w", err)
+	}
+	defer procWTSFreeMemory.Call(uintptr(unsafe.Pointer(sessions)))
+
+	var status Status
+	for _, session := range unsafe.Slice(sessions, count) {
+		if session.state != wtsActive || session.sessionID == 0 {
+			continue
+		}
+
+		var (
+			info *wtsInfo
+			size uint32
+		)
+		r1, _, err := procWTSQuerySessionInformationW.Call(0, uintptr(session.sessionID), wtsSessionInfo, uintptr(unsafe.Pointer(&info)), uintptr(unsafe.Pointer(&size)))
+		if r1 == 0 {
+			return Status{}, fmt.Errorf("failed to query session %d: %w", session.sessionID, err)
+		}
+		if uintptr(size) < unsafe.Sizeof(wtsInfo{}) {
+			procWTSFreeMemory.Call(uintptr(unsafe.Pointer(info)))
+			return Status{}, errors.New("the session information returned by Terminal Services is incomplete")
+		}
+		lastInput, current := info.lastInputTime, info.currentTime
+		procWTSFreeMemory.Call(uintptr(unsafe.Pointer(info)))
+
+		// The times are measured in 100-nanosecond intervals. Sessions that
+		// do not report their last input are treated as active, so that
+		// users are not disrupted.
+		var idle time.Duration
+		if lastInput != 0 {
+			idle = time.Duration(max(current-lastInput, 0)) * 100
+		}
+
+		if status.Sessions == 0 || idle < status.IdleTime {
+			status.IdleTime = idle
+		}
+		status.Sessions++
+	}
+
+	return status, nil
+}
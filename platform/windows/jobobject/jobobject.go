@@ -0,0 +1,89 @@
+// Package jobobject manages groups of processes with Windows job objects.
+package jobobject
+
+import (
+	"errors"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Job is a Windows job object. Processes that are assigned to a job, and
+// any child processes they create, are members of the job.
+type Job struct {
+	handle windows.Handle
+}
+
+// Create creates a new unnamed job object.
+//
+// It is the caller's responsibility to close the job when finished with it.
+// Closing the job does not affect its member processes.
+func Create() (*Job, error) {
+	handle, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Job{handle: handle}, nil
+}
+
+// Assign assigns the process with the given process ID to the job.
+//
+// Child processes created by the process after it has been assigned will
+// also be members of the job.
+func (job *Job) Assign(pid int) error {
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(process)
+
+	return windows.AssignProcessToJobObject(job.handle, process)
+}
+
+// ProcessIDs returns the process IDs of the active members of the job.
+func (job *Job) ProcessIDs() ([]uint32, error) {
+	// The JOBOBJECT_BASIC_PROCESS_ID_LIST structure has a variable-length
+	// array of process IDs at its end. Start with room for a reasonable
+	// number of them, and grow the buffer when needed.
+	type header struct {
+		NumberOfAssignedProcesses uint32
+		NumberOfProcessIdsInList  uint32
+	}
+	const headerSize = unsafe.Sizeof(header{})
+	const idSize = unsafe.Sizeof(uintptr(0))
+
+	count := 64
+	for {
+		buf := make([]byte, headerSize+uintptr(count)*idSize)
+		err := windows.QueryInformationJobObject(job.handle, windows.JobObjectBasicProcessIdList, uintptr(unsafe.Pointer(&buf[0])), uint32(len(buf)), nil)
+		if err != nil && !errors.Is(err, windows.ERROR_MORE_DATA) {
+			return nil, err
+		}
+
+		hdr := (*header)(unsafe.Pointer(&buf[0]))
+		if err != nil || hdr.NumberOfProcessIdsInList < hdr.NumberOfAssignedProcesses {
+			// The list is incomplete. Try again with a larger buffer.
+			count = max(count*2, int(hdr.NumberOfAssignedProcesses)+8)
+			continue
+		}
+
+		// Each process ID is stored as a ULONG_PTR.
+		ids := unsafe.Slice((*uintptr)(unsafe.Pointer(&buf[headerSize])), hdr.NumberOfProcessIdsInList)
+		pids := make([]uint32, len(ids))
+		for i, id := range ids {
+			pids[i] = uint32(id)
+		}
+		return pids, nil
+	}
+}
+
+// Terminate terminates all of the processes that are members of the job.
+// Each process exits with the given exit code.
+func (job *Job) Terminate(exitCode uint32) error {
+	return windows.TerminateJobObject(job.handle, exitCode)
+}
+
+// Close releases the job object's handle.
+func (job *Job) Close() error {
+	return windows.CloseHandle(job.handle)
+}
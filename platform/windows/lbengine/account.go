@@ -0,0 +1,113 @@
+package lbengine
+
+import (
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/localaccount"
+)
+
+// configureLocalUsers creates and removes local users. It stops at the
+// first user that fails.
+func (engine *actionEngine) configureLocalUsers() error {
+	for _, user := range engine.action.Definition.LocalUsers {
+		changed, err := func() (bool, error) {
+			if user.Remove {
+				return localaccount.RemoveUser(user.Name)
+			}
+			password, err := resolveSecret(user.Password)
+			if err != nil {
+				return false, fmt.Errorf("failed to resolve the password: %w", err)
+			}
+			return localaccount.CreateUser(localaccount.User{
+				Name:                 user.Name,
+				FullName:             user.FullName,
+				Description:          user.Description,
+				Disabled:             user.Disabled,
+				PasswordNeverExpires: user.PasswordNeverExpires,
+			}, password)
+		}()
+
+		engine.events.Record(lbdeployevent.LocalUserChange{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			User:        user.Name,
+			Removed:     user.Remove,
+			Changed:     changed,
+			Err:         err,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureLocalGroups adds accounts to, and removes them from, local
+// groups. It stops at the first membership that fails.
+func (engine *actionEngine) configureLocalGroups() error {
+	for _, membership := range engine.action.Definition.GroupMembers {
+		var (
+			changed bool
+			err     error
+		)
+		if membership.Remove {
+			changed, err = localaccount.RemoveGroupMember(membership.Group, membership.Member)
+		} else {
+			changed, err = localaccount.AddGroupMember(membership.Group, membership.Member)
+		}
+
+		engine.events.Record(lbdeployevent.GroupMembershipChange{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Group:       membership.Group,
+			Member:      membership.Member,
+			Removed:     membership.Remove,
+			Changed:     changed,
+			Err:         err,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureUserRights grants and revokes user rights. It stops at the
+// first assignment that fails.
+func (engine *actionEngine) configureUserRights() error {
+	for _, assignment := range engine.action.Definition.UserRights {
+		var (
+			changed bool
+			err     error
+		)
+		if assignment.Remove {
+			changed, err = localaccount.RevokeRight(assignment.Account, assignment.Right)
+		} else {
+			changed, err = localaccount.GrantRight(assignment.Account, assignment.Right)
+		}
+
+		engine.events.Record(lbdeployevent.UserRightChange{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Right:       assignment.Right,
+			Account:     assignment.Account,
+			Removed:     assignment.Remove,
+			Changed:     changed,
+			Err:         err,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -26,6 +26,57 @@ type actionEngine struct {
 	state      *engineState
 }
 
+// EvaluateConditions evaluates the conditions for the action. It returns
+// true if all of the conditions passed.
+//
+// If any of the conditions failed or could not be evaluated, an event is
+// recorded that indicates the action was skipped.
+func (engine *actionEngine) EvaluateConditions() (bool, error) {
+	ce := traceConditions(NewConditionEngine(engine.deployment), engine.state, engine.events, lbdeployevent.ConditionObserved{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+	})
+
+	var passed, failed lbdeploy.ConditionList
+	for i, condition := range engine.action.Definition.Conditions {
+		result, err := ce.Evaluate(condition)
+		if err != nil {
+			// Record the evaluation failure.
+			engine.events.Record(lbdeployevent.ActionSkipped{
+				Deployment:  engine.deployment.ID,
+				Flow:        engine.flow.ID,
+				ActionIndex: engine.action.Index,
+				ActionType:  engine.action.Definition.Type,
+				Err:         err,
+			})
+
+			return false, fmt.Errorf("action %d failed to evaluate condition %d: %w", engine.action.Index+1, i+1, err)
+		}
+		if !result {
+			failed = append(failed, condition)
+		} else {
+			passed = append(passed, condition)
+		}
+	}
+
+	if len(failed) > 0 {
+		// Record that the action was skipped.
+		engine.events.Record(lbdeployevent.ActionSkipped{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Passed:      passed,
+			Failed:      failed,
+		})
+		return false, nil
+	}
+
+	return true, nil
+}
+
 func (engine *actionEngine) Invoke(ctx context.Context) error {
 	// Record the start of the action.
 	engine.events.Record(lbdeployevent.ActionStarted{
@@ -61,6 +112,92 @@ func (engine *actionEngine) Invoke(ctx context.Context) error {
 			if err := engine.deleteFile(ctx); err != nil {
 				return err
 			}
+		case lbdeploy.ActionBackupFile:
+			if err := engine.backupFile(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionRestoreFile:
+			if err := engine.restoreFile(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionPowerShellScript:
+			if err := engine.runPowerShellScript(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionCmdScript:
+			if err := engine.runCmdScript(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionWaitForRegistry:
+			if err := engine.waitForRegistryValue(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionEditINIFile, lbdeploy.ActionEditXMLFile, lbdeploy.ActionEditJSONFile:
+			if err := engine.editConfigFile(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionEditHostsFile:
+			if err := engine.editHostsFile(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionSuspendBitLocker, lbdeploy.ActionResumeBitLocker:
+			if err := engine.changeBitLockerProtection(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionSetTimeZone:
+			if err := engine.setTimeZone(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionRegisterLogonFlow:
+			if err := engine.registerLogonFlow(); err != nil {
+				return err
+			}
+		case lbdeploy.ActionConfigureBrowserExtensions:
+			if err := engine.configureBrowserExtensions(); err != nil {
+				return err
+			}
+		case lbdeploy.ActionSetBrowserPolicies:
+			if err := engine.setBrowserPolicies(); err != nil {
+				return err
+			}
+		case lbdeploy.ActionSetDefaultAssociations:
+			if err := engine.setDefaultAssociations(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionSetStartLayout:
+			if err := engine.setStartLayout(); err != nil {
+				return err
+			}
+		case lbdeploy.ActionClearStartLayout:
+			if err := engine.clearStartLayout(); err != nil {
+				return err
+			}
+		case lbdeploy.ActionConfigureLocalUsers:
+			if err := engine.configureLocalUsers(); err != nil {
+				return err
+			}
+		case lbdeploy.ActionConfigureLocalGroups:
+			if err := engine.configureLocalGroups(); err != nil {
+				return err
+			}
+		case lbdeploy.ActionConfigureUserRights:
+			if err := engine.configureUserRights(); err != nil {
+				return err
+			}
+		case lbdeploy.ActionSetPowerPlan:
+			if err := engine.setPowerPlan(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionConfigurePowerSettings:
+			if err := engine.configurePowerSettings(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionActivateLicense:
+			if err := engine.activateLicense(ctx); err != nil {
+				return err
+			}
+		case lbdeploy.ActionShellScript:
+			return fmt.Errorf("the \"%s\" action is not supported on Windows", engine.action.Definition.Type)
 		default:
 			return fmt.Errorf("unrecognized deployment action type \"%s\"", engine.action.Definition.Type)
 		}
@@ -175,6 +312,16 @@ func (engine *actionEngine) invokeCommand(ctx context.Context) error {
 		command = commandData{ID: engine.action.Definition.Command, Definition: definition}
 	}
 
+	// Package manager commands are only supported on Linux.
+	if command.Definition.Type.IsPackageManager() {
+		return fmt.Errorf("the \"%s\" command uses a package manager, which is not supported on Windows", command.ID)
+	}
+
+	// macOS installer commands are only supported on macOS.
+	if command.Definition.Type.IsMacInstaller() {
+		return fmt.Errorf("the \"%s\" command installs macOS software, which is not supported on Windows", command.ID)
+	}
+
 	// Determine whether any app changes are anticipated.
 	ae := NewAppEngine(engine.deployment)
 	appEvaluation, err := ae.EvaluateAppChanges(command.Definition.Installs, command.Definition.Uninstalls)
@@ -182,6 +329,22 @@ func (engine *actionEngine) invokeCommand(ctx context.Context) error {
 		return fmt.Errorf("the evaluation of potential application changes did not succeed: %w", err)
 	}
 
+	// If the command would modify an app that is held on this machine,
+	// skip it, even if command invocation is forced.
+	if app, hold, held := heldApp(engine.state, engine.deployment, command.Definition); held {
+		engine.events.Record(lbdeployevent.CommandSkipped{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Command:     command.ID,
+			Apps:        appEvaluation,
+			HeldApp:     app,
+			HoldReason:  hold.Reason,
+		})
+		return nil
+	}
+
 	// If the command declares that it installs or uninstalls something,
 	// review the app evaluation to determine whether any application changes
 	// are anticpated.
@@ -242,6 +405,36 @@ func (engine *actionEngine) copyFile(ctx context.Context) error {
 	return fe.CopyFile(ctx)
 }
 
+// backupFile performs a file backup operation.
+func (engine *actionEngine) backupFile(ctx context.Context) error {
+	// Prepare a file engine.
+	fe := fileEngine{
+		deployment: engine.deployment,
+		flow:       engine.flow,
+		action:     engine.action,
+		events:     engine.events,
+		state:      engine.state,
+	}
+
+	// Execute the backup-file action via the file engine.
+	return fe.BackupFile(ctx)
+}
+
+// restoreFile performs a file restore operation.
+func (engine *actionEngine) restoreFile(ctx context.Context) error {
+	// Prepare a file engine.
+	fe := fileEngine{
+		deployment: engine.deployment,
+		flow:       engine.flow,
+		action:     engine.action,
+		events:     engine.events,
+		state:      engine.state,
+	}
+
+	// Execute the restore-file action via the file engine.
+	return fe.RestoreFile(ctx)
+}
+
 // deleteFile performs a file delete operation.
 func (engine *actionEngine) deleteFile(ctx context.Context) error {
 	// Prepare a file engine.
@@ -256,3 +449,48 @@ func (engine *actionEngine) deleteFile(ctx context.Context) error {
 	// Execute the delete-file action via the file engine.
 	return fe.DeleteFile(ctx)
 }
+
+// editConfigFile applies configuration edits to a file.
+func (engine *actionEngine) editConfigFile(ctx context.Context) error {
+	// Prepare a file engine.
+	fe := fileEngine{
+		deployment: engine.deployment,
+		flow:       engine.flow,
+		action:     engine.action,
+		events:     engine.events,
+		state:      engine.state,
+	}
+
+	// Execute the edit action via the file engine.
+	return fe.EditConfigFile(ctx)
+}
+
+// runPowerShellScript runs a PowerShell script.
+func (engine *actionEngine) runPowerShellScript(ctx context.Context) error {
+	// Prepare a script engine.
+	se := scriptEngine{
+		deployment: engine.deployment,
+		flow:       engine.flow,
+		action:     engine.action,
+		events:     engine.events,
+		state:      engine.state,
+	}
+
+	// Execute the powershell-script action via the script engine.
+	return se.InvokePowerShell(ctx)
+}
+
+// runCmdScript runs a batch script with the Windows command processor.
+func (engine *actionEngine) runCmdScript(ctx context.Context) error {
+	// Prepare a script engine.
+	se := scriptEngine{
+		deployment: engine.deployment,
+		flow:       engine.flow,
+		action:     engine.action,
+		events:     engine.events,
+		state:      engine.state,
+	}
+
+	// Execute the cmd-script action via the script engine.
+	return se.InvokeCmd(ctx)
+}
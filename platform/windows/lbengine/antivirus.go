@@ -0,0 +1,101 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/defender"
+)
+
+// antivirusRemovalTimeout limits the amount of time spent removing
+// temporary antivirus exclusions when a deployment finishes.
+const antivirusRemovalTimeout = time.Minute
+
+// excludeFromAntivirus temporarily excludes path from real-time antivirus
+// scanning, if the deployment requests it. The exclusion is removed when
+// the deployment's invocation has finished.
+//
+// Paths that are already excluded are left alone, and are not removed
+// later. Failures are recorded as events but are not fatal.
+func excludeFromAntivirus(ctx context.Context, deployment lbdeploy.Deployment, events lbevent.Recorder, state *engineState, path string) {
+	if !deployment.Staging.ExcludeFromAntivirus || state.antivirusExcluded(path) {
+		return
+	}
+
+	excluded, err := defender.PathExcluded(ctx, path)
+	if err == nil && excluded {
+		return
+	}
+	if err == nil {
+		err = defender.AddPathExclusion(ctx, path)
+	}
+
+	// If Defender isn't available, there's nothing to record. Another
+	// antivirus product might be in use.
+	if errors.Is(err, defender.ErrUnavailable) {
+		return
+	}
+
+	events.Record(lbdeployevent.AntivirusExclusion{
+		Deployment: deployment.ID,
+		Operation:  lbdeployevent.AntivirusExclusionAdded,
+		Path:       path,
+		Err:        err,
+	})
+	events.Record(lbdeployevent.SecurityChange{
+		Deployment: deployment.ID,
+		Kind:       lbdeployevent.SecurityChangeAntivirusExclusion,
+		Operation:  string(lbdeployevent.AntivirusExclusionAdded),
+		Target:     path,
+		Err:        err,
+	})
+
+	if err == nil {
+		state.antivirusExclusions = append(state.antivirusExclusions, path)
+	}
+}
+
+// removeAntivirusExclusions removes all of the temporary antivirus
+// exclusions that were added during the deployment's invocation.
+//
+// It runs even if ctx has been cancelled.
+func removeAntivirusExclusions(ctx context.Context, deployment lbdeploy.Deployment, events lbevent.Recorder, state *engineState) {
+	if len(state.antivirusExclusions) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), antivirusRemovalTimeout)
+	defer cancel()
+
+	for _, path := range state.antivirusExclusions {
+		err := defender.RemovePathExclusion(ctx, path)
+		events.Record(lbdeployevent.AntivirusExclusion{
+			Deployment: deployment.ID,
+			Operation:  lbdeployevent.AntivirusExclusionRemoved,
+			Path:       path,
+			Err:        err,
+		})
+		events.Record(lbdeployevent.SecurityChange{
+			Deployment: deployment.ID,
+			Kind:       lbdeployevent.SecurityChangeAntivirusExclusion,
+			Operation:  string(lbdeployevent.AntivirusExclusionRemoved),
+			Target:     path,
+			Err:        err,
+		})
+	}
+	state.antivirusExclusions = nil
+}
+
+// antivirusExcluded returns true if a temporary antivirus exclusion has
+// been added for path.
+func (state *engineState) antivirusExcluded(path string) bool {
+	return slices.ContainsFunc(state.antivirusExclusions, func(excluded string) bool {
+		return strings.EqualFold(excluded, path)
+	})
+}
@@ -0,0 +1,61 @@
+package lbengine
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/windows/hostinfo"
+)
+
+// nativeArchitecture returns the native architecture of the operating
+// system, which might differ from the architecture of the running process.
+func nativeArchitecture() lbdeploy.Architecture {
+	switch hostinfo.Architecture() {
+	case "arm64":
+		return lbdeploy.ArchitectureARM64
+	case "386":
+		return lbdeploy.ArchitectureX86
+	default:
+		return lbdeploy.ArchitectureX64
+	}
+}
+
+// architecture returns the architecture that the engine selects packages
+// for.
+//
+// An architecture provided in the engine's options takes precedence. When
+// the engine runs from a bundle, the packages that were bundled determine
+// the architecture, as long as they can run on the system. Otherwise the
+// native architecture of the system is used.
+func (engine DeploymentEngine) architecture() (lbdeploy.Architecture, error) {
+	if engine.arch != "" {
+		return engine.arch, nil
+	}
+
+	native := nativeArchitecture()
+	if bundle := engine.state.bundle; bundle != nil {
+		if arch := bundle.Manifest().Architecture; arch != "" {
+			if !slices.Contains(native.Compatible(), arch) {
+				return "", fmt.Errorf("the bundle holds packages for the %s architecture, which cannot run on this %s system", arch, native)
+			}
+			return arch, nil
+		}
+	}
+
+	return native, nil
+}
+
+// selectArchitecture returns the engine's deployment with the packages
+// that suit the architecture the engine selects packages for.
+func (engine DeploymentEngine) selectArchitecture() (lbdeploy.Deployment, lbdeploy.Architecture, error) {
+	arch, err := engine.architecture()
+	if err != nil {
+		return lbdeploy.Deployment{}, "", err
+	}
+	dep, err := engine.deployment.ForArchitecture(arch)
+	if err != nil {
+		return lbdeploy.Deployment{}, "", err
+	}
+	return dep, arch, nil
+}
@@ -0,0 +1,164 @@
+package lbengine
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/internal/archivepath"
+)
+
+// ZIP compression methods that are recognized but not supported by the
+// extraction engine.
+const (
+	zipMethodDeflate64 = 9
+	zipMethodBZIP2     = 12
+	zipMethodLZMA      = 14
+	zipMethodZstandard = 93
+	zipMethodXZ        = 95
+	zipMethodPPMd      = 98
+)
+
+// zipFlagEncrypted is set in the general purpose flags of encrypted ZIP
+// entries.
+const zipFlagEncrypted = 0x1
+
+// UnsupportedArchiveError is returned when an archive relies on a feature
+// that the extraction engine does not support.
+type UnsupportedArchiveError struct {
+	Entry   string
+	Feature string
+}
+
+// Error returns a description of the error.
+func (err UnsupportedArchiveError) Error() string {
+	if err.Entry == "" {
+		return fmt.Sprintf("the archive uses an unsupported feature: %s", err.Feature)
+	}
+	return fmt.Sprintf("the \"%s\" entry in the archive uses an unsupported feature: %s", err.Entry, err.Feature)
+}
+
+// UnsafeArchiveError is returned when an archive contains entries with
+// paths that could escape the extraction directory.
+type UnsafeArchiveError struct {
+	Entries []string
+}
+
+// Error returns a description of the error.
+func (err UnsafeArchiveError) Error() string {
+	if len(err.Entries) == 1 {
+		return fmt.Sprintf("the archive was rejected because the \"%s\" entry has an unsafe path", err.Entries[0])
+	}
+	return fmt.Sprintf("the archive was rejected because %d entries have unsafe paths, including \"%s\"", len(err.Entries), err.Entries[0])
+}
+
+// Category returns the category of the error.
+func (err UnsafeArchiveError) Category() lberror.Category {
+	return lberror.Verification
+}
+
+// ExtractionQuotaError is returned when the files extracted from an
+// archive exceed the extraction quota of its package.
+type ExtractionQuotaError struct {
+	MaxFiles   int
+	MaxBytes   int64
+	Files      int
+	TotalBytes int64
+}
+
+// Error returns a description of the error.
+func (err ExtractionQuotaError) Error() string {
+	if err.MaxFiles > 0 && err.Files > err.MaxFiles {
+		return fmt.Sprintf("the archive was rejected because it holds more than %d files, which exceeds the package's extraction quota", err.MaxFiles)
+	}
+	return fmt.Sprintf("the archive was rejected because it expands to more than %d bytes, which exceeds the package's extraction quota", err.MaxBytes)
+}
+
+// Category returns the category of the error.
+func (err ExtractionQuotaError) Category() lberror.Category {
+	return lberror.Verification
+}
+
+// unsafeArchiveEntries returns a violation for each entry in the archive
+// with a path that could escape the extraction directory.
+func unsafeArchiveEntries(reader *zip.Reader) (violations []lbdeployevent.ExtractionViolation) {
+	for _, f := range reader.File {
+		if reason := archivepath.Check(f.Name, f.Mode()); reason != "" {
+			violations = append(violations, lbdeployevent.ExtractionViolation{
+				Entry:  f.Name,
+				Reason: string(reason),
+			})
+		}
+	}
+	return violations
+}
+
+// openArchive prepares a ZIP reader for the given source. Zip64 archives
+// are supported.
+func openArchive(source io.ReaderAt, size int64) (*zip.Reader, error) {
+	reader, err := zip.NewReader(source, size)
+	switch {
+	case err == nil:
+		return reader, nil
+	case errors.Is(err, zip.ErrFormat):
+		return nil, fmt.Errorf("the package is not a valid ZIP archive or its central directory is damaged: %w", err)
+	default:
+		return nil, err
+	}
+}
+
+// unsupportedArchiveFeature returns a description of the first feature used
+// by f that cannot be extracted. It returns an empty string if f can be
+// extracted.
+//
+// Zero-length files are always supported, because they are written without
+// being decompressed.
+func unsupportedArchiveFeature(f *zip.File) string {
+	if f.FileInfo().IsDir() || f.UncompressedSize64 == 0 {
+		return ""
+	}
+
+	if f.Flags&zipFlagEncrypted != 0 {
+		return "encryption"
+	}
+
+	switch f.Method {
+	case zip.Store, zip.Deflate:
+		return ""
+	case zipMethodDeflate64:
+		return "Deflate64 compression"
+	case zipMethodBZIP2:
+		return "BZIP2 compression"
+	case zipMethodLZMA:
+		return "LZMA compression"
+	case zipMethodZstandard:
+		return "Zstandard compression"
+	case zipMethodXZ:
+		return "XZ compression"
+	case zipMethodPPMd:
+		return "PPMd compression"
+	default:
+		return fmt.Sprintf("compression method %d", f.Method)
+	}
+}
+
+// openArchiveFile opens f for reading. Zero-length files are not
+// decompressed.
+func openArchiveFile(f *zip.File) (io.ReadCloser, error) {
+	if f.UncompressedSize64 == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		if errors.Is(err, zip.ErrAlgorithm) {
+			return nil, UnsupportedArchiveError{Entry: f.Name, Feature: fmt.Sprintf("compression method %d", f.Method)}
+		}
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,139 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/appassoc"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// associationsFile is the name of the default application association
+	// file that is maintained in ProgramData\LeafBridge.
+	associationsFile = "DefaultAssociations.xml"
+
+	// associationsPolicyKeyPath is the registry key that holds the group
+	// policy that applies a default application association file at logon.
+	associationsPolicyKeyPath = `SOFTWARE\Policies\Microsoft\Windows\System`
+
+	// associationsPolicyValue is the name of the registry value that holds
+	// the path of the default application association file applied at logon.
+	associationsPolicyValue = "DefaultAssociationsConfiguration"
+)
+
+// setDefaultAssociations updates the default application association file
+// of the machine and imports it through DISM. If the action enforces the
+// associations, the file is also applied at each logon through group
+// policy.
+func (engine *actionEngine) setDefaultAssociations(ctx context.Context) error {
+	var (
+		path         string
+		set, removed []string
+	)
+	err := func() error {
+		base, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(base, stagingfs.RootDir, associationsFile)
+
+		doc, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		for _, assoc := range engine.action.Definition.Associations {
+			var changed bool
+			if assoc.Remove {
+				doc, changed, err = appassoc.Remove(doc, assoc.Identifier)
+				if changed {
+					removed = append(removed, assoc.Identifier)
+				}
+			} else {
+				doc, changed, err = appassoc.Set(doc, appassoc.Association{
+					Identifier:      assoc.Identifier,
+					ProgID:          assoc.ProgID,
+					ApplicationName: assoc.Application,
+				})
+				if changed {
+					set = append(set, assoc.Identifier)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(set) > 0 || len(removed) > 0 {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, doc, 0644); err != nil {
+				return err
+			}
+		}
+
+		// Import the file even when it hasn't changed, in case an earlier
+		// import failed.
+		if len(doc) > 0 {
+			if err := importDefaultAssociations(ctx, path); err != nil {
+				return err
+			}
+		}
+
+		if engine.action.Definition.Enforce {
+			key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, associationsPolicyKeyPath, registry.SET_VALUE)
+			if err != nil {
+				return fmt.Errorf("failed to open the \"%s\" registry key: %w", associationsPolicyKeyPath, err)
+			}
+			defer key.Close()
+			if err := key.SetStringValue(associationsPolicyValue, path); err != nil {
+				return fmt.Errorf("failed to set the %s policy: %w", associationsPolicyValue, err)
+			}
+		}
+
+		return nil
+	}()
+
+	engine.events.Record(lbdeployevent.DefaultAssociationsChange{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		FilePath:    path,
+		Set:         set,
+		Removed:     removed,
+		Enforced:    engine.action.Definition.Enforce,
+		Err:         err,
+	})
+
+	return err
+}
+
+// importDefaultAssociations imports the default application association
+// file at path through DISM.
+func importDefaultAssociations(ctx context.Context, path string) error {
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return err
+	}
+
+	output, err := exec.CommandContext(ctx, filepath.Join(system, "Dism.exe"), "/Online", "/Import-DefaultAppAssociations:"+path).CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("dism failed to import the default application associations: %w: %s", err, message)
+		}
+		return fmt.Errorf("dism failed to import the default application associations: %w", err)
+	}
+
+	return nil
+}
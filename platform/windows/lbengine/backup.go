@@ -0,0 +1,272 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/filetime"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+)
+
+// BackupFile performs a backup-file action.
+func (engine *fileEngine) BackupFile(ctx context.Context) error {
+	// Find the relevant file within the deployment. Backups don't change
+	// the file, so it may be in a protected location.
+	resolver := localfs.NewResolver(engine.deployment.Resources.FileSystem)
+	fileID := engine.action.Definition.DestinationFile
+	fileRef, err := resolver.ResolveFile(fileID)
+	if err != nil {
+		return fmt.Errorf("file: %w", err)
+	}
+	path, err := fileRef.Path()
+	if err != nil {
+		return err
+	}
+	return engine.backup(fileID, path, false)
+}
+
+// RestoreFile performs a restore-file action.
+func (engine *fileEngine) RestoreFile(ctx context.Context) error {
+	// Prepare a local file system resolver.
+	resolver := localfs.NewResolver(engine.deployment.Resources.FileSystem)
+
+	// Find the relevant file within the deployment.
+	fileID := engine.action.Definition.DestinationFile
+	fileRef, err := resolver.ResolveFile(fileID)
+	if err != nil {
+		return fmt.Errorf("file: %w", err)
+	}
+
+	// Make sure that the file isn't redirected elsewhere, and that it
+	// can't be redirected while it is being changed.
+	unlock, err := engine.lockReparsePoints(fileRef, fileID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Make sure that the file is not in a protected location, unless the
+	// action permits it.
+	release, err := engine.unprotect(fileRef, fileID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	var (
+		filePath   string
+		backupPath string
+		record     stagingfs.BackupRecord
+	)
+	err = func() error {
+		// Determine the path of the file. The path recorded in the
+		// backup is not used, so that the backup can't redirect the
+		// restoration elsewhere.
+		filePath, err = fileRef.Path()
+		if err != nil {
+			return err
+		}
+
+		// Open the backups made during this run.
+		set, err := stagingfs.OpenBackupSet(engine.deployment.ID, engine.events.Origin.RunID)
+		if err != nil {
+			return fmt.Errorf("unable to open the backup directory: %w", err)
+		}
+		defer set.Close()
+
+		// Find the backup of the file.
+		record, err = set.ReadRecord(fileID)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("the \"%s\" file was not backed up during this run", fileID)
+			}
+			return fmt.Errorf("unable to read the backup record: %w", err)
+		}
+
+		// Open the root above the file.
+		fileDir, err := localfs.OpenDir(fileRef.Dir())
+		if err != nil {
+			if !record.Existed && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("unable to open the file's directory: %w", err)
+		}
+		defer fileDir.Close()
+
+		// If the file didn't exist when it was backed up, remove it.
+		if !record.Existed {
+			if err := fileDir.System().Remove(fileRef.FilePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			return nil
+		}
+
+		// Open the backup data.
+		backupPath, _ = set.FilePath(fileID)
+		backup, err := set.Open(fileID)
+		if err != nil {
+			return fmt.Errorf("unable to open the backup: %w", err)
+		}
+		defer backup.Close()
+
+		// Write the backup next to the file, then replace the file with
+		// it, so that the file is left intact if it can't be replaced.
+		stagedFile := fileRef.FilePath + stagedFileSuffix
+		if err := copyFileData(ctx, fileDir.System(), stagedFile, backup); err != nil {
+			fileDir.System().Remove(stagedFile)
+			return err
+		}
+		if err := os.Rename(filePath+stagedFileSuffix, filePath); err != nil {
+			fileDir.System().Remove(stagedFile)
+			if locked := checkFileLocked(filePath, err); locked != nil {
+				return *locked
+			}
+			return err
+		}
+		return nil
+	}()
+
+	// Record the file restoration.
+	engine.events.Record(lbdeployevent.FileRestore{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		FileID:      fileID,
+		FilePath:    filePath,
+		FileExisted: record.Existed,
+		BackupPath:  backupPath,
+		BackupTime:  record.Created,
+		Err:         err,
+	})
+	engine.recordFileChange(fileRef, fileID, "restore", filePath, err)
+
+	return err
+}
+
+// autoBackup backs up the file at path before it is changed, if the flow
+// requests automatic backups. Only the first change to a file during a run
+// is backed up, so that the backup holds its original state.
+func (engine *fileEngine) autoBackup(fileID lbdeploy.FileResourceID, path string) error {
+	if !engine.flow.Definition.Backup {
+		return nil
+	}
+	if path == "" {
+		return errors.New("unable to determine the path of the file to be backed up")
+	}
+	return engine.backup(fileID, path, true)
+}
+
+// backup backs up the file at path and records the result. If automatic
+// is true and the file has already been backed up during this run, it
+// does nothing.
+func (engine *fileEngine) backup(fileID lbdeploy.FileResourceID, path string, automatic bool) error {
+	var (
+		backupPath string
+		record     stagingfs.BackupRecord
+	)
+	err := func() error {
+		// Open the backups made during this run.
+		set, err := stagingfs.OpenBackupSet(engine.deployment.ID, engine.events.Origin.RunID)
+		if err != nil {
+			return fmt.Errorf("unable to open the backup directory: %w", err)
+		}
+		defer set.Close()
+
+		// Automatic backups preserve the original state of the file.
+		if automatic {
+			if exists, err := set.HasRecord(fileID); err != nil || exists {
+				return err
+			}
+		}
+
+		record, err = writeBackup(set, fileID, path)
+		if record.Existed {
+			backupPath, _ = set.FilePath(fileID)
+		}
+		return err
+	}()
+
+	// Only record automatic backups that were actually made.
+	if automatic && err == nil && record.Created.IsZero() {
+		return nil
+	}
+
+	engine.events.Record(lbdeployevent.FileBackup{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		FileID:      fileID,
+		FilePath:    path,
+		FileExisted: record.Existed,
+		FileSize:    record.Size,
+		BackupPath:  backupPath,
+		Automatic:   automatic,
+		Err:         err,
+	})
+
+	return err
+}
+
+// writeBackup copies the file at path into the backup set and writes a
+// record describing it. If the file does not exist, only the record is
+// written.
+func writeBackup(set stagingfs.BackupSet, fileID lbdeploy.FileResourceID, path string) (stagingfs.BackupRecord, error) {
+	record := stagingfs.BackupRecord{
+		Path:    path,
+		Created: time.Now(),
+	}
+
+	fi, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return record, set.WriteRecord(fileID, record)
+	case err != nil:
+		return record, fmt.Errorf("unable to evaluate the file: %w", err)
+	case !fi.Mode().IsRegular():
+		return record, errors.New("the file path exists but is not a regular file")
+	}
+
+	record.Existed = true
+	record.Size = fi.Size()
+	record.Modified = fi.ModTime()
+
+	source, err := os.Open(path)
+	if err != nil {
+		return record, fmt.Errorf("unable to open the file: %w", err)
+	}
+	defer source.Close()
+
+	backup, err := set.Create(fileID)
+	if err != nil {
+		return record, fmt.Errorf("unable to create the backup: %w", err)
+	}
+	defer backup.Close()
+
+	if _, err := io.Copy(backup, source); err != nil {
+		return record, fmt.Errorf("unable to copy the file: %w", err)
+	}
+
+	// Preserve the modification time, so that it is restored along with
+	// the file.
+	if !record.Modified.IsZero() {
+		if err := filetime.SetFileModificationTime(backup, record.Modified); err != nil {
+			return record, fmt.Errorf("failed to set file modification time: %w", err)
+		}
+	}
+
+	if err := backup.Close(); err != nil {
+		return record, err
+	}
+
+	return record, set.WriteRecord(fileID, record)
+}
@@ -0,0 +1,38 @@
+package lbengine
+
+import (
+	"context"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/bitlocker"
+)
+
+// changeBitLockerProtection suspends or resumes BitLocker protection of
+// the system drive.
+func (engine *actionEngine) changeBitLockerProtection(ctx context.Context) error {
+	var drive string
+	err := func() error {
+		var err error
+		if drive, err = bitlocker.SystemDrive(); err != nil {
+			return err
+		}
+		if engine.action.Definition.Type == lbdeploy.ActionSuspendBitLocker {
+			return bitlocker.Suspend(ctx, drive, engine.action.Definition.RebootCount)
+		}
+		return bitlocker.Resume(ctx, drive)
+	}()
+
+	engine.events.Record(lbdeployevent.BitLockerProtection{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Drive:       drive,
+		RebootCount: engine.action.Definition.RebootCount,
+		Err:         err,
+	})
+	engine.recordSecurityChange(lbdeployevent.SecurityChangeBitLocker, string(engine.action.Definition.Type), drive, err)
+
+	return err
+}
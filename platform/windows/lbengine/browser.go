@@ -0,0 +1,65 @@
+package lbengine
+
+import (
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/browserpolicy"
+)
+
+// configureBrowserExtensions adds extensions to, or removes them from, the
+// force-install lists of their browsers. It stops at the first extension
+// that fails.
+func (engine *actionEngine) configureBrowserExtensions() error {
+	for _, ext := range engine.action.Definition.Extensions {
+		var err error
+		if ext.Remove {
+			err = browserpolicy.RemoveExtension(ext)
+		} else {
+			err = browserpolicy.InstallExtension(ext)
+		}
+
+		event := lbdeployevent.BrowserExtensionChange{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Browser:     ext.Browser,
+			Extension:   ext.ID,
+			Settings:    len(ext.Settings),
+			Removed:     ext.Remove,
+			Err:         err,
+		}
+		if !ext.Remove {
+			event.URL = ext.EffectiveURL()
+		}
+		engine.events.Record(event)
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setBrowserPolicies applies policies to browsers. It stops at the first
+// policy that fails.
+func (engine *actionEngine) setBrowserPolicies() error {
+	for _, policy := range engine.action.Definition.BrowserPolicies {
+		err := browserpolicy.SetPolicy(policy)
+
+		engine.events.Record(lbdeployevent.BrowserPolicyChange{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Browser:     policy.Browser,
+			Policy:      policy.Name,
+			Removed:     policy.Removes(),
+			Err:         err,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbbundle"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+)
+
+// Bundle downloads and verifies every package in the deployment, then
+// writes the deployment file and the packages to w as an offline bundle.
+//
+// The document must hold the deployment that the engine was prepared for.
+// Packages without any sources are omitted from the bundle, because they
+// are only used by commands that don't need the package file.
+func (engine DeploymentEngine) Bundle(ctx context.Context, document []byte, w io.Writer) error {
+	if engine.state.bundle != nil {
+		return fmt.Errorf("the \"%s\" deployment is already bundled", engine.deployment.ID)
+	}
+
+	// Ensure that the deployment is valid.
+	if err := engine.deployment.Validate(); err != nil {
+		return err
+	}
+
+	// Select the packages that suit the architecture the bundle is
+	// prepared for. The architecture is recorded in the bundle if it
+	// affected the selection.
+	dep, arch, err := engine.selectArchitecture()
+	if err != nil {
+		return err
+	}
+	selective := engine.deployment.Resources.Packages.ArchitectureSpecific()
+	engine.deployment = dep
+
+	bw := lbbundle.NewWriter(w)
+	if err := bw.SetDocument(document); err != nil {
+		return err
+	}
+	if selective {
+		bw.SetArchitecture(arch)
+	}
+
+	// Release resources when we are finished.
+	defer engine.state.locks.CloseAll()
+
+	for _, id := range engine.deployment.Resources.Packages.IDs() {
+		definition := engine.deployment.Resources.Packages[id]
+		if len(definition.Sources) == 0 {
+			continue
+		}
+
+		// Download and verify the package file, resolving its hash from
+		// its checksums file if necessary.
+		pe := packageEngine{
+			deployment: engine.deployment,
+			pkg: packageData{
+				ID:         id,
+				Definition: definition,
+			},
+			events: engine.events,
+			force:  engine.force,
+			state:  engine.state,
+		}
+		if err := pe.PreparePackage(ctx); err != nil {
+			return fmt.Errorf("failed to prepare the \"%s\" package: %w", id, err)
+		}
+
+		// Copy the verified package file into the bundle.
+		if err := func() error {
+			file, err := pe.openPackageFile()
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+
+			return bw.AddPackage(id, pe.pkg.Definition, newReaderWithContext(ctx, file))
+		}(); err != nil {
+			return err
+		}
+	}
+
+	return bw.Close()
+}
+
+// copyPackageFromBundle copies a package file from the engine's bundle into
+// the staging file, writing to both the file and the verifier. It replaces
+// any content that is already present in the file.
+func (engine *downloadEngine) copyPackageFromBundle(ctx context.Context, pkg packageData, file stagingfs.PackageFile, verifier *FileVerifier) (lbdeploy.PackageSource, error) {
+	bundle := engine.state.bundle
+
+	r, entry, err := bundle.OpenPackage(pkg.ID)
+	if err != nil {
+		return lbdeploy.PackageSource{}, err
+	}
+	defer r.Close()
+
+	source := lbdeploy.PackageSource{
+		Type: lbdeploy.PackageSourceBundle,
+		URL:  bundle.Path() + string(os.PathSeparator) + entry.Path,
+	}
+
+	// Files in a bundle can't be read from an offset, so partial content
+	// is discarded.
+	if verifier.Size() > 0 {
+		if err := engine.resetFileDownload(source, file, verifier, lbdeployevent.ExistingFileVerificationFailed); err != nil {
+			return source, err
+		}
+	}
+
+	// Record the start of the copy.
+	engine.events.Record(lbdeployevent.DownloadStarted{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Source:      source,
+		FileName:    file.Name,
+		Path:        file.Path,
+	})
+
+	started := time.Now()
+	copied, err := io.Copy(io.MultiWriter(file, verifier), newReaderWithContext(ctx, r))
+	stopped := time.Now()
+
+	// Record the end of the copy.
+	engine.events.Record(lbdeployevent.DownloadStopped{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Source:      source,
+		FileName:    file.Name,
+		Path:        file.Path,
+		Downloaded:  copied,
+		FileSize:    copied,
+		Started:     started,
+		Stopped:     stopped,
+		Err:         err,
+	})
+
+	return source, err
+}
@@ -0,0 +1,207 @@
+package lbengine
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbstate"
+	"github.com/leafbridge/leafbridge/platform/windows/tempfs"
+)
+
+// flowCheckpoint persists the progress of a running flow, so that it can
+// be resumed if it is interrupted.
+//
+// Checkpoints are not essential to running a flow, so failures to read or
+// write them are ignored. A flow whose checkpoint could not be written
+// starts over if it is resumed.
+type flowCheckpoint struct {
+	store      lbstate.Store
+	flow       lbdeploy.FlowID
+	checkpoint lbstate.Checkpoint
+	resumed    bool
+}
+
+// beginCheckpoint returns a checkpoint for the flow, which started at the
+// given time.
+//
+// If the engine is resuming an interrupted deployment and the flow has a
+// checkpoint that was made by its current definition, the flow continues
+// from that checkpoint and an event is recorded. The packages that had been
+// verified and extracted are added to the engine's state, so that they are
+// not verified or extracted again. Otherwise a new checkpoint is made.
+//
+// Checkpoints are kept with the flow's execution history, which is in
+// ProgramData for flows that run once per machine. Those checkpoints are
+// only honored if they can be trusted, as determined by
+// checkpointsTrusted.
+func (engine flowEngine) beginCheckpoint(started time.Time) *flowCheckpoint {
+	scope := engine.flow.Definition.Frequency.EffectiveScope()
+	store, err := openFlowState(engine.deployment.ID, scope)
+	if err != nil {
+		return nil
+	}
+
+	// Resume from an existing checkpoint, if requested.
+	if engine.state.resume && checkpointsTrusted(store, scope) {
+		if existing, found, err := store.Checkpoint(engine.flow.ID); err == nil && found && existing.Matches(engine.flow.Definition) {
+			engine.events.Record(lbdeployevent.FlowResumed{
+				Deployment:        engine.deployment.ID,
+				Flow:              engine.flow.ID,
+				Interrupted:       existing.Updated,
+				Completed:         len(existing.Completed),
+				Actions:           len(engine.flow.Definition.Actions),
+				VerifiedPackages:  existing.VerifiedPackages,
+				ExtractedPackages: existing.Extracted(),
+			})
+			for _, pkg := range existing.VerifiedPackages {
+				engine.state.resumedVerified.Add(pkg)
+			}
+			for _, extraction := range existing.ExtractedPackages {
+				if _, found := engine.state.resumedExtractions[extraction.Package]; !found {
+					engine.state.resumedExtractions[extraction.Package] = extraction
+				}
+			}
+			return &flowCheckpoint{store: store, flow: engine.flow.ID, checkpoint: existing, resumed: true}
+		}
+	}
+
+	// Start a new checkpoint. Flows started by other flows are resumed
+	// along with the flow that was invoked.
+	invoked := len(engine.state.activeFlows) == 1
+	checkpoint, err := lbstate.NewCheckpoint(engine.flow.Definition, invoked, started)
+	if err != nil {
+		return nil
+	}
+	if err := store.SaveCheckpoint(engine.flow.ID, checkpoint); err != nil {
+		return nil
+	}
+	return &flowCheckpoint{store: store, flow: engine.flow.ID, checkpoint: checkpoint}
+}
+
+// Done returns true if the action with the given index completed before
+// the flow was interrupted.
+func (c *flowCheckpoint) Done(index int) bool {
+	if c == nil || !c.resumed {
+		return false
+	}
+	return c.checkpoint.Done(index)
+}
+
+// Complete records that the action with the given index has completed,
+// along with the packages that have been verified and extracted so far.
+//
+// Packages carried over from an earlier checkpoint that have not been
+// needed yet are recorded again, so that they remain available if the flow
+// is interrupted a second time.
+func (c *flowCheckpoint) Complete(index int, state *engineState) {
+	if c == nil {
+		return
+	}
+	c.checkpoint.Completed = append(c.checkpoint.Completed, index)
+
+	verified := maps.Clone(state.resumedVerified)
+	for pkg := range state.verifiedPackageFiles {
+		verified.Add(pkg)
+	}
+	c.checkpoint.VerifiedPackages = slices.Sorted(maps.Keys(verified))
+
+	extractions := maps.Clone(state.resumedExtractions)
+	for pkg, dir := range state.extractedPackages {
+		extractions[pkg] = lbstate.PackageExtraction{
+			Package: pkg,
+			Run:     dir.Scope().Run,
+			Dir:     filepath.Base(dir.Path()),
+		}
+	}
+	c.checkpoint.ExtractedPackages = nil
+	for _, pkg := range slices.Sorted(maps.Keys(extractions)) {
+		c.checkpoint.ExtractedPackages = append(c.checkpoint.ExtractedPackages, extractions[pkg])
+	}
+
+	c.checkpoint.Updated = time.Now().UTC()
+	c.store.SaveCheckpoint(c.flow, c.checkpoint)
+}
+
+// resumeExtraction reopens the extraction directory of pkg that was
+// recorded by the checkpoint of a resumed flow. It returns false if no
+// directory was recorded for the package, or if it could not be reopened,
+// in which case the package must be extracted again.
+//
+// A directory is only reopened for the same package content, and it is
+// deleted when the deployment has finished, like any other extraction
+// directory.
+func (state *engineState) resumeExtraction(deployment lbdeploy.DeploymentID, pkg packageData) (tempfs.ExtractionDir, bool) {
+	extraction, found := state.resumedExtractions[pkg.ID]
+	if !found {
+		return tempfs.ExtractionDir{}, false
+	}
+	delete(state.resumedExtractions, pkg.ID)
+
+	dir, err := tempfs.ReopenExtractionDir(lbdeploy.PackageContent{
+		ID:          pkg.ID,
+		PrimaryHash: pkg.Definition.Attributes.Hashes.Primary(),
+	}, extraction.Dir, tempfs.Options{
+		DeleteOnClose: true,
+		Scope:         tempfs.Scope{Deployment: deployment, Run: extraction.Run},
+	})
+	if err != nil {
+		return tempfs.ExtractionDir{}, false
+	}
+	return dir, true
+}
+
+// End removes the checkpoint once the flow has finished.
+func (c *flowCheckpoint) End() {
+	if c == nil {
+		return
+	}
+	c.store.ClearCheckpoint(c.flow)
+}
+
+// InterruptedFlow returns the flow of the deployment that was most recently
+// interrupted on the local system, as recorded by its checkpoint. It
+// returns false if none of the deployment's flows were interrupted, or if
+// the flow has changed since it was interrupted.
+func InterruptedFlow(dep lbdeploy.Deployment) (lbdeploy.FlowID, bool) {
+	var (
+		interrupted lbdeploy.FlowID
+		latest      time.Time
+	)
+	for _, scope := range []lbdeploy.FrequencyScope{lbdeploy.FrequencyPerMachine, lbdeploy.FrequencyPerUser} {
+		store, err := openFlowState(dep.ID, scope)
+		if err != nil || !checkpointsTrusted(store, scope) {
+			continue
+		}
+		flow, checkpoint, found, err := store.Interrupted()
+		if err != nil || !found {
+			continue
+		}
+		definition, exists := dep.Flows[flow]
+		if !exists || !checkpoint.Matches(definition) || definition.Frequency.EffectiveScope() != scope {
+			continue
+		}
+		if interrupted == "" || checkpoint.Updated.After(latest) {
+			interrupted, latest = flow, checkpoint.Updated
+		}
+	}
+	return interrupted, interrupted != ""
+}
+
+// checkpointsTrusted returns true if the checkpoints in store can be
+// honored.
+//
+// Machine checkpoints skip actions that have already completed, so anyone
+// who can edit them can keep a flow from doing its work. They are only
+// honored if the machine state is trusted, as determined by
+// machineStateTrusted. User checkpoints can only affect the user's own
+// flows, and are always honored.
+func checkpointsTrusted(store lbstate.Store, scope lbdeploy.FrequencyScope) bool {
+	if scope != lbdeploy.FrequencyPerMachine {
+		return true
+	}
+	return machineStateTrusted(store.Path())
+}
@@ -0,0 +1,143 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+
+	"github.com/leafbridge/leafbridge/core/filehash"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+)
+
+// maxChecksumsFileSize is the maximum size of a checksums file or signature
+// that will be retrieved.
+const maxChecksumsFileSize = 1 << 20 // 1 MiB
+
+// resolveChecksums retrieves the expected hash of the package from its
+// checksums file, if it has one, and adds it to the package's file
+// attributes. The hash is retrieved once per invocation of the deployment.
+func (engine *packageEngine) resolveChecksums(ctx context.Context) error {
+	checksums := engine.pkg.Definition.Checksums
+	if checksums.IsZero() || len(engine.pkg.Definition.Attributes.Hashes) > 0 {
+		return nil
+	}
+
+	// Use a previously resolved hash if one is available.
+	if hashes, found := engine.state.resolvedHashes[engine.pkg.ID]; found {
+		engine.pkg.Definition.Attributes.Hashes = maps.Clone(hashes)
+		return nil
+	}
+
+	// When the deployment is invoked from a bundle, use the hashes that
+	// were resolved when the bundle was created. The checksums file and
+	// its signature were verified at that time.
+	if engine.state.bundle != nil {
+		entry, found := engine.state.bundle.Package(engine.pkg.ID)
+		if !found {
+			return fmt.Errorf("the \"%s\" package is not present in the bundle", engine.pkg.ID)
+		}
+		engine.pkg.Definition.Attributes.Hashes = maps.Clone(entry.Attributes.Hashes)
+		engine.state.resolvedHashes[engine.pkg.ID] = maps.Clone(entry.Attributes.Hashes)
+		return nil
+	}
+
+	entry, err := checksums.EntryFor(engine.pkg.Definition)
+	if err != nil {
+		return fmt.Errorf("failed to determine the checksums file entry for the \"%s\" package: %w", engine.pkg.ID, err)
+	}
+
+	hash, err := func() (filehash.Entry, error) {
+		// Retrieve the checksums file.
+		data, err := fetchSource(ctx, checksums.Source)
+		if err != nil {
+			return filehash.Entry{}, fmt.Errorf("failed to retrieve the checksums file: %w", err)
+		}
+
+		// Verify its signature, if one is required.
+		if !checksums.Signature.IsZero() {
+			signature, err := fetchSource(ctx, checksums.Signature.Source)
+			if err != nil {
+				return filehash.Entry{}, fmt.Errorf("failed to retrieve the checksums file signature: %w", err)
+			}
+			if err := checksums.Signature.Verify(data, signature); err != nil {
+				return filehash.Entry{}, err
+			}
+		}
+
+		// Find the entry for the package.
+		value, err := checksums.Lookup(data, entry)
+		if err != nil {
+			return filehash.Entry{}, err
+		}
+		return filehash.Entry{Type: checksums.HashTypeOrDefault(), Value: value}, nil
+	}()
+
+	// Record the outcome.
+	engine.events.Record(lbdeployevent.ChecksumsResolved{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Package:     engine.pkg.ID,
+		Source:      checksums.Source.Redacted(),
+		Entry:       entry,
+		Hash:        hash,
+		Signed:      !checksums.Signature.IsZero(),
+		Err:         err,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to resolve the checksum for the \"%s\" package: %w", engine.pkg.ID, err)
+	}
+
+	hashes := filehash.Map{hash.Type: hash.Value}
+	engine.state.resolvedHashes[engine.pkg.ID] = hashes
+	engine.pkg.Definition.Attributes.Hashes = maps.Clone(hashes)
+
+	return nil
+}
+
+// fetchSource retrieves a small file from a package source and returns its
+// content.
+func fetchSource(ctx context.Context, source lbdeploy.PackageSource) ([]byte, error) {
+	if source.Type != lbdeploy.PackageSourceHTTP {
+		return nil, fmt.Errorf("unrecognized source type: %s", source.Type)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", source.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareSourceRequest(req, source); err != nil {
+		return nil, err
+	}
+
+	client, release, err := sourceClient(source)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxChecksumsFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxChecksumsFileSize {
+		return nil, errors.New("the file exceeds the maximum size of 1 MiB")
+	}
+	return data, nil
+}
@@ -1,7 +1,6 @@
 package lbengine
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,17 +8,21 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
 	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
 	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbexpand"
+	"github.com/leafbridge/leafbridge/core/msi/msicmd"
 	"github.com/leafbridge/leafbridge/core/msi/msiresult"
+	"github.com/leafbridge/leafbridge/internal/logfile"
 	"github.com/leafbridge/leafbridge/internal/mergereader"
 	"github.com/leafbridge/leafbridge/platform/windows/localfs"
 	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
 	"github.com/leafbridge/leafbridge/platform/windows/tempfs"
-	"github.com/leafbridge/leafbridge/utility/bytesconv"
 )
 
 // commandData holds the ID and definition for a command.
@@ -76,7 +79,32 @@ func (engine *commandEngine) InvokeStandard(ctx context.Context) error {
 	}
 	execPath := filepath.Join(fileDir.Path(), localized)
 
-	return engine.invokePath(ctx, execPath)
+	// Make sure the executable satisfies any integrity requirements before
+	// it is run. Verified files remain locked until the command has
+	// finished.
+	var locked lockedFiles
+	defer locked.Release()
+	if err := engine.verifyIntegrity(fileID, execPath, &locked); err != nil {
+		return err
+	}
+
+	// Resolve any transforms that will be applied by the command, and make
+	// sure they satisfy their own integrity requirements.
+	transforms, err := engine.resolveTransforms(func(id lbdeploy.TransformID) (string, error) {
+		path, err := localFilePath(engine.deployment.Resources.FileSystem, lbdeploy.FileResourceID(id))
+		if err != nil {
+			return "", err
+		}
+		if err := engine.verifyIntegrity(lbdeploy.FileResourceID(id), path, &locked); err != nil {
+			return "", err
+		}
+		return path, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return engine.invokePath(ctx, execPath, transforms)
 }
 
 // InvokePackage runs the command on a package contained in dir.
@@ -97,7 +125,16 @@ func (engine *commandEngine) InvokePackage(ctx context.Context, dir stagingfs.Pa
 		return fmt.Errorf("an executable file path could not be prepared for %s: %w", engine.cmdDesc(), err)
 	}
 
-	return engine.invokePath(ctx, execPath)
+	// Resolve any transforms that will be applied by the command. Only
+	// embedded transforms are available to non-archive packages.
+	transforms, err := engine.resolveTransforms(func(id lbdeploy.TransformID) (string, error) {
+		return "", errors.New("transform files are only available to archive packages")
+	})
+	if err != nil {
+		return err
+	}
+
+	return engine.invokePath(ctx, execPath, transforms)
 }
 
 // InvokeArchive runs the command on a set of extracted archive package files.
@@ -124,7 +161,40 @@ func (engine *commandEngine) InvokeArchive(ctx context.Context, files tempfs.Ext
 		return fmt.Errorf("an executable file path could not be prepared for %s: %w", engine.cmdDesc(), err)
 	}
 
-	return engine.invokePath(ctx, execPath)
+	// Make sure the extracted executable still carries a trusted signature
+	// before it is run. Verified files remain locked until the command has
+	// finished.
+	var locked lockedFiles
+	defer locked.Release()
+	if err := engine.verifySignature(fileID, fileData, execPath, &locked); err != nil {
+		return err
+	}
+
+	// Resolve any transforms that will be applied by the command, and make
+	// sure they carry trusted signatures of their own.
+	transforms, err := engine.resolveTransforms(func(id lbdeploy.TransformID) (string, error) {
+		transformID := lbdeploy.PackageFileID(id)
+		transformData, exists := engine.pkg.Definition.Files[transformID]
+		if !exists {
+			return "", fmt.Errorf("the file is not defined in the \"%s\" package", engine.pkg.ID)
+		}
+		if _, err := files.Stat(transformData.Path); err != nil {
+			return "", err
+		}
+		path, err := files.FilePath(transformData.Path)
+		if err != nil {
+			return "", err
+		}
+		if err := engine.verifySignature(transformID, transformData, path, &locked); err != nil {
+			return "", err
+		}
+		return path, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return engine.invokePath(ctx, execPath, transforms)
 }
 
 // InvokeApp runs the command against an application's product code.
@@ -152,8 +222,14 @@ func (engine *commandEngine) InvokeApp(ctx context.Context) error {
 		return fmt.Errorf("%s refers to an application \"%s\" that does not have a product code", engine.cmdDesc(), app)
 	}
 
+	// Prepare a log file for the Windows Installer, if requested.
+	logPath, err := engine.createMSILog()
+	if err != nil {
+		return fmt.Errorf("a log file could not be prepared for %s: %w", engine.cmdDesc(), err)
+	}
+
 	// Prepare the command arguments.
-	args := engine.command.Definition.Args
+	var args []string
 
 	// Handle app-based command types.
 	//
@@ -161,10 +237,13 @@ func (engine *commandEngine) InvokeApp(ctx context.Context) error {
 	// https://learn.microsoft.com/en-us/windows/win32/api/msi/nf-msi-msiinstallproductw
 	switch engine.command.Definition.Type {
 	case lbdeploy.CommandTypeMSIUninstallProductCode:
-		args = append([]string{"/x", string(appData.ProductCode), "/quiet", "/norestart"}, args...)
+		args, err = engine.msiArgs(msicmd.Uninstall, string(appData.ProductCode), nil, logPath)
 	default:
 		return fmt.Errorf("%s uses a \"%s\" command type that is not recognized or is not suitable for app-based invocation", engine.cmdDesc(), engine.command.Definition.Type)
 	}
+	if err != nil {
+		return err
+	}
 
 	// If a working directory was specified, resolve it.
 	workingDir, err := engine.workingDirectory()
@@ -178,35 +257,52 @@ func (engine *commandEngine) InvokeApp(ctx context.Context) error {
 		return fmt.Errorf("failed to locate the Windows Installer executable: %w", err)
 	}
 
-	return engine.invoke(ctx, workingDir, execPath, args)
+	return engine.invoke(ctx, workingDir, execPath, args, logPath)
 }
 
-func (engine *commandEngine) invokePath(ctx context.Context, execPath string) (err error) {
+func (engine *commandEngine) invokePath(ctx context.Context, execPath string, transforms []string) (err error) {
 	// Determine a working directory for the command.
 	workingDir, err := engine.workingDirectoryForExecutable(execPath)
 	if err != nil {
 		return fmt.Errorf("a working directory could not be determined for %s: %w", engine.cmdDesc(), err)
 	}
 
+	// Run regular executables directly.
+	switch engine.command.Definition.Type {
+	case lbdeploy.CommandTypeExe, "":
+		args, err := engine.args()
+		if err != nil {
+			return err
+		}
+		return engine.invoke(ctx, workingDir, execPath, args, "")
+	}
+
+	// Prepare a log file for the Windows Installer, if requested.
+	logPath, err := engine.createMSILog()
+	if err != nil {
+		return fmt.Errorf("a log file could not be prepared for %s: %w", engine.cmdDesc(), err)
+	}
+
 	// Prepare the command arguments.
-	args := engine.command.Definition.Args
+	var args []string
 
 	// Special handling for use of msiexec.
 	//
 	// TODO: Switch to the Microsoft Installer API:
 	// https://learn.microsoft.com/en-us/windows/win32/api/msi/nf-msi-msiinstallproductw
 	switch engine.command.Definition.Type {
-	case lbdeploy.CommandTypeExe, "":
-		return engine.invoke(ctx, workingDir, execPath, args)
 	case lbdeploy.CommandTypeMSIInstall:
-		args = append([]string{"/i", execPath, "/quiet", "/norestart"}, args...)
+		args, err = engine.msiArgs(msicmd.Install, execPath, transforms, logPath)
 	case lbdeploy.CommandTypeMSIUpdate:
-		args = append([]string{"/update", execPath, "/quiet", "/norestart"}, args...)
+		args, err = engine.msiArgs(msicmd.Update, execPath, nil, logPath)
 	case lbdeploy.CommandTypeMSIUninstall:
-		args = append([]string{"/x", execPath, "/quiet", "/norestart"}, args...)
+		args, err = engine.msiArgs(msicmd.Uninstall, execPath, nil, logPath)
 	default:
 		return fmt.Errorf("an unknown command type was specified: %s", engine.command.Definition.Type)
 	}
+	if err != nil {
+		return err
+	}
 
 	// Find the msiexec executable.
 	execPath, err = exec.LookPath("msiexec.exe")
@@ -214,21 +310,48 @@ func (engine *commandEngine) invokePath(ctx context.Context, execPath string) (e
 		return fmt.Errorf("failed to locate the Windows Installer executable: %w", err)
 	}
 
-	return engine.invoke(ctx, workingDir, execPath, args)
+	return engine.invoke(ctx, workingDir, execPath, args, logPath)
 }
 
-func (engine *commandEngine) invoke(ctx context.Context, workingDir, execPath string, args []string) (err error) {
+func (engine *commandEngine) invoke(ctx context.Context, workingDir, execPath string, args []string, logPath string) (err error) {
 	// Check for cancellation before starting the command.
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
+	// Wait for any Windows Installer operation that is in progress to
+	// finish before starting an msi-based command.
+	if engine.command.Definition.Type.IsMSI() {
+		if err := engine.waitForInstaller(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Allow the command's window watchdog to terminate it.
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
 	// Prepare a command that will be terminated when ctx is cancelled.
 	cmd := exec.CommandContext(ctx, execPath, args...)
 
+	// The Windows Installer parses its own command line, and it doesn't
+	// understand the escaping that the exec package applies to arguments.
+	// Provide it with a command line that has already been prepared for it.
+	if engine.command.Definition.Type.IsMSI() {
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			CmdLine: msicmd.CommandLine(execPath, args...),
+		}
+	}
+
 	// Set the command's working directory.
 	cmd.Dir = workingDir
 
+	// Prepare the command's environment.
+	cmd.Env, err = buildEnvironment(ctx, engine.deployment, engine.command.Definition.Environment)
+	if err != nil {
+		return fmt.Errorf("the environment for %s could not be prepared: %w", engine.cmdDesc(), err)
+	}
+
 	// Configure the command to wait up to one minute for the command to close
 	// out gracefully when its context is cancelled.
 	//
@@ -254,24 +377,58 @@ func (engine *commandEngine) invoke(ctx context.Context, workingDir, execPath st
 		ActionType:           engine.action.Definition.Type,
 		Package:              engine.pkg.ID,
 		Command:              engine.command.ID,
-		CommandLine:          cmd.String(),
+		CommandLine:          commandLine(cmd),
 		WorkingDirectory:     engine.command.Definition.WorkingDirectory,
 		WorkingDirectoryPath: workingDir,
+		LogPath:              logPath,
 		Apps:                 engine.apps,
 	})
 
 	// Prepare a buffer to hold the combined command output.
-	var output bytes.Buffer
+	output := newOutputBuffer(engine.command.Definition.Output, engine.command.Definition.OutputEncoding)
+
+	// If requested, write the complete output to a file.
+	var outputPath string
+	if id := engine.command.Definition.Output.File; id != "" {
+		file, err := createOutputFile(engine.deployment.Resources.FileSystem, id)
+		if err != nil {
+			return fmt.Errorf("the output file for %s could not be created: %w", engine.cmdDesc(), err)
+		}
+		defer file.Close()
+		output.Tee(file)
+		outputPath = file.Name()
+	}
+
+	// Identify the applications using the files that the command will
+	// replace, shutting them down if requested. They'll be restarted
+	// after the command has finished.
+	finishRestartManager := engine.startRestartManager(ctx)
+	defer finishRestartManager()
 
 	// Record the time that the command started.
 	started := time.Now()
 
+	// Track the process tree of the command, so that all of its processes
+	// can be terminated if it is cancelled.
+	tree, err := newProcessTree(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to prepare a job object for %s: %w", engine.cmdDesc(), err)
+	}
+	defer tree.Close()
+
 	// Start the command.
 	err = cmd.Start()
 
 	// If the command started successfully, send its output to stdout and
 	// stderr as well as the output buffer, then wait for it to finish.
 	if err == nil {
+		// Attach the command to its process tree. If this fails, cancellation
+		// will only terminate the command's own process.
+		tree.Attach(cmd)
+
+		// Monitor the windows opened by the command, if requested.
+		stopWatchdog := engine.watchWindows(ctx, tree, cancel)
+
 		// Tee stdout and stderr to the console.
 		r1 := io.TeeReader(stdout, os.Stdout)
 		r2 := io.TeeReader(stderr, os.Stderr)
@@ -280,18 +437,51 @@ func (engine *commandEngine) invoke(ctx context.Context, workingDir, execPath st
 		merged := mergereader.New(r1, r2)
 
 		// Read the combined output from the command.
-		io.Copy(&output, merged)
+		io.Copy(output, merged)
 
 		// Wait for the command to be completed.
 		err = cmd.Wait()
+		stopWatchdog()
 	}
 
 	// Record the time that the command stopped.
 	stopped := time.Now()
 
+	// If the command was cancelled, record the processes that were
+	// terminated.
+	if pids, termErr := tree.Terminated(); pids != nil || termErr != nil {
+		engine.events.Record(lbdeployevent.ProcessTreeTerminated{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Package:     engine.pkg.ID,
+			Command:     engine.command.ID,
+			ProcessIDs:  pids,
+			Reason:      cancellationReason(ctx),
+			Err:         termErr,
+		})
+	}
+
 	// Analyze the exit code of the command.
 	result, err := engine.buildResult(err)
 
+	// If the command was terminated by its window watchdog, report that
+	// instead of its exit code.
+	var windowErr WindowError
+	if errors.As(context.Cause(ctx), &windowErr) {
+		err = fmt.Errorf("%s was stopped by its window watchdog: %w", engine.cmdDesc(), windowErr)
+	}
+
+	// If the command was terminated because its action or flow exceeded its
+	// timeout, report that instead of its exit code.
+	var timeout time.Duration
+	var timeoutErr TimeoutError
+	if err != nil && errors.As(context.Cause(ctx), &timeoutErr) {
+		err = fmt.Errorf("%s was stopped because %w", engine.cmdDesc(), timeoutErr)
+		timeout = timeoutErr.Timeout
+	}
+
 	// Special handling for some exit codes returned by msiexec.
 	switch engine.command.Definition.Type {
 	case lbdeploy.CommandTypeMSIUninstall, lbdeploy.CommandTypeMSIUninstallProductCode:
@@ -302,6 +492,15 @@ func (engine *commandEngine) invoke(ctx context.Context, workingDir, execPath st
 		}
 	}
 
+	// If the command failed and it produced a log, collect the end of the
+	// log when requested.
+	var logTail string
+	if err != nil && logPath != "" {
+		if lines := engine.command.Definition.MSI.Log.TailLines; lines > 0 {
+			logTail, _ = logfile.ReadTail(logPath, lines)
+		}
+	}
+
 	// Evaluate the effectiveness of any expected application changes.
 	ae := NewAppEngine(engine.deployment)
 	appSummary, appSummaryErr := ae.SummarizeAppChanges(engine.apps)
@@ -320,15 +519,20 @@ func (engine *commandEngine) invoke(ctx context.Context, workingDir, execPath st
 		ActionType:           engine.action.Definition.Type,
 		Package:              engine.pkg.ID,
 		Command:              engine.command.ID,
-		CommandLine:          cmd.String(),
+		CommandLine:          commandLine(cmd),
 		Result:               result,
-		Output:               bytesconv.DecodeString(output.Bytes()),
+		Output:               output.String(),
+		OutputDiscarded:      output.Discarded(),
+		OutputPath:           outputPath,
 		WorkingDirectory:     engine.command.Definition.WorkingDirectory,
 		WorkingDirectoryPath: workingDir,
+		LogPath:              logPath,
+		LogTail:              logTail,
 		AppsBefore:           engine.apps,
 		AppsAfter:            appSummary,
 		Started:              started,
 		Stopped:              stopped,
+		Timeout:              timeout,
 		Err:                  err,
 	})
 
@@ -358,6 +562,146 @@ func (engine *commandEngine) invoke(ctx context.Context, workingDir, execPath st
 	return appSummary.Err()
 }
 
+// verifyIntegrity checks the integrity requirements of the given file and
+// records the result. Files without requirements are not checked.
+//
+// The file is verified through locked, which must not be released until the
+// command has finished.
+func (engine *commandEngine) verifyIntegrity(fileID lbdeploy.FileResourceID, path string, locked *lockedFiles) error {
+	integrity := engine.deployment.Resources.FileSystem.Files[fileID].Integrity
+	if integrity.IsZero() {
+		return nil
+	}
+
+	actual, signer, err := locked.Verify(path, integrity)
+	if err != nil {
+		err = fmt.Errorf("the file \"%s\" for %s failed its integrity check: %w", fileID, engine.cmdDesc(), err)
+	}
+
+	engine.events.Record(lbdeployevent.FileIntegrity{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		FileID:      fileID,
+		FilePath:    path,
+		Required:    integrity,
+		Actual:      actual,
+		Signer:      signer.Subject,
+		Thumbprint:  signer.Thumbprint,
+		Err:         err,
+	})
+
+	return err
+}
+
+// verifySignature checks the signature requirements of the given package
+// file, which has been extracted to path, and records the result. Files
+// without requirements are not checked.
+//
+// The file is verified through locked, which must not be released until the
+// command has finished.
+func (engine *commandEngine) verifySignature(fileID lbdeploy.PackageFileID, file lbdeploy.PackageFile, path string, locked *lockedFiles) error {
+	if !file.Signature.Required {
+		return nil
+	}
+
+	_, signer, err := locked.Verify(path, lbdeploy.FileIntegrity{Signature: file.Signature})
+	if err != nil {
+		err = fmt.Errorf("the file \"%s\" for %s failed its signature check: %w", fileID, engine.cmdDesc(), err)
+	}
+
+	engine.events.Record(lbdeployevent.SignatureCheck{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Package:     engine.pkg.ID,
+		File:        fileID,
+		Path:        path,
+		Signer:      signer.Subject,
+		Thumbprint:  signer.Thumbprint,
+		Err:         err,
+	})
+
+	return err
+}
+
+// msiArgs returns a set of msiexec arguments that apply the given operation
+// to target. If logPath is not empty, a verbose log will be written to it.
+// The returned arguments have already been quoted for msiexec.
+//
+// The arguments are generated from the Windows Installer options for the
+// command, followed by any additional arguments provided by the command.
+// Placeholders within property values and arguments are expanded.
+func (engine *commandEngine) msiArgs(operation msicmd.Operation, target string, transforms []string, logPath string) ([]string, error) {
+	opts := engine.command.Definition.MSI
+	inv := msicmd.Invocation{
+		Operation:      operation,
+		Target:         target,
+		UI:             opts.UILevel.Flag(),
+		Restart:        msicmd.Restart(opts.Restart.Flag()),
+		SuppressReboot: opts.Restart.ReallySuppress(),
+		LogPath:        logPath,
+		Transforms:     transforms,
+	}
+	resolve := placeholderResolver(engine.deployment)
+	for _, name := range opts.Properties.Names() {
+		value, err := lbexpand.Expand(opts.Properties[name], resolve)
+		if err != nil {
+			return nil, fmt.Errorf("the \"%s\" property for %s could not be prepared: %w", name, engine.cmdDesc(), err)
+		}
+		inv.Properties = append(inv.Properties, msicmd.PropertyValue{Name: name, Value: value})
+	}
+	extra, err := engine.args()
+	if err != nil {
+		return nil, err
+	}
+	inv.Extra = extra
+	return inv.Args(), nil
+}
+
+// args returns the arguments provided by the command, with placeholders
+// expanded.
+func (engine *commandEngine) args() ([]string, error) {
+	return expandArgs(engine.deployment, engine.command.Definition.Args)
+}
+
+// resolveTransforms returns the paths of the transforms that will be applied
+// by the command. Embedded transforms are returned as-is. All other
+// transforms are resolved by calling resolve.
+func (engine *commandEngine) resolveTransforms(resolve func(lbdeploy.TransformID) (string, error)) ([]string, error) {
+	transforms := engine.command.Definition.MSI.Transforms
+	if len(transforms) == 0 {
+		return nil, nil
+	}
+
+	paths := make([]string, 0, len(transforms))
+	for _, transform := range transforms {
+		if transform.IsEmbedded() {
+			paths = append(paths, string(transform))
+			continue
+		}
+		path, err := resolve(transform)
+		if err != nil {
+			return nil, fmt.Errorf("%s refers to a transform \"%s\" that could not be resolved: %w", engine.cmdDesc(), transform, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// commandLine returns a string representation of cmd's command line. If a
+// command line has been provided to the operating system verbatim, that
+// command line is returned.
+func commandLine(cmd *exec.Cmd) string {
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.CmdLine != "" {
+		return cmd.SysProcAttr.CmdLine
+	}
+	return cmd.String()
+}
+
 // cmdDesc returns a string describing the command. It is used to build
 // error messages.
 func (engine *commandEngine) cmdDesc() string {
@@ -397,22 +741,19 @@ func (engine *commandEngine) workingDirectory() (path string, err error) {
 		return "", nil
 	}
 
-	resolver := localfs.NewResolver(engine.deployment.Resources.FileSystem)
-	dirRef, err := resolver.ResolveDirectory(dirID)
-	if err != nil {
-		return "", err
-	}
-
-	dir, err := localfs.OpenDir(dirRef)
-	if err != nil {
-		return "", err
-	}
-	defer dir.Close()
-
-	return dir.Path(), nil
+	return localDirPath(engine.deployment.Resources.FileSystem, dirID)
 }
 
 func (engine *commandEngine) buildResult(cmdError error) (result lbdeploy.CommandResult, err error) {
+	return buildCommandResult(cmdError, engine.command.Definition.ExitCodes, engine.command.Definition.Type.IsMSI())
+}
+
+// buildCommandResult examines the error returned by a command and
+// interprets its exit code. The exit code is looked up in exitCodes, and if
+// msi is true, in the set of exit codes that are well known for msiexec.
+//
+// If the exit code is known to be successful, a nil error is returned.
+func buildCommandResult(cmdError error, exitCodes lbdeploy.ExitCodeMap, msi bool) (result lbdeploy.CommandResult, err error) {
 	// If the command returned an error, examine it.
 	if cmdError != nil {
 		// Assume that any error returned by cmd.Wait() is a real error,
@@ -438,15 +779,17 @@ func (engine *commandEngine) buildResult(cmdError error) (result lbdeploy.Comman
 			return
 		}
 
-		// Record the exit code returned by the command.
+		// Record the exit code returned by the command. From here on, a
+		// failure is attributed to the installer.
 		result.ExitCode = lbdeploy.ExitCode(exitErr.ExitCode())
+		err = lberror.Wrap(lberror.Installer, cmdError)
 	} else {
 		// The command returned an exit code of zero.
 		result.ExitCode = 0
 	}
 
 	// Attempt to look up the error code information in the command.
-	if info, found := engine.command.Definition.ExitCodes[result.ExitCode]; found {
+	if info, found := exitCodes[result.ExitCode]; found {
 		result.Info = info
 		if info.OK {
 			err = nil
@@ -456,7 +799,7 @@ func (engine *commandEngine) buildResult(cmdError error) (result lbdeploy.Comman
 
 	// If this is an msiexec command, look for an exit code that is well
 	// known.
-	if engine.command.Definition.Type.IsMSI() {
+	if msi {
 		code := msiresult.ExitCode(result.ExitCode)
 		if info, found := msiresult.InfoMap[code]; found {
 			result.Info = info
@@ -0,0 +1,17 @@
+package lbengine
+
+import (
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbeval"
+	"github.com/leafbridge/leafbridge/platform/windows/winplatform"
+)
+
+// ComplianceEngine is responsible for evaluating the desired state of a
+// deployment on the local system.
+type ComplianceEngine = lbeval.ComplianceEngine
+
+// NewComplianceEngine prepares a compliance engine for the given
+// deployment.
+func NewComplianceEngine(dep lbdeploy.Deployment) ComplianceEngine {
+	return lbeval.NewComplianceEngine(dep, winplatform.New())
+}
@@ -2,50 +2,117 @@ package lbengine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
 	"github.com/leafbridge/leafbridge/core/lbevent"
 )
 
+// ErrDeploymentTimeout is returned when a deployment is stopped because it
+// exceeded its timeout.
+var ErrDeploymentTimeout = lberror.New(lberror.Timeout, "the deployment exceeded its timeout")
+
+// ErrDeploymentCancelled is returned when a deployment is stopped because
+// its context was cancelled.
+var ErrDeploymentCancelled = lberror.New(lberror.Cancelled, "the deployment was cancelled")
+
 // DeploymentEngine is a LeafBridge engine that is responsible for invocation
 // of deployments.
 type DeploymentEngine struct {
 	deployment lbdeploy.Deployment
 	events     lbevent.Recorder
 	force      bool
+	timeout    time.Duration
+	arch       lbdeploy.Architecture
 	state      *engineState
 }
 
 // NewDeploymentEngine returns a new LeafBridge deployment engine for the
 // given deployment and options.
 func NewDeploymentEngine(deployment lbdeploy.Deployment, opts Options) DeploymentEngine {
+	state := newEngineState()
+	state.bundle = opts.Bundle
+	state.agent = opts.Agent
+	state.reverify = opts.Reverify
+	state.resume = opts.Resume
+	state.traceConditions = opts.TraceConditions
 	return DeploymentEngine{
 		deployment: deployment,
 		events:     opts.Events,
 		force:      opts.Force,
-		state:      newEngineState(),
+		timeout:    opts.Timeout,
+		arch:       opts.Architecture,
+		state:      state,
 	}
 }
 
 // Invoke executes a flow within a LeafBridge deployment.
 func (engine DeploymentEngine) Invoke(ctx context.Context, flow lbdeploy.FlowID) error {
-	// TODO: Generate some sort of random UUID for the deployment invocation
-	// that can be used for log analysis?
+	// Assign a unique run ID to this invocation, along with the name of the
+	// machine, so that its events can be correlated during log analysis.
+	events := engine.events
+	if events.Origin.RunID == "" {
+		events.Origin.RunID = lbevent.NewRunID()
+	}
+	if events.Origin.Machine == "" {
+		events.Origin.Machine, _ = os.Hostname()
+	}
+	engine.events = events
 
 	// Ensure that the deployment is valid.
 	if err := engine.deployment.Validate(); err != nil {
 		return err
 	}
 
+	// Select the packages that suit the architecture of the system.
+	dep, _, err := engine.selectArchitecture()
+	if err != nil {
+		return err
+	}
+	engine.deployment = dep
+
 	// Find the requested flow within the deployment.
 	definition, found := engine.deployment.Flows[flow]
 	if !found {
 		return fmt.Errorf("the flow \"%s\" does not exist within the \"%s\" deployment", flow, engine.deployment.ID)
 	}
 
-	// Release resources when we are finished.
+	// Refuse to run outside of the deployment's validity window.
+	if err := checkValidity(engine.events, engine.deployment.ID, flow, engine.deployment.Validity(), false); err != nil {
+		return err
+	}
+
+	// Make sure that the execution policy of the machine permits the
+	// deployment before anything is started.
+	if err := engine.checkPolicy(flow); err != nil {
+		return err
+	}
+
+	// If the deployment has a timeout, enforce it. When the timeout is
+	// exceeded the context is cancelled, which terminates any processes
+	// that are running.
+	timeout := engine.timeout
+	if timeout == 0 {
+		timeout = time.Duration(engine.deployment.Timeout)
+	}
+	started := time.Now()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrDeploymentTimeout)
+		defer cancel()
+	}
+
+	// Release resources when we are finished. This happens even when the
+	// deployment has been cancelled, so the cleanup is not subject to
+	// cancellation.
 	defer func() {
+		ctx := context.WithoutCancel(ctx)
+
 		// Close and remove any extracted files in temporary directories.
 		for packageID, extractedFiles := range engine.state.extractedPackages {
 			extractedFiles.Close()
@@ -60,6 +127,9 @@ func (engine DeploymentEngine) Invoke(ctx context.Context, flow lbdeploy.FlowID)
 
 		// Release and close all locks.
 		engine.state.locks.CloseAll()
+
+		// Remove any temporary antivirus exclusions.
+		removeAntivirusExclusions(ctx, engine.deployment, engine.events, engine.state)
 	}()
 
 	// Invoke the requested flow.
@@ -74,5 +144,31 @@ func (engine DeploymentEngine) Invoke(ctx context.Context, flow lbdeploy.FlowID)
 		state:  engine.state,
 	}
 
-	return fe.Invoke(ctx)
+	err = fe.Invoke(ctx)
+
+	// If the deployment timed out, record it and report it to the caller.
+	if timeout > 0 && errors.Is(context.Cause(ctx), ErrDeploymentTimeout) {
+		engine.events.Record(lbdeployevent.DeploymentTimeout{
+			Deployment: engine.deployment.ID,
+			Flow:       flow,
+			Timeout:    timeout,
+			Started:    started,
+			Stopped:    time.Now(),
+		})
+		return fmt.Errorf("the \"%s\" deployment was stopped after %s: %w", engine.deployment.ID, timeout, ErrDeploymentTimeout)
+	}
+
+	// If the deployment was cancelled, record it and report it to the
+	// caller as a cancellation rather than a failure.
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		engine.events.Record(lbdeployevent.DeploymentCancelled{
+			Deployment: engine.deployment.ID,
+			Flow:       flow,
+			Started:    started,
+			Stopped:    time.Now(),
+		})
+		return fmt.Errorf("the \"%s\" deployment was stopped: %w", engine.deployment.ID, ErrDeploymentCancelled)
+	}
+
+	return err
 }
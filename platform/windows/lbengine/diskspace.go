@@ -0,0 +1,47 @@
+package lbengine
+
+import (
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/windows/diskspace"
+)
+
+// InsufficientDiskSpaceError is returned when a volume does not have enough
+// free space for a download or extraction.
+type InsufficientDiskSpaceError struct {
+	Path      string
+	Available int64
+	Required  int64
+}
+
+// Error returns a description of the error.
+func (err InsufficientDiskSpaceError) Error() string {
+	return fmt.Sprintf("insufficient disk space: the volume holding \"%s\" has %d bytes available, but %d bytes are required", err.Path, err.Available, err.Required)
+}
+
+// checkDiskSpace verifies that the volume holding dir has enough free space
+// to write size bytes, with the safety factor of [lbdeploy.RequiredDiskSpace]
+// applied. It returns an
+// [InsufficientDiskSpaceError] if it does not.
+func checkDiskSpace(dir string, size int64) error {
+	if size <= 0 {
+		return nil
+	}
+
+	available, err := diskspace.Available(dir)
+	if err != nil {
+		return fmt.Errorf("failed to determine the free disk space for \"%s\": %w", dir, err)
+	}
+
+	required := lbdeploy.RequiredDiskSpace(size)
+	if available < required {
+		return InsufficientDiskSpaceError{
+			Path:      dir,
+			Available: available,
+			Required:  required,
+		}
+	}
+
+	return nil
+}
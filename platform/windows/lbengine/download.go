@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"time"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
 	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
 	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/internal/httpcache"
 	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
 )
 
@@ -40,6 +43,30 @@ func (engine *downloadEngine) DownloadAndVerifyPackage(ctx context.Context, pkg
 		return errors.New("packages must provide at least one file hash for verification")
 	}
 
+	// Unless a fresh check was requested, trust the verification record
+	// of an earlier run if it still describes the staged file. This avoids
+	// hashing large files again on every run. Packages that were verified
+	// by an interrupted run that is being resumed are not verified again.
+	record, _ := file.ReadVerification()
+	if !engine.state.reverify || engine.state.resumedVerified.Contains(pkg.ID) {
+		if fi, err := file.Stat(); err == nil && record.Matches(fi, pkg.Definition.Attributes) {
+			engine.events.Record(lbdeployevent.FileVerification{
+				Deployment:  engine.deployment.ID,
+				Flow:        engine.flow.ID,
+				ActionIndex: engine.action.Index,
+				ActionType:  engine.action.Definition.Type,
+				FileName:    file.Name,
+				Path:        file.Path,
+				Expected:    pkg.Definition.Attributes,
+				Actual:      record.Attributes,
+				Cached:      true,
+				Verified:    record.Verified,
+			})
+			engine.revalidatePackage(ctx, pkg, file)
+			return nil
+		}
+	}
+
 	// Move to the beginning of the file.
 	file.Seek(0, io.SeekStart)
 
@@ -52,7 +79,13 @@ func (engine *downloadEngine) DownloadAndVerifyPackage(ctx context.Context, pkg
 	// If the file has already been filled with the expected number of
 	// bytes, or if it is larger than expected, treat it as a completed
 	// download and go immediately to the verification process.
-	if existingFileAttributes := verifier.State(); existingFileAttributes.Size >= pkg.Definition.Attributes.Size {
+	//
+	// If the expected size is not known, which is the case for packages
+	// that rely on a checksums file, any existing content is verified. It
+	// can't be resumed, because there's no way to tell whether it is
+	// complete.
+	expectedSize := pkg.Definition.Attributes.Size
+	if existingFileAttributes := verifier.State(); existingFileAttributes.Size >= expectedSize && (expectedSize > 0 || existingFileAttributes.Size > 0) {
 		// Record the file verification result.
 		engine.events.Record(lbdeployevent.FileVerification{
 			Deployment:  engine.deployment.ID,
@@ -67,15 +100,20 @@ func (engine *downloadEngine) DownloadAndVerifyPackage(ctx context.Context, pkg
 
 		// Verify the existing file by testing whether its attributes match
 		// what was expected.
-		if lbdeploy.EqualFileAttributes(pkg.Definition.Attributes, existingFileAttributes) {
+		if lbdeploy.MatchFileAttributes(pkg.Definition.Attributes, existingFileAttributes) {
 			// The file attributes match what was expected.
-			// Verification is complete and we're done.
+			// Verification is complete. Record the result for later
+			// runs. If cache validators were recorded when the file was
+			// downloaded, revalidate the cached copy with its source
+			// before we're done.
+			file.WriteVerification(existingFileAttributes, record.Source, time.Now())
+			engine.revalidatePackage(ctx, pkg, file)
 			return nil
 		}
 
 		// The file failed verification. Truncate it and try again.
 		var reason lbdeployevent.DownloadResetReason
-		if existingFileAttributes.Size > pkg.Definition.Attributes.Size {
+		if expectedSize > 0 && existingFileAttributes.Size > expectedSize {
 			reason = lbdeployevent.ExistingFileTooLarge
 		} else {
 			reason = lbdeployevent.ExistingFileVerificationFailed
@@ -85,25 +123,44 @@ func (engine *downloadEngine) DownloadAndVerifyPackage(ctx context.Context, pkg
 		}
 	}
 
-	// Verify that at least one source has been specified.
-	if len(pkg.Definition.Sources) == 0 {
+	// Verify that at least one source has been specified, unless the
+	// package will be copied from a bundle.
+	if len(pkg.Definition.Sources) == 0 && engine.state.bundle == nil {
 		return errors.New("no sources were provided for the package")
 	}
 
+	// Verify that the staging volume has room for the rest of the package
+	// before any data is downloaded.
+	if err := engine.checkDiskSpace(file, pkg.Definition.Attributes.Size-verifier.Size()); err != nil {
+		return err
+	}
+
 	// Start or resume the download. Attempt the download up to two times.
 	for attempt := 0; attempt < 2; attempt++ {
 		var (
 			errs   []error
 			source lbdeploy.PackageSource
 		)
-		for _, candidate := range pkg.Definition.Sources {
-			err := engine.downloadPackageFromSource(ctx, candidate, file, verifier)
+		if engine.state.bundle != nil {
+			// When the deployment is invoked from a bundle, the package
+			// is copied from the bundle and its sources are never
+			// contacted.
+			candidate, err := engine.copyPackageFromBundle(ctx, pkg, file, verifier)
 			if err == nil {
-				// The download completed successfully.
 				source = candidate
-				break
+			} else {
+				errs = append(errs, err)
+			}
+		} else {
+			for _, candidate := range pkg.Definition.Sources {
+				err := engine.downloadPackageFromSourceWithRetry(ctx, pkg.Definition.Retry, candidate, file, verifier)
+				if err == nil {
+					// The download completed successfully.
+					source = candidate
+					break
+				}
+				errs = append(errs, err)
 			}
-			errs = append(errs, err)
 		}
 
 		// If the download failed, so we stop.
@@ -122,7 +179,7 @@ func (engine *downloadEngine) DownloadAndVerifyPackage(ctx context.Context, pkg
 			Flow:        engine.flow.ID,
 			ActionIndex: engine.action.Index,
 			ActionType:  engine.action.Definition.Type,
-			Source:      source,
+			Source:      source.Redacted(),
 			FileName:    file.Name,
 			Path:        file.Path,
 			Expected:    pkg.Definition.Attributes,
@@ -131,9 +188,11 @@ func (engine *downloadEngine) DownloadAndVerifyPackage(ctx context.Context, pkg
 
 		// Verify the downloaded file by testing whether its attributes match
 		// what was expected.
-		if lbdeploy.EqualFileAttributes(pkg.Definition.Attributes, downloadedFileAttributes) {
+		if lbdeploy.MatchFileAttributes(pkg.Definition.Attributes, downloadedFileAttributes) {
 			// The file attributes match what was expected.
-			// Verification is complete and we're done.
+			// Verification is complete. Record the result for later
+			// runs and we're done. A failure to record it is not fatal.
+			file.WriteVerification(downloadedFileAttributes, source.Redacted().URL, time.Now())
 			return nil
 		}
 
@@ -147,7 +206,41 @@ func (engine *downloadEngine) DownloadAndVerifyPackage(ctx context.Context, pkg
 
 	// We've exhausted the maximum number of retries, but still failed to
 	// produce a downloaded package with the expected file attributes.
-	return errors.New("the downloaded package did not pass its file verification checks")
+	return lberror.New(lberror.Verification, "the downloaded package did not pass its file verification checks")
+}
+
+// downloadPackageFromSourceWithRetry attempts to download a package from
+// the given source. Attempts that fail with transient errors are retried
+// according to the retry policy. Each retry resumes the download where the
+// previous attempt left off.
+func (engine *downloadEngine) downloadPackageFromSourceWithRetry(ctx context.Context, policy lbdeploy.RetryPolicy, source lbdeploy.PackageSource, file stagingfs.PackageFile, verifier *FileVerifier) error {
+	policy = policy.WithDefaults()
+
+	for attempt := 1; ; attempt++ {
+		err := engine.downloadPackageFromSource(ctx, source, file, verifier)
+		if err == nil || attempt >= policy.Attempts || ctx.Err() != nil || !isTransientError(err) {
+			return err
+		}
+
+		// Record the failed attempt and wait before trying again.
+		delay := policy.Delay(attempt)
+		engine.events.Record(lbdeployevent.DownloadRetry{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Source:      source.Redacted(),
+			FileName:    file.Name,
+			Attempt:     attempt,
+			MaxAttempts: policy.Attempts,
+			Delay:       delay,
+			Err:         err,
+		})
+
+		if err := sleepWithContext(ctx, delay); err != nil {
+			return err
+		}
+	}
 }
 
 func (engine *downloadEngine) downloadPackageFromSource(ctx context.Context, source lbdeploy.PackageSource, file stagingfs.PackageFile, verifier *FileVerifier) (err error) {
@@ -160,16 +253,32 @@ func (engine *downloadEngine) downloadPackageFromSource(ctx context.Context, sou
 
 	// Prepare an HTTP request. If offset is greater than zero, include a
 	// range header.
+	//
+	// When resuming, also include any cache validators that were recorded
+	// for the partial file, so that the server will send the entire file
+	// if it has changed since the download started.
 	req, err := http.NewRequestWithContext(ctx, "GET", source.URL, nil)
 	if err != nil {
 		return err
 	}
+	if err := prepareSourceRequest(req, source); err != nil {
+		return err
+	}
 	if offset > 0 {
 		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validators, err := file.ReadValidators(); err == nil && validators.URL == source.URL {
+			validators.SetIfRange(req)
+		}
 	}
 
 	// Make the HTTP request.
-	resp, err := http.DefaultClient.Do(req)
+	client, release, err := sourceClient(source)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -191,7 +300,7 @@ func (engine *downloadEngine) downloadPackageFromSource(ctx context.Context, sou
 		// This indicates that the range header was accepted and the download
 		// can be resumed.
 	default:
-		return fmt.Errorf("the server returned an unexpected status code: %s", resp.Status)
+		return httpStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
 	// Record the start of the download.
@@ -200,7 +309,7 @@ func (engine *downloadEngine) downloadPackageFromSource(ctx context.Context, sou
 		Flow:        engine.flow.ID,
 		ActionIndex: engine.action.Index,
 		ActionType:  engine.action.Definition.Type,
-		Source:      source,
+		Source:      source.Redacted(),
 		FileName:    file.Name,
 		Path:        file.Path,
 		Offset:      offset,
@@ -238,13 +347,20 @@ func (engine *downloadEngine) downloadPackageFromSource(ctx context.Context, sou
 	// Record the time that the download stopped.
 	stopped := time.Now()
 
+	// If the download completed, record the cache validators that were
+	// returned by the server, so that the cached file can be revalidated
+	// or resumed later. A failure to record them is not fatal.
+	if err == nil {
+		file.WriteValidators(httpcache.FromResponse(source.URL, resp))
+	}
+
 	// Record the end of the download.
 	engine.events.Record(lbdeployevent.DownloadStopped{
 		Deployment:  engine.deployment.ID,
 		Flow:        engine.flow.ID,
 		ActionIndex: engine.action.Index,
 		ActionType:  engine.action.Definition.Type,
-		Source:      source,
+		Source:      source.Redacted(),
 		FileName:    file.Name,
 		Path:        file.Path,
 		Downloaded:  downloaded,
@@ -257,6 +373,119 @@ func (engine *downloadEngine) downloadPackageFromSource(ctx context.Context, sou
 	return err
 }
 
+// checkDiskSpace verifies that the volume holding the file has room for
+// the given number of additional bytes. If it does not, an event is
+// recorded and an error is returned.
+func (engine *downloadEngine) checkDiskSpace(file stagingfs.PackageFile, remaining int64) error {
+	err := checkDiskSpace(filepath.Dir(file.Path), remaining)
+
+	var spaceErr InsufficientDiskSpaceError
+	if errors.As(err, &spaceErr) {
+		engine.events.Record(lbdeployevent.DiskSpaceInsufficient{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Operation:   lbdeployevent.DiskSpaceForDownload,
+			FileName:    file.Name,
+			Path:        spaceErr.Path,
+			Available:   spaceErr.Available,
+			Required:    spaceErr.Required,
+		})
+	}
+
+	return err
+}
+
+// revalidatePackage makes a conditional request to the source of a cached
+// package file, using the cache validators that were recorded when it was
+// downloaded. The outcome is recorded as an event.
+//
+// Revalidation is advisory. The cached file has already been verified
+// against the package's file attributes, so it will be used regardless of
+// the outcome.
+func (engine *downloadEngine) revalidatePackage(ctx context.Context, pkg packageData, file stagingfs.PackageFile) {
+	// Deployments invoked from a bundle must not use the network.
+	if engine.state.bundle != nil {
+		return
+	}
+
+	// Look up the validators that were recorded for the file.
+	validators, err := file.ReadValidators()
+	if err != nil || validators.IsZero() {
+		return
+	}
+
+	// Only revalidate with a source that is still listed by the package.
+	var source lbdeploy.PackageSource
+	for _, candidate := range pkg.Definition.Sources {
+		if candidate.Type == lbdeploy.PackageSourceHTTP && candidate.URL == validators.URL {
+			source = candidate
+			break
+		}
+	}
+	if source.URL == "" {
+		return
+	}
+
+	// Record the time that the revalidation started.
+	started := time.Now()
+
+	outcome, err := func() (lbdeployevent.DownloadOutcome, error) {
+		// Prepare a conditional HTTP request.
+		req, err := http.NewRequestWithContext(ctx, "GET", source.URL, nil)
+		if err != nil {
+			return lbdeployevent.DownloadCacheUnverified, err
+		}
+		if err := prepareSourceRequest(req, source); err != nil {
+			return lbdeployevent.DownloadCacheUnverified, err
+		}
+		validators.SetConditional(req)
+
+		// Make the HTTP request. The response body is never read.
+		client, release, err := sourceClient(source)
+		if err != nil {
+			return lbdeployevent.DownloadCacheUnverified, err
+		}
+		defer release()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return lbdeployevent.DownloadCacheUnverified, err
+		}
+		resp.Body.Close()
+
+		// Examine the status code of the response.
+		switch resp.StatusCode {
+		case http.StatusNotModified:
+			return lbdeployevent.DownloadCacheRevalidated, nil
+		case http.StatusOK:
+			return lbdeployevent.DownloadCacheStale, nil
+		default:
+			return lbdeployevent.DownloadCacheUnverified, httpStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+	}()
+
+	// Record the time that the revalidation stopped.
+	stopped := time.Now()
+
+	// Record the outcome of the revalidation.
+	engine.events.Record(lbdeployevent.DownloadStopped{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Source:      source.Redacted(),
+		FileName:    file.Name,
+		Path:        file.Path,
+		FileSize:    pkg.Definition.Attributes.Size,
+		Started:     started,
+		Stopped:     stopped,
+		Outcome:     outcome,
+		Err:         err,
+	})
+}
+
 func (engine *downloadEngine) resetFileDownload(source lbdeploy.PackageSource, file stagingfs.PackageFile, verifier *FileVerifier, reason lbdeployevent.DownloadResetReason) error {
 	// Record the reset of the download.
 	engine.events.Record(lbdeployevent.DownloadReset{
@@ -264,7 +493,7 @@ func (engine *downloadEngine) resetFileDownload(source lbdeploy.PackageSource, f
 		Flow:        engine.flow.ID,
 		ActionIndex: engine.action.Index,
 		ActionType:  engine.action.Definition.Type,
-		Source:      source,
+		Source:      source.Redacted(),
 		FileName:    file.Name,
 		Path:        file.Path,
 		Reason:      reason,
@@ -283,5 +512,15 @@ func (engine *downloadEngine) resetFileDownload(source lbdeploy.PackageSource, f
 	// Reset the file verifier.
 	verifier.Reset()
 
+	// Remove any cache validators for the discarded content.
+	if err := file.RemoveValidators(); err != nil {
+		return err
+	}
+
+	// Remove any verification record for the discarded content.
+	if err := file.RemoveVerification(); err != nil {
+		return err
+	}
+
 	return nil
 }
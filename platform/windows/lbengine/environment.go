@@ -0,0 +1,54 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbexpand"
+)
+
+// buildEnvironment returns the environment of the current process with the
+// deployment's environment variables and the given sets of variables applied
+// to it, in order. Placeholders within the values are expanded.
+//
+// If ctx carries hook variables, they are applied last and are not
+// expanded.
+//
+// If no variables are provided, it returns nil, which causes child processes
+// to inherit the environment of the current process.
+//
+// The exec package only uses the last value provided for each variable,
+// comparing names without regard to case on Windows, so the variables
+// are simply appended.
+func buildEnvironment(ctx context.Context, dep lbdeploy.Deployment, sets ...lbdeploy.EnvironmentMap) ([]string, error) {
+	sets = append([]lbdeploy.EnvironmentMap{dep.Environment}, sets...)
+
+	var env []string
+	resolve := placeholderResolver(dep)
+	for _, vars := range sets {
+		if len(vars) == 0 {
+			continue
+		}
+		if env == nil {
+			env = os.Environ()
+		}
+		for _, name := range vars.Names() {
+			value, err := lbexpand.Expand(vars[name], resolve)
+			if err != nil {
+				return nil, fmt.Errorf("the \"%s\" environment variable could not be prepared: %w", name, err)
+			}
+			env = append(env, name+"="+value)
+		}
+	}
+
+	if vars := hookVariables(ctx); len(vars) > 0 {
+		if env == nil {
+			env = os.Environ()
+		}
+		env = append(env, vars...)
+	}
+
+	return env, nil
+}
@@ -0,0 +1,60 @@
+package lbengine
+
+import (
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbexpand"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+)
+
+// placeholderResolver returns a function that resolves template
+// placeholders to local file system paths and variable values for the
+// given deployment.
+//
+// Paths are resolved without regard to whether the files or directories
+// exist.
+func placeholderResolver(dep lbdeploy.Deployment) lbexpand.ResolverFunc {
+	resolver := localfs.NewResolver(dep.Resources.FileSystem)
+	return func(p lbexpand.Placeholder) (string, error) {
+		switch p.Kind {
+		case lbexpand.KindFile:
+			ref, err := resolver.ResolveFile(lbdeploy.FileResourceID(p.Name))
+			if err != nil {
+				return "", err
+			}
+			return ref.Path()
+		case lbexpand.KindDir:
+			ref, err := resolver.ResolveDirectory(lbdeploy.DirectoryResourceID(p.Name))
+			if err != nil {
+				return "", err
+			}
+			return ref.Path()
+		case lbexpand.KindVar:
+			value, found := dep.Variables[p.Name]
+			if !found {
+				return "", fmt.Errorf("the \"%s\" variable is not defined in the \"%s\" deployment", p.Name, dep.ID)
+			}
+			return value, nil
+		default:
+			return "", fmt.Errorf("the \"%s\" placeholder kind is not supported", p.Kind)
+		}
+	}
+}
+
+// expandArgs returns a copy of args with placeholders expanded.
+func expandArgs(dep lbdeploy.Deployment, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	resolve := placeholderResolver(dep)
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		value, err := lbexpand.Expand(arg, resolve)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d could not be prepared: %w", i+1, err)
+		}
+		expanded[i] = value
+	}
+	return expanded, nil
+}
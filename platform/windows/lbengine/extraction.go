@@ -3,13 +3,18 @@ package lbengine
 import (
 	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"path"
 	"time"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
 	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
 	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/internal/throughput"
 	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
 	"github.com/leafbridge/leafbridge/platform/windows/tempfs"
 )
@@ -20,6 +25,7 @@ type extractionEngine struct {
 	deployment lbdeploy.Deployment
 	flow       flowData
 	action     actionData
+	pkg        packageData
 	events     lbevent.Recorder
 	state      *engineState
 }
@@ -35,26 +41,105 @@ func (engine *extractionEngine) ExtractPackage(ctx context.Context, source stagi
 	}
 
 	// Prepare a ZIP file reader.
-	reader, err := zip.NewReader(source, fi.Size())
+	reader, err := openArchive(source, fi.Size())
 	if err != nil {
 		return err
 	}
 
-	// Collect statistics for the archive.
-	var sourceStats lbdeployevent.ExtractionStats
+	// Reject the archive if any of its entries have paths that could
+	// escape the extraction directory. This applies to all entries,
+	// including those that would be skipped by the extraction filter.
+	if violations := unsafeArchiveEntries(reader); len(violations) > 0 {
+		engine.events.Record(lbdeployevent.ExtractionRejected{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			SourcePath:  source.Path,
+			Violations:  violations,
+		})
+		entries := make([]string, len(violations))
+		for i := range violations {
+			entries[i] = violations[i].Entry
+		}
+		return UnsafeArchiveError{Entries: entries}
+	}
+
+	// Prepare the package's extraction filter, which determines which
+	// entries in the archive will be extracted.
+	extract, err := engine.pkg.Definition.Extract.Compile()
+	if err != nil {
+		return fmt.Errorf("the package's extraction filter is invalid: %w", err)
+	}
+
+	// Collect statistics for the archive, keeping track of the entries
+	// that will be skipped separately.
+	var sourceStats, skippedStats lbdeployevent.ExtractionStats
 	for _, zipFile := range reader.File {
+		stats := &sourceStats
+		if !extract(zipFile.Name) {
+			stats = &skippedStats
+		}
 		fi := zipFile.FileInfo()
 		if fi.IsDir() {
-			sourceStats.Directories++
+			stats.Directories++
 		} else {
-			sourceStats.Files++
-			sourceStats.TotalBytes += fi.Size()
+			stats.Files++
+			stats.TotalBytes += fi.Size()
 		}
 		// FIXME: Include parent directories in file paths, which
 		// propbably requires building a map of all directories
 		// encountered.
 	}
 
+	// Verify that every entry selected for extraction can be extracted,
+	// so that unsupported archives are rejected before anything is
+	// written.
+	for _, zipFile := range reader.File {
+		if !extract(zipFile.Name) {
+			continue
+		}
+		if feature := unsupportedArchiveFeature(zipFile); feature != "" {
+			engine.events.Record(lbdeployevent.ExtractionUnsupported{
+				Deployment:  engine.deployment.ID,
+				Flow:        engine.flow.ID,
+				ActionIndex: engine.action.Index,
+				ActionType:  engine.action.Definition.Type,
+				SourcePath:  source.Path,
+				EntryPath:   zipFile.Name,
+				Feature:     feature,
+			})
+			return UnsupportedArchiveError{Entry: zipFile.Name, Feature: feature}
+		}
+	}
+
+	// Reject the archive if the files selected for extraction declare more
+	// than the package's extraction quota allows.
+	maxFiles, maxBytes := engine.pkg.Definition.Quota.MaxFiles(), engine.pkg.Definition.Quota.MaxBytes()
+	if (maxFiles > 0 && sourceStats.Files > maxFiles) || (maxBytes > 0 && sourceStats.TotalBytes > maxBytes) {
+		return engine.quotaExceeded(source, true, sourceStats.Files, sourceStats.TotalBytes)
+	}
+
+	// Verify that the destination volume has room for the extracted files
+	// before any of them are written.
+	if err := checkDiskSpace(destination.Path(), sourceStats.TotalBytes); err != nil {
+		var spaceErr InsufficientDiskSpaceError
+		if errors.As(err, &spaceErr) {
+			engine.events.Record(lbdeployevent.DiskSpaceInsufficient{
+				Deployment:  engine.deployment.ID,
+				Flow:        engine.flow.ID,
+				ActionIndex: engine.action.Index,
+				ActionType:  engine.action.Definition.Type,
+				Operation:   lbdeployevent.DiskSpaceForExtraction,
+				FileName:    source.Name,
+				Path:        spaceErr.Path,
+				Available:   spaceErr.Available,
+				Required:    spaceErr.Required,
+			})
+		}
+		return err
+	}
+
 	// Record the start of the extraction.
 	engine.events.Record(lbdeployevent.ExtractionStarted{
 		Deployment:      engine.deployment.ID,
@@ -64,16 +149,26 @@ func (engine *extractionEngine) ExtractPackage(ctx context.Context, source stagi
 		SourcePath:      source.Path,
 		DestinationPath: destination.Path(),
 		SourceStats:     sourceStats,
+		SkippedStats:    skippedStats,
+		MaxFiles:        maxFiles,
+		MaxBytes:        maxBytes,
 	})
 
-	// Process each file and directory in the archive.
+	// Process each file and directory in the archive, watching for a
+	// collapse in throughput.
 	var destinationStats lbdeployevent.ExtractionStats
+	monitor := throughput.NewMonitor(time.Now())
 	err = func() error {
 		for i, zipFile := range reader.File {
 			if err := ctx.Err(); err != nil {
 				return err
 			}
 
+			// Skip entries that are excluded by the extraction filter.
+			if !extract(zipFile.Name) {
+				continue
+			}
+
 			// Record the start of the extraction of this file.
 			fileStarted := time.Now()
 
@@ -102,17 +197,33 @@ func (engine *extractionEngine) ExtractPackage(ctx context.Context, source stagi
 					}
 				}
 
+				// Stop if another file would exceed the package's quota.
+				if maxFiles > 0 && destinationStats.Files >= maxFiles {
+					return engine.quotaExceeded(source, false, destinationStats.Files+1, destinationStats.TotalBytes)
+				}
+
 				// Open the file.
-				fileReader, err := zipFile.Open()
+				fileReader, err := openArchiveFile(zipFile)
 				if err != nil {
 					return fmt.Errorf("failed to open file within the zip archive: %w", err)
 				}
 				defer fileReader.Close()
 
+				// Don't write more than the package's quota allows, even
+				// if the file is larger than the archive declares. A
+				// saturated quota can't be exceeded, so it isn't applied.
+				var reader io.Reader = newReaderWithContext(ctx, fileReader)
+				if maxBytes > 0 && maxBytes < math.MaxInt64 {
+					reader = io.LimitReader(reader, maxBytes-destinationStats.TotalBytes+1)
+				}
+
 				// Write the file to the directory, preserving its
 				// modification time.
-				written, err := destination.WriteFile(zipFile.Name, newReaderWithContext(ctx, fileReader), zipFile.Modified)
+				written, err := destination.WriteFile(zipFile.Name, reader, zipFile.Modified)
 				if err != nil {
+					if errors.Is(err, zip.ErrChecksum) {
+						return lberror.Wrap(lberror.Verification, fmt.Errorf("the extracted file does not match the checksum recorded in the archive: %w", err))
+					}
 					return fmt.Errorf("failed to write file to its destination: %w", err)
 				}
 
@@ -120,12 +231,35 @@ func (engine *extractionEngine) ExtractPackage(ctx context.Context, source stagi
 				destinationStats.Files++
 				destinationStats.TotalBytes += written
 
+				// Stop if the file exceeded the package's quota.
+				if maxBytes > 0 && destinationStats.TotalBytes > maxBytes {
+					return engine.quotaExceeded(source, false, destinationStats.Files, destinationStats.TotalBytes)
+				}
+
 				return nil
 			}()
 
 			// Record the time that the extraction of this file stopped.
 			fileStopped := time.Now()
 
+			// If the throughput has collapsed, note that something such as
+			// real-time antivirus scanning might be interfering.
+			if collapsed, bytes, elapsed := monitor.Add(fileInfo.Size(), fileStopped); collapsed {
+				peakBytes, peakTime := monitor.Peak()
+				engine.events.Record(lbdeployevent.ExtractionSlowed{
+					Deployment:  engine.deployment.ID,
+					Flow:        engine.flow.ID,
+					ActionIndex: engine.action.Index,
+					ActionType:  engine.action.Definition.Type,
+					SourcePath:  source.Path,
+					PeakBytes:   peakBytes,
+					PeakTime:    peakTime,
+					RecentBytes: bytes,
+					RecentTime:  elapsed,
+					Excluded:    engine.state.antivirusExcluded(destination.Path()),
+				})
+			}
+
 			// Record the extraction of the file.
 			engine.events.Record(lbdeployevent.FileExtraction{
 				Deployment: engine.deployment.ID,
@@ -160,6 +294,7 @@ func (engine *extractionEngine) ExtractPackage(ctx context.Context, source stagi
 		SourcePath:       source.Path,
 		DestinationPath:  destination.Path(),
 		SourceStats:      sourceStats,
+		SkippedStats:     skippedStats,
 		DestinationStats: destinationStats,
 		Started:          started,
 		Stopped:          stopped,
@@ -168,3 +303,27 @@ func (engine *extractionEngine) ExtractPackage(ctx context.Context, source stagi
 
 	return err
 }
+
+// quotaExceeded records that the extraction of source exceeded the
+// extraction quota of the package, and returns an error describing it.
+func (engine *extractionEngine) quotaExceeded(source stagingfs.PackageFile, declared bool, files int, totalBytes int64) error {
+	quota := engine.pkg.Definition.Quota
+	engine.events.Record(lbdeployevent.ExtractionQuotaExceeded{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		SourcePath:  source.Path,
+		Declared:    declared,
+		MaxFiles:    quota.MaxFiles(),
+		MaxBytes:    quota.MaxBytes(),
+		Files:       files,
+		TotalBytes:  totalBytes,
+	})
+	return ExtractionQuotaError{
+		MaxFiles:   quota.MaxFiles(),
+		MaxBytes:   quota.MaxBytes(),
+		Files:      files,
+		TotalBytes: totalBytes,
+	}
+}
@@ -1,7 +1,9 @@
 package lbengine
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,9 +11,11 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/leafbridge/leafbridge/core/configedit"
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
 	"github.com/leafbridge/leafbridge/core/lbdeployevent"
 	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbexpand"
 	"github.com/leafbridge/leafbridge/platform/windows/filetime"
 	"github.com/leafbridge/leafbridge/platform/windows/localfs"
 )
@@ -44,19 +48,33 @@ func (engine *fileEngine) CopyFile(ctx context.Context) error {
 		return fmt.Errorf("destination file: %w", err)
 	}
 
-	// Make sure that the destination file is not in a protected location.
-	if destFileRef.Root.Protected {
-		return fmt.Errorf("the destination file is located in the \"%s\" root, which is protected", destFileRef.Root.ID)
+	// Make sure that the destination file isn't redirected elsewhere, and
+	// that it can't be redirected while it is being copied.
+	unlock, err := engine.lockReparsePoints(destFileRef, destFileID)
+	if err != nil {
+		return fmt.Errorf("destination file: %w", err)
+	}
+	defer unlock()
+
+	// Make sure that the destination file is not in a protected location,
+	// unless the action permits it.
+	release, err := engine.unprotect(destFileRef, destFileID)
+	if err != nil {
+		return fmt.Errorf("destination file: %w", err)
 	}
+	defer release()
 
 	// Record the time that the file copy started.
 	started := time.Now()
 
 	var (
-		sourceFilePath  string
-		destFilePath    string
-		destFileExisted bool
-		fileSize        int64
+		sourceFilePath     string
+		destFilePath       string
+		destFileExisted    bool
+		destFileReplaced   bool
+		lockers            []lbdeployevent.FileLocker
+		scheduledForReboot bool
+		fileSize           int64
 	)
 	err = func() error {
 		// Open the root above the destination file.
@@ -81,16 +99,24 @@ func (engine *fileEngine) CopyFile(ctx context.Context) error {
 				return fmt.Errorf("unable to evaluate the destination file: %w", err)
 			}
 		} else if fi.Mode().IsRegular() {
-			// The file already exists.
-			//
-			// TODO: Support replacing existing files, optionally via
-			// configuration.
+			// The file already exists. Leave it alone unless the action
+			// calls for it to be replaced.
 			destFileExisted = true
-			return nil
+			if !engine.action.Definition.Replace {
+				return nil
+			}
+			if destFilePath == "" {
+				return errors.New("unable to determine the path of the existing destination file")
+			}
 		} else {
 			return errors.New("the destination file path already exists but is not a regular file")
 		}
 
+		// Back up the destination before it is changed, if requested.
+		if err := engine.autoBackup(destFileID, destFilePath); err != nil {
+			return fmt.Errorf("unable to back up the destination file: %w", err)
+		}
+
 		// Open the source file.
 		sourceFile, err := localfs.OpenFile(sourceFileRef)
 		if err != nil {
@@ -104,31 +130,51 @@ func (engine *fileEngine) CopyFile(ctx context.Context) error {
 			fileSize = fi.Size()
 		}
 
-		// Open the destination file.
-		destFile, err := destDir.System().Create(destFileRef.FilePath)
-		if err != nil {
-			return err
+		// If the file doesn't exist yet, copy it directly. A partial copy is
+		// removed, so that a cancelled copy doesn't leave it behind.
+		if !destFileExisted {
+			if err := copyFileData(ctx, destDir.System(), destFileRef.FilePath, sourceFile.System()); err != nil {
+				destDir.System().Remove(destFileRef.FilePath)
+				return err
+			}
+			return nil
 		}
-		defer destFile.Close()
 
-		// Record the destination path for event logging.
-		sourceFilePath = sourceFile.Path()
-
-		// Copy file data.
-		if _, err := io.Copy(destFile, sourceFile.System()); err != nil {
+		// When replacing an existing file, write the new file next to it
+		// first, so that the existing file is left intact if it can't be
+		// replaced.
+		stagedFile := destFileRef.FilePath + stagedFileSuffix
+		if err := copyFileData(ctx, destDir.System(), stagedFile, sourceFile.System()); err != nil {
+			destDir.System().Remove(stagedFile)
 			return err
 		}
 
-		// Copy the file modification date.
-		sourceFileInfo, err := sourceFile.System().Stat()
-		if err != nil {
+		// Replace the existing file with the staged file.
+		stagedPath := destFilePath + stagedFileSuffix
+		err = os.Rename(stagedPath, destFilePath)
+		if err == nil {
+			destFileReplaced = true
+			return nil
+		}
+
+		// If the existing file is in use, identify the processes using it
+		// and schedule the replacement for the next restart if the
+		// action calls for it.
+		locked := checkFileLocked(destFilePath, err)
+		if locked == nil {
+			destDir.System().Remove(stagedFile)
 			return err
 		}
-		if modTime := sourceFileInfo.ModTime(); !modTime.IsZero() {
-			if err := filetime.SetFileModificationTime(destFile, modTime); err != nil {
-				return fmt.Errorf("failed to set file modification time: %w", err)
-			}
+		lockers = locked.Lockers
+		if engine.action.Definition.OnLocked != lbdeploy.FileLockedReplaceOnReboot {
+			destDir.System().Remove(stagedFile)
+			return *locked
+		}
+		if err := replaceOnReboot(stagedPath, destFilePath); err != nil {
+			destDir.System().Remove(stagedFile)
+			return err
 		}
+		scheduledForReboot = true
 		return nil
 	}()
 
@@ -146,15 +192,52 @@ func (engine *fileEngine) CopyFile(ctx context.Context) error {
 		DestinationID:      destFileID,
 		DestinationPath:    destFilePath,
 		DestinationExisted: destFileExisted,
+		Replaced:           destFileReplaced,
+		Lockers:            lockers,
+		ScheduledForReboot: scheduledForReboot,
 		FileSize:           fileSize,
 		Started:            started,
 		Stopped:            stopped,
 		Err:                err,
 	})
+	engine.recordFileChange(destFileRef, destFileID, "copy", destFilePath, err)
 
 	return nil
 }
 
+// stagedFileSuffix is appended to the name of a file that is about to
+// replace an existing file.
+const stagedFileSuffix = ".leafbridge-staged"
+
+// copyFileData copies the content and modification time of source to a new
+// file with the given name within root. If the file already exists, it is
+// truncated. The copy stops if ctx is cancelled.
+func copyFileData(ctx context.Context, root *os.Root, name string, source *os.File) error {
+	destFile, err := root.Create(name)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	// Copy file data.
+	if _, err := io.Copy(destFile, newReaderWithContext(ctx, source)); err != nil {
+		return err
+	}
+
+	// Copy the file modification date.
+	sourceFileInfo, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	if modTime := sourceFileInfo.ModTime(); !modTime.IsZero() {
+		if err := filetime.SetFileModificationTime(destFile, modTime); err != nil {
+			return fmt.Errorf("failed to set file modification time: %w", err)
+		}
+	}
+
+	return destFile.Close()
+}
+
 // DeleteFile performs a file delete operation.
 func (engine *fileEngine) DeleteFile(ctx context.Context) error {
 	// Prepare a local file system resolver.
@@ -167,18 +250,31 @@ func (engine *fileEngine) DeleteFile(ctx context.Context) error {
 		return fmt.Errorf("file: %w", err)
 	}
 
-	// Make sure that the file is not in a protected location.
-	if fileRef.Root.Protected {
-		return fmt.Errorf("the file is located in the \"%s\" root, which is protected", fileRef.Root.ID)
+	// Make sure that the file isn't redirected elsewhere, and that it
+	// can't be redirected while it is being changed.
+	unlock, err := engine.lockReparsePoints(fileRef, fileID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Make sure that the file is not in a protected location, unless the
+	// action permits it.
+	release, err := engine.unprotect(fileRef, fileID)
+	if err != nil {
+		return err
 	}
+	defer release()
 
 	// Record the time that the file deletion started.
 	started := time.Now()
 
 	var (
-		filePath    string
-		fileSize    int64
-		fileExisted bool
+		filePath           string
+		fileSize           int64
+		fileExisted        bool
+		lockers            []lbdeployevent.FileLocker
+		scheduledForReboot bool
 	)
 	err = func() error {
 		// Open the root above the destination file.
@@ -214,8 +310,33 @@ func (engine *fileEngine) DeleteFile(ctx context.Context) error {
 		// Record that the file exixted.
 		fileExisted = true
 
+		// Back up the file before it is deleted, if requested.
+		if err := engine.autoBackup(fileID, filePath); err != nil {
+			return fmt.Errorf("unable to back up the file: %w", err)
+		}
+
 		// Delete the file.
-		return fileDir.System().Remove(fileRef.FilePath)
+		err = fileDir.System().Remove(fileRef.FilePath)
+		if err == nil {
+			return nil
+		}
+
+		// If the file is in use, identify the processes using it and
+		// schedule the deletion for the next restart if the action calls
+		// for it.
+		locked := checkFileLocked(filePath, err)
+		if locked == nil {
+			return err
+		}
+		lockers = locked.Lockers
+		if engine.action.Definition.OnLocked != lbdeploy.FileLockedReplaceOnReboot || filePath == "" {
+			return *locked
+		}
+		if err := replaceOnReboot(filePath, ""); err != nil {
+			return err
+		}
+		scheduledForReboot = true
+		return nil
 	}()
 
 	// Record the time that the file deletion stopped.
@@ -223,18 +344,201 @@ func (engine *fileEngine) DeleteFile(ctx context.Context) error {
 
 	// Record the file deletion.
 	engine.events.Record(lbdeployevent.FileDelete{
+		Deployment:         engine.deployment.ID,
+		Flow:               engine.flow.ID,
+		ActionIndex:        engine.action.Index,
+		ActionType:         engine.action.Definition.Type,
+		FileID:             fileID,
+		FilePath:           filePath,
+		FileSize:           fileSize,
+		FileExisted:        fileExisted,
+		Lockers:            lockers,
+		ScheduledForReboot: scheduledForReboot,
+		Started:            started,
+		Stopped:            stopped,
+		Err:                err,
+	})
+	if fileExisted || err != nil {
+		engine.recordFileChange(fileRef, fileID, "delete", filePath, err)
+	}
+
+	return nil
+}
+
+// EditConfigFile applies the configuration edits of the action to its
+// destination file. The file is created if it does not exist.
+func (engine *fileEngine) EditConfigFile(ctx context.Context) error {
+	format := engine.action.Definition.Type.ConfigFormat()
+	edits := engine.action.Definition.Edits
+
+	// Prepare a local file system resolver.
+	resolver := localfs.NewResolver(engine.deployment.Resources.FileSystem)
+
+	// Find the relevant file within the deployment.
+	fileID := engine.action.Definition.DestinationFile
+	fileRef, err := resolver.ResolveFile(fileID)
+	if err != nil {
+		return fmt.Errorf("file: %w", err)
+	}
+
+	// Make sure that the file isn't redirected elsewhere, and that it
+	// can't be redirected while it is being changed.
+	unlock, err := engine.lockReparsePoints(fileRef, fileID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Make sure that the file is not in a protected location.
+	if fileRef.Root.Protected {
+		return fmt.Errorf("the file is located in the \"%s\" root, which is protected", fileRef.Root.ID)
+	}
+
+	// Record the time that the file edit started.
+	started := time.Now()
+
+	var (
+		filePath string
+		created  bool
+		changed  bool
+	)
+	err = func() error {
+		// Open the root above the file.
+		fileDir, err := localfs.OpenDir(fileRef.Dir())
+		if err != nil {
+			return fmt.Errorf("unable to open the file's directory: %w", err)
+		}
+		defer fileDir.Close()
+
+		// Record the file path for event logging.
+		{
+			localized, err := filepath.Localize(fileRef.FilePath)
+			if err == nil {
+				filePath = filepath.Join(fileDir.Path(), localized)
+			}
+		}
+
+		// Read the existing file, if there is one.
+		var original []byte
+		fi, err := fileDir.System().Stat(fileRef.FilePath)
+		switch {
+		case err == nil && !fi.Mode().IsRegular():
+			return errors.New("the file path exists but is not a regular file")
+		case err == nil:
+			if original, err = readRootFile(fileDir.System(), fileRef.FilePath); err != nil {
+				return fmt.Errorf("unable to read the file: %w", err)
+			}
+		case os.IsNotExist(err):
+			created = true
+		default:
+			return fmt.Errorf("unable to evaluate the file to be edited: %w", err)
+		}
+
+		text, encoding, err := configedit.Decode(original)
+		if err != nil {
+			return err
+		}
+
+		// Apply each of the edits in order.
+		edited := text
+		for i, edit := range edits {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			edited, err = applyConfigEdit(engine.deployment, format, edited, edit)
+			if err != nil {
+				return fmt.Errorf("edit %d: %w", i+1, err)
+			}
+		}
+
+		if !created && bytes.Equal(edited, text) {
+			return nil
+		}
+
+		// Back up the file before it is changed, if requested.
+		if err := engine.autoBackup(fileID, filePath); err != nil {
+			return fmt.Errorf("unable to back up the file: %w", err)
+		}
+		changed = true
+
+		file, err := fileDir.System().OpenFile(fileRef.FilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return fmt.Errorf("unable to open the file for writing: %w", err)
+		}
+		if _, err := file.Write(encoding.Encode(edited)); err != nil {
+			file.Close()
+			return fmt.Errorf("unable to write the file: %w", err)
+		}
+		return file.Close()
+	}()
+
+	// Record the time that the file edit stopped.
+	stopped := time.Now()
+
+	// Record the file edit.
+	engine.events.Record(lbdeployevent.FileEdit{
 		Deployment:  engine.deployment.ID,
 		Flow:        engine.flow.ID,
 		ActionIndex: engine.action.Index,
 		ActionType:  engine.action.Definition.Type,
 		FileID:      fileID,
 		FilePath:    filePath,
-		FileSize:    fileSize,
-		FileExisted: fileExisted,
+		Format:      format,
+		Edits:       len(edits),
+		Created:     created && changed,
+		Changed:     changed,
 		Started:     started,
 		Stopped:     stopped,
 		Err:         err,
 	})
+	if changed || err != nil {
+		engine.recordFileChange(fileRef, fileID, "edit", filePath, err)
+	}
 
-	return nil
+	return err
+}
+
+// readRootFile returns the contents of the named file within root.
+func readRootFile(root *os.Root, name string) ([]byte, error) {
+	file, err := root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// applyConfigEdit applies a single configuration edit to text and returns
+// the result. Placeholders within string values are expanded first.
+func applyConfigEdit(dep lbdeploy.Deployment, format lbdeploy.ConfigFormat, text []byte, edit lbdeploy.ConfigEdit) ([]byte, error) {
+	if template, ok := edit.Template(); ok {
+		expanded, err := lbexpand.Expand(template, placeholderResolver(dep))
+		if err != nil {
+			return nil, fmt.Errorf("the value could not be prepared: %w", err)
+		}
+		value, err := json.Marshal(expanded)
+		if err != nil {
+			return nil, err
+		}
+		edit.Value = value
+	}
+
+	switch format {
+	case lbdeploy.ConfigJSON:
+		return configedit.SetJSON(text, edit.Path, edit.Value)
+	}
+
+	value, err := edit.Text()
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case lbdeploy.ConfigINI:
+		return configedit.SetINI(text, edit.Section, edit.Key, value)
+	case lbdeploy.ConfigXML:
+		return configedit.SetXML(text, edit.Path, edit.Attribute, value)
+	default:
+		return nil, fmt.Errorf("the configuration format \"%s\" is not recognized", format)
+	}
 }
@@ -0,0 +1,85 @@
+package lbengine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/restartmgr"
+	"golang.org/x/sys/windows"
+)
+
+// FileLockedError is returned when a file cannot be changed because it is
+// in use by other processes.
+type FileLockedError struct {
+	Path    string
+	Lockers []lbdeployevent.FileLocker
+}
+
+// Error returns a description of the error.
+func (err FileLockedError) Error() string {
+	if len(err.Lockers) == 0 {
+		return fmt.Sprintf("the file \"%s\" is in use by another process", err.Path)
+	}
+	names := make([]string, len(err.Lockers))
+	for i, locker := range err.Lockers {
+		names[i] = locker.String()
+	}
+	return fmt.Sprintf("the file \"%s\" is in use by %s", err.Path, strings.Join(names, ", "))
+}
+
+// checkFileLocked examines an error that was returned while changing the
+// file at path. If the error was caused by other processes holding the
+// file open, it returns a [FileLockedError] that identifies them.
+// Otherwise it returns nil.
+//
+// Access denied errors are ambiguous, so they are only treated as locking
+// errors if the Restart Manager reports that the file is in use.
+func checkFileLocked(path string, err error) *FileLockedError {
+	sharing := errors.Is(err, windows.ERROR_SHARING_VIOLATION) || errors.Is(err, windows.ERROR_LOCK_VIOLATION)
+	if !sharing && !errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		return nil
+	}
+
+	// Ask the Restart Manager which processes are using the file. If it
+	// can't tell us, we still know the file is locked in the case of a
+	// sharing violation.
+	processes, _ := restartmgr.FileUsers(path)
+	if len(processes) == 0 && !sharing {
+		return nil
+	}
+
+	lockers := make([]lbdeployevent.FileLocker, len(processes))
+	for i, process := range processes {
+		lockers[i] = lbdeployevent.FileLocker{
+			ProcessID: process.ID,
+			Name:      process.Name,
+			Service:   process.Service,
+		}
+	}
+
+	return &FileLockedError{Path: path, Lockers: lockers}
+}
+
+// replaceOnReboot schedules the file at source to replace the file at
+// destination the next time the system restarts. If destination is empty,
+// source is deleted instead.
+func replaceOnReboot(source, destination string) error {
+	from, err := windows.UTF16PtrFromString(source)
+	if err != nil {
+		return err
+	}
+
+	var to *uint16
+	if destination != "" {
+		if to, err = windows.UTF16PtrFromString(destination); err != nil {
+			return err
+		}
+	}
+
+	if err := windows.MoveFileEx(from, to, windows.MOVEFILE_DELAY_UNTIL_REBOOT|windows.MOVEFILE_REPLACE_EXISTING); err != nil {
+		return fmt.Errorf("failed to schedule the file change for the next restart: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package lbengine
 
 import (
+	"crypto/sha256"
 	"crypto/sha3"
 	"fmt"
 	"hash"
@@ -34,6 +35,8 @@ func NewFileVerifier(hashTypes ...filehash.Type) (*FileVerifier, error) {
 		switch typ {
 		case filehash.SHA3_256:
 			v.hashes[typ] = sha3.New256()
+		case filehash.SHA256:
+			v.hashes[typ] = sha256.New()
 		default:
 			return nil, fmt.Errorf("unrecognized file hash type \"%s\"", typ)
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
@@ -42,10 +43,19 @@ func (engine flowEngine) Invoke(ctx context.Context) error {
 		return fmt.Errorf("the \"%s\" flow is already running", engine.flow.ID)
 	}
 
+	// Refuse to run outside of the flow's validity window.
+	if err := checkValidity(engine.events, engine.deployment.ID, engine.flow.ID, engine.flow.Definition.Validity(), true); err != nil {
+		return err
+	}
+
 	// Evaluate all constraints for the flow.
 	if conditions := engine.flow.Definition.Constraints; len(conditions) > 0 {
 		// Prepare a condition engine.
-		ce := NewConditionEngine(engine.deployment)
+		ce := traceConditions(NewConditionEngine(engine.deployment), engine.state, engine.events, lbdeployevent.ConditionObserved{
+			Deployment: engine.deployment.ID,
+			Flow:       engine.flow.ID,
+			Use:        lbdeploy.ConditionUseConstraint,
+		})
 
 		// Evaluate each condition.
 		var passed, failed lbdeploy.ConditionList
@@ -84,10 +94,29 @@ func (engine flowEngine) Invoke(ctx context.Context) error {
 		}
 	}
 
+	// Skip the flow if it has already run as often as its frequency
+	// allows.
+	if permitted, err := engine.checkFrequency(); err != nil {
+		return err
+	} else if !permitted {
+		return nil
+	}
+
+	// Defer the flow if it is disruptive and the machine is not idle.
+	if permitted, err := engine.checkIdle(); err != nil {
+		return err
+	} else if !permitted {
+		return nil
+	}
+
 	// Evaluate all preconditions for the flow.
 	if conditions := engine.flow.Definition.Preconditions; len(conditions) > 0 {
 		// Prepare a condition engine.
-		ce := NewConditionEngine(engine.deployment)
+		ce := traceConditions(NewConditionEngine(engine.deployment), engine.state, engine.events, lbdeployevent.ConditionObserved{
+			Deployment: engine.deployment.ID,
+			Flow:       engine.flow.ID,
+			Use:        lbdeploy.ConditionUsePrecondition,
+		})
 
 		// Evaluate each condition.
 		var passed, failed lbdeploy.ConditionList
@@ -112,17 +141,22 @@ func (engine flowEngine) Invoke(ctx context.Context) error {
 		}
 
 		// Record the results of the evaluation.
+		messages := engine.deployment.FailureMessages(failed)
 		engine.events.Record(lbdeployevent.FlowCondition{
-			Deployment: engine.deployment.ID,
-			Flow:       engine.flow.ID,
-			Use:        lbdeploy.ConditionUsePrecondition,
-			Passed:     passed,
-			Failed:     failed,
+			Deployment:      engine.deployment.ID,
+			Flow:            engine.flow.ID,
+			Use:             lbdeploy.ConditionUsePrecondition,
+			Passed:          passed,
+			Failed:          failed,
+			FailureMessages: messages,
 		})
 
 		// If any of the preconditions failed, stop execution.
 		if len(failed) > 0 {
-			return fmt.Errorf("the \"%s\" flow is unable to run because one or more preconditions failed: %s", engine.flow.ID, failed)
+			if len(messages) > 0 {
+				return engine.failure(fmt.Errorf("the \"%s\" flow is unable to run because one or more preconditions failed (%s): %s", engine.flow.ID, failed, strings.Join(messages, " ")))
+			}
+			return engine.failure(fmt.Errorf("the \"%s\" flow is unable to run because one or more preconditions failed: %s", engine.flow.ID, failed))
 		}
 	}
 
@@ -184,19 +218,43 @@ func (engine flowEngine) Invoke(ctx context.Context) error {
 	// Record the time that the flow started.
 	started := time.Now()
 
+	// Create a restore point before any actions run, if requested.
+	endRestorePoint := engine.beginRestorePoint()
+
+	// Keep track of the progress of the flow, so that it can be resumed if
+	// it is interrupted. When resuming, continue from the last checkpoint.
+	checkpoint := engine.beginCheckpoint(started)
+
 	// Collect statistics.
 	var stats lbdeploy.FlowStats
 
+	// Measure the duration of each action, and keep track of the actions
+	// that completed so that a rollback only undoes work that happened.
+	durations := make([]time.Duration, len(engine.flow.Definition.Actions))
+	completed := make([]bool, len(engine.flow.Definition.Actions))
+
+	// If the flow has a timeout, enforce it while its actions run. The
+	// rollback of a flow that timed out is not subject to the timeout.
+	flowCtx, cancelFlow := withFlowTimeout(ctx, engine.flow)
+	defer cancelFlow()
+
 	// Execute each action in the flow.
 	err := func() error {
 		var errs []error
 		for i, action := range engine.flow.Definition.Actions {
 			// Check for context cancellation.
-			if err := ctx.Err(); err != nil {
-				errs = append(errs, err)
+			if err := flowCtx.Err(); err != nil {
+				errs = append(errs, timeoutCause(flowCtx, err))
 				break
 			}
 
+			// Skip actions that completed before the flow was interrupted.
+			if checkpoint.Done(i) {
+				stats.ActionsCompleted++
+				completed[i] = true
+				continue
+			}
+
 			// Create an action engine.
 			ae := actionEngine{
 				deployment: engine.deployment,
@@ -210,37 +268,174 @@ func (engine flowEngine) Invoke(ctx context.Context) error {
 				state:  engine.state,
 			}
 
-			// Invoke the action.
-			if err := ae.Invoke(ctx); err != nil {
-				if ctx.Err() == err {
-					break // Always stop when the context is cancelled.
+			// Evaluate the conditions for the action, if it has any.
+			if len(action.Conditions) > 0 {
+				passed, err := ae.EvaluateConditions()
+				if err != nil {
+					proceed, err := engine.handleActionError(flowCtx, action, behavior, &stats, err)
+					if err != nil {
+						errs = append(errs, err)
+					}
+					if !proceed {
+						break
+					}
+					continue
+				}
+				if !passed {
+					stats.ActionsSkipped++
+					continue
 				}
+			}
 
-				stats.ActionsFailed++
+			// Invoke the action, along with any hooks that are attached to it.
+			actionStarted := time.Now()
+			err := engine.invokeAction(flowCtx, &ae)
+			durations[i] = time.Since(actionStarted)
+			if err != nil {
+				if flowCtx.Err() != nil {
+					errs = append(errs, timeoutCause(flowCtx, err))
+					break // Always stop when the context is cancelled.
+				}
 
-				errs = append(errs, err)
-				if behavior.OnError != lbdeploy.OnErrorContinue {
+				proceed, err := engine.handleActionError(flowCtx, action, behavior, &stats, err)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				if !proceed {
 					break
 				}
 			} else {
 				stats.ActionsCompleted++
+				completed[i] = true
+				checkpoint.Complete(i, engine.state)
 			}
 		}
 		return errors.Join(errs...)
 	}()
 
+	// Undo the work of the completed actions if the flow failed, unless
+	// the deployment was cancelled.
+	if err != nil && ctx.Err() == nil {
+		err = engine.rollback(ctx, completed, err)
+	}
+
+	// Finish the restore point, if one was created.
+	endRestorePoint()
+
+	// The flow has finished, so it no longer needs a checkpoint. If it was
+	// cancelled, it is left in place so that the flow can be resumed.
+	if ctx.Err() == nil {
+		checkpoint.End()
+	}
+
 	// Record the time that the flow stopped.
 	stopped := time.Now()
 
+	// Record the successful completion of the flow if it has a frequency
+	// or has been deferred, along with the duration of each of its actions.
+	if err == nil {
+		err = engine.recordCompletion(stopped)
+		engine.recordDurations(durations)
+	}
+
 	// Record the end of the flow.
 	engine.events.Record(lbdeployevent.FlowStopped{
-		Deployment: engine.deployment.ID,
-		Flow:       engine.flow.ID,
-		Stats:      stats,
-		Started:    started,
-		Stopped:    stopped,
-		Err:        err,
+		Deployment:     engine.deployment.ID,
+		Flow:           engine.flow.ID,
+		Stats:          stats,
+		Started:        started,
+		Stopped:        stopped,
+		Err:            err,
+		Cancelled:      err != nil && errors.Is(ctx.Err(), context.Canceled),
+		FailureMessage: engine.flow.Definition.FailureMessage,
 	})
 
+	return engine.failure(err)
+}
+
+// failure returns err with the flow's failure message in front of it. If
+// err is nil or the flow does not have a failure message, err is returned
+// unmodified.
+func (engine flowEngine) failure(err error) error {
+	if err == nil || engine.flow.Definition.FailureMessage == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", engine.flow.Definition.FailureMessage, err)
+}
+
+// invokeAction invokes the action managed by ae. If the flow has hooks,
+// they are invoked before and after the action.
+//
+// If a hook that runs before the action fails, the action is not invoked.
+// If a hook that runs after the action fails, its error is returned along
+// with any error returned by the action.
+func (engine flowEngine) invokeAction(ctx context.Context, ae *actionEngine) error {
+	hooks := engine.flow.Definition.Hooks
+
+	if err := engine.runHooks(ctx, lbdeploy.HookBefore, hooks.Before, ae.action, hookResult{}); err != nil {
+		return err
+	}
+
+	err := engine.invokeWithRetry(ctx, ae)
+	if ctx.Err() != nil {
+		return err
+	}
+
+	if hookErr := engine.runHooks(ctx, lbdeploy.HookAfter, hooks.After, ae.action, hookResult{Err: err}); hookErr != nil {
+		return errors.Join(err, hookErr)
+	}
+
 	return err
 }
+
+// handleActionError applies the error policy of an action that has failed.
+// It updates stats accordingly and returns true if the flow should proceed
+// to its next action. It also returns the error that should be reported by
+// the flow, which is nil if the failure was ignored or handled.
+//
+// If the action does not have its own error policy, the behavior of the
+// flow determines whether the flow proceeds, and the failure is reported.
+func (engine flowEngine) handleActionError(ctx context.Context, action lbdeploy.Action, behavior lbdeploy.Behavior, stats *lbdeploy.FlowStats, err error) (proceed bool, flowErr error) {
+	switch action.OnError {
+	case lbdeploy.OnErrorContinue:
+		stats.ActionsIgnored++
+		return true, nil
+	case lbdeploy.OnErrorStop:
+		stats.ActionsFailed++
+		return false, err
+	case lbdeploy.OnErrorGotoFlow:
+		if gotoErr := engine.startFlow(ctx, action.OnErrorFlow); gotoErr != nil {
+			stats.ActionsFailed++
+			return false, errors.Join(err, gotoErr)
+		}
+		stats.ActionsIgnored++
+		return false, nil
+	default:
+		stats.ActionsFailed++
+		return behavior.OnError == lbdeploy.OnErrorContinue, err
+	}
+}
+
+// startFlow starts another flow within the LeafBridge deployment.
+func (engine flowEngine) startFlow(ctx context.Context, flow lbdeploy.FlowID) error {
+	// Find the requested flow within the deployment.
+	definition, found := engine.deployment.Flows[flow]
+	if !found {
+		return fmt.Errorf("the \"%s\" flow does not exist within the \"%s\" deployment", flow, engine.deployment.ID)
+	}
+
+	// Prepare the flow engine.
+	fe := flowEngine{
+		deployment: engine.deployment,
+		flow: flowData{
+			ID:         flow,
+			Definition: definition,
+		},
+		events: engine.events,
+		force:  engine.force,
+		state:  engine.state,
+	}
+
+	// Invoke the requested flow.
+	return fe.Invoke(ctx)
+}
@@ -0,0 +1,161 @@
+package lbengine
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbstate"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+	"golang.org/x/sys/windows"
+)
+
+// secureStateDir restricts access to the machine state directory the first
+// time that a machine state store is opened by the process.
+var secureStateDir = sync.OnceValues(stagingfs.SecureStateDir)
+
+// openFlowState returns the persistent state store for a deployment in the
+// given frequency scope.
+//
+// Machine state is kept in ProgramData\LeafBridge\State. User state is kept
+// in the LocalAppData\LeafBridge\State directory of the user that is running
+// the deployment.
+//
+// The first time that machine state is opened, access to its directory is
+// restricted to SYSTEM and Administrators. This fails when the process is
+// not elevated, in which case the directory is used as it is.
+//
+// Machine state records which flows have completed, been deferred or been
+// remediated, so anyone who can edit it can keep those flows from running.
+// It is only read if it is trusted, as determined by machineStateTrusted.
+// Untrusted machine state is treated as though it does not exist.
+func openFlowState(id lbdeploy.DeploymentID, scope lbdeploy.FrequencyScope) (lbstate.Store, error) {
+	if err := id.Validate(); err != nil {
+		return lbstate.Store{}, err
+	}
+	if scope == lbdeploy.FrequencyPerUser {
+		base, err := windows.KnownFolderPath(windows.FOLDERID_LocalAppData, 0)
+		if err != nil {
+			return lbstate.Store{}, err
+		}
+		return lbstate.NewStore(filepath.Join(base, stagingfs.RootDir, stagingfs.StateDir, string(id)+".json")), nil
+	}
+
+	dir, err := secureStateDir()
+	if err != nil {
+		base, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+		if err != nil {
+			return lbstate.Store{}, err
+		}
+		dir = filepath.Join(base, stagingfs.RootDir, stagingfs.StateDir)
+	}
+	return lbstate.NewStore(filepath.Join(dir, string(id)+".json")).WithTrust(machineStateTrusted), nil
+}
+
+// machineStateTrusted returns true if the machine state file at path can
+// be trusted. Its directory must have been restricted to SYSTEM and
+// Administrators, and the file must be owned by one of them.
+//
+// The directory and file are examined but not modified.
+func machineStateTrusted(path string) bool {
+	if secured, err := stagingfs.StateDirSecured(filepath.Dir(path)); err != nil || !secured {
+		return false
+	}
+	owned, err := stagingfs.OwnedByAdministrators(path)
+	return err == nil && owned
+}
+
+// checkFrequency returns false if the flow has already run as often as its
+// frequency allows, in which case an event is recorded. Forced invocations
+// are always permitted.
+func (engine flowEngine) checkFrequency() (bool, error) {
+	frequency := engine.flow.Definition.Frequency
+	if frequency.IsZero() || engine.force {
+		return true, nil
+	}
+
+	store, err := openFlowState(engine.deployment.ID, frequency.EffectiveScope())
+	if err != nil {
+		return false, fmt.Errorf("the \"%s\" flow failed to open its state store: %w", engine.flow.ID, err)
+	}
+	record, err := store.Flow(engine.flow.ID)
+	if err != nil {
+		return false, fmt.Errorf("the \"%s\" flow failed to read its execution history: %w", engine.flow.ID, err)
+	}
+
+	if record.Permits(frequency, time.Now()) {
+		return true, nil
+	}
+
+	engine.events.Record(lbdeployevent.FlowFrequencyLimit{
+		Deployment:    engine.deployment.ID,
+		Flow:          engine.flow.ID,
+		Frequency:     frequency,
+		LastCompleted: record.LastCompleted,
+		Completions:   record.Completions,
+		NextRun:       record.NextRun(frequency),
+	})
+
+	return false, nil
+}
+
+// recordCompletion records the successful completion of the flow in its
+// state store, if the flow has a frequency. The deferrals of a disruptive
+// flow are cleared.
+func (engine flowEngine) recordCompletion(completed time.Time) error {
+	frequency := engine.flow.Definition.Frequency
+	if engine.flow.Definition.Disruptive && (frequency.IsZero() || frequency.EffectiveScope() != lbdeploy.FrequencyPerMachine) {
+		if err := engine.clearDeferrals(); err != nil {
+			return err
+		}
+	}
+	if frequency.IsZero() {
+		return nil
+	}
+
+	store, err := openFlowState(engine.deployment.ID, frequency.EffectiveScope())
+	if err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to open its state store: %w", engine.flow.ID, err)
+	}
+	if err := store.RecordCompletion(engine.flow.ID, completed); err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to record its completion: %w", engine.flow.ID, err)
+	}
+
+	return nil
+}
+
+// recordDurations records the duration of each of the flow's actions in
+// its state store, so that the progress of later runs can be estimated.
+// Progress estimates are not essential, so failures are ignored.
+func (engine flowEngine) recordDurations(durations []time.Duration) {
+	store, err := openFlowState(engine.deployment.ID, engine.flow.Definition.Frequency.EffectiveScope())
+	if err != nil {
+		return
+	}
+	store.RecordDurations(engine.flow.ID, durations)
+}
+
+// ActionDurations returns the duration of each action in the deployment's
+// flows when the flow last completed, as recorded in the state stores of
+// the local system. Flows without recorded durations are omitted.
+func ActionDurations(dep lbdeploy.Deployment) map[lbdeploy.FlowID][]time.Duration {
+	durations := make(map[lbdeploy.FlowID][]time.Duration)
+	states := make(map[lbdeploy.FrequencyScope]lbstate.DeploymentState)
+	for id, flow := range dep.Flows {
+		scope := flow.Frequency.EffectiveScope()
+		state, loaded := states[scope]
+		if !loaded {
+			if store, err := openFlowState(dep.ID, scope); err == nil {
+				state, _ = store.Load()
+			}
+			states[scope] = state
+		}
+		if recorded := state.Flows[id].ActionDurations(); recorded != nil {
+			durations[id] = recorded
+		}
+	}
+	return durations
+}
@@ -0,0 +1,35 @@
+package lbengine
+
+import (
+	"path/filepath"
+	"slices"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbpolicy"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+	"golang.org/x/sys/windows"
+)
+
+// holdsFile is the name of the file that holds the app holds that have
+// been placed on the machine from the command line.
+const holdsFile = "holds.json"
+
+// MachineHoldsPath returns the path of the file that holds the app holds
+// of the machine, which is kept in ProgramData\LeafBridge.
+func MachineHoldsPath() (string, error) {
+	base, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, stagingfs.RootDir, holdsFile), nil
+}
+
+// heldApp returns the first app installed or uninstalled by the command
+// that is held on the machine, along with its hold. It returns false if
+// none of the apps are held.
+func heldApp(state *engineState, dep lbdeploy.Deployment, command lbdeploy.Command) (lbdeploy.AppID, lbpolicy.Hold, bool) {
+	if len(state.holds) == 0 {
+		return "", lbpolicy.Hold{}, false
+	}
+	return state.holds.Find(dep.Apps, slices.Concat(command.Installs, command.Uninstalls)...)
+}
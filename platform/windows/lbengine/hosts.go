@@ -0,0 +1,106 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/hostsfile"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"golang.org/x/sys/windows"
+)
+
+// hostsFilePath returns the path of the hosts file on the local system.
+func hostsFilePath() (string, error) {
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the system directory: %w", err)
+	}
+	return filepath.Join(system, "drivers", "etc", "hosts"), nil
+}
+
+// editHostsFile adds and removes entries in the hosts file of the local
+// system. A timestamped backup of the hosts file is written next to it
+// before it is changed.
+func (engine *actionEngine) editHostsFile(ctx context.Context) error {
+	var (
+		path           string
+		backup         string
+		added, removed []string
+	)
+	err := func() error {
+		var err error
+		if path, err = hostsFilePath(); err != nil {
+			return err
+		}
+
+		original, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read the hosts file: %w", err)
+		}
+
+		// Apply each of the entries in order.
+		content := original
+		for i, entry := range engine.action.Definition.HostsEntries {
+			var changed bool
+			if entry.Remove {
+				content, changed, err = hostsfile.Remove(content, entry.Hostname)
+			} else {
+				content, changed, err = hostsfile.Add(content, entry.Address, entry.Hostname)
+			}
+			if err != nil {
+				return fmt.Errorf("hosts entry %d: %w", i+1, err)
+			}
+			if !changed {
+				continue
+			}
+			if entry.Remove {
+				removed = append(removed, entry.Hostname)
+			} else {
+				added = append(added, entry.Hostname)
+			}
+		}
+
+		if len(added) == 0 && len(removed) == 0 {
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Back up the hosts file before changing it.
+		if original != nil {
+			backup = fmt.Sprintf("%s.leafbridge-%s.bak", path, time.Now().Format("20060102T150405"))
+			if err := os.WriteFile(backup, original, 0o644); err != nil {
+				backup = ""
+				return fmt.Errorf("failed to back up the hosts file: %w", err)
+			}
+		}
+
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return fmt.Errorf("failed to write the hosts file: %w", err)
+		}
+
+		return nil
+	}()
+
+	engine.events.Record(lbdeployevent.HostsFileEdit{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		FilePath:    path,
+		BackupPath:  backup,
+		Added:       added,
+		Removed:     removed,
+		Err:         err,
+	})
+	if len(added) > 0 || len(removed) > 0 || err != nil {
+		engine.recordSecurityChange(lbdeployevent.SecurityChangeHostsFile, "edit", path, err)
+	}
+
+	return err
+}
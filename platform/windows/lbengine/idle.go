@@ -0,0 +1,76 @@
+package lbengine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/idle"
+)
+
+// checkIdle returns false if the flow is disruptive and the machine is not
+// idle, in which case the deferral is recorded in the machine's state store
+// and an event is recorded.
+//
+// Flows are only deferred when the engine is running on behalf of an
+// agent. Forced invocations are never deferred, and neither are flows whose
+// deadline has passed.
+func (engine flowEngine) checkIdle() (bool, error) {
+	definition := engine.flow.Definition
+	if !definition.Disruptive || !engine.state.agent || engine.force {
+		return true, nil
+	}
+
+	status, err := idle.Current()
+	if err != nil {
+		return false, fmt.Errorf("the \"%s\" flow failed to determine whether the machine is idle: %w", engine.flow.ID, err)
+	}
+	required := definition.Idle.EffectiveTime()
+	if status.Idle(required) {
+		return true, nil
+	}
+
+	store, err := openFlowState(engine.deployment.ID, lbdeploy.FrequencyPerMachine)
+	if err != nil {
+		return false, fmt.Errorf("the \"%s\" flow failed to open its state store: %w", engine.flow.ID, err)
+	}
+	record, err := store.Flow(engine.flow.ID)
+	if err != nil {
+		return false, fmt.Errorf("the \"%s\" flow failed to read its execution history: %w", engine.flow.ID, err)
+	}
+	if record.DeadlinePassed(definition.Idle, time.Now()) {
+		return true, nil
+	}
+
+	record, err = store.RecordDeferral(engine.flow.ID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("the \"%s\" flow failed to record its deferral: %w", engine.flow.ID, err)
+	}
+
+	engine.events.Record(lbdeployevent.FlowDeferred{
+		Deployment: engine.deployment.ID,
+		Flow:       engine.flow.ID,
+		Sessions:   status.Sessions,
+		IdleTime:   status.IdleTime,
+		Required:   required,
+		Presenting: status.Presenting,
+		Deferrals:  record.Deferrals,
+		Deadline:   record.Deadline(definition.Idle),
+	})
+
+	return false, nil
+}
+
+// clearDeferrals clears any deferrals of the flow from the machine's state
+// store.
+func (engine flowEngine) clearDeferrals() error {
+	store, err := openFlowState(engine.deployment.ID, lbdeploy.FrequencyPerMachine)
+	if err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to open its state store: %w", engine.flow.ID, err)
+	}
+	if err := store.ClearDeferrals(engine.flow.ID); err != nil {
+		return fmt.Errorf("the \"%s\" flow failed to clear its deferrals: %w", engine.flow.ID, err)
+	}
+	return nil
+}
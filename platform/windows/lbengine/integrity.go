@@ -0,0 +1,111 @@
+package lbengine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/platform/windows/authenticode"
+	"golang.org/x/sys/windows"
+)
+
+// lockFile opens the file at path for reading. While the file is open, other
+// processes can read and run it, but they cannot write to it, rename it or
+// delete it.
+//
+// Files are locked before they are verified and remain locked until they
+// have been used, so that the file that was verified is the file that is
+// used.
+func lockFile(path string) (*os.File, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := windows.CreateFile(pathPtr, windows.GENERIC_READ, windows.FILE_SHARE_READ, nil, windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+
+	return os.NewFile(uintptr(handle), path), nil
+}
+
+// lockedFiles is a set of files that have been locked by lockFile.
+type lockedFiles []*os.File
+
+// Verify locks the file at path and verifies that it satisfies the given
+// integrity requirements. If it does, the file is added to the set and
+// remains locked until the set is released.
+//
+// It returns the computed attributes of the file and its signer, if they
+// were examined.
+func (files *lockedFiles) Verify(path string, integrity lbdeploy.FileIntegrity) (actual lbdeploy.FileAttributes, signer authenticode.Signer, err error) {
+	file, err := lockFile(path)
+	if err != nil {
+		return actual, signer, err
+	}
+
+	actual, signer, err = checkFileIntegrity(file, integrity)
+	if err != nil {
+		file.Close()
+		return actual, signer, err
+	}
+
+	*files = append(*files, file)
+	return actual, signer, nil
+}
+
+// Release closes all of the files in the set.
+func (files lockedFiles) Release() {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+// checkFileIntegrity verifies that file satisfies the given integrity
+// requirements. It returns the computed attributes of the file and its
+// signer, if they were examined.
+//
+// The file should have been opened by lockFile, so that it cannot be
+// modified after it has been verified.
+//
+// If the file does not satisfy the requirements, it returns an error.
+func checkFileIntegrity(file *os.File, integrity lbdeploy.FileIntegrity) (actual lbdeploy.FileAttributes, signer authenticode.Signer, err error) {
+	// Verify the file's hashes.
+	if len(integrity.Hashes) > 0 {
+		verifier, err := NewFileVerifier(integrity.Hashes.Types()...)
+		if err != nil {
+			return actual, signer, err
+		}
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return actual, signer, fmt.Errorf("the file could not be read: %w", err)
+		}
+		if _, err := verifier.ReadFrom(file); err != nil {
+			return actual, signer, fmt.Errorf("the file could not be read: %w", err)
+		}
+
+		actual = verifier.State()
+		for _, expected := range integrity.Hashes.ToList() {
+			if !bytes.Equal(actual.Hashes[expected.Type], expected.Value) {
+				return actual, signer, lberror.Wrap(lberror.Verification, fmt.Errorf("the %s hash of the file does not match the expected value", expected.Type))
+			}
+		}
+	}
+
+	// Verify the file's signature.
+	if integrity.Signature.Required {
+		signer, err = authenticode.VerifyFile(file)
+		if err != nil {
+			return actual, signer, lberror.Wrap(lberror.Verification, err)
+		}
+		if !integrity.Signature.Trusts(signer.Subject, signer.Thumbprint) {
+			return actual, signer, lberror.Wrap(lberror.Verification, fmt.Errorf("the file is signed by \"%s\" with a certificate thumbprint of %s, which is not a trusted publisher", signer.Subject, signer.Thumbprint))
+		}
+	}
+
+	return actual, signer, nil
+}
@@ -0,0 +1,146 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/platform/windows/softwarelicense"
+)
+
+// activateLicense installs the product key of the action if it is not
+// installed already, configures the key management service and activates
+// the product. The action fails unless the product is licensed afterwards.
+func (engine *actionEngine) activateLicense(ctx context.Context) error {
+	activation := engine.action.Definition.Activation
+
+	app := softwarelicense.Windows
+	if activation.Product == lbdeploy.LicenseProductOffice {
+		app = softwarelicense.Office
+	}
+
+	var (
+		partial      string
+		keyInstalled bool
+		activated    bool
+		status       string
+	)
+	err := func() error {
+		licenses, err := softwarelicense.Licenses(ctx, app)
+		if err != nil {
+			return fmt.Errorf("failed to determine the installed licenses: %w", err)
+		}
+
+		// Install the product key unless it is installed already.
+		if !activation.ProductKey.IsZero() {
+			key, err := resolveSecret(activation.ProductKey)
+			if err != nil {
+				return fmt.Errorf("failed to resolve the product key: %w", err)
+			}
+			if err := lbdeploy.ValidateProductKey(key); err != nil {
+				return err
+			}
+			partial = lbdeploy.PartialProductKey(key)
+			if !hasPartialProductKey(licenses, partial) {
+				if err := softwarelicense.InstallProductKey(ctx, key); err != nil {
+					return fmt.Errorf("failed to install the product key: %w", err)
+				}
+				keyInstalled = true
+			}
+		}
+
+		if activation.KMSHost != "" {
+			host, port, err := activation.KMSAddress()
+			if err != nil {
+				return err
+			}
+			if err := softwarelicense.SetKMSHost(ctx, host, port); err != nil {
+				return fmt.Errorf("failed to set the KMS host: %w", err)
+			}
+		}
+
+		// Leave the product alone if it is licensed already.
+		if !keyInstalled {
+			if license, found := licenseToActivate(licenses, partial); found && license.Status == softwarelicense.Licensed {
+				status = license.Status.String()
+				return nil
+			}
+		}
+
+		licenses, err = softwarelicense.Activate(ctx, app)
+		if err != nil {
+			if errors.Is(err, softwarelicense.ErrKMSUnavailable) {
+				return lberror.Wrap(lberror.Network, err)
+			}
+			return err
+		}
+
+		// Confirm that activation succeeded, because the licensing service
+		// does not always report a failure.
+		license, found := licenseToActivate(licenses, partial)
+		if !found {
+			return fmt.Errorf("a product key is not installed for %s", activation.Product)
+		}
+		status = license.Status.String()
+		if license.Status != softwarelicense.Licensed {
+			return fmt.Errorf("the license status of \"%s\" is %s after activation", license.Name, license.Status)
+		}
+		activated = true
+
+		return nil
+	}()
+
+	engine.events.Record(lbdeployevent.LicenseActivation{
+		Deployment:        engine.deployment.ID,
+		Flow:              engine.flow.ID,
+		ActionIndex:       engine.action.Index,
+		ActionType:        engine.action.Definition.Type,
+		Product:           activation.Product,
+		PartialProductKey: partial,
+		KMSHost:           activation.KMSHost,
+		KeyInstalled:      keyInstalled,
+		Activated:         activated,
+		Status:            status,
+		Err:               err,
+	})
+
+	return err
+}
+
+// hasPartialProductKey returns true if one of the licenses has a product
+// key ending in partial.
+func hasPartialProductKey(licenses []softwarelicense.License, partial string) bool {
+	for _, license := range licenses {
+		if license.PartialProductKey == partial {
+			return true
+		}
+	}
+	return false
+}
+
+// licenseToActivate returns the license that an activation is concerned
+// with. If a partial product key is given, it is the license with that
+// key. Otherwise it is the first license that is not licensed, or the first
+// license if all of them are.
+func licenseToActivate(licenses []softwarelicense.License, partial string) (softwarelicense.License, bool) {
+	if partial != "" {
+		for _, license := range licenses {
+			if license.PartialProductKey == partial {
+				return license, true
+			}
+		}
+		return softwarelicense.License{}, false
+	}
+	for _, license := range licenses {
+		if license.Status != softwarelicense.Licensed {
+			return license, true
+		}
+	}
+	if len(licenses) > 0 {
+		return licenses[0], true
+	}
+	return softwarelicense.License{}, false
+}
@@ -0,0 +1,45 @@
+package lbengine
+
+import (
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+)
+
+// localDirPath resolves a directory resource and returns its absolute path.
+//
+// If the directory could not be resolved or does not exist, it returns an
+// error.
+func localDirPath(resources lbdeploy.FileSystemResources, id lbdeploy.DirectoryResourceID) (string, error) {
+	resolver := localfs.NewResolver(resources)
+	dirRef, err := resolver.ResolveDirectory(id)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := localfs.OpenDir(dirRef)
+	if err != nil {
+		return "", err
+	}
+	defer dir.Close()
+
+	return dir.Path(), nil
+}
+
+// localFilePath resolves a file resource and returns its absolute path.
+//
+// If the file could not be resolved or does not exist, it returns an error.
+func localFilePath(resources lbdeploy.FileSystemResources, id lbdeploy.FileResourceID) (string, error) {
+	resolver := localfs.NewResolver(resources)
+	fileRef, err := resolver.ResolveFile(id)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := localfs.OpenFile(fileRef)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return file.Path(), nil
+}
@@ -0,0 +1,179 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lblogon"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// logonDir is the name of the directory in ProgramData\LeafBridge that
+	// holds logon registrations.
+	logonDir = "Logon"
+
+	// logonExecutable is the name of the copy of LeafBridge that is started
+	// when each user logs on.
+	logonExecutable = "leafbridge-deploy.exe"
+
+	// logonRunKeyPath is the registry key that holds the programs that
+	// Windows starts when each user logs on.
+	logonRunKeyPath = `SOFTWARE\Microsoft\Windows\CurrentVersion\Run`
+
+	// logonRunValue is the name of the registry value that starts
+	// LeafBridge when each user logs on.
+	logonRunValue = "LeafBridge Logon"
+)
+
+// machineLogonDir returns the directory that holds the logon registrations
+// of the machine, which is kept in ProgramData\LeafBridge\Logon.
+func machineLogonDir() (lblogon.Dir, error) {
+	base, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return "", err
+	}
+	return lblogon.Dir(filepath.Join(base, stagingfs.RootDir, logonDir)), nil
+}
+
+// registerLogonFlow registers a flow of the deployment to be run in the
+// context of each user the next time they log on.
+func (engine *actionEngine) registerLogonFlow() error {
+	flow := engine.action.Definition.Flow
+
+	var reg lblogon.Registration
+	err := func() error {
+		dir, err := machineLogonDir()
+		if err != nil {
+			return err
+		}
+		if reg, err = dir.Register(engine.deployment, flow, time.Now()); err != nil {
+			return err
+		}
+		return installLogonRunner()
+	}()
+
+	engine.events.Record(lbdeployevent.LogonFlowRegistered{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		LogonFlow:   flow,
+		Registered:  reg.Registered,
+		Err:         err,
+	})
+
+	return err
+}
+
+// installLogonRunner makes sure that LeafBridge is started when each user
+// logs on, so that registered flows can be run.
+//
+// The running executable is copied to ProgramData\LeafBridge, so that it
+// remains available after the deployment has finished. If the copy cannot
+// be updated because it is in use, the existing copy is kept.
+func installLogonRunner() error {
+	base, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(base, stagingfs.RootDir, logonExecutable)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+	if !strings.EqualFold(filepath.Clean(exe), target) {
+		if err := copyLogonExecutable(exe, target); err != nil {
+			if _, statErr := os.Stat(target); statErr != nil {
+				return fmt.Errorf("failed to copy the running executable to \"%s\": %w", target, err)
+			}
+		}
+	}
+
+	key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, logonRunKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open the \"%s\" registry key: %w", logonRunKeyPath, err)
+	}
+	defer key.Close()
+
+	return key.SetStringValue(logonRunValue, fmt.Sprintf("\"%s\" logon", target))
+}
+
+// copyLogonExecutable copies the executable at source to target.
+func copyLogonExecutable(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// RunLogonFlows runs the logon flows that have been registered on the
+// machine and that have not yet been run by the current user for their
+// current registration.
+//
+// Each flow is run at most once per registration. A flow that fails is
+// attempted again at the next logon. The errors of all failed flows are
+// returned together.
+func RunLogonFlows(ctx context.Context, opts Options) error {
+	dir, err := machineLogonDir()
+	if err != nil {
+		return err
+	}
+	entries, err := dir.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to read the logon registrations: %w", err)
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		store, err := openFlowState(entry.Deployment.ID, lbdeploy.FrequencyPerUser)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("the \"%s\" deployment failed to open its state store: %w", entry.Deployment.ID, err))
+			continue
+		}
+		for _, reg := range entry.Registrations {
+			pending, err := store.LogonPending(reg.Flow, reg.Registered)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("the \"%s\" logon flow of the \"%s\" deployment failed to read its logon history: %w", reg.Flow, entry.Deployment.ID, err))
+				continue
+			}
+			if !pending {
+				continue
+			}
+			if err := NewDeploymentEngine(entry.Deployment, opts).Invoke(ctx, reg.Flow); err != nil {
+				errs = append(errs, fmt.Errorf("the \"%s\" logon flow of the \"%s\" deployment failed: %w", reg.Flow, entry.Deployment.ID, err))
+				continue
+			}
+			if err := store.RecordLogon(reg.Flow, reg.Registered); err != nil {
+				errs = append(errs, fmt.Errorf("the \"%s\" logon flow of the \"%s\" deployment failed to record its completion: %w", reg.Flow, entry.Deployment.ID, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,69 @@
+package lbengine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leafbridge/leafbridge/internal/logfile"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+)
+
+// createMSILog creates an empty log file with a unique name in the command's
+// log directory, and returns its path. If logging has not been configured for
+// the command, it returns an empty string.
+func (engine *commandEngine) createMSILog() (path string, err error) {
+	dirID := engine.command.Definition.MSI.Log.Directory
+	if dirID == "" {
+		return "", nil
+	}
+
+	// Open the log directory.
+	resolver := localfs.NewResolver(engine.deployment.Resources.FileSystem)
+	dirRef, err := resolver.ResolveDirectory(dirID)
+	if err != nil {
+		return "", fmt.Errorf("the msi log directory \"%s\" could not be resolved: %w", dirID, err)
+	}
+
+	dir, err := localfs.OpenDir(dirRef)
+	if err != nil {
+		return "", fmt.Errorf("the msi log directory \"%s\" could not be opened: %w", dirID, err)
+	}
+	defer dir.Close()
+
+	// Build a file name from the deployment, package, command and run,
+	// followed by the current time.
+	parts := []string{string(engine.deployment.ID)}
+	if engine.pkg.ID != "" {
+		parts = append(parts, string(engine.pkg.ID))
+	}
+	parts = append(parts, string(engine.command.ID))
+	if run := engine.events.Origin.RunID; run != "" {
+		parts = append(parts, string(run))
+	}
+	parts = append(parts, time.Now().UTC().Format("20060102T150405Z"))
+	base := logfile.Name(parts...)
+
+	// Create the file exclusively, so that the log of another invocation is
+	// never overwritten.
+	for i := 0; i < 100; i++ {
+		name := base + ".log"
+		if i > 0 {
+			name = fmt.Sprintf("%s.%d.log", base, i)
+		}
+		file, err := dir.System().OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("the msi log file could not be created: %w", err)
+		}
+		file.Close()
+		return filepath.Join(dir.Path(), name), nil
+	}
+
+	return "", fmt.Errorf("a unique msi log file name could not be found in the \"%s\" directory", dirID)
+}
@@ -0,0 +1,65 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/platform/windows/msiexecute"
+)
+
+// installerPollInterval is the interval at which an msi-based command checks
+// whether another Windows Installer operation has finished.
+const installerPollInterval = 5 * time.Second
+
+// waitForInstaller waits for any Windows Installer operation that is in
+// progress to finish, so that the command doesn't fail with
+// ERROR_INSTALL_ALREADY_RUNNING. If a wait was necessary, it is recorded
+// as an event.
+//
+// If the operation doesn't finish within the busy timeout of the command,
+// an error is returned.
+func (engine *commandEngine) waitForInstaller(ctx context.Context) error {
+	// If the installer isn't busy, or if its state can't be determined,
+	// run the command right away.
+	if busy, err := msiexecute.Busy(); err != nil || !busy {
+		return nil
+	}
+
+	// Try to identify the installation that is in progress.
+	blocker, _ := msiexecute.Blocker()
+
+	started := time.Now()
+	timeout := engine.command.Definition.MSI.EffectiveBusyTimeout()
+
+	err := func() error {
+		for {
+			if err := sleepWithContext(ctx, installerPollInterval); err != nil {
+				return err
+			}
+			if busy, err := msiexecute.Busy(); err != nil || !busy {
+				return nil
+			}
+			if time.Since(started) >= timeout {
+				return lberror.New(lberror.Installer, fmt.Sprintf("another Windows Installer operation did not finish within %s", timeout))
+			}
+		}
+	}()
+
+	engine.events.Record(lbdeployevent.InstallerWait{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Package:     engine.pkg.ID,
+		Command:     engine.command.ID,
+		Blocker:     blocker,
+		Started:     started,
+		Stopped:     time.Now(),
+		Err:         err,
+	})
+
+	return err
+}
@@ -1,9 +1,45 @@
 package lbengine
 
-import "github.com/leafbridge/leafbridge/core/lbevent"
+import (
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbbundle"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
 
 // Options hold configuration options for a LeafBridge deployment engine.
+//
+// If Timeout is non-zero, it overrides the timeout of the deployment.
+//
+// If Bundle is non-nil, the deployment is invoked entirely from the bundle.
+// Package files are copied from the bundle and verified, and no network
+// requests are made.
+//
+// If Agent is true, the engine is running unattended on behalf of an agent,
+// and disruptive flows are deferred until the machine is idle.
+//
+// If Reverify is true, staged package files are hashed again even when a
+// verification record from an earlier run says that they can be trusted.
+//
+// If Resume is true, flows that were interrupted by a crash or a restart
+// of the system continue from their last completed action, as recorded in
+// their checkpoints.
+//
+// If TraceConditions is true, an event is recorded for each condition that
+// is evaluated, describing what was observed on the system.
+//
+// If Architecture is provided, packages are selected for that architecture
+// instead of the native architecture of the system. This is used to bundle
+// packages for other systems.
 type Options struct {
-	Events lbevent.Recorder
-	Force  bool
+	Events          lbevent.Recorder
+	Force           bool
+	Timeout         time.Duration
+	Bundle          *lbbundle.Bundle
+	Agent           bool
+	Reverify        bool
+	Resume          bool
+	TraceConditions bool
+	Architecture    lbdeploy.Architecture
 }
@@ -0,0 +1,141 @@
+package lbengine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"unicode/utf8"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/internal/outputbuffer"
+	"github.com/leafbridge/leafbridge/platform/windows/codepage"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+	"github.com/leafbridge/leafbridge/utility/bytesconv"
+)
+
+// outputBuffer captures the output of a command or script within the limits
+// of its output options, and decodes it with the output encoding of the
+// command or script.
+type outputBuffer struct {
+	*outputbuffer.Buffer
+	encoding lbdeploy.OutputEncoding
+}
+
+// newOutputBuffer returns an output buffer that captures output according
+// to opts and decodes it with the given encoding.
+func newOutputBuffer(opts lbdeploy.OutputOptions, encoding lbdeploy.OutputEncoding) outputBuffer {
+	// Keep the retained output aligned, in case it is UTF-16.
+	return outputBuffer{
+		Buffer:   outputbuffer.New(opts, true),
+		encoding: encoding,
+	}
+}
+
+// String returns the captured output as a string. If any of the output was
+// omitted, a note indicating how much was omitted is included in its place.
+func (b outputBuffer) String() string {
+	head, tail := b.Bytes()
+
+	// The tail won't include a byte order mark, so decode it with the same
+	// encoding that was detected for the head.
+	tailEncoding := b.encoding
+	if len(head) > 0 && (tailEncoding == "" || tailEncoding == lbdeploy.OutputEncodingAuto) {
+		switch {
+		case bytesconv.HasUTF16BOM(head, binary.LittleEndian):
+			tailEncoding = lbdeploy.OutputEncodingUTF16LE
+		case bytesconv.HasUTF16BOM(head, binary.BigEndian):
+			tailEncoding = lbdeploy.OutputEncodingUTF16BE
+		}
+	}
+
+	// If the output was cut off in the middle of a UTF-8 sequence, drop the
+	// incomplete sequence.
+	switch {
+	case b.Discarded() == 0:
+	case tailEncoding == lbdeploy.OutputEncodingUTF16LE, tailEncoding == lbdeploy.OutputEncodingUTF16BE:
+	default:
+		head = outputbuffer.TrimPartialUTF8(head, false)
+		tail = outputbuffer.TrimPartialUTF8(tail, true)
+	}
+
+	return b.Format(decodeOutput(head, b.encoding), decodeOutput(tail, tailEncoding))
+}
+
+// createOutputFile creates or truncates the file resource that will hold
+// the complete output of a command or script.
+func createOutputFile(resources lbdeploy.FileSystemResources, id lbdeploy.FileResourceID) (*os.File, error) {
+	resolver := localfs.NewResolver(resources)
+	ref, err := resolver.ResolveFile(id)
+	if err != nil {
+		return nil, err
+	}
+	path, err := ref.Path()
+	if err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// decodeOutput interprets the output of a command or script as text with
+// the given encoding and returns it as a string.
+//
+// If the encoding is not specified, it is detected. Output with a unicode
+// byte order mark, output that is valid UTF-8, and output that appears to be
+// UTF-16 is decoded accordingly. Anything else is assumed to be encoded with
+// the system's OEM code page, which is used by most console programs.
+func decodeOutput(data []byte, encoding lbdeploy.OutputEncoding) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	switch encoding {
+	case lbdeploy.OutputEncodingUTF8:
+		return string(bytes.ToValidUTF8(data, []byte("\uFFFD")))
+	case lbdeploy.OutputEncodingUTF16LE:
+		if bytesconv.HasUTF16BOM(data, binary.LittleEndian) {
+			data = data[2:]
+		}
+		return bytesconv.DecodeUTF16(data, binary.LittleEndian)
+	case lbdeploy.OutputEncodingUTF16BE:
+		if bytesconv.HasUTF16BOM(data, binary.BigEndian) {
+			data = data[2:]
+		}
+		return bytesconv.DecodeUTF16(data, binary.BigEndian)
+	case lbdeploy.OutputEncodingOEM:
+		return decodeCodePage(data, codepage.OEM)
+	case lbdeploy.OutputEncodingANSI:
+		return decodeCodePage(data, codepage.ANSI)
+	case lbdeploy.OutputEncodingAuto:
+	default:
+		if cp, ok := encoding.CodePage(); ok {
+			return decodeCodePage(data, codepage.CodePage(cp))
+		}
+	}
+
+	// Detect the encoding.
+	switch {
+	case bytesconv.HasUTF16BOM(data, binary.LittleEndian):
+		return bytesconv.DecodeUTF16(data[2:], binary.LittleEndian)
+	case bytesconv.HasUTF16BOM(data, binary.BigEndian):
+		return bytesconv.DecodeUTF16(data[2:], binary.BigEndian)
+	case utf8.Valid(data) && !bytes.ContainsRune(data, 0):
+		return string(data)
+	case bytesconv.LooksLikeUTF16(data, binary.LittleEndian):
+		return bytesconv.DecodeUTF16(data, binary.LittleEndian)
+	case bytesconv.LooksLikeUTF16(data, binary.BigEndian):
+		return bytesconv.DecodeUTF16(data, binary.BigEndian)
+	default:
+		return decodeCodePage(data, codepage.OEM)
+	}
+}
+
+// decodeCodePage interprets data as text in the given code page. If the
+// conversion fails, the data is returned as a Base64 raw URL-encoded string.
+func decodeCodePage(data []byte, cp codepage.CodePage) string {
+	s, err := codepage.Decode(data, cp)
+	if err != nil {
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	return s
+}
@@ -3,6 +3,7 @@ package lbengine
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
 	"github.com/leafbridge/leafbridge/core/lbdeployevent"
@@ -30,6 +31,11 @@ type packageEngine struct {
 
 // preparePackage performs a package preparation action.
 func (engine *packageEngine) PreparePackage(ctx context.Context) error {
+	// Retrieve the package's hash from its checksums file, if necessary.
+	if err := engine.resolveChecksums(ctx); err != nil {
+		return err
+	}
+
 	// Open the package file, or create it if it doesn't exist.
 	file, err := engine.openPackageFile()
 	if err != nil {
@@ -71,6 +77,23 @@ func (engine *packageEngine) InvokeCommand(ctx context.Context, command lbdeploy
 		return fmt.Errorf("the evaluation of potential application changes did not succeed: %w", err)
 	}
 
+	// If the command would modify an app that is held on this machine,
+	// skip it, even if command invocation is forced.
+	if app, hold, held := heldApp(engine.state, engine.deployment, commandDefinition); held {
+		engine.events.Record(lbdeployevent.CommandSkipped{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Package:     engine.pkg.ID,
+			Command:     command,
+			Apps:        appEvaluation,
+			HeldApp:     app,
+			HoldReason:  hold.Reason,
+		})
+		return nil
+	}
+
 	// If the command declares that it installs or uninstalls something,
 	// review the app evaluation to determine whether any application changes
 	// are anticpated.
@@ -118,6 +141,12 @@ func (engine *packageEngine) invokePackageCommand(ctx context.Context, command c
 	// the package file.
 	packageDir, alreadyVerified := engine.state.verifiedPackageFiles[engine.pkg.ID]
 	if !alreadyVerified {
+		// Retrieve the package's hash from its checksums file, if
+		// necessary.
+		if err := engine.resolveChecksums(ctx); err != nil {
+			return err
+		}
+
 		// Prepare the package directory.
 		var err error
 		packageDir, err = engine.openPackageDir()
@@ -134,6 +163,10 @@ func (engine *packageEngine) invokePackageCommand(ctx context.Context, command c
 			}
 			defer packageFile.Close()
 
+			// Exclude the staging directory from real-time antivirus
+			// scanning, if requested.
+			excludeFromAntivirus(ctx, engine.deployment, engine.events, engine.state, filepath.Dir(packageFile.Path))
+
 			// Prepare a download engine.
 			de := downloadEngine{
 				deployment: engine.deployment,
@@ -196,8 +229,24 @@ func (engine *packageEngine) invokeArchiveCommand(ctx context.Context, command c
 	// extracted the files in this package.
 	extractedFiles, alreadyExtracted := engine.state.extractedPackages[engine.pkg.ID]
 
+	// If a resumed flow extracted the package before it was interrupted,
+	// use the files it extracted.
+	if !alreadyExtracted {
+		extractedFiles, alreadyExtracted = engine.state.resumeExtraction(engine.deployment.ID, engine.pkg)
+		if alreadyExtracted {
+			excludeFromAntivirus(ctx, engine.deployment, engine.events, engine.state, extractedFiles.Path())
+			engine.state.extractedPackages[engine.pkg.ID] = extractedFiles
+		}
+	}
+
 	// Download, verify and extract the package if we haven't done so already.
 	if !alreadyExtracted {
+		// Retrieve the package's hash from its checksums file, if
+		// necessary.
+		if err := engine.resolveChecksums(ctx); err != nil {
+			return err
+		}
+
 		// Open the package file, or create it if it doesn't exist.
 		packageFile, err := engine.openPackageFile()
 		if err != nil {
@@ -205,6 +254,10 @@ func (engine *packageEngine) invokeArchiveCommand(ctx context.Context, command c
 		}
 		defer packageFile.Close()
 
+		// Exclude the staging directory from real-time antivirus scanning,
+		// if requested.
+		excludeFromAntivirus(ctx, engine.deployment, engine.events, engine.state, filepath.Dir(packageFile.Path))
+
 		// Prepare a download engine.
 		de := downloadEngine{
 			deployment: engine.deployment,
@@ -230,16 +283,22 @@ func (engine *packageEngine) invokeArchiveCommand(ctx context.Context, command c
 			PrimaryHash: engine.pkg.Definition.Attributes.Hashes.Primary(),
 		}, tempfs.Options{
 			DeleteOnClose: true,
+			Scope:         tempScope(engine.deployment.ID, engine.events),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to prepare a directory for file extraction: %w", err)
 		}
 
+		// Exclude the extraction directory from real-time antivirus
+		// scanning, if requested.
+		excludeFromAntivirus(ctx, engine.deployment, engine.events, engine.state, extractedFiles.Path())
+
 		// Prepare an extraction engine.
 		ee := extractionEngine{
 			deployment: engine.deployment,
 			flow:       engine.flow,
 			action:     engine.action,
+			pkg:        engine.pkg,
 			events:     engine.events,
 			state:      engine.state,
 		}
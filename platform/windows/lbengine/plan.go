@@ -0,0 +1,39 @@
+package lbengine
+
+import (
+	"context"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbplan"
+	"github.com/leafbridge/leafbridge/platform/windows/winplatform"
+)
+
+// Plan describes what a flow within the deployment would do on the local
+// system, without changing it. It returns the steps of the plan in order.
+//
+// The packages that suit the architecture of the system are selected
+// first, and file and directory resources are described by their local
+// paths.
+func (engine DeploymentEngine) Plan(ctx context.Context, flow lbdeploy.FlowID) ([]lbplan.Step, error) {
+	// Ensure that the deployment is valid.
+	if err := engine.deployment.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Select the packages that suit the architecture of the system.
+	dep, _, err := engine.selectArchitecture()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := dep.Resources.FileSystem
+	return lbplan.Make(ctx, dep, flow, winplatform.New(), lbplan.Options{
+		Force: engine.force,
+		ResolveFile: func(id lbdeploy.FileResourceID) (string, error) {
+			return localFilePath(resources, id)
+		},
+		ResolveDir: func(id lbdeploy.DirectoryResourceID) (string, error) {
+			return localDirPath(resources, id)
+		},
+	})
+}
@@ -0,0 +1,110 @@
+package lbengine
+
+import (
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/core/lbpolicy"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+	"golang.org/x/sys/windows"
+)
+
+// policyFile is the name of the file that holds the execution policy of
+// the machine.
+const policyFile = "policy.json"
+
+// machinePolicyPath returns the path of the machine's execution policy,
+// which is kept in ProgramData\LeafBridge. Administrators that rely on the
+// policy should make sure that only they can modify it.
+func machinePolicyPath() (string, error) {
+	base, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, stagingfs.RootDir, policyFile), nil
+}
+
+// MachinePolicy returns the execution policy of the machine. If the machine
+// doesn't have one, a zero policy is returned.
+func MachinePolicy() (lbpolicy.Policy, error) {
+	path, err := machinePolicyPath()
+	if err != nil {
+		return lbpolicy.Policy{}, err
+	}
+	return lbpolicy.Load(path)
+}
+
+// checkPolicy returns an error if the execution policy of the machine
+// does not permit the deployment. A violation is recorded as an event.
+//
+// If the policy can't be read, the deployment is refused.
+//
+// The app holds of the machine are loaded as well, and an error is returned
+// if they can't be read.
+//
+// The approval of the deployment is checked as well. If the policy requires
+// approval and the deployment doesn't satisfy it, the deployment is refused
+// entirely. The scope of a flow is declared by the deployment itself, so it
+// can't be relied upon to decide which flows need approval.
+func (engine DeploymentEngine) checkPolicy(flow lbdeploy.FlowID) error {
+	path, err := machinePolicyPath()
+	if err != nil {
+		return lberror.Wrap(lberror.Policy, err)
+	}
+	policy, err := lbpolicy.Load(path)
+	if err != nil {
+		return lberror.Wrap(lberror.Policy, err)
+	}
+	if err := policy.Check(engine.deployment); err != nil {
+		engine.events.Record(lbdeployevent.PolicyViolation{
+			Deployment: engine.deployment.ID,
+			Flow:       flow,
+			PolicyFile: path,
+			Err:        err,
+		})
+		return err
+	}
+	if err := engine.checkApproval(policy.Approval, flow); err != nil {
+		return err
+	}
+
+	// Collect the app holds of the machine, which are placed either by
+	// the policy or from the command line.
+	holdsPath, err := MachineHoldsPath()
+	if err != nil {
+		return lberror.Wrap(lberror.Policy, err)
+	}
+	holds, err := lbpolicy.LoadHolds(holdsPath)
+	if err != nil {
+		return lberror.Wrap(lberror.Policy, err)
+	}
+	engine.state.holds = slices.Concat(policy.Holds, holds)
+
+	return nil
+}
+
+// checkApproval verifies the approval of the deployment against the given
+// requirement, and records the approval for auditing if the deployment
+// carries one or the requirement demands one.
+func (engine DeploymentEngine) checkApproval(req lbpolicy.ApprovalRequirement, flow lbdeploy.FlowID) error {
+	approval := engine.deployment.Approval
+	verified, err := req.Verify(engine.deployment, time.Now())
+	if approval.IsZero() && !req.Required {
+		return nil
+	}
+	engine.events.Record(lbdeployevent.DeploymentApproval{
+		Deployment: engine.deployment.ID,
+		Flow:       flow,
+		Ticket:     approval.Ticket,
+		Approvers:  approval.Approvers,
+		Verified:   verified,
+		Expires:    approval.Expires,
+		Required:   req.Required,
+		Err:        err,
+	})
+	return err
+}
@@ -0,0 +1,121 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+	"github.com/leafbridge/leafbridge/platform/windows/powerscheme"
+)
+
+// setPowerPlan makes the power plan of the action active. If the action
+// has a source file, the plan is imported from it first. Built-in plans
+// that Windows hides, such as Ultimate Performance, are restored before
+// they are made active.
+func (engine *actionEngine) setPowerPlan(ctx context.Context) error {
+	plan := engine.action.Definition.PowerPlan
+	guid := plan.GUID()
+
+	var (
+		previous string
+		imported bool
+	)
+	err := func() error {
+		var err error
+		if previous, err = powerscheme.Active(); err != nil {
+			return err
+		}
+
+		if engine.action.Definition.SourceFile != "" {
+			resolver := localfs.NewResolver(engine.deployment.Resources.FileSystem)
+			sourceRef, err := resolver.ResolveFile(engine.action.Definition.SourceFile)
+			if err != nil {
+				return fmt.Errorf("source file: %w", err)
+			}
+			sourcePath, err := sourceRef.Path()
+			if err != nil {
+				return fmt.Errorf("source file: %w", err)
+			}
+			if err := powerscheme.Import(ctx, sourcePath, guid); err != nil {
+				return fmt.Errorf("failed to import the power plan: %w", err)
+			}
+			imported = true
+		} else if previous == guid {
+			return nil
+		} else if plan.IsBuiltIn() {
+			exists, err := powerscheme.Exists(guid)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				if err := powerscheme.Restore(ctx, guid); err != nil {
+					return fmt.Errorf("failed to restore the built-in power plan: %w", err)
+				}
+			}
+		}
+
+		return powerscheme.SetActive(ctx, guid)
+	}()
+
+	engine.events.Record(lbdeployevent.PowerPlanChange{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Plan:        plan,
+		Previous:    previous,
+		Imported:    imported,
+		Err:         err,
+	})
+
+	return err
+}
+
+// configurePowerSettings applies the power settings of the action in
+// order. It stops at the first setting that fails.
+func (engine *actionEngine) configurePowerSettings(ctx context.Context) error {
+	for _, setting := range engine.action.Definition.PowerSettings {
+		err := applyPowerSetting(ctx, setting)
+
+		engine.events.Record(lbdeployevent.PowerSettingChange{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Setting:     setting,
+			Err:         err,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPowerSetting applies a single power setting. Timeouts without a
+// power source are applied to both.
+func applyPowerSetting(ctx context.Context, setting lbdeploy.PowerSetting) error {
+	if setting.Name == lbdeploy.PowerSettingHibernation {
+		return powerscheme.SetHibernation(ctx, setting.Enabled)
+	}
+
+	var sources []powerscheme.Source
+	switch setting.Source {
+	case lbdeploy.PowerSourceAC:
+		sources = []powerscheme.Source{powerscheme.AC}
+	case lbdeploy.PowerSourceBattery:
+		sources = []powerscheme.Source{powerscheme.Battery}
+	default:
+		sources = []powerscheme.Source{powerscheme.AC, powerscheme.Battery}
+	}
+
+	for _, source := range sources {
+		if err := powerscheme.SetTimeout(ctx, string(setting.Name), source, setting.Minutes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/leafbridge/leafbridge/platform/windows/jobobject"
+)
+
+// processTree tracks a process and all of its descendants with a job
+// object, so that the entire process tree can be terminated when the
+// process is cancelled.
+//
+// Processes that outlive the command after it exits normally are left
+// running.
+type processTree struct {
+	job        *jobobject.Job
+	terminated []uint32
+	err        error
+}
+
+// newProcessTree prepares a process tree for cmd, which must not have been
+// started yet. It replaces the cancellation function of cmd, so that
+// cancellation of the command's context terminates the whole tree.
+//
+// Once the command has been started, Attach must be called to assign it to
+// the process tree. When the command has finished, Close must be called to
+// release resources.
+func newProcessTree(cmd *exec.Cmd) (*processTree, error) {
+	job, err := jobobject.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &processTree{job: job}
+
+	// This is called from a separate goroutine by the exec package. It
+	// is guaranteed to have finished by the time cmd.Wait returns.
+	cmd.Cancel = func() error {
+		tree.terminated, tree.err = tree.job.ProcessIDs()
+		if err := tree.job.Terminate(1); err != nil {
+			tree.err = errors.Join(tree.err, err)
+		}
+
+		// Kill the process directly as well, in case it hadn't been
+		// attached to the job yet.
+		if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return err
+		}
+		return nil
+	}
+
+	return tree, nil
+}
+
+// Attach assigns the started command to the process tree.
+func (tree *processTree) Attach(cmd *exec.Cmd) error {
+	return tree.job.Assign(cmd.Process.Pid)
+}
+
+// Terminated returns the IDs of the processes that were terminated when
+// the command was cancelled, and any error encountered while terminating
+// them. It returns nil if the command was not cancelled.
+func (tree *processTree) Terminated() ([]uint32, error) {
+	return tree.terminated, tree.err
+}
+
+// Close releases the job object used by the process tree.
+func (tree *processTree) Close() error {
+	return tree.job.Close()
+}
+
+// cancellationReason returns a description of the reason that ctx was
+// cancelled.
+func cancellationReason(ctx context.Context) string {
+	if cause := context.Cause(ctx); cause != nil {
+		return cause.Error()
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package lbengine
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/fileowner"
+)
+
+// protectedPrivileges are the privileges enabled by file actions that
+// are permitted to modify files in protected folders.
+var protectedPrivileges = []fileowner.Privilege{
+	fileowner.TakeOwnershipPrivilege,
+	fileowner.BackupPrivilege,
+	fileowner.RestorePrivilege,
+}
+
+// unprotect prepares the file identified by ref to be modified by the
+// current action. It does nothing if the file is not located in a
+// protected folder.
+//
+// If the file is in a protected folder and the action does not permit
+// changes to protected files, an error is returned. Otherwise the
+// privileges needed to modify protected files are enabled and, if the
+// file exists and can't be modified as-is, ownership of the file is
+// taken. Each of these steps is recorded as a security event.
+//
+// The privileges are only enabled for the calling goroutine, which must
+// be the one that modifies the file.
+//
+// The returned function must be called from the same goroutine when the
+// action is finished. It restores the previous owner of the file, if
+// ownership was taken, and then releases the privileges.
+func (engine *fileEngine) unprotect(ref lbdeploy.FileRef, id lbdeploy.FileResourceID) (release func(), err error) {
+	if !ref.Root.Protected {
+		return func() {}, nil
+	}
+	if !engine.action.Definition.OnProtected.Allowed() {
+		return nil, fmt.Errorf("the \"%s\" file is located in the \"%s\" root, which is protected", id, ref.Root.ID)
+	}
+
+	path, err := ref.Path()
+	if err != nil {
+		return nil, fmt.Errorf("file: %w", err)
+	}
+
+	// Enable the privileges needed to modify protected files.
+	names := make([]string, len(protectedPrivileges))
+	for i, privilege := range protectedPrivileges {
+		names[i] = string(privilege)
+	}
+	privileges, err := fileowner.EnablePrivileges(protectedPrivileges...)
+	engine.recordProtectedChange(lbdeployevent.SecurityChangePrivilege, ref, "enable "+strings.Join(names, ", "), path, "", err)
+	if err != nil {
+		return nil, err
+	}
+	release = func() { privileges.Restore() }
+
+	// If the file exists and can't be modified, take ownership of it.
+	if _, err := os.Lstat(path); err != nil {
+		if os.IsNotExist(err) {
+			return release, nil
+		}
+		release()
+		return nil, fmt.Errorf("unable to evaluate the protected file: %w", err)
+	}
+	if modifiable, err := fileowner.CanModify(path); err != nil {
+		release()
+		return nil, fmt.Errorf("unable to evaluate the protected file: %w", err)
+	} else if modifiable {
+		return release, nil
+	}
+	ownership, err := fileowner.Take(path)
+	engine.recordProtectedChange(lbdeployevent.SecurityChangeOwnership, ref, "take-ownership", path, ownership.Previous(), err)
+	releasePrivileges := release
+	release = func() {
+		if ownership != nil {
+			err := ownership.Restore()
+			engine.recordProtectedChange(lbdeployevent.SecurityChangeOwnership, ref, "restore-ownership", path, ownership.Previous(), err)
+		}
+		releasePrivileges()
+	}
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("unable to take ownership of the protected file: %w", err)
+	}
+
+	return release, nil
+}
+
+// recordProtectedChange records a security audit event for a change made
+// in preparation for modifying a protected file.
+func (engine *fileEngine) recordProtectedChange(kind lbdeployevent.SecurityChangeKind, ref lbdeploy.FileRef, operation, path, previous string, err error) {
+	engine.events.Record(lbdeployevent.SecurityChange{
+		Deployment:    engine.deployment.ID,
+		Flow:          engine.flow.ID,
+		ActionIndex:   engine.action.Index,
+		ActionType:    engine.action.Definition.Type,
+		Kind:          kind,
+		Operation:     operation,
+		Folder:        ref.Root.ID,
+		Target:        path,
+		PreviousOwner: previous,
+		Err:           err,
+	})
+}
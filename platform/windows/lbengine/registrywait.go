@@ -0,0 +1,123 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/localregistry"
+)
+
+// waitForRegistryValue waits until the action's registry condition is
+// satisfied, or until its timeout elapses.
+//
+// Rather than polling, it arms a change notification for the registry key
+// that the condition examines, and evaluates the condition again each time
+// the key changes.
+func (engine *actionEngine) waitForRegistryValue(ctx context.Context) error {
+	id := engine.action.Definition.Condition
+	condition, found := engine.deployment.Conditions[id]
+	if !found {
+		return fmt.Errorf("the condition \"%s\" does not exist within the \"%s\" deployment", id, engine.deployment.ID)
+	}
+
+	// Determine which registry key to watch.
+	ref, err := registryConditionKey(engine.deployment.Resources.Registry, condition)
+	if err != nil {
+		return fmt.Errorf("the \"%s\" condition: %w", id, err)
+	}
+
+	timeout := time.Duration(engine.action.Definition.Timeout)
+	if timeout == 0 {
+		timeout = time.Duration(lbdeploy.DefaultRegistryWaitTimeout)
+	}
+
+	// Record the time that the wait started.
+	started := time.Now()
+	deadline := started.Add(timeout)
+
+	ce := NewConditionEngine(engine.deployment)
+
+	var (
+		path    string
+		changes int
+	)
+	satisfied, err := func() (bool, error) {
+		for {
+			// Arm the notification before evaluating the condition, so
+			// that changes made in between aren't missed.
+			watch, err := localregistry.WatchKey(ref)
+			if err != nil {
+				return false, err
+			}
+			path = watch.Path()
+
+			result, err := ce.Evaluate(id)
+			if err != nil || result {
+				watch.Close()
+				return result, err
+			}
+
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				watch.Close()
+				return false, nil
+			}
+
+			changed, err := watch.Wait(ctx, remaining)
+			watch.Close()
+			if err != nil {
+				return false, err
+			}
+			if changed {
+				changes++
+			}
+		}
+	}()
+
+	// Record the time that the wait stopped.
+	stopped := time.Now()
+
+	// Record the outcome of the wait.
+	engine.events.Record(lbdeployevent.RegistryWait{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Condition:   id,
+		Path:        path,
+		Changes:     changes,
+		Satisfied:   satisfied,
+		Started:     started,
+		Stopped:     stopped,
+		Err:         err,
+	})
+
+	if err != nil {
+		return err
+	}
+	if !satisfied {
+		return fmt.Errorf("the \"%s\" condition was not satisfied within %s", id, timeout)
+	}
+	return nil
+}
+
+// registryConditionKey returns a reference to the registry key that is
+// examined by a registry condition.
+func registryConditionKey(resources lbdeploy.RegistryResources, condition lbdeploy.Condition) (lbdeploy.RegistryKeyRef, error) {
+	resolver := localregistry.NewResolver(resources)
+	switch condition.Type {
+	case lbdeploy.ConditionTypeRegistryKeyExists:
+		return resolver.ResolveKey(lbdeploy.RegistryKeyResourceID(condition.Subject))
+	case lbdeploy.ConditionTypeRegistryValueExists, lbdeploy.ConditionTypeRegistryValueComparison:
+		ref, err := resolver.ResolveValue(lbdeploy.RegistryValueResourceID(condition.Subject))
+		if err != nil {
+			return lbdeploy.RegistryKeyRef{}, err
+		}
+		return lbdeploy.RegistryKeyRef{Root: ref.Root, Lineage: ref.Lineage}, nil
+	default:
+		return lbdeploy.RegistryKeyRef{}, fmt.Errorf("the condition type \"%s\" does not examine the registry", condition.Type)
+	}
+}
@@ -0,0 +1,107 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbstate"
+)
+
+// Remediate evaluates the compliance of the deployment. If drift is
+// detected, each flow that is remediated on drift is invoked, unless it is
+// held back by its remediation limits. If any flows were invoked, the
+// compliance of the deployment is evaluated again.
+//
+// The outcome of each evaluation is kept in the machine's state store, so
+// that remediation limits are enforced across invocations. The report of
+// the final evaluation is returned.
+func (engine DeploymentEngine) Remediate(ctx context.Context) (lbdeploy.ComplianceReport, error) {
+	// Ensure that the deployment is valid.
+	if err := engine.deployment.Validate(); err != nil {
+		return lbdeploy.ComplianceReport{}, err
+	}
+
+	store, err := openFlowState(engine.deployment.ID, lbdeploy.FrequencyPerMachine)
+	if err != nil {
+		return lbdeploy.ComplianceReport{}, fmt.Errorf("the \"%s\" deployment failed to open its state store: %w", engine.deployment.ID, err)
+	}
+
+	// Evaluate the compliance of the deployment.
+	report, record, err := engine.evaluateCompliance(store)
+	if err != nil || report.Compliant() {
+		return report, err
+	}
+
+	// Invoke each remediation flow that is permitted by its limits.
+	var (
+		invoked int
+		errs    []error
+	)
+	for _, flow := range engine.deployment.RemediationFlows() {
+		remediation := engine.deployment.Flows[flow].Remediation
+		if hold := record.Hold(flow, remediation, time.Now()); hold != lbstate.RemediationPermitted {
+			engine.events.Record(lbdeployevent.RemediationHeld{
+				Deployment:       engine.deployment.ID,
+				Flow:             flow,
+				Hold:             hold,
+				ConsecutiveDrift: record.ConsecutiveDrift,
+				Attempts:         record.Remediations[flow].Attempts,
+				NextAttempt:      record.NextAttempt(flow, remediation),
+			})
+			continue
+		}
+
+		// Record the attempt before the flow is invoked, so that an attempt
+		// that never finishes still counts toward the limit.
+		if err := store.RecordRemediation(flow, time.Now()); err != nil {
+			return report, fmt.Errorf("the \"%s\" flow failed to record its remediation attempt: %w", flow, err)
+		}
+		engine.events.Record(lbdeployevent.RemediationStarted{
+			Deployment:  engine.deployment.ID,
+			Flow:        flow,
+			Attempt:     record.Remediations[flow].Attempts + 1,
+			MaxAttempts: remediation.EffectiveMaxAttempts(),
+		})
+		invoked++
+
+		if err := engine.Invoke(ctx, flow); err != nil {
+			errs = append(errs, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return report, errors.Join(append(errs, err)...)
+		}
+	}
+
+	// Evaluate the compliance of the deployment again, now that it has
+	// been remediated.
+	if invoked > 0 {
+		var err error
+		report, _, err = engine.evaluateCompliance(store)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// evaluateCompliance evaluates the compliance of the deployment, records a
+// compliance report event and stores the outcome in the given store.
+func (engine DeploymentEngine) evaluateCompliance(store lbstate.Store) (lbdeploy.ComplianceReport, lbstate.ComplianceRecord, error) {
+	report := NewComplianceEngine(engine.deployment).Evaluate()
+	engine.events.Record(lbdeployevent.ComplianceReport{
+		Deployment: engine.deployment.ID,
+		Report:     report,
+	})
+
+	record, err := store.RecordEvaluation(report.Compliant(), time.Now())
+	if err != nil {
+		return report, record, fmt.Errorf("the \"%s\" deployment failed to record its compliance: %w", engine.deployment.ID, err)
+	}
+
+	return report, record, nil
+}
@@ -0,0 +1,147 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+	"github.com/leafbridge/leafbridge/platform/windows/restartmgr"
+)
+
+// maxRestartManagerFiles limits the number of files that are registered
+// with the Restart Manager for the directories of a command.
+const maxRestartManagerFiles = 4096
+
+// errRestartManagerFileLimit is returned when walking a directory would
+// exceed maxRestartManagerFiles.
+var errRestartManagerFileLimit = errors.New("too many files")
+
+// startRestartManager identifies the applications that are using the files
+// that the command will replace, and shuts them down if the command calls
+// for it. It returns a function that must be called after the command has
+// finished, which restarts the applications if requested.
+//
+// Restart Manager failures are recorded but are not fatal, because the
+// command might still succeed, perhaps with a reboot.
+func (engine *commandEngine) startRestartManager(ctx context.Context) (finish func()) {
+	rm := engine.command.Definition.RestartManager
+	if rm.IsZero() {
+		return func() {}
+	}
+
+	record := func(op lbdeployevent.RestartManagerOperation, files int, apps []lbdeployevent.FileLocker, err error) {
+		engine.events.Record(lbdeployevent.RestartManager{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			Package:     engine.pkg.ID,
+			Command:     engine.command.ID,
+			Operation:   op,
+			Files:       files,
+			Apps:        apps,
+			Err:         err,
+		})
+	}
+
+	// Collect the paths of the files that the command will replace.
+	paths, err := engine.restartManagerFiles()
+	if err != nil {
+		record(lbdeployevent.RestartManagerDetect, 0, nil, err)
+		return func() {}
+	}
+
+	// Start a session and identify the applications using the files.
+	session, err := restartmgr.Start()
+	if err != nil {
+		record(lbdeployevent.RestartManagerDetect, len(paths), nil, err)
+		return func() {}
+	}
+	if err := session.RegisterFiles(paths...); err != nil {
+		session.Close()
+		record(lbdeployevent.RestartManagerDetect, len(paths), nil, err)
+		return func() {}
+	}
+	processes, err := session.Processes()
+	apps := make([]lbdeployevent.FileLocker, len(processes))
+	for i, process := range processes {
+		apps[i] = lbdeployevent.FileLocker{
+			ProcessID: process.ID,
+			Name:      process.Name,
+			Service:   process.Service,
+		}
+	}
+	record(lbdeployevent.RestartManagerDetect, len(paths), apps, err)
+
+	// Shut down the applications, if requested.
+	if err != nil || len(apps) == 0 || !rm.Shutdown || ctx.Err() != nil {
+		session.Close()
+		return func() {}
+	}
+	if err := session.Shutdown(true); err != nil {
+		session.Close()
+		record(lbdeployevent.RestartManagerShutdown, len(paths), apps, err)
+		return func() {}
+	}
+	record(lbdeployevent.RestartManagerShutdown, len(paths), apps, nil)
+
+	return func() {
+		defer session.Close()
+		if rm.Restart {
+			record(lbdeployevent.RestartManagerRestart, len(paths), apps, session.Restart())
+		}
+	}
+}
+
+// restartManagerFiles returns the paths of the files identified by the
+// command's restart manager configuration.
+func (engine *commandEngine) restartManagerFiles() ([]string, error) {
+	rm := engine.command.Definition.RestartManager
+	resolver := localfs.NewResolver(engine.deployment.Resources.FileSystem)
+
+	var paths []string
+	for _, id := range rm.Files {
+		ref, err := resolver.ResolveFile(id)
+		if err != nil {
+			return nil, err
+		}
+		path, err := ref.Path()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	for _, id := range rm.Directories {
+		ref, err := resolver.ResolveDirectory(id)
+		if err != nil {
+			return nil, err
+		}
+		root, err := ref.Path()
+		if err != nil {
+			return nil, err
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			// Files and directories that can't be read are skipped.
+			if err != nil || !d.Type().IsRegular() {
+				return nil
+			}
+			if len(paths) >= maxRestartManagerFiles {
+				return errRestartManagerFileLimit
+			}
+			paths = append(paths, path)
+			return nil
+		})
+		if errors.Is(err, errRestartManagerFileLimit) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,41 @@
+package lbengine
+
+import (
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/restorepoint"
+)
+
+// beginRestorePoint creates a system restore point before the flow runs
+// its actions, if the flow requests one. It returns a function that must
+// be called when the flow's actions have finished.
+//
+// Failure to create a restore point is recorded, but does not prevent the
+// flow from running.
+func (engine flowEngine) beginRestorePoint() (end func()) {
+	if !engine.flow.Definition.RestorePoint {
+		return func() {}
+	}
+
+	description := fmt.Sprintf("LeafBridge: %s: %s", engine.deployment.ID, engine.flow.ID)
+	point, err := restorepoint.Begin(description, restorepoint.ApplicationInstall)
+
+	engine.events.Record(lbdeployevent.FlowRestorePoint{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		Description: description,
+		Sequence:    point.Sequence,
+		Err:         err,
+	})
+
+	if err != nil {
+		return func() {}
+	}
+
+	// The restore point is ended even if the flow fails, so that it remains
+	// available for rolling back the failed change.
+	return func() {
+		point.End()
+	}
+}
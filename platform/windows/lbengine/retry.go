@@ -0,0 +1,84 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"golang.org/x/sys/windows"
+)
+
+// httpStatusError is returned when an HTTP server responds with an
+// unexpected status code.
+type httpStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+// Error returns a description of the error.
+func (err httpStatusError) Error() string {
+	return fmt.Sprintf("the server returned an unexpected status code: %s", err.Status)
+}
+
+// Category returns the category of the error. Unexpected responses are
+// usually caused by servers or proxies that refuse the request.
+func (err httpStatusError) Category() lberror.Category {
+	return lberror.Network
+}
+
+// Transient returns true if the status code indicates a condition that
+// might be resolved by trying again.
+func (err httpStatusError) Transient() bool {
+	switch err.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		return err.StatusCode >= 500
+	}
+}
+
+// isTransientError returns true if err is likely to be caused by a
+// temporary network condition, such as a server error, a timeout or a
+// connection that was reset.
+func isTransientError(err error) bool {
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Transient()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, windows.WSAECONNRESET),
+		errors.Is(err, windows.WSAECONNABORTED):
+		return true
+	}
+
+	return false
+}
+
+// sleepWithContext waits for the given duration to elapse. It returns early
+// with an error if the context is cancelled.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
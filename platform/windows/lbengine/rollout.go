@@ -0,0 +1,176 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbrollout"
+)
+
+// RolloutEngine is a LeafBridge engine that is responsible for invocation
+// of rollouts.
+type RolloutEngine struct {
+	rollout     lbrollout.Rollout
+	deployments []lbdeploy.Deployment
+	opts        Options
+}
+
+// NewRolloutEngine returns a new LeafBridge rollout engine for the given
+// rollout and options. The deployments hold the deployment for each step of
+// the rollout, in the same order as the steps.
+func NewRolloutEngine(rollout lbrollout.Rollout, deployments []lbdeploy.Deployment, opts Options) RolloutEngine {
+	return RolloutEngine{
+		rollout:     rollout,
+		deployments: deployments,
+		opts:        opts,
+	}
+}
+
+// Invoke executes each step of the rollout in order. It returns a report
+// describing the outcome of every step.
+//
+// A non-nil error is returned if any step failed, or if the rollout could
+// not be started.
+func (engine RolloutEngine) Invoke(ctx context.Context) (lbrollout.Report, error) {
+	report := lbrollout.Report{Rollout: engine.rollout.ID}
+
+	// Ensure that the rollout is valid, and that every step refers to a
+	// valid deployment and flow before anything is invoked.
+	if err := engine.rollout.Validate(); err != nil {
+		return report, err
+	}
+	if len(engine.deployments) != len(engine.rollout.Steps) {
+		return report, fmt.Errorf("the \"%s\" rollout has %d steps, but %d deployments were provided", engine.rollout.ID, len(engine.rollout.Steps), len(engine.deployments))
+	}
+	for i, step := range engine.rollout.Steps {
+		dep := engine.deployments[i]
+		if err := dep.Validate(); err != nil {
+			return report, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if _, found := dep.Flows[step.Flow]; !found {
+			return report, fmt.Errorf("step %d: the flow \"%s\" does not exist within the \"%s\" deployment", i+1, step.Flow, dep.ID)
+		}
+		for _, condition := range step.Conditions {
+			if _, found := dep.Conditions[condition]; !found {
+				return report, fmt.Errorf("step %d: the condition \"%s\" does not exist within the \"%s\" deployment", i+1, condition, dep.ID)
+			}
+		}
+	}
+
+	// Share a run ID and machine name across all of the deployments, so
+	// that their events can be correlated during log analysis.
+	opts := engine.opts
+	if opts.Events.Origin.RunID == "" {
+		opts.Events.Origin.RunID = lbevent.NewRunID()
+	}
+	if opts.Events.Origin.Machine == "" {
+		opts.Events.Origin.Machine, _ = os.Hostname()
+	}
+	events := opts.Events
+
+	started := time.Now()
+	events.Record(lbdeployevent.RolloutStarted{
+		Rollout: engine.rollout.ID,
+		Steps:   len(engine.rollout.Steps),
+	})
+
+	var (
+		stopped bool
+		failed  int
+	)
+	for i, step := range engine.rollout.Steps {
+		dep := engine.deployments[i]
+		result := lbrollout.StepResult{
+			Index:      i,
+			Label:      step.Label(),
+			Deployment: dep.ID,
+			Flow:       step.Flow,
+		}
+
+		var failedConditions lbdeploy.ConditionList
+		switch {
+		case stopped || ctx.Err() != nil:
+			result.Outcome = lbrollout.StepNotRun
+		default:
+			failedConditions, result.Err = evaluateStepConditions(dep, step.Conditions)
+			switch {
+			case result.Err != nil:
+				result.Outcome = lbrollout.StepFailed
+			case len(failedConditions) > 0:
+				result.Outcome = lbrollout.StepSkipped
+			default:
+				stepStarted := time.Now()
+				result.Err = NewDeploymentEngine(dep, opts).Invoke(ctx, step.Flow)
+				result.Duration = time.Since(stepStarted)
+				if result.Err != nil {
+					result.Outcome = lbrollout.StepFailed
+				} else {
+					result.Outcome = lbrollout.StepCompleted
+				}
+			}
+		}
+
+		if result.Outcome == lbrollout.StepFailed {
+			failed++
+			if step.OnFailure.Or(engine.rollout.OnFailure).Or(lbrollout.StopOnFailure) == lbrollout.StopOnFailure {
+				stopped = true
+			}
+		}
+
+		events.Record(lbdeployevent.RolloutStep{
+			Rollout:    engine.rollout.ID,
+			Step:       i + 1,
+			Label:      result.Label,
+			Deployment: result.Deployment,
+			Flow:       result.Flow,
+			Outcome:    result.Outcome,
+			Failed:     failedConditions,
+			Duration:   result.Duration,
+			Err:        result.Err,
+		})
+
+		report.Steps = append(report.Steps, result)
+	}
+
+	events.Record(lbdeployevent.RolloutStopped{
+		Rollout: engine.rollout.ID,
+		Stats:   report.Stats(),
+		Started: started,
+		Stopped: time.Now(),
+	})
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if failed > 0 {
+		return report, fmt.Errorf("%d of %d steps in the \"%s\" rollout failed", failed, len(engine.rollout.Steps), engine.rollout.ID)
+	}
+
+	return report, nil
+}
+
+// evaluateStepConditions evaluates the conditions of a rollout step within
+// its deployment. It returns the conditions that were not met.
+func evaluateStepConditions(dep lbdeploy.Deployment, conditions lbdeploy.ConditionList) (failed lbdeploy.ConditionList, err error) {
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	ce := NewConditionEngine(dep)
+	for _, condition := range conditions {
+		result, err := ce.Evaluate(condition)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate the \"%s\" condition: %w", condition, err)
+		}
+		if !result {
+			failed = append(failed, condition)
+		}
+	}
+
+	return failed, nil
+}
@@ -0,0 +1,456 @@
+package lbengine
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf16"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/internal/mergereader"
+	"github.com/leafbridge/leafbridge/platform/windows/tempfs"
+	"golang.org/x/sys/windows"
+)
+
+// scriptEngine manages invocation of a script action.
+type scriptEngine struct {
+	deployment lbdeploy.Deployment
+	flow       flowData
+	action     actionData
+	events     lbevent.Recorder
+	state      *engineState
+}
+
+// scriptInvocation holds the details of a script that is ready to be run.
+type scriptInvocation struct {
+	ScriptPath  string
+	WorkingDir  string
+	ExecPath    string
+	Args        []string
+	RawCmdLine  string // A command line to be provided to the OS verbatim
+	CommandLine string // A description of the command line for events
+}
+
+// InvokePowerShell runs the action's script with Windows PowerShell.
+//
+// The script is run without a profile and with an execution policy of
+// bypass. The exit code of the script is taken from $LASTEXITCODE.
+func (engine *scriptEngine) InvokePowerShell(ctx context.Context) error {
+	script := engine.action.Definition.Script
+	if err := script.Validate(); err != nil {
+		return fmt.Errorf("the script is not valid: %w", err)
+	}
+
+	// Prepare a temporary directory for the script.
+	dir, err := tempfs.OpenScriptDir(tempScope(engine.deployment.ID, engine.events))
+	if err != nil {
+		return fmt.Errorf("a temporary directory could not be created for the script: %w", err)
+	}
+	defer dir.Close()
+
+	// Determine the location of the script. Inline scripts are written with
+	// a UTF-8 byte order mark, which Windows PowerShell requires to
+	// recognize the encoding. Verified script files remain locked until the
+	// script has finished.
+	var locked lockedFiles
+	defer locked.Release()
+	scriptPath, err := engine.scriptPath(dir, "script.ps1", append([]byte("\xef\xbb\xbf"), script.Inline...), &locked)
+	if err != nil {
+		return err
+	}
+
+	// Locate Windows PowerShell.
+	execPath, err := powerShellPath()
+	if err != nil {
+		return fmt.Errorf("failed to locate Windows PowerShell: %w", err)
+	}
+
+	// Expand placeholders in the script arguments.
+	args, err := expandArgs(engine.deployment, script.Args)
+	if err != nil {
+		return fmt.Errorf("the script arguments could not be prepared: %w", err)
+	}
+
+	// Prepare a PowerShell command that invokes the script and then exits
+	// with the value of $LASTEXITCODE.
+	command := powerShellCommand(scriptPath, args)
+
+	// Determine the working directory.
+	workingDir, err := engine.workingDirectory(scriptPath)
+	if err != nil {
+		return fmt.Errorf("a working directory could not be determined for the script: %w", err)
+	}
+
+	return engine.invoke(ctx, scriptInvocation{
+		ScriptPath: scriptPath,
+		WorkingDir: workingDir,
+		ExecPath:   execPath,
+		Args: []string{
+			"-NoLogo",
+			"-NoProfile",
+			"-NonInteractive",
+			"-ExecutionPolicy", "Bypass",
+			"-EncodedCommand", encodePowerShellCommand(command),
+		},
+		CommandLine: command,
+	})
+}
+
+// InvokeCmd runs the action's script with the Windows command processor.
+//
+// Inline scripts are written to a batch file in a temporary directory that
+// is removed after the script has run.
+func (engine *scriptEngine) InvokeCmd(ctx context.Context) error {
+	script := engine.action.Definition.Script
+	if err := script.Validate(); err != nil {
+		return fmt.Errorf("the script is not valid: %w", err)
+	}
+
+	// Expand placeholders in the script arguments.
+	args, err := expandArgs(engine.deployment, script.Args)
+	if err != nil {
+		return fmt.Errorf("the script arguments could not be prepared: %w", err)
+	}
+
+	// Make sure that the arguments can be passed through the command
+	// processor safely.
+	for i, arg := range args {
+		if strings.ContainsAny(arg, "\"%\r\n") {
+			return fmt.Errorf("script argument %d contains a quotation mark, percent sign or line break, which cannot be passed to a batch file safely", i+1)
+		}
+	}
+
+	// Prepare a temporary directory for the script.
+	dir, err := tempfs.OpenScriptDir(tempScope(engine.deployment.ID, engine.events))
+	if err != nil {
+		return fmt.Errorf("a temporary directory could not be created for the script: %w", err)
+	}
+	defer dir.Close()
+
+	// Determine the location of the script. The command processor expects
+	// batch files to use CRLF line endings. Verified script files remain
+	// locked until the script has finished.
+	body := strings.ReplaceAll(strings.ReplaceAll(script.Inline, "\r\n", "\n"), "\n", "\r\n")
+	var locked lockedFiles
+	defer locked.Release()
+	scriptPath, err := engine.scriptPath(dir, "script.cmd", []byte(body), &locked)
+	if err != nil {
+		return err
+	}
+
+	// Locate the command processor.
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return fmt.Errorf("failed to locate the Windows command processor: %w", err)
+	}
+	execPath := filepath.Join(system, "cmd.exe")
+
+	// Prepare the command line. With the /S option, the command processor
+	// removes the outermost quotation marks that surround the command and
+	// preserves the rest of it.
+	command := quoteCmdArg(scriptPath)
+	for _, arg := range args {
+		command += " " + quoteCmdArg(arg)
+	}
+	cmdLine := fmt.Sprintf(`%s /D /E:ON /V:OFF /S /C "%s"`, quoteCmdArg(execPath), command)
+
+	// Determine the working directory.
+	workingDir, err := engine.workingDirectory(scriptPath)
+	if err != nil {
+		return fmt.Errorf("a working directory could not be determined for the script: %w", err)
+	}
+
+	return engine.invoke(ctx, scriptInvocation{
+		ScriptPath:  scriptPath,
+		WorkingDir:  workingDir,
+		ExecPath:    execPath,
+		RawCmdLine:  cmdLine,
+		CommandLine: cmdLine,
+	})
+}
+
+// scriptPath returns the path of the script to be run. For inline scripts,
+// the given data is written to a file with the given name in dir. Script
+// files that are verified are added to locked.
+func (engine *scriptEngine) scriptPath(dir tempfs.ScriptDir, name string, data []byte, locked *lockedFiles) (string, error) {
+	script := engine.action.Definition.Script
+	if script.File == "" {
+		path, err := dir.WriteFile(name, data)
+		if err != nil {
+			return "", fmt.Errorf("the inline script could not be written to disk: %w", err)
+		}
+		return path, nil
+	}
+
+	path, err := localFilePath(engine.deployment.Resources.FileSystem, script.File)
+	if err != nil {
+		return "", fmt.Errorf("the script file \"%s\" could not be resolved: %w", script.File, err)
+	}
+
+	// Make sure the script satisfies any integrity requirements before it
+	// is run.
+	if err := engine.verifyIntegrity(script.File, path, locked); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// verifyIntegrity checks the integrity requirements of the given script
+// file and records the result. Files without requirements are not checked.
+//
+// The file is verified through locked, which must not be released until the
+// script has finished.
+func (engine *scriptEngine) verifyIntegrity(fileID lbdeploy.FileResourceID, path string, locked *lockedFiles) error {
+	integrity := engine.deployment.Resources.FileSystem.Files[fileID].Integrity
+	if integrity.IsZero() {
+		return nil
+	}
+
+	actual, signer, err := locked.Verify(path, integrity)
+	if err != nil {
+		err = fmt.Errorf("the script file \"%s\" failed its integrity check: %w", fileID, err)
+	}
+
+	engine.events.Record(lbdeployevent.FileIntegrity{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		FileID:      fileID,
+		FilePath:    path,
+		Required:    integrity,
+		Actual:      actual,
+		Signer:      signer.Subject,
+		Thumbprint:  signer.Thumbprint,
+		Err:         err,
+	})
+
+	return err
+}
+
+// workingDirectory returns an absolute path to the script's working
+// directory. If a working directory was not provided for the script, it
+// returns the directory containing the script.
+func (engine *scriptEngine) workingDirectory(scriptPath string) (string, error) {
+	dirID := engine.action.Definition.Script.WorkingDirectory
+	if dirID == "" {
+		return filepath.Dir(scriptPath), nil
+	}
+	return localDirPath(engine.deployment.Resources.FileSystem, dirID)
+}
+
+func (engine *scriptEngine) invoke(ctx context.Context, inv scriptInvocation) (err error) {
+	// Check for cancellation before starting the script.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	script := engine.action.Definition.Script
+
+	// Prepare a command that will be terminated when ctx is cancelled.
+	cmd := exec.CommandContext(ctx, inv.ExecPath, inv.Args...)
+	cmd.Dir = inv.WorkingDir
+	cmd.Env, err = buildEnvironment(ctx, engine.deployment, script.Environment)
+	if err != nil {
+		return err
+	}
+	if inv.RawCmdLine != "" {
+		cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: inv.RawCmdLine}
+	}
+
+	// Configure the command to wait up to one minute for the script to close
+	// out gracefully when its context is cancelled.
+	cmd.WaitDelay = time.Minute
+
+	// Prepare two sets of output pipes for the command.
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	// Record the start of the script.
+	engine.events.Record(lbdeployevent.ScriptStarted{
+		Deployment:           engine.deployment.ID,
+		Flow:                 engine.flow.ID,
+		ActionIndex:          engine.action.Index,
+		ActionType:           engine.action.Definition.Type,
+		ScriptFile:           script.File,
+		ScriptPath:           inv.ScriptPath,
+		CommandLine:          inv.CommandLine,
+		WorkingDirectoryPath: inv.WorkingDir,
+	})
+
+	// Prepare a buffer to hold a limited amount of the combined output.
+	output := newOutputBuffer(script.Output, script.OutputEncoding)
+
+	// If requested, write the complete output to a file.
+	var outputPath string
+	if id := script.Output.File; id != "" {
+		file, err := createOutputFile(engine.deployment.Resources.FileSystem, id)
+		if err != nil {
+			return fmt.Errorf("the output file for the script could not be created: %w", err)
+		}
+		defer file.Close()
+		output.Tee(file)
+		outputPath = file.Name()
+	}
+
+	// Record the time that the script started.
+	started := time.Now()
+
+	// Track the process tree of the script, so that all of its processes
+	// can be terminated if it is cancelled.
+	tree, err := newProcessTree(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to prepare a job object for the script: %w", err)
+	}
+	defer tree.Close()
+
+	// Start the script.
+	err = cmd.Start()
+
+	// If the script started successfully, send its output to stdout and
+	// stderr as well as the output buffer, then wait for it to finish.
+	if err == nil {
+		// Attach the script to its process tree. If this fails, cancellation
+		// will only terminate the script's own process.
+		tree.Attach(cmd)
+
+		r1 := io.TeeReader(stdout, os.Stdout)
+		r2 := io.TeeReader(stderr, os.Stderr)
+		io.Copy(output, mergereader.New(r1, r2))
+		err = cmd.Wait()
+	}
+
+	// Record the time that the script stopped.
+	stopped := time.Now()
+
+	// If the script was cancelled, record the processes that were
+	// terminated.
+	if pids, termErr := tree.Terminated(); pids != nil || termErr != nil {
+		engine.events.Record(lbdeployevent.ProcessTreeTerminated{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			ScriptFile:  script.File,
+			ProcessIDs:  pids,
+			Reason:      cancellationReason(ctx),
+			Err:         termErr,
+		})
+	}
+
+	// Analyze the exit code of the script.
+	result, err := buildCommandResult(err, script.ExitCodes, false)
+
+	// Record the end of the script.
+	engine.events.Record(lbdeployevent.ScriptStopped{
+		Deployment:           engine.deployment.ID,
+		Flow:                 engine.flow.ID,
+		ActionIndex:          engine.action.Index,
+		ActionType:           engine.action.Definition.Type,
+		ScriptFile:           script.File,
+		ScriptPath:           inv.ScriptPath,
+		CommandLine:          inv.CommandLine,
+		WorkingDirectoryPath: inv.WorkingDir,
+		Result:               result,
+		Output:               output.String(),
+		OutputDiscarded:      output.Discarded(),
+		OutputPath:           outputPath,
+		Started:              started,
+		Stopped:              stopped,
+		Err:                  err,
+	})
+
+	return err
+}
+
+// powerShellPath returns the path of the Windows PowerShell executable
+// within the system directory.
+func powerShellPath() (string, error) {
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(system, "WindowsPowerShell", "v1.0", "powershell.exe"), nil
+}
+
+// quoteCmdArg returns s surrounded by quotation marks if it contains spaces
+// or characters that are special to the command processor.
+func quoteCmdArg(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t&|<>^(),;=!") {
+		return s
+	}
+	return `"` + s + `"`
+}
+
+// powerShellParameterName matches arguments that look like PowerShell
+// parameter names, such as -Name or -Force:.
+var powerShellParameterName = regexp.MustCompile(`^-[A-Za-z_][A-Za-z0-9_]*:?$`)
+
+// powerShellCommand returns a PowerShell command that invokes the script at
+// path with the given arguments, then exits with $LASTEXITCODE.
+//
+// Arguments that look like parameter names are passed as parameter names.
+// All other arguments are passed as literal strings.
+func powerShellCommand(path string, args []string) string {
+	var builder strings.Builder
+	builder.WriteString("& ")
+	builder.WriteString(quotePowerShell(path))
+	for _, arg := range args {
+		builder.WriteByte(' ')
+		if powerShellParameterName.MatchString(arg) {
+			builder.WriteString(arg)
+		} else {
+			builder.WriteString(quotePowerShell(arg))
+		}
+	}
+	builder.WriteString("; exit $LASTEXITCODE")
+	return builder.String()
+}
+
+// quotePowerShell returns s as a single-quoted PowerShell string literal.
+//
+// PowerShell treats several typographic quotation marks as equivalent to
+// the apostrophe, so all of them are escaped by doubling.
+func quotePowerShell(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '‘', '’', '‚', '‛':
+			builder.WriteRune(r)
+		}
+		builder.WriteRune(r)
+	}
+	builder.WriteByte('\'')
+	return builder.String()
+}
+
+// encodePowerShellCommand encodes a PowerShell command for use with the
+// -EncodedCommand option, which avoids any ambiguity in quoting.
+func encodePowerShellCommand(command string) string {
+	encoded := utf16.Encode([]rune(command))
+	data := make([]byte, 0, len(encoded)*2)
+	for _, v := range encoded {
+		data = binary.LittleEndian.AppendUint16(data, v)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
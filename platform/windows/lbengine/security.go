@@ -0,0 +1,78 @@
+package lbengine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+)
+
+// recordSecurityChange records a security audit event for a privileged
+// change made by the current action.
+func (engine *actionEngine) recordSecurityChange(kind lbdeployevent.SecurityChangeKind, operation, target string, err error) {
+	engine.events.Record(lbdeployevent.SecurityChange{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Kind:        kind,
+		Operation:   operation,
+		Target:      target,
+		Err:         err,
+	})
+}
+
+// recordFileChange records a security audit event for a change made to a
+// file, if the file is located within a privileged folder.
+//
+// If the path of the file could not be determined, the file resource ID
+// is recorded in its place.
+func (engine *fileEngine) recordFileChange(ref lbdeploy.FileRef, id lbdeploy.FileResourceID, operation, path string, err error) {
+	if !ref.Root.Privileged {
+		return
+	}
+	if path == "" {
+		path = string(id)
+	}
+	engine.events.Record(lbdeployevent.SecurityChange{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Kind:        lbdeployevent.SecurityChangeFile,
+		Operation:   operation,
+		Folder:      ref.Root.ID,
+		Target:      path,
+		Err:         err,
+	})
+}
+
+// lockReparsePoints returns an error if the file, or a directory above
+// it, is a reparse point that hasn't been allowed by its directory
+// resource. Reparse points that are found are recorded as security events.
+//
+// The directories that were checked remain locked until the returned
+// release function is called, so that they can't be redirected elsewhere
+// while the file is being modified.
+func (engine *fileEngine) lockReparsePoints(ref lbdeploy.FileRef, id lbdeploy.FileResourceID) (release func(), err error) {
+	lock, err := localfs.LockFile(ref)
+	if err == nil {
+		return lock.Release, nil
+	}
+	var reparse localfs.ReparsePointError
+	if errors.As(err, &reparse) {
+		engine.events.Record(lbdeployevent.ReparsePointBlocked{
+			Deployment:  engine.deployment.ID,
+			Flow:        engine.flow.ID,
+			ActionIndex: engine.action.Index,
+			ActionType:  engine.action.Definition.Type,
+			File:        id,
+			Path:        reparse.Path,
+			Kind:        reparse.Kind,
+		})
+		return nil, err
+	}
+	return nil, fmt.Errorf("unable to check the file's path for reparse points: %w", err)
+}
@@ -0,0 +1,85 @@
+package lbengine
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/windows/dpapi"
+	"github.com/leafbridge/leafbridge/platform/windows/sspi"
+)
+
+// sourceClient returns an HTTP client for the package source. If the source
+// uses integrated authentication, a dedicated client is returned and its
+// connections are closed when the returned release function is called.
+func sourceClient(source lbdeploy.PackageSource) (client *http.Client, release func(), err error) {
+	var pkg sspi.Package
+	switch source.IntegratedAuth {
+	case lbdeploy.IntegratedAuthNone:
+		return http.DefaultClient, func() {}, nil
+	case lbdeploy.IntegratedAuthNegotiate:
+		pkg = sspi.Negotiate
+	case lbdeploy.IntegratedAuthKerberos:
+		pkg = sspi.Kerberos
+	case lbdeploy.IntegratedAuthNTLM:
+		pkg = sspi.NTLM
+	default:
+		return nil, nil, fmt.Errorf("the integrated authentication option \"%s\" is not recognized", source.IntegratedAuth)
+	}
+
+	u, err := url.Parse(source.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the package source URL is invalid: %w", err)
+	}
+
+	client = &http.Client{Transport: sspi.NewTransport(pkg, u.Hostname())}
+	return client, client.CloseIdleConnections, nil
+}
+
+// prepareSourceRequest applies the headers and authorization of a package
+// source to an HTTP request.
+func prepareSourceRequest(req *http.Request, source lbdeploy.PackageSource) error {
+	for name, value := range source.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if !source.Authorization.IsZero() {
+		token, err := resolveSecret(source.Authorization.Token)
+		if err != nil {
+			return fmt.Errorf("failed to resolve the authorization token for the package source: %w", err)
+		}
+		req.Header.Set("Authorization", source.Authorization.SchemeOrDefault()+" "+token)
+	}
+
+	return nil
+}
+
+// resolveSecret returns the value of a secret from its source.
+func resolveSecret(secret lbdeploy.Secret) (string, error) {
+	switch {
+	case secret.Value != "":
+		return secret.Value, nil
+	case secret.Environment != "":
+		value := os.Getenv(secret.Environment)
+		if value == "" {
+			return "", fmt.Errorf("the \"%s\" environment variable is not set", secret.Environment)
+		}
+		return value, nil
+	case secret.DPAPI != "":
+		blob, err := base64.StdEncoding.DecodeString(secret.DPAPI)
+		if err != nil {
+			return "", fmt.Errorf("the dpapi blob is not valid base64: %w", err)
+		}
+		value, err := dpapi.Decrypt(blob)
+		if err != nil {
+			return "", fmt.Errorf("the dpapi blob could not be decrypted: %w", err)
+		}
+		return string(value), nil
+	default:
+		return "", errors.New("a secret source is missing")
+	}
+}
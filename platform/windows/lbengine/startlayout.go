@@ -0,0 +1,210 @@
+package lbengine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// startLayoutFile is the name of the copy of the Start layout that is
+	// applied through group policy, which is kept in ProgramData\LeafBridge.
+	startLayoutFile = "StartLayout.xml"
+
+	// startLayoutProfilePath is the location of the Start layout within a
+	// user profile.
+	startLayoutProfilePath = `AppData\Local\Microsoft\Windows\Shell\LayoutModification.xml`
+
+	// startLayoutPolicyKeyPath is the registry key that holds the group
+	// policies of Explorer.
+	startLayoutPolicyKeyPath = `SOFTWARE\Policies\Microsoft\Windows\Explorer`
+
+	// profileListKeyPath is the registry key that holds the locations of
+	// user profiles.
+	profileListKeyPath = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\ProfileList`
+)
+
+// setStartLayout deploys a Start menu and taskbar layout to the default
+// user profile, and optionally enforces it through group policy.
+func (engine *actionEngine) setStartLayout() error {
+	var sourcePath, profilePath, policyPath string
+	err := func() error {
+		// Read the layout from the source file.
+		resolver := localfs.NewResolver(engine.deployment.Resources.FileSystem)
+		sourceRef, err := resolver.ResolveFile(engine.action.Definition.SourceFile)
+		if err != nil {
+			return fmt.Errorf("source file: %w", err)
+		}
+		source, err := localfs.OpenFile(sourceRef)
+		if err != nil {
+			return fmt.Errorf("unable to open the source file: %w", err)
+		}
+		defer source.Close()
+		sourcePath = source.Path()
+
+		layout, err := io.ReadAll(source.System())
+		if err != nil {
+			return fmt.Errorf("unable to read the source file: %w", err)
+		}
+		if err := validateStartLayout(layout); err != nil {
+			return err
+		}
+
+		// Place the layout in the default user profile.
+		if profilePath, err = defaultProfileStartLayoutPath(); err != nil {
+			return err
+		}
+		if err := writeStartLayout(profilePath, layout); err != nil {
+			return err
+		}
+
+		if !engine.action.Definition.Enforce {
+			return nil
+		}
+
+		// Enforce the layout through group policy.
+		base, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+		if err != nil {
+			return err
+		}
+		policyPath = filepath.Join(base, stagingfs.RootDir, startLayoutFile)
+		if err := writeStartLayout(policyPath, layout); err != nil {
+			return err
+		}
+		key, _, err := registry.CreateKey(registry.LOCAL_MACHINE, startLayoutPolicyKeyPath, registry.SET_VALUE)
+		if err != nil {
+			return fmt.Errorf("failed to open the \"%s\" registry key: %w", startLayoutPolicyKeyPath, err)
+		}
+		defer key.Close()
+		if err := key.SetExpandStringValue("StartLayoutFile", policyPath); err != nil {
+			return fmt.Errorf("failed to set the StartLayoutFile policy: %w", err)
+		}
+		if err := key.SetDWordValue("LockedStartLayout", 1); err != nil {
+			return fmt.Errorf("failed to set the LockedStartLayout policy: %w", err)
+		}
+		return nil
+	}()
+
+	engine.events.Record(lbdeployevent.StartLayoutChange{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		SourcePath:  sourcePath,
+		ProfilePath: profilePath,
+		PolicyPath:  policyPath,
+		Err:         err,
+	})
+
+	return err
+}
+
+// clearStartLayout removes a Start menu and taskbar layout from the default
+// user profile and from group policy.
+func (engine *actionEngine) clearStartLayout() error {
+	var profilePath, policyPath string
+	err := func() error {
+		var err error
+		if profilePath, err = defaultProfileStartLayoutPath(); err != nil {
+			return err
+		}
+		if err := os.Remove(profilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, startLayoutPolicyKeyPath, registry.SET_VALUE)
+		if err == nil {
+			defer key.Close()
+			for _, name := range []string{"StartLayoutFile", "LockedStartLayout"} {
+				if err := key.DeleteValue(name); err != nil && !errors.Is(err, registry.ErrNotExist) {
+					return fmt.Errorf("failed to remove the %s policy: %w", name, err)
+				}
+			}
+		} else if !errors.Is(err, registry.ErrNotExist) {
+			return fmt.Errorf("failed to open the \"%s\" registry key: %w", startLayoutPolicyKeyPath, err)
+		}
+
+		base, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+		if err != nil {
+			return err
+		}
+		policyPath = filepath.Join(base, stagingfs.RootDir, startLayoutFile)
+		if err := os.Remove(policyPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}()
+
+	engine.events.Record(lbdeployevent.StartLayoutChange{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		ProfilePath: profilePath,
+		PolicyPath:  policyPath,
+		Cleared:     true,
+		Err:         err,
+	})
+
+	return err
+}
+
+// defaultProfileStartLayoutPath returns the path of the Start layout
+// within the default user profile.
+func defaultProfileStartLayoutPath() (string, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, profileListKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed to open the \"%s\" registry key: %w", profileListKeyPath, err)
+	}
+	defer key.Close()
+
+	profile, _, err := key.GetStringValue("Default")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the default user profile: %w", err)
+	}
+	if profile, err = registry.ExpandString(profile); err != nil {
+		return "", fmt.Errorf("failed to locate the default user profile: %w", err)
+	}
+
+	return filepath.Join(profile, startLayoutProfilePath), nil
+}
+
+// writeStartLayout writes a Start layout to path, creating its directory
+// if necessary.
+func writeStartLayout(path string, layout []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, layout, 0644); err != nil {
+		return fmt.Errorf("failed to write the Start layout to \"%s\": %w", path, err)
+	}
+	return nil
+}
+
+// validateStartLayout returns an error if layout is not a
+// LayoutModification.xml document.
+func validateStartLayout(layout []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(layout))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("the source file is not a valid Start layout: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "LayoutModificationTemplate" {
+				return fmt.Errorf("the source file is not a valid Start layout: its root element is \"%s\" instead of \"LayoutModificationTemplate\"", start.Name.Local)
+			}
+			return nil
+		}
+	}
+}
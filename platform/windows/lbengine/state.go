@@ -1,18 +1,51 @@
 package lbengine
 
 import (
+	"github.com/leafbridge/leafbridge/core/filehash"
 	"github.com/leafbridge/leafbridge/core/idset"
+	"github.com/leafbridge/leafbridge/core/lbbundle"
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbpolicy"
+	"github.com/leafbridge/leafbridge/core/lbstate"
 	"github.com/leafbridge/leafbridge/platform/windows/stagingfs"
 	"github.com/leafbridge/leafbridge/platform/windows/tempfs"
 )
 
 // engineState keeps track of the overall state of an flow.
+//
+// If bundle is non-nil, packages are copied from the bundle instead of
+// being downloaded from their sources.
+//
+// If agent is true, disruptive flows are deferred until the machine is
+// idle.
+//
+// If reverify is true, verification records of staged package files are
+// ignored and the files are hashed again.
+//
+// If resume is true, flows with checkpoints left behind by an interrupted
+// run continue from their last completed action. The packages that a
+// resumed flow's checkpoint records as verified are held in
+// resumedVerified, and are not hashed again. The extraction directories it
+// records are held in resumedExtractions, and are reopened instead of
+// extracting their packages again.
+//
+// Commands that would modify an app listed in holds are skipped.
 type engineState struct {
 	activeFlows          flowSet
 	verifiedPackageFiles map[lbdeploy.PackageID]stagingfs.PackageDir
 	extractedPackages    map[lbdeploy.PackageID]tempfs.ExtractionDir
+	resolvedHashes       map[lbdeploy.PackageID]filehash.Map
 	locks                *lockManager
+	bundle               *lbbundle.Bundle
+	agent                bool
+	reverify             bool
+	resume               bool
+	resumedVerified      idset.SetOf[lbdeploy.PackageID]
+	resumedExtractions   map[lbdeploy.PackageID]lbstate.PackageExtraction
+	traceConditions      bool
+	antivirusExclusions  []string
+	holds                lbpolicy.HoldList
 }
 
 func newEngineState() *engineState {
@@ -20,9 +53,19 @@ func newEngineState() *engineState {
 		activeFlows:          make(flowSet),
 		verifiedPackageFiles: make(map[lbdeploy.PackageID]stagingfs.PackageDir),
 		extractedPackages:    make(map[lbdeploy.PackageID]tempfs.ExtractionDir),
+		resolvedHashes:       make(map[lbdeploy.PackageID]filehash.Map),
+		resumedVerified:      make(idset.SetOf[lbdeploy.PackageID]),
+		resumedExtractions:   make(map[lbdeploy.PackageID]lbstate.PackageExtraction),
 		locks:                newLockManager(),
 	}
 }
 
 // flowSet keeps track of a set of flows.
 type flowSet = idset.SetOf[lbdeploy.FlowID]
+
+// tempScope returns the scope of the temporary directories created for a
+// run of a deployment, so that they are kept apart from those of other
+// deployments and runs.
+func tempScope(deployment lbdeploy.DeploymentID, events lbevent.Recorder) tempfs.Scope {
+	return tempfs.Scope{Deployment: deployment, Run: events.Origin.RunID}
+}
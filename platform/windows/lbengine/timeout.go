@@ -0,0 +1,71 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lberror"
+)
+
+// TimeoutError is returned when an action or flow is stopped because it
+// exceeded its timeout.
+//
+// Action is the one-based number of the action that exceeded its timeout.
+// It is zero when the flow itself exceeded its timeout.
+type TimeoutError struct {
+	Flow    lbdeploy.FlowID
+	Action  int
+	Timeout time.Duration
+}
+
+// Error returns a description of the error.
+func (e TimeoutError) Error() string {
+	if e.Action > 0 {
+		return fmt.Sprintf("action %d of the \"%s\" flow exceeded its %s timeout", e.Action, e.Flow, e.Timeout)
+	}
+	return fmt.Sprintf("the \"%s\" flow exceeded its %s timeout", e.Flow, e.Timeout)
+}
+
+// Category returns the category of the error.
+func (e TimeoutError) Category() lberror.Category {
+	return lberror.Timeout
+}
+
+// withFlowTimeout returns a context that is cancelled when the flow exceeds
+// its timeout. If the flow does not have a timeout, ctx is returned.
+func withFlowTimeout(ctx context.Context, flow flowData) (context.Context, context.CancelFunc) {
+	timeout := time.Duration(flow.Definition.Timeout)
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, timeout, TimeoutError{Flow: flow.ID, Timeout: timeout})
+}
+
+// withActionTimeout returns a context that is cancelled when an attempt of
+// the action managed by ae exceeds its timeout. If the action does not have
+// a timeout, ctx is returned.
+//
+// The timeout of a wait-for-registry-value action limits how long it waits
+// for its condition instead, so it is not enforced here.
+func withActionTimeout(ctx context.Context, ae *actionEngine) (context.Context, context.CancelFunc) {
+	timeout := time.Duration(ae.action.Definition.Timeout)
+	if timeout <= 0 || ae.action.Definition.Type == lbdeploy.ActionWaitForRegistry {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, timeout, TimeoutError{Flow: ae.flow.ID, Action: ae.action.Index + 1, Timeout: timeout})
+}
+
+// timeoutCause returns err with the timeout that cancelled ctx in front of
+// it, so that failures caused by a timeout are reported as such. If ctx
+// was not cancelled by a timeout, or err already reports it, err is
+// returned unmodified.
+func timeoutCause(ctx context.Context, err error) error {
+	var timeoutErr TimeoutError
+	if err == nil || errors.As(err, &timeoutErr) || !errors.As(context.Cause(ctx), &timeoutErr) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", timeoutErr, err)
+}
@@ -0,0 +1,74 @@
+package lbengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/platform/windows/winplatform"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// timeZonesKeyPath is the registry key beneath which the time zones known
+// to Windows are defined.
+const timeZonesKeyPath = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\Time Zones`
+
+// setTimeZone sets the time zone of the local system through tzutil. The
+// time zone must be known to Windows.
+func (engine *actionEngine) setTimeZone(ctx context.Context) error {
+	zone := engine.action.Definition.TimeZone
+
+	var previous string
+	err := func() error {
+		var err error
+		if previous, err = (winplatform.LocaleDetector{}).TimeZone(); err != nil {
+			return err
+		}
+		if strings.EqualFold(previous, zone) {
+			zone = previous
+			return nil
+		}
+
+		// Make sure the time zone exists before attempting to apply it.
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, timeZonesKeyPath+`\`+zone, registry.QUERY_VALUE)
+		if err != nil {
+			if errors.Is(err, registry.ErrNotExist) {
+				return fmt.Errorf("the \"%s\" time zone is not known to Windows", zone)
+			}
+			return err
+		}
+		key.Close()
+
+		system, err := windows.GetSystemDirectory()
+		if err != nil {
+			return err
+		}
+
+		output, err := exec.CommandContext(ctx, filepath.Join(system, "tzutil.exe"), "/s", zone).CombinedOutput()
+		if err != nil {
+			if message := strings.TrimSpace(string(output)); message != "" {
+				return fmt.Errorf("%w: %s", err, message)
+			}
+			return err
+		}
+
+		return nil
+	}()
+
+	engine.events.Record(lbdeployevent.TimeZoneChange{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Previous:    previous,
+		TimeZone:    zone,
+		Err:         err,
+	})
+
+	return err
+}
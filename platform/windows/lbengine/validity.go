@@ -0,0 +1,38 @@
+package lbengine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+)
+
+// checkValidity returns an error if the current time falls outside of the
+// given validity window of a deployment or flow, in which case an event
+// is recorded.
+func checkValidity(events lbevent.Recorder, deployment lbdeploy.DeploymentID, flow lbdeploy.FlowID, window lbdeploy.ValidityWindow, flowWindow bool) error {
+	if window.IsZero() {
+		return nil
+	}
+
+	now := time.Now()
+	err := window.Check(now)
+	if err == nil {
+		return nil
+	}
+
+	events.Record(lbdeployevent.OutsideValidityWindow{
+		Deployment: deployment,
+		Flow:       flow,
+		FlowWindow: flowWindow,
+		Window:     window,
+		Checked:    now,
+	})
+
+	if flowWindow {
+		return fmt.Errorf("the \"%s\" flow was not started because %w", flow, err)
+	}
+	return fmt.Errorf("the \"%s\" deployment was not started because %w", deployment, err)
+}
@@ -0,0 +1,173 @@
+package lbengine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbdeployevent"
+	"github.com/leafbridge/leafbridge/core/lberror"
+	"github.com/leafbridge/leafbridge/platform/windows/window"
+	"golang.org/x/sys/windows"
+)
+
+// windowPollInterval is the interval at which a window watchdog looks for
+// windows opened by a command.
+const windowPollInterval = 2 * time.Second
+
+// WindowError is the cause of cancellation when a command is terminated by
+// its window watchdog.
+type WindowError struct {
+	Title      string
+	Unexpected bool
+}
+
+// Error returns a description of the error.
+func (e WindowError) Error() string {
+	if e.Unexpected {
+		return fmt.Sprintf("the command was terminated because it left the unexpected \"%s\" window open", e.Title)
+	}
+	return fmt.Sprintf("the command was terminated because it opened the \"%s\" window", e.Title)
+}
+
+// Category returns the category of the error.
+func (e WindowError) Category() lberror.Category {
+	return lberror.Installer
+}
+
+// windowSighting keeps track of a window that has been seen by a window
+// watchdog.
+type windowSighting struct {
+	firstSeen time.Time
+	handled   bool
+}
+
+// watchWindows starts a window watchdog for the command, if it has one.
+// The watchdog monitors the windows opened by the processes in tree, and
+// calls cancel with a WindowError if the command must be terminated.
+//
+// The returned function stops the watchdog and waits for it to exit.
+func (engine *commandEngine) watchWindows(ctx context.Context, tree *processTree, cancel context.CancelCauseFunc) (stop func()) {
+	config := engine.command.Definition.WindowWatchdog
+	if config.IsZero() {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		seen := make(map[windows.HWND]*windowSighting)
+		ticker := time.NewTicker(windowPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			pids, err := tree.job.ProcessIDs()
+			if err != nil || len(pids) == 0 {
+				continue
+			}
+			list, err := window.ForProcesses(pids)
+			if err != nil {
+				continue
+			}
+
+			for _, w := range list {
+				if werr, terminate := engine.handleWindow(config, w, seen); terminate {
+					cancel(werr)
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// handleWindow applies the watchdog configuration to a window. It returns
+// true if the command must be terminated.
+func (engine *commandEngine) handleWindow(config lbdeploy.WindowWatchdog, w window.Window, seen map[windows.HWND]*windowSighting) (WindowError, bool) {
+	sighting, found := seen[w.Handle]
+	if !found {
+		sighting = &windowSighting{firstSeen: time.Now()}
+		seen[w.Handle] = sighting
+	}
+	if sighting.handled {
+		return WindowError{}, false
+	}
+
+	event := lbdeployevent.CommandWindow{
+		Deployment:  engine.deployment.ID,
+		Flow:        engine.flow.ID,
+		ActionIndex: engine.action.Index,
+		ActionType:  engine.action.Definition.Type,
+		Package:     engine.pkg.ID,
+		Command:     engine.command.ID,
+		ProcessID:   w.ProcessID,
+		Title:       w.Title,
+		Class:       w.Class,
+	}
+
+	// Windows that don't match a rule are reported when they are first
+	// seen. Once the grace period has passed, they are handled according
+	// to the on-unexpected policy.
+	index := config.Match(w.Title)
+	if index < 0 {
+		if !found {
+			engine.events.Record(event)
+		}
+		if config.OnUnexpected != lbdeploy.WindowPolicyFail {
+			sighting.handled = true
+			return WindowError{}, false
+		}
+		if time.Since(sighting.firstSeen) < config.EffectiveGrace() {
+			return WindowError{}, false
+		}
+		sighting.handled = true
+		event.Action = lbdeploy.WindowFail
+		engine.events.Record(event)
+		return WindowError{Title: w.Title, Unexpected: true}, true
+	}
+
+	rule := config.Rules[index]
+	event.Rule = index + 1
+	event.Action = rule.Action
+
+	switch rule.Action {
+	case lbdeploy.WindowFail:
+		sighting.handled = true
+		engine.events.Record(event)
+		return WindowError{Title: w.Title}, true
+	case lbdeploy.WindowClose:
+		event.Err = w.Close()
+	case lbdeploy.WindowClick:
+		clicked, err := w.Click(rule.Button)
+		if err == nil && !clicked {
+			// The button might not have been created yet. Try again until
+			// the grace period has passed.
+			if time.Since(sighting.firstSeen) < config.EffectiveGrace() {
+				return WindowError{}, false
+			}
+			err = fmt.Errorf("a \"%s\" button could not be found", rule.Button)
+		}
+		event.Err = err
+	}
+
+	sighting.handled = true
+	engine.events.Record(event)
+	return WindowError{}, false
+}
@@ -0,0 +1,380 @@
+// Package localaccount manages the local user accounts, local group
+// memberships and user rights of a Windows machine.
+//
+// Users and groups are managed through the network management API, and
+// user rights through the local security authority. Accounts are named
+// the way Windows names them, optionally qualified by a domain, such as
+// "CONTOSO\Workstation Admins" or "NT SERVICE\MSSQLSERVER". Group members
+// and user rights are matched by security identifier, so a qualified and
+// an unqualified name of the same account are equivalent.
+package localaccount
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modnetapi32                 = windows.NewLazySystemDLL("netapi32.dll")
+	procNetUserAdd              = modnetapi32.NewProc("NetUserAdd")
+	procNetUserDel              = modnetapi32.NewProc("NetUserDel")
+	procNetUserGetInfo          = modnetapi32.NewProc("NetUserGetInfo")
+	procNetUserSetInfo          = modnetapi32.NewProc("NetUserSetInfo")
+	procNetLocalGroupAddMembers = modnetapi32.NewProc("NetLocalGroupAddMembers")
+	procNetLocalGroupDelMembers = modnetapi32.NewProc("NetLocalGroupDelMembers")
+	procNetLocalGroupGetMembers = modnetapi32.NewProc("NetLocalGroupGetMembers")
+)
+
+// Network management constants.
+const (
+	maxPreferredLength = 0xFFFFFFFF
+	nerrSuccess        = 0
+
+	nerrGroupNotFound     = 2220
+	nerrUserNotFound      = 2221
+	errorNoSuchAlias      = 1376
+	errorMemberNotInAlias = 1377
+	errorMemberInAlias    = 1378
+	errorNoneMapped       = 1332
+
+	userPrivUser       = 1
+	ufScript           = 0x0001
+	ufAccountDisable   = 0x0002
+	ufDontExpirePasswd = 0x10000
+
+	userInfoLevel0     = 0
+	userInfoLevel1     = 1
+	userInfoComment    = 1007
+	userInfoFlags      = 1008
+	userInfoFullName   = 1011
+	localGroupMembers0 = 0
+)
+
+// userInfo1 is the USER_INFO_1 structure used by NetUserAdd and
+// NetUserGetInfo.
+type userInfo1 struct {
+	name        *uint16
+	password    *uint16
+	passwordAge uint32
+	priv        uint32
+	homeDir     *uint16
+	comment     *uint16
+	flags       uint32
+	scriptPath  *uint16
+}
+
+// localGroupMembersInfo0 is the LOCALGROUP_MEMBERS_INFO_0 structure, which
+// identifies a group member by its security identifier.
+type localGroupMembersInfo0 struct {
+	sid *windows.SID
+}
+
+// User describes a local user account.
+type User struct {
+	Name                 string
+	FullName             string
+	Description          string
+	Disabled             bool
+	PasswordNeverExpires bool
+}
+
+// UserExists returns true if a local user with the given name exists.
+func UserExists(name string) (bool, error) {
+	username, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return false, err
+	}
+	if err := procNetUserGetInfo.Find(); err != nil {
+		return false, err
+	}
+	var buf *byte
+	r1, _, _ := procNetUserGetInfo.Call(0, uintptr(unsafe.Pointer(username)), userInfoLevel0, uintptr(unsafe.Pointer(&buf)))
+	switch r1 {
+	case nerrSuccess:
+		windows.NetApiBufferFree(buf)
+		return true, nil
+	case nerrUserNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to look up the \"%s\" local user: %w", name, windows.Errno(r1))
+	}
+}
+
+// CreateUser creates a local user with the given password, and returns
+// true. If the user already exists, its password is left alone, its full
+// name, description and flags are updated, and false is returned.
+func CreateUser(user User, password string) (created bool, err error) {
+	exists, err := UserExists(user.Name)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		if err := addUser(user, password); err != nil {
+			return false, err
+		}
+		created = true
+	} else if err := updateFlags(user); err != nil {
+		return false, err
+	}
+
+	// The full name cannot be provided when the user is created at level 1.
+	if err := setUserString(user.Name, userInfoFullName, user.FullName); err != nil {
+		return created, fmt.Errorf("failed to set the full name of the \"%s\" local user: %w", user.Name, err)
+	}
+	if exists {
+		if err := setUserString(user.Name, userInfoComment, user.Description); err != nil {
+			return false, fmt.Errorf("failed to set the description of the \"%s\" local user: %w", user.Name, err)
+		}
+	}
+	return created, nil
+}
+
+// RemoveUser removes the local user with the given name. It returns false
+// if the user does not exist.
+func RemoveUser(name string) (bool, error) {
+	username, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return false, err
+	}
+	if err := procNetUserDel.Find(); err != nil {
+		return false, err
+	}
+	r1, _, _ := procNetUserDel.Call(0, uintptr(unsafe.Pointer(username)))
+	switch r1 {
+	case nerrSuccess:
+		return true, nil
+	case nerrUserNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to remove the \"%s\" local user: %w", name, windows.Errno(r1))
+	}
+}
+
+// addUser creates a local user.
+func addUser(user User, password string) error {
+	var (
+		info userInfo1
+		err  error
+	)
+	if info.name, err = windows.UTF16PtrFromString(user.Name); err != nil {
+		return err
+	}
+	if info.password, err = windows.UTF16PtrFromString(password); err != nil {
+		return err
+	}
+	if info.comment, err = windows.UTF16PtrFromString(user.Description); err != nil {
+		return err
+	}
+	info.priv = userPrivUser
+	info.flags = userFlags(ufScript, user)
+
+	if err := procNetUserAdd.Find(); err != nil {
+		return err
+	}
+	var parmErr uint32
+	r1, _, _ := procNetUserAdd.Call(0, userInfoLevel1, uintptr(unsafe.Pointer(&info)), uintptr(unsafe.Pointer(&parmErr)))
+	if r1 != nerrSuccess {
+		return fmt.Errorf("failed to create the \"%s\" local user: %w", user.Name, windows.Errno(r1))
+	}
+	return nil
+}
+
+// updateFlags updates the flags of an existing local user to match user.
+// Flags that are not managed by this package are preserved.
+func updateFlags(user User) error {
+	username, err := windows.UTF16PtrFromString(user.Name)
+	if err != nil {
+		return err
+	}
+	if err := procNetUserGetInfo.Find(); err != nil {
+		return err
+	}
+	var buf *byte
+	r1, _, _ := procNetUserGetInfo.Call(0, uintptr(unsafe.Pointer(username)), userInfoLevel1, uintptr(unsafe.Pointer(&buf)))
+	if r1 != nerrSuccess {
+		return fmt.Errorf("failed to look up the \"%s\" local user: %w", user.Name, windows.Errno(r1))
+	}
+	current := (*userInfo1)(unsafe.Pointer(buf)).flags
+	windows.NetApiBufferFree(buf)
+
+	flags := userFlags(current, user)
+	if flags == current {
+		return nil
+	}
+	if err := setUserInfo(user.Name, userInfoFlags, unsafe.Pointer(&flags)); err != nil {
+		return fmt.Errorf("failed to update the flags of the \"%s\" local user: %w", user.Name, err)
+	}
+	return nil
+}
+
+// userFlags returns flags with the flags managed by this package set to
+// match user.
+func userFlags(flags uint32, user User) uint32 {
+	flags &^= ufAccountDisable | ufDontExpirePasswd
+	if user.Disabled {
+		flags |= ufAccountDisable
+	}
+	if user.PasswordNeverExpires {
+		flags |= ufDontExpirePasswd
+	}
+	return flags
+}
+
+// setUserString sets a string attribute of a local user at the given
+// information level, which must use a structure with a single string.
+func setUserString(name string, level uint32, value string) error {
+	ptr, err := windows.UTF16PtrFromString(value)
+	if err != nil {
+		return err
+	}
+	return setUserInfo(name, level, unsafe.Pointer(&ptr))
+}
+
+// setUserInfo calls NetUserSetInfo for the local user with the given name.
+func setUserInfo(name string, level uint32, info unsafe.Pointer) error {
+	username, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	if err := procNetUserSetInfo.Find(); err != nil {
+		return err
+	}
+	var parmErr uint32
+	r1, _, _ := procNetUserSetInfo.Call(0, uintptr(unsafe.Pointer(username)), uintptr(level), uintptr(info), uintptr(unsafe.Pointer(&parmErr)))
+	if r1 != nerrSuccess {
+		return windows.Errno(r1)
+	}
+	return nil
+}
+
+// AddGroupMember adds the account to the local group. It returns false if
+// the account is already a member.
+func AddGroupMember(group, account string) (bool, error) {
+	sid, err := lookupSID(account)
+	if err != nil {
+		return false, err
+	}
+	r1, err := callGroupMembers(procNetLocalGroupAddMembers, group, sid)
+	if err != nil {
+		return false, err
+	}
+	switch r1 {
+	case nerrSuccess:
+		return true, nil
+	case errorMemberInAlias:
+		return false, nil
+	default:
+		return false, groupError("failed to add \""+account+"\" to", group, r1)
+	}
+}
+
+// RemoveGroupMember removes the account from the local group. It returns
+// false if the account is not a member.
+func RemoveGroupMember(group, account string) (bool, error) {
+	sid, err := lookupSID(account)
+	if err != nil {
+		return false, err
+	}
+	r1, err := callGroupMembers(procNetLocalGroupDelMembers, group, sid)
+	if err != nil {
+		return false, err
+	}
+	switch r1 {
+	case nerrSuccess:
+		return true, nil
+	case errorMemberNotInAlias:
+		return false, nil
+	default:
+		return false, groupError("failed to remove \""+account+"\" from", group, r1)
+	}
+}
+
+// IsGroupMember returns true if the account is a direct member of the
+// local group. It returns false if the account does not exist, and an
+// error if the group does not exist.
+func IsGroupMember(group, account string) (bool, error) {
+	sid, err := lookupSID(account)
+	if err != nil {
+		if isNoneMapped(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	groupname, err := windows.UTF16PtrFromString(group)
+	if err != nil {
+		return false, err
+	}
+	if err := procNetLocalGroupGetMembers.Find(); err != nil {
+		return false, err
+	}
+	var (
+		buf          *byte
+		entriesRead  uint32
+		totalEntries uint32
+		resume       uintptr
+	)
+	r1, _, _ := procNetLocalGroupGetMembers.Call(
+		0,
+		uintptr(unsafe.Pointer(groupname)),
+		localGroupMembers0,
+		uintptr(unsafe.Pointer(&buf)),
+		maxPreferredLength,
+		uintptr(unsafe.Pointer(&entriesRead)),
+		uintptr(unsafe.Pointer(&totalEntries)),
+		uintptr(unsafe.Pointer(&resume)))
+	if r1 != nerrSuccess {
+		return false, groupError("failed to list the members of", group, r1)
+	}
+	defer windows.NetApiBufferFree(buf)
+
+	for _, member := range unsafe.Slice((*localGroupMembersInfo0)(unsafe.Pointer(buf)), entriesRead) {
+		if member.sid.Equals(sid) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// callGroupMembers calls NetLocalGroupAddMembers or NetLocalGroupDelMembers
+// for a single member, and returns the status code.
+func callGroupMembers(proc *windows.LazyProc, group string, sid *windows.SID) (uintptr, error) {
+	groupname, err := windows.UTF16PtrFromString(group)
+	if err != nil {
+		return 0, err
+	}
+	if err := proc.Find(); err != nil {
+		return 0, err
+	}
+	member := localGroupMembersInfo0{sid: sid}
+	r1, _, _ := proc.Call(0, uintptr(unsafe.Pointer(groupname)), localGroupMembers0, uintptr(unsafe.Pointer(&member)), 1)
+	return r1, nil
+}
+
+// groupError returns an error for a failed operation on a local group.
+func groupError(operation, group string, status uintptr) error {
+	switch status {
+	case nerrGroupNotFound, errorNoSuchAlias:
+		return fmt.Errorf("%s the \"%s\" local group: the group does not exist", operation, group)
+	default:
+		return fmt.Errorf("%s the \"%s\" local group: %w", operation, group, windows.Errno(status))
+	}
+}
+
+// lookupSID returns the security identifier of the account.
+func lookupSID(account string) (*windows.SID, error) {
+	sid, _, _, err := windows.LookupSID("", account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the \"%s\" account: %w", account, err)
+	}
+	return sid, nil
+}
+
+// isNoneMapped returns true if err indicates that an account name could
+// not be mapped to a security identifier.
+func isNoneMapped(err error) bool {
+	return errors.Is(err, windows.Errno(errorNoneMapped))
+}
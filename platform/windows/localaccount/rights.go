@@ -0,0 +1,193 @@
+package localaccount
+
+import (
+	"fmt"
+	"slices"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modadvapi32                   = windows.NewLazySystemDLL("advapi32.dll")
+	procLsaOpenPolicy             = modadvapi32.NewProc("LsaOpenPolicy")
+	procLsaClose                  = modadvapi32.NewProc("LsaClose")
+	procLsaFreeMemory             = modadvapi32.NewProc("LsaFreeMemory")
+	procLsaAddAccountRights       = modadvapi32.NewProc("LsaAddAccountRights")
+	procLsaRemoveAccountRights    = modadvapi32.NewProc("LsaRemoveAccountRights")
+	procLsaEnumerateAccountRights = modadvapi32.NewProc("LsaEnumerateAccountRights")
+	procLsaNtStatusToWinError     = modadvapi32.NewProc("LsaNtStatusToWinError")
+)
+
+// Local security authority constants.
+const (
+	policyViewLocalInformation = 0x00000001
+	policyCreateAccount        = 0x00000010
+	policyLookupNames          = 0x00000800
+
+	statusObjectNameNotFound = 0xC0000034
+)
+
+// lsaUnicodeString is the LSA_UNICODE_STRING structure.
+type lsaUnicodeString struct {
+	length        uint16
+	maximumLength uint16
+	buffer        *uint16
+}
+
+// lsaObjectAttributes is the LSA_OBJECT_ATTRIBUTES structure, which must
+// be zeroed when a policy is opened.
+type lsaObjectAttributes struct {
+	length                   uint32
+	rootDirectory            uintptr
+	objectName               *lsaUnicodeString
+	attributes               uint32
+	securityDescriptor       uintptr
+	securityQualityOfService uintptr
+}
+
+// HasRight returns true if the user right has been granted to the account
+// directly. It returns false if the account does not exist.
+func HasRight(account, right string) (bool, error) {
+	sid, err := lookupSID(account)
+	if err != nil {
+		if isNoneMapped(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	policy, err := openPolicy()
+	if err != nil {
+		return false, err
+	}
+	defer procLsaClose.Call(policy)
+
+	rights, err := accountRights(policy, sid)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve the user rights of \"%s\": %w", account, err)
+	}
+	return slices.Contains(rights, right), nil
+}
+
+// GrantRight grants the user right to the account. It returns false if the
+// account already holds the right.
+func GrantRight(account, right string) (bool, error) {
+	return changeRight(account, right, true)
+}
+
+// RevokeRight revokes the user right from the account. It returns false if
+// the account does not hold the right directly.
+func RevokeRight(account, right string) (bool, error) {
+	return changeRight(account, right, false)
+}
+
+// changeRight grants or revokes a user right.
+func changeRight(account, right string, grant bool) (bool, error) {
+	sid, err := lookupSID(account)
+	if err != nil {
+		return false, err
+	}
+	policy, err := openPolicy()
+	if err != nil {
+		return false, err
+	}
+	defer procLsaClose.Call(policy)
+
+	rights, err := accountRights(policy, sid)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve the user rights of \"%s\": %w", account, err)
+	}
+	if slices.Contains(rights, right) == grant {
+		return false, nil
+	}
+
+	name, err := newLSAString(right)
+	if err != nil {
+		return false, err
+	}
+	var status uintptr
+	if grant {
+		if err := procLsaAddAccountRights.Find(); err != nil {
+			return false, err
+		}
+		status, _, _ = procLsaAddAccountRights.Call(policy, uintptr(unsafe.Pointer(sid)), uintptr(unsafe.Pointer(&name)), 1)
+	} else {
+		if err := procLsaRemoveAccountRights.Find(); err != nil {
+			return false, err
+		}
+		status, _, _ = procLsaRemoveAccountRights.Call(policy, uintptr(unsafe.Pointer(sid)), 0, uintptr(unsafe.Pointer(&name)), 1)
+	}
+	if status != 0 {
+		if grant {
+			return false, fmt.Errorf("failed to grant %s to \"%s\": %w", right, account, ntStatusError(status))
+		}
+		return false, fmt.Errorf("failed to revoke %s from \"%s\": %w", right, account, ntStatusError(status))
+	}
+	return true, nil
+}
+
+// openPolicy opens the local security policy.
+func openPolicy() (uintptr, error) {
+	if err := procLsaOpenPolicy.Find(); err != nil {
+		return 0, err
+	}
+	var (
+		attrs  lsaObjectAttributes
+		policy uintptr
+	)
+	status, _, _ := procLsaOpenPolicy.Call(0, uintptr(unsafe.Pointer(&attrs)), policyViewLocalInformation|policyCreateAccount|policyLookupNames, uintptr(unsafe.Pointer(&policy)))
+	if status != 0 {
+		return 0, fmt.Errorf("failed to open the local security policy: %w", ntStatusError(status))
+	}
+	return policy, nil
+}
+
+// accountRights returns the user rights that have been granted directly
+// to the account with the given security identifier.
+func accountRights(policy uintptr, sid *windows.SID) ([]string, error) {
+	if err := procLsaEnumerateAccountRights.Find(); err != nil {
+		return nil, err
+	}
+	var (
+		buf   *lsaUnicodeString
+		count uint32
+	)
+	status, _, _ := procLsaEnumerateAccountRights.Call(policy, uintptr(unsafe.Pointer(sid)), uintptr(unsafe.Pointer(&buf)), uintptr(unsafe.Pointer(&count)))
+	if status == statusObjectNameNotFound {
+		// The account has not been granted any rights.
+		return nil, nil
+	}
+	if status != 0 {
+		return nil, ntStatusError(status)
+	}
+	defer procLsaFreeMemory.Call(uintptr(unsafe.Pointer(buf)))
+
+	rights := make([]string, 0, count)
+	for _, s := range unsafe.Slice(buf, count) {
+		rights = append(rights, windows.UTF16ToString(unsafe.Slice(s.buffer, s.length/2)))
+	}
+	return rights, nil
+}
+
+// newLSAString returns s as an LSA_UNICODE_STRING.
+func newLSAString(s string) (lsaUnicodeString, error) {
+	buf, err := windows.UTF16FromString(s)
+	if err != nil {
+		return lsaUnicodeString{}, err
+	}
+	return lsaUnicodeString{
+		length:        uint16((len(buf) - 1) * 2),
+		maximumLength: uint16(len(buf) * 2),
+		buffer:        &buf[0],
+	}, nil
+}
+
+// ntStatusError converts an NTSTATUS code returned by the local security
+// authority to a Windows error.
+func ntStatusError(status uintptr) error {
+	if err := procLsaNtStatusToWinError.Find(); err != nil {
+		return windows.NTStatus(status)
+	}
+	code, _, _ := procLsaNtStatusToWinError.Call(status)
+	return windows.Errno(code)
+}
@@ -11,17 +11,18 @@ type knownFolderMap map[lbdeploy.DirectoryResourceID]knownFolder
 
 // knownFolder holds the GUID and properties for a known folder in Windows.
 type knownFolder struct {
-	guid      *windows.KNOWNFOLDERID
-	protected bool
+	guid       *windows.KNOWNFOLDERID
+	protected  bool
+	privileged bool // Only administrators can modify the folder
 }
 
 // Known folders that are recognized by their resource IDs.
 var knownFolders = knownFolderMap{
-	"common-start-menu": knownFolder{guid: windows.FOLDERID_CommonStartMenu},
-	"public-desktop":    knownFolder{guid: windows.FOLDERID_PublicDesktop},
+	"common-start-menu": knownFolder{guid: windows.FOLDERID_CommonStartMenu, privileged: true},
+	"public-desktop":    knownFolder{guid: windows.FOLDERID_PublicDesktop, privileged: true},
 	"program-data":      knownFolder{guid: windows.FOLDERID_ProgramData},
-	"program-files":     knownFolder{guid: windows.FOLDERID_ProgramFiles},
-	"program-files-x86": knownFolder{guid: windows.FOLDERID_ProgramFilesX86},
-	"program-files-x64": knownFolder{guid: windows.FOLDERID_ProgramFilesX64},
-	"system":            knownFolder{guid: windows.FOLDERID_System, protected: true},
+	"program-files":     knownFolder{guid: windows.FOLDERID_ProgramFiles, privileged: true},
+	"program-files-x86": knownFolder{guid: windows.FOLDERID_ProgramFilesX86, privileged: true},
+	"program-files-x64": knownFolder{guid: windows.FOLDERID_ProgramFilesX64, privileged: true},
+	"system":            knownFolder{guid: windows.FOLDERID_System, protected: true, privileged: true},
 }
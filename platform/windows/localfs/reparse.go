@@ -0,0 +1,195 @@
+package localfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"golang.org/x/sys/windows"
+)
+
+// ReparsePointError is returned when a component of a path is a reparse
+// point, such as a junction or symbolic link, that has not been allowed.
+type ReparsePointError struct {
+	Path string
+	Kind string
+}
+
+// Error returns a description of the error.
+func (e ReparsePointError) Error() string {
+	return fmt.Sprintf("%s is a %s, which has not been allowed by its directory resource", e.Path, e.Kind)
+}
+
+// PathLock holds open the directories above a file that have been checked
+// for reparse points. While the lock is held, the directories cannot be
+// renamed, deleted or replaced, so paths beneath them continue to refer to
+// the directories that were checked.
+type PathLock struct {
+	handles []windows.Handle
+}
+
+// Release closes the directory handles held by the lock.
+func (lock PathLock) Release() {
+	for _, handle := range lock.handles {
+		windows.CloseHandle(handle)
+	}
+}
+
+// LockFile returns a [ReparsePointError] if the file, or any directory
+// between it and its known folder, is a reparse point. The known folder
+// itself is not checked, and neither are directories with resources that
+// allow reparse points.
+//
+// Each component is opened without following reparse points, and it is
+// checked through the handle that was opened. The directories that are
+// checked remain open until the returned lock is released, which must not
+// happen until the file has been modified. The file itself is not held
+// open, so that it can be replaced or deleted.
+//
+// Files and directories that do not exist are not considered.
+func LockFile(ref lbdeploy.FileRef) (lock PathLock, err error) {
+	if ref.Root.Path == "" {
+		return PathLock{}, errors.New("the file reference has a root with an empty path")
+	}
+	defer func() {
+		if err != nil {
+			lock.Release()
+		}
+	}()
+
+	// Check and hold each directory in the file's lineage.
+	path := ref.Root.Path
+	for _, next := range ref.Lineage {
+		if next.AllowReparsePoint {
+			localized, err := filepath.Localize(next.Path)
+			if err != nil {
+				return lock, err
+			}
+			path = filepath.Join(path, localized)
+			continue
+		}
+		path, err = lock.holdComponents(path, next.Path, false)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return lock, nil
+			}
+			return lock, err
+		}
+	}
+
+	// Check and hold the directories within the file's path, and check the
+	// file itself.
+	if _, err := lock.holdComponents(path, ref.FilePath, true); err != nil && !os.IsNotExist(err) {
+		return lock, err
+	}
+
+	return lock, nil
+}
+
+// LockDir returns a [ReparsePointError] if the directory at the
+// slash-separated relative path beneath base, or any directory between
+// them, is a reparse point. The base directory itself is not checked.
+//
+// Each directory is opened without following reparse points, and it is
+// checked through the handle that was opened. The directories remain open
+// until the returned lock is released, so that the path continues to refer
+// to the directory that was checked.
+func LockDir(base, relative string) (lock PathLock, err error) {
+	if relative == "" {
+		return PathLock{}, nil
+	}
+	if _, err := lock.holdComponents(base, relative, false); err != nil {
+		lock.Release()
+		return PathLock{}, err
+	}
+	return lock, nil
+}
+
+// holdComponents checks each component of the slash-separated relative
+// path beneath parent, and adds each directory to the lock. If file is
+// true, the last component is checked but is not held.
+//
+// It returns the path of the last component. It returns an error
+// satisfying [os.IsNotExist] if a component does not exist.
+func (lock *PathLock) holdComponents(parent, relative string, file bool) (string, error) {
+	if _, err := filepath.Localize(relative); err != nil {
+		return "", err
+	}
+	components := strings.Split(relative, "/")
+	path := parent
+	for i, component := range components {
+		path = filepath.Join(path, component)
+		hold := !file || i < len(components)-1
+		handle, err := openReparsePoint(path, hold)
+		if err != nil {
+			return "", err
+		}
+		if err := checkReparsePoint(handle, path); err != nil {
+			windows.CloseHandle(handle)
+			return "", err
+		}
+		if hold {
+			lock.handles = append(lock.handles, handle)
+		} else {
+			windows.CloseHandle(handle)
+		}
+	}
+	return path, nil
+}
+
+// openReparsePoint opens the file or directory at path without following
+// a reparse point in its final component.
+//
+// If hold is true, other processes can read and write the file while it is
+// open, but they cannot rename or delete it. Otherwise the file is only
+// opened to examine its attributes, and it does not prevent access by other
+// processes.
+func openReparsePoint(path string, hold bool) (windows.Handle, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+	const flags = windows.FILE_FLAG_OPEN_REPARSE_POINT | windows.FILE_FLAG_BACKUP_SEMANTICS
+	var access, share uint32 = windows.FILE_READ_ATTRIBUTES | windows.SYNCHRONIZE, windows.FILE_SHARE_READ | windows.FILE_SHARE_WRITE | windows.FILE_SHARE_DELETE
+	if hold {
+		// Share modes are only enforced for handles with data access.
+		access |= windows.FILE_LIST_DIRECTORY
+		share &^= windows.FILE_SHARE_DELETE
+	}
+	handle, err := windows.CreateFile(pathPtr, access, share, nil, windows.OPEN_EXISTING, flags, 0)
+	if err != nil {
+		return windows.InvalidHandle, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	return handle, nil
+}
+
+// fileAttributeTagInfo is the FILE_ATTRIBUTE_TAG_INFO structure.
+type fileAttributeTagInfo struct {
+	FileAttributes uint32
+	ReparseTag     uint32
+}
+
+// checkReparsePoint returns a [ReparsePointError] if the file that handle
+// refers to is a reparse point.
+func checkReparsePoint(handle windows.Handle, path string) error {
+	var info fileAttributeTagInfo
+	if err := windows.GetFileInformationByHandleEx(handle, windows.FileAttributeTagInfo, (*byte)(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		return &os.PathError{Op: "stat", Path: path, Err: err}
+	}
+	if info.FileAttributes&windows.FILE_ATTRIBUTE_REPARSE_POINT == 0 {
+		return nil
+	}
+
+	kind := "reparse point"
+	switch info.ReparseTag {
+	case windows.IO_REPARSE_TAG_SYMLINK:
+		kind = "symbolic link"
+	case windows.IO_REPARSE_TAG_MOUNT_POINT:
+		kind = "junction"
+	}
+	return ReparsePointError{Path: path, Kind: kind}
+}
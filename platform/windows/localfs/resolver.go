@@ -7,6 +7,7 @@ import (
 	"slices"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lberror"
 	"golang.org/x/sys/windows"
 )
 
@@ -37,9 +38,10 @@ func (resolver *Resolver) ResolveKnownFolder(id lbdeploy.DirectoryResourceID) (l
 	}
 
 	return lbdeploy.KnownFolder{
-		ID:        id,
-		Path:      path,
-		Protected: folder.protected,
+		ID:         id,
+		Path:       path,
+		Protected:  folder.protected,
+		Privileged: folder.privileged,
 	}, nil
 }
 
@@ -52,7 +54,10 @@ func (resolver *Resolver) ResolveKnownFolder(id lbdeploy.DirectoryResourceID) (l
 //
 // If the directory cannot be resolved, an error is returned.
 func (resolver *Resolver) ResolveDirectory(id lbdeploy.DirectoryResourceID) (ref lbdeploy.DirRef, err error) {
-	// TODO: Consider making custom error types for resolution.
+	// Classify any failure as a resolution error.
+	defer func() {
+		err = lberror.Wrap(lberror.Resolution, err)
+	}()
 
 	// Look up the directory by its ID.
 	data, exists := resolver.fs.Directories[id]
@@ -133,7 +138,10 @@ func (resolver *Resolver) ResolveDirectory(id lbdeploy.DirectoryResourceID) (ref
 //
 // If the file cannot be resolved, an error is returned.
 func (resolver *Resolver) ResolveFile(id lbdeploy.FileResourceID) (ref lbdeploy.FileRef, err error) {
-	// TODO: Consider making custom error types for resolution.
+	// Classify any failure as a resolution error.
+	defer func() {
+		err = lberror.Wrap(lberror.Resolution, err)
+	}()
 
 	// Look up the file by its ID.
 	data, exists := resolver.fs.Files[id]
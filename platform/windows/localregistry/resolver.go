@@ -7,6 +7,7 @@ import (
 	"slices"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lberror"
 )
 
 // Resolver is capable of locating registry resources on the local system.
@@ -51,7 +52,10 @@ func (resolver *Resolver) ResolveRoot(id lbdeploy.RegistryKeyResourceID) (lbdepl
 //
 // If the registry key cannot be resolved, an error is returned.
 func (resolver Resolver) ResolveKey(key lbdeploy.RegistryKeyResourceID) (ref lbdeploy.RegistryKeyRef, err error) {
-	// TODO: Consider making custom error types for resolution.
+	// Classify any failure as a resolution error.
+	defer func() {
+		err = lberror.Wrap(lberror.Resolution, err)
+	}()
 
 	// Look up the registry key by its ID.
 	data, exists := resolver.reg.Keys[key]
@@ -134,7 +138,10 @@ func (resolver Resolver) ResolveKey(key lbdeploy.RegistryKeyResourceID) (ref lbd
 //
 // If the registry value cannot be resolved, an error is returned.
 func (resolver Resolver) ResolveValue(value lbdeploy.RegistryValueResourceID) (ref lbdeploy.RegistryValueRef, err error) {
-	// TODO: Consider making custom error types for resolution.
+	// Classify any failure as a resolution error.
+	defer func() {
+		err = lberror.Wrap(lberror.Resolution, err)
+	}()
 
 	// Look up the registry value by its ID.
 	data, exists := resolver.reg.Values[value]
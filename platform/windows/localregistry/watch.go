@@ -0,0 +1,157 @@
+package localregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// watchFilter is the set of changes that a watch is notified about.
+const watchFilter = windows.REG_NOTIFY_CHANGE_NAME | windows.REG_NOTIFY_CHANGE_LAST_SET | windows.REG_NOTIFY_THREAD_AGNOSTIC
+
+// Watch is an armed change notification for a registry key.
+//
+// A watch is signaled once. To wait for subsequent changes, close the
+// watch and start a new one.
+type Watch struct {
+	key     registry.Key
+	path    string
+	subtree bool
+	event   windows.Handle
+}
+
+// WatchKey arms a change notification for the registry key identified by
+// the given registry key reference. The watch is signaled when the values
+// of the key change, or when subkeys are added or removed.
+//
+// If the key does not exist, its nearest ancestor that does exist is
+// watched instead, along with all of its descendants, so that the creation
+// of the key will signal the watch.
+//
+// It is the caller's responsibility to close the watch when finished with
+// it.
+func WatchKey(ref lbdeploy.RegistryKeyRef) (*Watch, error) {
+	// Get the predefined key handle for the root and make sure it is valid.
+	predefinedKey, err := PredefinedKeyHandle(ref.Root.PredefinedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to watch registry key: %w", err)
+	}
+
+	path, err := ref.Root.AbsolutePath()
+	if err != nil {
+		return nil, err
+	}
+
+	// Open the root with notification access.
+	const access = registry.QUERY_VALUE | registry.NOTIFY
+	key, err := registry.OpenKey(predefinedKey, ref.Root.Path, access)
+	if err != nil {
+		return nil, err
+	}
+
+	// Traverse down as far as possible.
+	var subtree bool
+	for _, next := range ref.Lineage {
+		var name string
+		switch {
+		case next.Name != "":
+			name = next.Name
+		case next.Path != "":
+			if name, err = filepath.Localize(next.Path); err != nil {
+				key.Close()
+				return nil, err
+			}
+		default:
+			key.Close()
+			return nil, errors.New("a registry key resource does not specify a name or path")
+		}
+
+		child, err := registry.OpenKey(key, name, access)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// Watch the nearest ancestor instead.
+				subtree = true
+				break
+			}
+			key.Close()
+			return nil, err
+		}
+		key.Close()
+		key = child
+		path = path + `\` + name
+	}
+
+	// Prepare an event that will be signaled when a change occurs.
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		key.Close()
+		return nil, err
+	}
+
+	// Arm the notification.
+	if err := windows.RegNotifyChangeKeyValue(windows.Handle(key), subtree, watchFilter, event, true); err != nil {
+		windows.CloseHandle(event)
+		key.Close()
+		return nil, fmt.Errorf("unable to watch registry key \"%s\": %w", path, err)
+	}
+
+	return &Watch{
+		key:     key,
+		path:    path,
+		subtree: subtree,
+		event:   event,
+	}, nil
+}
+
+// Path returns the path of the registry key that is being watched. If the
+// requested key did not exist, this is the path of its nearest ancestor.
+func (w *Watch) Path() string {
+	return w.path
+}
+
+// Wait blocks until the watch is signaled, the timeout elapses or the
+// context is cancelled. It returns true if the watch was signaled.
+func (w *Watch) Wait(ctx context.Context, timeout time.Duration) (bool, error) {
+	// Prepare an event that is signaled when the context is cancelled.
+	cancelled, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return false, err
+	}
+	defer windows.CloseHandle(cancelled)
+
+	stop := context.AfterFunc(ctx, func() {
+		windows.SetEvent(cancelled)
+	})
+	defer stop()
+
+	ms := uint32(min(max(timeout.Milliseconds(), 0), math.MaxUint32-1))
+	result, err := windows.WaitForMultipleObjects([]windows.Handle{w.event, cancelled}, false, ms)
+	if err != nil {
+		return false, err
+	}
+
+	switch result {
+	case windows.WAIT_OBJECT_0:
+		return true, nil
+	case windows.WAIT_OBJECT_0 + 1:
+		return false, ctx.Err()
+	case uint32(windows.WAIT_TIMEOUT):
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected wait result: %d", result)
+	}
+}
+
+// Close releases the resources held by the watch.
+func (w *Watch) Close() error {
+	windows.CloseHandle(w.event)
+	return w.key.Close()
+}
@@ -0,0 +1,81 @@
+// Package msiexecute detects Windows Installer operations that are in
+// progress on the local system.
+//
+// The Windows Installer only runs one installation at a time. While an
+// installation is executing, it holds the _MSIExecute mutex, and any other
+// installation that is started fails with ERROR_INSTALL_ALREADY_RUNNING
+// (1618).
+package msiexecute
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/platform/windows/winplatform"
+	"golang.org/x/sys/windows"
+)
+
+// MutexName is the name of the mutex that the Windows Installer holds while
+// an installation is executing.
+const MutexName = `Global\_MSIExecute`
+
+// Busy returns true if another Windows Installer operation is in progress.
+func Busy() (bool, error) {
+	name, err := windows.UTF16PtrFromString(MutexName)
+	if err != nil {
+		return false, err
+	}
+
+	// If the mutex doesn't exist, no installation is executing.
+	handle, err := windows.OpenMutex(windows.SYNCHRONIZE, false, name)
+	if err != nil {
+		if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer windows.CloseHandle(handle)
+
+	// The mutex can exist while no installation is executing. Try to
+	// acquire it without waiting. A mutex is owned by a thread, so make
+	// sure that it is released by the same thread that acquired it.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	event, err := windows.WaitForSingleObject(handle, 0)
+	switch event {
+	case windows.WAIT_OBJECT_0, windows.WAIT_ABANDONED:
+		windows.ReleaseMutex(handle)
+		return false, nil
+	case uint32(windows.WAIT_TIMEOUT):
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+// Blocker returns the command line of the Windows Installer client that
+// started the installation in progress, which usually names the product
+// or package being installed. It returns false if a client could not be
+// identified, which is common for installations started through the
+// Windows Installer API by other programs.
+func Blocker() (string, bool) {
+	lines, err := winplatform.CommandLines("msiexec.exe")
+	if err != nil {
+		return "", false
+	}
+
+	for _, line := range lines {
+		// The Windows Installer service runs as "msiexec.exe /V", and
+		// custom action servers run with an "-Embedding" argument.
+		// Neither identifies the installation.
+		lower := strings.ToLower(line)
+		if strings.HasSuffix(lower, " /v") || strings.Contains(lower, " -embedding ") {
+			continue
+		}
+		return line, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,138 @@
+// Package powerscheme queries and changes the power schemes of the local
+// system.
+//
+// The active scheme is read through the power management API. Changes are
+// made through powercfg, which validates the schemes and settings it is
+// given and applies them to the active scheme where necessary.
+package powerscheme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+var (
+	modpowrprof              = windows.NewLazySystemDLL("powrprof.dll")
+	procPowerGetActiveScheme = modpowrprof.NewProc("PowerGetActiveScheme")
+)
+
+// schemesKeyPath is the registry key beneath which the power schemes of
+// the local system are stored.
+const schemesKeyPath = `SYSTEM\CurrentControlSet\Control\Power\User\PowerSchemes`
+
+// Source identifies the power source that a timeout applies to.
+type Source string
+
+// Power sources recognized by powercfg.
+const (
+	AC      Source = "ac"
+	Battery Source = "dc"
+)
+
+// Active returns the GUID of the active power scheme in lower case,
+// without braces.
+func Active() (string, error) {
+	if err := procPowerGetActiveScheme.Find(); err != nil {
+		return "", err
+	}
+	var guid *windows.GUID
+	r1, _, _ := procPowerGetActiveScheme.Call(0, uintptr(unsafe.Pointer(&guid)))
+	if r1 != 0 {
+		return "", fmt.Errorf("failed to determine the active power scheme: %w", windows.Errno(r1))
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(guid)))
+	return formatGUID(*guid), nil
+}
+
+// Exists returns true if a power scheme with the given GUID is present on
+// the local system.
+func Exists(guid string) (bool, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, schemesKeyPath+`\`+guid, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	key.Close()
+	return true, nil
+}
+
+// SetActive makes the power scheme with the given GUID active.
+func SetActive(ctx context.Context, guid string) error {
+	return run(ctx, "/setactive", guid)
+}
+
+// Import imports the power scheme stored in file, which must have been
+// exported by powercfg, under the given GUID. An existing scheme with the
+// same GUID is replaced.
+func Import(ctx context.Context, file, guid string) error {
+	exists, err := Exists(guid)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := run(ctx, "/delete", guid); err != nil {
+			return fmt.Errorf("failed to remove the existing power scheme: %w", err)
+		}
+	}
+	return run(ctx, "/import", file, guid)
+}
+
+// Restore creates a power scheme with the given GUID from the built-in
+// scheme of the same GUID. This makes schemes that Windows hides by
+// default, such as Ultimate Performance, available for use.
+func Restore(ctx context.Context, guid string) error {
+	return run(ctx, "/duplicatescheme", guid, guid)
+}
+
+// SetTimeout sets a timeout of the active power scheme for the given power
+// source. The setting is named as powercfg expects it, such as
+// "standby-timeout". A timeout of zero minutes means never.
+func SetTimeout(ctx context.Context, setting string, source Source, minutes int) error {
+	return run(ctx, "/change", setting+"-"+string(source), strconv.Itoa(minutes))
+}
+
+// SetHibernation enables or disables hibernation for the local system.
+func SetHibernation(ctx context.Context, enabled bool) error {
+	if enabled {
+		return run(ctx, "/hibernate", "on")
+	}
+	return run(ctx, "/hibernate", "off")
+}
+
+// run runs powercfg with the given arguments.
+func run(ctx context.Context, args ...string) error {
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return err
+	}
+
+	output, err := exec.CommandContext(ctx, filepath.Join(system, "powercfg.exe"), args...).CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// formatGUID returns the canonical lower case representation of guid,
+// without braces.
+func formatGUID(guid windows.GUID) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%02x%02x-%02x%02x%02x%02x%02x%02x",
+		guid.Data1, guid.Data2, guid.Data3,
+		guid.Data4[0], guid.Data4[1], guid.Data4[2], guid.Data4[3],
+		guid.Data4[4], guid.Data4[5], guid.Data4[6], guid.Data4[7])
+}
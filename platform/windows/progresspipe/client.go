@@ -0,0 +1,95 @@
+package progresspipe
+
+import (
+	"sync"
+
+	"golang.org/x/sys/windows"
+)
+
+// client is a connected instance of the pipe. It writes the most recent
+// status that it has been sent, dropping any that it could not keep up
+// with.
+type client struct {
+	pipe    windows.Handle
+	remove  func(*client)
+	mutex   sync.Mutex
+	pending []byte
+	closing bool
+	ready   chan struct{}
+	done    chan struct{}
+}
+
+func newClient(pipe windows.Handle, remove func(*client)) *client {
+	c := &client{
+		pipe:   pipe,
+		remove: remove,
+		ready:  make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// send queues data to be written to the client, replacing any data that
+// has not been written yet.
+func (c *client) send(data []byte) {
+	c.mutex.Lock()
+	c.pending = data
+	c.mutex.Unlock()
+
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+// close writes any pending data, then disconnects the client and waits
+// for it to finish.
+func (c *client) close() {
+	c.mutex.Lock()
+	c.closing = true
+	c.mutex.Unlock()
+
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+	<-c.done
+}
+
+// run writes data to the client until it disconnects or is closed.
+func (c *client) run() {
+	defer close(c.done)
+	defer windows.CloseHandle(c.pipe)
+	defer windows.DisconnectNamedPipe(c.pipe)
+
+	for range c.ready {
+		c.mutex.Lock()
+		data, closing := c.pending, c.closing
+		c.pending = nil
+		c.mutex.Unlock()
+
+		if data != nil {
+			if err := write(c.pipe, data); err != nil {
+				c.remove(c)
+				return
+			}
+		}
+		if closing {
+			windows.FlushFileBuffers(c.pipe)
+			return
+		}
+	}
+}
+
+// write writes all of data to the pipe.
+func write(pipe windows.Handle, data []byte) error {
+	for len(data) > 0 {
+		var n uint32
+		if err := windows.WriteFile(pipe, data, &n, nil); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Package progresspipe streams the progress of LeafBridge deployments to
+// other processes through a named pipe.
+//
+// Each client that connects to the pipe receives a stream of JSON
+// documents, one per line, that describe the status of the deployment.
+// The most recent status is sent as soon as a client connects. Clients
+// that fall behind only receive the most recent status, so a slow client
+// never delays the deployment.
+//
+// Completion is signaled in two ways. The last status of a deployment is
+// finished, with a state of succeeded, failed or cancelled. When the server
+// is closed, each client is sent the most recent status and then
+// disconnected, so that it reads the end of the stream. A client that
+// reaches the end of the stream without a finished status can assume that
+// the deployment stopped unexpectedly.
+//
+// The pipe is outbound only. Its security descriptor lets local
+// authenticated users read from it, so that a user interface running in
+// a user's session can present the progress of a deployment that runs
+// as SYSTEM.
+package progresspipe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/core/lbprogress"
+	"golang.org/x/sys/windows"
+)
+
+// DefaultName is the default name of the progress pipe.
+const DefaultName = `\\.\pipe\LeafBridge\Progress`
+
+// RunName returns a pipe name that is unique to the given run of
+// LeafBridge.
+func RunName(run lbevent.RunID) string {
+	return DefaultName + `\` + string(run)
+}
+
+// securityDescriptor grants full control of the pipe to SYSTEM and to
+// administrators, and read access to authenticated users.
+const securityDescriptor = "D:P(A;;GA;;;SY)(A;;GA;;;BA)(A;;GR;;;AU)"
+
+// bufferSize is the size of the pipe's output buffer.
+const bufferSize = 4096
+
+// Server is a named pipe server that publishes the status of a deployment
+// to connected clients.
+type Server struct {
+	name string
+	sa   windows.SecurityAttributes
+
+	mutex   sync.Mutex
+	clients map[*client]struct{}
+	latest  []byte
+	closed  bool
+	done    chan struct{}
+}
+
+// Listen creates a named pipe with the given name and starts accepting
+// clients. If name is empty, DefaultName is used.
+//
+// An error is returned if the pipe already exists, which prevents another
+// process from intercepting clients.
+func Listen(name string) (*Server, error) {
+	if name == "" {
+		name = DefaultName
+	}
+
+	sd, err := windows.SecurityDescriptorFromString(securityDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare a security descriptor for the progress pipe: %w", err)
+	}
+
+	s := &Server{
+		name:    name,
+		sa:      windows.SecurityAttributes{SecurityDescriptor: sd},
+		clients: make(map[*client]struct{}),
+		done:    make(chan struct{}),
+	}
+	s.sa.Length = uint32(unsafe.Sizeof(s.sa))
+
+	pipe, err := s.create(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the \"%s\" progress pipe: %w", name, err)
+	}
+
+	go s.accept(pipe)
+
+	return s, nil
+}
+
+// Name returns the name of the pipe.
+func (s *Server) Name() string {
+	return s.name
+}
+
+// Publish sends the status to all connected clients. It does not wait for
+// the clients to receive it.
+func (s *Server) Publish(status lbprogress.Status) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.latest = data
+	for c := range s.clients {
+		c.send(data)
+	}
+}
+
+// Close stops accepting clients and disconnects all connected clients.
+// Each client is sent the most recent status before it is disconnected.
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	clients := s.clients
+	s.clients = nil
+	s.mutex.Unlock()
+
+	// Wake the accept loop by connecting to the pipe.
+	if name, err := windows.UTF16PtrFromString(s.name); err == nil {
+		if h, err := windows.CreateFile(name, windows.GENERIC_READ, 0, nil, windows.OPEN_EXISTING, 0, 0); err == nil {
+			windows.CloseHandle(h)
+		}
+	}
+	<-s.done
+
+	for c := range clients {
+		c.close()
+	}
+
+	return nil
+}
+
+// create creates a new instance of the pipe.
+func (s *Server) create(first bool) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(s.name)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+
+	flags := uint32(windows.PIPE_ACCESS_OUTBOUND)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	mode := uint32(windows.PIPE_TYPE_BYTE | windows.PIPE_READMODE_BYTE | windows.PIPE_WAIT | windows.PIPE_REJECT_REMOTE_CLIENTS)
+
+	return windows.CreateNamedPipe(name, flags, mode, windows.PIPE_UNLIMITED_INSTANCES, bufferSize, 0, 0, &s.sa)
+}
+
+// accept waits for clients to connect to the pipe until the server is
+// closed.
+func (s *Server) accept(pipe windows.Handle) {
+	defer close(s.done)
+
+	for {
+		err := windows.ConnectNamedPipe(pipe, nil)
+		if err != nil && !errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+			windows.CloseHandle(pipe)
+			return
+		}
+
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			windows.DisconnectNamedPipe(pipe)
+			windows.CloseHandle(pipe)
+			return
+		}
+		c := newClient(pipe, s.remove)
+		s.clients[c] = struct{}{}
+		if s.latest != nil {
+			c.send(s.latest)
+		}
+		s.mutex.Unlock()
+
+		// Prepare another instance for the next client.
+		pipe, err = s.create(false)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// remove removes a client that has disconnected.
+func (s *Server) remove(c *client) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.clients, c)
+}
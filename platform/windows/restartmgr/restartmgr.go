@@ -0,0 +1,232 @@
+// Package restartmgr identifies the processes that are using files on a
+// Windows machine through the Restart Manager API. It can also shut those
+// processes down and restart them.
+package restartmgr
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modrstrtmgr             = windows.NewLazySystemDLL("rstrtmgr.dll")
+	procRmStartSession      = modrstrtmgr.NewProc("RmStartSession")
+	procRmEndSession        = modrstrtmgr.NewProc("RmEndSession")
+	procRmRegisterResources = modrstrtmgr.NewProc("RmRegisterResources")
+	procRmGetList           = modrstrtmgr.NewProc("RmGetList")
+	procRmShutdown          = modrstrtmgr.NewProc("RmShutdown")
+	procRmRestart           = modrstrtmgr.NewProc("RmRestart")
+)
+
+// rmForceShutdown forces unresponsive applications and services to shut
+// down after the timeout period.
+const rmForceShutdown = 0x1
+
+// Restart Manager string lengths, including the terminating null.
+const (
+	sessionKeyLen = 32 + 1
+	appNameLen    = 255 + 1
+	serviceLen    = 63 + 1
+)
+
+// AppType identifies the type of application that is using a file.
+type AppType uint32
+
+// Restart Manager application types.
+const (
+	AppUnknown     AppType = 0
+	AppMainWindow  AppType = 1
+	AppOtherWindow AppType = 2
+	AppService     AppType = 3
+	AppExplorer    AppType = 4
+	AppConsole     AppType = 5
+	AppCritical    AppType = 1000
+)
+
+// String returns a string representation of the application type.
+func (t AppType) String() string {
+	switch t {
+	case AppUnknown:
+		return "unknown"
+	case AppMainWindow:
+		return "window"
+	case AppOtherWindow:
+		return "other-window"
+	case AppService:
+		return "service"
+	case AppExplorer:
+		return "explorer"
+	case AppConsole:
+		return "console"
+	case AppCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("<unknown app type %d>", uint32(t))
+	}
+}
+
+// Process describes a process that is using a registered file.
+type Process struct {
+	ID          uint32
+	StartTime   time.Time
+	Name        string
+	Service     string
+	Type        AppType
+	Session     uint32
+	Restartable bool
+}
+
+// uniqueProcess is the RM_UNIQUE_PROCESS structure.
+type uniqueProcess struct {
+	ProcessID        uint32
+	ProcessStartTime windows.Filetime
+}
+
+// processInfo is the RM_PROCESS_INFO structure.
+type processInfo struct {
+	Process          uniqueProcess
+	AppName          [appNameLen]uint16
+	ServiceShortName [serviceLen]uint16
+	ApplicationType  uint32
+	AppStatus        uint32
+	TSSessionID      uint32
+	Restartable      int32
+}
+
+// Session is a Restart Manager session.
+type Session struct {
+	handle uint32
+}
+
+// Start starts a new Restart Manager session. The session must be closed
+// when it is no longer needed.
+func Start() (*Session, error) {
+	var (
+		handle uint32
+		key    [sessionKeyLen]uint16
+	)
+	r1, _, _ := procRmStartSession.Call(uintptr(unsafe.Pointer(&handle)), 0, uintptr(unsafe.Pointer(&key[0])))
+	if r1 != 0 {
+		return nil, fmt.Errorf("failed to start a restart manager session: %w", windows.Errno(r1))
+	}
+	return &Session{handle: handle}, nil
+}
+
+// RegisterFiles registers files with the session, so that the processes
+// using them can be identified.
+func (s *Session) RegisterFiles(paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	names := make([]*uint16, len(paths))
+	for i, path := range paths {
+		name, err := windows.UTF16PtrFromString(path)
+		if err != nil {
+			return err
+		}
+		names[i] = name
+	}
+
+	r1, _, _ := procRmRegisterResources.Call(uintptr(s.handle), uintptr(len(names)), uintptr(unsafe.Pointer(&names[0])), 0, 0, 0, 0)
+	if r1 != 0 {
+		return fmt.Errorf("failed to register files with the restart manager: %w", windows.Errno(r1))
+	}
+	return nil
+}
+
+// Processes returns the processes that are using the files registered with
+// the session.
+func (s *Session) Processes() ([]Process, error) {
+	var infos []processInfo
+	for {
+		count := uint32(len(infos))
+		var needed, reasons uint32
+		var first *processInfo
+		if count > 0 {
+			first = &infos[0]
+		}
+		r1, _, _ := procRmGetList.Call(uintptr(s.handle), uintptr(unsafe.Pointer(&needed)), uintptr(unsafe.Pointer(&count)), uintptr(unsafe.Pointer(first)), uintptr(unsafe.Pointer(&reasons)))
+		if windows.Errno(r1) == windows.ERROR_MORE_DATA {
+			// The set of processes can grow between calls, so leave a
+			// little room.
+			infos = make([]processInfo, needed+4)
+			continue
+		}
+		if r1 != 0 {
+			return nil, fmt.Errorf("failed to retrieve the processes using the files: %w", windows.Errno(r1))
+		}
+		infos = infos[:count]
+		break
+	}
+
+	processes := make([]Process, 0, len(infos))
+	for i := range infos {
+		info := &infos[i]
+		processes = append(processes, Process{
+			ID:          info.Process.ProcessID,
+			StartTime:   time.Unix(0, info.Process.ProcessStartTime.Nanoseconds()),
+			Name:        windows.UTF16ToString(info.AppName[:]),
+			Service:     windows.UTF16ToString(info.ServiceShortName[:]),
+			Type:        AppType(info.ApplicationType),
+			Session:     info.TSSessionID,
+			Restartable: info.Restartable != 0,
+		})
+	}
+	return processes, nil
+}
+
+// Shutdown shuts down the applications and services that are using the
+// files registered with the session. Applications that have registered
+// for restart will be restarted by a subsequent call to Restart.
+//
+// If force is true, applications that do not respond are forced to shut
+// down.
+func (s *Session) Shutdown(force bool) error {
+	var flags uintptr
+	if force {
+		flags = rmForceShutdown
+	}
+	r1, _, _ := procRmShutdown.Call(uintptr(s.handle), flags, 0)
+	if r1 != 0 {
+		return fmt.Errorf("failed to shut down the applications using the files: %w", windows.Errno(r1))
+	}
+	return nil
+}
+
+// Restart restarts the applications and services that were shut down by
+// Shutdown.
+func (s *Session) Restart() error {
+	r1, _, _ := procRmRestart.Call(uintptr(s.handle), 0, 0)
+	if r1 != 0 {
+		return fmt.Errorf("failed to restart the applications that were shut down: %w", windows.Errno(r1))
+	}
+	return nil
+}
+
+// Close ends the session.
+func (s *Session) Close() error {
+	r1, _, _ := procRmEndSession.Call(uintptr(s.handle))
+	if r1 != 0 {
+		return fmt.Errorf("failed to end the restart manager session: %w", windows.Errno(r1))
+	}
+	return nil
+}
+
+// FileUsers returns the processes that are using any of the given files.
+func FileUsers(paths ...string) ([]Process, error) {
+	session, err := Start()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	if err := session.RegisterFiles(paths...); err != nil {
+		return nil, err
+	}
+
+	return session.Processes()
+}
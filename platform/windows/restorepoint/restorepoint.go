@@ -0,0 +1,105 @@
+// Package restorepoint creates System Restore points on the local system.
+package restorepoint
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modsrclient            = windows.NewLazySystemDLL("srclient.dll")
+	procSRSetRestorePointW = modsrclient.NewProc("SRSetRestorePointW")
+)
+
+// System Restore event types.
+const (
+	beginSystemChange = 100
+	endSystemChange   = 101
+)
+
+// maxDescription is the maximum length of a restore point description in
+// UTF-16 code units, excluding the terminating null.
+const maxDescription = 255
+
+// Type identifies the type of change that a restore point precedes.
+type Type uint32
+
+// Restore point types.
+const (
+	ApplicationInstall   Type = 0
+	ApplicationUninstall Type = 1
+	ModifySettings       Type = 12
+)
+
+// restorePointInfo is the RESTOREPOINTINFOW structure.
+type restorePointInfo struct {
+	EventType      uint32
+	RestorePtType  uint32
+	SequenceNumber int64
+	Description    [maxDescription + 1]uint16
+}
+
+// stateMgrStatus is the STATEMGRSTATUS structure. It is packed, so the
+// sequence number is split into two halves to avoid padding.
+type stateMgrStatus struct {
+	Status         uint32
+	SequenceNumber [2]uint32
+}
+
+// Point is a restore point that has been started by Begin.
+type Point struct {
+	Sequence int64
+}
+
+// Begin starts a restore point with the given description, which is shown
+// to users when they choose a restore point. The change must be ended by
+// calling End once it is finished.
+//
+// Windows limits how often restore points can be created. When a restore
+// point has been created recently, Windows might not create a new one.
+func Begin(description string, t Type) (Point, error) {
+	info := restorePointInfo{
+		EventType:     beginSystemChange,
+		RestorePtType: uint32(t),
+	}
+	encoded := utf16.Encode([]rune(description))
+	if len(encoded) > maxDescription {
+		encoded = encoded[:maxDescription]
+	}
+	copy(info.Description[:], encoded)
+
+	seq, err := set(&info)
+	if err != nil {
+		return Point{}, fmt.Errorf("failed to create a restore point: %w", err)
+	}
+	return Point{Sequence: seq}, nil
+}
+
+// End marks the change that the restore point precedes as finished.
+func (p Point) End() error {
+	info := restorePointInfo{
+		EventType:      endSystemChange,
+		SequenceNumber: p.Sequence,
+	}
+	if _, err := set(&info); err != nil {
+		return fmt.Errorf("failed to finish restore point %d: %w", p.Sequence, err)
+	}
+	return nil
+}
+
+// set calls SRSetRestorePointW and returns the resulting sequence number.
+func set(info *restorePointInfo) (int64, error) {
+	if err := procSRSetRestorePointW.Find(); err != nil {
+		return 0, fmt.Errorf("system restore is not available: %w", err)
+	}
+
+	var status stateMgrStatus
+	r1, _, _ := procSRSetRestorePointW.Call(uintptr(unsafe.Pointer(info)), uintptr(unsafe.Pointer(&status)))
+	if r1 == 0 {
+		return 0, windows.Errno(status.Status)
+	}
+	return int64(uint64(status.SequenceNumber[1])<<32 | uint64(status.SequenceNumber[0])), nil
+}
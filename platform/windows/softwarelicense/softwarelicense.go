@@ -0,0 +1,245 @@
+// Package softwarelicense installs product keys and activates the volume
+// licenses of Windows and Office on the local system.
+//
+// It works through the SoftwareLicensingService and SoftwareLicensingProduct
+// classes of Windows Management Instrumentation, which it reaches through
+// Windows PowerShell, in the same way that slmgr.vbs and ospp.vbs do. The
+// license status and error codes are reported as numbers, so the output is
+// not affected by the display language of the system.
+package softwarelicense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// Application identifies the application that a license belongs to.
+type Application string
+
+// Applications licensed by the Software Protection Platform.
+const (
+	Windows Application = "55c92734-d682-4d71-983e-d6ec3f16059f"
+	Office  Application = "0ff1ce15-a989-479d-af46-f275c6370663"
+)
+
+// Status is the license status of a product.
+type Status int
+
+// License status values, as defined by SoftwareLicensingProduct.
+const (
+	Unlicensed      Status = 0
+	Licensed        Status = 1
+	OOBGrace        Status = 2
+	OOTGrace        Status = 3
+	NonGenuineGrace Status = 4
+	Notification    Status = 5
+	ExtendedGrace   Status = 6
+)
+
+// String returns a string representation of the status.
+func (s Status) String() string {
+	switch s {
+	case Unlicensed:
+		return "unlicensed"
+	case Licensed:
+		return "licensed"
+	case OOBGrace:
+		return "initial grace period"
+	case OOTGrace:
+		return "additional grace period"
+	case NonGenuineGrace:
+		return "non-genuine grace period"
+	case Notification:
+		return "notification"
+	case ExtendedGrace:
+		return "extended grace period"
+	default:
+		return fmt.Sprintf("<unknown license status %d>", int(s))
+	}
+}
+
+// License describes a product of an application that has a product key
+// installed.
+type License struct {
+	ID                string
+	Name              string
+	PartialProductKey string
+	Status            Status
+}
+
+// Error is an error reported by the Software Protection Platform.
+type Error uint32
+
+// Error returns a description of the error.
+func (e Error) Error() string {
+	if message, found := errorMessages[e]; found {
+		return fmt.Sprintf("%s (0x%08X)", message, uint32(e))
+	}
+	return fmt.Sprintf("the Software Protection Platform reported error 0x%08X", uint32(e))
+}
+
+// ErrKMSUnavailable is reported when a key management service could not be
+// contacted.
+const ErrKMSUnavailable Error = 0xC004F074
+
+// errorMessages describe the errors that are commonly encountered during
+// volume activation.
+var errorMessages = map[Error]string{
+	ErrKMSUnavailable: "no key management service could be contacted",
+	0xC004F038:        "the key management service does not have enough activations to activate this product",
+	0xC004F039:        "the key management service is not enabled",
+	0xC004F041:        "the key management service could not be verified",
+	0xC004F042:        "the key management service cannot activate this product",
+	0xC004F050:        "the product key is invalid",
+	0xC004F069:        "the product key does not match an installed product",
+	0xC004C003:        "the product key is blocked",
+	0xC004C008:        "the product key has exceeded its activation limit",
+	0xC004F015:        "the license is not installed",
+	0xC004E015:        "the product key could not be installed",
+}
+
+// productKeyVariable is the environment variable through which product
+// keys are passed to the scripts, so that they do not appear on the command
+// line of a process.
+const productKeyVariable = "LEAFBRIDGE_PRODUCT_KEY"
+
+// errorScript reports the error code of a failed licensing operation, and
+// rethrows other errors.
+const errorScript = `$ErrorActionPreference = 'Stop'
+trap {
+	$code = $null
+	if ($_.Exception -is [Microsoft.Management.Infrastructure.CimException] -and $null -ne $_.Exception.ErrorData) {
+		$code = $_.Exception.ErrorData.CimInstanceProperties['error_Code'].Value
+	}
+	if ($null -eq $code) { break }
+	Write-Output ('error {0}' -f [uint32]$code)
+	exit 0
+}
+$service = Get-CimInstance -ClassName 'SoftwareLicensingService'
+`
+
+// licensesScript writes a line for each licensed product of an
+// application.
+const licensesScript = `Get-CimInstance -ClassName 'SoftwareLicensingProduct' -Filter "ApplicationID='%s' AND PartialProductKey IS NOT NULL" | ForEach-Object {
+	Write-Output ('license {0}` + "`t" + `{1}` + "`t" + `{2}` + "`t" + `{3}' -f $_.ID, $_.PartialProductKey, $_.LicenseStatus, $_.Name)
+}
+`
+
+// Licenses returns the products of the application that have a product key
+// installed.
+func Licenses(ctx context.Context, app Application) ([]License, error) {
+	output, err := run(ctx, "", fmt.Sprintf(licensesScript, app))
+	if err != nil {
+		return nil, err
+	}
+	return parseLicenses(output)
+}
+
+// InstallProductKey installs a product key. The application it belongs to
+// is determined by the key.
+func InstallProductKey(ctx context.Context, key string) error {
+	_, err := run(ctx, key, `Invoke-CimMethod -InputObject $service -MethodName 'InstallProductKey' -Arguments @{ProductKey=$env:`+productKeyVariable+`} | Out-Null
+Invoke-CimMethod -InputObject $service -MethodName 'RefreshLicenseStatus' | Out-Null
+`)
+	return err
+}
+
+// SetKMSHost sets the key management service that is used for activation.
+func SetKMSHost(ctx context.Context, host string, port int) error {
+	if host == "" || strings.ContainsAny(host, "'\"`$") {
+		return fmt.Errorf("the KMS host \"%s\" is not valid", host)
+	}
+	_, err := run(ctx, "", fmt.Sprintf(`Invoke-CimMethod -InputObject $service -MethodName 'SetKeyManagementServiceMachine' -Arguments @{MachineName='%s'} | Out-Null
+Invoke-CimMethod -InputObject $service -MethodName 'SetKeyManagementServicePort' -Arguments @{PortNumber=[uint32]%d} | Out-Null
+`, host, port))
+	return err
+}
+
+// Activate activates each product of the application that has a product
+// key installed and is not yet licensed. It returns the licenses of the
+// application afterwards, so that the caller can confirm their status.
+func Activate(ctx context.Context, app Application) ([]License, error) {
+	output, err := run(ctx, "", fmt.Sprintf(`Get-CimInstance -ClassName 'SoftwareLicensingProduct' -Filter "ApplicationID='%s' AND PartialProductKey IS NOT NULL AND LicenseStatus <> 1" | ForEach-Object {
+	Invoke-CimMethod -InputObject $_ -MethodName 'Activate' | Out-Null
+}
+Invoke-CimMethod -InputObject $service -MethodName 'RefreshLicenseStatus' | Out-Null
+`, app)+fmt.Sprintf(licensesScript, app))
+	if err != nil {
+		return nil, err
+	}
+	return parseLicenses(output)
+}
+
+// parseLicenses parses the output of licensesScript.
+func parseLicenses(output string) ([]License, error) {
+	var licenses []License
+	for line := range strings.Lines(output) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields, found := strings.CutPrefix(line, "license ")
+		if !found {
+			return nil, fmt.Errorf("unexpected output \"%s\"", line)
+		}
+		parts := strings.SplitN(fields, "\t", 4)
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("unexpected license \"%s\"", fields)
+		}
+		status, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected license status \"%s\"", parts[2])
+		}
+		licenses = append(licenses, License{
+			ID:                parts[0],
+			PartialProductKey: parts[1],
+			Status:            Status(status),
+			Name:              parts[3],
+		})
+	}
+	return licenses, nil
+}
+
+// run runs script after errorScript. If key is not empty, it is passed to
+// the script through an environment variable. It returns the trimmed output
+// of the script, or the error reported by the Software Protection Platform.
+func run(ctx context.Context, key, script string) (string, error) {
+	system, err := windows.GetSystemDirectory()
+	if err != nil {
+		return "", err
+	}
+	powershell := filepath.Join(system, "WindowsPowerShell", "v1.0", "powershell.exe")
+
+	cmd := exec.CommandContext(ctx, powershell, "-NoLogo", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", errorScript+script)
+	if key != "" {
+		cmd.Env = append(os.Environ(), productKeyVariable+"="+key)
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", errors.New(message)
+		}
+		return "", err
+	}
+
+	result := strings.TrimSpace(string(output))
+	if last := result[strings.LastIndex(result, "\n")+1:]; strings.HasPrefix(last, "error ") {
+		code, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(last, "error ")), 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("unexpected error code \"%s\"", last)
+		}
+		return "", Error(code)
+	}
+	return result, nil
+}
@@ -0,0 +1,197 @@
+// Package sspi performs client-side authentication through the Windows
+// Security Support Provider Interface.
+//
+// It supports the Negotiate, Kerberos and NTLM security packages, using the
+// credentials of the security context that the process is running in. When
+// LeafBridge runs as a service, this is the computer account.
+package sspi
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modsecur32                     = windows.NewLazySystemDLL("secur32.dll")
+	procAcquireCredentialsHandleW  = modsecur32.NewProc("AcquireCredentialsHandleW")
+	procInitializeSecurityContextW = modsecur32.NewProc("InitializeSecurityContextW")
+	procCompleteAuthToken          = modsecur32.NewProc("CompleteAuthToken")
+	procFreeCredentialsHandle      = modsecur32.NewProc("FreeCredentialsHandle")
+	procDeleteSecurityContext      = modsecur32.NewProc("DeleteSecurityContext")
+	procFreeContextBuffer          = modsecur32.NewProc("FreeContextBuffer")
+)
+
+// SSPI constants.
+const (
+	secpkgCredOutbound       = 2
+	securityNativeDrep       = 0x10
+	secbufferVersion         = 0
+	secbufferToken           = 2
+	iscReqMutualAuth         = 0x2
+	iscReqConfidentiality    = 0x10
+	iscReqAllocateMemory     = 0x100
+	iscReqConnection         = 0x800
+	secEOK                   = 0
+	secIContinueNeeded       = 0x00090312
+	secICompleteNeeded       = 0x00090313
+	secICompleteAndContinue  = 0x00090314
+	contextRequirementsFlags = iscReqMutualAuth | iscReqConfidentiality | iscReqAllocateMemory | iscReqConnection
+)
+
+// Package identifies a security package.
+type Package string
+
+// Supported security packages.
+const (
+	Negotiate Package = "Negotiate"
+	Kerberos  Package = "Kerberos"
+	NTLM      Package = "NTLM"
+)
+
+type secHandle struct {
+	lower uintptr
+	upper uintptr
+}
+
+func (h secHandle) isZero() bool {
+	return h.lower == 0 && h.upper == 0
+}
+
+type secBuffer struct {
+	size       uint32
+	bufferType uint32
+	buffer     *byte
+}
+
+type secBufferDesc struct {
+	version uint32
+	count   uint32
+	buffers *secBuffer
+}
+
+// Client is the client side of an authentication exchange. It must be
+// closed when it is no longer needed.
+type Client struct {
+	target  *uint16
+	cred    secHandle
+	ctx     secHandle
+	expiry  int64
+	started bool
+}
+
+// NewClient prepares an authentication exchange with the given security
+// package. The target is the service principal name of the server, such as
+// HTTP/server.example.com.
+func NewClient(pkg Package, target string) (*Client, error) {
+	pkgName, err := windows.UTF16PtrFromString(string(pkg))
+	if err != nil {
+		return nil, err
+	}
+	targetName, err := windows.UTF16PtrFromString(target)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{target: targetName}
+	r, _, _ := procAcquireCredentialsHandleW.Call(
+		0,
+		uintptr(unsafe.Pointer(pkgName)),
+		secpkgCredOutbound,
+		0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&c.cred)),
+		uintptr(unsafe.Pointer(&c.expiry)))
+	if r != secEOK {
+		return nil, fmt.Errorf("failed to acquire %s credentials: %w", pkg, windows.Errno(r))
+	}
+	return c, nil
+}
+
+// Step processes a token received from the server and returns the next
+// token to send to it. The first call should provide a nil input token.
+//
+// When done is true, the client's side of the exchange is complete, but
+// the returned token must still be sent to the server if it isn't empty.
+func (c *Client) Step(input []byte) (output []byte, done bool, err error) {
+	if c.cred.isZero() {
+		return nil, false, errors.New("the client has been closed")
+	}
+
+	// Prepare the input buffer, if a token was provided.
+	var (
+		inBuf  secBuffer
+		inDesc secBufferDesc
+		inPtr  uintptr
+	)
+	if len(input) > 0 {
+		inBuf = secBuffer{size: uint32(len(input)), bufferType: secbufferToken, buffer: &input[0]}
+		inDesc = secBufferDesc{version: secbufferVersion, count: 1, buffers: &inBuf}
+		inPtr = uintptr(unsafe.Pointer(&inDesc))
+	}
+
+	// Prepare the output buffer, which is allocated by the system.
+	outBuf := secBuffer{bufferType: secbufferToken}
+	outDesc := secBufferDesc{version: secbufferVersion, count: 1, buffers: &outBuf}
+
+	// The existing context is passed on every call after the first.
+	var ctxPtr uintptr
+	if c.started {
+		ctxPtr = uintptr(unsafe.Pointer(&c.ctx))
+	}
+
+	var attrs uint32
+	r, _, _ := procInitializeSecurityContextW.Call(
+		uintptr(unsafe.Pointer(&c.cred)),
+		ctxPtr,
+		uintptr(unsafe.Pointer(c.target)),
+		contextRequirementsFlags,
+		0,
+		securityNativeDrep,
+		inPtr,
+		0,
+		uintptr(unsafe.Pointer(&c.ctx)),
+		uintptr(unsafe.Pointer(&outDesc)),
+		uintptr(unsafe.Pointer(&attrs)),
+		uintptr(unsafe.Pointer(&c.expiry)))
+	if outBuf.buffer != nil {
+		defer procFreeContextBuffer.Call(uintptr(unsafe.Pointer(outBuf.buffer)))
+	}
+
+	switch r {
+	case secEOK, secIContinueNeeded, secICompleteNeeded, secICompleteAndContinue:
+		c.started = true
+	default:
+		return nil, false, fmt.Errorf("failed to initialize the security context: %w", windows.Errno(r))
+	}
+
+	// Some security packages require the token to be completed before it
+	// is sent.
+	if r == secICompleteNeeded || r == secICompleteAndContinue {
+		if r, _, _ := procCompleteAuthToken.Call(uintptr(unsafe.Pointer(&c.ctx)), uintptr(unsafe.Pointer(&outDesc))); r != secEOK {
+			return nil, false, fmt.Errorf("failed to complete the authentication token: %w", windows.Errno(r))
+		}
+	}
+
+	// Copy the token out of the buffer allocated by the system.
+	if outBuf.buffer != nil && outBuf.size > 0 {
+		output = append([]byte(nil), unsafe.Slice(outBuf.buffer, outBuf.size)...)
+	}
+
+	done = r == secEOK || r == secICompleteNeeded
+	return output, done, nil
+}
+
+// Close releases the security context and credentials held by the client.
+func (c *Client) Close() error {
+	if c.started {
+		procDeleteSecurityContext.Call(uintptr(unsafe.Pointer(&c.ctx)))
+		c.started = false
+	}
+	if !c.cred.isZero() {
+		procFreeCredentialsHandle.Call(uintptr(unsafe.Pointer(&c.cred)))
+		c.cred = secHandle{}
+	}
+	return nil
+}
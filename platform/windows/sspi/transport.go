@@ -0,0 +1,156 @@
+package sspi
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxLegs is the maximum number of authentication round trips that will be
+// attempted for a single request. NTLM requires two, and Kerberos usually
+// requires one.
+const maxLegs = 3
+
+// Transport is an HTTP round tripper that responds to authentication
+// challenges from a particular host using a security package.
+//
+// Connection-oriented schemes like NTLM require the authenticated requests
+// to be sent over the same connection, so the base round tripper should
+// limit itself to a single connection per host.
+type Transport struct {
+	Package Package
+	Host    string
+	Base    http.RoundTripper
+}
+
+// NewTransport returns an HTTP transport that authenticates with the given
+// host using the given security package. Other hosts are never sent
+// credentials, even when a request is redirected to them.
+func NewTransport(pkg Package, host string) *Transport {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.MaxConnsPerHost = 1
+	return &Transport{
+		Package: pkg,
+		Host:    host,
+		Base:    base,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction. If the server responds
+// with an authentication challenge that the transport can satisfy, the
+// request is repeated with the appropriate authorization.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// Only authenticate with the intended host.
+	if !strings.EqualFold(req.URL.Hostname(), t.Host) {
+		return resp, nil
+	}
+
+	// Requests with bodies can only be repeated if their bodies can be
+	// recreated.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// Determine which authentication scheme to respond with.
+	scheme := t.scheme(resp)
+	if scheme == "" {
+		return resp, nil
+	}
+
+	client, err := NewClient(t.Package, "HTTP/"+req.URL.Hostname())
+	if err != nil {
+		discard(resp)
+		return nil, err
+	}
+	defer client.Close()
+
+	var input []byte
+	for leg := 0; leg < maxLegs; leg++ {
+		output, _, err := client.Step(input)
+		if err != nil {
+			discard(resp)
+			return nil, err
+		}
+
+		// Discard the challenge response, so that its connection can be
+		// reused for the next request.
+		discard(resp)
+
+		next := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		next.Header.Set("Authorization", scheme+" "+base64.StdEncoding.EncodeToString(output))
+
+		resp, err = t.Base.RoundTrip(next)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+
+		// If the server didn't include a token, it rejected our
+		// credentials.
+		input = challengeToken(resp, scheme)
+		if input == nil {
+			return resp, nil
+		}
+	}
+
+	return resp, nil
+}
+
+// scheme returns the HTTP authentication scheme to use when responding to
+// the challenges in resp. It returns an empty string if none of the
+// offered schemes can be satisfied by the transport's security package.
+func (t *Transport) scheme(resp *http.Response) string {
+	offered := make(map[string]bool)
+	for _, challenge := range resp.Header.Values("WWW-Authenticate") {
+		name, _, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+		offered[strings.ToLower(name)] = true
+	}
+
+	switch t.Package {
+	case NTLM:
+		if offered["ntlm"] {
+			return "NTLM"
+		}
+		if offered["negotiate"] {
+			return "Negotiate"
+		}
+	default:
+		if offered["negotiate"] {
+			return "Negotiate"
+		}
+	}
+	return ""
+}
+
+// challengeToken returns the token included in a challenge for the given
+// scheme, or nil if one was not provided.
+func challengeToken(resp *http.Response, scheme string) []byte {
+	for _, challenge := range resp.Header.Values("WWW-Authenticate") {
+		name, value, found := strings.Cut(strings.TrimSpace(challenge), " ")
+		if !found || !strings.EqualFold(name, scheme) {
+			continue
+		}
+		token, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil || len(token) == 0 {
+			return nil
+		}
+		return token
+	}
+	return nil
+}
+
+// discard drains and closes the body of the response.
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+}
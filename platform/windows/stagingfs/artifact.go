@@ -0,0 +1,155 @@
+package stagingfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/tempfs"
+	"golang.org/x/sys/windows"
+)
+
+// ArtifactKind identifies the kind of an artifact left on the local system
+// by a deployment.
+type ArtifactKind string
+
+// Kinds of artifacts.
+const (
+	ArtifactStaging   ArtifactKind = "staging"
+	ArtifactBackup    ArtifactKind = "backup"
+	ArtifactState     ArtifactKind = "state"
+	ArtifactUserState ArtifactKind = "user-state"
+	ArtifactTemp      ArtifactKind = "temp"
+)
+
+// Artifact is a file or directory left on the local system by a
+// deployment. Backups and temporary directories belong to a particular run
+// of the deployment.
+type Artifact struct {
+	Deployment lbdeploy.DeploymentID
+	Kind       ArtifactKind
+	Run        lbevent.RunID
+	Path       string
+	Size       int64
+	Files      int
+	Modified   time.Time
+}
+
+// Artifacts returns the artifacts that deployments have left on the local
+// system, sorted by deployment, kind and run. If deployment is not empty,
+// only the artifacts of that deployment are returned.
+//
+// Staging directories, backups and machine state are kept in ProgramData,
+// and user state in the LocalAppData directory of the current user.
+// Temporary directories are kept in the temporary directory of the
+// account that ran the deployment, so only those of the current account
+// are found.
+func Artifacts(deployment lbdeploy.DeploymentID) ([]Artifact, error) {
+	programData, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return nil, err
+	}
+	localAppData, err := windows.KnownFolderPath(windows.FOLDERID_LocalAppData, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []struct {
+		Kind ArtifactKind
+		Path string
+		Runs bool
+		File bool
+	}{
+		{Kind: ArtifactStaging, Path: filepath.Join(programData, RootDir, StagingDir)},
+		{Kind: ArtifactBackup, Path: filepath.Join(programData, RootDir, BackupDir), Runs: true},
+		{Kind: ArtifactState, Path: filepath.Join(programData, RootDir, StateDir), File: true},
+		{Kind: ArtifactUserState, Path: filepath.Join(localAppData, RootDir, StateDir), File: true},
+		{Kind: ArtifactTemp, Path: tempfs.Root(), Runs: true},
+	}
+
+	var artifacts []Artifact
+	for _, source := range sources {
+		entries, err := os.ReadDir(source.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if source.File {
+				if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+					continue
+				}
+				name = strings.TrimSuffix(name, ".json")
+			} else if !entry.IsDir() {
+				continue
+			}
+			id := lbdeploy.DeploymentID(name)
+			if deployment != "" && id != deployment {
+				continue
+			}
+
+			path := filepath.Join(source.Path, entry.Name())
+			if !source.Runs {
+				artifacts = append(artifacts, measure(Artifact{Deployment: id, Kind: source.Kind, Path: path}))
+				continue
+			}
+
+			runs, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, run := range runs {
+				if !run.IsDir() {
+					continue
+				}
+				artifacts = append(artifacts, measure(Artifact{
+					Deployment: id,
+					Kind:       source.Kind,
+					Run:        lbevent.RunID(run.Name()),
+					Path:       filepath.Join(path, run.Name()),
+				}))
+			}
+		}
+	}
+
+	slices.SortStableFunc(artifacts, func(a, b Artifact) int {
+		if c := strings.Compare(string(a.Deployment), string(b.Deployment)); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Kind), string(b.Kind))
+	})
+
+	return artifacts, nil
+}
+
+// measure fills in the size, file count and modification time of an
+// artifact. Files that can't be read are skipped.
+func measure(artifact Artifact) Artifact {
+	filepath.WalkDir(artifact.Path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if fi.ModTime().After(artifact.Modified) {
+			artifact.Modified = fi.ModTime()
+		}
+		if !entry.IsDir() {
+			artifact.Size += fi.Size()
+			artifact.Files++
+		}
+		return nil
+	})
+	return artifact
+}
@@ -0,0 +1,204 @@
+package stagingfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+	"golang.org/x/sys/windows"
+)
+
+// BackupDir is the name of the directory that holds file backups.
+const BackupDir = "Backup"
+
+// BackupRecord describes a file that has been backed up.
+//
+// If the file did not exist when it was backed up, Existed is false and
+// restoring the backup removes the file.
+type BackupRecord struct {
+	Path     string    `json:"path"`
+	Existed  bool      `json:"existed"`
+	Size     int64     `json:"size,omitempty"`
+	Modified time.Time `json:"modified,omitzero"`
+	Created  time.Time `json:"created"`
+}
+
+// BackupSet is a directory that holds the file backups made during a
+// single run of a deployment.
+type BackupSet struct {
+	path string
+	dir  *os.Root
+	lock localfs.PathLock
+}
+
+// OpenBackupSet opens the backup directory for the given deployment and
+// run. If the directory does not already exist, it is created.
+//
+// The directory is located at ProgramData/LeafBridge/Backup/{DeploymentID}/{RunID}.
+//
+// It is the caller's responsibility to close the directory when finished
+// with it.
+func OpenBackupSet(deployment lbdeploy.DeploymentID, run lbevent.RunID) (BackupSet, error) {
+	if !isLocalName(string(deployment)) || !isLocalName(string(run)) {
+		return BackupSet{}, fmt.Errorf("unable to prepare a backup directory for deployment \"%s\" and run \"%s\"", deployment, run)
+	}
+
+	// Look up the system's ProgramData directory path.
+	programDataPath, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return BackupSet{}, err
+	}
+
+	// Open the ProgramData directory.
+	current, err := os.OpenRoot(programDataPath)
+	if err != nil {
+		return BackupSet{}, err
+	}
+
+	// Create each directory in turn.
+	names := []string{RootDir, BackupDir, string(deployment), string(run)}
+	for _, name := range names {
+		next, err := openOrCreateRootInRoot(current, name, 0755)
+		current.Close()
+		if err != nil {
+			return BackupSet{}, err
+		}
+		current = next
+	}
+	current.Close()
+
+	// Open the backup directory.
+	lock, dir, err := openLockedDir(programDataPath, names...)
+	if err != nil {
+		return BackupSet{}, err
+	}
+
+	return BackupSet{
+		path: filepath.Join(programDataPath, RootDir, BackupDir, string(deployment), string(run)),
+		dir:  dir,
+		lock: lock,
+	}, nil
+}
+
+// Path returns the absolute path of the backup directory.
+func (s BackupSet) Path() string {
+	return s.path
+}
+
+// FilePath returns the absolute path of the backup data for a file.
+func (s BackupSet) FilePath(file lbdeploy.FileResourceID) (string, error) {
+	name, err := backupName(file, ".bak")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.path, name), nil
+}
+
+// Create creates or truncates the backup data file for a file.
+func (s BackupSet) Create(file lbdeploy.FileResourceID) (*os.File, error) {
+	name, err := backupName(file, ".bak")
+	if err != nil {
+		return nil, err
+	}
+	return s.dir.Create(name)
+}
+
+// Open opens the backup data file for a file.
+func (s BackupSet) Open(file lbdeploy.FileResourceID) (*os.File, error) {
+	name, err := backupName(file, ".bak")
+	if err != nil {
+		return nil, err
+	}
+	return s.dir.Open(name)
+}
+
+// ReadRecord returns the backup record for a file. If the file has not
+// been backed up, it returns an error that wraps [fs.ErrNotExist].
+func (s BackupSet) ReadRecord(file lbdeploy.FileResourceID) (BackupRecord, error) {
+	name, err := backupName(file, ".json")
+	if err != nil {
+		return BackupRecord{}, err
+	}
+
+	f, err := s.dir.Open(name)
+	if err != nil {
+		return BackupRecord{}, err
+	}
+	defer f.Close()
+
+	var record BackupRecord
+	if err := json.NewDecoder(f).Decode(&record); err != nil {
+		return BackupRecord{}, fmt.Errorf("failed to parse the backup record: %w", err)
+	}
+	return record, nil
+}
+
+// WriteRecord writes the backup record for a file. It should be written
+// after the backup data, so that the presence of a record indicates a
+// complete backup.
+func (s BackupSet) WriteRecord(file lbdeploy.FileResourceID, record BackupRecord) error {
+	name, err := backupName(file, ".json")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(record, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	f, err := s.dir.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// HasRecord returns true if a backup record exists for a file.
+func (s BackupSet) HasRecord(file lbdeploy.FileResourceID) (bool, error) {
+	name, err := backupName(file, ".json")
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.dir.Stat(name); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Close releases any file handles or resources held by the backup
+// directory.
+func (s BackupSet) Close() error {
+	err := s.dir.Close()
+	s.lock.Release()
+	return err
+}
+
+// backupName returns the name of a backup file for the given file resource
+// and extension.
+func backupName(file lbdeploy.FileResourceID, ext string) (string, error) {
+	if !isLocalName(string(file)) {
+		return "", fmt.Errorf("the file resource ID \"%s\" cannot be used as a backup file name", file)
+	}
+	return string(file) + ext, nil
+}
+
+// isLocalName returns true if name can be used as a single file or
+// directory name.
+func isLocalName(name string) bool {
+	return name != "" && filepath.IsLocal(name) && filepath.Base(name) == name
+}
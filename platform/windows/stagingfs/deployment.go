@@ -1,10 +1,14 @@
 package stagingfs
 
 import (
+	"errors"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
 	"golang.org/x/sys/windows"
 )
 
@@ -12,13 +16,16 @@ import (
 const (
 	RootDir    = "LeafBridge"
 	StagingDir = "Deploy"
+	StateDir   = "State"
 )
 
 // DeploymentDir is a staging directory for a deployment in LeafBridge.
 type DeploymentDir struct {
 	deployment lbdeploy.DeploymentID
-	path       string
+	base       string
+	names      []string
 	dir        *os.Root
+	lock       localfs.PathLock
 }
 
 // OpenDeployment opens the staging directory for a deployment in LeafBridge.
@@ -54,16 +61,24 @@ func OpenDeployment(id lbdeploy.DeploymentID) (DeploymentDir, error) {
 	}
 	defer staging.Close()
 
+	// Create the ProgramData/LeafBridge/Deploy/{DeploymentID} directory.
+	if err := staging.Mkdir(string(id), 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return DeploymentDir{}, err
+	}
+
 	// Open the ProgramData/LeafBridge/Deploy/{DeploymentID} directory.
-	dir, err := openOrCreateRootInRoot(staging, string(id), 0755)
+	names := []string{RootDir, StagingDir, string(id)}
+	lock, dir, err := openLockedDir(programDataPath, names...)
 	if err != nil {
 		return DeploymentDir{}, err
 	}
 
 	return DeploymentDir{
 		deployment: id,
-		path:       filepath.Join(programDataPath, RootDir, StagingDir, string(id)),
+		base:       programDataPath,
+		names:      names,
 		dir:        dir,
+		lock:       lock,
 	}, nil
 }
 
@@ -73,21 +88,47 @@ func OpenDeployment(id lbdeploy.DeploymentID) (DeploymentDir, error) {
 // It is the caller's responsibility to close the directory when finished
 // with it.
 func (r DeploymentDir) OpenPackage(content lbdeploy.PackageContent) (PackageDir, error) {
-	dir, err := openOrCreateRootInRoot(r.dir, content.String(), 0755)
+	if err := r.dir.Mkdir(content.String(), 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return PackageDir{}, err
+	}
+	names := append(r.names[:len(r.names):len(r.names)], content.String())
+	lock, dir, err := openLockedDir(r.base, names...)
 	if err != nil {
 		return PackageDir{}, err
 	}
 	return PackageDir{
 		content: content,
-		path:    filepath.Join(r.path, content.String()),
+		base:    r.base,
+		names:   names,
+		path:    filepath.Join(append([]string{r.base}, names...)...),
 		dir:     dir,
+		lock:    lock,
 	}, nil
 }
 
 // Close releases any file handles or resources held by the deployment
 // staging directory.
 func (r DeploymentDir) Close() error {
-	return r.dir.Close()
+	err := r.dir.Close()
+	r.lock.Release()
+	return err
+}
+
+// openLockedDir opens the directory at the given names beneath base. The
+// directory, and each directory between it and base, is checked for
+// reparse points and remains locked until the returned lock is released,
+// so that the directory can't be redirected elsewhere while it is in use.
+func openLockedDir(base string, names ...string) (localfs.PathLock, *os.Root, error) {
+	lock, err := localfs.LockDir(base, path.Join(names...))
+	if err != nil {
+		return localfs.PathLock{}, nil, err
+	}
+	dir, err := os.OpenRoot(filepath.Join(append([]string{base}, names...)...))
+	if err != nil {
+		lock.Release()
+		return localfs.PathLock{}, nil, err
+	}
+	return lock, dir, nil
 }
 
 func openOrCreateRootInRoot(parent *os.Root, name string, perm os.FileMode) (*os.Root, error) {
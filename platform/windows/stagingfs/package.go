@@ -1,18 +1,27 @@
 package stagingfs
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/internal/httpcache"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
 )
 
 // PackageDir is a staging directory for a package in LeafBridge.
 type PackageDir struct {
 	content lbdeploy.PackageContent
+	base    string
+	names   []string
 	path    string
 	dir     *os.Root
+	lock    localfs.PathLock
 }
 
 // Stat returns a [os.FileInfo] describing the package file.
@@ -52,8 +61,16 @@ func (d PackageDir) OpenFile(pkg lbdeploy.Package) (PackageFile, error) {
 		return PackageFile{}, fmt.Errorf("localization of the package file name failed: %w", err)
 	}
 
+	// Lock the directory separately for the file, so that it remains
+	// locked while the file is open.
+	lock, err := localfs.LockDir(d.base, path.Join(d.names...))
+	if err != nil {
+		return PackageFile{}, err
+	}
+
 	f, err := d.dir.OpenFile(localized, os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
+		lock.Release()
 		return PackageFile{}, err
 	}
 	return PackageFile{
@@ -62,13 +79,16 @@ func (d PackageDir) OpenFile(pkg lbdeploy.Package) (PackageFile, error) {
 		Format: pkg.Format,
 		Path:   filepath.Join(d.path, localized),
 		File:   f,
+		lock:   lock,
 	}, nil
 }
 
 // Close releases any file handles or resources held by the package
 // staging directory.
 func (d PackageDir) Close() error {
-	return d.dir.Close()
+	err := d.dir.Close()
+	d.lock.Release()
+	return err
 }
 
 // PackageFile is an open file for a package.
@@ -78,4 +98,66 @@ type PackageFile struct {
 	Format lbdeploy.PackageFormat
 	Path   string
 	*os.File
+
+	lock localfs.PathLock
+}
+
+// Close closes the package file and releases its staging directory.
+func (f PackageFile) Close() error {
+	err := f.File.Close()
+	f.lock.Release()
+	return err
+}
+
+// CacheValidators hold the HTTP cache validators that were returned by the
+// source of a package file when it was downloaded.
+type CacheValidators = httpcache.Validators
+
+// validatorsPath returns the path of the file that holds the cache
+// validators for the package file.
+func (f PackageFile) validatorsPath() string {
+	return f.Path + ".validators.json"
+}
+
+// ReadValidators returns the cache validators that were recorded for the
+// package file. If no validators have been recorded, it returns an empty
+// set of validators.
+func (f PackageFile) ReadValidators() (CacheValidators, error) {
+	data, err := os.ReadFile(f.validatorsPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return CacheValidators{}, nil
+		}
+		return CacheValidators{}, err
+	}
+
+	var v CacheValidators
+	if err := json.Unmarshal(data, &v); err != nil {
+		return CacheValidators{}, fmt.Errorf("failed to parse cache validators: %w", err)
+	}
+	return v, nil
+}
+
+// WriteValidators records cache validators for the package file. If the
+// validators are empty, any previously recorded validators are removed.
+func (f PackageFile) WriteValidators(v CacheValidators) error {
+	if v.IsZero() {
+		return f.RemoveValidators()
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.validatorsPath(), data, 0644)
+}
+
+// RemoveValidators removes any cache validators that were recorded for the
+// package file.
+func (f PackageFile) RemoveValidators() error {
+	err := os.Remove(f.validatorsPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
 }
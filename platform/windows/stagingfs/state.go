@@ -0,0 +1,150 @@
+package stagingfs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// stateDirSecurity is the security descriptor applied to the machine state
+// directory. It makes the local Administrators group the owner and grants
+// full control to SYSTEM and Administrators only. The access control list
+// is protected from inheritance, and is inherited by the files within the
+// directory.
+const stateDirSecurity = "O:BAD:P(A;OICI;FA;;;SY)(A;OICI;FA;;;BA)"
+
+// SecureStateDir creates the machine state directory if it does not
+// already exist, and restricts access to it to SYSTEM and the local
+// Administrators group. It returns the path of the directory.
+//
+// The directory is located at ProgramData/LeafBridge/State.
+//
+// The access control list of the directory can only be changed by an
+// elevated process.
+func SecureStateDir() (string, error) {
+	// Look up the system's ProgramData directory path.
+	programDataPath, err := windows.KnownFolderPath(windows.FOLDERID_ProgramData, 0)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(programDataPath, RootDir, StateDir)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", err
+	}
+
+	// Refuse to secure a directory that is really a link to another
+	// location.
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("the state directory \"%s\" is not a directory", path)
+	}
+
+	sd, err := windows.SecurityDescriptorFromString(stateDirSecurity)
+	if err != nil {
+		return "", err
+	}
+	owner, _, err := sd.Owner()
+	if err != nil {
+		return "", err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return "", err
+	}
+
+	const security = windows.OWNER_SECURITY_INFORMATION | windows.DACL_SECURITY_INFORMATION | windows.PROTECTED_DACL_SECURITY_INFORMATION
+	if err := windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, security, owner, nil, dacl, nil); err != nil {
+		return "", fmt.Errorf("unable to restrict access to the state directory: %w", err)
+	}
+
+	return path, nil
+}
+
+// StateDirSecured returns true if the directory at path has been secured
+// in the way that SecureStateDir secures the machine state directory. The
+// directory must not be a reparse point, it must be owned by SYSTEM or the
+// local Administrators group, and its protected access control list must
+// only grant access to them.
+//
+// Unlike SecureStateDir, it does not modify the directory.
+func StateDirSecured(path string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	if info.Mode().Type() != fs.ModeDir {
+		return false, nil
+	}
+
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION|windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return false, err
+	}
+	owner, _, err := sd.Owner()
+	if err != nil {
+		return false, err
+	}
+	if !isAdministrator(owner) {
+		return false, nil
+	}
+
+	control, _, err := sd.Control()
+	if err != nil {
+		return false, err
+	}
+	if control&windows.SE_DACL_PROTECTED == 0 {
+		return false, nil
+	}
+
+	// A missing access control list grants access to everyone.
+	dacl, _, err := sd.DACL()
+	if err != nil || dacl == nil {
+		return false, err
+	}
+	for i := range uint32(dacl.AceCount) {
+		var ace *windows.ACCESS_ALLOWED_ACE
+		if err := windows.GetAce(dacl, i, &ace); err != nil {
+			return false, err
+		}
+		switch ace.Header.AceType {
+		case windows.ACCESS_DENIED_ACE_TYPE:
+		case windows.ACCESS_ALLOWED_ACE_TYPE:
+			sid := (*windows.SID)(unsafe.Pointer(&ace.SidStart))
+			if !isAdministrator(sid) {
+				return false, nil
+			}
+		default:
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// OwnedByAdministrators returns true if the file at path is owned by the
+// SYSTEM account or the local Administrators group.
+func OwnedByAdministrators(path string) (bool, error) {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION)
+	if err != nil {
+		return false, err
+	}
+	owner, _, err := sd.Owner()
+	if err != nil {
+		return false, err
+	}
+	return isAdministrator(owner), nil
+}
+
+// isAdministrator returns true if sid identifies the SYSTEM account or the
+// local Administrators group.
+func isAdministrator(sid *windows.SID) bool {
+	return sid.IsWellKnown(windows.WinLocalSystemSid) || sid.IsWellKnown(windows.WinBuiltinAdministratorsSid)
+}
@@ -0,0 +1,96 @@
+package stagingfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// VerificationRecord holds the result of a successful verification of a
+// package file. It is recorded alongside the file so that a later run can
+// trust the staged copy without hashing its content again.
+//
+// Size and Modified describe the file as it was when it was verified. The
+// record is only trusted while the file still has the same size and
+// modification time.
+type VerificationRecord struct {
+	Attributes lbdeploy.FileAttributes `json:"attributes"`
+	Modified   time.Time               `json:"modified"`
+	Verified   time.Time               `json:"verified"`
+	Source     string                  `json:"source,omitempty"`
+}
+
+// Matches returns true if the record describes the file with the given
+// information, and if its attributes match the expected attributes.
+func (r VerificationRecord) Matches(fi fs.FileInfo, expected lbdeploy.FileAttributes) bool {
+	if r.Verified.IsZero() || len(r.Attributes.Hashes) == 0 {
+		return false
+	}
+	if fi.Size() != r.Attributes.Size || !fi.ModTime().Equal(r.Modified) {
+		return false
+	}
+	if len(expected.Hashes) == 0 {
+		return false
+	}
+	return lbdeploy.MatchFileAttributes(expected, r.Attributes)
+}
+
+// verificationPath returns the path of the file that holds the
+// verification record for the package file.
+func (f PackageFile) verificationPath() string {
+	return f.Path + ".verified.json"
+}
+
+// ReadVerification returns the verification record for the package file.
+// If no record has been written, it returns an empty record.
+func (f PackageFile) ReadVerification() (VerificationRecord, error) {
+	data, err := os.ReadFile(f.verificationPath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return VerificationRecord{}, nil
+		}
+		return VerificationRecord{}, err
+	}
+
+	var r VerificationRecord
+	if err := json.Unmarshal(data, &r); err != nil {
+		return VerificationRecord{}, fmt.Errorf("failed to parse verification record: %w", err)
+	}
+	return r, nil
+}
+
+// WriteVerification records the attributes of the package file after it
+// has been verified. The size and modification time of the file are
+// taken from the file itself.
+func (f PackageFile) WriteVerification(attrs lbdeploy.FileAttributes, source string, verified time.Time) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(VerificationRecord{
+		Attributes: attrs,
+		Modified:   fi.ModTime(),
+		Verified:   verified,
+		Source:     source,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.verificationPath(), data, 0644)
+}
+
+// RemoveVerification removes any verification record that was written for
+// the package file.
+func (f PackageFile) RemoveVerification() error {
+	err := os.Remove(f.verificationPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/leafbridge/leafbridge/core/lbdeploy"
 	"github.com/leafbridge/leafbridge/platform/windows/filetime"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
 )
 
 // Options hold a set of options for extraction directories.
@@ -18,16 +20,22 @@ type Options struct {
 	// DeleteOnClose requests that temporary directories and their contents
 	// are deleted when the directory is closed.
 	DeleteOnClose bool
+
+	// Scope identifies the deployment and run that the directory belongs
+	// to.
+	Scope Scope
 }
 
 // ExtractionDir is an extraction directory for a package in LeafBridge.
 //
-// It is a temporary directory created via os.MkdirTemp. Its name will have
-// "leafbridge-" as a prefix.
+// It is a temporary directory created via os.MkdirTemp within the directory
+// of its scope. Its name will have "leafbridge-" as a prefix.
 type ExtractionDir struct {
-	path string
-	dir  *os.Root
-	opts Options
+	path      string
+	dir       *os.Root
+	opts      Options
+	scopeLock localfs.PathLock
+	dirLock   localfs.PathLock
 }
 
 // OpenExtractionDirForPackage opens a temporary directory to receive
@@ -41,8 +49,14 @@ type ExtractionDir struct {
 //
 // TODO: Make the options variadic.
 func OpenExtractionDirForPackage(pkg lbdeploy.PackageContent, opts Options) (ExtractionDir, error) {
+	// Prepare the directory of the scope.
+	parent, err := opts.Scope.create()
+	if err != nil {
+		return ExtractionDir{}, err
+	}
+
 	// Unfortunately, this returns a path instead of an open directory handle.
-	dirPath, err := os.MkdirTemp("", "leafbridge-"+pkg.String())
+	dirPath, err := os.MkdirTemp(parent, "leafbridge-"+pkg.String())
 	if err != nil {
 		return ExtractionDir{}, err
 	}
@@ -60,20 +74,80 @@ func OpenExtractionDirForPackage(pkg lbdeploy.PackageContent, opts Options) (Ext
 		}
 	}
 
+	// Make sure that neither the directory nor the directories of its
+	// scope are reparse points, and keep them from being redirected while
+	// the directory is in use.
+	scopeLock, dirLock, err := opts.Scope.lock(filepath.Base(dirPath))
+	if err != nil {
+		return ExtractionDir{}, fmt.Errorf("the extraction directory could not be secured: %w", err)
+	}
+
 	// Open the root of the newly created temp directory.
 	dir, err := os.OpenRoot(dirPath)
 	if err != nil {
+		dirLock.Release()
+		scopeLock.Release()
 		return ExtractionDir{}, err
 	}
 
 	// Return the extraction directory.
 	return ExtractionDir{
-		path: dirPath,
-		dir:  dir,
-		opts: opts,
+		path:      dirPath,
+		dir:       dir,
+		opts:      opts,
+		scopeLock: scopeLock,
+		dirLock:   dirLock,
 	}, nil
 }
 
+// ReopenExtractionDir opens an extraction directory with the given name
+// that was created for a package by an earlier run, so that the files it
+// holds can be used again. The scope of the options must identify the run
+// that created it.
+//
+// The name must be that of an extraction directory for the same package
+// content, so a directory is never reused after the package has changed.
+//
+// It is the caller's responsibility to close the returned directory when
+// finished with it.
+func ReopenExtractionDir(pkg lbdeploy.PackageContent, name string, opts Options) (ExtractionDir, error) {
+	if opts.Scope.IsZero() || !isLocalName(string(opts.Scope.Deployment)) || !isLocalName(string(opts.Scope.Run)) {
+		return ExtractionDir{}, fmt.Errorf("unable to reopen an extraction directory for deployment \"%s\" and run \"%s\"", opts.Scope.Deployment, opts.Scope.Run)
+	}
+	if !isLocalName(name) || !strings.HasPrefix(name, "leafbridge-"+pkg.String()) {
+		return ExtractionDir{}, fmt.Errorf("\"%s\" is not an extraction directory for package \"%s\"", name, pkg.ID)
+	}
+
+	// Make sure that neither the directory nor the directories of its
+	// scope are reparse points, and keep them from being redirected while
+	// the directory is in use.
+	scopeLock, dirLock, err := opts.Scope.lock(name)
+	if err != nil {
+		return ExtractionDir{}, fmt.Errorf("the extraction directory could not be secured: %w", err)
+	}
+
+	dirPath := filepath.Join(opts.Scope.Path(), name)
+	dir, err := os.OpenRoot(dirPath)
+	if err != nil {
+		dirLock.Release()
+		scopeLock.Release()
+		return ExtractionDir{}, err
+	}
+
+	return ExtractionDir{
+		path:      dirPath,
+		dir:       dir,
+		opts:      opts,
+		scopeLock: scopeLock,
+		dirLock:   dirLock,
+	}, nil
+}
+
+// Scope returns the scope of the extraction directory.
+func (d ExtractionDir) Scope() Scope {
+	return d.opts.Scope
+}
+
 // Path returns the path to the extraction directory at the time of its
 // creation.
 func (d ExtractionDir) Path() string {
@@ -97,13 +171,16 @@ func (d ExtractionDir) MkdirAll(path string) error {
 		return fmt.Errorf("localization of the directory path failed: %w", err)
 	}
 
-	// Join the relative path to the absolute path of the extraciton
-	// directory.
-	dirPath := filepath.Join(d.path, localized)
-
-	// Create the directory and any of it ancestors that don't already exist.
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		return fmt.Errorf("failed to create directory path: %w", err)
+	// Create the directory and any of its ancestors that don't already
+	// exist. Each directory is created through the root, which prevents
+	// symbolic links and other reparse points from redirecting the
+	// creation to a location outside of the extraction directory.
+	var current string
+	for segment := range strings.SplitSeq(localized, string(filepath.Separator)) {
+		current = filepath.Join(current, segment)
+		if err := d.dir.Mkdir(current, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("failed to create directory path: %w", err)
+		}
 	}
 
 	// TODO: Use d.dir.MkdirAll() when Go 1.25 is released, which should
@@ -198,12 +275,20 @@ func (d ExtractionDir) WriteFile(path string, r io.Reader, modified time.Time) (
 func (d ExtractionDir) Close() error {
 	// Simple closure.
 	if !d.opts.DeleteOnClose {
-		return d.dir.Close()
+		err := d.dir.Close()
+		d.dirLock.Release()
+		d.scopeLock.Release()
+		return err
 	}
 
-	// Close and delete.
+	// Close and delete. The directories of the scope remain locked until
+	// the directory has been removed, so that the removal can't be
+	// redirected elsewhere.
 	err1 := d.dir.Close()
+	d.dirLock.Release()
 	err2 := os.RemoveAll(d.path)
+	d.scopeLock.Release()
+	d.opts.Scope.cleanup()
 
 	// TODO: Use d.dir.RemoveAll() when Go 1.25 is released, which should
 	// include it.
@@ -0,0 +1,105 @@
+package tempfs
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbevent"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+)
+
+// RootDir is the name of the directory within the system's temporary
+// directory that holds the temporary directories of LeafBridge.
+const RootDir = "LeafBridge"
+
+// Root returns the path of the directory that holds the temporary
+// directories of all deployments.
+func Root() string {
+	return filepath.Join(os.TempDir(), RootDir)
+}
+
+// Scope identifies the deployment and run that a temporary directory
+// belongs to.
+//
+// Temporary directories with a scope are created within
+// {TEMP}\LeafBridge\{DeploymentID}\{RunID}, so that concurrent runs never
+// share a directory, and the directories of each deployment can be found
+// and cleaned up. Temporary directories without a scope are created
+// directly within the system's temporary directory.
+type Scope struct {
+	Deployment lbdeploy.DeploymentID
+	Run        lbevent.RunID
+}
+
+// IsZero returns true if the scope is empty.
+func (s Scope) IsZero() bool {
+	return s.Deployment == "" && s.Run == ""
+}
+
+// Path returns the path of the directory for the scope. It returns an
+// empty string if the scope is empty.
+func (s Scope) Path() string {
+	if s.IsZero() {
+		return ""
+	}
+	return filepath.Join(Root(), string(s.Deployment), string(s.Run))
+}
+
+// create creates the directory for the scope, if it doesn't already exist,
+// and returns its path.
+func (s Scope) create() (string, error) {
+	if s.IsZero() {
+		return "", nil
+	}
+	if !isLocalName(string(s.Deployment)) || !isLocalName(string(s.Run)) {
+		return "", fmt.Errorf("unable to prepare a temporary directory for deployment \"%s\" and run \"%s\"", s.Deployment, s.Run)
+	}
+	path := s.Path()
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// lock checks the directories of the scope, and the directory with the
+// given name within it, for reparse points. The directories of the scope
+// are held open until the first lock is released, and the named directory
+// is held open until the second lock is released.
+//
+// This keeps a temporary directory from being redirected elsewhere while it
+// is in use, including when it is removed.
+func (s Scope) lock(name string) (scope, dir localfs.PathLock, err error) {
+	var relative string
+	if !s.IsZero() {
+		relative = path.Join(RootDir, string(s.Deployment), string(s.Run))
+	}
+	scope, err = localfs.LockDir(os.TempDir(), relative)
+	if err != nil {
+		return localfs.PathLock{}, localfs.PathLock{}, err
+	}
+	dir, err = localfs.LockDir(filepath.Join(os.TempDir(), filepath.FromSlash(relative)), name)
+	if err != nil {
+		scope.Release()
+		return localfs.PathLock{}, localfs.PathLock{}, err
+	}
+	return scope, dir, nil
+}
+
+// cleanup removes the directories of the scope and its deployment, if they
+// are empty.
+func (s Scope) cleanup() {
+	if s.IsZero() {
+		return
+	}
+	if os.Remove(s.Path()) == nil {
+		os.Remove(filepath.Dir(s.Path()))
+	}
+}
+
+// isLocalName returns true if name is a single, local path element.
+func isLocalName(name string) bool {
+	return name != "" && filepath.IsLocal(name) && filepath.Base(name) == name
+}
@@ -0,0 +1,116 @@
+package tempfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+)
+
+// ScriptDir is a temporary directory that holds scripts written by
+// LeafBridge.
+//
+// It is a temporary directory created via os.MkdirTemp within the directory
+// of its scope. Its name will have "leafbridge-script-" as a prefix. The
+// directory and its contents are deleted when it is closed.
+type ScriptDir struct {
+	path      string
+	dir       *os.Root
+	scope     Scope
+	scopeLock localfs.PathLock
+	dirLock   localfs.PathLock
+}
+
+// OpenScriptDir creates a temporary directory to hold scripts within the
+// directory of the given scope.
+//
+// It is the caller's responsibility to close the returned directory when
+// finished with it.
+func OpenScriptDir(scope Scope) (ScriptDir, error) {
+	parent, err := scope.create()
+	if err != nil {
+		return ScriptDir{}, err
+	}
+
+	dirPath, err := os.MkdirTemp(parent, "leafbridge-script-")
+	if err != nil {
+		return ScriptDir{}, err
+	}
+
+	// Sanity check the directory path to make sure it conforms to our
+	// expectations, because it will be removed when it is closed.
+	{
+		dirPath := strings.ToLower(dirPath) // Case-insensitive search
+		if !strings.Contains(dirPath, "leafbridge") || !strings.Contains(dirPath, "temp") {
+			return ScriptDir{}, fmt.Errorf("the os.MkdirTemp call failed to create a directory with the expected format: %s", dirPath)
+		}
+	}
+
+	// Make sure that neither the directory nor the directories of its
+	// scope are reparse points, and keep them from being redirected while
+	// the directory is in use.
+	scopeLock, dirLock, err := scope.lock(filepath.Base(dirPath))
+	if err != nil {
+		return ScriptDir{}, fmt.Errorf("the script directory could not be secured: %w", err)
+	}
+
+	// Open the root of the newly created temp directory.
+	dir, err := os.OpenRoot(dirPath)
+	if err != nil {
+		dirLock.Release()
+		scopeLock.Release()
+		return ScriptDir{}, err
+	}
+
+	return ScriptDir{
+		path:      dirPath,
+		dir:       dir,
+		scope:     scope,
+		scopeLock: scopeLock,
+		dirLock:   dirLock,
+	}, nil
+}
+
+// Path returns the path to the script directory.
+func (d ScriptDir) Path() string {
+	return d.path
+}
+
+// WriteFile writes data to a new file with the given name in the script
+// directory and returns its absolute path.
+//
+// The file must not already exist.
+func (d ScriptDir) WriteFile(name string, data []byte) (path string, err error) {
+	if !filepath.IsLocal(name) || filepath.Base(name) != name {
+		return "", fmt.Errorf("the script file name is not valid: %s", name)
+	}
+
+	file, err := d.dir.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create script file: %w", err)
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write script file: %w", err)
+	}
+
+	return filepath.Join(d.path, name), nil
+}
+
+// Close closes the script directory and deletes it along with all of its
+// contents.
+func (d ScriptDir) Close() error {
+	err1 := d.dir.Close()
+	d.dirLock.Release()
+	err2 := os.RemoveAll(d.path)
+	d.scopeLock.Release()
+	d.scope.cleanup()
+	return errors.Join(err1, err2)
+}
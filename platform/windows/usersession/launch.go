@@ -0,0 +1,120 @@
+// Package usersession starts programs in the session of the user that is
+// signed in to the console of a Windows machine.
+//
+// It allows a process running as SYSTEM, such as a deployment invoked by
+// a management agent, to present a user interface to the signed-in user.
+package usersession
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// ErrNoSession is returned when no user is signed in to the console.
+var ErrNoSession = errors.New("no user is signed in to the console")
+
+// noSession is the session ID returned by WTSGetActiveConsoleSessionId
+// when no session is attached to the console.
+const noSession = 0xFFFFFFFF
+
+// interactiveDesktop is the desktop on which programs are started.
+const interactiveDesktop = `winsta0\default`
+
+// Process describes a program that has been started.
+type Process struct {
+	ID      uint32
+	Session uint32
+
+	// AsCaller is true if the program was started as the calling user,
+	// because the caller was not permitted to act on behalf of the
+	// signed-in user.
+	AsCaller bool
+}
+
+// Launch starts the program at path with the given arguments in the
+// session of the user signed in to the console, as that user. It does
+// not wait for the program to exit.
+//
+// The caller must be running as SYSTEM to start programs on behalf of
+// another user. If it lacks the privilege to do so, the program is
+// started as the caller, in the caller's session.
+//
+// If no user is signed in to the console, it returns ErrNoSession.
+func Launch(path string, args ...string) (Process, error) {
+	session := windows.WTSGetActiveConsoleSessionId()
+	if session == noSession {
+		return Process{}, ErrNoSession
+	}
+
+	var token windows.Token
+	if err := windows.WTSQueryUserToken(session, &token); err != nil {
+		switch {
+		case errors.Is(err, windows.ERROR_NO_TOKEN):
+			return Process{}, ErrNoSession
+		case errors.Is(err, windows.ERROR_PRIVILEGE_NOT_HELD):
+			return launchAsCaller(path, args)
+		default:
+			return Process{}, fmt.Errorf("failed to retrieve the token of the signed-in user: %w", err)
+		}
+	}
+	defer token.Close()
+
+	// Prepare the environment of the signed-in user.
+	var env *uint16
+	if err := windows.CreateEnvironmentBlock(&env, token, false); err != nil {
+		return Process{}, fmt.Errorf("failed to prepare the environment of the signed-in user: %w", err)
+	}
+	defer windows.DestroyEnvironmentBlock(env)
+
+	appName, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return Process{}, err
+	}
+	commandLine, err := windows.UTF16PtrFromString(windows.ComposeCommandLine(append([]string{path}, args...)))
+	if err != nil {
+		return Process{}, err
+	}
+	dir, err := windows.UTF16PtrFromString(filepath.Dir(path))
+	if err != nil {
+		return Process{}, err
+	}
+	desktop, err := windows.UTF16PtrFromString(interactiveDesktop)
+	if err != nil {
+		return Process{}, err
+	}
+
+	startup := windows.StartupInfo{Desktop: desktop}
+	startup.Cb = uint32(unsafe.Sizeof(startup))
+
+	var info windows.ProcessInformation
+	if err := windows.CreateProcessAsUser(token, appName, commandLine, nil, nil, false, windows.CREATE_UNICODE_ENVIRONMENT, env, dir, &startup, &info); err != nil {
+		return Process{}, fmt.Errorf("failed to start \"%s\" in session %d: %w", path, session, err)
+	}
+	windows.CloseHandle(info.Thread)
+	windows.CloseHandle(info.Process)
+
+	return Process{ID: info.ProcessId, Session: session}, nil
+}
+
+// launchAsCaller starts the program at path as the calling user.
+func launchAsCaller(path string, args []string) (Process, error) {
+	attr := os.ProcAttr{
+		Dir:   filepath.Dir(path),
+		Files: []*os.File{nil, nil, nil},
+	}
+	p, err := os.StartProcess(path, append([]string{path}, args...), &attr)
+	if err != nil {
+		return Process{}, fmt.Errorf("failed to start \"%s\": %w", path, err)
+	}
+	defer p.Release()
+
+	var session uint32
+	windows.ProcessIdToSessionId(uint32(p.Pid), &session)
+
+	return Process{ID: uint32(p.Pid), Session: session, AsCaller: true}, nil
+}
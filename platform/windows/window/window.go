@@ -0,0 +1,166 @@
+// Package window inspects and manipulates the top-level windows of Windows
+// processes.
+package window
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	moduser32                    = windows.NewLazySystemDLL("user32.dll")
+	procEnumWindows              = moduser32.NewProc("EnumWindows")
+	procEnumChildWindows         = moduser32.NewProc("EnumChildWindows")
+	procGetWindowThreadProcessId = moduser32.NewProc("GetWindowThreadProcessId")
+	procIsWindowVisible          = moduser32.NewProc("IsWindowVisible")
+	procGetWindowTextW           = moduser32.NewProc("GetWindowTextW")
+	procGetClassNameW            = moduser32.NewProc("GetClassNameW")
+	procPostMessageW             = moduser32.NewProc("PostMessageW")
+)
+
+// Window messages.
+const (
+	wmClose = 0x0010
+	bmClick = 0x00F5
+)
+
+// maxTextLength is the maximum number of characters retrieved from window
+// titles and class names.
+const maxTextLength = 512
+
+// Callbacks can't be released once they are created, so a single callback
+// is shared by all enumerations. The mutex guards the visitor that it
+// calls.
+var (
+	enumMutex    sync.Mutex
+	enumVisitor  func(hwnd windows.HWND)
+	enumCallback = windows.NewCallback(func(hwnd windows.HWND, lparam uintptr) uintptr {
+		enumVisitor(hwnd)
+		return 1
+	})
+)
+
+// Window describes a top-level window.
+type Window struct {
+	Handle    windows.HWND
+	ProcessID uint32
+	Title     string
+	Class     string
+}
+
+// String returns a string representation of the window.
+func (w Window) String() string {
+	return fmt.Sprintf("\"%s\" (%s, pid %d)", w.Title, w.Class, w.ProcessID)
+}
+
+// ForProcesses returns the visible top-level windows that belong to the
+// given processes.
+func ForProcesses(pids []uint32) ([]Window, error) {
+	if err := procEnumWindows.Find(); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[uint32]bool, len(pids))
+	for _, pid := range pids {
+		wanted[pid] = true
+	}
+
+	var handles []windows.HWND
+	if err := enumerate(procEnumWindows, 0, func(hwnd windows.HWND) {
+		handles = append(handles, hwnd)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to enumerate windows: %w", err)
+	}
+
+	var list []Window
+	for _, hwnd := range handles {
+		if visible, _, _ := procIsWindowVisible.Call(uintptr(hwnd)); visible == 0 {
+			continue
+		}
+		var pid uint32
+		procGetWindowThreadProcessId.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&pid)))
+		if !wanted[pid] {
+			continue
+		}
+		list = append(list, Window{
+			Handle:    hwnd,
+			ProcessID: pid,
+			Title:     text(procGetWindowTextW, hwnd),
+			Class:     text(procGetClassNameW, hwnd),
+		})
+	}
+
+	return list, nil
+}
+
+// Close asks the window to close.
+func (w Window) Close() error {
+	if r1, _, err := procPostMessageW.Call(uintptr(w.Handle), wmClose, 0, 0); r1 == 0 {
+		return fmt.Errorf("failed to close the window: %w", err)
+	}
+	return nil
+}
+
+// Click presses the button within the window whose text matches the given
+// text. The comparison ignores case and keyboard accelerators, so "&Next >"
+// is matched by "next >". It returns false if a matching button is not
+// found.
+func (w Window) Click(button string) (bool, error) {
+	if err := procEnumChildWindows.Find(); err != nil {
+		return false, err
+	}
+
+	var target windows.HWND
+	err := enumerate(procEnumChildWindows, w.Handle, func(hwnd windows.HWND) {
+		if target != 0 || !strings.EqualFold(text(procGetClassNameW, hwnd), "Button") {
+			return
+		}
+		label := strings.ReplaceAll(text(procGetWindowTextW, hwnd), "&", "")
+		if strings.EqualFold(strings.TrimSpace(label), strings.TrimSpace(button)) {
+			target = hwnd
+		}
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to enumerate the controls of the window: %w", err)
+	}
+	if target == 0 {
+		return false, nil
+	}
+
+	if r1, _, err := procPostMessageW.Call(uintptr(target), bmClick, 0, 0); r1 == 0 {
+		return false, fmt.Errorf("failed to press the \"%s\" button: %w", button, err)
+	}
+	return true, nil
+}
+
+// enumerate calls visit for each window enumerated by proc. If parent is
+// non-zero, it is passed to proc as the parent window.
+func enumerate(proc *windows.LazyProc, parent windows.HWND, visit func(hwnd windows.HWND)) error {
+	enumMutex.Lock()
+	defer enumMutex.Unlock()
+
+	enumVisitor = visit
+	defer func() { enumVisitor = nil }()
+
+	// EnumChildWindows does not report errors.
+	if parent != 0 {
+		proc.Call(uintptr(parent), enumCallback, 0)
+		return nil
+	}
+	if r1, _, err := proc.Call(enumCallback, 0); r1 == 0 {
+		return err
+	}
+	return nil
+}
+
+// text retrieves the window text or class name of a window with the given
+// procedure.
+func text(proc *windows.LazyProc, hwnd windows.HWND) string {
+	buf := make([]uint16, maxTextLength)
+	n, _, _ := proc.Call(uintptr(hwnd), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	return windows.UTF16ToString(buf[:n])
+}
@@ -0,0 +1,24 @@
+package winplatform
+
+import "github.com/leafbridge/leafbridge/platform/windows/localaccount"
+
+// AccountDetector reports on the local user accounts, local groups and
+// user rights of the local system.
+type AccountDetector struct{}
+
+// LocalUserExists returns true if a local user with the given name exists.
+func (AccountDetector) LocalUserExists(name string) (bool, error) {
+	return localaccount.UserExists(name)
+}
+
+// IsGroupMember returns true if the account is a direct member of the
+// local group.
+func (AccountDetector) IsGroupMember(group, account string) (bool, error) {
+	return localaccount.IsGroupMember(group, account)
+}
+
+// HasUserRight returns true if the user right has been granted to the
+// account directly.
+func (AccountDetector) HasUserRight(right, account string) (bool, error) {
+	return localaccount.HasRight(account, right)
+}
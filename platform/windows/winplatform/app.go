@@ -0,0 +1,47 @@
+package winplatform
+
+import (
+	"github.com/gentlemanautomaton/winapp/appcode"
+	"github.com/gentlemanautomaton/winapp/unpackaged"
+	"github.com/gentlemanautomaton/winapp/unpackaged/appregistry"
+	"github.com/gentlemanautomaton/winapp/unpackaged/appscope"
+	"github.com/leafbridge/leafbridge/core/datatype"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+)
+
+// AppDetector looks up applications in the Windows application registry.
+type AppDetector struct{}
+
+// IsInstalled returns true if the application is present in the Windows
+// application registry.
+func (AppDetector) IsInstalled(app lbdeploy.Application) (bool, error) {
+	// Use the application registry that matches the application's
+	// architecture (x64 or x86) and scope (machine or user).
+	view, err := appregistry.ViewFor(appcode.Architecture(app.Architecture), appscope.Scope(app.Scope))
+	if err != nil {
+		return false, err
+	}
+
+	// Look for the application in the registry.
+	return view.Contains(unpackaged.AppID(app.ProductCode))
+}
+
+// Version returns the DisplayVersion recorded for the application in the
+// Windows application registry.
+func (AppDetector) Version(app lbdeploy.Application) (datatype.Version, error) {
+	// Use the application registry that matches the application's
+	// architecture (x64 or x86) and scope (machine or user).
+	view, err := appregistry.ViewFor(appcode.Architecture(app.Architecture), appscope.Scope(app.Scope))
+	if err != nil {
+		return "", err
+	}
+
+	// Retrieve the properties of the app from the registry.
+	properties, err := view.Get(unpackaged.AppID(app.ProductCode))
+	if err != nil {
+		return "", err
+	}
+
+	// If a DisplayVersion property is present, return it.
+	return datatype.Version(properties.Attributes.GetString("DisplayVersion")), nil
+}
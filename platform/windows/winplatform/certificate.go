@@ -0,0 +1,83 @@
+package winplatform
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"golang.org/x/sys/windows"
+)
+
+// CertificateDetector looks up certificates in the certificate stores of
+// the local machine.
+type CertificateDetector struct{}
+
+// CertificateExists returns true if a certificate matching the resource is
+// present in its LocalMachine certificate store. It returns false if the
+// store does not exist.
+func (CertificateDetector) CertificateExists(resource lbdeploy.CertificateResource) (bool, error) {
+	name, err := windows.UTF16PtrFromString(resource.Store)
+	if err != nil {
+		return false, err
+	}
+
+	const flags = windows.CERT_SYSTEM_STORE_LOCAL_MACHINE | windows.CERT_STORE_READONLY_FLAG | windows.CERT_STORE_OPEN_EXISTING_FLAG
+	store, err := windows.CertOpenStore(windows.CERT_STORE_PROV_SYSTEM, 0, 0, flags, uintptr(unsafe.Pointer(name)))
+	if err != nil {
+		if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open the \"%s\" certificate store: %w", resource.Store, err)
+	}
+	defer windows.CertCloseStore(store, 0)
+
+	thumbprint := resource.NormalizedThumbprint()
+	now := time.Now()
+
+	// Each call to CertEnumCertificatesInStore frees the previous context.
+	var context *windows.CertContext
+	for {
+		context, _ = windows.CertEnumCertificatesInStore(store, context)
+		if context == nil {
+			return false, nil
+		}
+
+		raw := unsafe.Slice(context.EncodedCert, context.Length)
+		if thumbprint != "" {
+			sum := sha1.Sum(raw)
+			if hex.EncodeToString(sum[:]) != thumbprint {
+				continue
+			}
+		}
+
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			// Skip certificates that cannot be parsed.
+			continue
+		}
+		if resource.Subject != "" && !matchCertificateSubject(cert, resource.Subject) {
+			continue
+		}
+		if !resource.IncludeExpired && (now.Before(cert.NotBefore) || now.After(cert.NotAfter)) {
+			continue
+		}
+
+		windows.CertFreeCertificateContext(context)
+		return true, nil
+	}
+}
+
+// matchCertificateSubject returns true if the subject matches the common
+// name or the distinguished name of the certificate.
+func matchCertificateSubject(cert *x509.Certificate, subject string) bool {
+	if strings.EqualFold(cert.Subject.CommonName, subject) {
+		return true
+	}
+	return strings.EqualFold(cert.Subject.String(), subject)
+}
@@ -0,0 +1,83 @@
+package winplatform
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/windows/localfs"
+)
+
+// FileSystem provides access to file system resources on the local
+// Windows system.
+type FileSystem struct {
+	resources lbdeploy.FileSystemResources
+}
+
+// DirectoryExists returns true if the directory exists.
+func (fs FileSystem) DirectoryExists(id lbdeploy.DirectoryResourceID) (bool, error) {
+	resolver := localfs.NewResolver(fs.resources)
+	ref, err := resolver.ResolveDirectory(id)
+	if err != nil {
+		return false, err
+	}
+	dir, err := localfs.OpenDir(ref)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer dir.Close()
+	return true, nil
+}
+
+// FileExists returns true if the file exists. It returns an error if the
+// file's path exists but is not a regular file.
+func (fs FileSystem) FileExists(id lbdeploy.FileResourceID) (bool, error) {
+	resolver := localfs.NewResolver(fs.resources)
+	ref, err := resolver.ResolveFile(id)
+	if err != nil {
+		return false, err
+	}
+	dir, err := localfs.OpenDir(ref.Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer dir.Close()
+	fi, err := dir.System().Stat(ref.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if fi.Mode().IsRegular() {
+		return true, nil
+	}
+	path, err := ref.Path()
+	if err != nil {
+		return false, fmt.Errorf("the path exists but it is not a regular file")
+	}
+	return false, fmt.Errorf("the \"%s\" path exists but it is not a regular file", path)
+}
+
+// OpenFile opens the file for reading. If the file does not exist it
+// returns an error that satisfies os.IsNotExist.
+func (fs FileSystem) OpenFile(id lbdeploy.FileResourceID) (io.ReadCloser, error) {
+	resolver := localfs.NewResolver(fs.resources)
+	ref, err := resolver.ResolveFile(id)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := localfs.OpenDir(ref.Dir())
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	return dir.System().Open(ref.FilePath)
+}
@@ -0,0 +1,153 @@
+package winplatform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/platform/windows/bitlocker"
+	"github.com/leafbridge/leafbridge/platform/windows/powerscheme"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+var (
+	modkernel32                            = windows.NewLazySystemDLL("kernel32.dll")
+	modtbs                                 = windows.NewLazySystemDLL("tbs.dll")
+	procGetPhysicallyInstalledSystemMemory = modkernel32.NewProc("GetPhysicallyInstalledSystemMemory")
+	procTbsiGetDeviceInfo                  = modtbs.NewProc("Tbsi_GetDeviceInfo")
+)
+
+// TPM Base Services constants.
+const (
+	tbsSuccess        = 0
+	tbsETPMNotFound   = 0x8028400F
+	tpmDeviceInfoV1   = 1
+	secureBootKeyPath = `SYSTEM\CurrentControlSet\Control\SecureBoot\State`
+	biosKeyPath       = `HARDWARE\DESCRIPTION\System\BIOS`
+)
+
+// tpmDeviceInfo is the TPM_DEVICE_INFO structure returned by
+// Tbsi_GetDeviceInfo.
+type tpmDeviceInfo struct {
+	structVersion    uint32
+	tpmVersion       uint32
+	tpmInterfaceType uint32
+	tpmImpRevision   uint32
+}
+
+// HardwareDetector reports on the hardware and firmware of the local
+// system.
+type HardwareDetector struct{}
+
+// InstalledMemory returns the amount of physically installed memory in
+// bytes, as reported by the system firmware.
+func (HardwareDetector) InstalledMemory() (int64, error) {
+	if err := procGetPhysicallyInstalledSystemMemory.Find(); err != nil {
+		return 0, err
+	}
+	var kilobytes uint64
+	r1, _, err := procGetPhysicallyInstalledSystemMemory.Call(uintptr(unsafe.Pointer(&kilobytes)))
+	if r1 == 0 {
+		return 0, fmt.Errorf("failed to determine the amount of installed memory: %w", err)
+	}
+	return int64(kilobytes) << 10, nil
+}
+
+// LogicalProcessors returns the number of logical processors across all
+// processor groups.
+func (HardwareDetector) LogicalProcessors() (int, error) {
+	count := windows.GetActiveProcessorCount(windows.ALL_PROCESSOR_GROUPS)
+	if count == 0 {
+		return 0, errors.New("failed to determine the number of logical processors")
+	}
+	return int(count), nil
+}
+
+// TPMVersion returns the major version of the trusted platform module
+// through TPM Base Services. It returns zero if a TPM is not present.
+func (HardwareDetector) TPMVersion() (int, error) {
+	if err := procTbsiGetDeviceInfo.Find(); err != nil {
+		return 0, err
+	}
+	info := tpmDeviceInfo{structVersion: tpmDeviceInfoV1}
+	r1, _, _ := procTbsiGetDeviceInfo.Call(uintptr(unsafe.Sizeof(info)), uintptr(unsafe.Pointer(&info)))
+	switch r1 {
+	case tbsSuccess:
+		return int(info.tpmVersion), nil
+	case tbsETPMNotFound:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("failed to retrieve TPM device information: TBS result 0x%08X", r1)
+	}
+}
+
+// SecureBootEnabled returns true if UEFI Secure Boot is enabled. Systems
+// that boot through legacy BIOS report that it is disabled.
+func (HardwareDetector) SecureBootEnabled() (bool, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, secureBootKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer key.Close()
+
+	value, _, err := key.GetIntegerValue("UEFISecureBootEnabled")
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return value == 1, nil
+}
+
+// Hypervisor returns the hypervisor that the local system is running on,
+// based on the system manufacturer and product names reported by the
+// firmware. It returns an empty string if the system is a physical
+// machine.
+//
+// The hypervisor bit reported by the processor is not used, because it is
+// also set on physical machines that run Hyper-V or virtualization-based
+// security.
+func (HardwareDetector) Hypervisor() (lbdeploy.Hypervisor, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, biosKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed to open the system firmware information: %w", err)
+	}
+	defer key.Close()
+
+	manufacturer, _, err := key.GetStringValue("SystemManufacturer")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return "", err
+	}
+	product, _, err := key.GetStringValue("SystemProductName")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return "", err
+	}
+
+	return lbdeploy.IdentifyHypervisor(manufacturer, product), nil
+}
+
+// BitLockerProtected returns true if BitLocker protection of the system
+// drive is on.
+func (HardwareDetector) BitLockerProtected() (bool, error) {
+	drive, err := bitlocker.SystemDrive()
+	if err != nil {
+		return false, err
+	}
+	status, err := bitlocker.Status(context.Background(), drive)
+	if err != nil {
+		return false, fmt.Errorf("failed to determine the BitLocker protection status of the %s drive: %w", drive, err)
+	}
+	return status == bitlocker.ProtectionOn, nil
+}
+
+// ActivePowerPlan returns the GUID of the active power scheme.
+func (HardwareDetector) ActivePowerPlan() (string, error) {
+	return powerscheme.Active()
+}
@@ -0,0 +1,124 @@
+package winplatform
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"unsafe"
+
+	"github.com/leafbridge/leafbridge/core/lbplatform"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+var (
+	procGetSystemDefaultUILanguage = modkernel32.NewProc("GetSystemDefaultUILanguage")
+	procGetSystemDefaultLocaleName = modkernel32.NewProc("GetSystemDefaultLocaleName")
+	procLCIDToLocaleName           = modkernel32.NewProc("LCIDToLocaleName")
+)
+
+// localeNameMaxLength is the maximum length of a locale name, including
+// its terminating null character.
+const localeNameMaxLength = 85
+
+// LocaleDetector reports on the language and regional settings of the
+// local system.
+type LocaleDetector struct{}
+
+// UILanguage returns the display language that the operating system was
+// installed with.
+func (LocaleDetector) UILanguage() (string, error) {
+	if err := procGetSystemDefaultUILanguage.Find(); err != nil {
+		return "", err
+	}
+	langID, _, _ := procGetSystemDefaultUILanguage.Call()
+	return localeName(uint32(uint16(langID)))
+}
+
+// SystemLocale returns the system locale.
+func (LocaleDetector) SystemLocale() (string, error) {
+	if err := procGetSystemDefaultLocaleName.Find(); err != nil {
+		return "", err
+	}
+	var buf [localeNameMaxLength]uint16
+	r1, _, err := procGetSystemDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if r1 == 0 {
+		return "", fmt.Errorf("failed to determine the system locale: %w", err)
+	}
+	return windows.UTF16ToString(buf[:]), nil
+}
+
+// KeyboardLayouts returns the keyboard layouts that are loaded for the
+// current user. When LeafBridge runs as a service, these are the layouts
+// of the default user profile.
+//
+// The identifier of each layout is its keyboard layout identifier (KLID),
+// after layout substitutions have been applied.
+func (LocaleDetector) KeyboardLayouts() ([]lbplatform.KeyboardLayout, error) {
+	preload, err := registry.OpenKey(registry.CURRENT_USER, `Keyboard Layout\Preload`, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open the keyboard layouts of the current user: %w", err)
+	}
+	defer preload.Close()
+
+	names, err := preload.ReadValueNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the keyboard layouts of the current user: %w", err)
+	}
+
+	// Values are named by their order of preference.
+	slices.SortFunc(names, func(a, b string) int {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return x - y
+	})
+
+	substitutes, err := registry.OpenKey(registry.CURRENT_USER, `Keyboard Layout\Substitutes`, registry.QUERY_VALUE)
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open the keyboard layout substitutions of the current user: %w", err)
+	}
+	if err == nil {
+		defer substitutes.Close()
+	}
+
+	var layouts []lbplatform.KeyboardLayout
+	for _, name := range names {
+		id, _, err := preload.GetStringValue(name)
+		if err != nil || id == "" {
+			continue
+		}
+
+		// The low word of the preloaded identifier is a language ID.
+		var language string
+		if klid, err := strconv.ParseUint(id, 16, 32); err == nil {
+			language, _ = localeName(uint32(klid & 0xFFFF))
+		}
+
+		if substitutes != 0 {
+			if substitute, _, err := substitutes.GetStringValue(id); err == nil && substitute != "" {
+				id = substitute
+			}
+		}
+
+		layouts = append(layouts, lbplatform.KeyboardLayout{ID: id, Language: language})
+	}
+
+	return layouts, nil
+}
+
+// localeName returns the locale name for a locale identifier.
+func localeName(lcid uint32) (string, error) {
+	if err := procLCIDToLocaleName.Find(); err != nil {
+		return "", err
+	}
+	var buf [localeNameMaxLength]uint16
+	r1, _, err := procLCIDToLocaleName.Call(uintptr(lcid), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)), 0)
+	if r1 == 0 {
+		return "", fmt.Errorf("failed to determine the locale name for locale identifier 0x%04X: %w", lcid, err)
+	}
+	return windows.UTF16ToString(buf[:]), nil
+}
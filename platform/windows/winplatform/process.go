@@ -0,0 +1,196 @@
+package winplatform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/gentlemanautomaton/winobj/winmutex"
+	"github.com/gentlemanautomaton/winproc"
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"golang.org/x/sys/windows"
+)
+
+// ProcessController provides information about processes and kernel
+// objects on the local Windows system.
+type ProcessController struct{}
+
+// NumberOfRunningProcesses returns the number of processes running on the
+// local system that match the given criteria.
+//
+// Values are compared without regard to case.
+func (ProcessController) NumberOfRunningProcesses(match lbdeploy.ProcessMatch) (n int, err error) {
+	filter, err := match.Compile(true)
+	if err != nil {
+		return 0, err
+	}
+
+	procs, err := winproc.List()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, proc := range procs {
+		info := processInfo{id: uint32(proc.ID), name: proc.Name}
+		matched, err := filter(&info)
+		info.close()
+		if err != nil {
+			return 0, err
+		}
+		if matched {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// CommandLines returns the command lines of the running processes with the
+// given executable name. Names are compared without regard to case.
+// Processes that cannot be examined are skipped.
+func CommandLines(name string) ([]string, error) {
+	procs, err := winproc.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, proc := range procs {
+		if !strings.EqualFold(proc.Name, name) {
+			continue
+		}
+		info := processInfo{id: uint32(proc.ID), name: proc.Name}
+		line, _ := info.ProcessAttribute(lbdeploy.ProcessCommandLine)
+		info.close()
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
+
+// MutexExists returns true if a mutex with the given object name exists.
+func (ProcessController) MutexExists(name string) (bool, error) {
+	return winmutex.Exists(name)
+}
+
+// processInfo collects the attributes of a Windows process on demand.
+//
+// Processes that cannot be opened, typically because they belong to a
+// protected process or have exited, report empty attributes so that they
+// never match.
+type processInfo struct {
+	id     uint32
+	name   string
+	opened bool
+	handle windows.Handle
+}
+
+// ProcessAttribute returns the value of the given process attribute.
+func (p *processInfo) ProcessAttribute(attr lbdeploy.ProcessAttributeID) (string, error) {
+	switch attr {
+	case lbdeploy.ProcessName:
+		return p.name, nil
+	case lbdeploy.ProcessSession:
+		var session uint32
+		if err := windows.ProcessIdToSessionId(p.id, &session); err != nil {
+			return "", nil
+		}
+		return strconv.FormatUint(uint64(session), 10), nil
+	case lbdeploy.ProcessPath, lbdeploy.ProcessCommandLine, lbdeploy.ProcessUser:
+	default:
+		return "", fmt.Errorf("the process attribute \"%s\" is not recognized", attr)
+	}
+
+	handle, ok := p.open()
+	if !ok {
+		return "", nil
+	}
+
+	var (
+		value string
+		err   error
+	)
+	switch attr {
+	case lbdeploy.ProcessPath:
+		value, err = processImagePath(handle)
+	case lbdeploy.ProcessCommandLine:
+		value, err = processCommandLine(handle)
+	case lbdeploy.ProcessUser:
+		value, err = processUser(handle)
+	}
+	if err != nil {
+		return "", nil
+	}
+	return value, nil
+}
+
+// open returns a handle to the process with limited query rights. It
+// returns false if the process could not be opened.
+func (p *processInfo) open() (windows.Handle, bool) {
+	if !p.opened {
+		p.opened = true
+		handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, p.id)
+		if err == nil {
+			p.handle = handle
+		}
+	}
+	return p.handle, p.handle != 0
+}
+
+// close releases the process handle, if one was opened.
+func (p *processInfo) close() {
+	if p.handle != 0 {
+		windows.CloseHandle(p.handle)
+		p.handle = 0
+	}
+}
+
+// processImagePath returns the full path of the process executable.
+func processImagePath(handle windows.Handle) (string, error) {
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err != nil {
+		return "", err
+	}
+	return windows.UTF16ToString(buf[:size]), nil
+}
+
+// processCommandLine returns the command line of the process.
+func processCommandLine(handle windows.Handle) (string, error) {
+	// The command line is returned as a UNICODE_STRING followed by its
+	// buffer. A UNICODE_STRING holds at most 65535 bytes, so this buffer
+	// is always large enough. It is allocated as []uint64 for alignment.
+	buf := make([]uint64, (unsafe.Sizeof(windows.NTUnicodeString{})+0xFFFF)/8+1)
+	size := uint32(len(buf) * 8)
+	if err := windows.NtQueryInformationProcess(handle, windows.ProcessCommandLineInformation, unsafe.Pointer(&buf[0]), size, nil); err != nil {
+		return "", err
+	}
+	return (*windows.NTUnicodeString)(unsafe.Pointer(&buf[0])).String(), nil
+}
+
+// processUser returns the account that owns the process, formatted as
+// DOMAIN\account.
+func processUser(handle windows.Handle) (string, error) {
+	var token windows.Token
+	if err := windows.OpenProcessToken(handle, windows.TOKEN_QUERY, &token); err != nil {
+		return "", err
+	}
+	defer token.Close()
+
+	user, err := token.GetTokenUser()
+	if err != nil {
+		return "", err
+	}
+
+	account, domain, _, err := user.User.Sid.LookupAccount("")
+	if err != nil {
+		return user.User.Sid.String(), nil
+	}
+	if domain == "" {
+		return account, nil
+	}
+	return domain + `\` + account, nil
+}
@@ -0,0 +1,67 @@
+package winplatform
+
+import (
+	"os"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbvalue"
+	"github.com/leafbridge/leafbridge/platform/windows/localregistry"
+)
+
+// Registry provides read access to registry resources on the local Windows
+// system.
+type Registry struct {
+	resources lbdeploy.RegistryResources
+}
+
+// KeyExists returns true if the registry key exists.
+func (r Registry) KeyExists(id lbdeploy.RegistryKeyResourceID) (bool, error) {
+	resolver := localregistry.NewResolver(r.resources)
+	ref, err := resolver.ResolveKey(id)
+	if err != nil {
+		return false, err
+	}
+	key, err := localregistry.OpenKey(ref)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer key.Close()
+	return true, nil
+}
+
+// ValueExists returns true if the registry value exists.
+func (r Registry) ValueExists(id lbdeploy.RegistryValueResourceID) (bool, error) {
+	resolver := localregistry.NewResolver(r.resources)
+	ref, err := resolver.ResolveValue(id)
+	if err != nil {
+		return false, err
+	}
+	key, err := localregistry.OpenKey(ref.Key())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer key.Close()
+	return key.HasValue(ref.Name)
+}
+
+// GetValue returns the registry value. If the key or value does not exist
+// it returns an error that satisfies os.IsNotExist.
+func (r Registry) GetValue(id lbdeploy.RegistryValueResourceID) (lbvalue.Value, error) {
+	resolver := localregistry.NewResolver(r.resources)
+	ref, err := resolver.ResolveValue(id)
+	if err != nil {
+		return lbvalue.Value{}, err
+	}
+	key, err := localregistry.OpenKey(ref.Key())
+	if err != nil {
+		return lbvalue.Value{}, err
+	}
+	defer key.Close()
+	return key.GetValue(ref.Name, ref.Type)
+}
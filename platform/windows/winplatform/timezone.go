@@ -0,0 +1,42 @@
+package winplatform
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetDynamicTimeZoneInformation = modkernel32.NewProc("GetDynamicTimeZoneInformation")
+
+// timeZoneIDInvalid is returned by GetDynamicTimeZoneInformation when it
+// fails.
+const timeZoneIDInvalid = 0xFFFFFFFF
+
+// dynamicTimeZoneInformation is the DYNAMIC_TIME_ZONE_INFORMATION
+// structure.
+type dynamicTimeZoneInformation struct {
+	Bias                        int32
+	StandardName                [32]uint16
+	StandardDate                windows.Systemtime
+	StandardBias                int32
+	DaylightName                [32]uint16
+	DaylightDate                windows.Systemtime
+	DaylightBias                int32
+	TimeZoneKeyName             [128]uint16
+	DynamicDaylightTimeDisabled uint8
+}
+
+// TimeZone returns the Windows identifier of the current time zone, such
+// as "W. Europe Standard Time".
+func (LocaleDetector) TimeZone() (string, error) {
+	if err := procGetDynamicTimeZoneInformation.Find(); err != nil {
+		return "", err
+	}
+	var info dynamicTimeZoneInformation
+	r1, _, err := procGetDynamicTimeZoneInformation.Call(uintptr(unsafe.Pointer(&info)))
+	if r1 == timeZoneIDInvalid {
+		return "", fmt.Errorf("failed to determine the current time zone: %w", err)
+	}
+	return windows.UTF16ToString(info.TimeZoneKeyName[:]), nil
+}
@@ -0,0 +1,76 @@
+// Package winservice controls Windows services.
+package winservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// pollInterval is the interval at which the state of a service is checked
+// while waiting for it to change.
+const pollInterval = 250 * time.Millisecond
+
+// Restart stops the named service if it is running, waits for it to stop,
+// and then starts it again. If the service is not running, it is started.
+//
+// The context bounds the time spent waiting for the service to stop.
+func Restart(ctx context.Context, name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service control manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("failed to open the service: %w", err)
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		return fmt.Errorf("failed to query the state of the service: %w", err)
+	}
+
+	if status.State != svc.Stopped {
+		if status.State != svc.StopPending {
+			if _, err := s.Control(svc.Stop); err != nil {
+				return fmt.Errorf("failed to stop the service: %w", err)
+			}
+		}
+		if err := waitForState(ctx, s, svc.Stopped); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start the service: %w", err)
+	}
+
+	return nil
+}
+
+// waitForState waits until the service reaches the given state.
+func waitForState(ctx context.Context, s *mgr.Service, state svc.State) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := s.Query()
+		if err != nil {
+			return fmt.Errorf("failed to query the state of the service: %w", err)
+		}
+		if status.State == state {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the service did not stop in time: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package leafbridge
+
+import (
+	"context"
+
+	"github.com/leafbridge/leafbridge/core/lbdeploy"
+	"github.com/leafbridge/leafbridge/core/lbtest"
+)
+
+// Test simulates a flow of the deployment against the system described by
+// the fixture, without making any changes to the local system. It returns
+// the steps that were simulated, in order, and the error that the flow
+// returned. The steps can be checked against the expectations of the
+// fixture with its Expect.Check method.
+func Test(ctx context.Context, dep lbdeploy.Deployment, fixture lbtest.Fixture) ([]lbtest.Step, error) {
+	return lbtest.Run(ctx, dep, fixture)
+}
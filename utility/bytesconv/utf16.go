@@ -90,6 +90,29 @@ func HasUTF16BOM(p []byte, order binary.ByteOrder) bool {
 	return order.Uint16(p) == 0xFEFF
 }
 
+// LooksLikeUTF16 returns true if the given bytes appear to hold text encoded
+// as UTF-16 with the specified byte order.
+//
+// It looks for code units in the ASCII and Latin-1 ranges, which are
+// encoded with a zero high byte. Most of the code units in console output
+// encoded as UTF-16 fall into these ranges, while text in single-byte and
+// multi-byte encodings rarely contains zero bytes at all.
+func LooksLikeUTF16(p []byte, order binary.ByteOrder) bool {
+	n := len(p) &^ 1
+	if n < 2 {
+		return false
+	}
+
+	var narrow int
+	for i := 0; i+1 < n; i += 2 {
+		if v := order.Uint16(p[i:]); v != 0 && v <= 0xFF {
+			narrow++
+		}
+	}
+
+	return narrow*2 >= n/2
+}
+
 // These constants are taken from the standard library's utf16 package.
 const (
 	// 0xd800-0xdc00 encodes the high 10 bits of a pair.